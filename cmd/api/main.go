@@ -7,16 +7,30 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "github.com/eneskaya/insider-messaging/docs"
 	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/archive"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/failover"
 	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/kafka"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/leader"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/outbox"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/retention"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/scheduler"
+	"github.com/eneskaya/insider-messaging/internal/presentation/grpcserver"
 	"github.com/eneskaya/insider-messaging/internal/presentation/handler"
+	"github.com/eneskaya/insider-messaging/internal/presentation/middleware"
 	"github.com/eneskaya/insider-messaging/internal/presentation/router"
 	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/graceful"
+	"github.com/eneskaya/insider-messaging/pkg/jwt"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -39,7 +53,7 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Type "Bearer" followed by a space and your API token
+// @description Type "Bearer" followed by a space and your API token or JWT
 
 func main() {
 	if err := run(); err != nil {
@@ -64,30 +78,258 @@ func run() error {
 		zap.String("port", cfg.App.Port),
 	)
 
-	db, err := persistence.NewPostgresGormDB(&cfg.Database)
+	if cfg.Database.AutoMigrate {
+		if err := persistence.MigrateUp(&cfg.Database); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	db, err := persistence.NewGormDB(&cfg.Database)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
 
-	redisCache, err := cache.NewRedisCache(&cfg.Redis)
+	// Without Redis caching is a no-op, and the send guard and recipient
+	// limits, which already fail open when Redis errors, are left off
+	var redisCache *cache.RedisCache
+	if cfg.Redis.Enabled {
+		redisCache, err = cache.NewRedisCache(&cfg.Redis)
+		if err != nil {
+			if !cfg.Redis.Optional {
+				return fmt.Errorf("failed to connect to Redis: %w", err)
+			}
+			if required := cfg.RedisRequiredBy(); required != "" {
+				return fmt.Errorf("failed to connect to Redis, which %s needs: %w", required, err)
+			}
+			logger.Get().Warn("Redis is unreachable, starting without it", zap.Error(err))
+		} else {
+			defer redisCache.Close()
+		}
+	}
+	if redisCache == nil {
+		logger.Get().Warn("running without Redis: caching, the send guard and recipient limits are off")
+	}
+
+	var sentCacheTTL time.Duration
+	if cfg.Redis.SentCacheEnabled {
+		sentCacheTTL = cfg.Redis.SentCacheTTL
+	}
+	var messageStore cache.Cache = cache.NoopCache{}
+	var cacheFallback *cache.FallbackCache
+	if redisCache != nil {
+		messageStore = redisCache
+		if cfg.Redis.MemoryFallback {
+			cacheFallback = cache.NewFallbackCache(
+				redisCache,
+				cache.NewMemoryCache(cfg.Redis.MemoryMaxEntries, cfg.Redis.CacheTTL),
+				cfg.Redis.FallbackRetryInterval,
+			)
+			messageStore = cacheFallback
+		}
+	}
+	messageCache := cache.NewMessageCache(messageStore, sentCacheTTL, cfg.Redis.StatsCacheTTL)
+
+	var breaker *infrahttp.CircuitBreaker
+	if cfg.Webhook.BreakerThreshold > 0 {
+		breaker = infrahttp.NewCircuitBreaker(cfg.Webhook.BreakerThreshold, cfg.Webhook.BreakerOpenDuration)
+	}
+
+	sender, err := infrahttp.NewSenderProvider(context.Background(), &cfg.Sender, &cfg.Webhook, breaker)
+	if err != nil {
+		return fmt.Errorf("failed to create sender provider: %w", err)
+	}
+	logger.Get().Info("sender provider configured", zap.String("provider", sender.Name()))
+	if cfg.Sender.Provider == "fake" {
+		logger.Get().Warn("messages are sent to the fake webhook, not a real provider",
+			zap.String("url", cfg.Sender.FakeWebhookURL),
+		)
+	}
+	if limited, ok := sender.(infrahttp.QuotaLimited); ok && cfg.Webhook.DailyQuota > 0 && redisCache != nil {
+		limited.LimitDaily(cache.NewDailyQuota(redisCache, sender.Name(), cfg.Webhook.DailyQuota))
+		logger.Get().Info("provider daily quota enforced", zap.Int("daily_quota", cfg.Webhook.DailyQuota))
+	}
+	if cfg.Message.DryRun {
+		logger.Get().Warn("dry-run mode: sends are simulated and no provider is called")
+	}
+
+	channels, err := infrahttp.NewChannelProviders(&cfg.Sender, &cfg.Webhook)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Redis: %w", err)
+		return fmt.Errorf("failed to create channel providers: %w", err)
+	}
+	for channel, channelSender := range channels {
+		logger.Get().Info("channel provider configured",
+			zap.String("channel", channel.String()),
+			zap.String("provider", channelSender.Name()),
+		)
 	}
-	defer redisCache.Close()
 
-	messageCache := cache.NewMessageCache(redisCache)
+	charLimit := valueobject.ContentCharLimit(cfg.Message.CharLimit, cfg.Message.MaxSegments)
+	messageRepo := persistence.NewMessageRepositoryGorm(
+		db.DB(),
+		charLimit,
+		cfg.Outbox.Enabled,
+		cfg.Message.LowerPriorityShare,
+	)
+
+	eventBus := eventbus.NewInMemoryBus()
+	auditService := service.NewAuditService(persistence.NewAuditRepositoryGorm(db.DB()), cfg.Audit.Enabled)
+
+	var recipientGuard cache.RecipientGuard
+	if cfg.Message.RecipientLimit.Enabled() && redisCache != nil {
+		recipientGuard = cache.NewRecipientGuard(
+			redisCache,
+			cfg.Message.RecipientLimit.MaxPerWindow,
+			cfg.Message.RecipientLimit.Window,
+			cfg.Message.RecipientLimit.DedupWindow,
+			cfg.Message.RecipientLimit.DedupAction == "collapse",
+		)
+	}
+
+	var sendGuard cache.SendGuard
+	if cfg.Message.SendGuard.Enabled() && redisCache != nil {
+		sendGuard = cache.NewSendGuard(redisCache, cfg.Message.SendGuard.HoldTTL, cfg.Message.SendGuard.ResultTTL)
+	}
+
+	var blacklistCache cache.BlacklistCache
+	if redisCache != nil {
+		blacklistCache = cache.NewBlacklistCache(redisCache, cfg.Blacklist.CacheTTL)
+	}
+	blacklistService := service.NewBlacklistService(
+		persistence.NewBlacklistRepositoryGorm(db.DB()),
+		blacklistCache,
+		auditService,
+	)
+
+	var moderation *service.ContentModeration
+	if cfg.Message.ContentFilter.Enabled() {
+		contentFilter, err := service.NewRegexContentFilter(map[string]string{
+			service.ContentRuleProfanity:  cfg.Message.ContentFilter.Profanity,
+			service.ContentRuleCreditCard: cfg.Message.ContentFilter.CreditCards,
+			service.ContentRuleURL:        cfg.Message.ContentFilter.URLs,
+		}, cfg.Message.ContentFilter.ProfanityWords)
+		if err != nil {
+			return fmt.Errorf("failed to create content filter: %w", err)
+		}
+		moderation = &service.ContentModeration{
+			Filter: contentFilter,
+			OnSend: cfg.Message.ContentFilter.OnSend,
+		}
+	}
 
-	webhookClient := infrahttp.NewWebhookClient(&cfg.Webhook)
+	tenantRepo := persistence.NewTenantRepositoryGorm(db.DB())
+
+	var quietWindow valueobject.QuietHours
+	if cfg.Message.QuietHours.Window != "" {
+		if quietWindow, err = valueobject.ParseQuietHours(cfg.Message.QuietHours.Window); err != nil {
+			return fmt.Errorf("failed to read QUIET_HOURS: %w", err)
+		}
+	}
+	var quietExempt []valueobject.MessagePriority
+	for _, priority := range cfg.Message.QuietHours.ExemptPriorities {
+		quietExempt = append(quietExempt, valueobject.MessagePriority(priority))
+	}
+	quietHours := service.NewQuietHours(quietWindow, cfg.Message.QuietHours.Countries, quietExempt, tenantRepo)
 
-	messageRepo := persistence.NewMessageRepositoryGorm(db.DB(), cfg.Message.CharLimit)
+	webhookDestinationRepo := persistence.NewWebhookDestinationRepositoryGorm(db.DB())
+	destinationPool := infrahttp.NewDestinationPool(webhookDestinationRepo, &cfg.Webhook, cfg.Webhook.DestinationRefreshInterval)
+	errorMappingService := service.NewProviderErrorMappingService(
+		persistence.NewProviderErrorMappingRepositoryGorm(db.DB()),
+		cfg.Webhook.ErrorMappingRefreshInterval,
+	)
+
+	// Callback URLs are refused unless the callbacks can be signed
+	var callbackNotifier *infrahttp.CallbackNotifier
+	var callbacks provider.CallbackNotifier
+	if cfg.Callbacks.Enabled() {
+		notifier, err := infrahttp.NewCallbackNotifier(&cfg.Callbacks)
+		if err != nil {
+			return fmt.Errorf("failed to create callback notifier: %w", err)
+		}
+		callbackNotifier = notifier
+		callbacks = callbackNotifier
+	}
 
 	messageService := service.NewMessageService(
 		messageRepo,
-		webhookClient,
+		sender,
 		messageCache,
-		cfg.Message.CharLimit,
+		eventBus,
+		charLimit,
+		cfg.Message.MaxSegments,
 		cfg.Message.MaxRetries,
+		service.NewRetryBackoff(cfg.Message.RetryBackoff.Base, cfg.Message.RetryBackoff.Max),
+		persistence.NewDeadLetterRepositoryGorm(db.DB()),
+		recipientGuard,
+		service.NewDestinationPolicy(
+			cfg.Message.Destinations.Allowed,
+			cfg.Message.Destinations.Blocked,
+			cfg.Message.Destinations.Action,
+		),
+		auditService,
+		blacklistService,
+		moderation,
+		channels,
+		sendGuard,
+		destinationPool,
+		quietHours,
+		persistence.NewMessageAttemptRepositoryGorm(db.DB()),
+		errorMappingService,
+		callbacks,
+		cfg.Message.SplitParts(),
+		cfg.Message.DryRun,
+	)
+
+	var retryBudget *scheduler.RetryBudget
+	if cfg.Message.RetryBudget.MaxFailureRatio > 0 {
+		retryBudget = scheduler.NewRetryBudget(
+			cfg.Message.RetryBudget.Window,
+			cfg.Message.RetryBudget.MaxFailureRatio,
+			cfg.Message.RetryBudget.MinAttempts,
+			cfg.Message.RetryBudget.PauseDuration,
+		)
+	}
+
+	var dispatchGates []scheduler.DispatchGate
+	var dbMonitor *persistence.Monitor
+	if cfg.Database.MonitorInterval > 0 {
+		dbMonitor = persistence.NewMonitor(db, cfg.Database.MonitorInterval)
+		dispatchGates = append(dispatchGates, dbMonitor)
+	}
+
+	var elector *failover.Elector
+	if cfg.Failover.Enabled {
+		elector = failover.NewElector(persistence.NewLeaseRepositoryGorm(db.DB()), &cfg.Failover)
+		dispatchGates = append(dispatchGates, elector)
+	}
+
+	var leaderElector *leader.Elector
+	if cfg.Leader.Enabled {
+		leaderElector = leader.NewElector(cache.NewLeaderLock(redisCache, cfg.Leader.Key), &cfg.Leader)
+		dispatchGates = append(dispatchGates, leaderElector)
+	}
+
+	var reaper *scheduler.Reaper
+	if cfg.Message.StaleReaper.Threshold > 0 {
+		reaper = scheduler.NewReaper(
+			messageService,
+			cfg.Message.StaleReaper.Threshold,
+			cfg.Message.StaleReaper.Interval,
+			cfg.Message.StaleReaper.BatchSize,
+		)
+	}
+
+	var schedule scheduler.Schedule
+	if cfg.Message.Schedule != "" {
+		schedule, err = scheduler.ParseSchedule(cfg.Message.Schedule, cfg.Message.ScheduleTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid MESSAGE_SCHEDULE: %w", err)
+		}
+	}
+
+	schedulerRunService := service.NewSchedulerRunService(
+		persistence.NewSchedulerRunRepositoryGorm(db.DB()),
+		cfg.Message.RunRetention,
 	)
 
 	msgScheduler := scheduler.NewScheduler(
@@ -95,13 +337,170 @@ func run() error {
 		cfg.Message.BatchSize,
 		cfg.Message.IntervalSeconds,
 		cfg.Message.WorkerCount,
+		retryBudget,
+		scheduler.AllGates(dispatchGates...),
+		breaker,
+		reaper,
+		schedule,
+		schedulerRunService,
+	)
+	if cfg.Message.DispatchMode == scheduler.DispatchModeEager {
+		msgScheduler.SendOnCreate(eventBus)
+	}
+	msgScheduler.LimitRate(cfg.Message.RateLimitPerSecond)
+	if lane := cfg.Message.PriorityLane; lane.Enabled() {
+		priorities := make([]valueobject.MessagePriority, len(lane.Priorities))
+		for i, priority := range lane.Priorities {
+			priorities[i] = valueobject.MessagePriority(priority)
+		}
+		msgScheduler.AddLane(scheduler.Lane{
+			Name:               "priority",
+			Priorities:         priorities,
+			Interval:           lane.Interval,
+			BatchSize:          lane.BatchSize,
+			WorkerCount:        lane.WorkerCount,
+			RateLimitPerSecond: lane.RateLimitPerSecond,
+		})
+	}
+
+	rateLimited := []infrahttp.RateLimited{destinationPool}
+	if limited, ok := sender.(infrahttp.RateLimited); ok {
+		rateLimited = append(rateLimited, limited)
+	}
+	for _, channelSender := range channels {
+		if limited, ok := channelSender.(infrahttp.RateLimited); ok {
+			rateLimited = append(rateLimited, limited)
+		}
+	}
+	configWatcher := config.NewWatcher(cfg)
+	subscribeConfigChanges(configWatcher, msgScheduler, rateLimited)
+
+	messageIntake := service.NewMessageIntake(
+		messageService,
+		cfg.Message.AsyncQueueSize,
+		cfg.Message.AsyncWorkerCount,
+		cfg.Message.AsyncStatusRetention,
+	)
+
+	messageHandler := handler.NewMessageHandler(messageService, messageIntake)
+	schedulerHandler := handler.NewSchedulerHandler(msgScheduler, auditService, schedulerRunService)
+	var providerProbe *infrahttp.ProviderProbe
+	if cfg.Webhook.HealthCheck {
+		providerProbe, err = infrahttp.NewProviderProbe(&cfg.Webhook)
+		if err != nil {
+			return fmt.Errorf("failed to create provider probe: %w", err)
+		}
+	}
+	healthHandler := handler.NewHealthHandler(db, redisCache, cacheFallback, msgScheduler, providerProbe)
+
+	var autoPause *scheduler.AutoPause
+	if cfg.Message.AutoPause.Failures > 0 {
+		checks := []scheduler.HealthCheck{{Name: "database", Check: db.HealthCheck}}
+		if providerProbe != nil {
+			checks = append(checks, scheduler.HealthCheck{Name: "provider", Check: providerProbe.Check})
+		}
+		autoPause = scheduler.NewAutoPause(checks, cfg.Message.AutoPause.Interval, cfg.Message.AutoPause.Failures, auditService)
+		msgScheduler.PauseWhenUnhealthy(autoPause)
+	}
+
+	var failoverHandler *handler.FailoverHandler
+	if elector != nil {
+		failoverHandler = handler.NewFailoverHandler(elector)
+	}
+
+	logLevelHandler := handler.NewLogLevelHandler()
+
+	var deliveryHandler *handler.DeliveryHandler
+	if cfg.Receipts.Secret != "" {
+		deliveryHandler = handler.NewDeliveryHandler(messageService, cfg.Receipts.Secret, cfg.Receipts.Tolerance)
+	}
+
+	tenantService := service.NewTenantService(tenantRepo)
+	tenantHandler := handler.NewTenantHandler(tenantService)
+	webhookDestinationHandler := handler.NewWebhookDestinationHandler(
+		service.NewWebhookDestinationService(webhookDestinationRepo, tenantRepo),
 	)
+	errorMappingHandler := handler.NewProviderErrorMappingHandler(errorMappingService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	campaignService := service.NewCampaignService(
+		persistence.NewCampaignRepositoryGorm(db.DB()),
+		messageRepo,
+		messageService,
+		auditService,
+	)
+	campaignHandler := handler.NewCampaignHandler(campaignService)
+	blacklistHandler := handler.NewBlacklistHandler(blacklistService)
+	contactHandler := handler.NewContactHandler(service.NewContactService(
+		persistence.NewContactRepositoryGorm(db.DB()),
+		persistence.NewContactGroupRepositoryGorm(db.DB()),
+		messageService,
+	))
+	analyticsHandler := handler.NewAnalyticsHandler(service.NewAnalyticsService(messageRepo))
+
+	var graphqlHandler *handler.GraphQLHandler
+	if cfg.GraphQL.Enabled {
+		graphqlHandler = handler.NewGraphQLHandler(messageService, schedulerHandler)
+	}
+
+	var retentionJob *retention.Job
+	var retentionHandler *handler.RetentionHandler
+	if cfg.Retention.Enabled {
+		retentionJob, err = retention.NewJob(messageRepo, auditService, &cfg.Retention)
+		if err != nil {
+			return fmt.Errorf("invalid RETENTION_POLICIES: %w", err)
+		}
+		retentionHandler = handler.NewRetentionHandler(retentionJob)
+	}
+
+	var archiveJob *archive.Job
+	var archiveHandler *handler.ArchiveHandler
+	if cfg.Archive.Enabled {
+		store, err := archive.NewStore(context.Background(), &cfg.Archive)
+		if err != nil {
+			return fmt.Errorf("failed to create archive store: %w", err)
+		}
+		archiveJob, err = archive.NewJob(messageRepo, persistence.NewMessageArchiveRepositoryGorm(db.DB()), store, auditService, &cfg.Archive)
+		if err != nil {
+			return fmt.Errorf("invalid ARCHIVE_STATUSES: %w", err)
+		}
+		archiveHandler = handler.NewArchiveHandler(archiveJob)
+	}
 
-	messageHandler := handler.NewMessageHandler(messageService)
-	schedulerHandler := handler.NewSchedulerHandler(msgScheduler)
-	healthHandler := handler.NewHealthHandler(db, redisCache)
+	var jwtVerifier *jwt.Verifier
+	if cfg.App.JWTSecret != "" {
+		jwtVerifier = jwt.NewVerifier(cfg.App.JWTSecret, cfg.App.JWTIssuer)
+	}
 
-	r := router.NewRouter(messageHandler, schedulerHandler, healthHandler, cfg.App.APIToken)
+	r := router.NewRouter(
+		messageHandler,
+		schedulerHandler,
+		healthHandler,
+		failoverHandler,
+		logLevelHandler,
+		tenantHandler,
+		deliveryHandler,
+		auditHandler,
+		campaignHandler,
+		retentionHandler,
+		archiveHandler,
+		blacklistHandler,
+		webhookDestinationHandler,
+		errorMappingHandler,
+		contactHandler,
+		analyticsHandler,
+		graphqlHandler,
+		tenantService,
+		cfg.App.APIToken,
+		cfg.App.AdminAPIToken,
+		jwtVerifier,
+		cfg.App.MetricsEnabled,
+		middleware.RequestDeadlines{
+			Read:  cfg.App.ReadDeadline,
+			Write: cfg.App.WriteDeadline,
+			Bulk:  cfg.App.BulkDeadline,
+		},
+		cfg.App.MaxBodyBytes,
+	)
 	engine := r.Setup()
 
 	srv := &http.Server{
@@ -112,27 +511,167 @@ func run() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	messageIntake.Start(ctx)
+	defer messageIntake.Stop()
+
+	var kafkaConsumer *kafka.Consumer
+	if cfg.Kafka.Enabled {
+		kafkaConsumer = kafka.NewConsumer(&cfg.Kafka, messageService)
+		kafkaConsumer.Start(ctx)
+	}
+
+	if cfg.Redis.WarmOnStart && redisCache != nil {
+		warmer := service.NewCacheWarmer(messageRepo, messageCache, cfg.Redis.WarmLimit, cfg.Redis.WarmMaxAge)
+		go func() {
+			if _, err := warmer.Run(ctx); err != nil {
+				logger.Get().Warn("cache warm start failed", zap.Error(err))
+			}
+		}()
+	}
+
+	if dbMonitor != nil {
+		dbMonitor.Start(ctx)
+	}
+	if elector != nil {
+		elector.Start(ctx)
+	}
+	if leaderElector != nil {
+		leaderElector.Start(ctx)
+	}
+
+	if callbackNotifier != nil {
+		callbackNotifier.Start(ctx)
+	}
+
 	if err := msgScheduler.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start scheduler: %w", err)
 	}
 
+	if reaper != nil {
+		reaper.Start(ctx)
+	}
+
+	if autoPause != nil {
+		autoPause.Start(ctx)
+	}
+
+	configWatcher.Start(ctx)
+
+	if retentionJob != nil {
+		retentionJob.Start(ctx)
+	}
+	if archiveJob != nil {
+		archiveJob.Start(ctx)
+	}
+
+	var outboxRelay *outbox.Relay
+	if cfg.Outbox.Enabled && cfg.Outbox.InProcessRelay {
+		publisher, err := outbox.NewPublisher(&cfg.Outbox, &cfg.Kafka, redisCache)
+		if err != nil {
+			return fmt.Errorf("failed to create outbox publisher: %w", err)
+		}
+		outboxRelay = outbox.NewRelay(persistence.NewOutboxRepositoryGorm(db.DB()), publisher, &cfg.Outbox)
+		outboxRelay.Start(ctx)
+	}
+
+	var grpcSrv *grpcserver.Server
+	if cfg.GRPC.Enabled {
+		grpcSrv = grpcserver.NewServer(&cfg.GRPC, db, redisCache, msgScheduler)
+		if err := grpcSrv.Start(); err != nil {
+			return fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+	}
+
+	listener, err := graceful.Listen(srv.Addr, cfg.App.ReusePort)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", cfg.App.Port, err)
+	}
+
 	go func() {
-		logger.Get().Info("starting HTTP server", zap.String("port", cfg.App.Port))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Get().Info("starting HTTP server",
+			zap.String("port", cfg.App.Port),
+			zap.Bool("inherited_listener", graceful.Inherited()),
+		)
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
 			logger.Get().Fatal("failed to start server", zap.Error(err))
 		}
 	}()
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	// SIGHUP hands the listening socket to a freshly exec'd binary and then
+	// drains this process through the normal shutdown path below
+	for sig := range quit {
+		if sig != syscall.SIGHUP {
+			break
+		}
+
+		process, err := graceful.Upgrade(listener)
+		if err != nil {
+			logger.Get().Error("binary upgrade failed, continuing with current process", zap.Error(err))
+			continue
+		}
+
+		logger.Get().Info("started upgraded process, draining current one", zap.Int("new_pid", process.Pid))
+		break
+	}
 
 	logger.Get().Info("shutting down application...")
 
+	// Stop taking new records first; uncommitted ones are redelivered to
+	// whichever consumer picks up the partition
+	if kafkaConsumer != nil {
+		kafkaConsumer.Stop()
+	}
+
 	if err := msgScheduler.Stop(); err != nil {
 		logger.Get().Error("error stopping scheduler", zap.Error(err))
 	}
 
+	if reaper != nil {
+		reaper.Stop()
+	}
+
+	// After the scheduler, so the last cycle's callbacks are queued first
+	if callbackNotifier != nil {
+		callbackNotifier.Stop()
+	}
+
+	if autoPause != nil {
+		autoPause.Stop()
+	}
+
+	configWatcher.Stop()
+
+	if retentionJob != nil {
+		retentionJob.Stop()
+	}
+	if archiveJob != nil {
+		archiveJob.Stop()
+	}
+
+	// Runs after the scheduler so events from its last cycle are published
+	if outboxRelay != nil {
+		outboxRelay.Stop()
+	}
+
+	// Release the lease only after dispatch has stopped so the other region
+	// can take over immediately instead of waiting for expiry
+	if elector != nil {
+		elector.Stop()
+	}
+	if leaderElector != nil {
+		leaderElector.Stop()
+	}
+	if dbMonitor != nil {
+		dbMonitor.Stop()
+	}
+
+	if grpcSrv != nil {
+		grpcSrv.Stop()
+	}
+
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.App.GracefulShutdownTimeout)
 	defer shutdownCancel()
 
@@ -144,3 +683,41 @@ func run() error {
 	logger.Get().Info("application stopped gracefully")
 	return nil
 }
+
+// subscribeConfigChanges applies the settings a config reload may change:
+// the log level, the scheduler's batch size, worker count and interval, and
+// the providers' rate limit. The interval stays as it is while a cron
+// schedule is in use.
+func subscribeConfigChanges(watcher *config.Watcher, msgScheduler *scheduler.Scheduler, rateLimited []infrahttp.RateLimited) {
+	watcher.Subscribe(func(previous, current *config.Config) {
+		if current.App.LogLevel != previous.App.LogLevel {
+			if err := logger.SetLevel(current.App.LogLevel); err != nil {
+				logger.Get().Warn("ignoring reloaded LOG_LEVEL", zap.Error(err))
+			}
+		}
+
+		var batchSize, workerCount *int
+		var interval *time.Duration
+		if current.Message.BatchSize != previous.Message.BatchSize {
+			batchSize = &current.Message.BatchSize
+		}
+		if current.Message.WorkerCount != previous.Message.WorkerCount {
+			workerCount = &current.Message.WorkerCount
+		}
+		if current.Message.IntervalSeconds != previous.Message.IntervalSeconds && current.Message.Schedule == "" {
+			d := time.Duration(current.Message.IntervalSeconds) * time.Second
+			interval = &d
+		}
+		if batchSize != nil || workerCount != nil || interval != nil {
+			if err := msgScheduler.Reconfigure(batchSize, workerCount, interval); err != nil {
+				logger.Get().Warn("failed to apply reloaded scheduler settings", zap.Error(err))
+			}
+		}
+
+		if current.Webhook.RateLimitPerSecond != previous.Webhook.RateLimitPerSecond {
+			for _, limited := range rateLimited {
+				limited.SetRateLimit(current.Webhook.RateLimitPerSecond)
+			}
+		}
+	})
+}
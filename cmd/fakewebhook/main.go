@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/http/fakewebhook"
+)
+
+func main() {
+	var (
+		addr         = flag.String("addr", ":8090", "Address to listen on")
+		latency      = flag.Duration("latency", 50*time.Millisecond, "Latency added to every response")
+		jitter       = flag.Duration("jitter", 50*time.Millisecond, "Random latency added on top, up to this much")
+		errorRate    = flag.Float64("error-rate", 0, "Share of requests answered 500")
+		throttleRate = flag.Float64("throttle-rate", 0, "Share of requests answered 429")
+		retryAfter   = flag.Duration("retry-after", time.Second, "Retry-After sent with 429 responses")
+		rejectRate   = flag.Float64("reject-rate", 0, "Share of requests answered 400")
+		hangRate     = flag.Float64("hang-rate", 0, "Share of requests left unanswered to trigger client timeouts")
+		hangDuration = flag.Duration("hang-duration", time.Minute, "How long an unanswered request is held")
+		maxPerSecond = flag.Int("max-per-second", 0, "Requests per second above which 429 is answered; 0 allows any rate")
+		authKey      = flag.String("auth-key", "", "Expected x-ins-auth-key header; empty accepts any")
+		seed         = flag.Int64("seed", 0, "Seed for repeatable outcomes; 0 seeds from the clock")
+	)
+	flag.Parse()
+
+	server, err := fakewebhook.NewServer(fakewebhook.Config{
+		Latency:      *latency,
+		Jitter:       *jitter,
+		HangRate:     *hangRate,
+		HangDuration: *hangDuration,
+		ThrottleRate: *throttleRate,
+		RetryAfter:   *retryAfter,
+		ErrorRate:    *errorRate,
+		RejectRate:   *rejectRate,
+		MaxPerSecond: *maxPerSecond,
+		AuthKey:      *authKey,
+		Seed:         *seed,
+	})
+	if err != nil {
+		log.Fatalf("Invalid settings: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Fake webhook listening on %s (error %.2f, throttle %.2f, reject %.2f, hang %.2f)",
+			*addr, *errorRate, *throttleRate, *rejectRate, *hangRate)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to serve: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown did not finish cleanly: %v", err)
+	}
+
+	stats := server.Stats()
+	log.Printf("Served %d requests: %d accepted, %d throttled, %d server errors, %d rejected, %d hung, %d unauthorized, %d bad requests",
+		stats.Requests, stats.Accepted, stats.Throttled, stats.ServerErrors, stats.Rejected, stats.Hung, stats.Unauthorized, stats.BadRequests)
+}
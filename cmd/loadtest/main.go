@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+)
+
+type settings struct {
+	baseURL     string
+	token       string
+	wait        bool
+	waitTimeout time.Duration
+	content     string
+	client      *http.Client
+}
+
+// results collects what the requests of a run measured. Create latencies
+// cover POST /api/v1/messages; delivery latencies run from the start of that
+// request until the message reached a terminal status.
+type results struct {
+	mu               sync.Mutex
+	createLatencies  []time.Duration
+	deliverLatencies []time.Duration
+	createErrors     map[string]int
+	outcomes         map[string]int
+	unfinished       int
+	skipped          int
+	lastDelivered    time.Time
+}
+
+func main() {
+	var (
+		baseURL     = flag.String("url", "http://localhost:8080", "Base URL of the API")
+		token       = flag.String("token", os.Getenv("LOADTEST_TOKEN"), "Bearer token with the operator or admin role (default $LOADTEST_TOKEN)")
+		rps         = flag.Int("rps", 50, "Messages created per second")
+		duration    = flag.Duration("duration", 30*time.Second, "How long messages are created for")
+		concurrency = flag.Int("concurrency", 200, "Most messages in flight at once; ticks beyond it are skipped and counted")
+		wait        = flag.Bool("wait", true, "Follow every message until it is sent or failed, to measure processing")
+		waitTimeout = flag.Duration("wait-timeout", 5*time.Minute, "How long a message is followed before it counts as unfinished")
+		content     = flag.String("content", "Load test message", "Content of the messages")
+	)
+	flag.Parse()
+
+	if *token == "" {
+		log.Fatal("-token or LOADTEST_TOKEN is required")
+	}
+	if *rps <= 0 || *duration <= 0 || *concurrency <= 0 {
+		log.Fatal("-rps, -duration and -concurrency must be positive")
+	}
+
+	s := &settings{
+		baseURL:     strings.TrimRight(*baseURL, "/"),
+		token:       *token,
+		wait:        *wait,
+		waitTimeout: *waitTimeout,
+		content:     *content,
+		client: &http.Client{
+			Timeout: 90 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:        *concurrency,
+				MaxIdleConnsPerHost: *concurrency,
+			},
+		},
+	}
+	r := &results{
+		createErrors: make(map[string]int),
+		outcomes:     make(map[string]int),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	log.Printf("Creating %d messages/s for %s against %s", *rps, *duration, s.baseURL)
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(*rps))
+	defer ticker.Stop()
+	deadline := time.After(*duration)
+	slots := make(chan struct{}, *concurrency)
+	var wg sync.WaitGroup
+
+	sequence := 0
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline:
+			break loop
+		case <-ticker.C:
+			select {
+			case slots <- struct{}{}:
+			default:
+				r.mu.Lock()
+				r.skipped++
+				r.mu.Unlock()
+				continue
+			}
+			sequence++
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				s.run(ctx, r, n)
+			}(sequence)
+		}
+	}
+	createPhase := time.Since(start)
+
+	if s.wait {
+		log.Printf("Waiting for %d messages in flight to finish", len(slots))
+	}
+	wg.Wait()
+
+	r.report(createPhase, start, s.wait)
+}
+
+// run creates one message and, when waiting, follows it to a terminal
+// status.
+func (s *settings) run(ctx context.Context, r *results, n int) {
+	begin := time.Now()
+	id, err := s.create(ctx, n)
+	elapsed := time.Since(begin)
+
+	r.mu.Lock()
+	if err != nil {
+		r.createErrors[err.Error()]++
+	} else {
+		r.createLatencies = append(r.createLatencies, elapsed)
+	}
+	r.mu.Unlock()
+	if err != nil || !s.wait {
+		return
+	}
+
+	status, err := s.follow(ctx, id)
+	finished := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.unfinished++
+		return
+	}
+	r.outcomes[status]++
+	r.deliverLatencies = append(r.deliverLatencies, finished.Sub(begin))
+	if finished.After(r.lastDelivered) {
+		r.lastDelivered = finished
+	}
+}
+
+func (s *settings) create(ctx context.Context, n int) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		// Spread the messages over recipients so recipient limits do not
+		// throttle the run
+		"phone_number": fmt.Sprintf("+90555%07d", n%10000000),
+		"content":      s.content,
+	})
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	status, err := s.do(ctx, http.MethodPost, "/api/v1/messages", bytes.NewReader(body), &created)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusCreated {
+		return "", fmt.Errorf("HTTP %d", status)
+	}
+	return created.ID, nil
+}
+
+// follow long-polls the message until it reaches a terminal status or
+// waitTimeout passes.
+func (s *settings) follow(ctx context.Context, id string) (string, error) {
+	deadline := time.Now().Add(s.waitTimeout)
+	for time.Now().Before(deadline) {
+		var message struct {
+			Status string `json:"status"`
+		}
+		status, err := s.do(ctx, http.MethodGet, "/api/v1/messages/"+id+"/wait?timeout=60s", nil, &message)
+		if err != nil {
+			return "", err
+		}
+		if status != http.StatusOK {
+			return "", fmt.Errorf("HTTP %d", status)
+		}
+		if valueobject.MessageStatus(message.Status).IsTerminal() {
+			return message.Status, nil
+		}
+	}
+	return "", fmt.Errorf("not finished within %s", s.waitTimeout)
+}
+
+func (s *settings) do(ctx context.Context, method, path string, body io.Reader, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("interrupted")
+		}
+		return 0, fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("unreadable response")
+	}
+	return resp.StatusCode, nil
+}
+
+func (r *results) report(createPhase time.Duration, start time.Time, waited bool) {
+	created := len(r.createLatencies)
+	failed := 0
+	for _, count := range r.createErrors {
+		failed += count
+	}
+
+	fmt.Println()
+	fmt.Printf("Create    %d ok, %d failed, %d skipped in %s (%.1f/s)\n",
+		created, failed, r.skipped, createPhase.Round(time.Millisecond), float64(created)/createPhase.Seconds())
+	printLatencies(r.createLatencies)
+	printCounts("  errors", r.createErrors)
+
+	if !waited {
+		return
+	}
+
+	finished := len(r.deliverLatencies)
+	throughput := 0.0
+	if finished > 0 {
+		throughput = float64(finished) / r.lastDelivered.Sub(start).Seconds()
+	}
+	fmt.Printf("Process   %d finished, %d unfinished (%.1f/s)\n", finished, r.unfinished, throughput)
+	printLatencies(r.deliverLatencies)
+	printCounts("  statuses", r.outcomes)
+}
+
+func printLatencies(latencies []time.Duration) {
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Printf("  latency p50 %s  p95 %s  p99 %s  max %s\n",
+		percentile(latencies, 50), percentile(latencies, 95), percentile(latencies, 99),
+		latencies[len(latencies)-1].Round(time.Millisecond))
+}
+
+// percentile takes the nearest rank of sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Round(time.Millisecond)
+}
+
+func printCounts(label string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", key, counts[key])
+	}
+	fmt.Printf("%s %s\n", label, strings.Join(parts, " "))
+}
@@ -1,21 +1,17 @@
 package main
 
 import (
-	"database/sql"
 	"flag"
-	"fmt"
 	"log"
 
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
 	"github.com/eneskaya/insider-messaging/pkg/config"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq"
 )
 
 func main() {
 	var (
-		migrationsPath = flag.String("path", "migrations", "Path to migration files")
+		migrationsPath = flag.String("path", "", "Path to migration files (default the migrations built into the binary, the sqlite set with DB_DRIVER=sqlite)")
 		command        = flag.String("cmd", "up", "Migration command: up, down, version, force")
 		steps          = flag.Int("steps", -1, "Number of migrations to run (for down command)")
 		version        = flag.Int("version", -1, "Force version (for force command)")
@@ -29,32 +25,14 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	db, err := sql.Open("postgres", cfg.Database.DSN())
+	m, err := persistence.NewMigrate(&cfg.Database, *migrationsPath)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-	defer db.Close()
-
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+		log.Fatalf("Failed to create migrate instance: %v", err)
 	}
+	defer m.Close()
 
 	log.Println("Connected to database successfully")
 
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		log.Fatalf("Failed to create migration driver: %v", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", *migrationsPath),
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		log.Fatalf("Failed to create migrate instance: %v", err)
-	}
-
 	switch *command {
 	case "up":
 		log.Println("Running migrations up...")
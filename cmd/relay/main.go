@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/outbox"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// relay publishes the outbox table to OUTBOX_BROKER on its own, so that work
+// can be deployed and scaled apart from the API. Run the API with
+// OUTBOX_IN_PROCESS_RELAY=false next to it. Several relays may run at once;
+// the outbox lock lets one of them publish at a time and the others take
+// over when it stops.
+func main() {
+	metricsAddr := flag.String("metrics-addr", ":9091", "Address to serve /metrics on (empty disables it)")
+	flag.Parse()
+
+	if err := run(*metricsAddr); err != nil {
+		fmt.Fprintf(os.Stderr, "Relay error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(metricsAddr string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.Outbox.Enabled {
+		return errors.New("OUTBOX_ENABLED must be true to run the relay")
+	}
+
+	if err := logger.Init(cfg.App.LogLevel); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	defer logger.Sync()
+
+	logger.Get().Info("starting outbox relay",
+		zap.String("env", cfg.App.Env),
+		zap.String("broker", cfg.Outbox.Broker),
+	)
+
+	db, err := persistence.NewGormDB(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	var redisCache *cache.RedisCache
+	if cfg.Outbox.Broker == "redis" {
+		redisCache, err = cache.NewRedisCache(&cfg.Redis)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		defer redisCache.Close()
+	}
+
+	publisher, err := outbox.NewPublisher(&cfg.Outbox, &cfg.Kafka, redisCache)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox publisher: %w", err)
+	}
+
+	var metricsSrv *http.Server
+	if metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{Addr: metricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			logger.Get().Info("serving relay metrics", zap.String("addr", metricsAddr))
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Get().Error("metrics server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	relay := outbox.NewRelay(persistence.NewOutboxRepositoryGorm(db.DB()), publisher, &cfg.Outbox)
+	relay.Start(ctx)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Get().Info("shutting down outbox relay...")
+
+	// Stop drains what is left before closing the publisher
+	relay.Stop()
+
+	if metricsSrv != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Get().Error("error stopping metrics server", zap.Error(err))
+		}
+	}
+
+	return nil
+}
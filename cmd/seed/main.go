@@ -2,104 +2,76 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
-	"math/rand"
 	"time"
 
-	"github.com/eneskaya/insider-messaging/internal/domain/entity"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/fixtures"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
 	"github.com/eneskaya/insider-messaging/pkg/config"
 )
 
-var (
-	phoneNumbers = []string{
-		"+905551111111", "+905552222222", "+905553333333", "+905554444444",
-		"+905555555555", "+905556666666", "+905557777777", "+905558888888",
-		"+905559999999", "+905550000000",
-	}
-
-	messageTemplates = []string{
-		"Welcome to Insider! Your journey starts here.",
-		"Special offer just for you! Check your account.",
-		"Your verification code is: %d",
-		"Thank you for choosing Insider!",
-		"Limited time offer - Don't miss out!",
-		"Your order has been confirmed.",
-		"Meeting reminder: Team sync at %s",
-		"System update completed successfully.",
-		"New features available in your account.",
-		"Your subscription has been renewed.",
-		"Important: Security update required.",
-		"Flash sale starts in 1 hour!",
-		"Your feedback matters to us.",
-		"Weekly summary: %d new updates",
-		"Reminder: Complete your profile",
-	}
-)
-
 func main() {
-	log.Println("Starting database seeding...")
-
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	db, err := persistence.NewPostgresDB(&cfg.Database)
+	var (
+		count        = flag.Int("count", cfg.Seed.MessageCount, "Messages to create")
+		distribution = flag.String("distribution", cfg.Seed.StatusDistribution, "Status mix as status=weight pairs, such as sent=70,pending=20,failed=10")
+		tenantCount  = flag.Int("tenants", cfg.Seed.Tenants, "Tenants to create and spread the messages across; 0 leaves them unscoped")
+		history      = flag.Duration("history", cfg.Seed.History, "How far back the oldest message is created")
+		seed         = flag.Int64("seed", int64(cfg.Seed.RandomSeed), "Seed for repeatable data; 0 seeds from the clock")
+	)
+	flag.Parse()
+
+	statuses, err := fixtures.ParseDistribution(*distribution)
+	if err != nil {
+		log.Fatalf("Invalid status distribution: %v", err)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	db, err := persistence.NewGormDB(&cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
-	repo := persistence.NewMessageRepositoryPostgres(db.DB(), cfg.Message.CharLimit)
-
 	ctx := context.Background()
-	messageCount := cfg.Seed.MessageCount
-
-	log.Printf("Creating %d test messages...", messageCount)
-
-	rand.Seed(time.Now().UnixNano())
-
-	successCount := 0
-	for i := 0; i < messageCount; i++ {
-		phoneNumber := phoneNumbers[rand.Intn(len(phoneNumbers))]
-		messageTemplate := messageTemplates[rand.Intn(len(messageTemplates))]
-
-		content := fmt.Sprintf(messageTemplate, rand.Intn(10000))
-		if len(content) > cfg.Message.CharLimit {
-			content = content[:cfg.Message.CharLimit]
-		}
-
-		phone, err := valueobject.NewPhoneNumber(phoneNumber)
-		if err != nil {
-			log.Printf("Failed to create phone number: %v", err)
-			continue
-		}
+	now := time.Now()
+	charLimit := valueobject.ContentCharLimit(cfg.Message.CharLimit, cfg.Message.MaxSegments)
+	repo := persistence.NewMessageRepositoryGorm(db.DB(), charLimit, false, cfg.Message.LowerPriorityShare)
 
-		messageContent, err := valueobject.NewMessageContent(content, cfg.Message.CharLimit)
-		if err != nil {
-			log.Printf("Failed to create message content: %v", err)
-			continue
-		}
-
-		message, err := entity.NewMessage(phone, messageContent, cfg.Message.MaxRetries)
-		if err != nil {
-			log.Printf("Failed to create message entity: %v", err)
-			continue
-		}
+	tenants := fixtures.GenerateTenants(*tenantCount, *seed, now)
+	if err := fixtures.InsertTenants(ctx, persistence.NewTenantRepositoryGorm(db.DB()), tenants); err != nil {
+		log.Fatalf("Failed to create tenants: %v", err)
+	}
+	for _, tenant := range tenants {
+		log.Printf("Tenant %s (%s), API key %s", tenant.Name, tenant.ID, tenant.APIKey)
+	}
 
-		if err := repo.Create(ctx, message); err != nil {
-			log.Printf("Failed to save message: %v", err)
-			continue
-		}
+	messages, err := fixtures.Generate(fixtures.Options{
+		Count:        *count,
+		Distribution: statuses,
+		Tenants:      fixtures.TenantIDs(tenants),
+		History:      *history,
+		Now:          now,
+		Seed:         *seed,
+		CharLimit:    charLimit,
+		MaxAttempts:  cfg.Message.MaxRetries,
+	})
+	if err != nil {
+		log.Fatalf("Failed to generate messages: %v", err)
+	}
 
-		successCount++
-		if (i+1)%10 == 0 {
-			log.Printf("Progress: %d/%d messages created", successCount, messageCount)
-		}
+	log.Printf("Creating %d messages (%s) over the last %s with seed %d...", len(messages), statuses, *history, *seed)
+	if err := fixtures.Insert(ctx, repo, messages); err != nil {
+		log.Fatalf("Failed to store messages: %v", err)
 	}
 
-	log.Printf("Seeding completed! Successfully created %d/%d messages", successCount, messageCount)
+	log.Printf("Seeding completed! Created %d messages", len(messages))
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/jwt"
+)
+
+func main() {
+	var (
+		subject = flag.String("sub", "", "Who the token is for, recorded as the actor in the audit log")
+		role    = flag.String("role", jwt.RoleReadOnly, "Role: admin, operator or read-only")
+		ttl     = flag.Duration("ttl", 24*time.Hour, "How long the token is valid")
+	)
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.App.JWTSecret == "" {
+		log.Fatal("JWT_SECRET is not set")
+	}
+	if *subject == "" {
+		log.Fatal("-sub is required")
+	}
+	if !jwt.ValidRole(*role) {
+		log.Fatalf("Unknown role %q", *role)
+	}
+	if *ttl <= 0 {
+		log.Fatal("-ttl must be positive")
+	}
+
+	now := time.Now()
+	token, err := jwt.Sign(cfg.App.JWTSecret, jwt.Claims{
+		Subject:   *subject,
+		Role:      *role,
+		Issuer:    cfg.App.JWTIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(*ttl).Unix(),
+	})
+	if err != nil {
+		log.Fatalf("Failed to sign token: %v", err)
+	}
+
+	fmt.Println(token)
+}
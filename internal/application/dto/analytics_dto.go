@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// DeliveryAnalyticsRequest selects the range to report on; From is inclusive
+// and To exclusive, both on the creation time. PrefixDigits is how many
+// digits after the country code make a number prefix. Prefixes with fewer
+// than MinMessages messages are left out.
+type DeliveryAnalyticsRequest struct {
+	From         *time.Time `form:"from"`
+	To           *time.Time `form:"to"`
+	PrefixDigits int        `form:"prefix_digits"`
+	MinMessages  int        `form:"min_messages"`
+	Limit        int        `form:"limit"`
+}
+
+// DeliveryCounts counts messages by outcome. Sent includes delivered
+// messages and Failed undelivered ones. The ratios are shares of the
+// messages that were either sent or failed, so pending messages do not
+// lower them; both are 0 when there are none.
+type DeliveryCounts struct {
+	Total       int64   `json:"total"`
+	Sent        int64   `json:"sent"`
+	Delivered   int64   `json:"delivered"`
+	Failed      int64   `json:"failed"`
+	SentRatio   float64 `json:"sent_ratio"`
+	FailedRatio float64 `json:"failed_ratio"`
+}
+
+type CountryDelivery struct {
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region"`
+	DeliveryCounts
+}
+
+// PrefixDelivery is the delivery of numbers starting with Prefix, such as
+// "+90555". Carrier is the carrier the prefix's numbers were assigned to,
+// taken from one of them; numbers can be ported, so it is only a hint.
+type PrefixDelivery struct {
+	Prefix      string `json:"prefix"`
+	CountryCode string `json:"country_code"`
+	Region      string `json:"region"`
+	Carrier     string `json:"carrier,omitempty"`
+	DeliveryCounts
+}
+
+type DeliveryAnalyticsResponse struct {
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	PrefixDigits int               `json:"prefix_digits"`
+	Countries    []CountryDelivery `json:"countries"`
+	Prefixes     []PrefixDelivery  `json:"prefixes"`
+}
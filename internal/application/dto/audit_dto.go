@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type ListAuditLogRequest struct {
+	Action       string     `form:"action"`
+	Actor        string     `form:"actor"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	RequestID    string     `form:"request_id"`
+	After        *time.Time `form:"after"`
+	Before       *time.Time `form:"before"`
+	Page         int        `form:"page"`
+	PageSize     int        `form:"page_size"`
+}
+
+type AuditLogEntryResponse struct {
+	ID           string    `json:"id"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	TenantID     string    `json:"tenant_id,omitempty"`
+	Actor        string    `json:"actor"`
+	RequestID    string    `json:"request_id,omitempty"`
+	FromStatus   string    `json:"from_status,omitempty"`
+	ToStatus     string    `json:"to_status,omitempty"`
+	Details      string    `json:"details,omitempty"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+type AuditLogListResponse struct {
+	Entries    []AuditLogEntryResponse `json:"entries"`
+	TotalCount int                     `json:"total_count"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+}
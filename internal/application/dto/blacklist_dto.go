@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type CreateBlacklistEntryRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required" example:"+905551234567"`
+	Reason      string `json:"reason,omitempty" example:"replied STOP"`
+}
+
+type BlacklistEntryResponse struct {
+	ID          string    `json:"id"`
+	PhoneNumber string    `json:"phone_number"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type BlacklistListResponse struct {
+	Entries    []BlacklistEntryResponse `json:"entries"`
+	TotalCount int                      `json:"total_count"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+}
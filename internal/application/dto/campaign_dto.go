@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// CreateCampaignRequest creates the campaign and, optionally, its first
+// batch of messages.
+type CreateCampaignRequest struct {
+	Name     string                 `json:"name" binding:"required"`
+	Messages []CreateMessageRequest `json:"messages,omitempty"`
+}
+
+type AddCampaignMessagesRequest struct {
+	Messages []CreateMessageRequest `json:"messages" binding:"required"`
+}
+
+type CampaignResponse struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Status    string                `json:"status" enums:"active,paused"`
+	TenantID  string                `json:"tenant_id,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Stats     *MessageStatsResponse `json:"stats,omitempty"`
+}
+
+type CampaignListResponse struct {
+	Campaigns  []CampaignResponse `json:"campaigns"`
+	TotalCount int                `json:"total_count"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+}
+
+// CampaignMessageError reports why the message at Index of the request was
+// not created; the other messages of the batch are unaffected.
+type CampaignMessageError struct {
+	Index int    `json:"index"`
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+type CampaignBatchResponse struct {
+	Campaign CampaignResponse       `json:"campaign"`
+	Created  []MessageResponse      `json:"created"`
+	Errors   []CampaignMessageError `json:"errors,omitempty"`
+}
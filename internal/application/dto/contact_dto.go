@@ -0,0 +1,111 @@
+package dto
+
+import "time"
+
+// CreateContactRequest needs at least one of phone_number, email and
+// device_token.
+type CreateContactRequest struct {
+	Name        string `json:"name,omitempty" example:"Jane Doe"`
+	PhoneNumber string `json:"phone_number,omitempty" example:"+905551234567"`
+	Email       string `json:"email,omitempty" example:"jane@example.com"`
+	DeviceToken string `json:"device_token,omitempty"`
+}
+
+// UpdateContactRequest changes only the fields that are set; an empty
+// string clears an address.
+type UpdateContactRequest struct {
+	Name        *string `json:"name,omitempty"`
+	PhoneNumber *string `json:"phone_number,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	DeviceToken *string `json:"device_token,omitempty"`
+}
+
+type ContactResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name,omitempty"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	DeviceToken string    `json:"device_token,omitempty"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ContactListResponse struct {
+	Contacts   []ContactResponse `json:"contacts"`
+	TotalCount int               `json:"total_count"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+}
+
+type CreateGroupRequest struct {
+	Name string `json:"name" binding:"required" example:"newsletter"`
+}
+
+type UpdateGroupRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type GroupResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	MemberCount int64     `json:"member_count"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type GroupListResponse struct {
+	Groups     []GroupResponse `json:"groups"`
+	TotalCount int             `json:"total_count"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+}
+
+type AddGroupMembersRequest struct {
+	ContactIDs []string `json:"contact_ids" binding:"required"`
+}
+
+// AddGroupMembersResponse counts the contacts added; contacts that were
+// already members are not counted.
+type AddGroupMembersResponse struct {
+	Added int64         `json:"added"`
+	Group GroupResponse `json:"group"`
+}
+
+type GroupMemberListResponse struct {
+	Members    []ContactResponse `json:"members"`
+	TotalCount int               `json:"total_count"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+}
+
+// SendToGroupRequest is a message sent to every member of a group, each at
+// the address of its channel. A client_reference is suffixed with
+// ":<contact_id>" so every member's message has its own.
+type SendToGroupRequest struct {
+	Channel         string            `json:"channel,omitempty" enums:"sms,email,push" default:"sms"`
+	Content         string            `json:"content" binding:"required"`
+	ScheduledAt     *time.Time        `json:"scheduled_at,omitempty"`
+	ExpiresAt       *time.Time        `json:"expires_at,omitempty"`
+	Priority        string            `json:"priority,omitempty" enums:"high,normal,low" default:"normal"`
+	ClientReference string            `json:"client_reference,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	DestinationID   string            `json:"destination_id,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+	CallbackURL     string            `json:"callback_url,omitempty"`
+}
+
+// GroupMessageError reports why the member's message was not created; the
+// other members are unaffected.
+type GroupMessageError struct {
+	ContactID string `json:"contact_id"`
+	Code      string `json:"code"`
+	Error     string `json:"error"`
+}
+
+type GroupSendResponse struct {
+	GroupID string              `json:"group_id"`
+	Created []MessageResponse   `json:"created"`
+	Errors  []GroupMessageError `json:"errors,omitempty"`
+}
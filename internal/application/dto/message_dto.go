@@ -1,24 +1,211 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type CreateMessageRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required"`
-	Content     string `json:"content" binding:"required"`
+	Channel string `json:"channel,omitempty" enums:"sms,email,push" default:"sms"`
+	// PhoneNumber is required for SMS; email and push messages go to
+	// Recipient, an email address or device token
+	PhoneNumber string     `json:"phone_number,omitempty"`
+	Recipient   string     `json:"recipient,omitempty"`
+	Content     string     `json:"content" binding:"required"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Priority    string     `json:"priority,omitempty" enums:"high,normal,low" default:"normal"`
+	// ClientReference doubles as the idempotency key; the Idempotency-Key
+	// header is copied here by the handler
+	ClientReference string `json:"client_reference,omitempty"`
+	// Metadata is forwarded to the webhook with the message
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// CampaignID is set by the campaign service, which checks the campaign
+	// belongs to the caller; clients add messages through the campaign API
+	CampaignID uuid.UUID `json:"-" swaggerignore:"true"`
+	// DestinationID sends the message to a webhook destination managed
+	// through the admin API instead of the configured provider
+	DestinationID string `json:"destination_id,omitempty"`
+	// DryRun takes the message through every step but the provider call,
+	// which is simulated as a success
+	DryRun bool `json:"dry_run,omitempty"`
+	// CallbackURL is an https URL on a public host that receives a signed
+	// POST with the message's final status once it is sent or fails
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 type MessageResponse struct {
 	ID               string     `json:"id"`
-	PhoneNumber      string     `json:"phone_number"`
+	Channel          string     `json:"channel"`
+	PhoneNumber      string     `json:"phone_number,omitempty"`
+	Recipient        string     `json:"recipient"`
 	Content          string     `json:"content"`
+	ContentHash      string     `json:"content_hash"`
+	Encoding         string     `json:"encoding"`
+	Segments         int        `json:"segments"`
 	Status           string     `json:"status"`
 	CreatedAt        time.Time  `json:"created_at"`
 	SentAt           *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	ScheduledAt      *time.Time `json:"scheduled_at,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	NextRetryAt      *time.Time `json:"next_retry_at,omitempty"`
 	Attempts         int        `json:"attempts"`
 	MaxAttempts      int        `json:"max_attempts"`
 	LastError        string     `json:"last_error,omitempty"`
 	ErrorCode        string     `json:"error_code,omitempty"`
 	WebhookMessageID string     `json:"webhook_message_id,omitempty"`
+	// ProviderResponse is the response body of the provider that accepted
+	// the message
+	ProviderResponse  json.RawMessage   `json:"provider_response,omitempty" swaggertype:"object"`
+	TraceID           string            `json:"trace_id,omitempty"`
+	ProviderRequestID string            `json:"provider_request_id,omitempty"`
+	ClientReference   string            `json:"client_reference,omitempty"`
+	Priority          string            `json:"priority"`
+	TenantID          string            `json:"tenant_id,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	CampaignID        string            `json:"campaign_id,omitempty"`
+	DestinationID     string            `json:"destination_id,omitempty"`
+	// CreatedBy names the caller that created the message, such as
+	// tenant:<id> for a tenant API key or jwt:<subject> for a JWT
+	CreatedBy string `json:"created_by,omitempty"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+	// Simulated is set once a dry-run send stood in for the provider
+	Simulated   bool   `json:"simulated,omitempty"`
+	CallbackURL string `json:"callback_url,omitempty"`
+	// SplitGroupID is shared by the parts of content that was split into
+	// several messages; PartNumber counts from 1 up to PartCount
+	SplitGroupID string `json:"split_group_id,omitempty"`
+	PartNumber   int    `json:"part_number,omitempty"`
+	PartCount    int    `json:"part_count,omitempty"`
+}
+
+// DeliveryReceiptRequest is the provider's report of the final delivery
+// outcome for a message it accepted earlier.
+type DeliveryReceiptRequest struct {
+	WebhookMessageID string     `json:"webhook_message_id" binding:"required"`
+	Status           string     `json:"status" binding:"required,oneof=delivered undelivered rejected"`
+	ErrorCode        string     `json:"error_code,omitempty"`
+	ErrorMessage     string     `json:"error_message,omitempty"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+}
+
+type RetryFailedRequest struct {
+	ErrorCode     string     `json:"error_code,omitempty"`
+	PhoneNumber   string     `json:"phone_number,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	ResetAttempts bool       `json:"reset_attempts"`
+}
+
+type RetryFailedResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+type DeadLetterResponse struct {
+	MessageID         string    `json:"message_id"`
+	PhoneNumber       string    `json:"phone_number"`
+	Content           string    `json:"content"`
+	Attempts          int       `json:"attempts"`
+	LastError         string    `json:"last_error,omitempty"`
+	ErrorCode         string    `json:"error_code,omitempty"`
+	TraceID           string    `json:"trace_id,omitempty"`
+	ProviderRequestID string    `json:"provider_request_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	DeadLetteredAt    time.Time `json:"dead_lettered_at"`
+}
+
+type DeadLetterListResponse struct {
+	Entries    []DeadLetterResponse `json:"entries"`
+	TotalCount int                  `json:"total_count"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+}
+
+// MessageAttemptResponse is one send attempt of a message. StatusCode is
+// left out when the provider did not answer.
+type MessageAttemptResponse struct {
+	Attempt           int             `json:"attempt"`
+	StartedAt         time.Time       `json:"started_at"`
+	DurationMs        int64           `json:"duration_ms"`
+	Status            string          `json:"status"`
+	StatusCode        int             `json:"status_code,omitempty"`
+	ErrorCode         string          `json:"error_code,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	ProviderResponse  json.RawMessage `json:"provider_response,omitempty" swaggertype:"object"`
+	ProviderRequestID string          `json:"provider_request_id,omitempty"`
+}
+
+type MessageAttemptListResponse struct {
+	MessageID string                   `json:"message_id"`
+	Attempts  []MessageAttemptResponse `json:"attempts"`
+}
+
+type AcceptedMessageResponse struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	StatusURL string `json:"status_url"`
+}
+
+// MessageImportResponse reports an import. Accepted includes rows whose
+// client_reference matched a message created earlier.
+type MessageImportResponse struct {
+	Accepted int              `json:"accepted"`
+	Rejected int              `json:"rejected"`
+	Errors   []ImportRowError `json:"errors,omitempty"`
+	// ErrorsTruncated is set when more rows were rejected than are listed
+	ErrorsTruncated bool `json:"errors_truncated,omitempty"`
+}
+
+// ImportRowError explains why the row on Line (1-based, counting the CSV
+// header) was not imported.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+type ListMessagesRequest struct {
+	Status        string     `form:"status"`
+	Channel       string     `form:"channel"`
+	PhoneNumber   string     `form:"phone_number"`
+	ErrorCode     string     `form:"error_code"`
+	CreatedAfter  *time.Time `form:"created_after"`
+	CreatedBefore *time.Time `form:"created_before"`
+	CampaignID    string     `form:"campaign_id"`
+	SplitGroupID  string     `form:"split_group_id"`
+	// Metadata comes from metadata[key]=value query parameters
+	Metadata map[string]string `form:"-"`
+	// ProviderResponse comes from provider_response[field]=value query
+	// parameters
+	ProviderResponse map[string]string `form:"-"`
+	Page             int               `form:"page"`
+	PageSize         int               `form:"page_size"`
+}
+
+// SearchMessagesRequest finds messages by content. Q uses web search
+// syntax: words, "quoted phrases" and -excluded words.
+type SearchMessagesRequest struct {
+	Q        string `form:"q"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+// ExportMessagesRequest selects the messages written to an export. From is
+// inclusive and To exclusive, both on the creation time.
+type ExportMessagesRequest struct {
+	Status      string     `form:"status"`
+	Channel     string     `form:"channel"`
+	PhoneNumber string     `form:"phone_number"`
+	ErrorCode   string     `form:"error_code"`
+	From        *time.Time `form:"from"`
+	To          *time.Time `form:"to"`
+	CampaignID  string     `form:"campaign_id"`
+	// Metadata comes from metadata[key]=value query parameters
+	Metadata map[string]string `form:"-"`
+	Gzip     bool              `form:"gzip"`
 }
 
 type MessageListResponse struct {
@@ -28,17 +215,317 @@ type MessageListResponse struct {
 	PageSize   int               `json:"page_size"`
 }
 
+// MessageSearchResult is a matching message. Highlight is its HTML escaped
+// content with every matched term wrapped in <mark></mark>.
+type MessageSearchResult struct {
+	MessageResponse
+	Highlight string `json:"highlight"`
+}
+
+type MessageSearchResponse struct {
+	Results    []MessageSearchResult `json:"results"`
+	TotalCount int                   `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+}
+
 type MessageStatsResponse struct {
-	TotalMessages   int64 `json:"total_messages"`
-	PendingMessages int64 `json:"pending_messages"`
-	SentMessages    int64 `json:"sent_messages"`
-	FailedMessages  int64 `json:"failed_messages"`
+	TotalMessages       int64 `json:"total_messages"`
+	PendingMessages     int64 `json:"pending_messages"`
+	SentMessages        int64 `json:"sent_messages"`
+	FailedMessages      int64 `json:"failed_messages"`
+	CancelledMessages   int64 `json:"cancelled_messages"`
+	DeliveredMessages   int64 `json:"delivered_messages"`
+	UndeliveredMessages int64 `json:"undelivered_messages"`
+	QuarantinedMessages int64 `json:"quarantined_messages"`
+	PausedMessages      int64 `json:"paused_messages"`
+	BlockedMessages     int64 `json:"blocked_messages"`
+	ExpiredMessages     int64 `json:"expired_messages"`
+	// Performance is only given with the stats of all messages
+	Performance *SendPerformanceResponse `json:"performance,omitempty"`
+}
+
+// SendPerformanceResponse sums up how sending went. Sent messages include
+// delivered and undelivered ones, and their time to send runs from creation
+// to sending. The failure rate is the share of failed among the messages
+// sent or failed in the last hour, 0 when there were none.
+type SendPerformanceResponse struct {
+	AvgAttemptsPerSent  float64 `json:"avg_attempts_per_sent"`
+	AvgTimeToSendMs     int64   `json:"avg_time_to_send_ms"`
+	P95TimeToSendMs     int64   `json:"p95_time_to_send_ms"`
+	FailureRateLastHour float64 `json:"failure_rate_last_hour"`
+}
+
+// CallerStatsResponse counts messages by status for each caller that created
+// them, busiest caller first. Callers are named as in the audit log; the
+// caller is empty for messages created before callers were recorded.
+type CallerStatsResponse struct {
+	Callers []CallerStats `json:"callers"`
+}
+
+type CallerStats struct {
+	Caller string `json:"caller"`
+	MessageStatsResponse
+}
+
+// StatsTimeSeriesRequest selects a time series of message counts. Bucket is
+// hour or day; From is inclusive and To exclusive.
+type StatsTimeSeriesRequest struct {
+	Bucket string     `form:"bucket"`
+	From   *time.Time `form:"from"`
+	To     *time.Time `form:"to"`
+}
+
+// StatsTimeSeriesResponse has one entry per bucket between From and To,
+// including empty ones, oldest first.
+type StatsTimeSeriesResponse struct {
+	Bucket     string                   `json:"bucket"`
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Buckets    []StatsBucketResponse    `json:"buckets"`
+	ErrorCodes []ErrorCodeCountResponse `json:"error_codes"`
+}
+
+// StatsBucketResponse counts the messages created, sent and failed in the
+// bucket starting at Start. Sent includes delivered and undelivered.
+type StatsBucketResponse struct {
+	Start   time.Time `json:"start"`
+	Created int64     `json:"created"`
+	Sent    int64     `json:"sent"`
+	Failed  int64     `json:"failed"`
+}
+
+type ErrorCodeCountResponse struct {
+	ErrorCode string `json:"error_code"`
+	Count     int64  `json:"count"`
+}
+
+// DuplicateContentRequest selects the range to look for duplicates in; From
+// is inclusive and To exclusive, both on the creation time. MinCount is how
+// many messages make a duplicate, at least 2.
+type DuplicateContentRequest struct {
+	From     *time.Time `form:"from"`
+	To       *time.Time `form:"to"`
+	MinCount int        `form:"min_count"`
+	Limit    int        `form:"limit"`
+}
+
+type DuplicateContentResponse struct {
+	From       time.Time                      `json:"from"`
+	To         time.Time                      `json:"to"`
+	MinCount   int                            `json:"min_count"`
+	Duplicates []DuplicateContentResponseItem `json:"duplicates"`
+}
+
+// DuplicateContentResponseItem is the same content created Count times for
+// one recipient.
+type DuplicateContentResponseItem struct {
+	Channel        string    `json:"channel"`
+	Recipient      string    `json:"recipient"`
+	ContentHash    string    `json:"content_hash"`
+	Content        string    `json:"content"`
+	Count          int64     `json:"count"`
+	FirstCreatedAt time.Time `json:"first_created_at"`
+	LastCreatedAt  time.Time `json:"last_created_at"`
 }
 
 type SchedulerStatusResponse struct {
-	IsRunning       bool      `json:"is_running"`
-	LastRunAt       time.Time `json:"last_run_at,omitempty"`
-	TotalProcessed  int64     `json:"total_processed"`
-	TotalSuccessful int64     `json:"total_successful"`
-	TotalFailed     int64     `json:"total_failed"`
+	IsRunning       bool                    `json:"is_running"`
+	Mode            string                  `json:"mode"`
+	LastRunAt       time.Time               `json:"last_run_at,omitempty"`
+	NextRunAt       *time.Time              `json:"next_run_at,omitempty"`
+	Schedule        string                  `json:"schedule"`
+	BatchSize       int                     `json:"batch_size"`
+	WorkerCount     int                     `json:"worker_count"`
+	IntervalSeconds int                     `json:"interval_seconds,omitempty"`
+	Priorities      []string                `json:"priorities,omitempty"`
+	Lanes           []SchedulerLaneResponse `json:"lanes,omitempty"`
+	TotalProcessed  int64                   `json:"total_processed"`
+	TotalSuccessful int64                   `json:"total_successful"`
+	TotalFailed     int64                   `json:"total_failed"`
+	Degraded        bool                    `json:"degraded"`
+	Dispatching     bool                    `json:"dispatching"`
+	RetryBudget     *RetryBudgetResponse    `json:"retry_budget,omitempty"`
+	CircuitBreaker  *CircuitBreakerResponse `json:"circuit_breaker,omitempty"`
+	Reaper          *ReaperResponse         `json:"reaper,omitempty"`
+	AutoPause       *AutoPauseResponse      `json:"auto_pause,omitempty"`
+}
+
+// AutoPauseResponse describes the health checks that pause dispatch while
+// the database or the provider keeps failing them.
+type AutoPauseResponse struct {
+	Paused      bool                  `json:"paused"`
+	Reason      string                `json:"reason,omitempty"`
+	PausedAt    *time.Time            `json:"paused_at,omitempty"`
+	LastCheckAt *time.Time            `json:"last_check_at,omitempty"`
+	Checks      []HealthCheckResponse `json:"checks"`
+}
+
+type HealthCheckResponse struct {
+	Name                string `json:"name"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// SchedulerLaneResponse describes a lane sending some priorities apart from
+// the main cycle, whose own priorities are then listed in the status. The
+// lane's totals are part of the scheduler's too.
+type SchedulerLaneResponse struct {
+	Name               string     `json:"name"`
+	Priorities         []string   `json:"priorities"`
+	IntervalSeconds    float64    `json:"interval_seconds"`
+	BatchSize          int        `json:"batch_size"`
+	WorkerCount        int        `json:"worker_count"`
+	RateLimitPerSecond int        `json:"rate_limit_per_second,omitempty"`
+	LastRunAt          *time.Time `json:"last_run_at,omitempty"`
+	TotalProcessed     int64      `json:"total_processed"`
+	TotalSuccessful    int64      `json:"total_successful"`
+	TotalFailed        int64      `json:"total_failed"`
+}
+
+// UpdateSchedulerConfigRequest changes only the fields that are set.
+type UpdateSchedulerConfigRequest struct {
+	BatchSize       *int `json:"batch_size,omitempty" binding:"omitempty,min=1"`
+	IntervalSeconds *int `json:"interval_seconds,omitempty" binding:"omitempty,min=1"`
+	WorkerCount     *int `json:"worker_count,omitempty" binding:"omitempty,min=1"`
+}
+
+type ReaperResponse struct {
+	ThresholdSeconds float64    `json:"threshold_seconds"`
+	IntervalSeconds  float64    `json:"interval_seconds"`
+	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	TotalRequeued    int64      `json:"total_requeued"`
+	TotalFailed      int64      `json:"total_failed"`
+}
+
+type CircuitBreakerResponse struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+type RetryBudgetResponse struct {
+	Paused       bool       `json:"paused"`
+	TrippedAt    *time.Time `json:"tripped_at,omitempty"`
+	PausedUntil  *time.Time `json:"paused_until,omitempty"`
+	Attempted    int        `json:"attempted"`
+	Failed       int        `json:"failed"`
+	FailureRatio float64    `json:"failure_ratio"`
+}
+
+type SchedulerRunResponse struct {
+	ID         string    `json:"id"`
+	Instance   string    `json:"instance"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Processed  int       `json:"processed"`
+	Successful int       `json:"successful"`
+	Failed     int       `json:"failed"`
+	Errors     int       `json:"errors"`
+	LastError  string    `json:"last_error,omitempty"`
+	SkipReason string    `json:"skip_reason,omitempty"`
+}
+
+// TriggerCycleResponse is the outcome of a cycle run on demand.
+type TriggerCycleResponse struct {
+	Claimed    int   `json:"claimed"`
+	Processed  int64 `json:"processed"`
+	Successful int64 `json:"successful"`
+	Failed     int64 `json:"failed"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+type SchedulerRunListResponse struct {
+	Runs       []SchedulerRunResponse `json:"runs"`
+	TotalCount int                    `json:"total_count"`
+	Page       int                    `json:"page"`
+	PageSize   int                    `json:"page_size"`
+}
+
+type FailoverRequest struct {
+	Region string `json:"region" binding:"required"`
+}
+
+type FailoverStatusResponse struct {
+	Region          string     `json:"region"`
+	Role            string     `json:"role"`
+	HolderID        string     `json:"holder_id"`
+	IsLeader        bool       `json:"is_leader"`
+	LeaseHolderID   string     `json:"lease_holder_id,omitempty"`
+	LeaseRegion     string     `json:"lease_region,omitempty"`
+	PreferredRegion string     `json:"preferred_region,omitempty"`
+	Epoch           int64      `json:"epoch,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+type RetentionPolicyResponse struct {
+	Status string `json:"status"`
+	Days   int    `json:"days"`
+}
+
+type RetentionStatusResponse struct {
+	Mode            string                    `json:"mode"`
+	Policies        []RetentionPolicyResponse `json:"policies"`
+	IntervalSeconds float64                   `json:"interval_seconds"`
+	LastRunAt       *time.Time                `json:"last_run_at,omitempty"`
+	LastError       string                    `json:"last_error,omitempty"`
+	LastRemoved     map[string]int64          `json:"last_removed,omitempty"`
+	TotalRemoved    int64                     `json:"total_removed"`
+}
+
+// RetentionRunResponse reports a manual retention run. Removed counts the
+// messages soft-deleted or purged, by status.
+type RetentionRunResponse struct {
+	Mode       string           `json:"mode"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Removed    map[string]int64 `json:"removed"`
+	Total      int64            `json:"total"`
+}
+
+// ListArchivesRequest selects archives holding messages created in
+// [From, To); both bounds are optional.
+type ListArchivesRequest struct {
+	From     *time.Time `form:"from"`
+	To       *time.Time `form:"to"`
+	Page     int        `form:"page"`
+	PageSize int        `form:"page_size"`
+}
+
+// ArchiveResponse describes one archive file. FirstCreatedAt and
+// LastCreatedAt bound the creation times of the messages in it.
+type ArchiveResponse struct {
+	ID             string    `json:"id"`
+	ObjectKey      string    `json:"object_key"`
+	Format         string    `json:"format"`
+	MessageCount   int       `json:"message_count"`
+	SizeBytes      int64     `json:"size_bytes"`
+	FirstCreatedAt time.Time `json:"first_created_at"`
+	LastCreatedAt  time.Time `json:"last_created_at"`
+	ArchivedAt     time.Time `json:"archived_at"`
+}
+
+type ArchiveListResponse struct {
+	Archives   []ArchiveResponse `json:"archives"`
+	TotalCount int               `json:"total_count"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+}
+
+// ArchiveRunResponse reports a manual archive run.
+type ArchiveRunResponse struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Files      int       `json:"files"`
+	Archived   int64     `json:"archived"`
+}
+
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
 }
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// CreateProviderErrorMappingRequest stores ErrorCode for messages that
+// Provider refuses with a matching answer. At least one of StatusCode,
+// ProviderCode and BodyContains must be set; the ones left out match any
+// answer.
+type CreateProviderErrorMappingRequest struct {
+	Provider     string `json:"provider" binding:"required" example:"twilio"`
+	StatusCode   int    `json:"status_code,omitempty" example:"400"`
+	ProviderCode string `json:"provider_code,omitempty" example:"30007"`
+	BodyContains string `json:"body_contains,omitempty" example:"insufficient funds"`
+	ErrorCode    string `json:"error_code" binding:"required" example:"INSUFFICIENT_BALANCE"`
+	Description  string `json:"description,omitempty"`
+}
+
+type ProviderErrorMappingResponse struct {
+	ID           string    `json:"id"`
+	Provider     string    `json:"provider"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ProviderCode string    `json:"provider_code,omitempty"`
+	BodyContains string    `json:"body_contains,omitempty"`
+	ErrorCode    string    `json:"error_code"`
+	Retryable    bool      `json:"retryable"`
+	Description  string    `json:"description,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ProviderErrorMappingListResponse struct {
+	Mappings []ProviderErrorMappingResponse `json:"mappings"`
+}
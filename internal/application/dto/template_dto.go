@@ -0,0 +1,28 @@
+package dto
+
+// TemplatePreviewRequest is message content with {{name}} placeholders and
+// the variables to fill them with.
+type TemplatePreviewRequest struct {
+	Content   string            `json:"content" binding:"required"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// TemplatePreviewResponse is the rendered content and everything that would
+// stop it from being sent. Content is what would be sent, after any
+// redaction by content moderation. Parts is how many messages it would be
+// split into and Segments the SMS they take together; MaxSegments is the
+// limit per message, 0 when there is none. Valid is true when there are no
+// violations.
+type TemplatePreviewResponse struct {
+	Content          string   `json:"content"`
+	CharacterCount   int      `json:"character_count"`
+	CharLimit        int      `json:"char_limit"`
+	Encoding         string   `json:"encoding"`
+	Segments         int      `json:"segments"`
+	MaxSegments      int      `json:"max_segments"`
+	Parts            int      `json:"parts"`
+	Redacted         bool     `json:"redacted"`
+	MissingVariables []string `json:"missing_variables"`
+	Violations       []string `json:"violations"`
+	Valid            bool     `json:"valid"`
+}
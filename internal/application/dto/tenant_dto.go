@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+// CreateTenantRequest's QuietHours replaces QUIET_HOURS for the tenant's
+// messages: HH:MM-HH:MM, "off", or empty to keep the default.
+type CreateTenantRequest struct {
+	Name       string `json:"name" binding:"required"`
+	QuietHours string `json:"quiet_hours,omitempty" example:"21:00-09:00"`
+}
+
+// UpdateTenantRequest leaves fields that are omitted unchanged; an empty
+// quiet_hours returns the tenant to the default.
+type UpdateTenantRequest struct {
+	Name       *string `json:"name,omitempty"`
+	Active     *bool   `json:"active,omitempty"`
+	QuietHours *string `json:"quiet_hours,omitempty" example:"off"`
+}
+
+type TenantResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Active     bool      `json:"active"`
+	QuietHours string    `json:"quiet_hours,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TenantCredentialsResponse is the only place the plaintext API key is ever
+// returned; it is not stored.
+type TenantCredentialsResponse struct {
+	TenantResponse
+	APIKey string `json:"api_key"`
+}
+
+type TenantListResponse struct {
+	Tenants    []TenantResponse `json:"tenants"`
+	TotalCount int              `json:"total_count"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+}
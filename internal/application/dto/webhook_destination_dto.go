@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// CreateWebhookDestinationRequest registers a webhook messages can be sent
+// to with their destination_id. RateLimitPerSecond 0 uses the global
+// WEBHOOK_RATE_LIMIT_PER_SECOND; TenantID restricts the destination to that
+// tenant's messages.
+type CreateWebhookDestinationRequest struct {
+	Name               string `json:"name" binding:"required"`
+	URL                string `json:"url" binding:"required"`
+	AuthKey            string `json:"auth_key,omitempty"`
+	RateLimitPerSecond int    `json:"rate_limit_per_second,omitempty"`
+	TenantID           string `json:"tenant_id,omitempty"`
+}
+
+// UpdateWebhookDestinationRequest leaves fields that are omitted unchanged.
+type UpdateWebhookDestinationRequest struct {
+	Name               *string `json:"name,omitempty"`
+	URL                *string `json:"url,omitempty"`
+	AuthKey            *string `json:"auth_key,omitempty"`
+	RateLimitPerSecond *int    `json:"rate_limit_per_second,omitempty"`
+	Active             *bool   `json:"active,omitempty"`
+}
+
+// WebhookDestinationResponse never includes the auth key; AuthKeySet tells
+// whether one is configured.
+type WebhookDestinationResponse struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	URL                string    `json:"url"`
+	AuthKeySet         bool      `json:"auth_key_set"`
+	RateLimitPerSecond int       `json:"rate_limit_per_second"`
+	TenantID           string    `json:"tenant_id,omitempty"`
+	Active             bool      `json:"active"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type WebhookDestinationListResponse struct {
+	Destinations []WebhookDestinationResponse `json:"destinations"`
+	TotalCount   int                          `json:"total_count"`
+	Page         int                          `json:"page"`
+	PageSize     int                          `json:"page_size"`
+}
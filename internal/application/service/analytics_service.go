@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+)
+
+// Defaults and bounds of the delivery report
+const (
+	defaultDeliveryRange        = 7 * 24 * time.Hour
+	defaultDeliveryPrefixDigits = 3
+	maxDeliveryPrefixDigits     = 6
+	defaultDeliveryPrefixLimit  = 50
+	maxDeliveryPrefixLimit      = 500
+	// maxCountryCodeDigits is the length of the longest country calling code
+	maxCountryCodeDigits = 3
+)
+
+type AnalyticsService interface {
+	// GetDeliveryByPrefix reports how SMS messages created in a range fared
+	// per country calling code and per number prefix, worst first, to tell
+	// a carrier's failures apart from the provider's.
+	GetDeliveryByPrefix(ctx context.Context, req *dto.DeliveryAnalyticsRequest) (*dto.DeliveryAnalyticsResponse, error)
+}
+
+type analyticsService struct {
+	repo repository.MessageRepository
+}
+
+func NewAnalyticsService(repo repository.MessageRepository) AnalyticsService {
+	return &analyticsService{repo: repo}
+}
+
+func (s *analyticsService) GetDeliveryByPrefix(ctx context.Context, req *dto.DeliveryAnalyticsRequest) (*dto.DeliveryAnalyticsResponse, error) {
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.To.UTC()
+	}
+	from := to.Add(-defaultDeliveryRange)
+	if req.From != nil {
+		from = req.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, apperrors.NewValidationError("from must be before to")
+	}
+
+	digits := req.PrefixDigits
+	if digits == 0 {
+		digits = defaultDeliveryPrefixDigits
+	}
+	if digits < 1 || digits > maxDeliveryPrefixDigits {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("prefix_digits must be between 1 and %d", maxDeliveryPrefixDigits))
+	}
+	minMessages := req.MinMessages
+	if minMessages < 0 {
+		return nil, apperrors.NewValidationError("min_messages must not be negative")
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultDeliveryPrefixLimit
+	}
+	if limit < 1 || limit > maxDeliveryPrefixLimit {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxDeliveryPrefixLimit))
+	}
+
+	// Grouped on enough characters to hold the longest country code, then
+	// merged here once each group's country code is known
+	rows, err := s.repo.GetDeliveryByPrefix(ctx, from, to, 1+maxCountryCodeDigits+digits)
+	if err != nil {
+		return nil, err
+	}
+
+	countries := make(map[string]*dto.CountryDelivery)
+	prefixes := make(map[string]*dto.PrefixDelivery)
+	samples := make(map[string]string)
+	for _, row := range rows {
+		countryCode, region, rest, _ := valueobject.SplitCountryCode(row.Prefix)
+		if len(rest) > digits {
+			rest = rest[:digits]
+		}
+		prefix := "+" + countryCode + rest
+
+		country, ok := countries[countryCode]
+		if !ok {
+			country = &dto.CountryDelivery{CountryCode: countryCode, Region: region}
+			countries[countryCode] = country
+		}
+		addDelivery(&country.DeliveryCounts, row)
+
+		item, ok := prefixes[prefix]
+		if !ok {
+			item = &dto.PrefixDelivery{Prefix: prefix, CountryCode: countryCode, Region: region}
+			prefixes[prefix] = item
+		}
+		addDelivery(&item.DeliveryCounts, row)
+		if sample, ok := samples[prefix]; !ok || row.Sample < sample {
+			samples[prefix] = row.Sample
+		}
+	}
+
+	resp := &dto.DeliveryAnalyticsResponse{
+		From:         from,
+		To:           to,
+		PrefixDigits: digits,
+		Countries:    make([]dto.CountryDelivery, 0, len(countries)),
+		Prefixes:     make([]dto.PrefixDelivery, 0, len(prefixes)),
+	}
+	for _, country := range countries {
+		setDeliveryRatios(&country.DeliveryCounts)
+		resp.Countries = append(resp.Countries, *country)
+	}
+	sort.Slice(resp.Countries, func(i, j int) bool {
+		return worseDelivery(resp.Countries[i].DeliveryCounts, resp.Countries[j].DeliveryCounts, resp.Countries[i].CountryCode < resp.Countries[j].CountryCode)
+	})
+
+	for _, item := range prefixes {
+		if item.Total < int64(minMessages) {
+			continue
+		}
+		setDeliveryRatios(&item.DeliveryCounts)
+		resp.Prefixes = append(resp.Prefixes, *item)
+	}
+	sort.Slice(resp.Prefixes, func(i, j int) bool {
+		return worseDelivery(resp.Prefixes[i].DeliveryCounts, resp.Prefixes[j].DeliveryCounts, resp.Prefixes[i].Prefix < resp.Prefixes[j].Prefix)
+	})
+	if len(resp.Prefixes) > limit {
+		resp.Prefixes = resp.Prefixes[:limit]
+	}
+
+	// Looked up only for the prefixes that are listed
+	for i := range resp.Prefixes {
+		if phone, err := valueobject.NewPhoneNumber(samples[resp.Prefixes[i].Prefix]); err == nil {
+			resp.Prefixes[i].Carrier = phone.Carrier()
+		}
+	}
+
+	return resp, nil
+}
+
+func addDelivery(counts *dto.DeliveryCounts, row repository.PrefixDelivery) {
+	counts.Total += row.Total
+	counts.Sent += row.Sent
+	counts.Delivered += row.Delivered
+	counts.Failed += row.Failed
+}
+
+func setDeliveryRatios(counts *dto.DeliveryCounts) {
+	finished := counts.Sent + counts.Failed
+	if finished == 0 {
+		return
+	}
+	counts.SentRatio = roundRatio(float64(counts.Sent) / float64(finished))
+	counts.FailedRatio = roundRatio(float64(counts.Failed) / float64(finished))
+}
+
+func roundRatio(ratio float64) float64 {
+	return math.Round(ratio*10000) / 10000
+}
+
+// worseDelivery orders by failed messages, then by volume, and falls back
+// to tie when both are equal.
+func worseDelivery(a, b dto.DeliveryCounts, tie bool) bool {
+	if a.Failed != b.Failed {
+		return a.Failed > b.Failed
+	}
+	if a.Total != b.Total {
+		return a.Total > b.Total
+	}
+	return tie
+}
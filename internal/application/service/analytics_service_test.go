@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetDeliveryByPrefix(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewAnalyticsService(mockRepo)
+
+	// Grouped on three digits past the longest country code, so +90555
+	// arrives split in two
+	mockRepo.On("GetDeliveryByPrefix", mock.Anything, mock.Anything, mock.Anything, 7).Return([]repository.PrefixDelivery{
+		{Prefix: "+905321", Sample: "+905321234567", Total: 10, Sent: 9, Delivered: 5},
+		{Prefix: "+905551", Sample: "+905551234567", Total: 4, Sent: 2, Failed: 2},
+		{Prefix: "+905559", Sample: "+905559876543", Total: 4, Sent: 1, Failed: 2},
+		{Prefix: "+447911", Sample: "+447911123456", Total: 1, Failed: 1},
+	}, nil)
+
+	// Act
+	resp, err := svc.GetDeliveryByPrefix(context.Background(), &dto.DeliveryAnalyticsRequest{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resp.PrefixDigits)
+	assert.Equal(t, 7*24*time.Hour, resp.To.Sub(resp.From))
+
+	if assert.Len(t, resp.Countries, 2) {
+		turkey := resp.Countries[0]
+		assert.Equal(t, "90", turkey.CountryCode)
+		assert.Equal(t, "TR", turkey.Region)
+		assert.Equal(t, int64(18), turkey.Total)
+		assert.Equal(t, int64(12), turkey.Sent)
+		assert.Equal(t, int64(4), turkey.Failed)
+		assert.Equal(t, 0.75, turkey.SentRatio)
+		assert.Equal(t, 0.25, turkey.FailedRatio)
+		assert.Equal(t, "GB", resp.Countries[1].Region)
+	}
+
+	if assert.Len(t, resp.Prefixes, 3) {
+		worst := resp.Prefixes[0]
+		assert.Equal(t, "+90555", worst.Prefix)
+		assert.Equal(t, int64(8), worst.Total)
+		assert.Equal(t, int64(3), worst.Sent)
+		assert.Equal(t, 0.5714, worst.FailedRatio)
+		assert.Equal(t, "Turk Telekom", worst.Carrier)
+		assert.Equal(t, "+44791", resp.Prefixes[1].Prefix)
+		assert.Equal(t, "+90532", resp.Prefixes[2].Prefix)
+		assert.Equal(t, "Turkcell", resp.Prefixes[2].Carrier)
+		assert.Zero(t, resp.Prefixes[2].FailedRatio)
+	}
+}
+
+func TestGetDeliveryByPrefix_MinMessagesAndLimit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewAnalyticsService(mockRepo)
+
+	mockRepo.On("GetDeliveryByPrefix", mock.Anything, mock.Anything, mock.Anything, 6).Return([]repository.PrefixDelivery{
+		{Prefix: "+90532", Sample: "+905321234567", Total: 10, Sent: 10},
+		{Prefix: "+90555", Sample: "+905551234567", Total: 8, Failed: 1},
+		{Prefix: "+44791", Sample: "+447911123456", Total: 1, Failed: 1},
+	}, nil)
+
+	// Act
+	resp, err := svc.GetDeliveryByPrefix(context.Background(), &dto.DeliveryAnalyticsRequest{
+		PrefixDigits: 2,
+		MinMessages:  2,
+		Limit:        1,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, resp.Countries, 2, "countries count every message")
+	if assert.Len(t, resp.Prefixes, 1) {
+		assert.Equal(t, "+9055", resp.Prefixes[0].Prefix)
+	}
+}
+
+func TestGetDeliveryByPrefix_InvalidRequest(t *testing.T) {
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	testCases := []struct {
+		name string
+		req  dto.DeliveryAnalyticsRequest
+	}{
+		{name: "from after to", req: dto.DeliveryAnalyticsRequest{From: &from, To: &to}},
+		{name: "too many prefix digits", req: dto.DeliveryAnalyticsRequest{PrefixDigits: 7}},
+		{name: "negative min messages", req: dto.DeliveryAnalyticsRequest{MinMessages: -1}},
+		{name: "limit too large", req: dto.DeliveryAnalyticsRequest{Limit: 501}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewAnalyticsService(mockRepo)
+
+			// Act
+			resp, err := svc.GetDeliveryByPrefix(context.Background(), &tc.req)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, resp)
+			mockRepo.AssertNotCalled(t, "GetDeliveryByPrefix", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
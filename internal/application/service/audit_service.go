@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Audited actions
+const (
+	AuditActionMessageCreated        = "message.created"
+	AuditActionMessageCancelled      = "message.cancelled"
+	AuditActionMessageRetried        = "message.retried"
+	AuditActionMessagePaused         = "message.paused"
+	AuditActionMessageResumed        = "message.resumed"
+	AuditActionMessageStatusChanged  = "message.status_changed"
+	AuditActionFailedRequeued        = "messages.failed_requeued"
+	AuditActionSchedulerStarted      = "scheduler.started"
+	AuditActionSchedulerStopped      = "scheduler.stopped"
+	AuditActionSchedulerReconfigured = "scheduler.reconfigured"
+	AuditActionSchedulerTriggered    = "scheduler.triggered"
+	AuditActionDispatchResumed       = "scheduler.dispatch_resumed"
+	AuditActionDispatchAutoPaused    = "scheduler.auto_paused"
+	AuditActionDispatchAutoResumed   = "scheduler.auto_resumed"
+	AuditActionCampaignCreated       = "campaign.created"
+	AuditActionCampaignPaused        = "campaign.paused"
+	AuditActionCampaignResumed       = "campaign.resumed"
+	AuditActionRetentionRun          = "retention.run"
+	AuditActionArchiveRun            = "archive.run"
+	AuditActionBlacklistAdded        = "blacklist.added"
+	AuditActionBlacklistRemoved      = "blacklist.removed"
+)
+
+const (
+	AuditResourceMessage   = "message"
+	AuditResourceScheduler = "scheduler"
+	AuditResourceCampaign  = "campaign"
+	AuditResourceRetention = "retention"
+	AuditResourceArchive   = "archive"
+	AuditResourceBlacklist = "blacklist"
+)
+
+// AuditRecord is what a write reports to the audit log; the actor, request
+// ID and time are taken from the context it is recorded with.
+type AuditRecord struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	// TenantID defaults to the tenant carried by the context
+	TenantID   uuid.UUID
+	FromStatus string
+	ToStatus   string
+	Details    string
+}
+
+type AuditService interface {
+	// Record appends record to the audit log. It is best effort: a failure
+	// is logged and never fails the write being audited.
+	Record(ctx context.Context, record AuditRecord)
+	ListAuditLog(ctx context.Context, req *dto.ListAuditLogRequest) (*dto.AuditLogListResponse, error)
+}
+
+type auditService struct {
+	repo    repository.AuditRepository
+	enabled bool
+}
+
+// NewAuditService returns a service that records nothing while enabled is
+// false; entries written earlier can still be listed.
+func NewAuditService(repo repository.AuditRepository, enabled bool) AuditService {
+	return &auditService{repo: repo, enabled: enabled}
+}
+
+func (s *auditService) Record(ctx context.Context, record AuditRecord) {
+	if !s.enabled {
+		return
+	}
+
+	tenantID := record.TenantID
+	if tenantID == uuid.Nil {
+		tenantID, _ = tenant.FromContext(ctx)
+	}
+
+	entry := &repository.AuditEntry{
+		ID:           uuid.New(),
+		Action:       record.Action,
+		ResourceType: record.ResourceType,
+		ResourceID:   record.ResourceID,
+		TenantID:     tenantID,
+		Actor:        auditActor(ctx),
+		RequestID:    requestid.FromContext(ctx),
+		FromStatus:   record.FromStatus,
+		ToStatus:     record.ToStatus,
+		Details:      record.Details,
+		OccurredAt:   time.Now().UTC(),
+	}
+
+	// The write being audited already happened; a client hanging up must
+	// not lose its entry
+	if err := s.repo.Add(context.WithoutCancel(ctx), entry); err != nil {
+		logger.FromContext(ctx).Error("failed to record audit log entry",
+			zap.Error(err),
+			zap.String("action", entry.Action),
+			zap.String("resource_id", entry.ResourceID),
+			zap.String("actor", entry.Actor),
+		)
+	}
+}
+
+// auditActor names the caller behind ctx. Tenant API keys are recognised
+// even when no actor was set explicitly.
+func auditActor(ctx context.Context) string {
+	if name := actor.FromContext(ctx); name != "" {
+		return name
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return actor.Tenant(tenantID)
+	}
+	return actor.Anonymous
+}
+
+func (s *auditService) ListAuditLog(ctx context.Context, req *dto.ListAuditLogRequest) (*dto.AuditLogListResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if req.After != nil && req.Before != nil && !req.After.Before(*req.Before) {
+		return nil, apperrors.NewValidationError("after must be earlier than before")
+	}
+
+	filter := repository.AuditFilter{
+		Action:       req.Action,
+		Actor:        req.Actor,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		RequestID:    req.RequestID,
+		After:        req.After,
+		Before:       req.Before,
+	}
+
+	entries, total, err := s.repo.List(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responseEntries := make([]dto.AuditLogEntryResponse, len(entries))
+	for i, entry := range entries {
+		responseEntries[i] = dto.AuditLogEntryResponse{
+			ID:           entry.ID.String(),
+			Action:       entry.Action,
+			ResourceType: entry.ResourceType,
+			ResourceID:   entry.ResourceID,
+			TenantID:     optionalID(entry.TenantID),
+			Actor:        entry.Actor,
+			RequestID:    entry.RequestID,
+			FromStatus:   entry.FromStatus,
+			ToStatus:     entry.ToStatus,
+			Details:      entry.Details,
+			OccurredAt:   entry.OccurredAt,
+		}
+	}
+
+	return &dto.AuditLogListResponse{
+		Entries:    responseEntries,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Add(ctx context.Context, entry *repository.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*repository.AuditEntry, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*repository.AuditEntry), args.Get(1).(int64), args.Error(2)
+}
+
+func TestAuditService_RecordTakesActorAndRequestIDFromContext(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockRepo, true)
+
+	ctx := actor.WithActor(context.Background(), actor.AdminToken)
+	ctx = requestid.WithRequestID(ctx, "req-123")
+
+	var recorded *repository.AuditEntry
+	mockRepo.On("Add", mock.Anything, mock.AnythingOfType("*repository.AuditEntry")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*repository.AuditEntry) }).
+		Return(nil)
+
+	// Act
+	svc.Record(ctx, service.AuditRecord{
+		Action:       service.AuditActionSchedulerStarted,
+		ResourceType: service.AuditResourceScheduler,
+	})
+
+	// Assert
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, actor.AdminToken, recorded.Actor)
+	assert.Equal(t, "req-123", recorded.RequestID)
+	assert.Equal(t, service.AuditActionSchedulerStarted, recorded.Action)
+	assert.Equal(t, uuid.Nil, recorded.TenantID)
+	assert.NotEqual(t, uuid.Nil, recorded.ID)
+	assert.WithinDuration(t, time.Now(), recorded.OccurredAt, time.Second)
+}
+
+func TestAuditService_RecordFallsBackToTenantActor(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockRepo, true)
+	tenantID := uuid.New()
+
+	mockRepo.On("Add", mock.Anything, mock.MatchedBy(func(entry *repository.AuditEntry) bool {
+		return entry.Actor == actor.Tenant(tenantID) && entry.TenantID == tenantID
+	})).Return(nil).Once()
+	mockRepo.On("Add", mock.Anything, mock.MatchedBy(func(entry *repository.AuditEntry) bool {
+		return entry.Actor == actor.Anonymous && entry.TenantID == uuid.Nil
+	})).Return(nil).Once()
+
+	// Act
+	svc.Record(tenant.WithTenantID(context.Background(), tenantID), service.AuditRecord{Action: service.AuditActionMessageCreated})
+	svc.Record(context.Background(), service.AuditRecord{Action: service.AuditActionMessageCreated})
+
+	// Assert
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_RecordFailureIsNotFatal(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockRepo, true)
+	mockRepo.On("Add", mock.Anything, mock.Anything).Return(apperrors.NewDatabaseError(assert.AnError))
+
+	// Act & Assert
+	assert.NotPanics(t, func() {
+		svc.Record(context.Background(), service.AuditRecord{Action: service.AuditActionMessageCreated})
+	})
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_DisabledRecordsNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockRepo, false)
+
+	// Act
+	svc.Record(context.Background(), service.AuditRecord{Action: service.AuditActionMessageCreated})
+
+	// Assert
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestAuditService_ListAuditLog(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockRepo, true)
+	messageID := uuid.New()
+
+	entries := []*repository.AuditEntry{{
+		ID:           uuid.New(),
+		Action:       service.AuditActionMessageCancelled,
+		ResourceType: service.AuditResourceMessage,
+		ResourceID:   messageID.String(),
+		Actor:        actor.APIToken,
+		FromStatus:   "pending",
+		ToStatus:     "cancelled",
+		OccurredAt:   time.Now().UTC(),
+	}}
+	filter := repository.AuditFilter{ResourceID: messageID.String()}
+	mockRepo.On("List", mock.Anything, filter, 20, 0).Return(entries, int64(1), nil)
+
+	// Act
+	result, err := svc.ListAuditLog(context.Background(), &dto.ListAuditLogRequest{ResourceID: messageID.String()})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.TotalCount)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 20, result.PageSize)
+	assert.Equal(t, "cancelled", result.Entries[0].ToStatus)
+	assert.Empty(t, result.Entries[0].TenantID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuditService_ListAuditLogRejectsInvertedRange(t *testing.T) {
+	// Arrange
+	svc := service.NewAuditService(new(MockAuditRepository), true)
+	after := time.Now()
+	before := after.Add(-time.Hour)
+
+	// Act
+	_, err := svc.ListAuditLog(context.Background(), &dto.ListAuditLogRequest{After: &after, Before: &before})
+
+	// Assert
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+}
+
+func TestCancelMessage_RecordsAuditEntry(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockAudit := new(MockAuditRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil,
+		service.NewAuditService(mockAudit, true), nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+	mockAudit.On("Add", mock.Anything, mock.MatchedBy(func(entry *repository.AuditEntry) bool {
+		return entry.Action == service.AuditActionMessageCancelled &&
+			entry.ResourceID == message.ID().String() &&
+			entry.FromStatus == "pending" &&
+			entry.ToStatus == "cancelled" &&
+			entry.Actor == actor.APIToken &&
+			entry.RequestID == "req-456"
+	})).Return(nil)
+
+	ctx := actor.WithActor(context.Background(), actor.APIToken)
+	ctx = requestid.WithRequestID(ctx, "req-456")
+
+	// Act
+	_, err := svc.CancelMessage(ctx, message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxBlacklistReasonLength bounds the free-text reason of an entry
+const maxBlacklistReasonLength = 500
+
+type BlacklistService interface {
+	AddEntry(ctx context.Context, req *dto.CreateBlacklistEntryRequest) (*dto.BlacklistEntryResponse, error)
+	GetEntry(ctx context.Context, id uuid.UUID) (*dto.BlacklistEntryResponse, error)
+	ListEntries(ctx context.Context, phoneNumber string, page, pageSize int) (*dto.BlacklistListResponse, error)
+	RemoveEntry(ctx context.Context, id uuid.UUID) error
+	// IsBlocked reports whether messages of tenantID (uuid.Nil for the
+	// global API token) must not be sent to phoneNumber: a global entry
+	// blocks every tenant, a tenant's entry only that tenant.
+	IsBlocked(ctx context.Context, phoneNumber string, tenantID uuid.UUID) (bool, error)
+}
+
+type blacklistService struct {
+	repo  repository.BlacklistRepository
+	cache cache.BlacklistCache
+	audit AuditService
+}
+
+// NewBlacklistService looks numbers up in the database on every call when
+// blacklistCache is nil. audit may be nil.
+func NewBlacklistService(repo repository.BlacklistRepository, blacklistCache cache.BlacklistCache, audit AuditService) BlacklistService {
+	return &blacklistService{
+		repo:  repo,
+		cache: blacklistCache,
+		audit: audit,
+	}
+}
+
+func (s *blacklistService) AddEntry(ctx context.Context, req *dto.CreateBlacklistEntryRequest) (*dto.BlacklistEntryResponse, error) {
+	phoneNumber, err := valueobject.NewPhoneNumber(req.PhoneNumber)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxBlacklistReasonLength {
+		return nil, apperrors.NewValidationError("reason must be at most 500 characters")
+	}
+
+	entry := &repository.BlacklistEntry{
+		ID:          uuid.New(),
+		PhoneNumber: phoneNumber.String(),
+		Reason:      reason,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		entry.TenantID = tenantID
+	}
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists {
+			return nil, apperrors.New(apperrors.ErrorCodeAlreadyExists, "phone number is already blacklisted")
+		}
+		return nil, err
+	}
+	s.invalidate(ctx, entry.PhoneNumber)
+
+	logger.FromContext(ctx).Info("phone number blacklisted",
+		zap.String("entry_id", entry.ID.String()),
+		zap.String("phone_number", entry.PhoneNumber),
+	)
+	s.recordAudit(ctx, AuditActionBlacklistAdded, entry)
+
+	resp := toBlacklistDTO(entry)
+	return &resp, nil
+}
+
+func (s *blacklistService) GetEntry(ctx context.Context, id uuid.UUID) (*dto.BlacklistEntryResponse, error) {
+	entry, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toBlacklistDTO(entry)
+	return &resp, nil
+}
+
+func (s *blacklistService) ListEntries(ctx context.Context, phoneNumber string, page, pageSize int) (*dto.BlacklistListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if phoneNumber != "" {
+		normalized, err := valueobject.NewPhoneNumber(phoneNumber)
+		if err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
+		}
+		phoneNumber = normalized.String()
+	}
+
+	entries, total, err := s.repo.List(ctx, phoneNumber, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.BlacklistEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = toBlacklistDTO(entry)
+	}
+
+	return &dto.BlacklistListResponse{
+		Entries:    responses,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *blacklistService) RemoveEntry(ctx context.Context, id uuid.UUID) error {
+	entry, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.invalidate(ctx, entry.PhoneNumber)
+
+	logger.FromContext(ctx).Info("phone number removed from blacklist",
+		zap.String("entry_id", entry.ID.String()),
+		zap.String("phone_number", entry.PhoneNumber),
+	)
+	s.recordAudit(ctx, AuditActionBlacklistRemoved, entry)
+
+	return nil
+}
+
+// IsBlocked reads through the cache. A cache failure falls back to the
+// database; only a database failure is returned, so callers never send to a
+// number they could not check.
+func (s *blacklistService) IsBlocked(ctx context.Context, phoneNumber string, tenantID uuid.UUID) (bool, error) {
+	tenants, err := s.tenantsBlocking(ctx, phoneNumber)
+	if err != nil {
+		return false, err
+	}
+
+	for _, blocking := range tenants {
+		if blocking == uuid.Nil || blocking == tenantID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *blacklistService) tenantsBlocking(ctx context.Context, phoneNumber string) ([]uuid.UUID, error) {
+	if s.cache != nil {
+		tenants, found, err := s.cache.Get(ctx, phoneNumber)
+		if err != nil {
+			logger.FromContext(ctx).Warn("blacklist cache unavailable, reading database", zap.Error(err))
+		} else if found {
+			return tenants, nil
+		}
+	}
+
+	tenants, err := s.repo.FindTenantsBlocking(ctx, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, phoneNumber, tenants); err != nil {
+			logger.FromContext(ctx).Warn("failed to cache blacklist lookup", zap.Error(err))
+		}
+	}
+	return tenants, nil
+}
+
+func (s *blacklistService) invalidate(ctx context.Context, phoneNumber string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Invalidate(ctx, phoneNumber); err != nil {
+		logger.FromContext(ctx).Error("failed to invalidate blacklist cache",
+			zap.Error(err),
+			zap.String("phone_number", phoneNumber),
+		)
+	}
+}
+
+func (s *blacklistService) recordAudit(ctx context.Context, action string, entry *repository.BlacklistEntry) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, AuditRecord{
+		Action:       action,
+		ResourceType: AuditResourceBlacklist,
+		ResourceID:   entry.ID.String(),
+		TenantID:     entry.TenantID,
+		Details:      entry.PhoneNumber,
+	})
+}
+
+func toBlacklistDTO(entry *repository.BlacklistEntry) dto.BlacklistEntryResponse {
+	return dto.BlacklistEntryResponse{
+		ID:          entry.ID.String(),
+		PhoneNumber: entry.PhoneNumber,
+		TenantID:    optionalID(entry.TenantID),
+		Reason:      entry.Reason,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
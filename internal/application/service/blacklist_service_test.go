@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBlacklistRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlacklistRepository) Create(ctx context.Context, entry *repository.BlacklistEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockBlacklistRepository) Delete(ctx context.Context, id uuid.UUID) (*repository.BlacklistEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.BlacklistEntry), args.Error(1)
+}
+
+func (m *MockBlacklistRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.BlacklistEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.BlacklistEntry), args.Error(1)
+}
+
+func (m *MockBlacklistRepository) List(ctx context.Context, phoneNumber string, limit, offset int) ([]*repository.BlacklistEntry, int64, error) {
+	args := m.Called(ctx, phoneNumber, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.BlacklistEntry), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockBlacklistRepository) FindTenantsBlocking(ctx context.Context, phoneNumber string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, phoneNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+type MockBlacklistCache struct {
+	mock.Mock
+}
+
+func (m *MockBlacklistCache) Get(ctx context.Context, phoneNumber string) ([]uuid.UUID, bool, error) {
+	args := m.Called(ctx, phoneNumber)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).([]uuid.UUID), args.Bool(1), args.Error(2)
+}
+
+func (m *MockBlacklistCache) Set(ctx context.Context, phoneNumber string, tenants []uuid.UUID) error {
+	args := m.Called(ctx, phoneNumber, tenants)
+	return args.Error(0)
+}
+
+func (m *MockBlacklistCache) Invalidate(ctx context.Context, phoneNumber string) error {
+	args := m.Called(ctx, phoneNumber)
+	return args.Error(0)
+}
+
+func TestAddBlacklistEntry_NormalizesAndInvalidatesCache(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	mockCache := new(MockBlacklistCache)
+	svc := service.NewBlacklistService(mockRepo, mockCache, nil)
+
+	tenantID := uuid.New()
+	ctx := tenant.WithTenantID(context.Background(), tenantID)
+
+	var stored *repository.BlacklistEntry
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.BlacklistEntry")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*repository.BlacklistEntry) }).
+		Return(nil)
+	mockCache.On("Invalidate", mock.Anything, "+905551234567").Return(nil)
+
+	// Act
+	result, err := svc.AddEntry(ctx, &dto.CreateBlacklistEntryRequest{
+		PhoneNumber: "+90 555 123 45 67",
+		Reason:      " replied STOP ",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "+905551234567", result.PhoneNumber)
+	assert.Equal(t, "replied STOP", result.Reason)
+	assert.Equal(t, tenantID.String(), result.TenantID)
+	assert.Equal(t, tenantID, stored.TenantID)
+	mockCache.AssertExpectations(t)
+}
+
+func TestAddBlacklistEntry_AlreadyListed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	mockCache := new(MockBlacklistCache)
+	svc := service.NewBlacklistService(mockRepo, mockCache, nil)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).
+		Return(apperrors.New(apperrors.ErrorCodeAlreadyExists, "duplicate key"))
+
+	// Act
+	result, err := svc.AddEntry(context.Background(), &dto.CreateBlacklistEntryRequest{PhoneNumber: "+905551234567"})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+	}
+	mockCache.AssertNotCalled(t, "Invalidate", mock.Anything, mock.Anything)
+}
+
+func TestIsBlocked_GlobalAndTenantEntries(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	svc := service.NewBlacklistService(mockRepo, nil, nil)
+
+	tenantA := uuid.New()
+	tenantB := uuid.New()
+	mockRepo.On("FindTenantsBlocking", mock.Anything, "+905551234567").Return([]uuid.UUID{tenantA}, nil)
+	mockRepo.On("FindTenantsBlocking", mock.Anything, "+905551234568").Return([]uuid.UUID{uuid.Nil}, nil)
+
+	for _, tc := range []struct {
+		phone    string
+		tenantID uuid.UUID
+		blocked  bool
+	}{
+		{"+905551234567", tenantA, true},
+		{"+905551234567", tenantB, false},
+		{"+905551234567", uuid.Nil, false},
+		{"+905551234568", tenantB, true},
+		{"+905551234568", uuid.Nil, true},
+	} {
+		// Act
+		blocked, err := svc.IsBlocked(context.Background(), tc.phone, tc.tenantID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, tc.blocked, blocked, tc)
+	}
+}
+
+func TestIsBlocked_ServedFromCache(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	mockCache := new(MockBlacklistCache)
+	svc := service.NewBlacklistService(mockRepo, mockCache, nil)
+
+	mockCache.On("Get", mock.Anything, "+905551234567").Return([]uuid.UUID{uuid.Nil}, true, nil)
+
+	// Act
+	blocked, err := svc.IsBlocked(context.Background(), "+905551234567", uuid.New())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	mockRepo.AssertNotCalled(t, "FindTenantsBlocking", mock.Anything, mock.Anything)
+}
+
+func TestIsBlocked_CacheFailureFallsBackToDatabase(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	mockCache := new(MockBlacklistCache)
+	svc := service.NewBlacklistService(mockRepo, mockCache, nil)
+
+	mockCache.On("Get", mock.Anything, "+905551234567").Return(nil, false, errors.New("connection refused"))
+	mockCache.On("Set", mock.Anything, "+905551234567", []uuid.UUID{}).Return(errors.New("connection refused"))
+	mockRepo.On("FindTenantsBlocking", mock.Anything, "+905551234567").Return([]uuid.UUID{}, nil)
+
+	// Act
+	blocked, err := svc.IsBlocked(context.Background(), "+905551234567", uuid.Nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIsBlocked_DatabaseFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	svc := service.NewBlacklistService(mockRepo, nil, nil)
+
+	mockRepo.On("FindTenantsBlocking", mock.Anything, mock.Anything).
+		Return(nil, apperrors.NewDatabaseError(errors.New("connection refused")))
+
+	// Act
+	blocked, err := svc.IsBlocked(context.Background(), "+905551234567", uuid.Nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, blocked)
+}
+
+func TestRemoveBlacklistEntry_InvalidatesCache(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBlacklistRepository)
+	mockCache := new(MockBlacklistCache)
+	svc := service.NewBlacklistService(mockRepo, mockCache, nil)
+
+	entry := &repository.BlacklistEntry{ID: uuid.New(), PhoneNumber: "+905551234567"}
+	mockRepo.On("Delete", mock.Anything, entry.ID).Return(entry, nil)
+	mockCache.On("Invalidate", mock.Anything, "+905551234567").Return(nil)
+
+	// Act
+	err := svc.RemoveEntry(context.Background(), entry.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	mockCache.AssertExpectations(t)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const cacheWarmPageSize = 100
+
+// CacheWarmer repopulates the sent-message cache from Postgres, e.g. after
+// Redis lost its data. It stops at whichever comes first: limit messages or
+// a sent_at older than maxAge. A zero limit or maxAge disables that bound.
+type CacheWarmer struct {
+	repo         repository.MessageRepository
+	messageCache cache.MessageCache
+	limit        int
+	maxAge       time.Duration
+}
+
+func NewCacheWarmer(
+	repo repository.MessageRepository,
+	messageCache cache.MessageCache,
+	limit int,
+	maxAge time.Duration,
+) *CacheWarmer {
+	return &CacheWarmer{
+		repo:         repo,
+		messageCache: messageCache,
+		limit:        limit,
+		maxAge:       maxAge,
+	}
+}
+
+// Run walks sent messages newest first and caches them, returning how many
+// were written. Individual cache failures are logged and skipped.
+func (w *CacheWarmer) Run(ctx context.Context) (int, error) {
+	start := time.Now()
+
+	var cutoff time.Time
+	if w.maxAge > 0 {
+		cutoff = start.Add(-w.maxAge)
+	}
+
+	warmed := 0
+	for offset := 0; ; offset += cacheWarmPageSize {
+		pageSize := cacheWarmPageSize
+		if w.limit > 0 && w.limit-offset < pageSize {
+			pageSize = w.limit - offset
+		}
+		if pageSize <= 0 {
+			break
+		}
+
+		messages, err := w.repo.FindSentMessages(ctx, pageSize, offset)
+		if err != nil {
+			return warmed, err
+		}
+
+		for _, message := range messages {
+			if message.SentAt() == nil {
+				continue
+			}
+			if !cutoff.IsZero() && message.SentAt().Before(cutoff) {
+				w.logDone(warmed, start)
+				return warmed, nil
+			}
+
+			if err := w.messageCache.CacheSentMessage(ctx, toCachedMessage(message)); err != nil {
+				logger.Get().Warn("failed to warm cache entry",
+					zap.Error(err),
+					zap.String("message_id", message.ID().String()),
+				)
+				continue
+			}
+			warmed++
+		}
+
+		if len(messages) < pageSize {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return warmed, err
+		}
+	}
+
+	w.logDone(warmed, start)
+	return warmed, nil
+}
+
+func (w *CacheWarmer) logDone(warmed int, start time.Time) {
+	logger.Get().Info("sent message cache warmed",
+		zap.Int("messages", warmed),
+		zap.Duration("duration", time.Since(start)),
+	)
+}
+
+func toCachedMessage(message *entity.Message) *cache.CachedMessage {
+	return &cache.CachedMessage{
+		MessageID:        message.ID().String(),
+		WebhookMessageID: message.WebhookMessageID(),
+		ContentHash:      message.ContentHash(),
+		SentAt:           *message.SentAt(),
+		PhoneNumber:      phoneNumberOf(message),
+	}
+}
@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newSentMessage(sentAt time.Time) *entity.Message {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	return entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(), valueobject.MessageStatusSent,
+		sentAt, &sentAt, nil, nil, nil, nil, 1, 3, "", "", "wh-1", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 1,
+	)
+}
+
+func TestCacheWarmer_StopsAtMaxAge(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockCache := new(MockMessageCache)
+
+	recent := newSentMessage(time.Now().Add(-time.Hour))
+	old := newSentMessage(time.Now().Add(-48 * time.Hour))
+
+	mockRepo.On("FindSentMessages", mock.Anything, 100, 0).
+		Return([]*entity.Message{recent, old}, nil)
+	mockCache.On("CacheSentMessage", mock.Anything, mock.MatchedBy(func(msg *cache.CachedMessage) bool {
+		return msg.MessageID == recent.ID().String() && msg.WebhookMessageID == "wh-1"
+	})).Return(nil)
+
+	warmer := service.NewCacheWarmer(mockRepo, mockCache, 1000, 24*time.Hour)
+
+	// Act
+	warmed, err := warmer.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, warmed)
+	mockCache.AssertNumberOfCalls(t, "CacheSentMessage", 1)
+}
+
+func TestCacheWarmer_RespectsLimitAcrossPages(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockCache := new(MockMessageCache)
+
+	firstPage := make([]*entity.Message, 100)
+	for i := range firstPage {
+		firstPage[i] = newSentMessage(time.Now())
+	}
+	secondPage := make([]*entity.Message, 50)
+	for i := range secondPage {
+		secondPage[i] = newSentMessage(time.Now())
+	}
+
+	mockRepo.On("FindSentMessages", mock.Anything, 100, 0).Return(firstPage, nil)
+	mockRepo.On("FindSentMessages", mock.Anything, 50, 100).Return(secondPage, nil)
+	mockCache.On("CacheSentMessage", mock.Anything, mock.Anything).Return(nil)
+
+	warmer := service.NewCacheWarmer(mockRepo, mockCache, 150, 0)
+
+	// Act
+	warmed, err := warmer.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 150, warmed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheWarmer_RepositoryError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockCache := new(MockMessageCache)
+
+	mockRepo.On("FindSentMessages", mock.Anything, 100, 0).Return(nil, errors.New("db down"))
+
+	warmer := service.NewCacheWarmer(mockRepo, mockCache, 1000, time.Hour)
+
+	// Act
+	warmed, err := warmer.Run(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, warmed)
+	mockCache.AssertNotCalled(t, "CacheSentMessage", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxCampaignBatchSize bounds the messages created by one request.
+const maxCampaignBatchSize = 1000
+
+type CampaignService interface {
+	CreateCampaign(ctx context.Context, req *dto.CreateCampaignRequest) (*dto.CampaignBatchResponse, error)
+	AddMessages(ctx context.Context, id uuid.UUID, req *dto.AddCampaignMessagesRequest) (*dto.CampaignBatchResponse, error)
+	GetCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error)
+	ListCampaigns(ctx context.Context, page, pageSize int) (*dto.CampaignListResponse, error)
+	// PauseCampaign keeps the campaign's pending messages from being
+	// dispatched until it is resumed; messages already claimed still go out.
+	PauseCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error)
+	ResumeCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error)
+}
+
+type campaignService struct {
+	repo     repository.CampaignRepository
+	messages repository.MessageRepository
+	creator  MessageService
+	audit    AuditService
+}
+
+func NewCampaignService(
+	repo repository.CampaignRepository,
+	messages repository.MessageRepository,
+	creator MessageService,
+	audit AuditService,
+) CampaignService {
+	return &campaignService{
+		repo:     repo,
+		messages: messages,
+		creator:  creator,
+		audit:    audit,
+	}
+}
+
+func (s *campaignService) CreateCampaign(ctx context.Context, req *dto.CreateCampaignRequest) (*dto.CampaignBatchResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, apperrors.NewValidationError("name is required")
+	}
+	if err := validateCampaignBatch(req.Messages, false); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	campaign := &repository.Campaign{
+		ID:        uuid.New(),
+		Name:      name,
+		Status:    repository.CampaignStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		campaign.TenantID = tenantID
+	}
+
+	if err := s.repo.Create(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("campaign created",
+		zap.String("campaign_id", campaign.ID.String()),
+		zap.String("name", campaign.Name),
+	)
+	s.recordAudit(ctx, AuditActionCampaignCreated, campaign, "", name)
+
+	return s.createMessages(ctx, campaign, req.Messages)
+}
+
+func (s *campaignService) AddMessages(ctx context.Context, id uuid.UUID, req *dto.AddCampaignMessagesRequest) (*dto.CampaignBatchResponse, error) {
+	if err := validateCampaignBatch(req.Messages, true); err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createMessages(ctx, campaign, req.Messages)
+}
+
+// createMessages creates each message under the campaign. A message that
+// fails validation is reported by its index and does not stop the others.
+func (s *campaignService) createMessages(ctx context.Context, campaign *repository.Campaign, reqs []dto.CreateMessageRequest) (*dto.CampaignBatchResponse, error) {
+	resp := &dto.CampaignBatchResponse{
+		Created: make([]dto.MessageResponse, 0, len(reqs)),
+	}
+
+	for i := range reqs {
+		req := reqs[i]
+		req.CampaignID = campaign.ID
+
+		message, err := s.creator.CreateMessage(ctx, &req)
+		if err != nil {
+			code := string(apperrors.ErrorCodeInternal)
+			if appErr, ok := err.(*apperrors.AppError); ok {
+				code = string(appErr.Code)
+			}
+			resp.Errors = append(resp.Errors, dto.CampaignMessageError{
+				Index: i,
+				Code:  code,
+				Error: err.Error(),
+			})
+			continue
+		}
+		resp.Created = append(resp.Created, *message)
+	}
+
+	if len(resp.Errors) > 0 {
+		logger.FromContext(ctx).Warn("some campaign messages were not created",
+			zap.String("campaign_id", campaign.ID.String()),
+			zap.Int("created", len(resp.Created)),
+			zap.Int("rejected", len(resp.Errors)),
+		)
+	}
+
+	campaignResp, err := s.withStats(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+	resp.Campaign = *campaignResp
+
+	return resp, nil
+}
+
+func (s *campaignService) GetCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error) {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withStats(ctx, campaign)
+}
+
+func (s *campaignService) ListCampaigns(ctx context.Context, page, pageSize int) (*dto.CampaignListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	campaigns, total, err := s.repo.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.CampaignResponse, len(campaigns))
+	for i, campaign := range campaigns {
+		responses[i] = toCampaignDTO(campaign)
+	}
+
+	return &dto.CampaignListResponse{
+		Campaigns:  responses,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *campaignService) PauseCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error) {
+	return s.setStatus(ctx, id, repository.CampaignStatusPaused, AuditActionCampaignPaused)
+}
+
+func (s *campaignService) ResumeCampaign(ctx context.Context, id uuid.UUID) (*dto.CampaignResponse, error) {
+	return s.setStatus(ctx, id, repository.CampaignStatusActive, AuditActionCampaignResumed)
+}
+
+// setStatus moves the campaign to status; a campaign already there is
+// returned unchanged.
+func (s *campaignService) setStatus(ctx context.Context, id uuid.UUID, status, action string) (*dto.CampaignResponse, error) {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.Status != status {
+		from := campaign.Status
+		campaign.Status = status
+		campaign.UpdatedAt = time.Now().UTC()
+
+		if err := s.repo.Update(ctx, campaign); err != nil {
+			return nil, err
+		}
+
+		logger.FromContext(ctx).Info("campaign status changed",
+			zap.String("campaign_id", campaign.ID.String()),
+			zap.String("from", from),
+			zap.String("to", status),
+		)
+		s.recordAudit(ctx, action, campaign, from, "")
+	}
+
+	return s.withStats(ctx, campaign)
+}
+
+func (s *campaignService) withStats(ctx context.Context, campaign *repository.Campaign) (*dto.CampaignResponse, error) {
+	stats, err := s.messages.GetCampaignStats(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toCampaignDTO(campaign)
+	resp.Stats = toStatsDTO(stats)
+	return &resp, nil
+}
+
+func (s *campaignService) recordAudit(ctx context.Context, action string, campaign *repository.Campaign, from, details string) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, AuditRecord{
+		Action:       action,
+		ResourceType: AuditResourceCampaign,
+		ResourceID:   campaign.ID.String(),
+		TenantID:     campaign.TenantID,
+		FromStatus:   from,
+		ToStatus:     campaign.Status,
+		Details:      details,
+	})
+}
+
+func validateCampaignBatch(messages []dto.CreateMessageRequest, required bool) error {
+	if required && len(messages) == 0 {
+		return apperrors.NewValidationError("messages must not be empty")
+	}
+	if len(messages) > maxCampaignBatchSize {
+		return apperrors.NewValidationError(
+			fmt.Sprintf("at most %d messages can be added at once", maxCampaignBatchSize))
+	}
+	return nil
+}
+
+func toCampaignDTO(campaign *repository.Campaign) dto.CampaignResponse {
+	return dto.CampaignResponse{
+		ID:        campaign.ID.String(),
+		Name:      campaign.Name,
+		Status:    campaign.Status,
+		TenantID:  optionalID(campaign.TenantID),
+		CreatedAt: campaign.CreatedAt,
+		UpdatedAt: campaign.UpdatedAt,
+	}
+}
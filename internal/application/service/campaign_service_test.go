@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepository) Create(ctx context.Context, campaign *repository.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) Update(ctx context.Context, campaign *repository.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.Campaign, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) List(ctx context.Context, limit, offset int) ([]*repository.Campaign, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.Campaign), args.Get(1).(int64), args.Error(2)
+}
+
+func newCampaignService(campaignRepo *MockCampaignRepository, messageRepo *MockMessageRepository) service.CampaignService {
+	messages := service.NewMessageService(messageRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	return service.NewCampaignService(campaignRepo, messageRepo, messages, nil)
+}
+
+func TestCreateCampaign_CreatesMessagesUnderCampaign(t *testing.T) {
+	// Arrange
+	campaignRepo := new(MockCampaignRepository)
+	messageRepo := new(MockMessageRepository)
+	svc := newCampaignService(campaignRepo, messageRepo)
+
+	var stored *repository.Campaign
+	campaignRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.Campaign")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*repository.Campaign) }).
+		Return(nil)
+
+	var created []*entity.Message
+	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*entity.Message)) }).
+		Return(nil)
+	messageRepo.On("GetCampaignStats", mock.Anything, mock.AnythingOfType("uuid.UUID")).
+		Return(&repository.MessageStats{TotalMessages: 1, PendingMessages: 1}, nil)
+
+	req := &dto.CreateCampaignRequest{
+		Name: " spring sale ",
+		Messages: []dto.CreateMessageRequest{
+			{PhoneNumber: "+905551234567", Content: "Sale starts today"},
+			{PhoneNumber: "not-a-number", Content: "Sale starts today"},
+		},
+	}
+
+	// Act
+	result, err := svc.CreateCampaign(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "spring sale", result.Campaign.Name)
+	assert.Equal(t, repository.CampaignStatusActive, result.Campaign.Status)
+	assert.Equal(t, int64(1), result.Campaign.Stats.PendingMessages)
+	assert.Len(t, result.Created, 1)
+	assert.Equal(t, stored.ID.String(), result.Created[0].CampaignID)
+	assert.Len(t, created, 1)
+	assert.Equal(t, stored.ID, created[0].CampaignID())
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, 1, result.Errors[0].Index)
+	assert.Equal(t, string(apperrors.ErrorCodeValidation), result.Errors[0].Code)
+	messageRepo.AssertExpectations(t)
+}
+
+func TestCreateCampaign_RequiresName(t *testing.T) {
+	// Arrange
+	campaignRepo := new(MockCampaignRepository)
+	svc := newCampaignService(campaignRepo, new(MockMessageRepository))
+
+	// Act
+	result, err := svc.CreateCampaign(context.Background(), &dto.CreateCampaignRequest{Name: "  "})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	campaignRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAddMessages_UnknownCampaign(t *testing.T) {
+	// Arrange
+	campaignRepo := new(MockCampaignRepository)
+	messageRepo := new(MockMessageRepository)
+	svc := newCampaignService(campaignRepo, messageRepo)
+
+	id := uuid.New()
+	campaignRepo.On("FindByID", mock.Anything, id).
+		Return(nil, apperrors.NewNotFoundError("record not found"))
+
+	req := &dto.AddCampaignMessagesRequest{
+		Messages: []dto.CreateMessageRequest{{PhoneNumber: "+905551234567", Content: "Hello"}},
+	}
+
+	// Act
+	result, err := svc.AddMessages(context.Background(), id, req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPauseCampaign_StoresPausedStatus(t *testing.T) {
+	// Arrange
+	campaignRepo := new(MockCampaignRepository)
+	messageRepo := new(MockMessageRepository)
+	svc := newCampaignService(campaignRepo, messageRepo)
+
+	campaign := &repository.Campaign{ID: uuid.New(), Name: "launch", Status: repository.CampaignStatusActive}
+	campaignRepo.On("FindByID", mock.Anything, campaign.ID).Return(campaign, nil)
+	campaignRepo.On("Update", mock.Anything, mock.MatchedBy(func(c *repository.Campaign) bool {
+		return c.Status == repository.CampaignStatusPaused
+	})).Return(nil)
+	messageRepo.On("GetCampaignStats", mock.Anything, campaign.ID).
+		Return(&repository.MessageStats{TotalMessages: 3, PendingMessages: 2, SentMessages: 1}, nil)
+
+	// Act
+	result, err := svc.PauseCampaign(context.Background(), campaign.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, repository.CampaignStatusPaused, result.Status)
+	assert.Equal(t, int64(2), result.Stats.PendingMessages)
+	assert.Equal(t, int64(1), result.Stats.SentMessages)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestResumeCampaign_AlreadyActiveIsUnchanged(t *testing.T) {
+	// Arrange
+	campaignRepo := new(MockCampaignRepository)
+	messageRepo := new(MockMessageRepository)
+	svc := newCampaignService(campaignRepo, messageRepo)
+
+	campaign := &repository.Campaign{ID: uuid.New(), Name: "launch", Status: repository.CampaignStatusActive}
+	campaignRepo.On("FindByID", mock.Anything, campaign.ID).Return(campaign, nil)
+	messageRepo.On("GetCampaignStats", mock.Anything, campaign.ID).
+		Return(&repository.MessageStats{}, nil)
+
+	// Act
+	result, err := svc.ResumeCampaign(context.Background(), campaign.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, repository.CampaignStatusActive, result.Status)
+	campaignRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxConflictAttempts bounds how often a read-change-write runs before a
+// lost optimistic lock race is reported to the caller.
+const maxConflictAttempts = 3
+
+// errUnchanged is returned by a change that finds the message already in the
+// wanted state, so there is nothing to write.
+var errUnchanged = errors.New("message unchanged")
+
+// updateWithRetry loads a message, applies change and saves it. When the save
+// loses an optimistic lock race the message is loaded again and change runs
+// on the fresh copy, so whether a transition is still allowed is always
+// decided against the latest state. Errors from load and change are returned
+// as they are; changed is false when change returned errUnchanged.
+func (s *messageService) updateWithRetry(
+	ctx context.Context,
+	load func(ctx context.Context) (*entity.Message, error),
+	change func(message *entity.Message) error,
+) (message *entity.Message, changed bool, err error) {
+	for attempt := 1; ; attempt++ {
+		message, err = load(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if err := change(message); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return message, false, nil
+			}
+			return nil, false, err
+		}
+
+		err = s.repo.Update(ctx, message)
+		if err == nil {
+			return message, true, nil
+		}
+		if !apperrors.IsVersionConflict(err) || attempt == maxConflictAttempts {
+			return nil, false, err
+		}
+
+		logger.FromContext(ctx).Debug("message changed concurrently, retrying update",
+			zap.String("message_id", message.ID().String()),
+			zap.Int("attempt", attempt),
+		)
+	}
+}
+
+// findByID is the load function for updates addressed by message ID.
+func (s *messageService) findByID(id uuid.UUID) func(ctx context.Context) (*entity.Message, error) {
+	return func(ctx context.Context) (*entity.Message, error) {
+		return s.repo.FindByID(ctx, id)
+	}
+}
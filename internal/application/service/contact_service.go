@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxGroupSendSize bounds the messages created by one send to a group,
+	// the same as a campaign batch.
+	maxGroupSendSize = maxCampaignBatchSize
+	// maxGroupMembersBatch bounds the contacts added to a group at once.
+	maxGroupMembersBatch = 1000
+	// groupSendPageSize is how many members are read at a time while sending.
+	groupSendPageSize = 100
+	// maxGroupClientReferenceLength leaves room for the ":<contact_id>"
+	// suffix of each member's reference.
+	maxGroupClientReferenceLength = maxClientReferenceLength - 37
+	maxContactNameLength          = 255
+)
+
+type ContactService interface {
+	CreateContact(ctx context.Context, req *dto.CreateContactRequest) (*dto.ContactResponse, error)
+	GetContact(ctx context.Context, id uuid.UUID) (*dto.ContactResponse, error)
+	ListContacts(ctx context.Context, page, pageSize int) (*dto.ContactListResponse, error)
+	UpdateContact(ctx context.Context, id uuid.UUID, req *dto.UpdateContactRequest) (*dto.ContactResponse, error)
+	DeleteContact(ctx context.Context, id uuid.UUID) error
+
+	CreateGroup(ctx context.Context, req *dto.CreateGroupRequest) (*dto.GroupResponse, error)
+	GetGroup(ctx context.Context, id uuid.UUID) (*dto.GroupResponse, error)
+	ListGroups(ctx context.Context, page, pageSize int) (*dto.GroupListResponse, error)
+	UpdateGroup(ctx context.Context, id uuid.UUID, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error)
+	DeleteGroup(ctx context.Context, id uuid.UUID) error
+	AddMembers(ctx context.Context, groupID uuid.UUID, req *dto.AddGroupMembersRequest) (*dto.AddGroupMembersResponse, error)
+	RemoveMember(ctx context.Context, groupID, contactID uuid.UUID) error
+	ListMembers(ctx context.Context, groupID uuid.UUID, page, pageSize int) (*dto.GroupMemberListResponse, error)
+	// SendToGroup creates one message per member of the group. A member the
+	// message cannot be created for is reported and does not stop the others.
+	SendToGroup(ctx context.Context, groupID uuid.UUID, req *dto.SendToGroupRequest) (*dto.GroupSendResponse, error)
+}
+
+type contactService struct {
+	contacts repository.ContactRepository
+	groups   repository.ContactGroupRepository
+	creator  MessageService
+}
+
+func NewContactService(contacts repository.ContactRepository, groups repository.ContactGroupRepository, creator MessageService) ContactService {
+	return &contactService{
+		contacts: contacts,
+		groups:   groups,
+		creator:  creator,
+	}
+}
+
+func (s *contactService) CreateContact(ctx context.Context, req *dto.CreateContactRequest) (*dto.ContactResponse, error) {
+	now := time.Now().UTC()
+	contact := &repository.Contact{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		contact.TenantID = tenantID
+	}
+
+	if err := applyContactFields(contact, &req.Name, &req.PhoneNumber, &req.Email, &req.DeviceToken); err != nil {
+		return nil, err
+	}
+
+	if err := s.contacts.Create(ctx, contact); err != nil {
+		return nil, contactConflict(err)
+	}
+
+	logger.FromContext(ctx).Info("contact created",
+		zap.String("contact_id", contact.ID.String()),
+	)
+
+	resp := toContactDTO(contact)
+	return &resp, nil
+}
+
+func (s *contactService) GetContact(ctx context.Context, id uuid.UUID) (*dto.ContactResponse, error) {
+	contact, err := s.contacts.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toContactDTO(contact)
+	return &resp, nil
+}
+
+func (s *contactService) ListContacts(ctx context.Context, page, pageSize int) (*dto.ContactListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	contacts, total, err := s.contacts.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ContactListResponse{
+		Contacts:   toContactDTOs(contacts),
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *contactService) UpdateContact(ctx context.Context, id uuid.UUID, req *dto.UpdateContactRequest) (*dto.ContactResponse, error) {
+	contact, err := s.contacts.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyContactFields(contact, req.Name, req.PhoneNumber, req.Email, req.DeviceToken); err != nil {
+		return nil, err
+	}
+	contact.UpdatedAt = time.Now().UTC()
+
+	if err := s.contacts.Update(ctx, contact); err != nil {
+		return nil, contactConflict(err)
+	}
+
+	logger.FromContext(ctx).Info("contact updated",
+		zap.String("contact_id", contact.ID.String()),
+	)
+
+	resp := toContactDTO(contact)
+	return &resp, nil
+}
+
+func (s *contactService) DeleteContact(ctx context.Context, id uuid.UUID) error {
+	if err := s.contacts.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("contact deleted",
+		zap.String("contact_id", id.String()),
+	)
+	return nil
+}
+
+func (s *contactService) CreateGroup(ctx context.Context, req *dto.CreateGroupRequest) (*dto.GroupResponse, error) {
+	name, err := groupName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	group := &repository.ContactGroup{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		group.TenantID = tenantID
+	}
+
+	if err := s.groups.Create(ctx, group); err != nil {
+		return nil, groupConflict(err)
+	}
+
+	logger.FromContext(ctx).Info("contact group created",
+		zap.String("group_id", group.ID.String()),
+		zap.String("name", group.Name),
+	)
+
+	resp := toGroupDTO(group)
+	return &resp, nil
+}
+
+func (s *contactService) GetGroup(ctx context.Context, id uuid.UUID) (*dto.GroupResponse, error) {
+	group, err := s.groups.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toGroupDTO(group)
+	return &resp, nil
+}
+
+func (s *contactService) ListGroups(ctx context.Context, page, pageSize int) (*dto.GroupListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	groups, total, err := s.groups.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.GroupResponse, len(groups))
+	for i, group := range groups {
+		responses[i] = toGroupDTO(group)
+	}
+
+	return &dto.GroupListResponse{
+		Groups:     responses,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *contactService) UpdateGroup(ctx context.Context, id uuid.UUID, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error) {
+	name, err := groupName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.groups.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	group.Name = name
+	group.UpdatedAt = time.Now().UTC()
+
+	if err := s.groups.Update(ctx, group); err != nil {
+		return nil, groupConflict(err)
+	}
+
+	resp := toGroupDTO(group)
+	return &resp, nil
+}
+
+func (s *contactService) DeleteGroup(ctx context.Context, id uuid.UUID) error {
+	if err := s.groups.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("contact group deleted",
+		zap.String("group_id", id.String()),
+	)
+	return nil
+}
+
+func (s *contactService) AddMembers(ctx context.Context, groupID uuid.UUID, req *dto.AddGroupMembersRequest) (*dto.AddGroupMembersResponse, error) {
+	if len(req.ContactIDs) == 0 {
+		return nil, apperrors.NewValidationError("contact_ids must not be empty")
+	}
+	if len(req.ContactIDs) > maxGroupMembersBatch {
+		return nil, apperrors.NewValidationError(
+			fmt.Sprintf("at most %d contacts can be added at once", maxGroupMembersBatch))
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.ContactIDs))
+	seen := make(map[uuid.UUID]bool, len(req.ContactIDs))
+	for _, raw := range req.ContactIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("contact ID %q is not a UUID", raw))
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if _, err := s.groups.FindByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	// Contacts of another tenant are not found, so they cannot be added.
+	found, err := s.contacts.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(ids) {
+		existing := make(map[uuid.UUID]bool, len(found))
+		for _, contact := range found {
+			existing[contact.ID] = true
+		}
+		for _, id := range ids {
+			if !existing[id] {
+				return nil, apperrors.NewValidationError(fmt.Sprintf("contact %s does not exist", id))
+			}
+		}
+	}
+
+	added, err := s.groups.AddMembers(ctx, groupID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.groups.FindByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("contacts added to group",
+		zap.String("group_id", groupID.String()),
+		zap.Int64("added", added),
+	)
+
+	return &dto.AddGroupMembersResponse{
+		Added: added,
+		Group: toGroupDTO(group),
+	}, nil
+}
+
+func (s *contactService) RemoveMember(ctx context.Context, groupID, contactID uuid.UUID) error {
+	if _, err := s.groups.FindByID(ctx, groupID); err != nil {
+		return err
+	}
+	return s.groups.RemoveMember(ctx, groupID, contactID)
+}
+
+func (s *contactService) ListMembers(ctx context.Context, groupID uuid.UUID, page, pageSize int) (*dto.GroupMemberListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if _, err := s.groups.FindByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	members, total, err := s.groups.ListMembers(ctx, groupID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GroupMemberListResponse{
+		Members:    toContactDTOs(members),
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *contactService) SendToGroup(ctx context.Context, groupID uuid.UUID, req *dto.SendToGroupRequest) (*dto.GroupSendResponse, error) {
+	channel, err := valueobject.NewChannel(req.Channel)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	if len(req.ClientReference) > maxGroupClientReferenceLength {
+		return nil, apperrors.NewValidationError(fmt.Sprintf(
+			"client_reference must be at most %d characters when sending to a group", maxGroupClientReferenceLength))
+	}
+
+	group, err := s.groups.FindByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.MemberCount == 0 {
+		return nil, apperrors.NewValidationError("group has no members")
+	}
+	if group.MemberCount > maxGroupSendSize {
+		return nil, apperrors.NewValidationError(
+			fmt.Sprintf("groups of more than %d members cannot be sent to at once", maxGroupSendSize))
+	}
+
+	resp := &dto.GroupSendResponse{
+		GroupID: group.ID.String(),
+		Created: make([]dto.MessageResponse, 0, group.MemberCount),
+	}
+
+	for offset := 0; ; offset += groupSendPageSize {
+		members, _, err := s.groups.ListMembers(ctx, groupID, groupSendPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			message, err := s.sendToMember(ctx, channel, member, req)
+			if err != nil {
+				code := string(apperrors.ErrorCodeInternal)
+				if appErr, ok := err.(*apperrors.AppError); ok {
+					code = string(appErr.Code)
+				}
+				resp.Errors = append(resp.Errors, dto.GroupMessageError{
+					ContactID: member.ID.String(),
+					Code:      code,
+					Error:     err.Error(),
+				})
+				continue
+			}
+			resp.Created = append(resp.Created, *message)
+		}
+
+		if len(members) < groupSendPageSize {
+			break
+		}
+	}
+
+	log := logger.FromContext(ctx)
+	log.Info("message sent to group",
+		zap.String("group_id", group.ID.String()),
+		zap.Int("created", len(resp.Created)),
+	)
+	if len(resp.Errors) > 0 {
+		log.Warn("some group messages were not created",
+			zap.String("group_id", group.ID.String()),
+			zap.Int("rejected", len(resp.Errors)),
+		)
+	}
+
+	return resp, nil
+}
+
+func (s *contactService) sendToMember(ctx context.Context, channel valueobject.Channel, member *repository.Contact, req *dto.SendToGroupRequest) (*dto.MessageResponse, error) {
+	messageReq := &dto.CreateMessageRequest{
+		Channel:       channel.String(),
+		Content:       req.Content,
+		ScheduledAt:   req.ScheduledAt,
+		ExpiresAt:     req.ExpiresAt,
+		Priority:      req.Priority,
+		Metadata:      req.Metadata,
+		DestinationID: req.DestinationID,
+		DryRun:        req.DryRun,
+		CallbackURL:   req.CallbackURL,
+	}
+	if req.ClientReference != "" {
+		messageReq.ClientReference = req.ClientReference + ":" + member.ID.String()
+	}
+
+	switch channel {
+	case valueobject.ChannelSMS:
+		messageReq.PhoneNumber = member.PhoneNumber
+	case valueobject.ChannelEmail:
+		messageReq.Recipient = member.Email
+	case valueobject.ChannelPush:
+		messageReq.Recipient = member.DeviceToken
+	}
+	if messageReq.PhoneNumber == "" && messageReq.Recipient == "" {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("contact has no %s address", channel))
+	}
+
+	return s.creator.CreateMessage(ctx, messageReq)
+}
+
+// applyContactFields validates and sets the fields that are not nil; an
+// empty address clears it. The contact must keep at least one address.
+func applyContactFields(contact *repository.Contact, name, phoneNumber, email, deviceToken *string) error {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if len(trimmed) > maxContactNameLength {
+			return apperrors.NewValidationError(
+				fmt.Sprintf("name must be at most %d characters", maxContactNameLength))
+		}
+		contact.Name = trimmed
+	}
+
+	addresses := []struct {
+		channel valueobject.Channel
+		value   *string
+		field   *string
+	}{
+		{valueobject.ChannelSMS, phoneNumber, &contact.PhoneNumber},
+		{valueobject.ChannelEmail, email, &contact.Email},
+		{valueobject.ChannelPush, deviceToken, &contact.DeviceToken},
+	}
+	for _, address := range addresses {
+		if address.value == nil {
+			continue
+		}
+		if *address.value == "" {
+			*address.field = ""
+			continue
+		}
+		recipient, err := valueobject.NewRecipient(address.channel, *address.value)
+		if err != nil {
+			return apperrors.NewValidationError(err.Error())
+		}
+		*address.field = recipient.String()
+	}
+
+	if contact.PhoneNumber == "" && contact.Email == "" && contact.DeviceToken == "" {
+		return apperrors.NewValidationError("one of phone_number, email and device_token is required")
+	}
+	return nil
+}
+
+func groupName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", apperrors.NewValidationError("name is required")
+	}
+	if len(name) > maxContactNameLength {
+		return "", apperrors.NewValidationError(
+			fmt.Sprintf("name must be at most %d characters", maxContactNameLength))
+	}
+	return name, nil
+}
+
+func contactConflict(err error) error {
+	if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists {
+		return apperrors.New(apperrors.ErrorCodeAlreadyExists, "a contact with this phone number already exists")
+	}
+	return err
+}
+
+func groupConflict(err error) error {
+	if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists {
+		return apperrors.New(apperrors.ErrorCodeAlreadyExists, "a group with this name already exists")
+	}
+	return err
+}
+
+func toContactDTO(contact *repository.Contact) dto.ContactResponse {
+	return dto.ContactResponse{
+		ID:          contact.ID.String(),
+		Name:        contact.Name,
+		PhoneNumber: contact.PhoneNumber,
+		Email:       contact.Email,
+		DeviceToken: contact.DeviceToken,
+		TenantID:    optionalID(contact.TenantID),
+		CreatedAt:   contact.CreatedAt,
+		UpdatedAt:   contact.UpdatedAt,
+	}
+}
+
+func toContactDTOs(contacts []*repository.Contact) []dto.ContactResponse {
+	responses := make([]dto.ContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = toContactDTO(contact)
+	}
+	return responses
+}
+
+func toGroupDTO(group *repository.ContactGroup) dto.GroupResponse {
+	return dto.GroupResponse{
+		ID:          group.ID.String(),
+		Name:        group.Name,
+		MemberCount: group.MemberCount,
+		TenantID:    optionalID(group.TenantID),
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockContactRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactRepository) Create(ctx context.Context, contact *repository.Contact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) Update(ctx context.Context, contact *repository.Contact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockContactRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.Contact, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Contact), args.Error(1)
+}
+
+func (m *MockContactRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*repository.Contact, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Contact), args.Error(1)
+}
+
+func (m *MockContactRepository) List(ctx context.Context, limit, offset int) ([]*repository.Contact, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.Contact), args.Get(1).(int64), args.Error(2)
+}
+
+type MockContactGroupRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactGroupRepository) Create(ctx context.Context, group *repository.ContactGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockContactGroupRepository) Update(ctx context.Context, group *repository.ContactGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockContactGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockContactGroupRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.ContactGroup, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ContactGroup), args.Error(1)
+}
+
+func (m *MockContactGroupRepository) List(ctx context.Context, limit, offset int) ([]*repository.ContactGroup, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.ContactGroup), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockContactGroupRepository) AddMembers(ctx context.Context, groupID uuid.UUID, contactIDs []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, groupID, contactIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockContactGroupRepository) RemoveMember(ctx context.Context, groupID, contactID uuid.UUID) error {
+	args := m.Called(ctx, groupID, contactID)
+	return args.Error(0)
+}
+
+func (m *MockContactGroupRepository) ListMembers(ctx context.Context, groupID uuid.UUID, limit, offset int) ([]*repository.Contact, int64, error) {
+	args := m.Called(ctx, groupID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.Contact), args.Get(1).(int64), args.Error(2)
+}
+
+func newContactService(contacts *MockContactRepository, groups *MockContactGroupRepository, messageRepo *MockMessageRepository) service.ContactService {
+	messages := service.NewMessageService(messageRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	return service.NewContactService(contacts, groups, messages)
+}
+
+func TestCreateContact_NormalizesAddresses(t *testing.T) {
+	// Arrange
+	contacts := new(MockContactRepository)
+	svc := newContactService(contacts, new(MockContactGroupRepository), new(MockMessageRepository))
+
+	contacts.On("Create", mock.Anything, mock.AnythingOfType("*repository.Contact")).Return(nil)
+
+	req := &dto.CreateContactRequest{
+		Name:        " Jane ",
+		PhoneNumber: "+90 555 123 45 67",
+		Email:       "Jane@Example.COM",
+	}
+
+	// Act
+	result, err := svc.CreateContact(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane", result.Name)
+	assert.Equal(t, "+905551234567", result.PhoneNumber)
+	assert.Equal(t, "Jane@example.com", result.Email)
+	contacts.AssertExpectations(t)
+}
+
+func TestCreateContact_RequiresAnAddress(t *testing.T) {
+	// Arrange
+	contacts := new(MockContactRepository)
+	svc := newContactService(contacts, new(MockContactGroupRepository), new(MockMessageRepository))
+
+	// Act
+	result, err := svc.CreateContact(context.Background(), &dto.CreateContactRequest{Name: "Jane"})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	contacts.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAddMembers_RejectsUnknownContacts(t *testing.T) {
+	// Arrange
+	contacts := new(MockContactRepository)
+	groups := new(MockContactGroupRepository)
+	svc := newContactService(contacts, groups, new(MockMessageRepository))
+
+	groupID := uuid.New()
+	known := &repository.Contact{ID: uuid.New(), PhoneNumber: "+905551234567"}
+	unknown := uuid.New()
+	groups.On("FindByID", mock.Anything, groupID).Return(&repository.ContactGroup{ID: groupID}, nil)
+	contacts.On("FindByIDs", mock.Anything, []uuid.UUID{known.ID, unknown}).
+		Return([]*repository.Contact{known}, nil)
+
+	req := &dto.AddGroupMembersRequest{ContactIDs: []string{known.ID.String(), unknown.String(), known.ID.String()}}
+
+	// Act
+	result, err := svc.AddMembers(context.Background(), groupID, req)
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+		assert.Contains(t, appErr.Message, unknown.String())
+	}
+	groups.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendToGroup_CreatesMessagePerMember(t *testing.T) {
+	// Arrange
+	groups := new(MockContactGroupRepository)
+	messageRepo := new(MockMessageRepository)
+	svc := newContactService(new(MockContactRepository), groups, messageRepo)
+
+	groupID := uuid.New()
+	withPhone := &repository.Contact{ID: uuid.New(), PhoneNumber: "+905551234567"}
+	emailOnly := &repository.Contact{ID: uuid.New(), Email: "jane@example.com"}
+	groups.On("FindByID", mock.Anything, groupID).
+		Return(&repository.ContactGroup{ID: groupID, MemberCount: 2}, nil)
+	groups.On("ListMembers", mock.Anything, groupID, 100, 0).
+		Return([]*repository.Contact{withPhone, emailOnly}, int64(2), nil)
+
+	messageRepo.On("FindByIdempotencyKey", mock.Anything, "spring:"+withPhone.ID.String()).
+		Return(nil, apperrors.NewNotFoundError("message not found"))
+	var created []*entity.Message
+	messageRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*entity.Message)) }).
+		Return(nil)
+
+	req := &dto.SendToGroupRequest{Content: "Sale starts today", ClientReference: "spring"}
+
+	// Act
+	result, err := svc.SendToGroup(context.Background(), groupID, req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, groupID.String(), result.GroupID)
+	if assert.Len(t, created, 1) {
+		assert.Equal(t, "+905551234567", created[0].Recipient().String())
+		assert.Equal(t, "spring:"+withPhone.ID.String(), created[0].IdempotencyKey())
+	}
+	assert.Len(t, result.Created, 1)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, emailOnly.ID.String(), result.Errors[0].ContactID)
+		assert.Equal(t, string(apperrors.ErrorCodeValidation), result.Errors[0].Code)
+	}
+}
+
+func TestSendToGroup_EmptyGroup(t *testing.T) {
+	// Arrange
+	groups := new(MockContactGroupRepository)
+	svc := newContactService(new(MockContactRepository), groups, new(MockMessageRepository))
+
+	groupID := uuid.New()
+	groups.On("FindByID", mock.Anything, groupID).Return(&repository.ContactGroup{ID: groupID}, nil)
+
+	// Act
+	result, err := svc.SendToGroup(context.Background(), groupID, &dto.SendToGroupRequest{Content: "Hello"})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	groups.AssertNotCalled(t, "ListMembers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ContentFilter screens message content. It returns the content to use,
+// with anything it redacted replaced, or an error to refuse the message;
+// refusals should use apperrors.ErrorCodeContentRejected.
+type ContentFilter interface {
+	Filter(ctx context.Context, content string) (string, error)
+}
+
+// ContentModeration runs Filter on every new message and, with OnSend, again
+// right before each send, so that rules enabled after a message was stored
+// still apply to it. A nil moderation lets everything through.
+type ContentModeration struct {
+	Filter ContentFilter
+	OnSend bool
+}
+
+func (m *ContentModeration) screen(ctx context.Context, content string) (string, error) {
+	if m == nil || m.Filter == nil {
+		return content, nil
+	}
+	return m.Filter.Filter(ctx, content)
+}
+
+func (m *ContentModeration) screenBeforeSend(ctx context.Context, content string) (string, error) {
+	if m == nil || !m.OnSend {
+		return content, nil
+	}
+	return m.screen(ctx, content)
+}
+
+// Rules of the built-in content filter
+const (
+	ContentRuleProfanity  = "profanity"
+	ContentRuleCreditCard = "credit_card"
+	ContentRuleURL        = "url"
+)
+
+// What the built-in content filter does with a match
+const (
+	ContentActionOff    = "off"
+	ContentActionReject = "reject"
+	ContentActionRedact = "redact"
+)
+
+// contentRedaction replaces every redacted match
+const contentRedaction = "[redacted]"
+
+var (
+	// 13 to 19 digits, optionally grouped by spaces or dashes
+	creditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	urlPattern        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+)
+
+type contentRule struct {
+	name    string
+	pattern *regexp.Regexp
+	action  string
+	// valid, when set, drops matches that only look like a hit
+	valid func(match string) bool
+}
+
+// RegexContentFilter is the built-in ContentFilter. Rules run in a fixed
+// order; the first rule set to reject that matches refuses the message.
+type RegexContentFilter struct {
+	rules []contentRule
+}
+
+// NewRegexContentFilter configures each rule with actions, keyed by rule
+// name; missing rules are off. The profanity rule matches profanity as whole
+// words, ignoring case. Credit card numbers must pass the Luhn check.
+func NewRegexContentFilter(actions map[string]string, profanity []string) (*RegexContentFilter, error) {
+	filter := &RegexContentFilter{}
+
+	add := func(name string, pattern *regexp.Regexp, valid func(string) bool) error {
+		action := actions[name]
+		switch action {
+		case "", ContentActionOff:
+			return nil
+		case ContentActionReject, ContentActionRedact:
+		default:
+			return fmt.Errorf("invalid action %q for content rule %s", action, name)
+		}
+		filter.rules = append(filter.rules, contentRule{name: name, pattern: pattern, action: action, valid: valid})
+		return nil
+	}
+
+	if len(profanity) > 0 {
+		words := make([]string, 0, len(profanity))
+		for _, word := range profanity {
+			if word = strings.TrimSpace(word); word != "" {
+				words = append(words, regexp.QuoteMeta(word))
+			}
+		}
+		pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(words, "|") + `)\b`)
+		if err := add(ContentRuleProfanity, pattern, nil); err != nil {
+			return nil, err
+		}
+	} else if actions[ContentRuleProfanity] != "" && actions[ContentRuleProfanity] != ContentActionOff {
+		return nil, fmt.Errorf("content rule %s needs a word list", ContentRuleProfanity)
+	}
+
+	if err := add(ContentRuleCreditCard, creditCardPattern, passesLuhn); err != nil {
+		return nil, err
+	}
+	if err := add(ContentRuleURL, urlPattern, nil); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+func (f *RegexContentFilter) Filter(ctx context.Context, content string) (string, error) {
+	var redacted []string
+
+	for _, rule := range f.rules {
+		matched := false
+		replaced := rule.pattern.ReplaceAllStringFunc(content, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			matched = true
+			return contentRedaction
+		})
+		if !matched {
+			continue
+		}
+
+		if rule.action == ContentActionReject {
+			return "", apperrors.New(apperrors.ErrorCodeContentRejected,
+				fmt.Sprintf("message content is not allowed: matched %s rule", rule.name))
+		}
+		content = replaced
+		redacted = append(redacted, rule.name)
+	}
+
+	if len(redacted) > 0 {
+		logger.FromContext(ctx).Info("redacted message content",
+			zap.Strings("rules", redacted),
+		)
+	}
+
+	return content, nil
+}
+
+// passesLuhn reports whether the digits in number have a valid Luhn check
+// digit, which rules out most numbers that are not card numbers.
+func passesLuhn(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexContentFilter_Redacts(t *testing.T) {
+	// Arrange
+	filter, err := service.NewRegexContentFilter(map[string]string{
+		service.ContentRuleProfanity:  service.ContentActionRedact,
+		service.ContentRuleCreditCard: service.ContentActionRedact,
+		service.ContentRuleURL:        service.ContentActionRedact,
+	}, []string{"darn"})
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		content string
+		want    string
+	}{
+		{"Pay with 4111 1111 1111 1111 today", "Pay with [redacted] today"},
+		{"Order 4111111111111112 shipped", "Order 4111111111111112 shipped"},
+		{"See https://example.com/offer?id=1 or www.example.com", "See [redacted] or [redacted]"},
+		{"DARN it, darned printer", "[redacted] it, darned printer"},
+		{"Your code is 123456", "Your code is 123456"},
+	} {
+		// Act
+		filtered, err := filter.Filter(context.Background(), tc.content)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, filtered, tc.content)
+	}
+}
+
+func TestRegexContentFilter_Rejects(t *testing.T) {
+	// Arrange
+	filter, err := service.NewRegexContentFilter(map[string]string{
+		service.ContentRuleCreditCard: service.ContentActionRedact,
+		service.ContentRuleURL:        service.ContentActionReject,
+	}, nil)
+	assert.NoError(t, err)
+
+	// Act
+	filtered, err := filter.Filter(context.Background(), "Card 4111-1111-1111-1111, details at http://example.com")
+
+	// Assert
+	assert.Empty(t, filtered)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeContentRejected, appErr.Code)
+		assert.Contains(t, appErr.Message, "url")
+	}
+}
+
+func TestNewRegexContentFilter_InvalidConfig(t *testing.T) {
+	_, actionErr := service.NewRegexContentFilter(map[string]string{service.ContentRuleURL: "block"}, nil)
+	_, wordsErr := service.NewRegexContentFilter(map[string]string{service.ContentRuleProfanity: service.ContentActionReject}, nil)
+
+	assert.Error(t, actionErr)
+	assert.Error(t, wordsErr)
+}
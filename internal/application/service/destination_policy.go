@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+)
+
+// What happens to a message whose destination country is not allowed
+const (
+	DestinationReject     = "reject"
+	DestinationQuarantine = "quarantine"
+)
+
+// DestinationPolicy restricts which countries messages may be sent to, by
+// ISO 3166-1 alpha-2 region code. An empty allow list allows every country
+// that is not blocked.
+type DestinationPolicy struct {
+	allowed    map[string]bool
+	blocked    map[string]bool
+	quarantine bool
+}
+
+func NewDestinationPolicy(allowed, blocked []string, action string) *DestinationPolicy {
+	return &DestinationPolicy{
+		allowed:    regionSet(allowed),
+		blocked:    regionSet(blocked),
+		quarantine: action == DestinationQuarantine,
+	}
+}
+
+func regionSet(regions []string) map[string]bool {
+	set := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		set[strings.ToUpper(strings.TrimSpace(region))] = true
+	}
+	return set
+}
+
+// Permits reports whether messages may be sent to phone. A nil policy
+// permits everything.
+func (p *DestinationPolicy) Permits(phone *valueobject.PhoneNumber) bool {
+	if p == nil {
+		return true
+	}
+	region := phone.RegionCode()
+	if p.blocked[region] {
+		return false
+	}
+	return len(p.allowed) == 0 || p.allowed[region]
+}
+
+// Quarantines reports whether disallowed messages are stored as quarantined
+// rather than rejected.
+func (p *DestinationPolicy) Quarantines() bool {
+	return p != nil && p.quarantine
+}
@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestinationPolicy_Permits(t *testing.T) {
+	tr, _ := valueobject.NewPhoneNumber("+905551234567")
+	us, _ := valueobject.NewPhoneNumber("+12025550123")
+	gb, _ := valueobject.NewPhoneNumber("+442079460958")
+
+	var unrestricted *service.DestinationPolicy
+	assert.True(t, unrestricted.Permits(tr))
+	assert.False(t, unrestricted.Quarantines())
+
+	allowList := service.NewDestinationPolicy([]string{"tr", " GB"}, nil, service.DestinationReject)
+	assert.True(t, allowList.Permits(tr))
+	assert.True(t, allowList.Permits(gb))
+	assert.False(t, allowList.Permits(us))
+
+	blockList := service.NewDestinationPolicy(nil, []string{"US"}, service.DestinationQuarantine)
+	assert.True(t, blockList.Permits(tr))
+	assert.False(t, blockList.Permits(us))
+	assert.True(t, blockList.Quarantines())
+
+	// Blocking wins over allowing
+	both := service.NewDestinationPolicy([]string{"TR", "US"}, []string{"US"}, service.DestinationReject)
+	assert.True(t, both.Permits(tr))
+	assert.False(t, both.Permits(us))
+}
@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMessageAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageAttemptRepository) Add(ctx context.Context, attempt *repository.MessageAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockMessageAttemptRepository) ListByMessage(ctx context.Context, messageID uuid.UUID) ([]*repository.MessageAttempt, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.MessageAttempt), args.Error(1)
+}
+
+func TestProcessClaimedMessage_RecordsSentAttempt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+	mockAttempts := new(MockMessageAttemptRepository)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockAttempts, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Run(func(args mock.Arguments) {
+			provider.RecordResponseStatus(args.Get(0).(context.Context), 202)
+		}).
+		Return(&provider.SendResult{MessageID: "webhook-123", ProviderRequestID: "req-1", Response: []byte(`{"messageId":"webhook-123"}`)}, nil)
+	mockCache.On("CacheSentMessage", mock.Anything, mock.AnythingOfType("*cache.CachedMessage")).Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	var recorded *repository.MessageAttempt
+	mockAttempts.On("Add", mock.Anything, mock.AnythingOfType("*repository.MessageAttempt")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*repository.MessageAttempt) }).
+		Return(nil).Once()
+
+	// Act
+	err := svc.ProcessClaimedMessage(context.Background(), claimed(message)[0])
+
+	// Assert
+	assert.NoError(t, err)
+	mockAttempts.AssertExpectations(t)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, message.ID(), recorded.MessageID)
+		assert.Equal(t, 1, recorded.Attempt)
+		assert.Equal(t, "sent", recorded.Status)
+		assert.Equal(t, 202, recorded.StatusCode)
+		assert.JSONEq(t, `{"messageId":"webhook-123"}`, recorded.WebhookResponse)
+		assert.Equal(t, "req-1", recorded.ProviderRequestID)
+		assert.False(t, recorded.StartedAt.IsZero())
+	}
+}
+
+func TestProcessClaimedMessage_RecordsFailedAttempt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockAttempts := new(MockMessageAttemptRepository)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockAttempts, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Run(func(args mock.Arguments) {
+			provider.RecordResponseStatus(args.Get(0).(context.Context), 503)
+		}).
+		Return(nil, apperrors.New(apperrors.ErrorCodeServerError, "service unavailable"))
+
+	// A history that cannot be written does not fail the attempt twice
+	var recorded *repository.MessageAttempt
+	mockAttempts.On("Add", mock.Anything, mock.AnythingOfType("*repository.MessageAttempt")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*repository.MessageAttempt) }).
+		Return(errors.New("database is locked")).Once()
+
+	// Act
+	err := svc.ProcessClaimedMessage(context.Background(), claimed(message)[0])
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, message.Attempts())
+	mockAttempts.AssertExpectations(t)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, "failed", recorded.Status)
+		assert.Equal(t, 503, recorded.StatusCode)
+		assert.Equal(t, string(apperrors.ErrorCodeServerError), recorded.ErrorCode)
+		assert.Contains(t, recorded.Error, "service unavailable")
+		assert.Empty(t, recorded.WebhookResponse)
+	}
+}
+
+func TestProcessClaimedMessage_HoldsUntilDailyQuotaResets(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockAttempts := new(MockMessageAttemptRepository)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockAttempts, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	resetAt := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil).Once()
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Return(nil, apperrors.Wrap(apperrors.ErrorCodeQuotaExhausted, "webhook daily quota is used up",
+			&provider.QuotaExhaustedError{ResetAt: resetAt}))
+
+	// Act
+	err := svc.ProcessClaimedMessage(context.Background(), claimed(message)[0])
+
+	// Assert
+	assert.True(t, service.IsDeferred(err))
+	assert.True(t, message.Status().IsPending())
+	assert.Zero(t, message.Attempts(), "a send the quota refused is not charged")
+	if assert.NotNil(t, message.ScheduledAt()) {
+		assert.Equal(t, resetAt, *message.ScheduledAt())
+	}
+	mockRepo.AssertExpectations(t)
+	mockAttempts.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestListMessageAttempts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockAttempts := new(MockMessageAttemptRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockAttempts, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	started := time.Now().UTC()
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockAttempts.On("ListByMessage", mock.Anything, message.ID()).Return([]*repository.MessageAttempt{
+		{MessageID: message.ID(), Attempt: 1, StartedAt: started, Duration: 2 * time.Second, Status: "failed", ErrorCode: "TIMEOUT", Error: "deadline exceeded"},
+		{MessageID: message.ID(), Attempt: 2, StartedAt: started.Add(time.Minute), Duration: 80 * time.Millisecond, Status: "sent", StatusCode: 202, WebhookResponse: `{"messageId":"abc"}`},
+	}, nil)
+
+	// Act
+	result, err := svc.ListMessageAttempts(context.Background(), message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, message.ID().String(), result.MessageID)
+	if assert.Len(t, result.Attempts, 2) {
+		assert.Equal(t, int64(2000), result.Attempts[0].DurationMs)
+		assert.Equal(t, "deadline exceeded", result.Attempts[0].Error)
+		assert.Nil(t, result.Attempts[0].ProviderResponse)
+		assert.Equal(t, 202, result.Attempts[1].StatusCode)
+		assert.JSONEq(t, `{"messageId":"abc"}`, string(result.Attempts[1].ProviderResponse))
+	}
+}
+
+func TestListMessageAttempts_MessageNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockAttempts := new(MockMessageAttemptRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockAttempts, nil, nil, 0, false)
+
+	id := uuid.New()
+	notFound := apperrors.NewNotFoundError("message not found")
+	mockRepo.On("FindByID", mock.Anything, id).Return(nil, notFound)
+
+	// Act
+	result, err := svc.ListMessageAttempts(context.Background(), id)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Equal(t, notFound, err)
+	mockAttempts.AssertNotCalled(t, "ListByMessage", mock.Anything, mock.Anything)
+}
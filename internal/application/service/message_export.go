@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// exportPageSize is the number of messages read from the repository at a time
+const exportPageSize = 1000
+
+var exportColumns = []string{
+	"id",
+	"phone_number",
+	"content",
+	"status",
+	"priority",
+	"created_at",
+	"scheduled_at",
+	"expires_at",
+	"sent_at",
+	"delivered_at",
+	"attempts",
+	"error_code",
+	"last_error",
+	"webhook_message_id",
+	"client_reference",
+	"tenant_id",
+	"campaign_id",
+	"channel",
+	"recipient",
+	"metadata",
+}
+
+func (s *messageService) ExportMessages(ctx context.Context, req *dto.ExportMessagesRequest, w io.Writer) error {
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return apperrors.NewValidationError("from must be before to")
+	}
+
+	filter, err := messageFilter(&dto.ListMessagesRequest{
+		Status:        req.Status,
+		Channel:       req.Channel,
+		PhoneNumber:   req.PhoneNumber,
+		ErrorCode:     req.ErrorCode,
+		CreatedAfter:  req.From,
+		CreatedBefore: req.To,
+		CampaignID:    req.CampaignID,
+		Metadata:      req.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The first page is read before anything is written, so a failing query
+	// can still be reported as an error response
+	messages, err := s.repo.FindByFilterAfter(ctx, filter, nil, exportPageSize)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+
+	exported := 0
+	for {
+		for _, message := range messages {
+			if err := writer.Write(exportRecord(message)); err != nil {
+				return err
+			}
+		}
+		exported += len(messages)
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+
+		if len(messages) < exportPageSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messages, err = s.repo.FindByFilterAfter(ctx, filter, repository.CursorOf(messages[len(messages)-1]), exportPageSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).Info("message export finished",
+		zap.Int("messages", exported),
+	)
+
+	return nil
+}
+
+func exportRecord(message *entity.Message) []string {
+	createdAt := message.CreatedAt()
+	metadata := ""
+	if len(message.Metadata()) > 0 {
+		data, err := json.Marshal(message.Metadata())
+		if err == nil {
+			metadata = string(data)
+		}
+	}
+
+	return []string{
+		message.ID().String(),
+		phoneNumberOf(message),
+		message.Content().String(),
+		message.Status().String(),
+		message.Priority().String(),
+		exportTime(&createdAt),
+		exportTime(message.ScheduledAt()),
+		exportTime(message.ExpiresAt()),
+		exportTime(message.SentAt()),
+		exportTime(message.DeliveredAt()),
+		strconv.Itoa(message.Attempts()),
+		message.ErrorCode(),
+		message.LastError(),
+		message.WebhookMessageID(),
+		message.IdempotencyKey(),
+		optionalID(message.TenantID()),
+		optionalID(message.CampaignID()),
+		message.Channel().String(),
+		message.Recipient().String(),
+		metadata,
+	}
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
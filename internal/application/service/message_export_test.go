@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportMessages_PagesThroughRepository(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Hello, world", 160)
+	firstPage := make([]*entity.Message, 1000)
+	for i := range firstPage {
+		firstPage[i], _ = entity.NewMessage(phone, content, 3)
+	}
+	last, _ := entity.NewMessage(phone, content, 3)
+	last.AssignMetadata(valueobject.MessageMetadata{"segment": "vip"})
+
+	isSentFilter := mock.MatchedBy(func(f repository.MessageFilter) bool { return f.Status == "sent" })
+	mockRepo.On("FindByFilterAfter", mock.Anything, isSentFilter, (*repository.MessageCursor)(nil), 1000).
+		Return(firstPage, nil).Once()
+	mockRepo.On("FindByFilterAfter", mock.Anything, isSentFilter, repository.CursorOf(firstPage[999]), 1000).
+		Return([]*entity.Message{last}, nil).Once()
+
+	var out bytes.Buffer
+
+	// Act
+	err := svc.ExportMessages(context.Background(), &dto.ExportMessagesRequest{Status: "sent"}, &out)
+
+	// Assert
+	assert.NoError(t, err)
+	records, parseErr := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, parseErr)
+	if assert.Len(t, records, 1002) {
+		assert.Equal(t, "id", records[0][0])
+		assert.Equal(t, "Hello, world", records[1][2])
+		assert.Equal(t, last.ID().String(), records[1001][0])
+		assert.Equal(t, `{"segment":"vip"}`, records[1001][len(records[1001])-1])
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportMessages_InvalidRangeWritesNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	var out bytes.Buffer
+
+	// Act
+	err := svc.ExportMessages(context.Background(), &dto.ExportMessagesRequest{From: &from, To: &to}, &out)
+
+	// Assert
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	}
+	assert.Zero(t, out.Len())
+	mockRepo.AssertNotCalled(t, "FindByFilterAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV   ImportFormat = "csv"
+	ImportFormatJSONL ImportFormat = "jsonl"
+)
+
+const (
+	// importBatchSize is the number of validated rows inserted together
+	importBatchSize = 500
+	// maxImportErrors bounds the rejected rows listed in the report; all of
+	// them are still counted
+	maxImportErrors = 1000
+	// maxImportLineBytes bounds a single JSONL line
+	maxImportLineBytes = 1 << 20
+	// csvMetadataPrefix marks CSV columns that become metadata keys
+	csvMetadataPrefix = "metadata."
+)
+
+// ParseImportFormat accepts a format name or a file extension.
+func ParseImportFormat(format string) (ImportFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "csv":
+		return ImportFormatCSV, nil
+	case "jsonl", "ndjson":
+		return ImportFormatJSONL, nil
+	default:
+		return "", apperrors.NewValidationError("import format must be csv or jsonl")
+	}
+}
+
+// importRow is one parsed line. err is set when the line could not be parsed;
+// the rows after it are still read.
+type importRow struct {
+	line int
+	req  *dto.CreateMessageRequest
+	err  error
+}
+
+type importReader interface {
+	// next returns the next row, or io.EOF after the last one. Any other
+	// error means the rest of the input cannot be read.
+	next() (*importRow, error)
+}
+
+func (s *messageService) ImportMessages(ctx context.Context, r io.Reader, format ImportFormat) (*dto.MessageImportResponse, error) {
+	var reader importReader
+	switch format {
+	case ImportFormatCSV:
+		csvReader, err := newCSVImportReader(r)
+		if err != nil {
+			return nil, err
+		}
+		reader = csvReader
+	case ImportFormatJSONL:
+		reader = newJSONLImportReader(r)
+	default:
+		return nil, apperrors.NewValidationError("import format must be csv or jsonl")
+	}
+
+	imp := &messageImport{service: s, report: &dto.MessageImportResponse{}}
+	lastLine := 0
+
+	for {
+		row, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Rows already read are still stored; the report says where
+			// reading stopped
+			imp.reject(lastLine+1, apperrors.NewValidationError(err.Error()))
+			break
+		}
+		lastLine = row.line
+
+		if row.err != nil {
+			imp.reject(row.line, apperrors.NewValidationError(row.err.Error()))
+			continue
+		}
+
+		draft, err := s.prepareMessage(ctx, uuid.New(), row.req)
+		if err != nil {
+			imp.reject(row.line, err)
+			continue
+		}
+		if draft.existing != nil {
+			imp.report.Accepted++
+			continue
+		}
+
+		imp.pending = append(imp.pending, pendingImportRow{line: row.line, req: row.req, draft: draft})
+		if len(imp.pending) >= importBatchSize {
+			imp.flush(ctx)
+		}
+	}
+	imp.flush(ctx)
+
+	logger.FromContext(ctx).Info("message import finished",
+		zap.String("format", string(format)),
+		zap.Int("accepted", imp.report.Accepted),
+		zap.Int("rejected", imp.report.Rejected),
+	)
+
+	return imp.report, nil
+}
+
+type pendingImportRow struct {
+	line  int
+	req   *dto.CreateMessageRequest
+	draft *messageDraft
+}
+
+type messageImport struct {
+	service *messageService
+	report  *dto.MessageImportResponse
+	pending []pendingImportRow
+}
+
+// flush inserts the pending rows in one batch. If the batch fails, for
+// example because two rows share a client_reference, they are inserted one
+// by one so only the offending rows are rejected.
+func (i *messageImport) flush(ctx context.Context) {
+	if len(i.pending) == 0 {
+		return
+	}
+	defer func() { i.pending = i.pending[:0] }()
+
+	messages := make([]*entity.Message, len(i.pending))
+	for n, row := range i.pending {
+		messages[n] = row.draft.message
+	}
+
+	err := i.service.repo.CreateBatch(ctx, messages)
+	if err == nil {
+		for _, message := range messages {
+			i.service.messageCreated(ctx, message)
+		}
+		i.report.Accepted += len(messages)
+		return
+	}
+
+	logger.FromContext(ctx).Warn("import batch failed, inserting rows one by one",
+		zap.Error(err),
+		zap.Int("rows", len(messages)),
+	)
+
+	for _, row := range i.pending {
+		if _, err := i.service.storeDraft(ctx, row.draft, row.req); err != nil {
+			i.reject(row.line, err)
+			continue
+		}
+		i.report.Accepted++
+	}
+}
+
+func (i *messageImport) reject(line int, err error) {
+	i.report.Rejected++
+	if len(i.report.Errors) >= maxImportErrors {
+		i.report.ErrorsTruncated = true
+		return
+	}
+
+	code := string(apperrors.ErrorCodeInternal)
+	message := err.Error()
+	if appErr, ok := err.(*apperrors.AppError); ok {
+		code = string(appErr.Code)
+		message = appErr.Message
+	}
+
+	i.report.Errors = append(i.report.Errors, dto.ImportRowError{
+		Line:  line,
+		Code:  code,
+		Error: message,
+	})
+}
+
+// csvImportReader reads rows under a header naming the columns: content is
+// required, along with phone_number for SMS or recipient for the channel
+// column's email and push messages; priority, scheduled_at and expires_at
+// (RFC 3339), client_reference and metadata.<key> columns are optional.
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperrors.NewValidationError("import file is empty")
+	}
+	if err != nil {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("invalid CSV header: %v", err))
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for n, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		switch {
+		case column == "channel", column == "phone_number", column == "recipient", column == "content", column == "priority",
+			column == "scheduled_at", column == "expires_at", column == "client_reference":
+		case strings.HasPrefix(column, csvMetadataPrefix) && len(column) > len(csvMetadataPrefix):
+		default:
+			return nil, apperrors.NewValidationError(fmt.Sprintf("unknown CSV column %q", column))
+		}
+		if seen[column] {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("duplicate CSV column %q", column))
+		}
+		seen[column] = true
+		columns[n] = column
+	}
+	if !seen["content"] || (!seen["phone_number"] && !seen["recipient"]) {
+		return nil, apperrors.NewValidationError("CSV header must include content and phone_number or recipient")
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) next() (*importRow, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		return nil, err
+	}
+
+	if err != nil {
+		return &importRow{line: parseErr.StartLine, err: parseErr.Err}, nil
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	req := &dto.CreateMessageRequest{}
+	for n, value := range record {
+		switch column := c.columns[n]; column {
+		case "channel":
+			req.Channel = value
+		case "phone_number":
+			req.PhoneNumber = value
+		case "recipient":
+			req.Recipient = value
+		case "content":
+			req.Content = value
+		case "priority":
+			req.Priority = value
+		case "client_reference":
+			req.ClientReference = value
+		case "scheduled_at":
+			if value == "" {
+				continue
+			}
+			scheduledAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return &importRow{line: line, err: fmt.Errorf("scheduled_at must be an RFC 3339 timestamp")}, nil
+			}
+			req.ScheduledAt = &scheduledAt
+		case "expires_at":
+			if value == "" {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return &importRow{line: line, err: fmt.Errorf("expires_at must be an RFC 3339 timestamp")}, nil
+			}
+			req.ExpiresAt = &expiresAt
+		default:
+			if value == "" {
+				continue
+			}
+			if req.Metadata == nil {
+				req.Metadata = make(map[string]string)
+			}
+			req.Metadata[strings.TrimPrefix(column, csvMetadataPrefix)] = value
+		}
+	}
+
+	return &importRow{line: line, req: req}, nil
+}
+
+// jsonlImportReader reads one JSON object per line, with the fields of a
+// create message request. Blank lines are skipped.
+type jsonlImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLImportReader(r io.Reader) *jsonlImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	return &jsonlImportReader{scanner: scanner}
+}
+
+func (j *jsonlImportReader) next() (*importRow, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := strings.TrimSpace(j.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var req dto.CreateMessageRequest
+		if err := json.Unmarshal([]byte(data), &req); err != nil {
+			return &importRow{line: j.line, err: fmt.Errorf("invalid JSON: %v", err)}, nil
+		}
+		return &importRow{line: j.line, req: &req}, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d exceeds %d bytes", j.line+1, maxImportLineBytes)
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportMessages_CSVReportsRejectedRows(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	var stored []*entity.Message
+	mockRepo.On("CreateBatch", mock.Anything, mock.AnythingOfType("[]*entity.Message")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]*entity.Message) }).
+		Return(nil)
+
+	file := strings.Join([]string{
+		"phone_number,content,priority,metadata.segment",
+		"+905551234567,Spring sale starts today,high,vip",
+		"12345,Invalid number,,",
+		`+905551234568,"Quoted, with comma",,`,
+		"+905551234569,Too few columns",
+	}, "\n")
+
+	// Act
+	result, err := svc.ImportMessages(context.Background(), strings.NewReader(file), service.ImportFormatCSV)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, 3, result.Errors[0].Line)
+		assert.Equal(t, string(apperrors.ErrorCodeValidation), result.Errors[0].Code)
+		assert.Equal(t, 5, result.Errors[1].Line)
+	}
+	if assert.Len(t, stored, 2) {
+		assert.Equal(t, "high", stored[0].Priority().String())
+		assert.Equal(t, "vip", stored[0].Metadata()["segment"])
+		assert.Equal(t, "Quoted, with comma", stored[1].Content().String())
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImportMessages_CSVRejectsUnknownColumn(t *testing.T) {
+	// Arrange
+	svc := service.NewMessageService(new(MockMessageRepository), new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.ImportMessages(context.Background(), strings.NewReader("phone,content\n"), service.ImportFormatCSV)
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	}
+}
+
+func TestImportMessages_JSONLFallsBackToSingleInserts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything).
+		Return(apperrors.NewDatabaseError(errors.New("batch insert failed")))
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *entity.Message) bool {
+		return m.Content().String() == "first"
+	})).Return(nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *entity.Message) bool {
+		return m.Content().String() == "second"
+	})).Return(apperrors.NewDatabaseError(errors.New("insert failed"))).Once()
+
+	file := strings.Join([]string{
+		`{"phone_number": "+905551234567", "content": "first"}`,
+		``,
+		`{"phone_number": "+905551234568", "content": "second"}`,
+		`{"phone_number": `,
+	}, "\n")
+
+	// Act
+	result, err := svc.ImportMessages(context.Background(), strings.NewReader(file), service.ImportFormatJSONL)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, 4, result.Errors[0].Line)
+		assert.Equal(t, string(apperrors.ErrorCodeValidation), result.Errors[0].Code)
+		assert.Equal(t, 3, result.Errors[1].Line)
+		assert.Equal(t, string(apperrors.ErrorCodeDatabase), result.Errors[1].Code)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestParseImportFormat(t *testing.T) {
+	for input, expected := range map[string]service.ImportFormat{
+		"csv":     service.ImportFormatCSV,
+		".CSV":    service.ImportFormatCSV,
+		"jsonl":   service.ImportFormatJSONL,
+		".ndjson": service.ImportFormatJSONL,
+	} {
+		format, err := service.ParseImportFormat(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, format, input)
+	}
+
+	_, err := service.ParseImportFormat(".xlsx")
+	assert.Error(t, err)
+}
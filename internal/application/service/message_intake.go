@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type IntakeState string
+
+const (
+	IntakeStateAccepted IntakeState = "accepted"
+	IntakeStateRejected IntakeState = "rejected"
+	// IntakeStateDuplicate means the idempotency key matched a message that
+	// already exists; MessageID points at it.
+	IntakeStateDuplicate IntakeState = "duplicate"
+)
+
+type IntakeStatus struct {
+	State     IntakeState
+	Err       error
+	MessageID uuid.UUID
+	TenantID  uuid.UUID
+	UpdatedAt time.Time
+}
+
+type intakeJob struct {
+	id        uuid.UUID
+	tenantID  uuid.UUID
+	actor     string
+	requestID string
+	req       *dto.CreateMessageRequest
+}
+
+// MessageIntake accepts create requests without blocking the caller and
+// validates/persists them on a small worker pool. Only requests that are
+// still queued or were rejected are tracked here; once a message is stored
+// the database is the source of truth for its status.
+type MessageIntake struct {
+	messageService MessageService
+	workerCount    int
+	retention      time.Duration
+
+	queue    chan intakeJob
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+
+	mu       sync.RWMutex
+	closed   bool
+	statuses map[uuid.UUID]*IntakeStatus
+}
+
+func NewMessageIntake(
+	messageService MessageService,
+	queueSize int,
+	workerCount int,
+	retention time.Duration,
+) *MessageIntake {
+	return &MessageIntake{
+		messageService: messageService,
+		workerCount:    workerCount,
+		retention:      retention,
+		queue:          make(chan intakeJob, queueSize),
+		stopChan:       make(chan struct{}),
+		statuses:       make(map[uuid.UUID]*IntakeStatus),
+	}
+}
+
+func (i *MessageIntake) Start(ctx context.Context) {
+	for w := 0; w < i.workerCount; w++ {
+		i.wg.Add(1)
+		go i.worker(ctx)
+	}
+
+	i.wg.Add(1)
+	go i.janitor()
+
+	logger.Get().Info("message intake started",
+		zap.Int("worker_count", i.workerCount),
+		zap.Int("queue_size", cap(i.queue)),
+	)
+}
+
+// Stop refuses new submissions and waits until everything already accepted
+// has been processed.
+func (i *MessageIntake) Stop() {
+	i.mu.Lock()
+	if i.closed {
+		i.mu.Unlock()
+		return
+	}
+	i.closed = true
+	close(i.queue)
+	i.mu.Unlock()
+
+	close(i.stopChan)
+	i.wg.Wait()
+
+	logger.Get().Info("message intake stopped")
+}
+
+// Submit queues req under the tenant, actor and request ID carried by ctx;
+// the request context itself is not kept.
+func (i *MessageIntake) Submit(ctx context.Context, req *dto.CreateMessageRequest) (uuid.UUID, error) {
+	id := uuid.New()
+	tenantID, _ := tenant.FromContext(ctx)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.closed {
+		return uuid.Nil, apperrors.New(apperrors.ErrorCodeInternal, "message intake is shutting down")
+	}
+
+	select {
+	case i.queue <- intakeJob{
+		id:        id,
+		tenantID:  tenantID,
+		actor:     actor.FromContext(ctx),
+		requestID: requestid.FromContext(ctx),
+		req:       req,
+	}:
+	default:
+		return uuid.Nil, apperrors.New(apperrors.ErrorCodeRateLimit, "async intake queue is full, retry later or create synchronously")
+	}
+
+	i.statuses[id] = &IntakeStatus{
+		State:     IntakeStateAccepted,
+		TenantID:  tenantID,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	return id, nil
+}
+
+// Status only reports requests submitted by the tenant carried by ctx.
+func (i *MessageIntake) Status(ctx context.Context, id uuid.UUID) (*IntakeStatus, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	status, ok := i.statuses[id]
+	if !ok {
+		return nil, false
+	}
+
+	if tenantID, scoped := tenant.FromContext(ctx); scoped && tenantID != status.TenantID {
+		return nil, false
+	}
+
+	copied := *status
+	return &copied, true
+}
+
+func (i *MessageIntake) worker(ctx context.Context) {
+	defer i.wg.Done()
+
+	for job := range i.queue {
+		// Detach from the scheduler/app context so a shutdown still persists
+		// what the API already acknowledged
+		jobCtx := context.WithoutCancel(ctx)
+		if job.tenantID != uuid.Nil {
+			jobCtx = tenant.WithTenantID(jobCtx, job.tenantID)
+		}
+		if job.actor != "" {
+			jobCtx = actor.WithActor(jobCtx, job.actor)
+		}
+		if job.requestID != "" {
+			jobCtx = requestid.WithRequestID(jobCtx, job.requestID)
+		}
+
+		result, err := i.messageService.CreateMessageWithID(jobCtx, job.id, job.req)
+
+		i.mu.Lock()
+		switch {
+		case err != nil:
+			i.statuses[job.id] = &IntakeStatus{
+				State:     IntakeStateRejected,
+				Err:       err,
+				TenantID:  job.tenantID,
+				UpdatedAt: time.Now().UTC(),
+			}
+		case result.ID != job.id.String():
+			// Nothing is stored under job.id, so keep pointing the caller at
+			// the message the idempotency key resolved to
+			existingID, _ := uuid.Parse(result.ID)
+			i.statuses[job.id] = &IntakeStatus{
+				State:     IntakeStateDuplicate,
+				MessageID: existingID,
+				TenantID:  job.tenantID,
+				UpdatedAt: time.Now().UTC(),
+			}
+		default:
+			delete(i.statuses, job.id)
+		}
+		i.mu.Unlock()
+
+		if err != nil {
+			logger.FromContext(ctx).Warn("async message creation rejected",
+				zap.Error(err),
+				zap.String("message_id", job.id.String()),
+			)
+		}
+	}
+}
+
+func (i *MessageIntake) janitor() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.stopChan:
+			return
+		case <-ticker.C:
+			i.purgeExpired()
+		}
+	}
+}
+
+func (i *MessageIntake) purgeExpired() {
+	cutoff := time.Now().UTC().Add(-i.retention)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for id, status := range i.statuses {
+		if status.State != IntakeStateAccepted && status.UpdatedAt.Before(cutoff) {
+			delete(i.statuses, id)
+		}
+	}
+}
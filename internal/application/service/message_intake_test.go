@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMessageIntake_PersistsAcceptedMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	intake := service.NewMessageIntake(svc, 10, 1, time.Hour)
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+
+	intake.Start(context.Background())
+
+	// Act
+	id, err := intake.Submit(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+	intake.Stop()
+
+	// Assert
+	assert.NoError(t, err)
+	_, tracked := intake.Status(context.Background(), id)
+	assert.False(t, tracked, "persisted messages are served from the repository")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMessageIntake_TracksRejectedMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	intake := service.NewMessageIntake(svc, 10, 1, time.Hour)
+	intake.Start(context.Background())
+
+	// Act
+	id, err := intake.Submit(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "invalid-phone",
+		Content:     "Test message",
+	})
+	intake.Stop()
+
+	// Assert
+	assert.NoError(t, err)
+	status, tracked := intake.Status(context.Background(), id)
+	assert.True(t, tracked)
+	assert.Equal(t, service.IntakeStateRejected, status.State)
+	assert.Contains(t, status.Err.Error(), "phone number")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMessageIntake_TracksDuplicateMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	intake := service.NewMessageIntake(svc, 10, 1, time.Hour)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	existing, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").Return(existing, nil)
+
+	intake.Start(context.Background())
+
+	// Act
+	id, err := intake.Submit(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         "Test message",
+		ClientReference: "order-42",
+	})
+	intake.Stop()
+
+	// Assert
+	assert.NoError(t, err)
+	status, tracked := intake.Status(context.Background(), id)
+	assert.True(t, tracked)
+	assert.Equal(t, service.IntakeStateDuplicate, status.State)
+	assert.Equal(t, existing.ID(), status.MessageID)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestMessageIntake_QueueFull(t *testing.T) {
+	// Arrange - workers are never started so the queue cannot drain
+	svc := service.NewMessageService(new(MockMessageRepository), new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+	intake := service.NewMessageIntake(svc, 1, 1, time.Hour)
+	req := &dto.CreateMessageRequest{PhoneNumber: "+905551234567", Content: "Test"}
+
+	// Act
+	_, firstErr := intake.Submit(context.Background(), req)
+	_, secondErr := intake.Submit(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Error(t, secondErr)
+	assert.Contains(t, secondErr.Error(), "queue is full")
+}
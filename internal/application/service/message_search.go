@@ -0,0 +1,43 @@
+package service
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// highlightTerms HTML escapes content and wraps every case-insensitive
+// occurrence of a term in <mark></mark>. Phrases match across any run of
+// whitespace; overlapping terms are marked once, preferring the longest.
+func highlightTerms(content string, terms []string) string {
+	if len(terms) == 0 {
+		return html.EscapeString(content)
+	}
+
+	sorted := append([]string(nil), terms...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	alternatives := make([]string, len(sorted))
+	for i, term := range sorted {
+		words := strings.Fields(term)
+		for j, word := range words {
+			words[j] = regexp.QuoteMeta(word)
+		}
+		alternatives[i] = strings.Join(words, `\s+`)
+	}
+	pattern := regexp.MustCompile(`(?i)` + strings.Join(alternatives, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(content, -1) {
+		b.WriteString(html.EscapeString(content[last:loc[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(content[loc[0]:loc[1]]))
+		b.WriteString("</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(content[last:]))
+
+	return b.String()
+}
@@ -1,256 +1,2252 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/application/dto"
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
 	"github.com/eneskaya/insider-messaging/internal/domain/repository"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
-	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
 	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type MessageService interface {
 	CreateMessage(ctx context.Context, req *dto.CreateMessageRequest) (*dto.MessageResponse, error)
+	CreateMessageWithID(ctx context.Context, id uuid.UUID, req *dto.CreateMessageRequest) (*dto.MessageResponse, error)
 	GetMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error)
+	// GetMessageByWebhookID finds a message by the ID its provider assigned
+	// when it was sent
+	GetMessageByWebhookID(ctx context.Context, webhookMessageID string) (*dto.MessageResponse, error)
+	WaitForMessage(ctx context.Context, id uuid.UUID, timeout time.Duration) (*dto.MessageResponse, error)
 	GetSentMessages(ctx context.Context, page, pageSize int) (*dto.MessageListResponse, error)
+	ListMessages(ctx context.Context, req *dto.ListMessagesRequest) (*dto.MessageListResponse, error)
+	// SearchMessages finds messages by content, best match first
+	SearchMessages(ctx context.Context, req *dto.SearchMessagesRequest) (*dto.MessageSearchResponse, error)
 	GetStats(ctx context.Context) (*dto.MessageStatsResponse, error)
-	ProcessPendingMessages(ctx context.Context, batchSize int) (int, error)
+	// GetStatsByCaller counts messages by status for each caller that
+	// created them
+	GetStatsByCaller(ctx context.Context) (*dto.CallerStatsResponse, error)
+	// GetStatsTimeSeries counts messages per hour or day over a range, for
+	// dashboards
+	GetStatsTimeSeries(ctx context.Context, req *dto.StatsTimeSeriesRequest) (*dto.StatsTimeSeriesResponse, error)
+	// GetDuplicateContent reports content created more than once for the
+	// same recipient within a range
+	GetDuplicateContent(ctx context.Context, req *dto.DuplicateContentRequest) (*dto.DuplicateContentResponse, error)
+	CancelMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error)
+	PauseMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error)
+	ResumeMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error)
+	RetryMessage(ctx context.Context, id uuid.UUID, resetAttempts bool) (*dto.MessageResponse, error)
+	RetryFailedMessages(ctx context.Context, req *dto.RetryFailedRequest) (*dto.RetryFailedResponse, error)
+	ListDeadLetters(ctx context.Context, page, pageSize int) (*dto.DeadLetterListResponse, error)
+	RequeueDeadLetter(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error)
+	// ListMessageAttempts returns every send attempt of a message, oldest
+	// first
+	ListMessageAttempts(ctx context.Context, id uuid.UUID) (*dto.MessageAttemptListResponse, error)
+	ApplyDeliveryReceipt(ctx context.Context, req *dto.DeliveryReceiptRequest) (*dto.MessageResponse, error)
+	// ImportMessages creates a message from every row of r, inserting them in
+	// batches. Rows that fail validation are reported and skipped.
+	ImportMessages(ctx context.Context, r io.Reader, format ImportFormat) (*dto.MessageImportResponse, error)
+	// ExportMessages writes the messages matching req to w as CSV, oldest
+	// first, reading them from the repository a page at a time. Nothing is
+	// written when req is invalid.
+	ExportMessages(ctx context.Context, req *dto.ExportMessagesRequest, w io.Writer) error
+	ProcessPendingMessages(ctx context.Context, batchSize int) (*BatchResult, error)
+	// ClaimPendingMessages marks up to limit pending messages processing in
+	// one query, so the caller can share them out between workers. Each must
+	// then go to ProcessClaimedMessage or ReleaseClaimedMessages.
+	ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error)
+	// ProcessClaimedMessage sends a claimed message and stores the outcome.
+	// It returns an error when the message was not sent.
+	ProcessClaimedMessage(ctx context.Context, message *entity.Message) error
+	// ReleaseClaimedMessages returns claimed messages that were never sent to
+	// pending, without using an attempt.
+	ReleaseClaimedMessages(ctx context.Context, messages []*entity.Message)
+	// BatchSentCache returns a context under which sent messages are cached
+	// together when flush is called, rather than one by one as they are
+	// stored. Messages sent under it are not cached until then.
+	BatchSentCache(ctx context.Context) (batchCtx context.Context, flush func(ctx context.Context))
+	// PreviewTemplate renders content with variables and reports its
+	// length, segments and what would reject it, without storing anything
+	PreviewTemplate(ctx context.Context, req *dto.TemplatePreviewRequest) (*dto.TemplatePreviewResponse, error)
+	ReapStaleMessages(ctx context.Context, startedBefore time.Time, limit int) (*ReapResult, error)
 }
 
+// BatchResult summarises one ProcessPendingMessages call. Attempted is the
+// number of messages claimed; an empty poll has Attempted == 0.
+type BatchResult struct {
+	Attempted  int
+	Successful int
+	Failed     int
+}
+
+// ReapResult summarises one ReapStaleMessages call. Found counts stale claims
+// seen; the ones neither requeued nor failed were changed concurrently.
+// Failed includes claims that expired.
+type ReapResult struct {
+	Found    int
+	Requeued int
+	Failed   int
+}
+
+// waitPollInterval bounds how stale WaitForMessage can be when the status
+// change happened on another instance and never reached the local event bus.
+const waitPollInterval = 2 * time.Second
+
+// Time series ranges: the default when from is omitted, and the most
+// buckets one request may span
+const (
+	defaultTimeSeriesBuckets = 24
+	maxTimeSeriesBuckets     = 1000
+)
+
+// failureRateWindow is how far back the failure rate in the stats looks
+const failureRateWindow = time.Hour
+
+// Duplicate content reports: the range when from is omitted, and how many
+// groups one report lists by default and at most
+const (
+	defaultDuplicateRange = 24 * time.Hour
+	defaultDuplicateLimit = 50
+	maxDuplicateLimit     = 500
+)
+
+// providerResponseFieldPattern limits the provider response fields messages
+// can be filtered by.
+var providerResponseFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// maxClientReferenceLength matches the idempotency_key column width.
+const maxClientReferenceLength = 255
+
+// maxCallbackURLLength keeps callback URLs to what common servers accept.
+const maxCallbackURLLength = 2048
+
+// recipientBlockedReason is the last_error of messages to blacklisted numbers
+const recipientBlockedReason = "recipient is on the blacklist"
+
+// messageExpiredReason is the last_error of messages that expired unsent
+const messageExpiredReason = "message expired before it was sent"
+
+// Outcomes of a send attempt in the message's attempt history
+const (
+	attemptSent   = "sent"
+	attemptFailed = "failed"
+)
+
+// Delivery receipt statuses reported by providers
+const (
+	receiptDelivered   = "delivered"
+	receiptUndelivered = "undelivered"
+	receiptRejected    = "rejected"
+)
+
 type messageService struct {
-	repo          repository.MessageRepository
-	webhookClient infrahttp.WebhookClient
-	messageCache  cache.MessageCache
-	charLimit     int
-	maxRetries    int
+	repo         repository.MessageRepository
+	sender       provider.SenderProvider
+	channels     provider.ChannelProviders
+	messageCache cache.MessageCache
+	eventBus     eventbus.Bus
+	charLimit    int
+	maxSegments  int
+	maxRetries   int
+	retryBackoff *RetryBackoff
+	deadLetters  repository.DeadLetterRepository
+	recipients   cache.RecipientGuard
+	destinations *DestinationPolicy
+	audit        AuditService
+	blacklist    BlacklistService
+	moderation   *ContentModeration
+	sendGuard    cache.SendGuard
+	webhooks     provider.DestinationSenders
+	quietHours   *QuietHours
+	attempts     repository.MessageAttemptRepository
+	// errorMappings, when set, replaces the error code of provider refusals
+	// that match one of the mappings managed at runtime
+	errorMappings ProviderErrorMappingService
+	// callbacks posts the final status of messages created with a
+	// callback_url; nil when callback URLs are not accepted
+	callbacks provider.CallbackNotifier
+	// splitMaxParts, when above 0, splits SMS content over the char limit
+	// into up to that many linked messages instead of rejecting it
+	splitMaxParts int
+	// dryRun simulates every send, as if each message were created with
+	// dry_run
+	dryRun bool
 }
 
 func NewMessageService(
 	repo repository.MessageRepository,
-	webhookClient infrahttp.WebhookClient,
+	sender provider.SenderProvider,
 	messageCache cache.MessageCache,
+	eventBus eventbus.Bus,
 	charLimit int,
+	maxSegments int,
 	maxRetries int,
+	retryBackoff *RetryBackoff,
+	deadLetters repository.DeadLetterRepository,
+	recipients cache.RecipientGuard,
+	destinations *DestinationPolicy,
+	audit AuditService,
+	blacklist BlacklistService,
+	moderation *ContentModeration,
+	channels provider.ChannelProviders,
+	sendGuard cache.SendGuard,
+	webhooks provider.DestinationSenders,
+	quietHours *QuietHours,
+	attempts repository.MessageAttemptRepository,
+	errorMappings ProviderErrorMappingService,
+	callbacks provider.CallbackNotifier,
+	splitMaxParts int,
+	dryRun bool,
 ) MessageService {
 	return &messageService{
 		repo:          repo,
-		webhookClient: webhookClient,
+		sender:        sender,
+		channels:      channels,
 		messageCache:  messageCache,
+		eventBus:      eventBus,
 		charLimit:     charLimit,
+		maxSegments:   maxSegments,
 		maxRetries:    maxRetries,
+		retryBackoff:  retryBackoff,
+		deadLetters:   deadLetters,
+		recipients:    recipients,
+		destinations:  destinations,
+		audit:         audit,
+		blacklist:     blacklist,
+		moderation:    moderation,
+		sendGuard:     sendGuard,
+		webhooks:      webhooks,
+		quietHours:    quietHours,
+		attempts:      attempts,
+		errorMappings: errorMappings,
+		callbacks:     callbacks,
+		splitMaxParts: splitMaxParts,
+		dryRun:        dryRun,
 	}
 }
 
 func (s *messageService) CreateMessage(ctx context.Context, req *dto.CreateMessageRequest) (*dto.MessageResponse, error) {
-	phoneNumber, err := valueobject.NewPhoneNumber(req.PhoneNumber)
-	if err != nil {
-		return nil, apperrors.NewValidationError(err.Error())
-	}
+	return s.CreateMessageWithID(ctx, uuid.New(), req)
+}
 
-	content, err := valueobject.NewMessageContent(req.Content, s.charLimit)
+func (s *messageService) CreateMessageWithID(ctx context.Context, id uuid.UUID, req *dto.CreateMessageRequest) (*dto.MessageResponse, error) {
+	parts, err := s.splitContent(ctx, req)
 	if err != nil {
-		return nil, apperrors.NewValidationError(err.Error())
+		return nil, err
+	}
+	if parts != nil {
+		return s.createParts(ctx, id, req, parts)
 	}
 
-	message, err := entity.NewMessage(phoneNumber, content, s.maxRetries)
+	draft, err := s.prepareMessage(ctx, id, req)
 	if err != nil {
-		return nil, apperrors.NewInternalError(err)
+		return nil, err
 	}
+	if draft.existing != nil {
+		return draft.existing, nil
+	}
+
+	return s.storeDraft(ctx, draft, req)
+}
+
+// storeDraft inserts the draft's message, releasing its reservation if that
+// fails.
+func (s *messageService) storeDraft(ctx context.Context, draft *messageDraft, req *dto.CreateMessageRequest) (*dto.MessageResponse, error) {
+	message := draft.message
 
 	if err := s.repo.Create(ctx, message); err != nil {
+		s.releaseRecipient(ctx, draft.reservation)
+
+		// A concurrent request with the same key won the insert
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists && req.ClientReference != "" {
+			existing, findErr := s.findByIdempotencyKey(ctx, req.ClientReference, message.Recipient(), message.Content())
+			if findErr != nil {
+				return nil, findErr
+			}
+			if existing != nil {
+				return existing, nil
+			}
+		}
 		return nil, err
 	}
 
-	logger.Get().Info("message created successfully",
-		zap.String("message_id", message.ID().String()),
-		zap.String("phone_number", phoneNumber.String()),
-	)
+	s.messageCreated(ctx, message)
 
 	return s.toDTO(message), nil
 }
 
-func (s *messageService) GetMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
-	message, err := s.repo.FindByID(ctx, id)
+// messageDraft is a validated message that has not been stored yet.
+type messageDraft struct {
+	message     *entity.Message
+	reservation *cache.RecipientReservation
+	// existing is the message an idempotent replay resolved to
+	existing *dto.MessageResponse
+}
+
+// prepareMessage validates req and builds the message to store, holding a
+// recipient reservation for it. The draft carries the earlier message instead
+// when req replays an idempotency key.
+func (s *messageService) prepareMessage(ctx context.Context, id uuid.UUID, req *dto.CreateMessageRequest) (*messageDraft, error) {
+	recipient, err := newRecipient(req)
 	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	var destinationID uuid.UUID
+	if req.DestinationID != "" {
+		destinationID, err = uuid.Parse(req.DestinationID)
+		if err != nil {
+			return nil, apperrors.NewValidationError("destination_id must be a UUID")
+		}
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	if _, err := s.senderFor(ctx, recipient.Channel(), destinationID, tenantID); err != nil {
 		return nil, err
 	}
 
-	return s.toDTO(message), nil
-}
+	// Destination rules are per country, so they only apply to SMS
+	phoneNumber := recipient.PhoneNumber()
+	permitted := phoneNumber == nil || s.destinations.Permits(phoneNumber)
+	if !permitted && !s.destinations.Quarantines() {
+		return nil, apperrors.New(apperrors.ErrorCodeDestinationBlocked, destinationBlockedReason(phoneNumber))
+	}
 
-func (s *messageService) GetSentMessages(ctx context.Context, page, pageSize int) (*dto.MessageListResponse, error) {
-	if page < 1 {
-		page = 1
+	text, err := s.moderation.screen(ctx, req.Content)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+
+	content, err := valueobject.NewMessageContent(text, recipient.Channel().ContentLimit(s.charLimit))
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	if s.maxSegments > 0 && phoneNumber != nil {
+		if segments := content.Segmentation().Segments; segments > s.maxSegments {
+			return nil, apperrors.NewValidationError(fmt.Sprintf(
+				"message content exceeds maximum of %d SMS segments (got %d)", s.maxSegments, segments))
+		}
 	}
 
-	offset := (page - 1) * pageSize
+	priority, err := valueobject.NewMessagePriority(req.Priority)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
 
-	messages, err := s.repo.FindSentMessages(ctx, pageSize, offset)
+	metadata, err := valueobject.NewMessageMetadata(req.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, apperrors.NewValidationError(err.Error())
 	}
 
-	stats, err := s.repo.GetStats(ctx)
+	if req.ScheduledAt != nil && !req.ScheduledAt.After(time.Now()) {
+		return nil, apperrors.NewValidationError("scheduled_at must be in the future")
+	}
+
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return nil, apperrors.NewValidationError("expires_at must be in the future")
+		}
+		if req.ScheduledAt != nil && !req.ExpiresAt.After(*req.ScheduledAt) {
+			return nil, apperrors.NewValidationError("expires_at must be after scheduled_at")
+		}
+	}
+
+	if len(req.ClientReference) > maxClientReferenceLength {
+		return nil, apperrors.NewValidationError(
+			fmt.Sprintf("client_reference must be at most %d characters", maxClientReferenceLength))
+	}
+
+	if req.CallbackURL != "" {
+		if s.callbacks == nil {
+			return nil, apperrors.NewValidationError("callback_url is not enabled on this server")
+		}
+		if err := s.validateCallbackURL(ctx, req.CallbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ClientReference != "" {
+		existing, err := s.findByIdempotencyKey(ctx, req.ClientReference, recipient, content)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return &messageDraft{existing: existing}, nil
+		}
+	}
+
+	blocked, err := s.isBlocked(ctx, recipient, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
-	responseMsgs := make([]dto.MessageResponse, len(messages))
-	for i, msg := range messages {
-		responseMsgs[i] = *s.toDTO(msg)
+	// A blocked message is never sent, so it takes no slot of the recipient
+	var reservation *cache.RecipientReservation
+	if !blocked {
+		var original *dto.MessageResponse
+		reservation, original, err = s.reserveRecipient(ctx, id, recipient, content)
+		if err != nil {
+			return nil, err
+		}
+		if original != nil {
+			return &messageDraft{existing: original}, nil
+		}
 	}
 
-	return &dto.MessageListResponse{
-		Messages:   responseMsgs,
-		TotalCount: int(stats.SentMessages),
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+	message, err := entity.NewMessageTo(id, recipient, content, s.maxRetries)
+	if err != nil {
+		s.releaseRecipient(ctx, reservation)
+		return nil, apperrors.NewInternalError(err)
+	}
+
+	if req.ScheduledAt != nil {
+		message.ScheduleAt(*req.ScheduledAt)
+	}
+	if req.ExpiresAt != nil {
+		message.ExpireAt(*req.ExpiresAt)
+	}
+	message.AssignPriority(priority)
+	message.AssignIdempotencyKey(req.ClientReference)
+	message.AssignMetadata(metadata)
+	message.AssignCampaign(req.CampaignID)
+	message.AssignDestination(destinationID)
+	// The scheduler sends the message with the ID of the request that
+	// created it, so the provider call can be correlated with it
+	message.RecordTrace(requestid.FromContext(ctx), "")
+	message.AssignTenant(tenantID)
+	message.RecordCreator(auditActor(ctx))
+	if req.DryRun {
+		message.MarkDryRun()
+	}
+	message.AssignCallbackURL(req.CallbackURL)
+	if blocked {
+		if err := message.Block(recipientBlockedReason, string(apperrors.ErrorCodeRecipientBlocked)); err != nil {
+			return nil, apperrors.NewInternalError(err)
+		}
+	} else if !permitted {
+		if err := message.Quarantine(destinationBlockedReason(phoneNumber), string(apperrors.ErrorCodeDestinationBlocked)); err != nil {
+			s.releaseRecipient(ctx, reservation)
+			return nil, apperrors.NewInternalError(err)
+		}
+	}
+
+	return &messageDraft{message: message, reservation: reservation}, nil
 }
 
-func (s *messageService) GetStats(ctx context.Context) (*dto.MessageStatsResponse, error) {
-	stats, err := s.repo.GetStats(ctx)
+// validateCallbackURL accepts absolute https URLs the callback notifier
+// will post to, which rules out hosts resolving to internal addresses.
+func (s *messageService) validateCallbackURL(ctx context.Context, raw string) error {
+	if len(raw) > maxCallbackURLLength {
+		return apperrors.NewValidationError(fmt.Sprintf("callback_url must be at most %d characters", maxCallbackURLLength))
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return apperrors.NewValidationError("callback_url must be an absolute https URL")
+	}
+	if err := s.callbacks.CheckURL(ctx, raw); err != nil {
+		return apperrors.NewValidationError(fmt.Sprintf("callback_url is not allowed: %v", err))
+	}
+	return nil
+}
+
+// messageCreated reports a message that was just stored.
+func (s *messageService) messageCreated(ctx context.Context, message *entity.Message) {
+	metrics.MessagesCreated.Inc()
+	s.recordAudit(ctx, AuditActionMessageCreated, message, "", message.LastError())
+	// Blocked or quarantined already, so it will never be claimed
+	s.notifyFinished(ctx, message)
+
+	logger.FromContext(ctx).Info("message created successfully",
+		zap.String("message_id", message.ID().String()),
+		zap.String("channel", message.Channel().String()),
+		zap.String("recipient", message.Recipient().String()),
+		zap.String("status", message.Status().String()),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageCreated, message.ID(), message.Status()))
+}
+
+// newRecipient validates the address of req on its channel: phone_number for
+// SMS, recipient for email and push.
+func newRecipient(req *dto.CreateMessageRequest) (*valueobject.Recipient, error) {
+	channel, err := valueobject.NewChannel(req.Channel)
 	if err != nil {
 		return nil, err
 	}
+	if channel == valueobject.ChannelSMS {
+		return valueobject.NewRecipient(channel, req.PhoneNumber)
+	}
+	if req.PhoneNumber != "" {
+		return nil, fmt.Errorf("%s messages take a recipient instead of a phone_number", channel)
+	}
+	return valueobject.NewRecipient(channel, req.Recipient)
+}
 
-	return &dto.MessageStatsResponse{
-		TotalMessages:   stats.TotalMessages,
-		PendingMessages: stats.PendingMessages,
-		SentMessages:    stats.SentMessages,
-		FailedMessages:  stats.FailedMessages,
-	}, nil
+// senderFor returns the provider that delivers a message on channel. A
+// message routed to a webhook destination goes through that destination's
+// client; otherwise SMS always go through the configured sender provider.
+func (s *messageService) senderFor(ctx context.Context, channel valueobject.Channel, destinationID, tenantID uuid.UUID) (provider.SenderProvider, error) {
+	if destinationID != uuid.Nil {
+		if s.webhooks == nil {
+			return nil, apperrors.New(apperrors.ErrorCodeDestinationUnavailable, "webhook destinations are not enabled")
+		}
+		return s.webhooks.SenderFor(ctx, destinationID, tenantID, channel)
+	}
+	if channel == valueobject.ChannelSMS {
+		return s.sender, nil
+	}
+	if sender, ok := s.channels[channel]; ok {
+		return sender, nil
+	}
+	return nil, apperrors.New(apperrors.ErrorCodeChannelUnavailable,
+		fmt.Sprintf("no provider is configured for the %s channel", channel))
 }
 
-func (s *messageService) ProcessPendingMessages(ctx context.Context, batchSize int) (int, error) {
-	tx, err := s.repo.BeginTx(ctx)
-	if err != nil {
-		return 0, err
+// isBlocked reports whether recipient is on the blacklist for tenantID. The
+// blacklist holds phone numbers, so only SMS can be blocked.
+func (s *messageService) isBlocked(ctx context.Context, recipient *valueobject.Recipient, tenantID uuid.UUID) (bool, error) {
+	if s.blacklist == nil || recipient.PhoneNumber() == nil {
+		return false, nil
+	}
+	return s.blacklist.IsBlocked(ctx, recipient.String(), tenantID)
+}
+
+func destinationBlockedReason(phoneNumber *valueobject.PhoneNumber) string {
+	return fmt.Sprintf("sending to country %s is not allowed", phoneNumber.RegionCode())
+}
+
+// reserveRecipient applies the per-recipient rate limit and content dedup.
+// A duplicate the guard collapses returns the message it repeats instead of
+// a reservation. The guard fails open: if Redis is unavailable the message is
+// admitted.
+func (s *messageService) reserveRecipient(
+	ctx context.Context,
+	id uuid.UUID,
+	recipient *valueobject.Recipient,
+	content *valueobject.MessageContent,
+) (*cache.RecipientReservation, *dto.MessageResponse, error) {
+	if s.recipients == nil {
+		return nil, nil, nil
 	}
-	defer tx.Rollback()
 
-	messages, err := s.repo.FindPendingMessages(tx.GetContext(), batchSize)
+	reservation, err := s.recipients.Reserve(ctx, recipient.String(), content.Hash(), id)
 	if err != nil {
-		return 0, err
+		logger.FromContext(ctx).Warn("recipient guard unavailable, admitting message", zap.Error(err))
+		return nil, nil, nil
 	}
 
-	if len(messages) == 0 {
-		return 0, nil
+	switch reservation.Decision {
+	case cache.RecipientRateLimited:
+		return nil, nil, apperrors.New(apperrors.ErrorCodeRecipientRateLimited,
+			"too many messages to this recipient, retry later")
+	case cache.RecipientCollapsed:
+		if original := s.collapseInto(ctx, reservation.DuplicateOf); original != nil {
+			return nil, original, nil
+		}
+		fallthrough
+	case cache.RecipientDuplicate:
+		return nil, nil, apperrors.New(apperrors.ErrorCodeDuplicateContent,
+			"identical content was sent to this recipient recently")
 	}
 
-	logger.Get().Info("processing pending messages",
-		zap.Int("count", len(messages)),
-		zap.Int("batch_size", batchSize),
+	return reservation, nil, nil
+}
+
+// collapseInto returns the earlier message a duplicate is answered with, or
+// nil when it cannot be read, such as when it belongs to another tenant or
+// is not stored yet.
+func (s *messageService) collapseInto(ctx context.Context, id uuid.UUID) *dto.MessageResponse {
+	original, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+
+	metrics.DuplicatesCollapsed.Inc()
+	logger.FromContext(ctx).Info("returning earlier message for duplicate content",
+		zap.String("message_id", original.ID().String()),
 	)
 
-	successCount := 0
-	for _, message := range messages {
-		if err := s.processSingleMessage(tx.GetContext(), message); err != nil {
-			logger.Get().Error("failed to process message",
-				zap.Error(err),
-				zap.String("message_id", message.ID().String()),
-			)
-			continue
+	return s.toDTO(original)
+}
+
+// releaseRecipient frees the slot of a message that was not stored.
+func (s *messageService) releaseRecipient(ctx context.Context, reservation *cache.RecipientReservation) {
+	if reservation == nil {
+		return
+	}
+	if err := s.recipients.Release(ctx, reservation); err != nil {
+		logger.FromContext(ctx).Warn("failed to release recipient reservation", zap.Error(err))
+	}
+}
+
+// findByIdempotencyKey returns the message previously created with key, or nil
+// if there is none. Reusing a key for a different recipient or content is a
+// conflict rather than a replay.
+func (s *messageService) findByIdempotencyKey(
+	ctx context.Context,
+	key string,
+	recipient *valueobject.Recipient,
+	content *valueobject.MessageContent,
+) (*dto.MessageResponse, error) {
+	existing, err := s.repo.FindByIdempotencyKey(ctx, key)
+	if err != nil {
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeNotFound {
+			return nil, nil
 		}
-		successCount++
+		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		logger.Get().Error("failed to commit transaction", zap.Error(err))
-		return 0, apperrors.NewDatabaseError(err)
+	if !existing.Recipient().Equals(recipient) || existing.Content().String() != content.String() {
+		return nil, apperrors.New(apperrors.ErrorCodeConflict,
+			"idempotency key was already used for a different message")
 	}
 
-	logger.Get().Info("batch processing completed",
-		zap.Int("total", len(messages)),
-		zap.Int("successful", successCount),
-		zap.Int("failed", len(messages)-successCount),
+	logger.FromContext(ctx).Info("returning existing message for idempotency key",
+		zap.String("message_id", existing.ID().String()),
 	)
 
-	return successCount, nil
+	return s.toDTO(existing), nil
 }
 
-func (s *messageService) processSingleMessage(ctx context.Context, message *entity.Message) error {
-	message.MarkAsProcessing()
+func (s *messageService) GetMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	message, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.Update(ctx, message); err != nil {
-		return err
+	return s.toDTO(message), nil
+}
+
+func (s *messageService) GetMessageByWebhookID(ctx context.Context, webhookMessageID string) (*dto.MessageResponse, error) {
+	message, err := s.repo.FindByWebhookMessageID(ctx, webhookMessageID)
+	if err != nil {
+		return nil, err
 	}
 
-	webhookResp, err := s.webhookClient.SendMessage(
-		ctx,
-		message.PhoneNumber().String(),
-		message.Content().String(),
-	)
+	return s.toDTO(message), nil
+}
 
+func (s *messageService) WaitForMessage(ctx context.Context, id uuid.UUID, timeout time.Duration) (*dto.MessageResponse, error) {
+	// Subscribe before the first read so a transition in between is not missed
+	events, unsubscribe := s.eventBus.Subscribe(64)
+	defer unsubscribe()
+
+	message, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		appErr, ok := err.(*apperrors.AppError)
-		errorCode := string(apperrors.ErrorCodeInternal)
-		if ok {
-			errorCode = string(appErr.Code)
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	poll := time.NewTicker(waitPollInterval)
+	defer poll.Stop()
+
+	for !message.Status().IsTerminal() {
+		select {
+		case <-waitCtx.Done():
+			return s.toDTO(message), nil
+		case evt := <-events:
+			if evt.MessageID != id || !evt.Status.IsTerminal() {
+				continue
+			}
+		case <-poll.C:
 		}
 
-		message.MarkAsFailed(err.Error(), errorCode)
-		if updateErr := s.repo.Update(ctx, message); updateErr != nil {
-			logger.Get().Error("failed to update message after webhook failure",
-				zap.Error(updateErr),
-				zap.String("message_id", message.ID().String()),
-			)
+		message, err = s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.toDTO(message), nil
+}
+
+func (s *messageService) GetSentMessages(ctx context.Context, page, pageSize int) (*dto.MessageListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	data, err := s.messageCache.GetOrLoadSentPage(ctx, readCacheScope(ctx), page, pageSize, func() ([]byte, error) {
+		resp, err := s.loadSentMessages(ctx, page, pageSize)
+		if err != nil {
+			return nil, err
 		}
+		return json.Marshal(resp)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		return fmt.Errorf("webhook send failed: %w", err)
+	var resp dto.MessageListResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, apperrors.NewInternalError(err)
 	}
+	return &resp, nil
+}
 
-	responseJSON := fmt.Sprintf(`{"message": "%s", "messageId": "%s"}`, webhookResp.Message, webhookResp.MessageID)
-	message.MarkAsSent(webhookResp.MessageID, responseJSON)
+func (s *messageService) loadSentMessages(ctx context.Context, page, pageSize int) (*dto.MessageListResponse, error) {
+	offset := (page - 1) * pageSize
 
-	if err := s.repo.Update(ctx, message); err != nil {
-		return err
+	messages, err := s.repo.FindSentMessages(ctx, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every page shares the cached stats instead of counting the table again
+	stats, err := s.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responseMsgs := make([]dto.MessageResponse, len(messages))
+	for i, msg := range messages {
+		responseMsgs[i] = *s.toDTO(msg)
+	}
+
+	return &dto.MessageListResponse{
+		Messages:   responseMsgs,
+		TotalCount: int(stats.SentMessages),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// readCacheScope keys cached reads by tenant, since a tenant only sees its
+// own messages.
+func readCacheScope(ctx context.Context) string {
+	if id, ok := tenant.FromContext(ctx); ok {
+		return id.String()
 	}
+	return "all"
+}
 
-	cachedMsg := &cache.CachedMessage{
-		MessageID:        message.ID().String(),
-		WebhookMessageID: webhookResp.MessageID,
-		SentAt:           *message.SentAt(),
-		PhoneNumber:      message.PhoneNumber().String(),
+// invalidateSentReads drops the cached sent pages and stats that can show
+// message: its tenant's and the unscoped view. A stale read only lasts until
+// the cache TTL, so a failure is logged and otherwise ignored.
+func (s *messageService) invalidateSentReads(ctx context.Context, message *entity.Message) {
+	scopes := []string{"all"}
+	if message.TenantID() != uuid.Nil {
+		scopes = append(scopes, message.TenantID().String())
 	}
 
-	if err := s.messageCache.CacheSentMessage(ctx, cachedMsg); err != nil {
-		logger.Get().Warn("failed to cache sent message (non-critical)",
+	if err := s.messageCache.InvalidateSentMessages(ctx, scopes...); err != nil {
+		logger.FromContext(ctx).Warn("failed to invalidate cached sent messages (non-critical)",
 			zap.Error(err),
 			zap.String("message_id", message.ID().String()),
 		)
 	}
+}
 
-	logger.Get().Info("message sent successfully",
-		zap.String("message_id", message.ID().String()),
-		zap.String("webhook_message_id", webhookResp.MessageID),
-	)
-
-	return nil
+// invalidateFinishedReads drops the cached stats once a failed attempt left
+// message failed or expired; a message going back to pending for a retry
+// does not change the counts.
+func (s *messageService) invalidateFinishedReads(ctx context.Context, message *entity.Message) {
+	if message.Status().IsTerminal() {
+		s.invalidateSentReads(ctx, message)
+	}
 }
 
-func (s *messageService) toDTO(message *entity.Message) *dto.MessageResponse {
-	return &dto.MessageResponse{
-		ID:               message.ID().String(),
-		PhoneNumber:      message.PhoneNumber().String(),
-		Content:          message.Content().String(),
+// notifyFinished queues the status callback of a message created with a
+// callback URL once it has reached a final status. Later delivery receipts
+// do not post another one.
+func (s *messageService) notifyFinished(ctx context.Context, message *entity.Message) {
+	if s.callbacks == nil || message.CallbackURL() == "" || !message.Status().IsTerminal() {
+		return
+	}
+	s.callbacks.Notify(ctx, &provider.StatusCallback{
+		URL:              message.CallbackURL(),
+		TraceID:          message.TraceID(),
+		MessageID:        message.ID(),
 		Status:           message.Status().String(),
-		CreatedAt:        message.CreatedAt(),
-		SentAt:           message.SentAt(),
+		ClientReference:  message.IdempotencyKey(),
 		Attempts:         message.Attempts(),
-		MaxAttempts:      message.MaxAttempts(),
-		LastError:        message.LastError(),
-		ErrorCode:        message.ErrorCode(),
 		WebhookMessageID: message.WebhookMessageID(),
+		ErrorCode:        message.ErrorCode(),
+		Error:            message.LastError(),
+		OccurredAt:       time.Now().UTC(),
+	})
+}
+
+func (s *messageService) ListMessages(ctx context.Context, req *dto.ListMessagesRequest) (*dto.MessageListResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	filter, err := messageFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.repo.FindByFilter(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responseMsgs := make([]dto.MessageResponse, len(messages))
+	for i, msg := range messages {
+		responseMsgs[i] = *s.toDTO(msg)
+	}
+
+	return &dto.MessageListResponse{
+		Messages:   responseMsgs,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *messageService) SearchMessages(ctx context.Context, req *dto.SearchMessagesRequest) (*dto.MessageSearchResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query, err := valueobject.NewSearchQuery(req.Q)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+
+	messages, total, err := s.repo.SearchContent(ctx, query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.MessageSearchResult, len(messages))
+	for i, msg := range messages {
+		results[i] = dto.MessageSearchResult{
+			MessageResponse: *s.toDTO(msg),
+			Highlight:       highlightTerms(msg.Content().String(), query.Terms()),
+		}
+	}
+
+	return &dto.MessageSearchResponse{
+		Results:    results,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// messageFilter validates the filter fields of a listing request.
+func messageFilter(req *dto.ListMessagesRequest) (repository.MessageFilter, error) {
+	filter := repository.MessageFilter{
+		ErrorCode:     req.ErrorCode,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+	}
+
+	if req.Status != "" {
+		status, err := valueobject.NewMessageStatus(req.Status)
+		if err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError(err.Error())
+		}
+		filter.Status = status.String()
+	}
+
+	if req.Channel != "" {
+		channel, err := valueobject.NewChannel(req.Channel)
+		if err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError(err.Error())
+		}
+		filter.Channel = channel.String()
+	}
+
+	if req.PhoneNumber != "" {
+		phoneNumber, err := valueobject.NewPhoneNumber(req.PhoneNumber)
+		if err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError(err.Error())
+		}
+		filter.PhoneNumber = phoneNumber.String()
+	}
+
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		return repository.MessageFilter{}, apperrors.NewValidationError("created_after must be before created_before")
+	}
+
+	for key := range req.Metadata {
+		if err := valueobject.ValidateMetadataKey(key); err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError(err.Error())
+		}
+	}
+	if len(req.Metadata) > 0 {
+		filter.Metadata = req.Metadata
+	}
+
+	if req.CampaignID != "" {
+		campaignID, err := uuid.Parse(req.CampaignID)
+		if err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError("campaign_id must be a UUID")
+		}
+		filter.CampaignID = campaignID
+	}
+
+	if req.SplitGroupID != "" {
+		groupID, err := uuid.Parse(req.SplitGroupID)
+		if err != nil {
+			return repository.MessageFilter{}, apperrors.NewValidationError("split_group_id must be a UUID")
+		}
+		filter.SplitGroupID = groupID
+	}
+
+	for field := range req.ProviderResponse {
+		if !providerResponseFieldPattern.MatchString(field) {
+			return repository.MessageFilter{}, apperrors.NewValidationError(fmt.Sprintf(
+				"invalid provider_response field %q (use up to 64 letters, digits, '_' and '-')", field))
+		}
+	}
+	if len(req.ProviderResponse) > 0 {
+		filter.ProviderResponse = req.ProviderResponse
+	}
+
+	return filter, nil
+}
+
+func (s *messageService) GetStats(ctx context.Context) (*dto.MessageStatsResponse, error) {
+	data, err := s.messageCache.GetOrLoadStats(ctx, readCacheScope(ctx), func() ([]byte, error) {
+		resp, err := s.loadStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp dto.MessageStatsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, apperrors.NewInternalError(err)
+	}
+	return &resp, nil
+}
+
+func (s *messageService) loadStats(ctx context.Context) (*dto.MessageStatsResponse, error) {
+	stats, err := s.repo.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	performance, err := s.repo.GetSendPerformance(ctx, time.Now().UTC().Add(-failureRateWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toStatsDTO(stats)
+	resp.Performance = toPerformanceDTO(performance)
+	return resp, nil
+}
+
+func (s *messageService) GetStatsByCaller(ctx context.Context) (*dto.CallerStatsResponse, error) {
+	stats, err := s.repo.GetStatsByCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.CallerStatsResponse{Callers: make([]dto.CallerStats, len(stats))}
+	for i := range stats {
+		resp.Callers[i] = dto.CallerStats{
+			Caller:               stats[i].Caller,
+			MessageStatsResponse: *toStatsDTO(&stats[i].MessageStats),
+		}
+	}
+	return resp, nil
+}
+
+func toStatsDTO(stats *repository.MessageStats) *dto.MessageStatsResponse {
+	return &dto.MessageStatsResponse{
+		TotalMessages:       stats.TotalMessages,
+		PendingMessages:     stats.PendingMessages,
+		SentMessages:        stats.SentMessages,
+		FailedMessages:      stats.FailedMessages,
+		CancelledMessages:   stats.CancelledMessages,
+		DeliveredMessages:   stats.DeliveredMessages,
+		UndeliveredMessages: stats.UndeliveredMessages,
+		QuarantinedMessages: stats.QuarantinedMessages,
+		PausedMessages:      stats.PausedMessages,
+		BlockedMessages:     stats.BlockedMessages,
+		ExpiredMessages:     stats.ExpiredMessages,
+	}
+}
+
+func toPerformanceDTO(performance *repository.SendPerformance) *dto.SendPerformanceResponse {
+	resp := &dto.SendPerformanceResponse{
+		AvgAttemptsPerSent: performance.AvgAttempts,
+		AvgTimeToSendMs:    performance.AvgTimeToSend.Milliseconds(),
+		P95TimeToSendMs:    performance.P95TimeToSend.Milliseconds(),
+	}
+	if finished := performance.RecentSent + performance.RecentFailed; finished > 0 {
+		resp.FailureRateLastHour = float64(performance.RecentFailed) / float64(finished)
+	}
+	return resp
+}
+
+func (s *messageService) GetStatsTimeSeries(ctx context.Context, req *dto.StatsTimeSeriesRequest) (*dto.StatsTimeSeriesResponse, error) {
+	bucket := repository.StatsBucket(req.Bucket)
+	if req.Bucket == "" {
+		bucket = repository.StatsBucketHour
+	}
+	if bucket != repository.StatsBucketHour && bucket != repository.StatsBucketDay {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("invalid bucket: %s (must be hour or day)", req.Bucket))
+	}
+
+	width := bucket.Duration()
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.To.UTC()
+	}
+	from := to.Add(-defaultTimeSeriesBuckets * width)
+	if req.From != nil {
+		from = req.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, apperrors.NewValidationError("from must be before to")
+	}
+
+	// Whole buckets only, so the first and last are not undercounted
+	from = from.Truncate(width)
+	if aligned := to.Truncate(width); aligned.Before(to) {
+		to = aligned.Add(width)
+	}
+	count := int(to.Sub(from) / width)
+	if count > maxTimeSeriesBuckets {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("range spans %d buckets, at most %d are allowed", count, maxTimeSeriesBuckets))
+	}
+
+	series, err := s.repo.GetStatsTimeSeries(ctx, bucket, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[time.Time]repository.MessageStatsBucket, len(series.Buckets))
+	for _, b := range series.Buckets {
+		found[b.Start.UTC()] = b
+	}
+
+	resp := &dto.StatsTimeSeriesResponse{
+		Bucket:     string(bucket),
+		From:       from,
+		To:         to,
+		Buckets:    make([]dto.StatsBucketResponse, 0, count),
+		ErrorCodes: make([]dto.ErrorCodeCountResponse, 0, len(series.ErrorCodes)),
+	}
+	for start := from; start.Before(to); start = start.Add(width) {
+		b := found[start]
+		resp.Buckets = append(resp.Buckets, dto.StatsBucketResponse{
+			Start:   start,
+			Created: b.Created,
+			Sent:    b.Sent,
+			Failed:  b.Failed,
+		})
+	}
+	for _, c := range series.ErrorCodes {
+		resp.ErrorCodes = append(resp.ErrorCodes, dto.ErrorCodeCountResponse{
+			ErrorCode: c.ErrorCode,
+			Count:     c.Count,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *messageService) GetDuplicateContent(ctx context.Context, req *dto.DuplicateContentRequest) (*dto.DuplicateContentResponse, error) {
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = req.To.UTC()
+	}
+	from := to.Add(-defaultDuplicateRange)
+	if req.From != nil {
+		from = req.From.UTC()
+	}
+	if !from.Before(to) {
+		return nil, apperrors.NewValidationError("from must be before to")
+	}
+
+	minCount := req.MinCount
+	if minCount == 0 {
+		minCount = 2
+	}
+	if minCount < 2 {
+		return nil, apperrors.NewValidationError("min_count must be at least 2")
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultDuplicateLimit
+	}
+	if limit < 1 || limit > maxDuplicateLimit {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxDuplicateLimit))
+	}
+
+	duplicates, err := s.repo.FindDuplicateContent(ctx, from, to, minCount, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DuplicateContentResponse{
+		From:       from,
+		To:         to,
+		MinCount:   minCount,
+		Duplicates: make([]dto.DuplicateContentResponseItem, 0, len(duplicates)),
+	}
+	for _, d := range duplicates {
+		resp.Duplicates = append(resp.Duplicates, dto.DuplicateContentResponseItem{
+			Channel:        d.Channel,
+			Recipient:      d.Recipient,
+			ContentHash:    d.ContentHash,
+			Content:        d.Content,
+			Count:          d.Count,
+			FirstCreatedAt: d.FirstCreatedAt.UTC(),
+			LastCreatedAt:  d.LastCreatedAt.UTC(),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *messageService) CancelMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	// A scheduler that claimed the message after we read it wins: the retry
+	// sees it processing and the cancel is refused
+	var from valueobject.MessageStatus
+	message, _, err := s.updateWithRetry(ctx, s.findByID(id), func(message *entity.Message) error {
+		from = message.Status()
+		if err := message.MarkAsCancelled(); err != nil {
+			return apperrors.New(apperrors.ErrorCodeConflict, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, AuditActionMessageCancelled, message, from.String(), "")
+	s.notifyFinished(ctx, message)
+
+	logger.FromContext(ctx).Info("message cancelled",
+		zap.String("message_id", message.ID().String()),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+	return s.toDTO(message), nil
+}
+
+// PauseMessage holds a pending message back until it is resumed. As with
+// cancel, a scheduler that claims the message first wins.
+func (s *messageService) PauseMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	var from valueobject.MessageStatus
+	message, _, err := s.updateWithRetry(ctx, s.findByID(id), func(message *entity.Message) error {
+		from = message.Status()
+		if err := message.Pause(); err != nil {
+			return apperrors.New(apperrors.ErrorCodeConflict, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, AuditActionMessagePaused, message, from.String(), "")
+
+	logger.FromContext(ctx).Info("message paused",
+		zap.String("message_id", message.ID().String()),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+	return s.toDTO(message), nil
+}
+
+func (s *messageService) ResumeMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	var from valueobject.MessageStatus
+	message, _, err := s.updateWithRetry(ctx, s.findByID(id), func(message *entity.Message) error {
+		from = message.Status()
+		if err := message.Resume(); err != nil {
+			return apperrors.New(apperrors.ErrorCodeConflict, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, AuditActionMessageResumed, message, from.String(), "")
+
+	logger.FromContext(ctx).Info("message resumed",
+		zap.String("message_id", message.ID().String()),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+	return s.toDTO(message), nil
+}
+
+func (s *messageService) RetryMessage(ctx context.Context, id uuid.UUID, resetAttempts bool) (*dto.MessageResponse, error) {
+	var from valueobject.MessageStatus
+	message, _, err := s.updateWithRetry(ctx, s.findByID(id), func(message *entity.Message) error {
+		from = message.Status()
+		if err := message.Requeue(resetAttempts); err != nil {
+			return apperrors.New(apperrors.ErrorCodeConflict, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeDeadLetter(ctx, message)
+
+	details := ""
+	if resetAttempts {
+		details = "attempts reset"
+	}
+	s.recordAudit(ctx, AuditActionMessageRetried, message, from.String(), details)
+
+	logger.FromContext(ctx).Info("message requeued",
+		zap.String("message_id", message.ID().String()),
+		zap.Bool("reset_attempts", resetAttempts),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+	return s.toDTO(message), nil
+}
+
+// ApplyDeliveryReceipt records the final delivery outcome the provider
+// reported for a sent message. A repeated receipt with the same outcome
+// returns the message unchanged.
+func (s *messageService) ApplyDeliveryReceipt(ctx context.Context, req *dto.DeliveryReceiptRequest) (*dto.MessageResponse, error) {
+	if req.Status != receiptDelivered && req.Status != receiptUndelivered && req.Status != receiptRejected {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("unsupported delivery status: %s", req.Status))
+	}
+
+	load := func(ctx context.Context) (*entity.Message, error) {
+		return s.repo.FindByWebhookMessageID(ctx, req.WebhookMessageID)
+	}
+	var from valueobject.MessageStatus
+	message, changed, err := s.updateWithRetry(ctx, load, func(message *entity.Message) error {
+		from = message.Status()
+		return applyReceipt(message, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return s.toDTO(message), nil
+	}
+
+	metrics.DeliveryReceipts.WithLabelValues(message.Status().String()).Inc()
+	s.invalidateSentReads(ctx, message)
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, from.String(), "delivery receipt: "+req.Status)
+
+	logger.FromContext(ctx).Info("delivery receipt applied",
+		zap.String("message_id", message.ID().String()),
+		zap.String("webhook_message_id", req.WebhookMessageID),
+		zap.String("status", message.Status().String()),
+	)
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+	return s.toDTO(message), nil
+}
+
+// applyReceipt moves message to the outcome the receipt reports, or returns
+// errUnchanged when an earlier copy of the receipt already did.
+func applyReceipt(message *entity.Message, req *dto.DeliveryReceiptRequest) error {
+	var err error
+	switch req.Status {
+	case receiptDelivered:
+		if message.Status().IsDelivered() {
+			return errUnchanged
+		}
+		deliveredAt := time.Now().UTC()
+		if req.DeliveredAt != nil {
+			deliveredAt = *req.DeliveredAt
+		}
+		err = message.MarkAsDelivered(deliveredAt)
+	case receiptUndelivered, receiptRejected:
+		if message.Status().IsUndelivered() {
+			return errUnchanged
+		}
+		errorCode := req.ErrorCode
+		if errorCode == "" {
+			errorCode = "UNDELIVERED"
+			if req.Status == receiptRejected {
+				errorCode = "REJECTED"
+			}
+		}
+		reason := req.ErrorMessage
+		if reason == "" {
+			reason = fmt.Sprintf("provider reported the message as %s", req.Status)
+		}
+		err = message.MarkAsUndelivered(reason, errorCode)
+	}
+	if err != nil {
+		return apperrors.New(apperrors.ErrorCodeConflict, err.Error())
+	}
+	return nil
+}
+
+func (s *messageService) RetryFailedMessages(ctx context.Context, req *dto.RetryFailedRequest) (*dto.RetryFailedResponse, error) {
+	filter := repository.FailedMessageFilter{
+		ErrorCode:     req.ErrorCode,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+	}
+
+	if req.PhoneNumber != "" {
+		phoneNumber, err := valueobject.NewPhoneNumber(req.PhoneNumber)
+		if err != nil {
+			return nil, apperrors.NewValidationError(err.Error())
+		}
+		filter.PhoneNumber = phoneNumber.String()
+	}
+
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		return nil, apperrors.NewValidationError("created_after must be before created_before")
+	}
+
+	requeued, err := s.repo.RequeueFailed(ctx, filter, req.ResetAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.audit != nil {
+		s.audit.Record(ctx, AuditRecord{
+			Action:       AuditActionFailedRequeued,
+			ResourceType: AuditResourceMessage,
+			FromStatus:   valueobject.MessageStatusFailed.String(),
+			ToStatus:     valueobject.MessageStatusPending.String(),
+			Details:      fmt.Sprintf("requeued %d messages", requeued),
+		})
+	}
+
+	logger.FromContext(ctx).Info("failed messages requeued",
+		zap.Int64("count", requeued),
+		zap.String("error_code", req.ErrorCode),
+		zap.Bool("reset_attempts", req.ResetAttempts),
+	)
+
+	return &dto.RetryFailedResponse{Requeued: requeued}, nil
+}
+
+func (s *messageService) ListDeadLetters(ctx context.Context, page, pageSize int) (*dto.DeadLetterListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	entries, total, err := s.deadLetters.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responseEntries := make([]dto.DeadLetterResponse, len(entries))
+	for i, entry := range entries {
+		responseEntries[i] = dto.DeadLetterResponse{
+			MessageID:         entry.MessageID.String(),
+			PhoneNumber:       entry.PhoneNumber,
+			Content:           entry.Content,
+			Attempts:          entry.Attempts,
+			LastError:         entry.LastError,
+			ErrorCode:         entry.ErrorCode,
+			TraceID:           entry.TraceID,
+			ProviderRequestID: entry.ProviderRequestID,
+			CreatedAt:         entry.MessageCreatedAt,
+			DeadLetteredAt:    entry.DeadLetteredAt,
+		}
+	}
+
+	return &dto.DeadLetterListResponse{
+		Entries:    responseEntries,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// RequeueDeadLetter replays a dead-lettered message with a fresh retry budget.
+func (s *messageService) RequeueDeadLetter(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	return s.RetryMessage(ctx, id, true)
+}
+
+func (s *messageService) ListMessageAttempts(ctx context.Context, id uuid.UUID) (*dto.MessageAttemptListResponse, error) {
+	// Looked up first so another tenant's message is not found
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	response := &dto.MessageAttemptListResponse{
+		MessageID: id.String(),
+		Attempts:  []dto.MessageAttemptResponse{},
+	}
+	if s.attempts == nil {
+		return response, nil
+	}
+
+	attempts, err := s.attempts.ListByMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attempt := range attempts {
+		item := dto.MessageAttemptResponse{
+			Attempt:           attempt.Attempt,
+			StartedAt:         attempt.StartedAt,
+			DurationMs:        attempt.Duration.Milliseconds(),
+			Status:            attempt.Status,
+			StatusCode:        attempt.StatusCode,
+			ErrorCode:         attempt.ErrorCode,
+			Error:             attempt.Error,
+			ProviderRequestID: attempt.ProviderRequestID,
+		}
+		if json.Valid([]byte(attempt.WebhookResponse)) {
+			item.ProviderResponse = json.RawMessage(attempt.WebhookResponse)
+		}
+		response.Attempts = append(response.Attempts, item)
+	}
+
+	return response, nil
+}
+
+func (s *messageService) ProcessPendingMessages(ctx context.Context, batchSize int) (*BatchResult, error) {
+	messages, err := s.ClaimPendingMessages(ctx, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) == 0 {
+		return &BatchResult{}, nil
+	}
+
+	batchCtx, flushSent := s.BatchSentCache(ctx)
+	successCount := 0
+	for _, message := range messages {
+		if err := s.ProcessClaimedMessage(batchCtx, message); err == nil {
+			successCount++
+		}
+	}
+	flushSent(context.WithoutCancel(ctx))
+
+	logger.FromContext(ctx).Info("batch processing completed",
+		zap.Int("total", len(messages)),
+		zap.Int("successful", successCount),
+		zap.Int("failed", len(messages)-successCount),
+	)
+
+	return &BatchResult{
+		Attempted:  len(messages),
+		Successful: successCount,
+		Failed:     len(messages) - successCount,
+	}, nil
+}
+
+func (s *messageService) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	ctx = actor.WithActor(ctx, actor.System)
+
+	// The claim is committed before anything is sent, so the batch holds no
+	// transaction or connection across provider calls. A message whose
+	// outcome is never stored stays processing until the reaper releases it.
+	messages, err := s.repo.ClaimPendingMessages(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) > 0 {
+		logger.FromContext(ctx).Info("processing pending messages",
+			zap.Int("count", len(messages)),
+			zap.Int("batch_size", limit),
+		)
+	}
+
+	return messages, nil
+}
+
+func (s *messageService) ProcessClaimedMessage(ctx context.Context, message *entity.Message) error {
+	ctx = actor.WithActor(ctx, actor.System)
+
+	err := s.processSingleMessage(ctx, message)
+	if err != nil && !IsDeferred(err) {
+		logger.FromContext(ctx).Error("failed to process message",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+	}
+
+	s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+	return err
+}
+
+func (s *messageService) ReleaseClaimedMessages(ctx context.Context, messages []*entity.Message) {
+	ctx = actor.WithActor(ctx, actor.System)
+
+	for _, message := range messages {
+		msgCtx := ctx
+		if message.TenantID() != uuid.Nil {
+			msgCtx = tenant.WithTenantID(ctx, message.TenantID())
+		}
+		s.unclaim(msgCtx, message)
+	}
+
+	if len(messages) > 0 {
+		logger.FromContext(ctx).Info("returned unsent messages to pending",
+			zap.Int("count", len(messages)),
+		)
+	}
+}
+
+// ReapStaleMessages releases up to limit messages claimed before
+// startedBefore that are still processing, typically because the worker
+// crashed or lost its database connection mid-send. Each counts as a failed
+// attempt: it goes back to pending while it has attempts left, so a send
+// that did reach the provider may be repeated.
+func (s *messageService) ReapStaleMessages(ctx context.Context, startedBefore time.Time, limit int) (*ReapResult, error) {
+	ctx = actor.WithActor(ctx, actor.System)
+
+	messages, err := s.repo.FindStaleProcessing(ctx, startedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReapResult{Found: len(messages)}
+	for _, message := range messages {
+		msgCtx := ctx
+		if message.TenantID() != uuid.Nil {
+			msgCtx = tenant.WithTenantID(ctx, message.TenantID())
+		}
+
+		if err := message.ReleaseStale("processing claim went stale", string(apperrors.ErrorCodeStaleClaim)); err != nil {
+			continue
+		}
+		message.DeferRetry(s.retryBackoff.Delay(message.Attempts()))
+		expireBeforeRetry(message)
+
+		// A version conflict means the worker finished after all
+		if err := s.repo.Update(msgCtx, message); err != nil {
+			logger.FromContext(ctx).Warn("failed to release stale message",
+				zap.Error(err),
+				zap.String("message_id", message.ID().String()),
+			)
+			continue
+		}
+
+		switch {
+		case message.Status().IsFailed():
+			result.Failed++
+			s.deadLetterIfExhausted(msgCtx, message)
+		case message.Status().IsExpired():
+			result.Failed++
+		default:
+			result.Requeued++
+		}
+		metrics.MessagesReaped.WithLabelValues(message.Status().String()).Inc()
+		s.recordAudit(msgCtx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusProcessing.String(), message.ErrorCode())
+		s.notifyFinished(msgCtx, message)
+		s.eventBus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, message.ID(), message.Status()))
+
+		logger.FromContext(ctx).Warn("released stale processing message",
+			zap.String("message_id", message.ID().String()),
+			zap.Timep("processing_started_at", message.ProcessingStartedAt()),
+			zap.Int("attempts", message.Attempts()),
+			zap.String("status", message.Status().String()),
+		)
+	}
+
+	return result, nil
+}
+
+func (s *messageService) processSingleMessage(ctx context.Context, message *entity.Message) error {
+	if message.TraceID() != "" && requestid.FromContext(ctx) == "" {
+		ctx = requestid.WithRequestID(ctx, message.TraceID())
+	}
+	ctx, traceID := requestid.Ensure(ctx)
+
+	// The batch is claimed across tenants; scope every follow-up write to
+	// the tenant that owns this message
+	if message.TenantID() != uuid.Nil {
+		ctx = tenant.WithTenantID(ctx, message.TenantID())
+	}
+
+	// Stale content, such as a one-time password, is never sent
+	if message.IsExpiredAt(time.Now()) {
+		return s.expireClaimed(ctx, message)
+	}
+
+	// The number may have been blacklisted after the message was created.
+	// A failed lookup hands the message back for the next cycle.
+	blocked, err := s.isBlocked(ctx, message.Recipient(), message.TenantID())
+	if err != nil {
+		s.unclaim(ctx, message)
+		return err
+	}
+	if blocked {
+		return s.blockClaimed(ctx, message)
+	}
+
+	if until, hold := s.quietHours.HoldUntil(ctx, message); hold {
+		return s.deferClaimed(ctx, message, until)
+	}
+
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusPending.String(), "")
+
+	if !message.VerifyContentIntegrity() {
+		logger.FromContext(ctx).Error("message content does not match stored hash",
+			zap.String("message_id", message.ID().String()),
+			zap.String("content_hash", message.ContentHash()),
+		)
+
+		s.failAttempt(message, "content hash mismatch", apperrors.ErrorCodeIntegrity)
+		metrics.MessagesFailed.WithLabelValues(string(apperrors.ErrorCodeIntegrity)).Inc()
+		if updateErr := s.repo.Update(ctx, message); updateErr != nil {
+			logger.FromContext(ctx).Error("failed to update message after integrity check failure",
+				zap.Error(updateErr),
+				zap.String("message_id", message.ID().String()),
+			)
+		} else {
+			s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusProcessing.String(), message.ErrorCode())
+			s.deadLetterIfExhausted(ctx, message)
+			s.invalidateFinishedReads(ctx, message)
+			s.notifyFinished(ctx, message)
+		}
+
+		return apperrors.New(apperrors.ErrorCodeIntegrity, "content hash mismatch")
+	}
+
+	// Redactions only change what is sent; the stored content keeps its hash
+	text, err := s.moderation.screenBeforeSend(ctx, message.Content().String())
+	if err != nil {
+		return s.quarantineClaimed(ctx, message, err)
+	}
+
+	// Another worker may hold the same message if a claim was lost
+	claim, handled, err := s.guardSend(ctx, message, traceID)
+	if handled {
+		return err
+	}
+
+	// A channel whose provider was removed, or a destination that was
+	// deactivated, since the message was created fails like an unreachable
+	// provider
+	sender, err := s.senderFor(ctx, message.Channel(), message.DestinationID(), message.TenantID())
+	sendCtx, responseStatus := provider.WithResponseStatus(ctx)
+	started := time.Now()
+	simulated := s.dryRun || message.DryRun()
+	var webhookResp *provider.SendResult
+	switch {
+	case err != nil:
+	case simulated:
+		webhookResp = simulateSend(sender, message)
+	default:
+		webhookResp, err = sender.SendMessage(
+			sendCtx,
+			message.Recipient().String(),
+			text,
+			message.Metadata(),
+		)
+	}
+
+	if err != nil {
+		s.releaseSend(ctx, claim)
+
+		// Nothing reached the provider, so the attempt is not charged
+		var exhausted *provider.QuotaExhaustedError
+		if errors.As(err, &exhausted) {
+			return s.holdForQuota(ctx, message, exhausted.ResetAt, err)
+		}
+
+		appErr, ok := err.(*apperrors.AppError)
+		errorCode := apperrors.ErrorCodeInternal
+		if ok {
+			errorCode = appErr.Code
+		}
+		if s.errorMappings != nil && responseStatus.Refused {
+			errorCode = s.errorMappings.Normalize(ctx, sender.Name(), responseStatus, errorCode)
+		}
+
+		message.RecordTrace(traceID, "")
+		s.failAttempt(message, err.Error(), errorCode)
+		metrics.MessagesFailed.WithLabelValues(string(errorCode)).Inc()
+		s.recordAttempt(ctx, &repository.MessageAttempt{
+			MessageID:  message.ID(),
+			Attempt:    message.Attempts(),
+			StartedAt:  started,
+			Duration:   time.Since(started),
+			Status:     attemptFailed,
+			StatusCode: responseStatus.Code,
+			ErrorCode:  string(errorCode),
+			Error:      err.Error(),
+		})
+		if updateErr := s.repo.Update(ctx, message); updateErr != nil {
+			logger.FromContext(ctx).Error("failed to update message after send failure",
+				zap.Error(updateErr),
+				zap.String("message_id", message.ID().String()),
+			)
+		} else {
+			s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusProcessing.String(), message.ErrorCode())
+			s.deadLetterIfExhausted(ctx, message)
+			s.invalidateFinishedReads(ctx, message)
+			s.notifyFinished(ctx, message)
+		}
+
+		return fmt.Errorf("%s send failed: %w", message.Channel(), err)
+	}
+
+	responseJSON := providerResponse(webhookResp)
+	message.RecordTrace(traceID, webhookResp.ProviderRequestID)
+	if simulated {
+		message.MarkAsSimulated(webhookResp.MessageID, responseJSON)
+		metrics.MessagesSimulated.Inc()
+	} else {
+		message.MarkAsSent(webhookResp.MessageID, responseJSON)
+	}
+	s.recordAttempt(ctx, &repository.MessageAttempt{
+		MessageID:         message.ID(),
+		Attempt:           message.Attempts(),
+		StartedAt:         started,
+		Duration:          time.Since(started),
+		Status:            attemptSent,
+		StatusCode:        responseStatus.Code,
+		WebhookResponse:   responseJSON,
+		ProviderRequestID: webhookResp.ProviderRequestID,
+	})
+
+	// Recorded before the database update, so a lost update cannot lead to
+	// a second send
+	s.completeSend(ctx, claim, &cache.SendRecord{
+		WebhookMessageID:  webhookResp.MessageID,
+		ProviderRequestID: webhookResp.ProviderRequestID,
+		Response:          responseJSON,
+		SentAt:            *message.SentAt(),
+	})
+
+	if err := s.storeSent(ctx, message); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("message sent successfully",
+		zap.String("message_id", message.ID().String()),
+		zap.String("provider", sender.Name()),
+		zap.String("webhook_message_id", webhookResp.MessageID),
+		zap.String("trace_id", traceID),
+		zap.String("provider_request_id", webhookResp.ProviderRequestID),
+		zap.Bool("simulated", simulated),
+	)
+
+	return nil
+}
+
+// simulateSend stands in for sender on a dry run, answering as the provider
+// would on acceptance without calling it.
+func simulateSend(sender provider.SenderProvider, message *entity.Message) *provider.SendResult {
+	messageID := "simulated-" + message.ID().String()
+	// A map of strings and a bool always marshals
+	response, _ := json.Marshal(map[string]interface{}{
+		"message":   "Simulated",
+		"messageId": messageID,
+		"provider":  sender.Name(),
+		"simulated": true,
+	})
+	return &provider.SendResult{
+		MessageID: messageID,
+		Message:   "Simulated",
+		Response:  response,
+	}
+}
+
+// recordAttempt adds an attempt to the message's history. The history is for
+// debugging, so a failed write is only logged.
+func (s *messageService) recordAttempt(ctx context.Context, attempt *repository.MessageAttempt) {
+	if s.attempts == nil {
+		return
+	}
+	attempt.ID = uuid.New()
+	if err := s.attempts.Add(ctx, attempt); err != nil {
+		logger.FromContext(ctx).Warn("failed to record send attempt",
+			zap.Error(err),
+			zap.String("message_id", attempt.MessageID.String()),
+		)
+	}
+}
+
+// providerResponse is the JSON stored as the message's webhook response: the
+// provider's own response body when it sent one, otherwise its status and
+// message ID.
+func providerResponse(result *provider.SendResult) string {
+	var compacted bytes.Buffer
+	if len(result.Response) > 0 && json.Compact(&compacted, result.Response) == nil {
+		return compacted.String()
+	}
+
+	// A map of strings always marshals
+	data, _ := json.Marshal(map[string]string{
+		"message":   result.Message,
+		"messageId": result.MessageID,
+	})
+	return string(data)
+}
+
+// storeSent stores a message that was handed to the provider.
+func (s *messageService) storeSent(ctx context.Context, message *entity.Message) error {
+	if err := s.repo.Update(ctx, message); err != nil {
+		return err
+	}
+
+	metrics.MessagesSent.Inc()
+	s.invalidateSentReads(ctx, message)
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusProcessing.String(), "")
+	s.notifyFinished(ctx, message)
+
+	if batch, ok := ctx.Value(sentCacheBatchKey{}).(*sentCacheBatch); ok {
+		batch.add(toCachedMessage(message))
+		return nil
+	}
+	if err := s.messageCache.CacheSentMessage(ctx, toCachedMessage(message)); err != nil {
+		logger.FromContext(ctx).Warn("failed to cache sent message (non-critical)",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+	}
+
+	return nil
+}
+
+type sentCacheBatchKey struct{}
+
+// sentCacheBatch collects the messages sent under a BatchSentCache context;
+// workers add to it concurrently.
+type sentCacheBatch struct {
+	mu       sync.Mutex
+	messages []*cache.CachedMessage
+}
+
+func (b *sentCacheBatch) add(message *cache.CachedMessage) {
+	b.mu.Lock()
+	b.messages = append(b.messages, message)
+	b.mu.Unlock()
+}
+
+// take empties the batch, so a second flush caches nothing twice.
+func (b *sentCacheBatch) take() []*cache.CachedMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := b.messages
+	b.messages = nil
+	return messages
+}
+
+func (s *messageService) BatchSentCache(ctx context.Context) (context.Context, func(context.Context)) {
+	batch := &sentCacheBatch{}
+	flush := func(ctx context.Context) {
+		messages := batch.take()
+		if len(messages) == 0 {
+			return
+		}
+		if err := s.messageCache.CacheSentMessages(ctx, messages); err != nil {
+			logger.FromContext(ctx).Warn("failed to cache sent messages (non-critical)",
+				zap.Error(err),
+				zap.Int("count", len(messages)),
+			)
+		}
+	}
+	return context.WithValue(ctx, sentCacheBatchKey{}, batch), flush
+}
+
+// guardSend takes the message's send guard. It reports handled when the
+// message must not be sent here: another worker is sending it, and it goes
+// back to pending, or it was already sent, and the recorded result is
+// stored instead. The guard fails open: if Redis is unavailable the message
+// is sent without it.
+func (s *messageService) guardSend(ctx context.Context, message *entity.Message, traceID string) (*cache.SendClaim, bool, error) {
+	if s.sendGuard == nil {
+		return nil, false, nil
+	}
+
+	claim, err := s.sendGuard.Acquire(ctx, message.ID())
+	if err != nil {
+		logger.FromContext(ctx).Warn("send guard unavailable, sending without it",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+		return nil, false, nil
+	}
+
+	switch claim.State {
+	case cache.SendInFlight:
+		metrics.DuplicateSendsPrevented.WithLabelValues("in_flight").Inc()
+		s.unclaim(ctx, message)
+
+		logger.FromContext(ctx).Warn("message is being sent by another worker, skipping",
+			zap.String("message_id", message.ID().String()),
+		)
+
+		return nil, true, apperrors.New(apperrors.ErrorCodeSendInProgress, "message is already being sent")
+	case cache.SendCompleted:
+		metrics.DuplicateSendsPrevented.WithLabelValues("already_sent").Inc()
+		message.RecordTrace(traceID, claim.Record.ProviderRequestID)
+		message.MarkAsSent(claim.Record.WebhookMessageID, claim.Record.Response)
+
+		logger.FromContext(ctx).Warn("message was already sent, storing the recorded result instead of sending again",
+			zap.String("message_id", message.ID().String()),
+			zap.String("webhook_message_id", claim.Record.WebhookMessageID),
+			zap.Time("sent_at", claim.Record.SentAt),
+		)
+
+		return nil, true, s.storeSent(ctx, message)
+	}
+
+	return claim, false, nil
+}
+
+// completeSend records a successful send in the guard. A failure leaves the
+// guard held until it expires, which still blocks a second send.
+func (s *messageService) completeSend(ctx context.Context, claim *cache.SendClaim, record *cache.SendRecord) {
+	if claim == nil {
+		return
+	}
+	if err := s.sendGuard.Complete(ctx, claim, record); err != nil {
+		logger.FromContext(ctx).Warn("failed to record send result in send guard",
+			zap.Error(err),
+		)
+	}
+}
+
+// releaseSend frees the guard after a failed send so the retry can send.
+func (s *messageService) releaseSend(ctx context.Context, claim *cache.SendClaim) {
+	if claim == nil {
+		return
+	}
+	if err := s.sendGuard.Release(ctx, claim); err != nil {
+		logger.FromContext(ctx).Warn("failed to release send guard, the retry waits until it expires",
+			zap.Error(err),
+		)
+	}
+}
+
+// quarantineClaimed holds back a claimed message the content filter refused
+// at send time, until an operator requeues it. The claim does not count as
+// an attempt.
+func (s *messageService) quarantineClaimed(ctx context.Context, message *entity.Message, filterErr error) error {
+	errorCode := string(apperrors.ErrorCodeContentRejected)
+	if appErr, ok := filterErr.(*apperrors.AppError); ok {
+		errorCode = string(appErr.Code)
+	}
+
+	if err := message.Unclaim(); err != nil {
+		return err
+	}
+	if err := message.Quarantine(filterErr.Error(), errorCode); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, message); err != nil {
+		return err
+	}
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusProcessing.String(), message.ErrorCode())
+	s.notifyFinished(ctx, message)
+
+	logger.FromContext(ctx).Warn("message quarantined, content filter refused it before sending",
+		zap.String("message_id", message.ID().String()),
+		zap.Error(filterErr),
+	)
+
+	return filterErr
+}
+
+// unclaim returns a claimed message that was not sent to pending. If that
+// fails the reaper releases it later.
+func (s *messageService) unclaim(ctx context.Context, message *entity.Message) {
+	if err := message.Unclaim(); err != nil {
+		return
+	}
+	if err := s.repo.Update(ctx, message); err != nil {
+		logger.FromContext(ctx).Error("failed to return claimed message to pending",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+	}
+}
+
+// blockClaimed stores a claimed message to a blacklisted number as blocked
+// instead of sending it. The claim does not count as an attempt.
+func (s *messageService) blockClaimed(ctx context.Context, message *entity.Message) error {
+	if err := message.Unclaim(); err != nil {
+		return err
+	}
+	if err := message.Block(recipientBlockedReason, string(apperrors.ErrorCodeRecipientBlocked)); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, message); err != nil {
+		return err
+	}
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusPending.String(), message.ErrorCode())
+	s.notifyFinished(ctx, message)
+
+	logger.FromContext(ctx).Info("message blocked, recipient is blacklisted",
+		zap.String("message_id", message.ID().String()),
+	)
+
+	return apperrors.New(apperrors.ErrorCodeRecipientBlocked, recipientBlockedReason)
+}
+
+// deferClaimed schedules a claimed message for the end of the recipient's
+// quiet hours instead of sending it. The claim does not count as an attempt.
+func (s *messageService) deferClaimed(ctx context.Context, message *entity.Message, until time.Time) error {
+	if err := message.Unclaim(); err != nil {
+		return err
+	}
+	message.ScheduleAt(until)
+	if err := s.repo.Update(ctx, message); err != nil {
+		return err
+	}
+	metrics.MessagesDeferred.WithLabelValues(message.Recipient().PhoneNumber().RegionCode()).Inc()
+
+	logger.FromContext(ctx).Info("message held back for the recipient's quiet hours",
+		zap.String("message_id", message.ID().String()),
+		zap.Time("scheduled_at", until),
+	)
+
+	return apperrors.New(apperrors.ErrorCodeQuietHours, "recipient is in quiet hours")
+}
+
+// holdForQuota hands a claimed message back until the provider's daily quota
+// resets at resetAt, without counting the attempt. It returns err, the
+// refusal of the quota.
+func (s *messageService) holdForQuota(ctx context.Context, message *entity.Message, resetAt time.Time, err error) error {
+	if unclaimErr := message.Unclaim(); unclaimErr != nil {
+		return unclaimErr
+	}
+	message.ScheduleAt(resetAt)
+	if updateErr := s.repo.Update(ctx, message); updateErr != nil {
+		return updateErr
+	}
+
+	logger.FromContext(ctx).Info("message held back until the provider's daily quota resets",
+		zap.String("message_id", message.ID().String()),
+		zap.Time("scheduled_at", resetAt),
+	)
+
+	return err
+}
+
+// expireClaimed stores a claimed message whose expiry has passed as expired
+// instead of sending it. The claim does not count as an attempt.
+func (s *messageService) expireClaimed(ctx context.Context, message *entity.Message) error {
+	if err := message.Unclaim(); err != nil {
+		return err
+	}
+	if err := message.Expire(messageExpiredReason, string(apperrors.ErrorCodeMessageExpired)); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, message); err != nil {
+		return err
+	}
+	metrics.MessagesExpired.Inc()
+	s.invalidateSentReads(ctx, message)
+	s.recordAudit(ctx, AuditActionMessageStatusChanged, message, valueobject.MessageStatusPending.String(), message.ErrorCode())
+	s.notifyFinished(ctx, message)
+
+	logger.FromContext(ctx).Info("message expired, not sending stale content",
+		zap.String("message_id", message.ID().String()),
+		zap.Timep("expires_at", message.ExpiresAt()),
+	)
+
+	return apperrors.New(apperrors.ErrorCodeMessageExpired, messageExpiredReason)
+}
+
+// failAttempt records a failed send. A failure another attempt would repeat
+// fails the message right away; any other backs off until the next attempt.
+func (s *messageService) failAttempt(message *entity.Message, errorMsg string, errorCode apperrors.ErrorCode) {
+	if !errorCode.Retryable() {
+		message.FailPermanently(errorMsg, string(errorCode))
+		return
+	}
+	message.MarkAsFailed(errorMsg, string(errorCode))
+	message.DeferRetry(s.retryBackoff.Delay(message.Attempts()))
+	expireBeforeRetry(message)
+}
+
+// expireBeforeRetry expires a message that went back to pending after a
+// failed attempt but would only be retried after its expiry. The failure
+// stays in last_error.
+func expireBeforeRetry(message *entity.Message) {
+	if !message.Status().IsPending() {
+		return
+	}
+	retryAt := time.Now()
+	if nextRetryAt := message.NextRetryAt(); nextRetryAt != nil {
+		retryAt = *nextRetryAt
+	}
+	if !message.IsExpiredAt(retryAt) {
+		return
+	}
+	reason := fmt.Sprintf("message expired before it could be retried: %s", message.LastError())
+	if message.Expire(reason, string(apperrors.ErrorCodeMessageExpired)) == nil {
+		metrics.MessagesExpired.Inc()
+	}
+}
+
+// deadLetterIfExhausted snapshots a message that has no attempts left. The
+// message row stays the source of truth, so a failure here is only logged.
+func (s *messageService) deadLetterIfExhausted(ctx context.Context, message *entity.Message) {
+	if s.deadLetters == nil || !message.Status().IsFailed() {
+		return
+	}
+
+	entry := &repository.DeadLetter{
+		MessageID:         message.ID(),
+		PhoneNumber:       phoneNumberOf(message),
+		Content:           message.Content().String(),
+		Attempts:          message.Attempts(),
+		LastError:         message.LastError(),
+		ErrorCode:         message.ErrorCode(),
+		TraceID:           message.TraceID(),
+		ProviderRequestID: message.ProviderRequestID(),
+		MessageCreatedAt:  message.CreatedAt(),
+		DeadLetteredAt:    time.Now().UTC(),
+	}
+
+	if err := s.deadLetters.Add(ctx, entry); err != nil {
+		logger.FromContext(ctx).Error("failed to dead-letter message",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+		return
+	}
+
+	metrics.MessagesDeadLettered.Inc()
+
+	logger.FromContext(ctx).Warn("message moved to dead letter queue",
+		zap.String("message_id", message.ID().String()),
+		zap.Int("attempts", message.Attempts()),
+		zap.String("error_code", message.ErrorCode()),
+	)
+}
+
+func (s *messageService) removeDeadLetter(ctx context.Context, message *entity.Message) {
+	if s.deadLetters == nil {
+		return
+	}
+	if err := s.deadLetters.Remove(ctx, message.ID()); err != nil {
+		logger.FromContext(ctx).Warn("failed to remove dead letter entry (non-critical)",
+			zap.Error(err),
+			zap.String("message_id", message.ID().String()),
+		)
+	}
+}
+
+// recordAudit logs a write to message; from is its status before the write,
+// empty for a new message.
+func (s *messageService) recordAudit(ctx context.Context, action string, message *entity.Message, from, details string) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, AuditRecord{
+		Action:       action,
+		ResourceType: AuditResourceMessage,
+		ResourceID:   message.ID().String(),
+		TenantID:     message.TenantID(),
+		FromStatus:   from,
+		ToStatus:     message.Status().String(),
+		Details:      details,
+	})
+}
+
+func (s *messageService) toDTO(message *entity.Message) *dto.MessageResponse {
+	segmentation := message.Content().Segmentation()
+	resp := &dto.MessageResponse{
+		ID:                message.ID().String(),
+		Channel:           message.Channel().String(),
+		PhoneNumber:       phoneNumberOf(message),
+		Recipient:         message.Recipient().String(),
+		Content:           message.Content().String(),
+		ContentHash:       message.ContentHash(),
+		Encoding:          string(segmentation.Encoding),
+		Segments:          segmentation.Segments,
+		Status:            message.Status().String(),
+		CreatedAt:         message.CreatedAt(),
+		SentAt:            message.SentAt(),
+		DeliveredAt:       message.DeliveredAt(),
+		ScheduledAt:       message.ScheduledAt(),
+		ExpiresAt:         message.ExpiresAt(),
+		NextRetryAt:       message.NextRetryAt(),
+		Attempts:          message.Attempts(),
+		MaxAttempts:       message.MaxAttempts(),
+		LastError:         message.LastError(),
+		ErrorCode:         message.ErrorCode(),
+		WebhookMessageID:  message.WebhookMessageID(),
+		ProviderResponse:  providerResponseOf(message),
+		TraceID:           message.TraceID(),
+		ProviderRequestID: message.ProviderRequestID(),
+		ClientReference:   message.IdempotencyKey(),
+		Priority:          message.Priority().String(),
+		TenantID:          optionalID(message.TenantID()),
+		Metadata:          message.Metadata(),
+		CampaignID:        optionalID(message.CampaignID()),
+		DestinationID:     optionalID(message.DestinationID()),
+		CreatedBy:         message.CreatedBy(),
+		DryRun:            message.DryRun(),
+		Simulated:         message.Simulated(),
+		CallbackURL:       message.CallbackURL(),
+	}
+	if part := message.Part(); part != nil {
+		resp.SplitGroupID = part.GroupID.String()
+		resp.PartNumber = part.Number
+		resp.PartCount = part.Count
+	}
+	return resp
+}
+
+// providerResponseOf is nil unless the message holds a JSON provider
+// response.
+func providerResponseOf(message *entity.Message) json.RawMessage {
+	response := message.WebhookResponse()
+	if response == "" || !json.Valid([]byte(response)) {
+		return nil
+	}
+	return json.RawMessage(response)
+}
+
+// phoneNumberOf is empty for messages that are not SMS.
+func phoneNumberOf(message *entity.Message) string {
+	if phone := message.PhoneNumber(); phone != nil {
+		return phone.String()
+	}
+	return ""
+}
+
+// optionalID formats an ID that may be unset as "" rather than the nil UUID.
+func optionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
 	}
+	return id.String()
 }
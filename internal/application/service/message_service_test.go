@@ -3,15 +3,24 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/application/dto"
 	"github.com/eneskaya/insider-messaging/internal/application/service"
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
 	"github.com/eneskaya/insider-messaging/internal/domain/repository"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
-	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -40,6 +49,22 @@ func (m *MockMessageRepository) FindByID(ctx context.Context, id uuid.UUID) (*en
 	return args.Get(0).(*entity.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Message, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindByWebhookMessageID(ctx context.Context, webhookMessageID string) (*entity.Message, error) {
+	args := m.Called(ctx, webhookMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Message), args.Error(1)
+}
+
 func (m *MockMessageRepository) FindSentMessages(ctx context.Context, limit, offset int) ([]*entity.Message, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -48,7 +73,23 @@ func (m *MockMessageRepository) FindSentMessages(ctx context.Context, limit, off
 	return args.Get(0).([]*entity.Message), args.Error(1)
 }
 
-func (m *MockMessageRepository) FindPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+func (m *MockMessageRepository) FindByFilter(ctx context.Context, filter repository.MessageFilter, limit, offset int) ([]*entity.Message, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Message), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMessageRepository) SearchContent(ctx context.Context, q *valueobject.SearchQuery, limit, offset int) ([]*entity.Message, int64, error) {
+	args := m.Called(ctx, q, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.Message), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMessageRepository) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
 	args := m.Called(ctx, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -56,6 +97,22 @@ func (m *MockMessageRepository) FindPendingMessages(ctx context.Context, limit i
 	return args.Get(0).([]*entity.Message), args.Error(1)
 }
 
+// claimed marks messages processing, as ClaimPendingMessages returns them.
+func claimed(messages ...*entity.Message) []*entity.Message {
+	for _, message := range messages {
+		message.MarkAsProcessing()
+	}
+	return messages
+}
+
+func (m *MockMessageRepository) FindStaleProcessing(ctx context.Context, startedBefore time.Time, limit int) ([]*entity.Message, error) {
+	args := m.Called(ctx, startedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
 func (m *MockMessageRepository) GetStats(ctx context.Context) (*repository.MessageStats, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -64,45 +121,133 @@ func (m *MockMessageRepository) GetStats(ctx context.Context) (*repository.Messa
 	return args.Get(0).(*repository.MessageStats), args.Error(1)
 }
 
-func (m *MockMessageRepository) BeginTx(ctx context.Context) (repository.Transaction, error) {
+func (m *MockMessageRepository) CreateBatch(ctx context.Context, messages []*entity.Message) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) FindByFilterAfter(ctx context.Context, filter repository.MessageFilter, after *repository.MessageCursor, limit int) ([]*entity.Message, error) {
+	args := m.Called(ctx, filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetCampaignStats(ctx context.Context, campaignID uuid.UUID) (*repository.MessageStats, error) {
+	args := m.Called(ctx, campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.MessageStats), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetStatsByCaller(ctx context.Context) ([]repository.CallerStats, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(repository.Transaction), args.Error(1)
+	return args.Get(0).([]repository.CallerStats), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetSendPerformance(ctx context.Context, since time.Time) (*repository.SendPerformance, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.SendPerformance), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindDuplicateContent(ctx context.Context, from, to time.Time, minCount, limit int) ([]repository.DuplicateContent, error) {
+	args := m.Called(ctx, from, to, minCount, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.DuplicateContent), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetDeliveryByPrefix(ctx context.Context, from, to time.Time, prefixLength int) ([]repository.PrefixDelivery, error) {
+	args := m.Called(ctx, from, to, prefixLength)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PrefixDelivery), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetStatsTimeSeries(ctx context.Context, bucket repository.StatsBucket, from, to time.Time) (*repository.MessageTimeSeries, error) {
+	args := m.Called(ctx, bucket, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.MessageTimeSeries), args.Error(1)
 }
 
-// Mock Transaction
-type MockTransaction struct {
+func (m *MockMessageRepository) RequeueFailed(ctx context.Context, filter repository.FailedMessageFilter, resetAttempts bool) (int64, error) {
+	args := m.Called(ctx, filter, resetAttempts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) SoftDeleteOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, status, createdBefore, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) PurgeOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, status, createdBefore, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindArchivable(ctx context.Context, statuses []string, createdBefore time.Time, limit int) ([]*entity.Message, error) {
+	args := m.Called(ctx, statuses, createdBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) PurgeByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Mock Dead Letter Repository
+type MockDeadLetterRepository struct {
 	mock.Mock
 }
 
-func (m *MockTransaction) Commit() error {
-	args := m.Called()
+func (m *MockDeadLetterRepository) Add(ctx context.Context, entry *repository.DeadLetter) error {
+	args := m.Called(ctx, entry)
 	return args.Error(0)
 }
 
-func (m *MockTransaction) Rollback() error {
-	args := m.Called()
-	return args.Error(0)
+func (m *MockDeadLetterRepository) List(ctx context.Context, limit, offset int) ([]*repository.DeadLetter, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.DeadLetter), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockTransaction) GetContext() context.Context {
-	args := m.Called()
-	return args.Get(0).(context.Context)
+func (m *MockDeadLetterRepository) Remove(ctx context.Context, messageID uuid.UUID) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
 }
 
-// Mock Webhook Client
-type MockWebhookClient struct {
+// Mock Sender Provider
+type MockSenderProvider struct {
 	mock.Mock
 }
 
-func (m *MockWebhookClient) SendMessage(ctx context.Context, phone, content string) (*infrahttp.WebhookResponse, error) {
-	args := m.Called(ctx, phone, content)
+func (m *MockSenderProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockSenderProvider) SendMessage(ctx context.Context, phone, content string, metadata map[string]string) (*provider.SendResult, error) {
+	args := m.Called(ctx, phone, content, metadata)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*infrahttp.WebhookResponse), args.Error(1)
+	return args.Get(0).(*provider.SendResult), args.Error(1)
 }
 
 // Mock Cache
@@ -115,6 +260,11 @@ func (m *MockMessageCache) CacheSentMessage(ctx context.Context, msg *cache.Cach
 	return args.Error(0)
 }
 
+func (m *MockMessageCache) CacheSentMessages(ctx context.Context, msgs []*cache.CachedMessage) error {
+	args := m.Called(ctx, msgs)
+	return args.Error(0)
+}
+
 func (m *MockMessageCache) GetSentMessage(ctx context.Context, messageID string) (*cache.CachedMessage, error) {
 	args := m.Called(ctx, messageID)
 	if args.Get(0) == nil {
@@ -128,14 +278,89 @@ func (m *MockMessageCache) IsCached(ctx context.Context, messageID string) (bool
 	return args.Bool(0), args.Error(1)
 }
 
+// GetOrLoadSentPage and GetOrLoadStats behave as a miss unless the
+// expectation returns cached bytes
+func (m *MockMessageCache) GetOrLoadSentPage(ctx context.Context, scope string, page, pageSize int, load func() ([]byte, error)) ([]byte, error) {
+	args := m.Called(ctx, scope, page, pageSize)
+	if args.Get(0) == nil {
+		return load()
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockMessageCache) GetOrLoadStats(ctx context.Context, scope string, load func() ([]byte, error)) ([]byte, error) {
+	args := m.Called(ctx, scope)
+	if args.Get(0) == nil {
+		return load()
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockMessageCache) InvalidateSentMessages(ctx context.Context, scopes ...string) error {
+	args := m.Called(ctx, scopes)
+	return args.Error(0)
+}
+
+// Mock Recipient Guard
+type MockRecipientGuard struct {
+	mock.Mock
+}
+
+func (m *MockRecipientGuard) Reserve(ctx context.Context, phoneNumber, contentHash string, messageID uuid.UUID) (*cache.RecipientReservation, error) {
+	args := m.Called(ctx, phoneNumber, contentHash, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cache.RecipientReservation), args.Error(1)
+}
+
+func (m *MockRecipientGuard) Release(ctx context.Context, reservation *cache.RecipientReservation) error {
+	args := m.Called(ctx, reservation)
+	return args.Error(0)
+}
+
+type MockSendGuard struct {
+	mock.Mock
+}
+
+func (m *MockSendGuard) Acquire(ctx context.Context, messageID uuid.UUID) (*cache.SendClaim, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cache.SendClaim), args.Error(1)
+}
+
+func (m *MockSendGuard) Complete(ctx context.Context, claim *cache.SendClaim, record *cache.SendRecord) error {
+	args := m.Called(ctx, claim, record)
+	return args.Error(0)
+}
+
+func (m *MockSendGuard) Release(ctx context.Context, claim *cache.SendClaim) error {
+	args := m.Called(ctx, claim)
+	return args.Error(0)
+}
+
+type MockDestinationSenders struct {
+	mock.Mock
+}
+
+func (m *MockDestinationSenders) SenderFor(ctx context.Context, destinationID, tenantID uuid.UUID, channel valueobject.Channel) (provider.SenderProvider, error) {
+	args := m.Called(ctx, destinationID, tenantID, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(provider.SenderProvider), args.Error(1)
+}
+
 // Tests
 func TestCreateMessage_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
 	req := &dto.CreateMessageRequest{
 		PhoneNumber: "+905551234567",
@@ -156,42 +381,54 @@ func TestCreateMessage_Success(t *testing.T) {
 	assert.Equal(t, "pending", result.Status)
 	assert.Equal(t, 0, result.Attempts)
 	assert.Equal(t, 3, result.MaxAttempts)
+	assert.Equal(t, "normal", result.Priority)
+	assert.Equal(t, "GSM-7", result.Encoding)
+	assert.Equal(t, 1, result.Segments)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestCreateMessage_InvalidPhone(t *testing.T) {
+func TestCreateMessage_Scheduled(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
+	scheduledAt := time.Now().Add(time.Hour)
 	req := &dto.CreateMessageRequest{
-		PhoneNumber: "invalid-phone",
-		Content:     "Test",
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+		ScheduledAt: &scheduledAt,
 	}
 
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.ScheduledAt() != nil && msg.ScheduledAt().Equal(scheduledAt)
+	})).Return(nil)
+
 	// Act
 	result, err := svc.CreateMessage(context.Background(), req)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "phone number")
+	assert.NoError(t, err)
+	assert.NotNil(t, result.ScheduledAt)
+	assert.Equal(t, "pending", result.Status)
+	mockRepo.AssertExpectations(t)
 }
 
-func TestCreateMessage_EmptyContent(t *testing.T) {
+func TestCreateMessage_ScheduledInPast(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
+	scheduledAt := time.Now().Add(-time.Minute)
 	req := &dto.CreateMessageRequest{
 		PhoneNumber: "+905551234567",
-		Content:     "",
+		Content:     "Test message",
+		ScheduledAt: &scheduledAt,
 	}
 
 	// Act
@@ -200,301 +437,2969 @@ func TestCreateMessage_EmptyContent(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "content")
+	assert.Contains(t, err.Error(), "scheduled_at")
+	mockRepo.AssertNotCalled(t, "Create")
 }
 
-func TestCreateMessage_ContentTooLong(t *testing.T) {
+func TestCreateMessage_ExpiresAt(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
-
-	// Create a string with 161 'a' characters
-	longContent := ""
-	for i := 0; i < 161; i++ {
-		longContent += "a"
-	}
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
+	expiresAt := time.Now().Add(5 * time.Minute)
 	req := &dto.CreateMessageRequest{
 		PhoneNumber: "+905551234567",
-		Content:     longContent, // 161 characters (exceeds 160 limit)
+		Content:     "Your code is 123456",
+		ExpiresAt:   &expiresAt,
 	}
 
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.ExpiresAt() != nil && msg.ExpiresAt().Equal(expiresAt)
+	})).Return(nil)
+
 	// Act
 	result, err := svc.CreateMessage(context.Background(), req)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.NotNil(t, result.ExpiresAt)
+	mockRepo.AssertExpectations(t)
 }
 
-func TestGetMessage_Success(t *testing.T) {
+func TestCreateMessage_ExpiresAtInvalid(t *testing.T) {
+	scheduledAt := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
+	beforeSchedule := time.Now().Add(30 * time.Minute)
+
+	tests := []struct {
+		name        string
+		scheduledAt *time.Time
+		expiresAt   time.Time
+	}{
+		{name: "in the past", expiresAt: past},
+		{name: "before scheduled_at", scheduledAt: &scheduledAt, expiresAt: beforeSchedule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+			expiresAt := tt.expiresAt
+			req := &dto.CreateMessageRequest{
+				PhoneNumber: "+905551234567",
+				Content:     "Test message",
+				ScheduledAt: tt.scheduledAt,
+				ExpiresAt:   &expiresAt,
+			}
+
+			// Act
+			result, err := svc.CreateMessage(context.Background(), req)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, result)
+			assert.Contains(t, err.Error(), "expires_at")
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+// recordedCallbacks keeps the status callbacks it is given and refuses
+// every callback URL with urlErr.
+type recordedCallbacks struct {
+	callbacks []*provider.StatusCallback
+	urlErr    error
+}
+
+func (r *recordedCallbacks) CheckURL(ctx context.Context, rawURL string) error {
+	return r.urlErr
+}
+
+func (r *recordedCallbacks) Notify(ctx context.Context, callback *provider.StatusCallback) {
+	r.callbacks = append(r.callbacks, callback)
+}
+
+func TestCreateMessage_CallbackURLInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		callbacks   provider.CallbackNotifier
+		callbackURL string
+		wantErr     string
+	}{
+		{name: "callbacks disabled", callbackURL: "https://client.example.com/hook", wantErr: "not enabled"},
+		{name: "relative URL", callbacks: &recordedCallbacks{}, callbackURL: "/hook", wantErr: "absolute https"},
+		{name: "unsupported scheme", callbacks: &recordedCallbacks{}, callbackURL: "ftp://client.example.com/hook", wantErr: "absolute https"},
+		{name: "plain http", callbacks: &recordedCallbacks{}, callbackURL: "http://client.example.com/hook", wantErr: "absolute https"},
+		{name: "internal address", callbacks: &recordedCallbacks{urlErr: errors.New("10.0.0.5: address is not public")}, callbackURL: "https://internal.example.com/hook", wantErr: "callback_url is not allowed"},
+		{name: "too long", callbacks: &recordedCallbacks{}, callbackURL: "https://client.example.com/" + strings.Repeat("a", 2048), wantErr: "at most 2048"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, tt.callbacks, 0, false)
+
+			req := &dto.CreateMessageRequest{
+				PhoneNumber: "+905551234567",
+				Content:     "Test message",
+				CallbackURL: tt.callbackURL,
+			}
+
+			// Act
+			result, err := svc.CreateMessage(context.Background(), req)
+
+			// Assert
+			assert.Error(t, err)
+			assert.Nil(t, result)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestProcessPendingMessages_PostsStatusCallback(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
+	callbacks := &recordedCallbacks{}
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, callbacks, 0, false)
 
-	messageID := uuid.New()
 	phone, _ := valueobject.NewPhoneNumber("+905551234567")
-	content, _ := valueobject.NewMessageContent("Test", 160)
+	content, _ := valueobject.NewMessageContent("Test message", 160)
 	message, _ := entity.NewMessage(phone, content, 3)
+	message.AssignCallbackURL("https://client.example.com/hook")
+	message.AssignIdempotencyKey("order-42")
+	untracked, _ := entity.NewMessage(phone, content, 3)
 
-	mockRepo.On("FindByID", mock.Anything, messageID).Return(message, nil)
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message, untracked), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
 
 	// Act
-	result, err := svc.GetMessage(context.Background(), messageID)
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "+905551234567", result.PhoneNumber)
-	mockRepo.AssertExpectations(t)
+	assert.Equal(t, 2, result.Successful)
+	if assert.Len(t, callbacks.callbacks, 1) {
+		callback := callbacks.callbacks[0]
+		assert.Equal(t, "https://client.example.com/hook", callback.URL)
+		assert.Equal(t, message.ID(), callback.MessageID)
+		assert.Equal(t, "sent", callback.Status)
+		assert.Equal(t, "order-42", callback.ClientReference)
+		assert.Equal(t, "webhook-123", callback.WebhookMessageID)
+		assert.Equal(t, 1, callback.Attempts)
+		assert.NotEmpty(t, callback.TraceID)
+	}
 }
 
-func TestGetMessage_NotFound(t *testing.T) {
+func TestCreateMessage_EmailChannel(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
-	mockCache := new(MockMessageCache)
+	emailSender := new(MockSenderProvider)
+	channels := provider.ChannelProviders{valueobject.ChannelEmail: emailSender}
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, channels, nil, nil, nil, nil, nil, nil, 0, false)
 
-	messageID := uuid.New()
-	mockRepo.On("FindByID", mock.Anything, messageID).Return(nil, errors.New("not found"))
+	req := &dto.CreateMessageRequest{
+		Channel:   "email",
+		Recipient: "Ada@Example.com",
+		Content:   strings.Repeat("Longer than an SMS. ", 20),
+	}
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.Channel() == valueobject.ChannelEmail && msg.PhoneNumber() == nil
+	})).Return(nil)
 
 	// Act
-	result, err := svc.GetMessage(context.Background(), messageID)
+	result, err := svc.CreateMessage(context.Background(), req)
 
 	// Assert
-	assert.Error(t, err)
-	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, "email", result.Channel)
+	assert.Equal(t, "Ada@example.com", result.Recipient)
+	assert.Empty(t, result.PhoneNumber)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestProcessPendingMessages_Success(t *testing.T) {
-	// Arrange
-	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
-	mockCache := new(MockMessageCache)
-
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
-
-	phone, _ := valueobject.NewPhoneNumber("+905551234567")
-	content, _ := valueobject.NewMessageContent("Test message", 160)
-	message, _ := entity.NewMessage(phone, content, 3)
+func TestCreateMessage_InvalidChannelRecipient(t *testing.T) {
+	channels := provider.ChannelProviders{
+		valueobject.ChannelEmail: new(MockSenderProvider),
+		valueobject.ChannelPush:  new(MockSenderProvider),
+	}
 
-	mockTx := new(MockTransaction)
-	mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockTx.On("GetContext").Return(context.Background())
-	mockRepo.On("FindPendingMessages", mock.Anything, 10).
-		Return([]*entity.Message{message}, nil)
-	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
-		Return(nil)
+	tests := []struct {
+		name string
+		req  *dto.CreateMessageRequest
+		code string
+	}{
+		{name: "unknown channel", req: &dto.CreateMessageRequest{Channel: "fax", PhoneNumber: "+905551234567", Content: "Test"}, code: "VALIDATION_ERROR"},
+		{name: "email without recipient", req: &dto.CreateMessageRequest{Channel: "email", Content: "Test"}, code: "VALIDATION_ERROR"},
+		{name: "email to a phone number", req: &dto.CreateMessageRequest{Channel: "email", PhoneNumber: "+905551234567", Content: "Test"}, code: "VALIDATION_ERROR"},
+		{name: "invalid email address", req: &dto.CreateMessageRequest{Channel: "email", Recipient: "not-an-address", Content: "Test"}, code: "VALIDATION_ERROR"},
+		{name: "push content too long", req: &dto.CreateMessageRequest{Channel: "push", Recipient: "device-token", Content: strings.Repeat("a", 1025)}, code: "VALIDATION_ERROR"},
+	}
 
-	webhookResp := &infrahttp.WebhookResponse{
-		MessageID: "webhook-123",
-		Message:   "Message sent successfully",
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, channels, nil, nil, nil, nil, nil, nil, 0, false)
+
+			// Act
+			result, err := svc.CreateMessage(context.Background(), tt.req)
+
+			// Assert
+			assert.Nil(t, result)
+			if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+				assert.Equal(t, tt.code, string(appErr.Code))
+			}
+			mockRepo.AssertNotCalled(t, "Create")
+		})
 	}
-	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message").
-		Return(webhookResp, nil)
+}
 
-	mockCache.On("CacheSentMessage", mock.Anything, mock.AnythingOfType("*cache.CachedMessage")).
-		Return(nil)
-	mockTx.On("Commit").Return(nil)
-	mockTx.On("Rollback").Return(nil)
+func TestCreateMessage_ChannelWithoutProvider(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	req := &dto.CreateMessageRequest{Channel: "push", Recipient: "device-token", Content: "Test"}
 
 	// Act
-	count, err := svc.ProcessPendingMessages(context.Background(), 10)
+	result, err := svc.CreateMessage(context.Background(), req)
 
 	// Assert
-	assert.NoError(t, err)
-	assert.Equal(t, 1, count)
-	mockRepo.AssertExpectations(t)
-	mockWebhook.AssertExpectations(t)
-	mockCache.AssertExpectations(t)
-	mockTx.AssertExpectations(t)
+	assert.Nil(t, result)
+	if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeChannelUnavailable, appErr.Code)
+	}
+	mockRepo.AssertNotCalled(t, "Create")
 }
 
-func TestProcessPendingMessages_NoMessages(t *testing.T) {
+func TestCreateMessage_WebhookDestination(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
-	mockCache := new(MockMessageCache)
-
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
-
-	mockTx := new(MockTransaction)
-	mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockTx.On("GetContext").Return(context.Background())
-	mockRepo.On("FindPendingMessages", mock.Anything, 10).
-		Return([]*entity.Message{}, nil)
-	mockTx.On("Rollback").Return(nil)
+	webhooks := new(MockDestinationSenders)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, webhooks, nil, nil, nil, nil, 0, false)
+
+	tenantID := uuid.New()
+	destinationID := uuid.New()
+	unavailableID := uuid.New()
+	ctx := tenant.WithTenantID(context.Background(), tenantID)
+
+	webhooks.On("SenderFor", mock.Anything, destinationID, tenantID, valueobject.ChannelSMS).
+		Return(new(MockSenderProvider), nil)
+	webhooks.On("SenderFor", mock.Anything, unavailableID, tenantID, valueobject.ChannelSMS).
+		Return(nil, apperrors.New(apperrors.ErrorCodeDestinationUnavailable, "webhook destination is not available"))
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.DestinationID() == destinationID
+	})).Return(nil)
 
 	// Act
-	count, err := svc.ProcessPendingMessages(context.Background(), 10)
+	result, err := svc.CreateMessage(ctx, &dto.CreateMessageRequest{
+		PhoneNumber:   "+905551234567",
+		Content:       "Test",
+		DestinationID: destinationID.String(),
+	})
+	_, unavailableErr := svc.CreateMessage(ctx, &dto.CreateMessageRequest{
+		PhoneNumber:   "+905551234567",
+		Content:       "Test",
+		DestinationID: unavailableID.String(),
+	})
+	_, malformedErr := svc.CreateMessage(ctx, &dto.CreateMessageRequest{
+		PhoneNumber:   "+905551234567",
+		Content:       "Test",
+		DestinationID: "acme",
+	})
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, 0, count)
-	mockRepo.AssertExpectations(t)
-	mockTx.AssertExpectations(t)
+	assert.Equal(t, destinationID.String(), result.DestinationID)
+	if appErr, ok := unavailableErr.(*apperrors.AppError); assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeDestinationUnavailable, appErr.Code)
+	}
+	if appErr, ok := malformedErr.(*apperrors.AppError); assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	}
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	webhooks.AssertExpectations(t)
 }
 
-func TestProcessPendingMessages_WebhookFailure(t *testing.T) {
+func TestCreateMessage_IdempotencyKeyReturnsExisting(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
 	phone, _ := valueobject.NewPhoneNumber("+905551234567")
-	content, _ := valueobject.NewMessageContent("Test", 160)
-	message, _ := entity.NewMessage(phone, content, 3)
-
-	mockTx := new(MockTransaction)
-	mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockTx.On("GetContext").Return(context.Background())
-	mockRepo.On("FindPendingMessages", mock.Anything, 10).
-		Return([]*entity.Message{message}, nil)
-	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
-		Return(nil).Times(2) // Once for processing, once for failed
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	existing, _ := entity.NewMessage(phone, content, 3)
+	existing.AssignIdempotencyKey("order-42")
 
-	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test").
-		Return(nil, errors.New("webhook error"))
+	req := &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         "Test message",
+		ClientReference: "order-42",
+	}
 
-	mockTx.On("Commit").Return(nil)
-	mockTx.On("Rollback").Return(nil)
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").Return(existing, nil)
 
 	// Act
-	count, err := svc.ProcessPendingMessages(context.Background(), 10)
+	result, err := svc.CreateMessage(context.Background(), req)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, 0, count) // Failed messages don't count
-	mockRepo.AssertExpectations(t)
-	mockWebhook.AssertExpectations(t)
-	mockTx.AssertExpectations(t)
+	assert.Equal(t, existing.ID().String(), result.ID)
+	assert.Equal(t, "order-42", result.ClientReference)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
-func TestGetSentMessages_Success(t *testing.T) {
+func TestCreateMessage_IdempotencyKeyStoredOnNewMessage(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
-	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	req := &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         "Test message",
+		ClientReference: "order-42",
+	}
+
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").
+		Return(nil, apperrors.NewNotFoundError("message not found"))
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.IdempotencyKey() == "order-42"
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "order-42", result.ClientReference)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_IdempotencyKeyReusedForDifferentMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Original message", 160)
+	existing, _ := entity.NewMessage(phone, content, 3)
+
+	req := &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         "Different message",
+		ClientReference: "order-42",
+	}
+
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").Return(existing, nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "CONFLICT")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_IdempotencyKeyConcurrentInsert(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	winner, _ := entity.NewMessage(phone, content, 3)
+
+	req := &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         "Test message",
+		ClientReference: "order-42",
+	}
+
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").
+		Return(nil, apperrors.NewNotFoundError("message not found")).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(apperrors.New(apperrors.ErrorCodeAlreadyExists, "duplicate record"))
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, "order-42").Return(winner, nil).Once()
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, winner.ID().String(), result.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_AssignsTenantFromContext(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	tenantID := uuid.New()
+	ctx := tenant.WithTenantID(context.Background(), tenantID)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.TenantID() == tenantID
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(ctx, &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, tenantID.String(), result.TenantID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_RecordsCaller(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	tenantID := uuid.New()
+	var callers []string
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Run(func(args mock.Arguments) { callers = append(callers, args.Get(1).(*entity.Message).CreatedBy()) }).
+		Return(nil)
+	req := &dto.CreateMessageRequest{PhoneNumber: "+905551234567", Content: "Test message"}
+
+	// Act
+	token, tokenErr := svc.CreateMessage(actor.WithActor(context.Background(), actor.Token("billing")), req)
+	_, tenantErr := svc.CreateMessage(tenant.WithTenantID(context.Background(), tenantID), req)
+	_, anonymousErr := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, tokenErr)
+	assert.NoError(t, tenantErr)
+	assert.NoError(t, anonymousErr)
+	assert.Equal(t, "jwt:billing", token.CreatedBy)
+	assert.Equal(t, []string{"jwt:billing", actor.Tenant(tenantID), actor.Anonymous}, callers)
+}
+
+func TestGetStatsByCaller(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("GetStatsByCaller", mock.Anything).Return([]repository.CallerStats{
+		{Caller: "api_token", MessageStats: repository.MessageStats{TotalMessages: 5, SentMessages: 4, FailedMessages: 1}},
+		{Caller: "", MessageStats: repository.MessageStats{TotalMessages: 2, PendingMessages: 2}},
+	}, nil)
+
+	// Act
+	result, err := svc.GetStatsByCaller(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &dto.CallerStatsResponse{Callers: []dto.CallerStats{
+		{Caller: "api_token", MessageStatsResponse: dto.MessageStatsResponse{TotalMessages: 5, SentMessages: 4, FailedMessages: 1}},
+		{Caller: "", MessageStatsResponse: dto.MessageStatsResponse{TotalMessages: 2, PendingMessages: 2}},
+	}}, result)
+}
+
+func TestCreateMessage_AssignsPriority(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.Priority() == valueobject.MessagePriorityHigh
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+		Priority:    "high",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "high", result.Priority)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_InvalidPriority(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+		Priority:    "urgent",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_AssignsMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *entity.Message) bool {
+		return msg.Metadata()["campaign"] == "spring-sale"
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+		Metadata:    map[string]string{"campaign": "spring-sale"},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"campaign": "spring-sale"}, result.Metadata)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_InvalidMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+		Metadata:    map[string]string{"order.id": "42"},
+	})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_NormalizesPhoneNumber(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *entity.Message) bool {
+		return m.PhoneNumber().String() == "+905551234567"
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+90 555 123 45 67",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "+905551234567", result.PhoneNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_DestinationBlocked(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	policy := service.NewDestinationPolicy([]string{"TR"}, nil, service.DestinationReject)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, policy, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+12025550123",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "DESTINATION_BLOCKED")
+	assert.Contains(t, err.Error(), "US")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_DestinationQuarantined(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	policy := service.NewDestinationPolicy(nil, []string{"US"}, service.DestinationQuarantine)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, policy, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *entity.Message) bool {
+		return m.Status().IsQuarantined()
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+12025550123",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "quarantined", result.Status)
+	assert.Equal(t, "DESTINATION_BLOCKED", result.ErrorCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_RecipientBlacklisted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockBlacklistRepo := new(MockBlacklistRepository)
+	blacklist := service.NewBlacklistService(mockBlacklistRepo, nil, nil)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, blacklist, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockBlacklistRepo.On("FindTenantsBlocking", mock.Anything, "+905551234567").Return([]uuid.UUID{uuid.Nil}, nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(m *entity.Message) bool {
+		return m.Status().IsBlocked()
+	})).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "blocked", result.Status)
+	assert.Equal(t, "RECIPIENT_BLOCKED", result.ErrorCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_RecipientRateLimited(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockGuard.On("Reserve", mock.Anything, "+905551234567", mock.AnythingOfType("string"), mock.Anything).
+		Return(&cache.RecipientReservation{Decision: cache.RecipientRateLimited}, nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "RECIPIENT_RATE_LIMITED")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_DuplicateContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockGuard.On("Reserve", mock.Anything, "+905551234567", mock.AnythingOfType("string"), mock.Anything).
+		Return(&cache.RecipientReservation{Decision: cache.RecipientDuplicate}, nil)
+
+	// Act
+	_, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DUPLICATE_CONTENT")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_CollapsesDuplicateContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	original, _ := entity.NewMessage(phone, content, 3)
+
+	mockGuard.On("Reserve", mock.Anything, "+905551234567", content.Hash(), mock.Anything).
+		Return(&cache.RecipientReservation{Decision: cache.RecipientCollapsed, DuplicateOf: original.ID()}, nil)
+	mockRepo.On("FindByID", mock.Anything, original.ID()).Return(original, nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, original.ID().String(), result.ID)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_RejectsDuplicateWhenOriginalUnreadable(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	originalID := uuid.New()
+	mockGuard.On("Reserve", mock.Anything, "+905551234567", mock.AnythingOfType("string"), mock.Anything).
+		Return(&cache.RecipientReservation{Decision: cache.RecipientCollapsed, DuplicateOf: originalID}, nil)
+	mockRepo.On("FindByID", mock.Anything, originalID).Return(nil, apperrors.NewNotFoundError("message not found"))
+
+	// Act
+	_, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DUPLICATE_CONTENT")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_RecipientGuardUnavailable(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockGuard.On("Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("redis unavailable"))
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateMessage_ReleasesRecipientOnCreateFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockGuard := new(MockRecipientGuard)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, mockGuard, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	reservation := &cache.RecipientReservation{Decision: cache.RecipientAllowed}
+	mockGuard.On("Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(reservation, nil)
+	mockGuard.On("Release", mock.Anything, reservation).Return(nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(errors.New("database error"))
+
+	// Act
+	_, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Test message",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	mockGuard.AssertExpectations(t)
+}
+
+func TestCreateMessage_InvalidPhone(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	req := &dto.CreateMessageRequest{
+		PhoneNumber: "invalid-phone",
+		Content:     "Test",
+	}
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "phone number")
+}
+
+func TestCreateMessage_EmptyContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	req := &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "",
+	}
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "content")
+}
+
+func TestCreateMessage_ContentTooLong(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Create a string with 161 'a' characters
+	longContent := ""
+	for i := 0; i < 161; i++ {
+		longContent += "a"
+	}
+
+	req := &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     longContent, // 161 characters (exceeds 160 limit)
+	}
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestCreateMessage_SegmentLimit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	charLimit := valueobject.ContentCharLimit(160, 2)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), charLimit, 2, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+
+	// Act
+	gsm, gsmErr := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     strings.Repeat("a", 300),
+	})
+	ucs, ucsErr := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     strings.Repeat("ş", 140),
+	})
+
+	// Assert
+	assert.NoError(t, gsmErr)
+	assert.Equal(t, 2, gsm.Segments)
+	assert.Nil(t, ucs)
+	appErr, ok := ucsErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+		assert.Contains(t, appErr.Message, "2 SMS segments (got 3)")
+	}
+}
+
+func TestCreateMessage_SplitsLongContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 5, false)
+
+	id := uuid.New()
+	req := &dto.CreateMessageRequest{
+		PhoneNumber:     "+905551234567",
+		Content:         strings.Repeat("word ", 60),
+		ClientReference: "order-42",
+	}
+
+	var stored []*entity.Message
+	mockRepo.On("FindByIdempotencyKey", mock.Anything, mock.Anything).
+		Return(nil, apperrors.NewNotFoundError("message not found"))
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]*entity.Message) }).
+		Return(nil)
+
+	// Act
+	result, err := svc.CreateMessageWithID(context.Background(), id, req)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, stored, 2) {
+		assert.Equal(t, id, stored[0].ID())
+		groupID := stored[0].Part().GroupID
+		assert.NotEqual(t, uuid.Nil, groupID)
+		for i, message := range stored {
+			assert.Equal(t, &valueobject.MessagePart{GroupID: groupID, Number: i + 1, Count: 2}, message.Part())
+			assert.Equal(t, fmt.Sprintf("order-42:%d", i+1), message.IdempotencyKey())
+			assert.LessOrEqual(t, message.Content().Length(), 160)
+		}
+		assert.True(t, strings.HasPrefix(stored[0].Content().String(), "(1/2) word"))
+		assert.True(t, strings.HasPrefix(stored[1].Content().String(), "(2/2) word"))
+
+		assert.Equal(t, id.String(), result.ID)
+		assert.Equal(t, groupID.String(), result.SplitGroupID)
+		assert.Equal(t, 1, result.PartNumber)
+		assert.Equal(t, 2, result.PartCount)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_SplitContentTooLong(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 2, false)
+
+	// Act
+	result, err := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     strings.Repeat("a", 400),
+	})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+		assert.Contains(t, appErr.Message, "does not fit in 2 parts")
+	}
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestCreateMessage_ContentModeration(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	filter, _ := service.NewRegexContentFilter(map[string]string{
+		service.ContentRuleCreditCard: service.ContentActionRedact,
+		service.ContentRuleURL:        service.ContentActionReject,
+	}, nil)
+	moderation := &service.ContentModeration{Filter: filter}
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, moderation, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	var stored *entity.Message
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*entity.Message) }).
+		Return(nil)
+
+	// Act
+	redacted, redactErr := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Card 4111 1111 1111 1111 charged",
+	})
+	rejected, rejectErr := svc.CreateMessage(context.Background(), &dto.CreateMessageRequest{
+		PhoneNumber: "+905551234567",
+		Content:     "Claim at https://example.com",
+	})
+
+	// Assert
+	assert.NoError(t, redactErr)
+	assert.Equal(t, "Card [redacted] charged", redacted.Content)
+	assert.Equal(t, valueobject.HashContent("Card [redacted] charged"), stored.ContentHash())
+	assert.Nil(t, rejected)
+	appErr, ok := rejectErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeContentRejected, appErr.Code)
+	}
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestGetMessage_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	messageID := uuid.New()
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByID", mock.Anything, messageID).Return(message, nil)
+
+	// Act
+	result, err := svc.GetMessage(context.Background(), messageID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "+905551234567", result.PhoneNumber)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetMessageByWebhookID_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.MarkAsProcessing()
+	message.MarkAsSent("SM123", `{"sid":"SM123"}`)
+
+	mockRepo.On("FindByWebhookMessageID", mock.Anything, "SM123").Return(message, nil)
+
+	// Act
+	result, err := svc.GetMessageByWebhookID(context.Background(), "SM123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, message.ID().String(), result.ID)
+	assert.Equal(t, "SM123", result.WebhookMessageID)
+	assert.JSONEq(t, `{"sid":"SM123"}`, string(result.ProviderResponse))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetMessage_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	messageID := uuid.New()
+	mockRepo.On("FindByID", mock.Anything, messageID).Return(nil, errors.New("not found"))
+
+	// Act
+	result, err := svc.GetMessage(context.Background(), messageID)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+
+	webhookResp := &provider.SendResult{
+		MessageID: "webhook-123",
+		Message:   "Message sent successfully",
+	}
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(webhookResp, nil)
+
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Attempted)
+	assert.Equal(t, 1, result.Successful)
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_CachesSentMessagesInOneBatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	var messages []*entity.Message
+	for _, number := range []string{"+905551234567", "+905551234568"} {
+		phone, _ := valueobject.NewPhoneNumber(number)
+		message, _ := entity.NewMessage(phone, content, 3)
+		messages = append(messages, message)
+	}
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(messages...), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, mock.Anything, "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123", Message: "Message sent successfully"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.MatchedBy(func(msgs []*cache.CachedMessage) bool {
+		return len(msgs) == 2
+	})).Return(nil).Once()
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Successful)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "CacheSentMessage", mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_DryRunSimulatesSend(t *testing.T) {
+	tests := []struct {
+		name          string
+		globalDryRun  bool
+		messageDryRun bool
+	}{
+		{name: "global dry run", globalDryRun: true},
+		{name: "per-message dry run", messageDryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			mockWebhook := new(MockSenderProvider)
+			mockCache := new(MockMessageCache)
+
+			svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, tt.globalDryRun)
+
+			phone, _ := valueobject.NewPhoneNumber("+905551234567")
+			content, _ := valueobject.NewMessageContent("Test message", 160)
+			message, _ := entity.NewMessage(phone, content, 3)
+			if tt.messageDryRun {
+				message.MarkDryRun()
+			}
+
+			mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+				Return(claimed(message), nil)
+			mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+				Return(nil)
+			mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+				Return(nil)
+			mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+			// Act
+			result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result.Successful)
+			assert.Equal(t, valueobject.MessageStatusSent, message.Status())
+			assert.True(t, message.Simulated())
+			assert.Equal(t, "simulated-"+message.ID().String(), message.WebhookMessageID())
+			mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestProcessPendingMessages_StoresProviderResponseAsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *provider.SendResult
+		expected string
+	}{
+		{
+			name:     "provider body",
+			result:   &provider.SendResult{MessageID: "SM123", Message: "queued", Response: []byte(`{"sid": "SM123", "status": "queued"}`)},
+			expected: `{"sid":"SM123","status":"queued"}`,
+		},
+		{
+			name:     "status with quotes",
+			result:   &provider.SendResult{MessageID: "webhook-123", Message: `Accepted "as is"`},
+			expected: `{"message":"Accepted \"as is\"","messageId":"webhook-123"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			mockWebhook := new(MockSenderProvider)
+			mockCache := new(MockMessageCache)
+
+			svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+			phone, _ := valueobject.NewPhoneNumber("+905551234567")
+			content, _ := valueobject.NewMessageContent("Test message", 160)
+			message, _ := entity.NewMessage(phone, content, 3)
+
+			mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+				Return(claimed(message), nil)
+			mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+				Return(nil)
+			mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+				Return(tt.result, nil)
+			mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+				Return(nil)
+			mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+			// Act
+			_, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, message.WebhookResponse())
+		})
+	}
+}
+
+func TestProcessPendingMessages_SendGuardRecordsResult(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+	mockGuard := new(MockSendGuard)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, mockGuard, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	claim := &cache.SendClaim{State: cache.SendAcquired}
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+	mockGuard.On("Acquire", mock.Anything, message.ID()).Return(claim, nil)
+	mockGuard.On("Complete", mock.Anything, claim, mock.MatchedBy(func(record *cache.SendRecord) bool {
+		return record.WebhookMessageID == "webhook-123"
+	})).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123", Message: "Accepted"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	mockGuard.AssertExpectations(t)
+	mockGuard.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_SendGuardReleasedOnFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockGuard := new(MockSendGuard)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, mockGuard, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	claim := &cache.SendClaim{State: cache.SendAcquired}
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+	mockGuard.On("Acquire", mock.Anything, message.ID()).Return(claim, nil)
+	mockGuard.On("Release", mock.Anything, claim).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(nil, errors.New("webhook error"))
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, message.Status().IsPending())
+	mockGuard.AssertExpectations(t)
+	mockGuard.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_PermanentFailureSkipsRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantPending bool
+	}{
+		{"invalid recipient", apperrors.New(apperrors.ErrorCodeInvalidRecipient, "invalid 'To' number"), false},
+		{"rejected by provider", apperrors.New(apperrors.ErrorCodeProviderRejected, "webhook rejected message"), false},
+		{"timeout", apperrors.New(apperrors.ErrorCodeTimeout, "webhook request timeout"), true},
+		{"unclassified", errors.New("webhook error"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			mockWebhook := new(MockSenderProvider)
+			mockDeadLetters := new(MockDeadLetterRepository)
+			mockCache := new(MockMessageCache)
+			svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, mockDeadLetters, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+			phone, _ := valueobject.NewPhoneNumber("+905551234567")
+			content, _ := valueobject.NewMessageContent("Test message", 160)
+			message, _ := entity.NewMessage(phone, content, 3)
+
+			mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+				Return(claimed(message), nil)
+			mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+				Return(nil)
+			mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+				Return(nil, tt.err)
+			mockDeadLetters.On("Add", mock.Anything, mock.AnythingOfType("*repository.DeadLetter")).
+				Return(nil)
+			mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+			// Act
+			result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result.Failed)
+			assert.Equal(t, 1, message.Attempts())
+			assert.Equal(t, tt.wantPending, message.Status().IsPending())
+			if tt.wantPending {
+				mockDeadLetters.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+				mockCache.AssertNotCalled(t, "InvalidateSentMessages", mock.Anything, mock.Anything)
+			} else {
+				assert.True(t, message.Status().IsFailed())
+				mockDeadLetters.AssertNumberOfCalls(t, "Add", 1)
+				// The failed count in the cached stats changed
+				mockCache.AssertCalled(t, "InvalidateSentMessages", mock.Anything, []string{"all"})
+			}
+		})
+	}
+}
+
+func TestProcessPendingMessages_MapsProviderRefusal(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockMappings := new(MockProviderErrorMappingRepository)
+	mappings := service.NewProviderErrorMappingService(mockMappings, time.Minute)
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, service.NewRetryBackoff(time.Second, time.Minute), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mappings, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockMappings.On("List", mock.Anything).Return([]*repository.ProviderErrorMapping{
+		{Provider: "mock", StatusCode: 400, BodyContains: "balance", ErrorCode: "INSUFFICIENT_BALANCE"},
+	}, nil)
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			provider.RecordResponseStatus(ctx, 400)
+			provider.RecordRefusal(ctx, "", []byte(`{"error":"account balance too low"}`))
+		}).
+		Return(nil, apperrors.New(apperrors.ErrorCodeProviderRejected, "webhook rejected message with status 400"))
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	// Retried, where PROVIDER_REJECTED would have failed it for good
+	assert.True(t, message.Status().IsPending())
+	assert.Equal(t, string(apperrors.ErrorCodeInsufficientBalance), message.ErrorCode())
+}
+
+func TestProcessPendingMessages_SendGuardInFlightSkipsSend(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockGuard := new(MockSendGuard)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, mockGuard, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	mockGuard.On("Acquire", mock.Anything, message.ID()).
+		Return(&cache.SendClaim{State: cache.SendInFlight}, nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, message.Status().IsPending())
+	assert.Zero(t, message.Attempts())
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_SendGuardStoresRecordedSend(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+	mockGuard := new(MockSendGuard)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, mockGuard, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	record := &cache.SendRecord{WebhookMessageID: "webhook-123", Response: `{"message": "Accepted", "messageId": "webhook-123"}`, SentAt: time.Now()}
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	mockGuard.On("Acquire", mock.Anything, message.ID()).
+		Return(&cache.SendClaim{State: cache.SendCompleted, Record: record}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	assert.True(t, message.Status().IsSent())
+	assert.Equal(t, "webhook-123", message.WebhookMessageID())
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_ScopesUpdatesToMessageTenant(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	tenantID := uuid.New()
+	message.AssignTenant(tenantID)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.MatchedBy(func(ctx context.Context) bool {
+		scoped, ok := tenant.FromContext(ctx)
+		return ok && scoped == tenantID
+	}), mock.AnythingOfType("*entity.Message")).Return(nil)
+
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all", tenantID.String()}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_ForwardsCreatingRequestID(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.RecordTrace("req-789", "")
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+
+	mockWebhook.On("SendMessage", mock.MatchedBy(func(ctx context.Context) bool {
+		return requestid.FromContext(ctx) == "req-789"
+	}), "+905551234567", "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	assert.Equal(t, "req-789", message.TraceID())
+	mockWebhook.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_NoMessages(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return([]*entity.Message{}, nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Attempted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_WebhookFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Return(nil, errors.New("webhook error"))
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Successful) // Failed messages don't count
+	assert.Equal(t, 1, result.Failed)
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_WebhookFailureBacksOff(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	backoff := service.NewRetryBackoff(time.Minute, time.Hour)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, backoff, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Return(nil, errors.New("webhook error"))
+
+	// Act
+	before := time.Now().UTC()
+	_, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, message.Status().IsPending())
+	assert.NotNil(t, message.NextRetryAt())
+	assert.True(t, message.NextRetryAt().After(before.Add(29*time.Second)))
+	assert.True(t, message.NextRetryAt().Before(before.Add(61*time.Second)))
+}
+
+func TestProcessPendingMessages_ContentHashMismatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, valueobject.HashContent("Tampered"),
+		valueobject.MessageStatusPending, time.Now().UTC(), nil, nil, nil, nil, nil, 0, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 1,
+	)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "INTEGRITY_ERROR", message.ErrorCode())
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_BlocksBlacklistedRecipient(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockBlacklistRepo := new(MockBlacklistRepository)
+	blacklist := service.NewBlacklistService(mockBlacklistRepo, nil, nil)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, blacklist, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	tenantID := uuid.New()
+	message.AssignTenant(tenantID)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockBlacklistRepo.On("FindTenantsBlocking", mock.Anything, "+905551234567").Return([]uuid.UUID{tenantID}, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, message.Status().IsBlocked())
+	assert.Equal(t, "RECIPIENT_BLOCKED", message.ErrorCode())
+	assert.Zero(t, message.Attempts())
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_ExpiresStaleMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Your code is 123456", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.ExpireAt(time.Now().Add(-time.Second))
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, message.Status().IsExpired())
+	assert.Equal(t, "MESSAGE_EXPIRED", message.ErrorCode())
+	assert.Zero(t, message.Attempts())
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_ExpiresInsteadOfLateRetry(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	backoff := service.NewRetryBackoff(time.Minute, time.Hour)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, backoff, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.ExpireAt(time.Now().Add(10 * time.Second))
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Return(nil, errors.New("webhook error"))
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	_, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, message.Status().IsExpired())
+	assert.Nil(t, message.NextRetryAt())
+	assert.Contains(t, message.LastError(), "webhook error")
+}
+
+func TestProcessPendingMessages_RoutesByChannel(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	smsSender := new(MockSenderProvider)
+	pushSender := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+	channels := provider.ChannelProviders{valueobject.ChannelPush: pushSender}
+
+	svc := service.NewMessageService(mockRepo, smsSender, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, channels, nil, nil, nil, nil, nil, nil, 0, false)
+
+	recipient, _ := valueobject.NewRecipient(valueobject.ChannelPush, "device-token")
+	content, _ := valueobject.NewMessageContent("You have a new message", 1024)
+	message, _ := entity.NewMessageTo(uuid.New(), recipient, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	pushSender.On("SendMessage", mock.Anything, "device-token", "You have a new message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "push-1", Message: "Accepted"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	assert.True(t, message.Status().IsSent())
+	pushSender.AssertExpectations(t)
+	smsSender.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_RoutesToWebhookDestination(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	smsSender := new(MockSenderProvider)
+	destinationSender := new(MockSenderProvider)
+	webhooks := new(MockDestinationSenders)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, smsSender, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, webhooks, nil, nil, nil, nil, 0, false)
+
+	tenantID := uuid.New()
+	destinationID := uuid.New()
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.AssignTenant(tenantID)
+	message.AssignDestination(destinationID)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+	webhooks.On("SenderFor", mock.Anything, destinationID, tenantID, valueobject.ChannelSMS).
+		Return(destinationSender, nil)
+	destinationSender.On("SendMessage", mock.Anything, "+905551234567", "Test message", mock.Anything).
+		Return(&provider.SendResult{MessageID: "acme-1", Message: "Accepted"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).
+		Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	assert.Equal(t, "acme-1", message.WebhookMessageID())
+	destinationSender.AssertExpectations(t)
+	smsSender.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessPendingMessages_ContentModerationOnSend(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	filter, _ := service.NewRegexContentFilter(map[string]string{
+		service.ContentRuleCreditCard: service.ContentActionRedact,
+		service.ContentRuleURL:        service.ContentActionReject,
+	}, nil)
+	moderation := &service.ContentModeration{Filter: filter, OnSend: true}
+	mockCache := new(MockMessageCache)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, moderation, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	cardContent, _ := valueobject.NewMessageContent("Card 4111 1111 1111 1111 charged", 160)
+	card, _ := entity.NewMessage(phone, cardContent, 3)
+	linkContent, _ := valueobject.NewMessageContent("Claim at https://example.com", 160)
+	link, _ := entity.NewMessage(phone, linkContent, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(card, link), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Card [redacted] charged", mock.Anything).
+		Return(&provider.SendResult{MessageID: "webhook-123"}, nil)
+	mockCache.On("CacheSentMessages", mock.Anything, mock.AnythingOfType("[]*cache.CachedMessage")).Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Successful)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, card.Status().IsSent())
+	assert.Equal(t, "Card 4111 1111 1111 1111 charged", card.Content().String())
+	assert.True(t, link.Status().IsQuarantined())
+	assert.Equal(t, "CONTENT_REJECTED", link.ErrorCode())
+	assert.Zero(t, link.Attempts())
+	mockWebhook.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestProcessPendingMessages_BlacklistLookupFailureUnclaims(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockBlacklistRepo := new(MockBlacklistRepository)
+	blacklist := service.NewBlacklistService(mockBlacklistRepo, nil, nil)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, blacklist, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockBlacklistRepo.On("FindTenantsBlocking", mock.Anything, "+905551234567").
+		Return(nil, apperrors.NewDatabaseError(errors.New("connection refused")))
+	mockRepo.On("Update", mock.Anything, message).Return(nil).Once()
+
+	// Act
+	result, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.True(t, message.Status().IsPending())
+	assert.Zero(t, message.Attempts())
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReleaseClaimedMessages_ReturnsThemToPending(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	first, _ := entity.NewMessage(phone, content, 3)
+	second, _ := entity.NewMessage(phone, content, 3)
+	messages := claimed(first, second)
+
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).Return(nil).Twice()
+
+	// Act
+	svc.ReleaseClaimedMessages(context.Background(), messages)
+
+	// Assert
+	for _, message := range messages {
+		assert.True(t, message.Status().IsPending())
+		assert.Zero(t, message.Attempts())
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSentMessages_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message1, _ := entity.NewMessage(phone, content, 3)
+	message2, _ := entity.NewMessage(phone, content, 3)
+
+	stats := &repository.MessageStats{
+		TotalMessages:   10,
+		SentMessages:    2,
+		FailedMessages:  3,
+		PendingMessages: 5,
+	}
+
+	mockRepo.On("FindSentMessages", mock.Anything, 20, 0).
+		Return([]*entity.Message{message1, message2}, nil)
+	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+	mockRepo.On("GetSendPerformance", mock.Anything, mock.AnythingOfType("time.Time")).Return(&repository.SendPerformance{}, nil)
+	mockCache.On("GetOrLoadSentPage", mock.Anything, "all", 1, 20).Return(nil, nil)
+	mockCache.On("GetOrLoadStats", mock.Anything, "all").Return(nil, nil)
+
+	// Act (page=1, pageSize=20)
+	result, err := svc.GetSentMessages(context.Background(), 1, 20)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Len(t, result.Messages, 2)
+	assert.Equal(t, "+905551234567", result.Messages[0].PhoneNumber)
+	assert.Equal(t, 2, result.TotalCount)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 20, result.PageSize)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSentMessages_EmptyResult(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	stats := &repository.MessageStats{
+		TotalMessages:   0,
+		SentMessages:    0,
+		FailedMessages:  0,
+		PendingMessages: 0,
+	}
+
+	mockRepo.On("FindSentMessages", mock.Anything, 20, 0).
+		Return([]*entity.Message{}, nil)
+	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+	mockRepo.On("GetSendPerformance", mock.Anything, mock.AnythingOfType("time.Time")).Return(&repository.SendPerformance{}, nil)
+	mockCache.On("GetOrLoadSentPage", mock.Anything, "all", 1, 20).Return(nil, nil)
+	mockCache.On("GetOrLoadStats", mock.Anything, "all").Return(nil, nil)
+
+	// Act
+	result, err := svc.GetSentMessages(context.Background(), 1, 20)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result.Messages)
+	assert.Equal(t, 0, result.TotalCount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetSentMessages_ServedFromCache(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	tenantID := uuid.New()
+	ctx := tenant.WithTenantID(context.Background(), tenantID)
+	cached := []byte(`{"messages":[{"id":"9b2f8a0e-3c47-4f55-8a56-0c1f1f6b9f10","phone_number":"+905551234567","status":"sent"}],"total_count":7,"page":2,"page_size":1}`)
+	mockCache.On("GetOrLoadSentPage", mock.Anything, tenantID.String(), 2, 1).Return(cached, nil)
+
+	// Act
+	result, err := svc.GetSentMessages(ctx, 2, 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Messages, 1)
+	assert.Equal(t, "+905551234567", result.Messages[0].PhoneNumber)
+	assert.Equal(t, 7, result.TotalCount)
+	mockRepo.AssertNotCalled(t, "FindSentMessages", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetStats", mock.Anything)
+}
+
+func TestGetSentMessages_TotalCountFromCachedStats(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindSentMessages", mock.Anything, 20, 20).
+		Return([]*entity.Message{message}, nil)
+	mockCache.On("GetOrLoadSentPage", mock.Anything, "all", 2, 20).Return(nil, nil)
+	mockCache.On("GetOrLoadStats", mock.Anything, "all").Return([]byte(`{"total_messages":50,"sent_messages":21}`), nil)
+
+	// Act
+	result, err := svc.GetSentMessages(context.Background(), 2, 20)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Messages, 1)
+	assert.Equal(t, 21, result.TotalCount)
+	mockRepo.AssertNotCalled(t, "GetStats", mock.Anything)
+}
+
+func TestListMessages_AppliesFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	after := time.Now().Add(-24 * time.Hour).UTC()
+	expected := repository.MessageFilter{
+		Status:       "failed",
+		PhoneNumber:  "+905551234567",
+		ErrorCode:    "TIMEOUT",
+		CreatedAfter: &after,
+	}
+	mockRepo.On("FindByFilter", mock.Anything, expected, 10, 10).
+		Return([]*entity.Message{message}, int64(11), nil)
+
+	// Act
+	result, err := svc.ListMessages(context.Background(), &dto.ListMessagesRequest{
+		Status:       "failed",
+		PhoneNumber:  "+905551234567",
+		ErrorCode:    "TIMEOUT",
+		CreatedAfter: &after,
+		Page:         2,
+		PageSize:     10,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Messages, 1)
+	assert.Equal(t, 11, result.TotalCount)
+	assert.Equal(t, 2, result.Page)
+	assert.Equal(t, 10, result.PageSize)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchMessages_HighlightsMatches(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Your Order <b>4821</b> has   shipped, order again!", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("SearchContent", mock.Anything, mock.MatchedBy(func(q *valueobject.SearchQuery) bool {
+		return q.String() == `order "has shipped" -refund`
+	}), 20, 20).Return([]*entity.Message{message}, int64(21), nil)
+
+	// Act
+	result, err := svc.SearchMessages(context.Background(), &dto.SearchMessagesRequest{
+		Q:    `order "has shipped" -refund`,
+		Page: 2,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 21, result.TotalCount)
+	assert.Equal(t, 2, result.Page)
+	if assert.Len(t, result.Results, 1) {
+		assert.Equal(t, message.ID().String(), result.Results[0].ID)
+		assert.Equal(t, "Your <mark>Order</mark> &lt;b&gt;4821&lt;/b&gt; <mark>has   shipped</mark>, <mark>order</mark> again!", result.Results[0].Highlight)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchMessages_InvalidQuery(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.SearchMessages(context.Background(), &dto.SearchMessagesRequest{Q: "-refund"})
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+	}
+	mockRepo.AssertNotCalled(t, "SearchContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListMessages_InvalidStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	// Act
+	result, err := svc.ListMessages(context.Background(), &dto.ListMessagesRequest{Status: "bounced"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "FindByFilter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetStats_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	stats := &repository.MessageStats{
+		TotalMessages:   100,
+		SentMessages:    80,
+		FailedMessages:  15,
+		PendingMessages: 5,
+	}
+
+	performance := &repository.SendPerformance{
+		SentMessages:  80,
+		AvgAttempts:   1.25,
+		AvgTimeToSend: 1500 * time.Millisecond,
+		P95TimeToSend: 4 * time.Second,
+		RecentSent:    9,
+		RecentFailed:  3,
+	}
+
+	var since time.Time
+	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+	mockRepo.On("GetSendPerformance", mock.Anything, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { since = args.Get(1).(time.Time) }).
+		Return(performance, nil)
+	mockCache.On("GetOrLoadStats", mock.Anything, "all").Return(nil, nil)
+
+	// Act
+	result, err := svc.GetStats(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int64(100), result.TotalMessages)
+	assert.Equal(t, int64(80), result.SentMessages)
+	assert.Equal(t, int64(15), result.FailedMessages)
+	assert.Equal(t, int64(5), result.PendingMessages)
+	assert.Equal(t, &dto.SendPerformanceResponse{
+		AvgAttemptsPerSent:  1.25,
+		AvgTimeToSendMs:     1500,
+		P95TimeToSendMs:     4000,
+		FailureRateLastHour: 0.25,
+	}, result.Performance)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Minute)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetStats_Error(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	mockRepo.On("GetStats", mock.Anything).Return(nil, errors.New("database error"))
+	mockCache.On("GetOrLoadStats", mock.Anything, "all").Return(nil, nil)
+
+	// Act
+	result, err := svc.GetStats(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "database error")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetStatsTimeSeries_FillsEmptyBuckets(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	day := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := day.Add(90 * time.Minute)
+	to := day.Add(4*time.Hour + 30*time.Minute)
+
+	mockRepo.On("GetStatsTimeSeries", mock.Anything, repository.StatsBucketHour, day.Add(time.Hour), day.Add(5*time.Hour)).
+		Return(&repository.MessageTimeSeries{
+			Buckets: []repository.MessageStatsBucket{
+				{Start: day.Add(2 * time.Hour), Created: 5, Sent: 4, Failed: 1},
+			},
+			ErrorCodes: []repository.ErrorCodeCount{{ErrorCode: "TIMEOUT", Count: 1}},
+		}, nil)
+
+	// Act
+	result, err := svc.GetStatsTimeSeries(context.Background(), &dto.StatsTimeSeriesRequest{From: &from, To: &to})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "hour", result.Bucket)
+	assert.Equal(t, day.Add(time.Hour), result.From)
+	assert.Equal(t, day.Add(5*time.Hour), result.To)
+	if assert.Len(t, result.Buckets, 4) {
+		assert.Equal(t, day.Add(time.Hour), result.Buckets[0].Start)
+		assert.Zero(t, result.Buckets[0].Created)
+		assert.Equal(t, dto.StatsBucketResponse{Start: day.Add(2 * time.Hour), Created: 5, Sent: 4, Failed: 1}, result.Buckets[1])
+		assert.Equal(t, day.Add(4*time.Hour), result.Buckets[3].Start)
+	}
+	assert.Equal(t, []dto.ErrorCodeCountResponse{{ErrorCode: "TIMEOUT", Count: 1}}, result.ErrorCodes)
+}
+
+func TestGetStatsTimeSeries_InvalidRange(t *testing.T) {
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := from.Add(-time.Hour)
+	tooLong := from.Add(1001 * time.Hour)
+
+	tests := []struct {
+		name string
+		req  *dto.StatsTimeSeriesRequest
+	}{
+		{name: "unknown bucket", req: &dto.StatsTimeSeriesRequest{Bucket: "week"}},
+		{name: "from after to", req: &dto.StatsTimeSeriesRequest{From: &from, To: &before}},
+		{name: "too many buckets", req: &dto.StatsTimeSeriesRequest{From: &from, To: &tooLong}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+			// Act
+			result, err := svc.GetStatsTimeSeries(context.Background(), tt.req)
+
+			// Assert
+			assert.Nil(t, result)
+			if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+				assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+			}
+			mockRepo.AssertNotCalled(t, "GetStatsTimeSeries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetDuplicateContent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	to := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	first := to.Add(-3 * time.Hour)
+	mockRepo.On("FindDuplicateContent", mock.Anything, to.Add(-24*time.Hour), to, 2, 50).
+		Return([]repository.DuplicateContent{{
+			Channel:        "sms",
+			Recipient:      "+905551234567",
+			ContentHash:    "abc",
+			Content:        "Your order has shipped",
+			Count:          3,
+			FirstCreatedAt: first,
+			LastCreatedAt:  first.Add(time.Minute),
+		}}, nil)
+
+	// Act
+	result, err := svc.GetDuplicateContent(context.Background(), &dto.DuplicateContentRequest{To: &to})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, to.Add(-24*time.Hour), result.From)
+	assert.Equal(t, 2, result.MinCount)
+	if assert.Len(t, result.Duplicates, 1) {
+		assert.Equal(t, "+905551234567", result.Duplicates[0].Recipient)
+		assert.Equal(t, int64(3), result.Duplicates[0].Count)
+		assert.Equal(t, first, result.Duplicates[0].FirstCreatedAt)
+	}
+}
+
+func TestGetDuplicateContent_InvalidRequest(t *testing.T) {
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := from.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  *dto.DuplicateContentRequest
+	}{
+		{name: "from after to", req: &dto.DuplicateContentRequest{From: &from, To: &before}},
+		{name: "min count below 2", req: &dto.DuplicateContentRequest{MinCount: 1}},
+		{name: "limit too high", req: &dto.DuplicateContentRequest{Limit: 501}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+			// Act
+			result, err := svc.GetDuplicateContent(context.Background(), tt.req)
+
+			// Assert
+			assert.Nil(t, result)
+			if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+				assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+			}
+			mockRepo.AssertNotCalled(t, "FindDuplicateContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCancelMessage_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+
+	// Act
+	result, err := svc.CancelMessage(context.Background(), message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelMessage_AlreadySent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.MarkAsSent("webhook-123", "{}")
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+
+	// Act
+	result, err := svc.CancelMessage(context.Background(), message.ID())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "CONFLICT")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCancelMessage_ClaimedConcurrently(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	pending, _ := entity.NewMessage(phone, content, 3)
+	claimed := entity.ReconstructMessage(
+		pending.ID(), valueobject.NewPhoneRecipient(phone), content, pending.ContentHash(),
+		valueobject.MessageStatusProcessing, pending.CreatedAt(), nil, nil, nil, nil, nil, 1, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 2,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, pending.ID()).Return(pending, nil).Once()
+	mockRepo.On("Update", mock.Anything, pending).
+		Return(apperrors.NewVersionConflictError("message was modified concurrently"))
+	mockRepo.On("FindByID", mock.Anything, pending.ID()).Return(claimed, nil).Once()
+
+	// Act
+	result, err := svc.CancelMessage(context.Background(), pending.ID())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "processing")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPauseAndResumeMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
 	content, _ := valueobject.NewMessageContent("Test", 160)
-	message1, _ := entity.NewMessage(phone, content, 3)
-	message2, _ := entity.NewMessage(phone, content, 3)
+	message, _ := entity.NewMessage(phone, content, 3)
 
-	stats := &repository.MessageStats{
-		TotalMessages:   10,
-		SentMessages:    2,
-		FailedMessages:  3,
-		PendingMessages: 5,
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil).Twice()
+
+	// Act
+	paused, pauseErr := svc.PauseMessage(context.Background(), message.ID())
+	_, pauseAgainErr := svc.PauseMessage(context.Background(), message.ID())
+	resumed, resumeErr := svc.ResumeMessage(context.Background(), message.ID())
+	_, resumeAgainErr := svc.ResumeMessage(context.Background(), message.ID())
+
+	// Assert
+	assert.NoError(t, pauseErr)
+	assert.Equal(t, "paused", paused.Status)
+	assert.NoError(t, resumeErr)
+	assert.Equal(t, "pending", resumed.Status)
+	for _, err := range []error{pauseAgainErr, resumeAgainErr} {
+		appErr, ok := err.(*apperrors.AppError)
+		if assert.True(t, ok) {
+			assert.Equal(t, apperrors.ErrorCodeConflict, appErr.Code)
+		}
 	}
+	mockRepo.AssertExpectations(t)
+}
 
-	mockRepo.On("FindSentMessages", mock.Anything, 20, 0).
-		Return([]*entity.Message{message1, message2}, nil)
-	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+func TestCancelMessage_RetriesAfterVersionConflict(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
 
-	// Act (page=1, pageSize=20)
-	result, err := svc.GetSentMessages(context.Background(), 1, 20)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	stale, _ := entity.NewMessage(phone, content, 3)
+	// Another writer deferred the message in between, bumping its version
+	fresh := entity.ReconstructMessage(
+		stale.ID(), valueobject.NewPhoneRecipient(phone), content, stale.ContentHash(),
+		valueobject.MessageStatusPending, stale.CreatedAt(), nil, nil, nil, nil, nil, 1, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 2,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, stale.ID()).Return(stale, nil).Once()
+	mockRepo.On("Update", mock.Anything, stale).
+		Return(apperrors.NewVersionConflictError("message was modified concurrently")).Once()
+	mockRepo.On("FindByID", mock.Anything, stale.ID()).Return(fresh, nil).Once()
+	mockRepo.On("Update", mock.Anything, fresh).Return(nil).Once()
+
+	// Act
+	result, err := svc.CancelMessage(context.Background(), stale.ID())
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Len(t, result.Messages, 2)
-	assert.Equal(t, "+905551234567", result.Messages[0].PhoneNumber)
-	assert.Equal(t, 2, result.TotalCount)
-	assert.Equal(t, 1, result.Page)
-	assert.Equal(t, 20, result.PageSize)
+	assert.Equal(t, "cancelled", result.Status)
+	assert.Equal(t, 1, result.Attempts)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetSentMessages_EmptyResult(t *testing.T) {
+func TestRetryMessage_GivesUpAfterRepeatedConflicts(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
-	stats := &repository.MessageStats{
-		TotalMessages:   0,
-		SentMessages:    0,
-		FailedMessages:  0,
-		PendingMessages: 0,
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	failed := func() *entity.Message {
+		return entity.ReconstructMessage(
+			message.ID(), valueobject.NewPhoneRecipient(phone), content, message.ContentHash(),
+			valueobject.MessageStatusFailed, message.CreatedAt(), nil, nil, nil, nil, nil, 3, 3, "timeout", "TIMEOUT", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 4,
+		)
 	}
 
-	mockRepo.On("FindSentMessages", mock.Anything, 20, 0).
-		Return([]*entity.Message{}, nil)
-	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(failed(), nil).Once()
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(failed(), nil).Once()
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(failed(), nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).
+		Return(apperrors.NewVersionConflictError("message was modified concurrently"))
 
 	// Act
-	result, err := svc.GetSentMessages(context.Background(), 1, 20)
+	result, err := svc.RetryMessage(context.Background(), message.ID(), false)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, apperrors.IsVersionConflict(err))
+	mockRepo.AssertNumberOfCalls(t, "Update", 3)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRetryMessage_ReleasesQuarantined(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+12025550123")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusQuarantined, time.Now().UTC(), nil, nil, nil, nil, nil, 0, 3, "sending to country US is not allowed", "DESTINATION_BLOCKED", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 1,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+
+	// Act
+	result, err := svc.RetryMessage(context.Background(), message.ID(), false)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Empty(t, result.Messages)
-	assert.Equal(t, 0, result.TotalCount)
+	assert.Equal(t, "pending", result.Status)
+	assert.Empty(t, result.ErrorCode)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetStats_Success(t *testing.T) {
+func TestRetryMessage_ResetsAttempts(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
-	stats := &repository.MessageStats{
-		TotalMessages:   100,
-		SentMessages:    80,
-		FailedMessages:  15,
-		PendingMessages: 5,
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusFailed, time.Now().UTC(), nil, nil, nil, nil, nil, 3, 3, "timeout", "TIMEOUT", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 4,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+
+	// Act
+	result, err := svc.RetryMessage(context.Background(), message.ID(), true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	assert.Equal(t, 0, result.Attempts)
+	assert.Empty(t, result.LastError)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProcessPendingMessages_DeadLettersExhaustedMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+	mockDeadLetters := new(MockDeadLetterRepository)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, mockDeadLetters, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusPending, time.Now().UTC(), nil, nil, nil, nil, nil, 2, 3, "timeout", "TIMEOUT", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 3,
+	)
+
+	mockRepo.On("ClaimPendingMessages", mock.Anything, 10).
+		Return(claimed(message), nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil).Once()
+	mockWebhook.On("SendMessage", mock.Anything, "+905551234567", "Test", mock.Anything).
+		Return(nil, errors.New("webhook error"))
+	mockDeadLetters.On("Add", mock.Anything, mock.MatchedBy(func(entry *repository.DeadLetter) bool {
+		return entry.MessageID == message.ID() && entry.Attempts == 3 && entry.LastError != ""
+	})).Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	_, err := svc.ProcessPendingMessages(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, message.Status().IsFailed())
+	mockDeadLetters.AssertExpectations(t)
+}
+
+func TestReapStaleMessages_RequeuesAndFails(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockDeadLetters := new(MockDeadLetterRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, mockDeadLetters, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	claimedAt := time.Now().UTC().Add(-time.Hour)
+	retryable := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusProcessing, time.Now().UTC(), nil, nil, nil, nil, &claimedAt, 1, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 2,
+	)
+	exhausted := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusProcessing, time.Now().UTC(), nil, nil, nil, nil, &claimedAt, 3, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 4,
+	)
+	startedBefore := time.Now().UTC().Add(-10 * time.Minute)
+
+	mockRepo.On("FindStaleProcessing", mock.Anything, startedBefore, 50).
+		Return([]*entity.Message{retryable, exhausted}, nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockDeadLetters.On("Add", mock.Anything, mock.MatchedBy(func(entry *repository.DeadLetter) bool {
+		return entry.MessageID == exhausted.ID()
+	})).Return(nil)
+
+	// Act
+	result, err := svc.ReapStaleMessages(context.Background(), startedBefore, 50)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &service.ReapResult{Found: 2, Requeued: 1, Failed: 1}, result)
+	assert.True(t, retryable.Status().IsPending())
+	assert.Equal(t, "STALE_CLAIM", retryable.ErrorCode())
+	assert.True(t, exhausted.Status().IsFailed())
+	mockRepo.AssertExpectations(t)
+	mockDeadLetters.AssertExpectations(t)
+}
+
+func TestReapStaleMessages_SkipsConcurrentlyFinishedMessage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	claimedAt := time.Now().UTC().Add(-time.Hour)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusProcessing, time.Now().UTC(), nil, nil, nil, nil, &claimedAt, 1, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 2,
+	)
+
+	mockRepo.On("FindStaleProcessing", mock.Anything, mock.Anything, 50).
+		Return([]*entity.Message{message}, nil)
+	mockRepo.On("Update", mock.Anything, message).
+		Return(apperrors.NewVersionConflictError("message was modified concurrently"))
+
+	// Act
+	result, err := svc.ReapStaleMessages(context.Background(), time.Now().UTC(), 50)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &service.ReapResult{Found: 1}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequeueDeadLetter_RemovesEntry(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockDeadLetters := new(MockDeadLetterRepository)
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, mockDeadLetters, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message := entity.ReconstructMessage(
+		uuid.New(), valueobject.NewPhoneRecipient(phone), content, content.Hash(),
+		valueobject.MessageStatusFailed, time.Now().UTC(), nil, nil, nil, nil, nil, 3, 3, "timeout", "TIMEOUT", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 4,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+	mockDeadLetters.On("Remove", mock.Anything, message.ID()).Return(nil)
+
+	// Act
+	result, err := svc.RequeueDeadLetter(context.Background(), message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	assert.Equal(t, 0, result.Attempts)
+	mockDeadLetters.AssertExpectations(t)
+}
+
+func TestRetryMessage_NotFailed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByID", mock.Anything, message.ID()).Return(message, nil)
+
+	// Act
+	result, err := svc.RetryMessage(context.Background(), message.ID(), false)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "CONFLICT")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRetryFailedMessages_AppliesFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	mockCache := new(MockMessageCache)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &dto.RetryFailedRequest{
+		ErrorCode:     "TIMEOUT",
+		CreatedAfter:  &after,
+		ResetAttempts: true,
 	}
 
-	mockRepo.On("GetStats", mock.Anything).Return(stats, nil)
+	mockRepo.On("RequeueFailed", mock.Anything, repository.FailedMessageFilter{
+		ErrorCode:    "TIMEOUT",
+		CreatedAfter: &after,
+	}, true).Return(int64(7), nil)
 
 	// Act
-	result, err := svc.GetStats(context.Background())
+	result, err := svc.RetryFailedMessages(context.Background(), req)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, int64(100), result.TotalMessages)
-	assert.Equal(t, int64(80), result.SentMessages)
-	assert.Equal(t, int64(15), result.FailedMessages)
-	assert.Equal(t, int64(5), result.PendingMessages)
+	assert.Equal(t, int64(7), result.Requeued)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetStats_Error(t *testing.T) {
+func TestRetryFailedMessages_InvalidRange(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockMessageRepository)
-	mockWebhook := new(MockWebhookClient)
+	mockWebhook := new(MockSenderProvider)
 	mockCache := new(MockMessageCache)
 
-	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, 160, 3)
+	svc := service.NewMessageService(mockRepo, mockWebhook, mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
 
-	mockRepo.On("GetStats", mock.Anything).Return(nil, errors.New("database error"))
+	after := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// Act
-	result, err := svc.GetStats(context.Background())
+	result, err := svc.RetryFailedMessages(context.Background(), &dto.RetryFailedRequest{
+		CreatedAfter:  &after,
+		CreatedBefore: &before,
+	})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "database error")
+	mockRepo.AssertNotCalled(t, "RequeueFailed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWaitForMessage_ReturnsOnTerminalEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	bus := eventbus.NewInMemoryBus()
+
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), bus, 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	pending, _ := entity.NewMessage(phone, content, 3)
+	sent := entity.ReconstructMessage(
+		pending.ID(), valueobject.NewPhoneRecipient(phone), content, pending.ContentHash(),
+		valueobject.MessageStatusSent, pending.CreatedAt(), nil, nil, nil, nil, nil, 1, 3, "", "", "webhook-123", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 2,
+	)
+
+	mockRepo.On("FindByID", mock.Anything, pending.ID()).Return(pending, nil).Once()
+	mockRepo.On("FindByID", mock.Anything, pending.ID()).Return(sent, nil)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		bus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, uuid.New(), valueobject.MessageStatusSent))
+		bus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, pending.ID(), valueobject.MessageStatusSent))
+	}()
+
+	// Act
+	start := time.Now()
+	result, err := svc.WaitForMessage(context.Background(), pending.ID(), 5*time.Second)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "sent", result.Status)
+	assert.Less(t, time.Since(start), time.Second)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWaitForMessage_TimesOut(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	pending, _ := entity.NewMessage(phone, content, 3)
+
+	mockRepo.On("FindByID", mock.Anything, pending.ID()).Return(pending, nil)
+
+	// Act
+	result, err := svc.WaitForMessage(context.Background(), pending.ID(), 100*time.Millisecond)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+}
+
+func TestApplyDeliveryReceipt_Delivered(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockCache := new(MockMessageCache)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.MarkAsProcessing()
+	message.MarkAsSent("webhook-123", "{}")
+
+	deliveredAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	mockRepo.On("FindByWebhookMessageID", mock.Anything, "webhook-123").Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil).Once()
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil).Once()
+
+	req := &dto.DeliveryReceiptRequest{WebhookMessageID: "webhook-123", Status: "delivered", DeliveredAt: &deliveredAt}
+
+	// Act
+	result, err := svc.ApplyDeliveryReceipt(context.Background(), req)
+	_, repeatErr := svc.ApplyDeliveryReceipt(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, repeatErr)
+	assert.Equal(t, "delivered", result.Status)
+	assert.Equal(t, deliveredAt, *result.DeliveredAt)
 	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestApplyDeliveryReceipt_RejectedDefaultsErrorCode(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockCache := new(MockMessageCache)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), mockCache, eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.MarkAsProcessing()
+	message.MarkAsSent("webhook-123", "{}")
+
+	mockRepo.On("FindByWebhookMessageID", mock.Anything, "webhook-123").Return(message, nil)
+	mockRepo.On("Update", mock.Anything, message).Return(nil)
+	mockCache.On("InvalidateSentMessages", mock.Anything, []string{"all"}).Return(nil)
+
+	// Act
+	result, err := svc.ApplyDeliveryReceipt(context.Background(), &dto.DeliveryReceiptRequest{
+		WebhookMessageID: "webhook-123",
+		Status:           "rejected",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "undelivered", result.Status)
+	assert.Equal(t, "REJECTED", result.ErrorCode)
+	assert.Nil(t, result.DeliveredAt)
+}
+
+func TestApplyDeliveryReceipt_ConflictingReceipt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, false)
+
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := entity.NewMessage(phone, content, 3)
+	message.MarkAsProcessing()
+	message.MarkAsSent("webhook-123", "{}")
+	_ = message.MarkAsDelivered(time.Now())
+
+	mockRepo.On("FindByWebhookMessageID", mock.Anything, "webhook-123").Return(message, nil)
+
+	// Act
+	result, err := svc.ApplyDeliveryReceipt(context.Background(), &dto.DeliveryReceiptRequest{
+		WebhookMessageID: "webhook-123",
+		Status:           "undelivered",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "CONFLICT")
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 }
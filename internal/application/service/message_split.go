@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// splitContent returns the parts SMS content over the char limit is split
+// into, or nil when the message is created whole. The content is screened
+// before it is split, so a filtered word cannot straddle two parts.
+func (s *messageService) splitContent(ctx context.Context, req *dto.CreateMessageRequest) ([]string, error) {
+	if s.splitMaxParts < 2 {
+		return nil, nil
+	}
+	// An invalid channel is reported by prepareMessage
+	channel, err := valueobject.NewChannel(req.Channel)
+	if err != nil || channel != valueobject.ChannelSMS {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(req.Content) <= s.charLimit {
+		return nil, nil
+	}
+
+	text, err := s.moderation.screen(ctx, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	if utf8.RuneCountInString(text) <= s.charLimit {
+		return nil, nil
+	}
+
+	parts, err := valueobject.SplitContent(text, s.charLimit, s.splitMaxParts)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	return parts, nil
+}
+
+// createParts stores parts as linked messages sharing a split group, all of
+// them or none, and returns the first one. The first part takes id. With a
+// client_reference, part n is stored under "<client_reference>:<n>", so a
+// repeated request returns the first part again.
+func (s *messageService) createParts(ctx context.Context, id uuid.UUID, req *dto.CreateMessageRequest, parts []string) (*dto.MessageResponse, error) {
+	groupID := uuid.New()
+	drafts := make([]*messageDraft, 0, len(parts))
+	release := func() {
+		for _, draft := range drafts {
+			s.releaseRecipient(ctx, draft.reservation)
+		}
+	}
+
+	messages := make([]*entity.Message, len(parts))
+	for i, content := range parts {
+		partReq := *req
+		partReq.Content = content
+		partReq.ClientReference = partReference(req.ClientReference, i+1)
+		partID := id
+		if i > 0 {
+			partID = uuid.New()
+		}
+
+		draft, err := s.prepareMessage(ctx, partID, &partReq)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if draft.existing != nil {
+			release()
+			return draft.existing, nil
+		}
+		drafts = append(drafts, draft)
+
+		draft.message.AssignPart(&valueobject.MessagePart{GroupID: groupID, Number: i + 1, Count: len(parts)})
+		messages[i] = draft.message
+	}
+
+	if err := s.repo.CreateBatch(ctx, messages); err != nil {
+		release()
+
+		// A concurrent request with the same key won the insert
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists && req.ClientReference != "" {
+			first := messages[0]
+			existing, findErr := s.findByIdempotencyKey(ctx, partReference(req.ClientReference, 1), first.Recipient(), first.Content())
+			if findErr != nil {
+				return nil, findErr
+			}
+			if existing != nil {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+
+	for _, message := range messages {
+		s.messageCreated(ctx, message)
+	}
+
+	return s.toDTO(messages[0]), nil
+}
+
+// partReference is the client_reference of part number of a split message,
+// empty when the request has none.
+func partReference(clientReference string, number int) string {
+	if clientReference == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", clientReference, number)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxMappingDescriptionLength bounds the free-text description of a mapping
+const maxMappingDescriptionLength = 500
+
+type ProviderErrorMappingService interface {
+	CreateMapping(ctx context.Context, req *dto.CreateProviderErrorMappingRequest) (*dto.ProviderErrorMappingResponse, error)
+	GetMapping(ctx context.Context, id uuid.UUID) (*dto.ProviderErrorMappingResponse, error)
+	ListMappings(ctx context.Context) (*dto.ProviderErrorMappingListResponse, error)
+	DeleteMapping(ctx context.Context, id uuid.UUID) error
+	// Normalize returns the error code of the mapping that matches the
+	// refusal the provider named providerName answered with, or code when
+	// none does.
+	Normalize(ctx context.Context, providerName string, refusal *provider.ResponseStatus, code apperrors.ErrorCode) apperrors.ErrorCode
+}
+
+// providerErrorMappingService keeps the mappings in memory, most specific
+// first, and reads them again once refreshInterval has passed since they
+// were loaded, so changes made on other instances arrive within it. Changes
+// made through the service apply to the next refusal.
+type providerErrorMappingService struct {
+	repo            repository.ProviderErrorMappingRepository
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu       sync.Mutex
+	mappings []*repository.ProviderErrorMapping
+	loadedAt time.Time
+}
+
+func NewProviderErrorMappingService(repo repository.ProviderErrorMappingRepository, refreshInterval time.Duration) ProviderErrorMappingService {
+	return &providerErrorMappingService{
+		repo:            repo,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+func (s *providerErrorMappingService) CreateMapping(ctx context.Context, req *dto.CreateProviderErrorMappingRequest) (*dto.ProviderErrorMappingResponse, error) {
+	mapping := &repository.ProviderErrorMapping{
+		ID:           uuid.New(),
+		Provider:     strings.TrimSpace(req.Provider),
+		StatusCode:   req.StatusCode,
+		ProviderCode: strings.TrimSpace(req.ProviderCode),
+		BodyContains: req.BodyContains,
+		ErrorCode:    strings.ToUpper(strings.TrimSpace(req.ErrorCode)),
+		Description:  strings.TrimSpace(req.Description),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := validateMapping(mapping); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, mapping); err != nil {
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeAlreadyExists {
+			return nil, apperrors.New(apperrors.ErrorCodeAlreadyExists, "a mapping with the same provider and conditions already exists")
+		}
+		return nil, err
+	}
+	s.invalidate()
+
+	logger.FromContext(ctx).Info("provider error mapping created",
+		zap.String("mapping_id", mapping.ID.String()),
+		zap.String("provider", mapping.Provider),
+		zap.String("error_code", mapping.ErrorCode),
+	)
+
+	resp := toProviderErrorMappingDTO(mapping)
+	return &resp, nil
+}
+
+func (s *providerErrorMappingService) GetMapping(ctx context.Context, id uuid.UUID) (*dto.ProviderErrorMappingResponse, error) {
+	mapping, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toProviderErrorMappingDTO(mapping)
+	return &resp, nil
+}
+
+func (s *providerErrorMappingService) ListMappings(ctx context.Context) (*dto.ProviderErrorMappingListResponse, error) {
+	mappings, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ProviderErrorMappingResponse, len(mappings))
+	for i, mapping := range mappings {
+		responses[i] = toProviderErrorMappingDTO(mapping)
+	}
+
+	return &dto.ProviderErrorMappingListResponse{Mappings: responses}, nil
+}
+
+func (s *providerErrorMappingService) DeleteMapping(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+
+	logger.FromContext(ctx).Info("provider error mapping deleted", zap.String("mapping_id", id.String()))
+	return nil
+}
+
+func (s *providerErrorMappingService) Normalize(ctx context.Context, providerName string, refusal *provider.ResponseStatus, code apperrors.ErrorCode) apperrors.ErrorCode {
+	if refusal == nil || !refusal.Refused {
+		return code
+	}
+
+	for _, mapping := range s.current(ctx) {
+		if mapping.Provider == providerName && mappingMatches(mapping, refusal) {
+			return apperrors.ErrorCode(mapping.ErrorCode)
+		}
+	}
+	return code
+}
+
+// current returns the mappings, reading them again when they are due. A
+// failed read keeps the ones loaded before until the next refresh.
+func (s *providerErrorMappingService) current(ctx context.Context) []*repository.ProviderErrorMapping {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !s.loadedAt.IsZero() && now.Sub(s.loadedAt) < s.refreshInterval {
+		return s.mappings
+	}
+	s.loadedAt = now
+
+	mappings, err := s.repo.List(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to load provider error mappings, keeping the previous ones", zap.Error(err))
+		return s.mappings
+	}
+	// Stable, so mappings as specific as each other keep their creation order
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return mappingSpecificity(mappings[i]) > mappingSpecificity(mappings[j])
+	})
+	s.mappings = mappings
+	return s.mappings
+}
+
+func (s *providerErrorMappingService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
+
+func validateMapping(mapping *repository.ProviderErrorMapping) error {
+	if mapping.Provider == "" {
+		return apperrors.NewValidationError("provider is required")
+	}
+	if mapping.StatusCode == 0 && mapping.ProviderCode == "" && mapping.BodyContains == "" {
+		return apperrors.NewValidationError("at least one of status_code, provider_code and body_contains is required")
+	}
+	if mapping.StatusCode != 0 && (mapping.StatusCode < 100 || mapping.StatusCode > 599) {
+		return apperrors.NewValidationError("status_code must be an HTTP status between 100 and 599")
+	}
+	if !apperrors.IsProviderFailureCode(apperrors.ErrorCode(mapping.ErrorCode)) {
+		codes := make([]string, len(apperrors.ProviderFailureCodes))
+		for i, code := range apperrors.ProviderFailureCodes {
+			codes[i] = string(code)
+		}
+		return apperrors.NewValidationError(fmt.Sprintf("error_code must be one of %s", strings.Join(codes, ", ")))
+	}
+	if len(mapping.Description) > maxMappingDescriptionLength {
+		return apperrors.NewValidationError("description must be at most 500 characters")
+	}
+	return nil
+}
+
+func mappingMatches(mapping *repository.ProviderErrorMapping, refusal *provider.ResponseStatus) bool {
+	if mapping.StatusCode != 0 && mapping.StatusCode != refusal.Code {
+		return false
+	}
+	if mapping.ProviderCode != "" && mapping.ProviderCode != refusal.ErrorCode {
+		return false
+	}
+	if mapping.BodyContains != "" && !strings.Contains(strings.ToLower(refusal.Body), strings.ToLower(mapping.BodyContains)) {
+		return false
+	}
+	return true
+}
+
+// mappingSpecificity ranks a provider's own error code above a body match,
+// and either above a status code alone.
+func mappingSpecificity(mapping *repository.ProviderErrorMapping) int {
+	specificity := 0
+	if mapping.ProviderCode != "" {
+		specificity += 4
+	}
+	if mapping.BodyContains != "" {
+		specificity += 2
+	}
+	if mapping.StatusCode != 0 {
+		specificity++
+	}
+	return specificity
+}
+
+func toProviderErrorMappingDTO(mapping *repository.ProviderErrorMapping) dto.ProviderErrorMappingResponse {
+	return dto.ProviderErrorMappingResponse{
+		ID:           mapping.ID.String(),
+		Provider:     mapping.Provider,
+		StatusCode:   mapping.StatusCode,
+		ProviderCode: mapping.ProviderCode,
+		BodyContains: mapping.BodyContains,
+		ErrorCode:    mapping.ErrorCode,
+		Retryable:    apperrors.ErrorCode(mapping.ErrorCode).Retryable(),
+		Description:  mapping.Description,
+		CreatedAt:    mapping.CreatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProviderErrorMappingRepository struct {
+	mock.Mock
+}
+
+func (m *MockProviderErrorMappingRepository) Create(ctx context.Context, mapping *repository.ProviderErrorMapping) error {
+	args := m.Called(ctx, mapping)
+	return args.Error(0)
+}
+
+func (m *MockProviderErrorMappingRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockProviderErrorMappingRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.ProviderErrorMapping, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ProviderErrorMapping), args.Error(1)
+}
+
+func (m *MockProviderErrorMappingRepository) List(ctx context.Context) ([]*repository.ProviderErrorMapping, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ProviderErrorMapping), args.Error(1)
+}
+
+func TestProviderErrorMapping_NormalizeMostSpecificWins(t *testing.T) {
+	mockRepo := new(MockProviderErrorMappingRepository)
+	mockRepo.On("List", mock.Anything).Return([]*repository.ProviderErrorMapping{
+		{Provider: "webhook", StatusCode: 402, ErrorCode: "INSUFFICIENT_BALANCE"},
+		{Provider: "webhook", StatusCode: 400, BodyContains: "Carrier", ErrorCode: "CARRIER_ERROR"},
+		{Provider: "twilio", ProviderCode: "30007", ErrorCode: "MESSAGE_BLOCKED"},
+		{Provider: "twilio", StatusCode: 400, ErrorCode: "INVALID_RECIPIENT"},
+	}, nil).Once()
+	svc := service.NewProviderErrorMappingService(mockRepo, time.Minute)
+
+	tests := []struct {
+		name     string
+		provider string
+		refusal  *provider.ResponseStatus
+		want     apperrors.ErrorCode
+	}{
+		{"status", "webhook", &provider.ResponseStatus{Code: 402, Refused: true}, apperrors.ErrorCodeInsufficientBalance},
+		{"body, case-insensitively", "webhook", &provider.ResponseStatus{Code: 400, Refused: true, Body: `{"error":"carrier unreachable"}`}, apperrors.ErrorCodeCarrierError},
+		{"body does not match", "webhook", &provider.ResponseStatus{Code: 400, Refused: true, Body: "bad request"}, apperrors.ErrorCodeProviderRejected},
+		{"provider code before status", "twilio", &provider.ResponseStatus{Code: 400, Refused: true, ErrorCode: "30007"}, apperrors.ErrorCodeMessageBlocked},
+		{"status alone", "twilio", &provider.ResponseStatus{Code: 400, Refused: true, ErrorCode: "21602"}, apperrors.ErrorCodeInvalidRecipient},
+		{"other provider", "sns", &provider.ResponseStatus{Code: 402, Refused: true}, apperrors.ErrorCodeProviderRejected},
+		{"not a refusal", "webhook", &provider.ResponseStatus{Code: 402}, apperrors.ErrorCodeProviderRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := svc.Normalize(context.Background(), tt.provider, tt.refusal, apperrors.ErrorCodeProviderRejected)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	// Loaded once and kept until the refresh interval passes
+	mockRepo.AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestProviderErrorMapping_KeepsMappingsWhenReloadFails(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProviderErrorMappingRepository)
+	mockRepo.On("List", mock.Anything).Return([]*repository.ProviderErrorMapping{
+		{Provider: "webhook", StatusCode: 402, ErrorCode: "INSUFFICIENT_BALANCE"},
+	}, nil).Once()
+	mockRepo.On("List", mock.Anything).Return(nil, apperrors.NewDatabaseError(errors.New("connection refused")))
+	svc := service.NewProviderErrorMappingService(mockRepo, 0)
+	refusal := &provider.ResponseStatus{Code: 402, Refused: true}
+
+	// Act
+	first := svc.Normalize(context.Background(), "webhook", refusal, apperrors.ErrorCodeInvalidResponse)
+	second := svc.Normalize(context.Background(), "webhook", refusal, apperrors.ErrorCodeInvalidResponse)
+
+	// Assert
+	assert.Equal(t, apperrors.ErrorCodeInsufficientBalance, first)
+	assert.Equal(t, apperrors.ErrorCodeInsufficientBalance, second)
+	mockRepo.AssertNumberOfCalls(t, "List", 2)
+}
+
+func TestProviderErrorMapping_CreateAppliesToNextRefusal(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProviderErrorMappingRepository)
+	mockRepo.On("List", mock.Anything).Return([]*repository.ProviderErrorMapping{}, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.ProviderErrorMapping")).Return(nil)
+	mockRepo.On("List", mock.Anything).Return([]*repository.ProviderErrorMapping{
+		{Provider: "webhook", BodyContains: "no credit", ErrorCode: "INSUFFICIENT_BALANCE"},
+	}, nil).Once()
+	svc := service.NewProviderErrorMappingService(mockRepo, time.Hour)
+	refusal := &provider.ResponseStatus{Code: 400, Refused: true, Body: "no credit left"}
+
+	// Act
+	before := svc.Normalize(context.Background(), "webhook", refusal, apperrors.ErrorCodeProviderRejected)
+	resp, err := svc.CreateMapping(context.Background(), &dto.CreateProviderErrorMappingRequest{
+		Provider:     " webhook ",
+		BodyContains: "no credit",
+		ErrorCode:    "insufficient_balance",
+	})
+	after := svc.Normalize(context.Background(), "webhook", refusal, apperrors.ErrorCodeProviderRejected)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook", resp.Provider)
+	assert.Equal(t, "INSUFFICIENT_BALANCE", resp.ErrorCode)
+	assert.True(t, resp.Retryable)
+	assert.Equal(t, apperrors.ErrorCodeProviderRejected, before)
+	assert.Equal(t, apperrors.ErrorCodeInsufficientBalance, after)
+}
+
+func TestProviderErrorMapping_CreateValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.CreateProviderErrorMappingRequest
+	}{
+		{"no condition", dto.CreateProviderErrorMappingRequest{Provider: "webhook", ErrorCode: "CARRIER_ERROR"}},
+		{"status out of range", dto.CreateProviderErrorMappingRequest{Provider: "webhook", StatusCode: 42, ErrorCode: "CARRIER_ERROR"}},
+		{"not a provider failure", dto.CreateProviderErrorMappingRequest{Provider: "webhook", StatusCode: 400, ErrorCode: "NOT_FOUND"}},
+		{"blank provider", dto.CreateProviderErrorMappingRequest{Provider: " ", StatusCode: 400, ErrorCode: "CARRIER_ERROR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewProviderErrorMappingService(new(MockProviderErrorMappingRepository), time.Minute)
+
+			_, err := svc.CreateMapping(context.Background(), &tt.req)
+
+			var appErr *apperrors.AppError
+			if assert.ErrorAs(t, err, &appErr) {
+				assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// QuietHoursOff is a tenant's quiet hours when it wants none, even though
+// QUIET_HOURS sets a default.
+const QuietHoursOff = "off"
+
+// tenantQuietHoursTTL is how long a tenant's quiet hours are used before
+// they are read again.
+const tenantQuietHoursTTL = time.Minute
+
+// QuietHours holds SMS back while it is night where the recipient is. The
+// recipient's time zones come from the phone number; a number that may be in
+// several zones is held while any of them is quiet, until the last one's
+// window ends. Tenants may replace the default window with their own or
+// turn it off.
+type QuietHours struct {
+	window    valueobject.QuietHours
+	regions   map[string]bool
+	exempt    map[valueobject.MessagePriority]bool
+	tenants   repository.TenantRepository
+	now       func() time.Time
+	locations sync.Map
+
+	mu        sync.Mutex
+	overrides map[uuid.UUID]tenantQuietHours
+}
+
+type tenantQuietHours struct {
+	window   valueobject.QuietHours
+	off      bool
+	loadedAt time.Time
+}
+
+// NewQuietHours applies window to the countries in regions, or every
+// country when regions is empty. Messages of the exempt priorities are
+// never held. tenants may be nil when there are no tenant overrides.
+func NewQuietHours(window valueobject.QuietHours, regions []string, exempt []valueobject.MessagePriority, tenants repository.TenantRepository) *QuietHours {
+	exemptSet := make(map[valueobject.MessagePriority]bool, len(exempt))
+	for _, priority := range exempt {
+		exemptSet[priority] = true
+	}
+	return &QuietHours{
+		window:    window,
+		regions:   regionSet(regions),
+		exempt:    exemptSet,
+		tenants:   tenants,
+		now:       time.Now,
+		overrides: make(map[uuid.UUID]tenantQuietHours),
+	}
+}
+
+// HoldUntil reports whether message falls in quiet hours now, and if so
+// until when it has to wait. A nil policy holds nothing.
+func (q *QuietHours) HoldUntil(ctx context.Context, message *entity.Message) (time.Time, bool) {
+	if q == nil || q.exempt[message.Priority()] {
+		return time.Time{}, false
+	}
+	phone := message.Recipient().PhoneNumber()
+	if phone == nil {
+		return time.Time{}, false
+	}
+	if len(q.regions) > 0 && !q.regions[phone.RegionCode()] {
+		return time.Time{}, false
+	}
+
+	window := q.windowFor(ctx, message.TenantID())
+	if window.IsZero() {
+		return time.Time{}, false
+	}
+
+	now := q.now()
+	var until time.Time
+	for _, zone := range phone.Timezones() {
+		location, ok := q.location(zone)
+		if !ok {
+			continue
+		}
+		if end, quiet := window.EndAfter(now.In(location)); quiet && end.After(until) {
+			until = end
+		}
+	}
+	return until.UTC(), !until.IsZero()
+}
+
+// IsDeferred reports whether err means a claimed message was held back, for
+// quiet hours or until the provider's daily quota resets, rather than failing.
+func IsDeferred(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) &&
+		(appErr.Code == apperrors.ErrorCodeQuietHours || appErr.Code == apperrors.ErrorCodeQuotaExhausted)
+}
+
+// windowFor returns the tenant's own window when it has one. A tenant
+// whose settings cannot be read gets the default.
+func (q *QuietHours) windowFor(ctx context.Context, tenantID uuid.UUID) valueobject.QuietHours {
+	if tenantID == uuid.Nil || q.tenants == nil {
+		return q.window
+	}
+
+	q.mu.Lock()
+	cached, ok := q.overrides[tenantID]
+	q.mu.Unlock()
+
+	if !ok || q.now().Sub(cached.loadedAt) >= tenantQuietHoursTTL {
+		tenant, err := q.tenants.FindByID(ctx, tenantID)
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to read tenant quiet hours, using the default",
+				zap.Error(err),
+				zap.String("tenant_id", tenantID.String()),
+			)
+			return q.window
+		}
+
+		cached = tenantQuietHours{off: tenant.QuietHours == QuietHoursOff, loadedAt: q.now()}
+		if !cached.off && tenant.QuietHours != "" {
+			// Stored values were checked when they were set
+			cached.window, _ = valueobject.ParseQuietHours(tenant.QuietHours)
+		}
+
+		q.mu.Lock()
+		q.overrides[tenantID] = cached
+		q.mu.Unlock()
+	}
+
+	switch {
+	case cached.off:
+		return valueobject.QuietHours{}
+	case !cached.window.IsZero():
+		return cached.window
+	default:
+		return q.window
+	}
+}
+
+func (q *QuietHours) location(zone string) (*time.Location, bool) {
+	if location, ok := q.locations.Load(zone); ok {
+		return location.(*time.Location), true
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, false
+	}
+	q.locations.Store(zone, location)
+	return location, true
+}
@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// quietWindow returns a two-hour window of Turkish local time that starts
+// from after now, and the time it ends.
+func quietWindow(t *testing.T, from time.Duration) (valueobject.QuietHours, time.Time) {
+	t.Helper()
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Skip("time zone data is not available")
+	}
+	start := time.Now().In(istanbul).Add(from).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+
+	window, err := valueobject.ParseQuietHours(fmt.Sprintf("%s-%s", start.Format("15:04"), end.Format("15:04")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return window, end.UTC()
+}
+
+func newQuietMessage(t *testing.T) *entity.Message {
+	t.Helper()
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, err := entity.NewMessage(phone, content, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestProcessClaimedMessage_DefersDuringQuietHours(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockSenderProvider)
+	window, end := quietWindow(t, -time.Hour)
+	quietHours := service.NewQuietHours(window, nil, nil, nil)
+
+	svc := service.NewMessageService(mockRepo, mockWebhook, new(MockMessageCache), eventbus.NewInMemoryBus(), 160, 0, 3, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, quietHours, nil, nil, nil, 0, false)
+
+	message := claimed(newQuietMessage(t))[0]
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Message")).
+		Return(nil).Once()
+
+	// Act
+	err := svc.ProcessClaimedMessage(context.Background(), message)
+
+	// Assert
+	assert.True(t, service.IsDeferred(err))
+	assert.True(t, message.Status().IsPending())
+	assert.Zero(t, message.Attempts())
+	if assert.NotNil(t, message.ScheduledAt()) {
+		assert.Equal(t, end, *message.ScheduledAt())
+	}
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestQuietHours_HoldUntil(t *testing.T) {
+	quiet, _ := quietWindow(t, -time.Hour)
+	later, _ := quietWindow(t, 2*time.Hour)
+
+	testCases := []struct {
+		name         string
+		window       valueobject.QuietHours
+		regions      []string
+		exempt       []valueobject.MessagePriority
+		priority     valueobject.MessagePriority
+		expectedHold bool
+	}{
+		{name: "inside the window", window: quiet, expectedHold: true},
+		{name: "outside the window", window: later},
+		{name: "no window", window: valueobject.QuietHours{}},
+		{name: "country listed", window: quiet, regions: []string{"TR"}, expectedHold: true},
+		{name: "country not listed", window: quiet, regions: []string{"US"}},
+		{
+			name:     "exempt priority",
+			window:   quiet,
+			exempt:   []valueobject.MessagePriority{valueobject.MessagePriorityHigh},
+			priority: valueobject.MessagePriorityHigh,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			quietHours := service.NewQuietHours(tc.window, tc.regions, tc.exempt, nil)
+			message := newQuietMessage(t)
+			if tc.priority != "" {
+				message.AssignPriority(tc.priority)
+			}
+
+			// Act
+			_, hold := quietHours.HoldUntil(context.Background(), message)
+
+			// Assert
+			assert.Equal(t, tc.expectedHold, hold)
+		})
+	}
+}
+
+func TestQuietHours_TenantOverride(t *testing.T) {
+	quiet, _ := quietWindow(t, -time.Hour)
+	later, _ := quietWindow(t, 2*time.Hour)
+
+	testCases := []struct {
+		name         string
+		tenantWindow string
+		findErr      error
+		expectedHold bool
+	}{
+		{name: "default window", tenantWindow: "", expectedHold: false},
+		{name: "own window", tenantWindow: quiet.String(), expectedHold: true},
+		{name: "turned off", tenantWindow: service.QuietHoursOff, expectedHold: false},
+		{name: "unreadable tenant", findErr: errors.New("connection refused"), expectedHold: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			tenantID := uuid.New()
+			mockRepo := new(MockTenantRepository)
+			if tc.findErr != nil {
+				mockRepo.On("FindByID", mock.Anything, tenantID).Return(nil, tc.findErr)
+			} else {
+				mockRepo.On("FindByID", mock.Anything, tenantID).
+					Return(&repository.Tenant{ID: tenantID, QuietHours: tc.tenantWindow}, nil).Once()
+			}
+			quietHours := service.NewQuietHours(later, nil, nil, mockRepo)
+			message := newQuietMessage(t)
+			message.AssignTenant(tenantID)
+
+			// Act
+			_, first := quietHours.HoldUntil(context.Background(), message)
+			_, second := quietHours.HoldUntil(context.Background(), message)
+
+			// Assert
+			assert.Equal(t, tc.expectedHold, first)
+			assert.Equal(t, first, second)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
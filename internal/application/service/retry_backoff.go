@@ -0,0 +1,51 @@
+package service
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryBackoff computes how long a message waits before its next attempt.
+// The delay doubles with every attempt up to max, and the upper half of it is
+// randomised so messages that failed together do not retry together.
+type RetryBackoff struct {
+	base time.Duration
+	max  time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func NewRetryBackoff(base, max time.Duration) *RetryBackoff {
+	return &RetryBackoff{
+		base: base,
+		max:  max,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Delay returns the wait after the given (1-based) attempt failed.
+func (b *RetryBackoff) Delay(attempt int) time.Duration {
+	if b == nil || b.base <= 0 {
+		return 0
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := b.base
+	for i := 1; i < attempt && delay < b.max; i++ {
+		delay *= 2
+	}
+	if b.max > 0 && delay > b.max {
+		delay = b.max
+	}
+
+	half := delay / 2
+	b.mu.Lock()
+	jitter := time.Duration(b.rand.Int63n(int64(half) + 1))
+	b.mu.Unlock()
+
+	return half + jitter
+}
@@ -0,0 +1,35 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff_GrowsExponentially(t *testing.T) {
+	backoff := service.NewRetryBackoff(10*time.Second, time.Hour)
+
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second} {
+		delay := backoff.Delay(attempt)
+		assert.GreaterOrEqual(t, delay, want/2)
+		assert.LessOrEqual(t, delay, want)
+	}
+}
+
+func TestRetryBackoff_CappedAtMax(t *testing.T) {
+	backoff := service.NewRetryBackoff(10*time.Second, time.Minute)
+
+	delay := backoff.Delay(20)
+
+	assert.GreaterOrEqual(t, delay, 30*time.Second)
+	assert.LessOrEqual(t, delay, time.Minute)
+}
+
+func TestRetryBackoff_DisabledWithoutBase(t *testing.T) {
+	var nilBackoff *service.RetryBackoff
+
+	assert.Zero(t, service.NewRetryBackoff(0, time.Minute).Delay(3))
+	assert.Zero(t, nilBackoff.Delay(3))
+}
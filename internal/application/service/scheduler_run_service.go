@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// schedulerRunPruneInterval is how often runs past their retention are deleted
+const schedulerRunPruneInterval = time.Hour
+
+type SchedulerRunService interface {
+	// Record stores run, stamped with this instance's name. It is best
+	// effort: a failure is logged and never affects the cycle.
+	Record(ctx context.Context, run *repository.SchedulerRun)
+	ListRuns(ctx context.Context, page, pageSize int) (*dto.SchedulerRunListResponse, error)
+}
+
+type schedulerRunService struct {
+	repo      repository.SchedulerRunRepository
+	retention time.Duration
+	instance  string
+	now       func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewSchedulerRunService keeps runs for retention, or forever when it is 0.
+// Old runs are deleted at most once an hour, when a run is recorded.
+func NewSchedulerRunService(repo repository.SchedulerRunRepository, retention time.Duration) SchedulerRunService {
+	instance, _ := os.Hostname()
+	return &schedulerRunService{
+		repo:      repo,
+		retention: retention,
+		instance:  instance,
+		now:       time.Now,
+	}
+}
+
+func (s *schedulerRunService) Record(ctx context.Context, run *repository.SchedulerRun) {
+	run.ID = uuid.New()
+	run.Instance = s.instance
+	run.StartedAt = run.StartedAt.UTC()
+
+	// The repository logs the failure
+	_ = s.repo.Add(ctx, run)
+
+	s.prune(ctx)
+}
+
+func (s *schedulerRunService) ListRuns(ctx context.Context, page, pageSize int) (*dto.SchedulerRunListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	runs, total, err := s.repo.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SchedulerRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = dto.SchedulerRunResponse{
+			ID:         run.ID.String(),
+			Instance:   run.Instance,
+			StartedAt:  run.StartedAt,
+			DurationMs: run.Duration.Milliseconds(),
+			Processed:  run.Processed,
+			Successful: run.Successful,
+			Failed:     run.Failed,
+			Errors:     run.Errors,
+			LastError:  run.LastError,
+			SkipReason: run.SkipReason,
+		}
+	}
+
+	return &dto.SchedulerRunListResponse{
+		Runs:       responses,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *schedulerRunService) prune(ctx context.Context) {
+	if s.retention <= 0 {
+		return
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < schedulerRunPruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	deleted, err := s.repo.DeleteStartedBefore(ctx, now.UTC().Add(-s.retention))
+	if err != nil {
+		return
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("deleted old scheduler runs",
+			zap.Int64("deleted", deleted),
+		)
+	}
+}
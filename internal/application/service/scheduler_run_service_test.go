@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSchedulerRunRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchedulerRunRepository) Add(ctx context.Context, run *repository.SchedulerRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockSchedulerRunRepository) List(ctx context.Context, limit, offset int) ([]*repository.SchedulerRun, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.SchedulerRun), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSchedulerRunRepository) DeleteStartedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRecordSchedulerRun_PrunesAtMostHourly(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockSchedulerRunRepository)
+	svc := service.NewSchedulerRunService(mockRepo, 24*time.Hour)
+
+	var stored []*repository.SchedulerRun
+	mockRepo.On("Add", mock.Anything, mock.AnythingOfType("*repository.SchedulerRun")).
+		Run(func(args mock.Arguments) { stored = append(stored, args.Get(1).(*repository.SchedulerRun)) }).
+		Return(nil)
+	mockRepo.On("DeleteStartedBefore", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff) > 23*time.Hour
+	})).Return(int64(5), nil).Once()
+
+	// Act
+	svc.Record(context.Background(), &repository.SchedulerRun{StartedAt: time.Now(), Processed: 2, Successful: 2})
+	svc.Record(context.Background(), &repository.SchedulerRun{StartedAt: time.Now(), SkipReason: "circuit_open"})
+
+	// Assert
+	if assert.Len(t, stored, 2) {
+		assert.NotEqual(t, uuid.Nil, stored[0].ID)
+		assert.NotEqual(t, stored[0].ID, stored[1].ID)
+		assert.Equal(t, time.UTC, stored[0].StartedAt.Location())
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordSchedulerRun_FailureIsNotPropagated(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockSchedulerRunRepository)
+	svc := service.NewSchedulerRunService(mockRepo, 0)
+
+	mockRepo.On("Add", mock.Anything, mock.Anything).
+		Return(apperrors.NewDatabaseError(errors.New("connection refused")))
+
+	// Act
+	svc.Record(context.Background(), &repository.SchedulerRun{StartedAt: time.Now()})
+
+	// Assert
+	mockRepo.AssertNotCalled(t, "DeleteStartedBefore", mock.Anything, mock.Anything)
+}
+
+func TestListSchedulerRuns_Paginates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockSchedulerRunRepository)
+	svc := service.NewSchedulerRunService(mockRepo, 0)
+
+	run := &repository.SchedulerRun{
+		ID:         uuid.New(),
+		Instance:   "api-1",
+		StartedAt:  time.Now().UTC(),
+		Duration:   1500 * time.Millisecond,
+		Processed:  3,
+		Successful: 2,
+		Failed:     1,
+	}
+	mockRepo.On("List", mock.Anything, 20, 40).Return([]*repository.SchedulerRun{run}, int64(41), nil)
+
+	// Act
+	result, err := svc.ListRuns(context.Background(), 3, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 41, result.TotalCount)
+	assert.Equal(t, 3, result.Page)
+	assert.Equal(t, 20, result.PageSize)
+	if assert.Len(t, result.Runs, 1) {
+		assert.Equal(t, "api-1", result.Runs[0].Instance)
+		assert.Equal(t, int64(1500), result.Runs[0].DurationMs)
+		assert.Equal(t, 1, result.Runs[0].Failed)
+	}
+}
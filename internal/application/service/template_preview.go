@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+)
+
+// PreviewTemplate renders content with its variables and checks the result
+// as SMS content the way CreateMessage does: content moderation first, then
+// the char and segment limits, splitting it when that is enabled. Nothing is
+// stored.
+func (s *messageService) PreviewTemplate(ctx context.Context, req *dto.TemplatePreviewRequest) (*dto.TemplatePreviewResponse, error) {
+	rendered, missing := valueobject.RenderTemplate(req.Content, req.Variables)
+
+	resp := &dto.TemplatePreviewResponse{
+		CharLimit:        s.charLimit,
+		MaxSegments:      s.maxSegments,
+		MissingVariables: missing,
+		Violations:       []string{},
+	}
+	for _, name := range missing {
+		resp.Violations = append(resp.Violations, fmt.Sprintf("variable %s has no value", name))
+	}
+
+	// A redaction changes what would be sent, and so its length
+	text, err := s.moderation.screen(ctx, rendered)
+	var appErr *apperrors.AppError
+	switch {
+	case err == nil:
+		resp.Redacted = text != rendered
+	case errors.As(err, &appErr) && appErr.Code == apperrors.ErrorCodeContentRejected:
+		resp.Violations = append(resp.Violations, appErr.Message)
+		text = rendered
+	default:
+		return nil, err
+	}
+
+	resp.Content = text
+	resp.CharacterCount = utf8.RuneCountInString(text)
+	resp.Encoding = string(valueobject.Segment(text).Encoding)
+
+	parts := []string{text}
+	if s.splitMaxParts >= 2 && resp.CharacterCount > s.charLimit {
+		split, err := valueobject.SplitContent(text, s.charLimit, s.splitMaxParts)
+		if err != nil {
+			resp.Violations = append(resp.Violations, err.Error())
+		} else {
+			parts = split
+		}
+	}
+	resp.Parts = len(parts)
+
+	for _, part := range parts {
+		if _, err := valueobject.NewMessageContent(part, s.charLimit); err != nil {
+			resp.Violations = append(resp.Violations, err.Error())
+		}
+		segments := valueobject.Segment(part).Segments
+		resp.Segments += segments
+		if s.maxSegments > 0 && segments > s.maxSegments {
+			resp.Violations = append(resp.Violations, fmt.Sprintf(
+				"message content exceeds maximum of %d SMS segments (got %d)", s.maxSegments, segments))
+		}
+	}
+
+	resp.Valid = len(resp.Violations) == 0
+	return resp, nil
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewTemplate(t *testing.T) {
+	filter, err := service.NewRegexContentFilter(map[string]string{
+		service.ContentRuleCreditCard: service.ContentActionRedact,
+		service.ContentRuleURL:        service.ContentActionReject,
+	}, nil)
+	require.NoError(t, err)
+	moderation := &service.ContentModeration{Filter: filter}
+
+	tests := []struct {
+		name          string
+		maxSegments   int
+		splitMaxParts int
+		req           dto.TemplatePreviewRequest
+		want          dto.TemplatePreviewResponse
+		wantViolation string
+	}{
+		{
+			name: "renders variables",
+			req:  dto.TemplatePreviewRequest{Content: "Hi {{name}}, your code is {{code}}", Variables: map[string]string{"name": "Ada", "code": "1234"}},
+			want: dto.TemplatePreviewResponse{Content: "Hi Ada, your code is 1234", CharacterCount: 25, CharLimit: 160, Encoding: "GSM-7", Segments: 1, Parts: 1, Valid: true},
+		},
+		{
+			name:          "missing variable",
+			req:           dto.TemplatePreviewRequest{Content: "Hi {{name}}"},
+			want:          dto.TemplatePreviewResponse{Content: "Hi {{name}}", CharacterCount: 11, CharLimit: 160, Encoding: "GSM-7", Segments: 1, Parts: 1, MissingVariables: []string{"name"}},
+			wantViolation: "variable name has no value",
+		},
+		{
+			name: "redacted by moderation",
+			req:  dto.TemplatePreviewRequest{Content: "Card {{card}} charged", Variables: map[string]string{"card": "4111 1111 1111 1111"}},
+			want: dto.TemplatePreviewResponse{Content: "Card [redacted] charged", CharacterCount: 23, CharLimit: 160, Encoding: "GSM-7", Segments: 1, Parts: 1, Redacted: true, Valid: true},
+		},
+		{
+			name:          "rejected by moderation",
+			req:           dto.TemplatePreviewRequest{Content: "Claim at {{link}}", Variables: map[string]string{"link": "https://example.com"}},
+			want:          dto.TemplatePreviewResponse{Content: "Claim at https://example.com", CharacterCount: 28, CharLimit: 160, Encoding: "GSM-7", Segments: 1, Parts: 1},
+			wantViolation: "matched url rule",
+		},
+		{
+			name:          "over the char limit",
+			req:           dto.TemplatePreviewRequest{Content: strings.Repeat("a", 161)},
+			want:          dto.TemplatePreviewResponse{Content: strings.Repeat("a", 161), CharacterCount: 161, CharLimit: 160, Encoding: "GSM-7", Segments: 2, Parts: 1},
+			wantViolation: "exceeds",
+		},
+		{
+			name:          "over the segment limit",
+			maxSegments:   1,
+			req:           dto.TemplatePreviewRequest{Content: strings.Repeat("ş", 71)},
+			want:          dto.TemplatePreviewResponse{Content: strings.Repeat("ş", 71), CharacterCount: 71, CharLimit: 160, Encoding: "UCS-2", Segments: 2, MaxSegments: 1, Parts: 1},
+			wantViolation: "maximum of 1 SMS segments (got 2)",
+		},
+		{
+			name:          "split into parts",
+			splitMaxParts: 3,
+			req:           dto.TemplatePreviewRequest{Content: strings.Repeat("a", 300)},
+			want:          dto.TemplatePreviewResponse{Content: strings.Repeat("a", 300), CharacterCount: 300, CharLimit: 160, Encoding: "GSM-7", Segments: 2, Parts: 2, Valid: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockMessageRepository)
+			svc := service.NewMessageService(mockRepo, new(MockSenderProvider), new(MockMessageCache), eventbus.NewInMemoryBus(), 160, tt.maxSegments, 3, nil, nil, nil, nil, nil, nil, moderation, nil, nil, nil, nil, nil, nil, nil, tt.splitMaxParts, false)
+
+			// Act
+			resp, err := svc.PreviewTemplate(context.Background(), &tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			violations := resp.Violations
+			resp.Violations = nil
+			if tt.want.MissingVariables == nil {
+				tt.want.MissingVariables = []string{}
+			}
+			assert.Equal(t, tt.want, *resp)
+			if tt.wantViolation == "" {
+				assert.Empty(t, violations)
+			} else if assert.NotEmpty(t, violations) {
+				assert.Contains(t, strings.Join(violations, "; "), tt.wantViolation)
+			}
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
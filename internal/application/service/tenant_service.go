@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// tenantAPIKeyPrefix makes tenant keys recognisable in logs and secret
+// scanners without revealing anything about the key itself.
+const tenantAPIKeyPrefix = "tk_"
+
+type TenantService interface {
+	CreateTenant(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantCredentialsResponse, error)
+	GetTenant(ctx context.Context, id uuid.UUID) (*dto.TenantResponse, error)
+	ListTenants(ctx context.Context, page, pageSize int) (*dto.TenantListResponse, error)
+	UpdateTenant(ctx context.Context, id uuid.UUID, req *dto.UpdateTenantRequest) (*dto.TenantResponse, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID) (*dto.TenantCredentialsResponse, error)
+	// ResolveAPIKey returns the tenant owning apiKey. ok is false for unknown
+	// keys and for deactivated tenants.
+	ResolveAPIKey(ctx context.Context, apiKey string) (tenantID uuid.UUID, ok bool, err error)
+}
+
+type tenantService struct {
+	repo repository.TenantRepository
+}
+
+func NewTenantService(repo repository.TenantRepository) TenantService {
+	return &tenantService{repo: repo}
+}
+
+func (s *tenantService) CreateTenant(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantCredentialsResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, apperrors.NewValidationError("name is required")
+	}
+	quietHours, err := normalizeQuietHours(req.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := generateTenantAPIKey()
+	if err != nil {
+		return nil, apperrors.NewInternalError(err)
+	}
+
+	now := time.Now().UTC()
+	tenant := &repository.Tenant{
+		ID:         uuid.New(),
+		Name:       name,
+		APIKeyHash: hashTenantAPIKey(apiKey),
+		Active:     true,
+		QuietHours: quietHours,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := s.repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("tenant created",
+		zap.String("tenant_id", tenant.ID.String()),
+		zap.String("name", tenant.Name),
+	)
+
+	return &dto.TenantCredentialsResponse{
+		TenantResponse: toTenantDTO(tenant),
+		APIKey:         apiKey,
+	}, nil
+}
+
+func (s *tenantService) GetTenant(ctx context.Context, id uuid.UUID) (*dto.TenantResponse, error) {
+	tenant, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toTenantDTO(tenant)
+	return &resp, nil
+}
+
+func (s *tenantService) ListTenants(ctx context.Context, page, pageSize int) (*dto.TenantListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	tenants, total, err := s.repo.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TenantResponse, len(tenants))
+	for i, tenant := range tenants {
+		responses[i] = toTenantDTO(tenant)
+	}
+
+	return &dto.TenantListResponse{
+		Tenants:    responses,
+		TotalCount: int(total),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+func (s *tenantService) UpdateTenant(ctx context.Context, id uuid.UUID, req *dto.UpdateTenantRequest) (*dto.TenantResponse, error) {
+	tenant, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, apperrors.NewValidationError("name must not be empty")
+		}
+		tenant.Name = name
+	}
+	if req.Active != nil {
+		tenant.Active = *req.Active
+	}
+	if req.QuietHours != nil {
+		quietHours, err := normalizeQuietHours(*req.QuietHours)
+		if err != nil {
+			return nil, err
+		}
+		tenant.QuietHours = quietHours
+	}
+	tenant.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("tenant updated",
+		zap.String("tenant_id", tenant.ID.String()),
+		zap.Bool("active", tenant.Active),
+	)
+
+	resp := toTenantDTO(tenant)
+	return &resp, nil
+}
+
+// RotateAPIKey replaces the tenant's key; the old key stops working
+// immediately.
+func (s *tenantService) RotateAPIKey(ctx context.Context, id uuid.UUID) (*dto.TenantCredentialsResponse, error) {
+	tenant, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := generateTenantAPIKey()
+	if err != nil {
+		return nil, apperrors.NewInternalError(err)
+	}
+
+	tenant.APIKeyHash = hashTenantAPIKey(apiKey)
+	tenant.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("tenant API key rotated", zap.String("tenant_id", tenant.ID.String()))
+
+	return &dto.TenantCredentialsResponse{
+		TenantResponse: toTenantDTO(tenant),
+		APIKey:         apiKey,
+	}, nil
+}
+
+func (s *tenantService) ResolveAPIKey(ctx context.Context, apiKey string) (uuid.UUID, bool, error) {
+	if !strings.HasPrefix(apiKey, tenantAPIKeyPrefix) {
+		return uuid.Nil, false, nil
+	}
+
+	tenant, err := s.repo.FindByAPIKeyHash(ctx, hashTenantAPIKey(apiKey))
+	if err != nil {
+		if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeNotFound {
+			return uuid.Nil, false, nil
+		}
+		return uuid.Nil, false, err
+	}
+
+	if !tenant.Active {
+		return uuid.Nil, false, nil
+	}
+
+	return tenant.ID, true, nil
+}
+
+func generateTenantAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tenantAPIKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashTenantAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeQuietHours checks a tenant's quiet hours and writes them the way
+// they are stored.
+func normalizeQuietHours(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == QuietHoursOff {
+		return value, nil
+	}
+	window, err := valueobject.ParseQuietHours(value)
+	if err != nil {
+		return "", apperrors.NewValidationError("quiet_hours must be HH:MM-HH:MM, off or empty")
+	}
+	return window.String(), nil
+}
+
+func toTenantDTO(tenant *repository.Tenant) dto.TenantResponse {
+	return dto.TenantResponse{
+		ID:         tenant.ID.String(),
+		Name:       tenant.Name,
+		Active:     tenant.Active,
+		QuietHours: tenant.QuietHours,
+		CreatedAt:  tenant.CreatedAt,
+		UpdatedAt:  tenant.UpdatedAt,
+	}
+}
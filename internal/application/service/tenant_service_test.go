@@ -0,0 +1,191 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) Create(ctx context.Context, tenant *repository.Tenant) error {
+	args := m.Called(ctx, tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) Update(ctx context.Context, tenant *repository.Tenant) error {
+	args := m.Called(ctx, tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.Tenant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) FindByAPIKeyHash(ctx context.Context, hash string) (*repository.Tenant, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) List(ctx context.Context, limit, offset int) ([]*repository.Tenant, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.Tenant), args.Get(1).(int64), args.Error(2)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCreateTenant_IssuesHashedAPIKey(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTenantRepository)
+	svc := service.NewTenantService(mockRepo)
+
+	var stored *repository.Tenant
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.Tenant")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*repository.Tenant) }).
+		Return(nil)
+
+	// Act
+	result, err := svc.CreateTenant(context.Background(), &dto.CreateTenantRequest{Name: " acme "})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", result.Name)
+	assert.True(t, result.Active)
+	assert.True(t, strings.HasPrefix(result.APIKey, "tk_"))
+	assert.Equal(t, sha256Hex(result.APIKey), stored.APIKeyHash)
+	assert.NotContains(t, stored.APIKeyHash, result.APIKey)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTenant_QuietHours(t *testing.T) {
+	testCases := []struct {
+		name          string
+		quietHours    string
+		expected      string
+		expectedError bool
+	}{
+		{name: "none", quietHours: "", expected: ""},
+		{name: "window", quietHours: " 22:00-8:00 ", expected: "22:00-08:00"},
+		{name: "off", quietHours: "OFF", expected: service.QuietHoursOff},
+		{name: "invalid", quietHours: "late", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTenantRepository)
+			svc := service.NewTenantService(mockRepo)
+			mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.Tenant")).Return(nil)
+
+			// Act
+			result, err := svc.CreateTenant(context.Background(), &dto.CreateTenantRequest{Name: "acme", QuietHours: tc.quietHours})
+
+			// Assert
+			if tc.expectedError {
+				assert.Error(t, err)
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result.QuietHours)
+		})
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	active := &repository.Tenant{ID: uuid.New(), Active: true}
+	inactive := &repository.Tenant{ID: uuid.New(), Active: false}
+
+	testCases := []struct {
+		name       string
+		apiKey     string
+		tenant     *repository.Tenant
+		findErr    error
+		expectedOK bool
+		expectedID uuid.UUID
+	}{
+		{name: "active tenant", apiKey: "tk_active", tenant: active, expectedOK: true, expectedID: active.ID},
+		{name: "inactive tenant", apiKey: "tk_inactive", tenant: inactive},
+		{name: "unknown key", apiKey: "tk_unknown", findErr: apperrors.NewNotFoundError("record not found")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTenantRepository)
+			svc := service.NewTenantService(mockRepo)
+
+			if tc.tenant != nil {
+				mockRepo.On("FindByAPIKeyHash", mock.Anything, sha256Hex(tc.apiKey)).Return(tc.tenant, nil)
+			} else {
+				mockRepo.On("FindByAPIKeyHash", mock.Anything, sha256Hex(tc.apiKey)).Return(nil, tc.findErr)
+			}
+
+			// Act
+			id, ok, err := svc.ResolveAPIKey(context.Background(), tc.apiKey)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedID, id)
+		})
+	}
+}
+
+func TestResolveAPIKey_SkipsLookupForForeignTokens(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTenantRepository)
+	svc := service.NewTenantService(mockRepo)
+
+	// Act
+	_, ok, err := svc.ResolveAPIKey(context.Background(), "some-other-token")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	mockRepo.AssertNotCalled(t, "FindByAPIKeyHash", mock.Anything, mock.Anything)
+}
+
+func TestRotateAPIKey_ReplacesHash(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTenantRepository)
+	svc := service.NewTenantService(mockRepo)
+
+	tenant := &repository.Tenant{ID: uuid.New(), Name: "acme", APIKeyHash: sha256Hex("tk_old"), Active: true}
+	mockRepo.On("FindByID", mock.Anything, tenant.ID).Return(tenant, nil)
+	mockRepo.On("Update", mock.Anything, tenant).Return(nil)
+
+	// Act
+	result, err := svc.RotateAPIKey(context.Background(), tenant.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEqual(t, "tk_old", result.APIKey)
+	assert.Equal(t, sha256Hex(result.APIKey), tenant.APIKeyHash)
+	mockRepo.AssertExpectations(t)
+}
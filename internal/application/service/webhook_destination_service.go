@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type WebhookDestinationService interface {
+	CreateDestination(ctx context.Context, req *dto.CreateWebhookDestinationRequest) (*dto.WebhookDestinationResponse, error)
+	GetDestination(ctx context.Context, id uuid.UUID) (*dto.WebhookDestinationResponse, error)
+	ListDestinations(ctx context.Context, page, pageSize int) (*dto.WebhookDestinationListResponse, error)
+	UpdateDestination(ctx context.Context, id uuid.UUID, req *dto.UpdateWebhookDestinationRequest) (*dto.WebhookDestinationResponse, error)
+}
+
+type webhookDestinationService struct {
+	repo    repository.WebhookDestinationRepository
+	tenants repository.TenantRepository
+}
+
+// NewWebhookDestinationService manages the destinations; changes reach the
+// senders once their cached copy is refreshed.
+func NewWebhookDestinationService(repo repository.WebhookDestinationRepository, tenants repository.TenantRepository) WebhookDestinationService {
+	return &webhookDestinationService{repo: repo, tenants: tenants}
+}
+
+func (s *webhookDestinationService) CreateDestination(ctx context.Context, req *dto.CreateWebhookDestinationRequest) (*dto.WebhookDestinationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, apperrors.NewValidationError("name is required")
+	}
+	if err := validateDestinationURL(req.URL); err != nil {
+		return nil, err
+	}
+	if req.RateLimitPerSecond < 0 {
+		return nil, apperrors.NewValidationError("rate_limit_per_second must not be negative")
+	}
+
+	var tenantID uuid.UUID
+	if req.TenantID != "" {
+		id, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return nil, apperrors.NewValidationError("tenant_id must be a UUID")
+		}
+		if _, err := s.tenants.FindByID(ctx, id); err != nil {
+			if appErr, ok := err.(*apperrors.AppError); ok && appErr.Code == apperrors.ErrorCodeNotFound {
+				return nil, apperrors.NewValidationError("tenant_id does not match a tenant")
+			}
+			return nil, err
+		}
+		tenantID = id
+	}
+
+	now := time.Now().UTC()
+	destination := &repository.WebhookDestination{
+		ID:                 uuid.New(),
+		Name:               name,
+		URL:                req.URL,
+		AuthKey:            req.AuthKey,
+		RateLimitPerSecond: req.RateLimitPerSecond,
+		TenantID:           tenantID,
+		Active:             true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	if err := s.repo.Create(ctx, destination); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("webhook destination created",
+		zap.String("destination_id", destination.ID.String()),
+		zap.String("name", destination.Name),
+	)
+
+	resp := toWebhookDestinationDTO(destination)
+	return &resp, nil
+}
+
+func (s *webhookDestinationService) GetDestination(ctx context.Context, id uuid.UUID) (*dto.WebhookDestinationResponse, error) {
+	destination, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toWebhookDestinationDTO(destination)
+	return &resp, nil
+}
+
+func (s *webhookDestinationService) ListDestinations(ctx context.Context, page, pageSize int) (*dto.WebhookDestinationListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	destinations, total, err := s.repo.List(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.WebhookDestinationResponse, len(destinations))
+	for i, destination := range destinations {
+		responses[i] = toWebhookDestinationDTO(destination)
+	}
+
+	return &dto.WebhookDestinationListResponse{
+		Destinations: responses,
+		TotalCount:   int(total),
+		Page:         page,
+		PageSize:     pageSize,
+	}, nil
+}
+
+func (s *webhookDestinationService) UpdateDestination(ctx context.Context, id uuid.UUID, req *dto.UpdateWebhookDestinationRequest) (*dto.WebhookDestinationResponse, error) {
+	destination, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, apperrors.NewValidationError("name must not be empty")
+		}
+		destination.Name = name
+	}
+	if req.URL != nil {
+		if err := validateDestinationURL(*req.URL); err != nil {
+			return nil, err
+		}
+		destination.URL = *req.URL
+	}
+	if req.AuthKey != nil {
+		destination.AuthKey = *req.AuthKey
+	}
+	if req.RateLimitPerSecond != nil {
+		if *req.RateLimitPerSecond < 0 {
+			return nil, apperrors.NewValidationError("rate_limit_per_second must not be negative")
+		}
+		destination.RateLimitPerSecond = *req.RateLimitPerSecond
+	}
+	if req.Active != nil {
+		destination.Active = *req.Active
+	}
+	destination.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.Update(ctx, destination); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("webhook destination updated",
+		zap.String("destination_id", destination.ID.String()),
+		zap.Bool("active", destination.Active),
+	)
+
+	resp := toWebhookDestinationDTO(destination)
+	return &resp, nil
+}
+
+func validateDestinationURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return apperrors.NewValidationError("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func toWebhookDestinationDTO(destination *repository.WebhookDestination) dto.WebhookDestinationResponse {
+	return dto.WebhookDestinationResponse{
+		ID:                 destination.ID.String(),
+		Name:               destination.Name,
+		URL:                destination.URL,
+		AuthKeySet:         destination.AuthKey != "",
+		RateLimitPerSecond: destination.RateLimitPerSecond,
+		TenantID:           optionalID(destination.TenantID),
+		Active:             destination.Active,
+		CreatedAt:          destination.CreatedAt,
+		UpdatedAt:          destination.UpdatedAt,
+	}
+}
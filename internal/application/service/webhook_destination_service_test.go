@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookDestinationRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDestinationRepository) Create(ctx context.Context, destination *repository.WebhookDestination) error {
+	args := m.Called(ctx, destination)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDestinationRepository) Update(ctx context.Context, destination *repository.WebhookDestination) error {
+	args := m.Called(ctx, destination)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDestinationRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.WebhookDestination, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.WebhookDestination), args.Error(1)
+}
+
+func (m *MockWebhookDestinationRepository) List(ctx context.Context, limit, offset int) ([]*repository.WebhookDestination, int64, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*repository.WebhookDestination), args.Get(1).(int64), args.Error(2)
+}
+
+func TestCreateDestination_HidesAuthKey(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWebhookDestinationRepository)
+	mockTenants := new(MockTenantRepository)
+	svc := service.NewWebhookDestinationService(mockRepo, mockTenants)
+
+	tenantID := uuid.New()
+	mockTenants.On("FindByID", mock.Anything, tenantID).Return(&repository.Tenant{ID: tenantID}, nil)
+
+	var stored *repository.WebhookDestination
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.WebhookDestination")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*repository.WebhookDestination) }).
+		Return(nil)
+
+	// Act
+	result, err := svc.CreateDestination(context.Background(), &dto.CreateWebhookDestinationRequest{
+		Name:               " acme ",
+		URL:                "https://hooks.acme.test/sms",
+		AuthKey:            "acme-key",
+		RateLimitPerSecond: 5,
+		TenantID:           tenantID.String(),
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", result.Name)
+	assert.True(t, result.AuthKeySet)
+	assert.True(t, result.Active)
+	assert.Equal(t, tenantID.String(), result.TenantID)
+	assert.Equal(t, "acme-key", stored.AuthKey)
+	assert.Equal(t, 5, stored.RateLimitPerSecond)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateDestination_Validation(t *testing.T) {
+	unknownTenant := uuid.New()
+
+	tests := []struct {
+		name string
+		req  *dto.CreateWebhookDestinationRequest
+	}{
+		{"blank name", &dto.CreateWebhookDestinationRequest{Name: " ", URL: "https://hooks.acme.test"}},
+		{"relative url", &dto.CreateWebhookDestinationRequest{Name: "acme", URL: "/sms"}},
+		{"unsupported scheme", &dto.CreateWebhookDestinationRequest{Name: "acme", URL: "ftp://hooks.acme.test"}},
+		{"negative rate limit", &dto.CreateWebhookDestinationRequest{Name: "acme", URL: "https://hooks.acme.test", RateLimitPerSecond: -1}},
+		{"malformed tenant", &dto.CreateWebhookDestinationRequest{Name: "acme", URL: "https://hooks.acme.test", TenantID: "acme"}},
+		{"unknown tenant", &dto.CreateWebhookDestinationRequest{Name: "acme", URL: "https://hooks.acme.test", TenantID: unknownTenant.String()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockWebhookDestinationRepository)
+			mockTenants := new(MockTenantRepository)
+			mockTenants.On("FindByID", mock.Anything, unknownTenant).Return(nil, apperrors.NewNotFoundError("tenant not found"))
+			svc := service.NewWebhookDestinationService(mockRepo, mockTenants)
+
+			// Act
+			result, err := svc.CreateDestination(context.Background(), tt.req)
+
+			// Assert
+			assert.Nil(t, result)
+			if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+				assert.Equal(t, apperrors.ErrorCodeValidation, appErr.Code)
+			}
+			mockRepo.AssertNotCalled(t, "Create")
+		})
+	}
+}
+
+func TestUpdateDestination_AppliesGivenFields(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWebhookDestinationRepository)
+	svc := service.NewWebhookDestinationService(mockRepo, new(MockTenantRepository))
+
+	destination := &repository.WebhookDestination{
+		ID:                 uuid.New(),
+		Name:               "acme",
+		URL:                "https://hooks.acme.test/sms",
+		AuthKey:            "old-key",
+		RateLimitPerSecond: 5,
+		Active:             true,
+	}
+	mockRepo.On("FindByID", mock.Anything, destination.ID).Return(destination, nil)
+	mockRepo.On("Update", mock.Anything, destination).Return(nil)
+
+	authKey := "new-key"
+	active := false
+
+	// Act
+	result, err := svc.UpdateDestination(context.Background(), destination.ID, &dto.UpdateWebhookDestinationRequest{
+		AuthKey: &authKey,
+		Active:  &active,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, result.Active)
+	assert.Equal(t, "acme", result.Name)
+	assert.Equal(t, "new-key", destination.AuthKey)
+	assert.Equal(t, 5, destination.RateLimitPerSecond)
+	assert.False(t, destination.UpdatedAt.IsZero())
+	mockRepo.AssertExpectations(t)
+}
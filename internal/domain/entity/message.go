@@ -1,74 +1,160 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/google/uuid"
 )
 
 type Message struct {
-	id                uuid.UUID
-	phoneNumber       *valueobject.PhoneNumber
-	content           *valueobject.MessageContent
-	status            valueobject.MessageStatus
-	createdAt         time.Time
-	sentAt            *time.Time
-	attempts          int
-	maxAttempts       int
-	lastError         string
-	errorCode         string
-	webhookMessageID  string
-	webhookResponse   string
-	version           int
+	id                  uuid.UUID
+	recipient           *valueobject.Recipient
+	content             *valueobject.MessageContent
+	contentHash         string
+	status              valueobject.MessageStatus
+	createdAt           time.Time
+	sentAt              *time.Time
+	deliveredAt         *time.Time
+	scheduledAt         *time.Time
+	nextRetryAt         *time.Time
+	processingStartedAt *time.Time
+	attempts            int
+	maxAttempts         int
+	lastError           string
+	errorCode           string
+	webhookMessageID    string
+	webhookResponse     string
+	traceID             string
+	providerRequestID   string
+	idempotencyKey      string
+	priority            valueobject.MessagePriority
+	tenantID            uuid.UUID
+	metadata            valueobject.MessageMetadata
+	campaignID          uuid.UUID
+	destinationID       uuid.UUID
+	expiresAt           *time.Time
+	part                *valueobject.MessagePart
+	createdBy           string
+	dryRun              bool
+	simulated           bool
+	callbackURL         string
+	version             int
+
+	// pendingEvents were raised since the message was last persisted
+	pendingEvents []event.Type
 }
 
+// NewMessage creates an SMS to phoneNumber.
 func NewMessage(
 	phoneNumber *valueobject.PhoneNumber,
 	content *valueobject.MessageContent,
 	maxAttempts int,
+) (*Message, error) {
+	return NewMessageWithID(uuid.New(), phoneNumber, content, maxAttempts)
+}
+
+func NewMessageWithID(
+	id uuid.UUID,
+	phoneNumber *valueobject.PhoneNumber,
+	content *valueobject.MessageContent,
+	maxAttempts int,
+) (*Message, error) {
+	return NewMessageTo(id, valueobject.NewPhoneRecipient(phoneNumber), content, maxAttempts)
+}
+
+// NewMessageTo creates a message on the recipient's channel.
+func NewMessageTo(
+	id uuid.UUID,
+	recipient *valueobject.Recipient,
+	content *valueobject.MessageContent,
+	maxAttempts int,
 ) (*Message, error) {
 	return &Message{
-		id:          uuid.New(),
-		phoneNumber: phoneNumber,
+		id:          id,
+		recipient:   recipient,
 		content:     content,
+		contentHash: content.Hash(),
 		status:      valueobject.MessageStatusPending,
 		createdAt:   time.Now().UTC(),
 		attempts:    0,
 		maxAttempts: maxAttempts,
+		priority:    valueobject.MessagePriorityNormal,
 		version:     1,
+
+		pendingEvents: []event.Type{event.TypeMessageCreated},
 	}, nil
 }
 
 func ReconstructMessage(
 	id uuid.UUID,
-	phoneNumber *valueobject.PhoneNumber,
+	recipient *valueobject.Recipient,
 	content *valueobject.MessageContent,
+	contentHash string,
 	status valueobject.MessageStatus,
 	createdAt time.Time,
 	sentAt *time.Time,
+	deliveredAt *time.Time,
+	scheduledAt *time.Time,
+	nextRetryAt *time.Time,
+	processingStartedAt *time.Time,
 	attempts int,
 	maxAttempts int,
 	lastError string,
 	errorCode string,
 	webhookMessageID string,
 	webhookResponse string,
+	traceID string,
+	providerRequestID string,
+	idempotencyKey string,
+	priority valueobject.MessagePriority,
+	tenantID uuid.UUID,
+	metadata valueobject.MessageMetadata,
+	campaignID uuid.UUID,
+	destinationID uuid.UUID,
+	expiresAt *time.Time,
+	part *valueobject.MessagePart,
+	createdBy string,
+	dryRun bool,
+	simulated bool,
+	callbackURL string,
 	version int,
 ) *Message {
 	return &Message{
-		id:               id,
-		phoneNumber:      phoneNumber,
-		content:          content,
-		status:           status,
-		createdAt:        createdAt,
-		sentAt:           sentAt,
-		attempts:         attempts,
-		maxAttempts:      maxAttempts,
-		lastError:        lastError,
-		errorCode:        errorCode,
-		webhookMessageID: webhookMessageID,
-		webhookResponse:  webhookResponse,
-		version:          version,
+		id:                  id,
+		recipient:           recipient,
+		content:             content,
+		contentHash:         contentHash,
+		status:              status,
+		createdAt:           createdAt,
+		sentAt:              sentAt,
+		deliveredAt:         deliveredAt,
+		scheduledAt:         scheduledAt,
+		nextRetryAt:         nextRetryAt,
+		processingStartedAt: processingStartedAt,
+		attempts:            attempts,
+		maxAttempts:         maxAttempts,
+		lastError:           lastError,
+		errorCode:           errorCode,
+		webhookMessageID:    webhookMessageID,
+		webhookResponse:     webhookResponse,
+		traceID:             traceID,
+		providerRequestID:   providerRequestID,
+		idempotencyKey:      idempotencyKey,
+		priority:            priority,
+		tenantID:            tenantID,
+		metadata:            metadata,
+		campaignID:          campaignID,
+		destinationID:       destinationID,
+		expiresAt:           expiresAt,
+		part:                part,
+		createdBy:           createdBy,
+		dryRun:              dryRun,
+		simulated:           simulated,
+		callbackURL:         callbackURL,
+		version:             version,
 	}
 }
 
@@ -76,14 +162,27 @@ func (m *Message) ID() uuid.UUID {
 	return m.id
 }
 
+func (m *Message) Recipient() *valueobject.Recipient {
+	return m.recipient
+}
+
+func (m *Message) Channel() valueobject.Channel {
+	return m.recipient.Channel()
+}
+
+// PhoneNumber is nil unless the message is an SMS.
 func (m *Message) PhoneNumber() *valueobject.PhoneNumber {
-	return m.phoneNumber
+	return m.recipient.PhoneNumber()
 }
 
 func (m *Message) Content() *valueobject.MessageContent {
 	return m.content
 }
 
+func (m *Message) ContentHash() string {
+	return m.contentHash
+}
+
 func (m *Message) Status() valueobject.MessageStatus {
 	return m.status
 }
@@ -96,6 +195,25 @@ func (m *Message) SentAt() *time.Time {
 	return m.sentAt
 }
 
+// DeliveredAt is the delivery time reported by the provider's receipt.
+func (m *Message) DeliveredAt() *time.Time {
+	return m.deliveredAt
+}
+
+func (m *Message) ScheduledAt() *time.Time {
+	return m.scheduledAt
+}
+
+// NextRetryAt is set while a failed attempt is backing off.
+func (m *Message) NextRetryAt() *time.Time {
+	return m.nextRetryAt
+}
+
+// ProcessingStartedAt is when the message was last claimed for sending.
+func (m *Message) ProcessingStartedAt() *time.Time {
+	return m.processingStartedAt
+}
+
 func (m *Message) Attempts() int {
 	return m.attempts
 }
@@ -120,13 +238,148 @@ func (m *Message) WebhookResponse() string {
 	return m.webhookResponse
 }
 
+func (m *Message) TraceID() string {
+	return m.traceID
+}
+
+func (m *Message) ProviderRequestID() string {
+	return m.providerRequestID
+}
+
+func (m *Message) IdempotencyKey() string {
+	return m.idempotencyKey
+}
+
+func (m *Message) Priority() valueobject.MessagePriority {
+	return m.priority
+}
+
 func (m *Message) Version() int {
 	return m.version
 }
 
+// ScheduleAt defers delivery until the given time; the scheduler ignores the
+// message until then.
+func (m *Message) ScheduleAt(at time.Time) {
+	scheduledAt := at.UTC()
+	m.scheduledAt = &scheduledAt
+}
+
+// ExpiresAt is the time after which the message is no longer worth sending,
+// nil when it never expires.
+func (m *Message) ExpiresAt() *time.Time {
+	return m.expiresAt
+}
+
+// ExpireAt sets the time after which the message is expired instead of sent.
+func (m *Message) ExpireAt(at time.Time) {
+	expiresAt := at.UTC()
+	m.expiresAt = &expiresAt
+}
+
+// IsExpiredAt reports whether the message's expiry has passed at t.
+func (m *Message) IsExpiredAt(t time.Time) bool {
+	return m.expiresAt != nil && !t.Before(*m.expiresAt)
+}
+
+// TenantID is uuid.Nil for messages created with the global API token.
+func (m *Message) TenantID() uuid.UUID {
+	return m.tenantID
+}
+
+func (m *Message) AssignTenant(tenantID uuid.UUID) {
+	m.tenantID = tenantID
+}
+
+func (m *Message) CampaignID() uuid.UUID {
+	return m.campaignID
+}
+
+func (m *Message) AssignCampaign(campaignID uuid.UUID) {
+	m.campaignID = campaignID
+}
+
+// DestinationID is the webhook destination the message is sent to, uuid.Nil
+// for messages that go through the configured sender.
+func (m *Message) DestinationID() uuid.UUID {
+	return m.destinationID
+}
+
+func (m *Message) AssignDestination(destinationID uuid.UUID) {
+	m.destinationID = destinationID
+}
+
+// Part is nil unless the message is one part of a long text that was split.
+func (m *Message) Part() *valueobject.MessagePart {
+	return m.part
+}
+
+func (m *Message) AssignPart(part *valueobject.MessagePart) {
+	m.part = part
+}
+
+// CreatedBy names the caller that created the message, such as a tenant API
+// key or a JWT subject; empty for messages created before callers were
+// recorded.
+func (m *Message) CreatedBy() string {
+	return m.createdBy
+}
+
+func (m *Message) RecordCreator(caller string) {
+	m.createdBy = caller
+}
+
+// DryRun reports whether the message was created to be simulated: it goes
+// through every step up to the provider, which is never called.
+func (m *Message) DryRun() bool {
+	return m.dryRun
+}
+
+func (m *Message) MarkDryRun() {
+	m.dryRun = true
+}
+
+// CallbackURL is where the message's final status is posted, empty when the
+// caller polls for it instead.
+func (m *Message) CallbackURL() string {
+	return m.callbackURL
+}
+
+func (m *Message) AssignCallbackURL(url string) {
+	m.callbackURL = url
+}
+
+// Simulated reports whether the send was simulated instead of handed to the
+// provider.
+func (m *Message) Simulated() bool {
+	return m.simulated
+}
+
+func (m *Message) Metadata() valueobject.MessageMetadata {
+	return m.metadata
+}
+
+func (m *Message) AssignMetadata(metadata valueobject.MessageMetadata) {
+	m.metadata = metadata
+}
+
+// AssignIdempotencyKey ties the message to a client-supplied key so that a
+// retried create returns this message instead of storing a second one.
+func (m *Message) AssignIdempotencyKey(key string) {
+	m.idempotencyKey = key
+}
+
+// AssignPriority sets the dispatch priority; higher priority messages are
+// claimed by the scheduler before older lower priority ones.
+func (m *Message) AssignPriority(priority valueobject.MessagePriority) {
+	m.priority = priority
+}
+
 func (m *Message) MarkAsProcessing() {
 	m.status = valueobject.MessageStatusProcessing
 	m.attempts++
+	now := time.Now().UTC()
+	m.processingStartedAt = &now
 }
 
 func (m *Message) MarkAsSent(webhookMessageID, webhookResponse string) {
@@ -137,23 +390,207 @@ func (m *Message) MarkAsSent(webhookMessageID, webhookResponse string) {
 	m.webhookResponse = webhookResponse
 	m.lastError = ""
 	m.errorCode = ""
+	m.nextRetryAt = nil
+	m.pendingEvents = append(m.pendingEvents, event.TypeMessageSent)
+}
+
+// MarkAsSimulated records a dry-run send as a success, flagged as simulated.
+func (m *Message) MarkAsSimulated(webhookMessageID, webhookResponse string) {
+	m.MarkAsSent(webhookMessageID, webhookResponse)
+	m.simulated = true
+}
+
+// MarkAsDelivered applies a delivered receipt from the provider. Receipts are
+// only accepted while the message is sent.
+func (m *Message) MarkAsDelivered(deliveredAt time.Time) error {
+	if !m.status.IsSent() {
+		return fmt.Errorf("cannot mark message in status %s as delivered", m.status)
+	}
+	at := deliveredAt.UTC()
+	m.status = valueobject.MessageStatusDelivered
+	m.deliveredAt = &at
+	m.pendingEvents = append(m.pendingEvents, event.TypeMessageDelivered)
+	return nil
+}
+
+// MarkAsUndelivered applies an undelivered or rejected receipt. The message is
+// not retried: the provider already accepted it once.
+func (m *Message) MarkAsUndelivered(reason, errorCode string) error {
+	if !m.status.IsSent() {
+		return fmt.Errorf("cannot mark message in status %s as undelivered", m.status)
+	}
+	m.status = valueobject.MessageStatusUndelivered
+	m.lastError = reason
+	m.errorCode = errorCode
+	m.pendingEvents = append(m.pendingEvents, event.TypeMessageUndelivered)
+	return nil
+}
+
+func (m *Message) RecordTrace(traceID, providerRequestID string) {
+	m.traceID = traceID
+	m.providerRequestID = providerRequestID
 }
 
 func (m *Message) MarkAsFailed(errorMsg, errorCode string) {
 	m.lastError = errorMsg
 	m.errorCode = errorCode
+	m.nextRetryAt = nil
 
 	if m.attempts >= m.maxAttempts {
 		m.status = valueobject.MessageStatusFailed
 	} else {
 		m.status = valueobject.MessageStatusPending
 	}
+	m.pendingEvents = append(m.pendingEvents, event.TypeMessageFailed)
+}
+
+// FailPermanently records an attempt that failed in a way another attempt
+// would repeat, such as an invalid recipient. The message fails without
+// using its remaining attempts.
+func (m *Message) FailPermanently(errorMsg, errorCode string) {
+	m.MarkAsFailed(errorMsg, errorCode)
+	m.status = valueobject.MessageStatusFailed
+}
+
+// ReleaseStale takes back a claim whose worker never reported an outcome.
+// The message returns to pending while it has attempts left and fails
+// otherwise, as if the attempt had failed.
+func (m *Message) ReleaseStale(reason, errorCode string) error {
+	if !m.status.IsProcessing() {
+		return fmt.Errorf("cannot release message in status %s", m.status)
+	}
+	m.MarkAsFailed(reason, errorCode)
+	return nil
+}
+
+// Unclaim hands a claimed message back to the queue without counting the
+// attempt, for when it was never sent.
+func (m *Message) Unclaim() error {
+	if !m.status.IsProcessing() {
+		return fmt.Errorf("cannot unclaim message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusPending
+	m.attempts--
+	m.processingStartedAt = nil
+	return nil
+}
+
+// DeferRetry holds a message that went back to pending after a failed
+// attempt until the backoff delay has passed. It is a no-op otherwise.
+func (m *Message) DeferRetry(delay time.Duration) {
+	if !m.status.IsPending() || delay <= 0 {
+		return
+	}
+	nextRetryAt := time.Now().UTC().Add(delay)
+	m.nextRetryAt = &nextRetryAt
+}
+
+// MarkAsCancelled withdraws a message that has not been picked up yet. Once
+// the scheduler has claimed it the send can no longer be stopped.
+func (m *Message) MarkAsCancelled() error {
+	if !m.status.IsPending() && !m.status.IsPaused() {
+		return fmt.Errorf("cannot cancel message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusCancelled
+	return nil
+}
+
+// Pause keeps a pending message out of the queue until it is resumed. Like
+// cancelling, it is refused once the scheduler has claimed the message.
+func (m *Message) Pause() error {
+	if !m.status.IsPending() {
+		return fmt.Errorf("cannot pause message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusPaused
+	return nil
+}
+
+// Resume puts a paused message back in the pending queue. Its schedule and
+// retry backoff still apply.
+func (m *Message) Resume() error {
+	if !m.status.IsPaused() {
+		return fmt.Errorf("cannot resume message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusPending
+	return nil
+}
+
+// Quarantine holds a new message back instead of queueing it. It stays
+// quarantined until an operator requeues it.
+func (m *Message) Quarantine(reason, errorCode string) error {
+	if !m.status.IsPending() {
+		return fmt.Errorf("cannot quarantine message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusQuarantined
+	m.lastError = reason
+	m.errorCode = errorCode
+	return nil
+}
+
+// Block stops a message to a blacklisted recipient, either at creation or
+// when the scheduler picks it up. It is only sent if an operator requeues it
+// after the number was removed from the blacklist.
+func (m *Message) Block(reason, errorCode string) error {
+	if !m.status.IsPending() {
+		return fmt.Errorf("cannot block message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusBlocked
+	m.lastError = reason
+	m.errorCode = errorCode
+	m.nextRetryAt = nil
+	return nil
+}
+
+// Expire ends a pending message whose expiry has passed without sending it.
+// An expired message cannot be requeued.
+func (m *Message) Expire(reason, errorCode string) error {
+	if !m.status.IsPending() {
+		return fmt.Errorf("cannot expire message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusExpired
+	m.lastError = reason
+	m.errorCode = errorCode
+	m.nextRetryAt = nil
+	m.pendingEvents = append(m.pendingEvents, event.TypeMessageExpired)
+	return nil
+}
+
+// Requeue puts a failed, quarantined or blocked message back in the queue.
+// Without resetAttempts the attempt counter is kept, so a failed message gets
+// exactly one more try.
+func (m *Message) Requeue(resetAttempts bool) error {
+	if !m.status.IsFailed() && !m.status.IsQuarantined() && !m.status.IsBlocked() {
+		return fmt.Errorf("cannot retry message in status %s", m.status)
+	}
+	m.status = valueobject.MessageStatusPending
+	m.lastError = ""
+	m.errorCode = ""
+	m.nextRetryAt = nil
+	if resetAttempts {
+		m.attempts = 0
+	}
+	return nil
+}
+
+func (m *Message) VerifyContentIntegrity() bool {
+	return m.contentHash == m.content.Hash()
 }
 
 func (m *Message) CanRetry() bool {
-	return m.attempts < m.maxAttempts && !m.status.IsSent()
+	return m.attempts < m.maxAttempts && !m.status.WasSent() && !m.status.IsCancelled() && !m.status.IsExpired()
 }
 
 func (m *Message) IncrementVersion() {
 	m.version++
 }
+
+// PendingEvents lists the lifecycle events raised since the message was last
+// persisted, oldest first. Repositories with an outbox store them in the same
+// transaction as the state change.
+func (m *Message) PendingEvents() []event.Type {
+	return m.pendingEvents
+}
+
+func (m *Message) ClearPendingEvents() {
+	m.pendingEvents = nil
+}
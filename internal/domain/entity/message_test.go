@@ -2,8 +2,11 @@ package entity
 
 import (
 	"testing"
+	"time"
 
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,6 +72,20 @@ func TestMessageMarkAsFailed(t *testing.T) {
 	assert.Equal(t, 3, message.Attempts())
 }
 
+func TestMessage_FailPermanently(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	message.MarkAsProcessing()
+	message.FailPermanently("invalid number", "INVALID_RECIPIENT")
+
+	assert.Equal(t, valueobject.MessageStatusFailed, message.Status())
+	assert.Equal(t, 1, message.Attempts())
+	assert.Equal(t, "INVALID_RECIPIENT", message.ErrorCode())
+	assert.Nil(t, message.NextRetryAt())
+}
+
 func TestMessageCanRetry(t *testing.T) {
 	phone, _ := valueobject.NewPhoneNumber("+905551234567")
 	content, _ := valueobject.NewMessageContent("Test message", 160)
@@ -85,3 +102,235 @@ func TestMessageCanRetry(t *testing.T) {
 	message.MarkAsSent("webhook-123", "{}")
 	assert.False(t, message.CanRetry())
 }
+
+func TestMessageMarkAsCancelled(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	err := message.MarkAsCancelled()
+
+	assert.NoError(t, err)
+	assert.Equal(t, valueobject.MessageStatusCancelled, message.Status())
+	assert.False(t, message.CanRetry())
+	assert.Error(t, message.MarkAsCancelled(), "already cancelled")
+}
+
+func TestMessageMarkAsCancelled_NotPending(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+
+	processing, _ := NewMessage(phone, content, 3)
+	processing.MarkAsProcessing()
+	assert.Error(t, processing.MarkAsCancelled())
+	assert.Equal(t, valueobject.MessageStatusProcessing, processing.Status())
+
+	sent, _ := NewMessage(phone, content, 3)
+	sent.MarkAsSent("webhook-123", "{}")
+	assert.Error(t, sent.MarkAsCancelled())
+	assert.Equal(t, valueobject.MessageStatusSent, sent.Status())
+}
+
+func TestMessageRequeue(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := NewMessage(phone, content, 1)
+
+	assert.Error(t, message.Requeue(false), "pending messages cannot be retried")
+
+	message.MarkAsProcessing()
+	message.MarkAsFailed("timeout", "TIMEOUT")
+	assert.Equal(t, valueobject.MessageStatusFailed, message.Status())
+
+	assert.NoError(t, message.Requeue(false))
+	assert.Equal(t, valueobject.MessageStatusPending, message.Status())
+	assert.Equal(t, 1, message.Attempts())
+	assert.Empty(t, message.LastError())
+	assert.Empty(t, message.ErrorCode())
+
+	message.MarkAsProcessing()
+	message.MarkAsFailed("timeout", "TIMEOUT")
+	assert.Equal(t, valueobject.MessageStatusFailed, message.Status(), "one extra attempt only")
+
+	assert.NoError(t, message.Requeue(true))
+	assert.Equal(t, 0, message.Attempts())
+	assert.True(t, message.CanRetry())
+}
+
+func TestMessageVerifyContentIntegrity(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test message", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	assert.Equal(t, content.Hash(), message.ContentHash())
+	assert.True(t, message.VerifyContentIntegrity())
+
+	tampered := ReconstructMessage(
+		message.ID(), valueobject.NewPhoneRecipient(phone), content, valueobject.HashContent("Other message"),
+		message.Status(), message.CreatedAt(), nil, nil, nil, nil, nil, 0, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 1,
+	)
+	assert.False(t, tampered.VerifyContentIntegrity())
+}
+
+func TestMessage_DeferRetryOnlyWhilePending(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 2)
+
+	message.MarkAsProcessing()
+	message.MarkAsFailed("timeout", "TIMEOUT")
+	message.DeferRetry(time.Minute)
+	assert.NotNil(t, message.NextRetryAt())
+
+	message.MarkAsProcessing()
+	message.MarkAsFailed("timeout", "TIMEOUT")
+	message.DeferRetry(time.Minute)
+	assert.True(t, message.Status().IsFailed())
+	assert.Nil(t, message.NextRetryAt())
+}
+
+func TestMessage_ReleaseStale(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 2)
+
+	assert.Error(t, message.ReleaseStale("stale", "STALE_CLAIM"), "pending messages are not claimed")
+	assert.Nil(t, message.ProcessingStartedAt())
+
+	message.MarkAsProcessing()
+	assert.NotNil(t, message.ProcessingStartedAt())
+
+	assert.NoError(t, message.ReleaseStale("stale", "STALE_CLAIM"))
+	assert.True(t, message.Status().IsPending())
+	assert.Equal(t, "STALE_CLAIM", message.ErrorCode())
+
+	message.MarkAsProcessing()
+	assert.NoError(t, message.ReleaseStale("stale", "STALE_CLAIM"))
+	assert.True(t, message.Status().IsFailed(), "no attempts left")
+}
+
+func TestMessage_QuarantineAndRelease(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+12025550123")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	assert.NoError(t, message.Quarantine("sending to country US is not allowed", "DESTINATION_BLOCKED"))
+	assert.True(t, message.Status().IsQuarantined())
+	assert.Equal(t, "DESTINATION_BLOCKED", message.ErrorCode())
+	assert.Error(t, message.Quarantine("again", "DESTINATION_BLOCKED"))
+
+	assert.NoError(t, message.Requeue(false))
+	assert.True(t, message.Status().IsPending())
+	assert.Empty(t, message.ErrorCode())
+}
+
+func TestMessage_BlockAndRequeue(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	assert.NoError(t, message.Block("recipient is on the blacklist", "RECIPIENT_BLOCKED"))
+	assert.True(t, message.Status().IsBlocked())
+	assert.Equal(t, "RECIPIENT_BLOCKED", message.ErrorCode())
+	assert.Error(t, message.Block("again", "RECIPIENT_BLOCKED"))
+
+	assert.NoError(t, message.Requeue(false))
+	assert.True(t, message.Status().IsPending())
+	assert.Empty(t, message.ErrorCode())
+
+	message.MarkAsProcessing()
+	assert.Error(t, message.Block("too late", "RECIPIENT_BLOCKED"))
+}
+
+func TestMessage_Expire(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Your code is 123456", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	expiresAt := time.Now().Add(time.Minute)
+	message.ExpireAt(expiresAt)
+	assert.False(t, message.IsExpiredAt(time.Now()))
+	assert.True(t, message.IsExpiredAt(expiresAt))
+
+	assert.NoError(t, message.Expire("message expired before it was sent", "MESSAGE_EXPIRED"))
+	assert.True(t, message.Status().IsExpired())
+	assert.True(t, message.Status().IsTerminal())
+	assert.Equal(t, "MESSAGE_EXPIRED", message.ErrorCode())
+	assert.Contains(t, message.PendingEvents(), event.TypeMessageExpired)
+	assert.False(t, message.CanRetry())
+	assert.Error(t, message.Requeue(true))
+	assert.Error(t, message.Expire("again", "MESSAGE_EXPIRED"))
+}
+
+func TestMessage_PauseAndResume(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 3)
+
+	assert.Error(t, message.Resume(), "pending messages are not paused")
+	assert.NoError(t, message.Pause())
+	assert.True(t, message.Status().IsPaused())
+	assert.Error(t, message.Pause(), "already paused")
+
+	assert.NoError(t, message.Resume())
+	assert.True(t, message.Status().IsPending())
+
+	message.MarkAsProcessing()
+	assert.Error(t, message.Pause())
+	assert.Equal(t, valueobject.MessageStatusProcessing, message.Status())
+
+	paused, _ := NewMessage(phone, content, 3)
+	assert.NoError(t, paused.Pause())
+	assert.NoError(t, paused.MarkAsCancelled())
+}
+
+func TestMessage_RaisesLifecycleEvents(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+	message, _ := NewMessage(phone, content, 2)
+
+	assert.Equal(t, []event.Type{event.TypeMessageCreated}, message.PendingEvents())
+	message.ClearPendingEvents()
+
+	message.MarkAsProcessing()
+	message.MarkAsFailed("timeout", "TIMEOUT")
+	message.MarkAsProcessing()
+	message.MarkAsSent("webhook-123", "{}")
+
+	assert.Equal(t, []event.Type{event.TypeMessageFailed, event.TypeMessageSent}, message.PendingEvents())
+
+	reconstructed := ReconstructMessage(
+		message.ID(), valueobject.NewPhoneRecipient(phone), content, message.ContentHash(),
+		message.Status(), message.CreatedAt(), nil, nil, nil, nil, nil, 0, 3, "", "", "", "", "", "", "", valueobject.MessagePriorityNormal, uuid.Nil, nil, uuid.Nil, uuid.Nil, nil, nil, "", false, false, "", 1,
+	)
+	assert.Empty(t, reconstructed.PendingEvents())
+}
+
+func TestMessage_DeliveryReceipts(t *testing.T) {
+	phone, _ := valueobject.NewPhoneNumber("+905551234567")
+	content, _ := valueobject.NewMessageContent("Test", 160)
+
+	pending, _ := NewMessage(phone, content, 3)
+	assert.Error(t, pending.MarkAsDelivered(time.Now()))
+
+	delivered, _ := NewMessage(phone, content, 3)
+	delivered.MarkAsProcessing()
+	delivered.MarkAsSent("webhook-123", "{}")
+	delivered.ClearPendingEvents()
+
+	assert.NoError(t, delivered.MarkAsDelivered(time.Now()))
+	assert.True(t, delivered.Status().IsDelivered())
+	assert.NotNil(t, delivered.DeliveredAt())
+	assert.False(t, delivered.CanRetry())
+	assert.Equal(t, []event.Type{event.TypeMessageDelivered}, delivered.PendingEvents())
+	assert.Error(t, delivered.MarkAsUndelivered("unreachable", "UNDELIVERED"))
+
+	undelivered, _ := NewMessage(phone, content, 3)
+	undelivered.MarkAsProcessing()
+	undelivered.MarkAsSent("webhook-456", "{}")
+
+	assert.NoError(t, undelivered.MarkAsUndelivered("unreachable", "UNDELIVERED"))
+	assert.True(t, undelivered.Status().IsUndelivered())
+	assert.Equal(t, "UNDELIVERED", undelivered.ErrorCode())
+	assert.False(t, undelivered.CanRetry())
+}
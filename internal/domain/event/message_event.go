@@ -0,0 +1,39 @@
+package event
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	TypeMessageCreated       Type = "message.created"
+	TypeMessageStatusChanged Type = "message.status_changed"
+
+	// Lifecycle events raised by the message entity and published through
+	// the outbox
+	TypeMessageSent        Type = "message.sent"
+	TypeMessageFailed      Type = "message.failed"
+	TypeMessageDelivered   Type = "message.delivered"
+	TypeMessageUndelivered Type = "message.undelivered"
+	TypeMessageExpired     Type = "message.expired"
+)
+
+type MessageEvent struct {
+	Type       Type
+	MessageID  uuid.UUID
+	Status     valueobject.MessageStatus
+	OccurredAt time.Time
+}
+
+func NewMessageEvent(eventType Type, messageID uuid.UUID, status valueobject.MessageStatus) MessageEvent {
+	return MessageEvent{
+		Type:       eventType,
+		MessageID:  messageID,
+		Status:     status,
+		OccurredAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,49 @@
+package provider
+
+import "context"
+
+// maxRefusalBody bounds the part of a refusal's body kept for mapping it
+const maxRefusalBody = 4 << 10
+
+// ResponseStatus holds the HTTP status of the last response a provider got
+// while sending one message, for the message's attempt history. When the
+// provider refused the message, ErrorCode and Body hold its own error code,
+// if it gave one, and its answer, for the provider error mappings.
+type ResponseStatus struct {
+	Code      int
+	Refused   bool
+	ErrorCode string
+	Body      string
+}
+
+type responseStatusKey struct{}
+
+// WithResponseStatus returns a ctx on which providers record the status of
+// their responses into the returned ResponseStatus.
+func WithResponseStatus(ctx context.Context) (context.Context, *ResponseStatus) {
+	status := &ResponseStatus{}
+	return context.WithValue(ctx, responseStatusKey{}, status), status
+}
+
+// RecordResponseStatus notes a response with status code on ctx. It does
+// nothing when nobody asked for it.
+func RecordResponseStatus(ctx context.Context, code int) {
+	if status, ok := ctx.Value(responseStatusKey{}).(*ResponseStatus); ok {
+		status.Code = code
+	}
+}
+
+// RecordRefusal notes on ctx that the provider refused the message, with
+// its own error code, empty when it has none, and the body of its answer.
+func RecordRefusal(ctx context.Context, errorCode string, body []byte) {
+	status, ok := ctx.Value(responseStatusKey{}).(*ResponseStatus)
+	if !ok {
+		return
+	}
+	if len(body) > maxRefusalBody {
+		body = body[:maxRefusalBody]
+	}
+	status.Refused = true
+	status.ErrorCode = errorCode
+	status.Body = string(body)
+}
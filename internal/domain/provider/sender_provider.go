@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
+)
+
+// SendResult describes a message the provider accepted for delivery.
+type SendResult struct {
+	// MessageID is the provider's identifier for the message
+	MessageID string
+	// Message is the provider's acceptance status, e.g. "Accepted" or "queued"
+	Message string
+
+	RequestID         string
+	ProviderRequestID string
+	// Response is the provider's response body when it answered with JSON;
+	// it is stored with the message
+	Response []byte
+}
+
+// SenderProvider delivers a message through an external channel such as a
+// generic webhook, Twilio or AWS SNS. recipient is a phone number for SMS
+// providers, and an email address or device token for the others. Failures
+// are *apperrors.AppError values whose code tells the caller whether retrying
+// could help. Channels that cannot carry the client's metadata ignore it.
+type SenderProvider interface {
+	Name() string
+	SendMessage(ctx context.Context, recipient, content string, metadata map[string]string) (*SendResult, error)
+}
+
+// QuotaExhaustedError reports that a send was refused because the provider's
+// daily quota is used up. Nothing reached the provider; sending can resume at
+// ResetAt.
+type QuotaExhaustedError struct {
+	ResetAt time.Time
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	return "daily quota used up until " + e.ResetAt.Format(time.RFC3339)
+}
+
+// ChannelProviders holds the providers of the channels besides SMS. A channel
+// without one cannot be used.
+type ChannelProviders map[valueobject.Channel]SenderProvider
+
+// DestinationSenders hands out the senders of the webhook destinations
+// configured in the database, each with its own rate limit.
+type DestinationSenders interface {
+	// SenderFor fails with DESTINATION_UNAVAILABLE when the destination does
+	// not exist, is inactive or belongs to a tenant other than tenantID.
+	SenderFor(ctx context.Context, destinationID, tenantID uuid.UUID, channel valueobject.Channel) (SenderProvider, error)
+}
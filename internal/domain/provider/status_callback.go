@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusCallback is the final status of a message, posted to the callback
+// URL it was created with. It is the JSON body of the callback request.
+type StatusCallback struct {
+	URL     string `json:"-"`
+	TraceID string `json:"-"`
+
+	MessageID        uuid.UUID `json:"message_id"`
+	Status           string    `json:"status"`
+	ClientReference  string    `json:"client_reference,omitempty"`
+	Attempts         int       `json:"attempts"`
+	WebhookMessageID string    `json:"webhook_message_id,omitempty"`
+	ErrorCode        string    `json:"error_code,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	OccurredAt       time.Time `json:"occurred_at"`
+}
+
+// CallbackNotifier delivers status callbacks in the background; Notify must
+// not block on the callback URL. CheckURL reports why a callback URL would
+// not be posted to, such as it resolving to an internal address.
+type CallbackNotifier interface {
+	CheckURL(ctx context.Context, rawURL string) error
+	Notify(ctx context.Context, callback *StatusCallback)
+}
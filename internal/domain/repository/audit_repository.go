@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry records one write: who made it, on which request, and the
+// status change it caused, if any.
+type AuditEntry struct {
+	ID           uuid.UUID
+	Action       string
+	ResourceType string
+	ResourceID   string
+	TenantID     uuid.UUID
+	Actor        string
+	RequestID    string
+	FromStatus   string
+	ToStatus     string
+	Details      string
+	OccurredAt   time.Time
+}
+
+type AuditFilter struct {
+	Action       string
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	RequestID    string
+	After        *time.Time
+	Before       *time.Time
+}
+
+type AuditRepository interface {
+	Add(ctx context.Context, entry *AuditEntry) error
+	// List returns matching entries, newest first.
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEntry, int64, error)
+}
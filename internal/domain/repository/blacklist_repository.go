@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlacklistEntry opts a phone number out of messages. An entry with a nil
+// TenantID was created with the global API token and applies to every
+// tenant; a tenant's entry only blocks that tenant's messages.
+type BlacklistEntry struct {
+	ID          uuid.UUID
+	PhoneNumber string
+	TenantID    uuid.UUID
+	Reason      string
+	CreatedAt   time.Time
+}
+
+type BlacklistRepository interface {
+	Create(ctx context.Context, entry *BlacklistEntry) error
+	Delete(ctx context.Context, id uuid.UUID) (*BlacklistEntry, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*BlacklistEntry, error)
+	List(ctx context.Context, phoneNumber string, limit, offset int) ([]*BlacklistEntry, int64, error)
+	// FindTenantsBlocking returns the tenants that blacklisted phoneNumber,
+	// with uuid.Nil standing for a global entry. It ignores the tenant in
+	// ctx, so the result can be cached for every tenant.
+	FindTenantsBlocking(ctx context.Context, phoneNumber string) ([]uuid.UUID, error)
+}
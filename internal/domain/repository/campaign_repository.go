@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CampaignStatusActive = "active"
+	CampaignStatusPaused = "paused"
+)
+
+// Campaign groups messages created together so they can be tracked and
+// paused as one. Pending messages of a paused campaign are not dispatched.
+type Campaign struct {
+	ID        uuid.UUID
+	Name      string
+	Status    string
+	TenantID  uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type CampaignRepository interface {
+	Create(ctx context.Context, campaign *Campaign) error
+	Update(ctx context.Context, campaign *Campaign) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Campaign, error)
+	List(ctx context.Context, limit, offset int) ([]*Campaign, int64, error)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Contact is a recipient in the directory. Each address is optional, but a
+// contact has at least one; messages to a group go to the address of their
+// channel.
+type Contact struct {
+	ID          uuid.UUID
+	Name        string
+	PhoneNumber string
+	Email       string
+	DeviceToken string
+	TenantID    uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ContactGroup is a named list of contacts. MemberCount is filled in by
+// FindByID and List.
+type ContactGroup struct {
+	ID          uuid.UUID
+	Name        string
+	TenantID    uuid.UUID
+	MemberCount int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type ContactRepository interface {
+	Create(ctx context.Context, contact *Contact) error
+	Update(ctx context.Context, contact *Contact) error
+	// Delete removes the contact from every group it is in as well
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Contact, error)
+	// FindByIDs returns the contacts among ids that exist; the others are
+	// left out
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*Contact, error)
+	List(ctx context.Context, limit, offset int) ([]*Contact, int64, error)
+}
+
+type ContactGroupRepository interface {
+	Create(ctx context.Context, group *ContactGroup) error
+	Update(ctx context.Context, group *ContactGroup) error
+	// Delete removes the group and its memberships, not the contacts
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*ContactGroup, error)
+	List(ctx context.Context, limit, offset int) ([]*ContactGroup, int64, error)
+	// AddMembers adds the contacts to the group and returns how many were
+	// added; contacts already in it are skipped
+	AddMembers(ctx context.Context, groupID uuid.UUID, contactIDs []uuid.UUID) (int64, error)
+	RemoveMember(ctx context.Context, groupID, contactID uuid.UUID) error
+	// ListMembers pages through the group's contacts in the order they were
+	// added
+	ListMembers(ctx context.Context, groupID uuid.UUID, limit, offset int) ([]*Contact, int64, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeadLetter is a snapshot of a message taken when it exhausted its
+// attempts, kept for inspection and replay.
+type DeadLetter struct {
+	MessageID         uuid.UUID
+	PhoneNumber       string
+	Content           string
+	Attempts          int
+	LastError         string
+	ErrorCode         string
+	TraceID           string
+	ProviderRequestID string
+	MessageCreatedAt  time.Time
+	DeadLetteredAt    time.Time
+}
+
+type DeadLetterRepository interface {
+	// Add records the message, replacing an earlier entry if it had been
+	// requeued and failed again.
+	Add(ctx context.Context, entry *DeadLetter) error
+	// List returns entries whose message is still failed, newest first.
+	List(ctx context.Context, limit, offset int) ([]*DeadLetter, int64, error)
+	Remove(ctx context.Context, messageID uuid.UUID) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type Lease struct {
+	Name            string
+	HolderID        string
+	HolderRegion    string
+	PreferredRegion string
+	Epoch           int64
+	AcquiredAt      time.Time
+	RenewedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+type LeaseRepository interface {
+	// TryAcquire takes or renews the lease for holderID. A lease held by
+	// someone else can only be taken once it has been expired for at least
+	// takeoverDelay. The returned bool reports whether holderID now holds it.
+	TryAcquire(ctx context.Context, name, holderID, region string, ttl, takeoverDelay time.Duration) (*Lease, bool, error)
+	Release(ctx context.Context, name, holderID string) error
+	Get(ctx context.Context, name string) (*Lease, error)
+	SetPreferredRegion(ctx context.Context, name, region string) error
+}
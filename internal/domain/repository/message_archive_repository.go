@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageArchive records one archive file. FirstCreatedAt and LastCreatedAt
+// are the creation times of the oldest and newest message in it.
+type MessageArchive struct {
+	ID             uuid.UUID
+	ObjectKey      string
+	Format         string
+	MessageCount   int
+	SizeBytes      int64
+	FirstCreatedAt time.Time
+	LastCreatedAt  time.Time
+	ArchivedAt     time.Time
+}
+
+// MessageArchiveFilter selects archives holding messages created in
+// [CreatedAfter, CreatedBefore); a nil bound is open.
+type MessageArchiveFilter struct {
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+type MessageArchiveRepository interface {
+	Add(ctx context.Context, archive *MessageArchive) error
+	// List returns the archives matching filter, oldest messages first.
+	List(ctx context.Context, filter MessageArchiveFilter, limit, offset int) ([]*MessageArchive, int64, error)
+}
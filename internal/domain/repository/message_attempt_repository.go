@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageAttempt records one send attempt of a message. StatusCode is the
+// HTTP status of the provider's last response, 0 when it did not answer.
+// WebhookResponse is the provider's response of a sent attempt.
+type MessageAttempt struct {
+	ID                uuid.UUID
+	MessageID         uuid.UUID
+	Attempt           int
+	StartedAt         time.Time
+	Duration          time.Duration
+	Status            string
+	StatusCode        int
+	ErrorCode         string
+	Error             string
+	WebhookResponse   string
+	ProviderRequestID string
+}
+
+type MessageAttemptRepository interface {
+	Add(ctx context.Context, attempt *MessageAttempt) error
+	// ListByMessage returns the attempts of a message, oldest first.
+	ListByMessage(ctx context.Context, messageID uuid.UUID) ([]*MessageAttempt, error)
+}
@@ -2,30 +2,218 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/google/uuid"
 )
 
 type MessageRepository interface {
 	Create(ctx context.Context, message *entity.Message) error
+	// CreateBatch stores all messages or, on any error, none of them
+	CreateBatch(ctx context.Context, messages []*entity.Message) error
 	Update(ctx context.Context, message *entity.Message) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.Message, error)
-	FindPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error)
+	FindByIdempotencyKey(ctx context.Context, key string) (*entity.Message, error)
+	FindByWebhookMessageID(ctx context.Context, webhookMessageID string) (*entity.Message, error)
+	// ClaimPendingMessages marks up to limit due messages processing, counts
+	// the attempt and returns them. The claim is committed before it returns,
+	// so no lock or connection is held while they are sent; concurrent
+	// callers never claim the same message. A ctx from WithClaimPriorities
+	// claims only messages of those priorities.
+	ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error)
+	FindStaleProcessing(ctx context.Context, startedBefore time.Time, limit int) ([]*entity.Message, error)
 	FindSentMessages(ctx context.Context, limit, offset int) ([]*entity.Message, error)
+	FindByFilter(ctx context.Context, filter MessageFilter, limit, offset int) ([]*entity.Message, int64, error)
+	// FindByFilterAfter pages through filtered messages oldest first. Pass
+	// nil to start and the cursor of the last message to continue; unlike an
+	// offset, this stays cheap and stable while new messages are created.
+	FindByFilterAfter(ctx context.Context, filter MessageFilter, after *MessageCursor, limit int) ([]*entity.Message, error)
+	// SearchContent finds messages whose content matches q, best match
+	// first, along with the number of matches.
+	SearchContent(ctx context.Context, q *valueobject.SearchQuery, limit, offset int) ([]*entity.Message, int64, error)
 	GetStats(ctx context.Context) (*MessageStats, error)
-	BeginTx(ctx context.Context) (Transaction, error)
+	GetCampaignStats(ctx context.Context, campaignID uuid.UUID) (*MessageStats, error)
+	// GetStatsByCaller counts messages by status for each caller that
+	// created them, busiest caller first.
+	GetStatsByCaller(ctx context.Context) ([]CallerStats, error)
+	// GetSendPerformance aggregates the attempts and time to send of the
+	// sent messages, and counts the messages sent or failed since since.
+	GetSendPerformance(ctx context.Context, since time.Time) (*SendPerformance, error)
+	// GetStatsTimeSeries counts the messages created, sent and failed in
+	// [from, to) per UTC hour or day, each on its own time, along with the
+	// error codes of the messages that last failed in the range.
+	GetStatsTimeSeries(ctx context.Context, bucket StatsBucket, from, to time.Time) (*MessageTimeSeries, error)
+	// FindDuplicateContent groups the messages created in [from, to) by
+	// recipient and content, and returns up to limit groups of at least
+	// minCount messages, largest first.
+	FindDuplicateContent(ctx context.Context, from, to time.Time, minCount, limit int) ([]DuplicateContent, error)
+	// GetDeliveryByPrefix groups the SMS messages created in [from, to) by
+	// the first prefixLength characters of their number.
+	GetDeliveryByPrefix(ctx context.Context, from, to time.Time, prefixLength int) ([]PrefixDelivery, error)
+	RequeueFailed(ctx context.Context, filter FailedMessageFilter, resetAttempts bool) (int64, error)
+	// SoftDeleteOlderThan hides up to limit messages with status created
+	// before createdBefore from every read, across all tenants, and returns
+	// how many it hid.
+	SoftDeleteOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error)
+	// PurgeOlderThan removes the same messages for good, including ones
+	// already soft-deleted.
+	PurgeOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error)
+	// FindArchivable returns up to limit messages in any of statuses created
+	// before createdBefore, soft-deleted ones included, across all tenants,
+	// oldest first.
+	FindArchivable(ctx context.Context, statuses []string, createdBefore time.Time, limit int) ([]*entity.Message, error)
+	// PurgeByIDs removes the given messages for good and returns how many it
+	// removed.
+	PurgeByIDs(ctx context.Context, ids []uuid.UUID) (int64, error)
 }
 
-type Transaction interface {
-	Commit() error
-	Rollback() error
-	GetContext() context.Context
+// MessageFilter selects messages for listing; zero-valued fields match all.
+// CreatedAfter is inclusive, CreatedBefore exclusive.
+type MessageFilter struct {
+	Status        string
+	Channel       string
+	PhoneNumber   string
+	ErrorCode     string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Metadata matches messages whose metadata has every given key/value
+	Metadata   map[string]string
+	CampaignID uuid.UUID
+	// SplitGroupID matches the parts of one split message
+	SplitGroupID uuid.UUID
+	// ProviderResponse matches messages whose stored provider response has
+	// every given top-level field with the given string value
+	ProviderResponse map[string]string
+}
+
+// MessageCursor is a position in creation order, ties broken by ID.
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorOf returns the position of message.
+func CursorOf(message *entity.Message) *MessageCursor {
+	return &MessageCursor{CreatedAt: message.CreatedAt(), ID: message.ID()}
+}
+
+// FailedMessageFilter narrows a bulk retry; zero-valued fields match all.
+type FailedMessageFilter struct {
+	ErrorCode     string
+	PhoneNumber   string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
 }
 
 type MessageStats struct {
-	TotalMessages   int64
-	PendingMessages int64
-	SentMessages    int64
-	FailedMessages  int64
+	TotalMessages       int64
+	PendingMessages     int64
+	SentMessages        int64
+	FailedMessages      int64
+	CancelledMessages   int64
+	DeliveredMessages   int64
+	UndeliveredMessages int64
+	QuarantinedMessages int64
+	PausedMessages      int64
+	BlockedMessages     int64
+	ExpiredMessages     int64
+}
+
+// SendPerformance sums up how sending went. Sent messages include delivered
+// and undelivered ones; their time to send runs from created_at to sent_at.
+// RecentSent and RecentFailed only count messages sent, or failed, since
+// the time asked for; a message failed when its last attempt started.
+type SendPerformance struct {
+	SentMessages  int64
+	AvgAttempts   float64
+	AvgTimeToSend time.Duration
+	P95TimeToSend time.Duration
+	RecentSent    int64
+	RecentFailed  int64
+}
+
+// CallerStats counts the messages one caller created. Caller is empty for
+// messages created before callers were recorded.
+type CallerStats struct {
+	Caller string
+	MessageStats
+}
+
+// StatsBucket is the width of a time series bucket.
+type StatsBucket string
+
+const (
+	StatsBucketHour StatsBucket = "hour"
+	StatsBucketDay  StatsBucket = "day"
+)
+
+// Duration is the length of one bucket.
+func (b StatsBucket) Duration() time.Duration {
+	if b == StatsBucketDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// MessageTimeSeries holds only the buckets that have messages, oldest first,
+// and the error codes by how often they occur, most frequent first.
+type MessageTimeSeries struct {
+	Buckets    []MessageStatsBucket
+	ErrorCodes []ErrorCodeCount
+}
+
+// MessageStatsBucket counts the messages created, sent and failed in the
+// bucket starting at Start. Sent includes messages that were delivered or
+// undelivered since; a message failed when its last attempt started.
+type MessageStatsBucket struct {
+	Start   time.Time
+	Created int64
+	Sent    int64
+	Failed  int64
+}
+
+type ErrorCodeCount struct {
+	ErrorCode string
+	Count     int64
+}
+
+// DuplicateContent is the same content created several times for one
+// recipient.
+type DuplicateContent struct {
+	Channel        string
+	Recipient      string
+	ContentHash    string
+	Content        string
+	Count          int64
+	FirstCreatedAt time.Time
+	LastCreatedAt  time.Time
+}
+
+// PrefixDelivery counts the messages to numbers starting with Prefix. Sent
+// includes delivered messages and Failed undelivered ones. Sample is one of
+// the numbers.
+type PrefixDelivery struct {
+	Prefix    string
+	Sample    string
+	Total     int64
+	Sent      int64
+	Delivered int64
+	Failed    int64
+}
+
+type claimPrioritiesKey struct{}
+
+// WithClaimPriorities limits ClaimPendingMessages on ctx to messages of the
+// given priorities, for a scheduler lane that sends only those.
+func WithClaimPriorities(ctx context.Context, priorities []valueobject.MessagePriority) context.Context {
+	return context.WithValue(ctx, claimPrioritiesKey{}, priorities)
+}
+
+// ClaimPriorities returns the priorities set with WithClaimPriorities, or
+// nil when messages of any priority may be claimed.
+func ClaimPriorities(ctx context.Context) []valueobject.MessagePriority {
+	priorities, _ := ctx.Value(claimPrioritiesKey{}).([]valueobject.MessagePriority)
+	return priorities
 }
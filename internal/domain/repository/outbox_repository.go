@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a lifecycle event stored in the same transaction as the
+// message change that raised it, waiting to be published to the broker.
+type OutboxEvent struct {
+	ID          uuid.UUID
+	AggregateID uuid.UUID
+	TenantID    uuid.UUID
+	Type        string
+	Payload     []byte
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
+
+type OutboxRepository interface {
+	// Relay hands up to limit unpublished events, oldest first, to publish
+	// and marks the ones it accepted as published. It stops at the first
+	// failure so events for the same message are never reordered. Rows are
+	// locked while publish runs, so concurrent relays skip them.
+	Relay(ctx context.Context, limit int, publish func(ctx context.Context, evt *OutboxEvent) error) (int, error)
+	// DeletePublishedBefore prunes events published before cutoff.
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
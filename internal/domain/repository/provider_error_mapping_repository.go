@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProviderErrorMapping stores ErrorCode as the error code of messages that
+// Provider refuses with a matching answer. StatusCode 0, an empty
+// ProviderCode and an empty BodyContains match any answer; at least one of
+// them is set. BodyContains matches the body case-insensitively.
+type ProviderErrorMapping struct {
+	ID           uuid.UUID
+	Provider     string
+	StatusCode   int
+	ProviderCode string
+	BodyContains string
+	ErrorCode    string
+	Description  string
+	CreatedAt    time.Time
+}
+
+type ProviderErrorMappingRepository interface {
+	Create(ctx context.Context, mapping *ProviderErrorMapping) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*ProviderErrorMapping, error)
+	// List returns every mapping, oldest first.
+	List(ctx context.Context) ([]*ProviderErrorMapping, error)
+}
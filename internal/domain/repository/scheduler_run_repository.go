@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchedulerRun records one scheduler cycle. A cycle skipped because the
+// retry budget paused dispatch or the circuit breaker was open has a
+// SkipReason and no counts.
+type SchedulerRun struct {
+	ID         uuid.UUID
+	Instance   string
+	StartedAt  time.Time
+	Duration   time.Duration
+	Processed  int
+	Successful int
+	Failed     int
+	// Errors counts the batches that could not be processed at all, e.g.
+	// because claiming them failed; LastError is the last of their errors.
+	Errors     int
+	LastError  string
+	SkipReason string
+}
+
+type SchedulerRunRepository interface {
+	Add(ctx context.Context, run *SchedulerRun) error
+	// List returns runs, newest first.
+	List(ctx context.Context, limit, offset int) ([]*SchedulerRun, int64, error)
+	// DeleteStartedBefore removes runs that started before cutoff.
+	DeleteStartedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant owns a set of messages and authenticates with its own API key. Only
+// the SHA-256 hash of the key is stored.
+type Tenant struct {
+	ID         uuid.UUID
+	Name       string
+	APIKeyHash string
+	Active     bool
+	// QuietHours replaces the default quiet hours for the tenant's
+	// messages: HH:MM-HH:MM, "off", or empty to keep the default
+	QuietHours string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *Tenant) error
+	Update(ctx context.Context, tenant *Tenant) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Tenant, error)
+	FindByAPIKeyHash(ctx context.Context, hash string) (*Tenant, error)
+	List(ctx context.Context, limit, offset int) ([]*Tenant, int64, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDestination is a webhook messages can be sent to instead of the
+// configured provider. RateLimitPerSecond 0 uses the global limit. A
+// destination with a TenantID is only available to that tenant's messages.
+type WebhookDestination struct {
+	ID                 uuid.UUID
+	Name               string
+	URL                string
+	AuthKey            string
+	RateLimitPerSecond int
+	TenantID           uuid.UUID
+	Active             bool
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type WebhookDestinationRepository interface {
+	Create(ctx context.Context, destination *WebhookDestination) error
+	Update(ctx context.Context, destination *WebhookDestination) error
+	FindByID(ctx context.Context, id uuid.UUID) (*WebhookDestination, error)
+	List(ctx context.Context, limit, offset int) ([]*WebhookDestination, int64, error)
+}
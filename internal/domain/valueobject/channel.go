@@ -0,0 +1,50 @@
+package valueobject
+
+import "fmt"
+
+// Channel is the medium a message is delivered through.
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"
+	ChannelEmail Channel = "email"
+	ChannelPush  Channel = "push"
+)
+
+// Content limits of the channels that are not split into SMS segments
+const (
+	maxEmailContentLength = 100000
+	maxPushContentLength  = 1024
+)
+
+// NewChannel parses a channel; an empty string means SMS.
+func NewChannel(channel string) (Channel, error) {
+	if channel == "" {
+		return ChannelSMS, nil
+	}
+
+	c := Channel(channel)
+	switch c {
+	case ChannelSMS, ChannelEmail, ChannelPush:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid channel: %s (must be sms, email or push)", channel)
+	}
+}
+
+func (c Channel) String() string {
+	return string(c)
+}
+
+// ContentLimit is the most characters a message on the channel may hold.
+// SMS are held to smsLimit, the configured limit.
+func (c Channel) ContentLimit(smsLimit int) int {
+	switch c {
+	case ChannelEmail:
+		return maxEmailContentLength
+	case ChannelPush:
+		return maxPushContentLength
+	default:
+		return smsLimit
+	}
+}
@@ -1,6 +1,8 @@
 package valueobject
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"unicode/utf8"
 )
@@ -34,9 +36,24 @@ func (m *MessageContent) Length() int {
 	return utf8.RuneCountInString(m.value)
 }
 
+// Segmentation reports the SMS encoding of the content and how many parts
+// it is sent as.
+func (m *MessageContent) Segmentation() Segmentation {
+	return Segment(m.value)
+}
+
+func (m *MessageContent) Hash() string {
+	return HashContent(m.value)
+}
+
 func (m *MessageContent) Equals(other *MessageContent) bool {
 	if other == nil {
 		return false
 	}
 	return m.value == other.value
 }
+
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
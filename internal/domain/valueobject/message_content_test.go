@@ -69,3 +69,14 @@ func TestMessageContentLength(t *testing.T) {
 	unicodeContent, _ := NewMessageContent("Merhaba", 160)
 	assert.Equal(t, 7, unicodeContent.Length())
 }
+
+func TestMessageContentHash(t *testing.T) {
+	content, _ := NewMessageContent("Hello", 160)
+	same, _ := NewMessageContent("Hello", 160)
+	other, _ := NewMessageContent("Hello!", 160)
+
+	assert.Equal(t, "185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969", content.Hash())
+	assert.Equal(t, content.Hash(), same.Hash())
+	assert.NotEqual(t, content.Hash(), other.Hash())
+	assert.Len(t, content.Hash(), 64)
+}
@@ -0,0 +1,52 @@
+package valueobject
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	MaxMetadataKeys        = 20
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 256
+)
+
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// MessageMetadata is the client's key/value data attached to a message. It is
+// forwarded to the provider and can be used to filter listings.
+type MessageMetadata map[string]string
+
+// NewMessageMetadata validates metadata; nil and empty maps are both valid
+// and mean the message has none.
+func NewMessageMetadata(metadata map[string]string) (MessageMetadata, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if len(metadata) > MaxMetadataKeys {
+		return nil, fmt.Errorf("metadata has %d keys, at most %d are allowed", len(metadata), MaxMetadataKeys)
+	}
+
+	for key, value := range metadata {
+		if err := ValidateMetadataKey(key); err != nil {
+			return nil, err
+		}
+		if len(value) > MaxMetadataValueLength {
+			return nil, fmt.Errorf("metadata value for %q exceeds %d characters", key, MaxMetadataValueLength)
+		}
+	}
+
+	return MessageMetadata(metadata), nil
+}
+
+// ValidateMetadataKey checks a key is short and made of letters, digits,
+// underscores and hyphens, so it can be used in a JSON path unescaped.
+func ValidateMetadataKey(key string) error {
+	if len(key) > MaxMetadataKeyLength {
+		return fmt.Errorf("metadata key %q exceeds %d characters", key, MaxMetadataKeyLength)
+	}
+	if !metadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q (use letters, digits, '_' and '-')", key)
+	}
+	return nil
+}
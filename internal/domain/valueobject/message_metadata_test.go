@@ -0,0 +1,43 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessageMetadata(t *testing.T) {
+	tooMany := make(map[string]string, MaxMetadataKeys+1)
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name      string
+		metadata  map[string]string
+		wantError bool
+	}{
+		{name: "valid", metadata: map[string]string{"campaign": "spring-sale", "order_id": "42"}},
+		{name: "empty", metadata: map[string]string{}},
+		{name: "nil", metadata: nil},
+		{name: "too many keys", metadata: tooMany, wantError: true},
+		{name: "key with dot", metadata: map[string]string{"order.id": "42"}, wantError: true},
+		{name: "empty key", metadata: map[string]string{"": "42"}, wantError: true},
+		{name: "overlong key", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "v"}, wantError: true},
+		{name: "overlong value", metadata: map[string]string{"note": strings.Repeat("v", MaxMetadataValueLength+1)}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := NewMessageMetadata(tt.metadata)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, metadata, len(tt.metadata))
+			}
+		})
+	}
+}
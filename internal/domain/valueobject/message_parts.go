@@ -0,0 +1,83 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MessagePart places a message in a long text that was split into several
+// messages sharing GroupID. Number counts from 1 up to Count.
+type MessagePart struct {
+	GroupID uuid.UUID
+	Number  int
+	Count   int
+}
+
+// SplitContent splits content into at most maxParts texts of up to maxChars
+// characters each, every one prefixed with its position, e.g. "(1/3) ".
+// Breaks fall on whitespace when there is any in the second half of a part,
+// otherwise between characters.
+func SplitContent(content string, maxChars, maxParts int) ([]string, error) {
+	text := strings.TrimSpace(content)
+	if text == "" {
+		return nil, fmt.Errorf("message content cannot be empty")
+	}
+
+	// The prefix grows with the number of parts, which in turn depends on
+	// the room the prefix leaves; the count settles within a few rounds
+	for count := 2; count <= maxParts; count++ {
+		room := maxChars - utf8.RuneCountInString(partPrefix(count, count))
+		if room < 1 {
+			break
+		}
+
+		chunks := splitText(text, room, count)
+		if len(chunks) > count {
+			continue
+		}
+
+		parts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			parts[i] = partPrefix(i+1, len(chunks)) + chunk
+		}
+		return parts, nil
+	}
+
+	return nil, fmt.Errorf("message content of %d characters does not fit in %d parts of %d characters",
+		utf8.RuneCountInString(text), maxParts, maxChars)
+}
+
+func partPrefix(number, count int) string {
+	return fmt.Sprintf("(%d/%d) ", number, count)
+}
+
+// splitText cuts text into chunks of at most room characters, giving up once
+// there are more than limit of them.
+func splitText(text string, room, limit int) []string {
+	var chunks []string
+	runes := []rune(text)
+
+	for len(runes) > 0 && len(chunks) <= limit {
+		if len(runes) <= room {
+			chunks = append(chunks, string(runes))
+			break
+		}
+
+		end := room
+		for i := room; i > room/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i
+				break
+			}
+		}
+
+		chunks = append(chunks, strings.TrimRightFunc(string(runes[:end]), unicode.IsSpace))
+		runes = []rune(strings.TrimLeftFunc(string(runes[end:]), unicode.IsSpace))
+	}
+
+	return chunks
+}
@@ -0,0 +1,57 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitContent_BreaksOnWhitespace(t *testing.T) {
+	parts, err := SplitContent("The quick brown fox jumps over the lazy dog", 20, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"(1/4) The quick",
+		"(2/4) brown fox",
+		"(3/4) jumps over the",
+		"(4/4) lazy dog",
+	}, parts)
+}
+
+func TestSplitContent_HardSplitsWithoutWhitespace(t *testing.T) {
+	text := strings.Repeat("ş", 300)
+
+	parts, err := SplitContent(text, 160, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, parts, 2)
+	joined := ""
+	for _, part := range parts {
+		assert.LessOrEqual(t, utf8.RuneCountInString(part), 160)
+		joined += part[len("(1/2) "):]
+	}
+	assert.Equal(t, text, joined)
+}
+
+func TestSplitContent_PrefixGrowsWithCount(t *testing.T) {
+	// One character more than nine parts of 14 hold; past nine parts the
+	// prefix takes two more characters, so it takes eleven of 12
+	parts, err := SplitContent(strings.Repeat("a", 9*14+1), 20, 20)
+
+	assert.NoError(t, err)
+	assert.Len(t, parts, 11)
+	assert.Equal(t, "(1/11) ", parts[0][:7])
+	for _, part := range parts {
+		assert.LessOrEqual(t, utf8.RuneCountInString(part), 20)
+	}
+}
+
+func TestSplitContent_TooManyParts(t *testing.T) {
+	_, err := SplitContent(strings.Repeat("a", 1000), 160, 3)
+	assert.Error(t, err)
+
+	_, err = SplitContent("   ", 160, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package valueobject
+
+import "fmt"
+
+type MessagePriority string
+
+const (
+	MessagePriorityHigh   MessagePriority = "high"
+	MessagePriorityNormal MessagePriority = "normal"
+	MessagePriorityLow    MessagePriority = "low"
+)
+
+// NewMessagePriority parses a priority; an empty string means normal.
+func NewMessagePriority(priority string) (MessagePriority, error) {
+	if priority == "" {
+		return MessagePriorityNormal, nil
+	}
+
+	mp := MessagePriority(priority)
+	switch mp {
+	case MessagePriorityHigh, MessagePriorityNormal, MessagePriorityLow:
+		return mp, nil
+	default:
+		return "", fmt.Errorf("invalid message priority: %s (must be high, normal or low)", priority)
+	}
+}
+
+// MessagePriorities lists every priority, highest first.
+func MessagePriorities() []MessagePriority {
+	return []MessagePriority{MessagePriorityHigh, MessagePriorityNormal, MessagePriorityLow}
+}
+
+// MessagePriorityFromRank is the inverse of Rank. Unknown ranks map to normal.
+func MessagePriorityFromRank(rank int) MessagePriority {
+	switch rank {
+	case 2:
+		return MessagePriorityHigh
+	case 0:
+		return MessagePriorityLow
+	default:
+		return MessagePriorityNormal
+	}
+}
+
+func (p MessagePriority) String() string {
+	return string(p)
+}
+
+// Rank is the stored form of the priority; higher ranks are dispatched first.
+func (p MessagePriority) Rank() int {
+	switch p {
+	case MessagePriorityHigh:
+		return 2
+	case MessagePriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
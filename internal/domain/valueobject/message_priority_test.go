@@ -0,0 +1,45 @@
+package valueobject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessagePriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		priority  string
+		wantError bool
+		expected  MessagePriority
+	}{
+		{name: "high", priority: "high", expected: MessagePriorityHigh},
+		{name: "normal", priority: "normal", expected: MessagePriorityNormal},
+		{name: "low", priority: "low", expected: MessagePriorityLow},
+		{name: "empty defaults to normal", priority: "", expected: MessagePriorityNormal},
+		{name: "invalid", priority: "urgent", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priority, err := NewMessagePriority(tt.priority)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid message priority")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, priority)
+			}
+		})
+	}
+}
+
+func TestMessagePriority_RankRoundTrip(t *testing.T) {
+	assert.Greater(t, MessagePriorityHigh.Rank(), MessagePriorityNormal.Rank())
+	assert.Greater(t, MessagePriorityNormal.Rank(), MessagePriorityLow.Rank())
+
+	for _, p := range []MessagePriority{MessagePriorityHigh, MessagePriorityNormal, MessagePriorityLow} {
+		assert.Equal(t, p, MessagePriorityFromRank(p.Rank()))
+	}
+}
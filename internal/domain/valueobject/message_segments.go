@@ -0,0 +1,104 @@
+package valueobject
+
+import "strings"
+
+// SMSEncoding is the alphabet an SMS is sent in.
+type SMSEncoding string
+
+const (
+	// SMSEncodingGSM7 packs each character into 7 bits; characters from the
+	// extension table take two.
+	SMSEncodingGSM7 SMSEncoding = "GSM-7"
+	// SMSEncodingUCS2 is used as soon as one character is outside GSM-7.
+	// Each character takes a UTF-16 code unit, or two outside the BMP.
+	SMSEncodingUCS2 SMSEncoding = "UCS-2"
+)
+
+// Segment sizes in encoding units. A message that does not fit a single SMS
+// is sent as concatenated parts, each of which loses room to the header.
+const (
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet, without the escape character.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension holds the characters sent as an escape plus a second septet.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// Segmentation describes how a text is split into SMS.
+type Segmentation struct {
+	Encoding SMSEncoding
+	// Units is the encoded length: septets for GSM-7, UTF-16 code units for
+	// UCS-2.
+	Units    int
+	Segments int
+}
+
+// Segment works out the encoding of text and the number of SMS it takes. A
+// character that takes two units is never split across two parts.
+func Segment(text string) Segmentation {
+	encoding := SMSEncodingGSM7
+	for _, r := range text {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extension, r) {
+			encoding = SMSEncodingUCS2
+			break
+		}
+	}
+
+	single, multi := gsm7SingleSegment, gsm7MultiSegment
+	if encoding == SMSEncodingUCS2 {
+		single, multi = ucs2SingleSegment, ucs2MultiSegment
+	}
+
+	units := 0
+	segments := 0
+	used := multi // forces a new part for the first character
+	for _, r := range text {
+		width := unitWidth(encoding, r)
+		units += width
+		if used+width > multi {
+			segments++
+			used = 0
+		}
+		used += width
+	}
+
+	if units <= single {
+		segments = min(segments, 1)
+	}
+
+	return Segmentation{Encoding: encoding, Units: units, Segments: segments}
+}
+
+// ContentCharLimit is the length content is checked against. With a segment
+// limit it replaces charLimit by the most characters maxSegments SMS can
+// hold, reached with GSM-7 characters of one septet each; the segment count
+// itself is checked separately.
+func ContentCharLimit(charLimit, maxSegments int) int {
+	switch {
+	case maxSegments <= 0:
+		return charLimit
+	case maxSegments == 1:
+		return gsm7SingleSegment
+	default:
+		return maxSegments * gsm7MultiSegment
+	}
+}
+
+func unitWidth(encoding SMSEncoding, r rune) int {
+	if encoding == SMSEncodingGSM7 {
+		if strings.ContainsRune(gsm7Extension, r) {
+			return 2
+		}
+		return 1
+	}
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,105 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		encoding SMSEncoding
+		units    int
+		segments int
+	}{
+		{
+			name:     "single GSM-7 segment",
+			text:     strings.Repeat("a", 160),
+			encoding: SMSEncodingGSM7,
+			units:    160,
+			segments: 1,
+		},
+		{
+			name:     "GSM-7 over one segment",
+			text:     strings.Repeat("a", 161),
+			encoding: SMSEncodingGSM7,
+			units:    161,
+			segments: 2,
+		},
+		{
+			name:     "extension characters take two septets",
+			text:     strings.Repeat("€", 80),
+			encoding: SMSEncodingGSM7,
+			units:    160,
+			segments: 1,
+		},
+		{
+			name:     "extension character is not split across parts",
+			text:     strings.Repeat("a", 152) + "{" + strings.Repeat("a", 10),
+			encoding: SMSEncodingGSM7,
+			units:    164,
+			segments: 2,
+		},
+		{
+			name:     "accented letters in the GSM-7 alphabet",
+			text:     "Çok güzel, ñandu à la carte",
+			encoding: SMSEncodingGSM7,
+			units:    27,
+			segments: 1,
+		},
+		{
+			name:     "single UCS-2 segment",
+			text:     strings.Repeat("ş", 70),
+			encoding: SMSEncodingUCS2,
+			units:    70,
+			segments: 1,
+		},
+		{
+			name:     "UCS-2 over one segment",
+			text:     strings.Repeat("ş", 71),
+			encoding: SMSEncodingUCS2,
+			units:    71,
+			segments: 2,
+		},
+		{
+			name:     "characters outside the BMP take two units",
+			text:     strings.Repeat("a", 66) + "😀" + "a",
+			encoding: SMSEncodingUCS2,
+			units:    69,
+			segments: 1,
+		},
+		{
+			name:     "surrogate pair is not split across parts",
+			text:     strings.Repeat("a", 66) + "😀" + strings.Repeat("a", 10),
+			encoding: SMSEncodingUCS2,
+			units:    78,
+			segments: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segmentation := Segment(tt.text)
+
+			assert.Equal(t, tt.encoding, segmentation.Encoding)
+			assert.Equal(t, tt.units, segmentation.Units)
+			assert.Equal(t, tt.segments, segmentation.Segments)
+		})
+	}
+}
+
+func TestSegment_MultiPartBoundaries(t *testing.T) {
+	assert.Equal(t, 2, Segment(strings.Repeat("a", 306)).Segments)
+	assert.Equal(t, 3, Segment(strings.Repeat("a", 307)).Segments)
+	assert.Equal(t, 2, Segment(strings.Repeat("ş", 134)).Segments)
+	assert.Equal(t, 3, Segment(strings.Repeat("ş", 135)).Segments)
+}
+
+func TestContentCharLimit(t *testing.T) {
+	assert.Equal(t, 500, ContentCharLimit(500, 0))
+	assert.Equal(t, 160, ContentCharLimit(500, 1))
+	assert.Equal(t, 459, ContentCharLimit(100, 3))
+}
@@ -9,12 +9,33 @@ const (
 	MessageStatusProcessing MessageStatus = "processing"
 	MessageStatusSent       MessageStatus = "sent"
 	MessageStatusFailed     MessageStatus = "failed"
+	MessageStatusCancelled  MessageStatus = "cancelled"
+
+	// Set from the provider's delivery receipt after the message was sent
+	MessageStatusDelivered   MessageStatus = "delivered"
+	MessageStatusUndelivered MessageStatus = "undelivered"
+
+	// Held back at creation because the destination country is not allowed;
+	// never sent unless an operator releases it
+	MessageStatusQuarantined MessageStatus = "quarantined"
+
+	// Held back by an operator before it was picked up; resuming puts it back
+	// in the pending queue
+	MessageStatusPaused MessageStatus = "paused"
+
+	// Never sent because the recipient is on the blacklist
+	MessageStatusBlocked MessageStatus = "blocked"
+
+	// Not sent before its expires_at passed; stale content is never sent
+	MessageStatusExpired MessageStatus = "expired"
 )
 
 func NewMessageStatus(status string) (MessageStatus, error) {
 	ms := MessageStatus(status)
 	switch ms {
-	case MessageStatusPending, MessageStatusProcessing, MessageStatusSent, MessageStatusFailed:
+	case MessageStatusPending, MessageStatusProcessing, MessageStatusSent, MessageStatusFailed, MessageStatusCancelled,
+		MessageStatusDelivered, MessageStatusUndelivered, MessageStatusQuarantined, MessageStatusPaused, MessageStatusBlocked,
+		MessageStatusExpired:
 		return ms, nil
 	default:
 		return "", fmt.Errorf("invalid message status: %s", status)
@@ -41,6 +62,45 @@ func (s MessageStatus) IsFailed() bool {
 	return s == MessageStatusFailed
 }
 
+func (s MessageStatus) IsCancelled() bool {
+	return s == MessageStatusCancelled
+}
+
+func (s MessageStatus) IsDelivered() bool {
+	return s == MessageStatusDelivered
+}
+
+func (s MessageStatus) IsUndelivered() bool {
+	return s == MessageStatusUndelivered
+}
+
+func (s MessageStatus) IsQuarantined() bool {
+	return s == MessageStatusQuarantined
+}
+
+func (s MessageStatus) IsPaused() bool {
+	return s == MessageStatusPaused
+}
+
+func (s MessageStatus) IsBlocked() bool {
+	return s == MessageStatusBlocked
+}
+
+func (s MessageStatus) IsExpired() bool {
+	return s == MessageStatusExpired
+}
+
+// WasSent reports whether the provider accepted the message, regardless of
+// any delivery receipt received since.
+func (s MessageStatus) WasSent() bool {
+	return s == MessageStatusSent || s == MessageStatusDelivered || s == MessageStatusUndelivered
+}
+
+func (s MessageStatus) IsTerminal() bool {
+	return s.WasSent() || s == MessageStatusFailed || s == MessageStatusCancelled || s == MessageStatusQuarantined || s == MessageStatusBlocked ||
+		s == MessageStatusExpired
+}
+
 func (s MessageStatus) CanProcess() bool {
 	return s == MessageStatusPending
 }
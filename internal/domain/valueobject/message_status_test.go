@@ -37,6 +37,48 @@ func TestNewMessageStatus(t *testing.T) {
 			wantError: false,
 			expected:  MessageStatusFailed,
 		},
+		{
+			name:      "valid cancelled status",
+			status:    "cancelled",
+			wantError: false,
+			expected:  MessageStatusCancelled,
+		},
+		{
+			name:      "valid delivered status",
+			status:    "delivered",
+			wantError: false,
+			expected:  MessageStatusDelivered,
+		},
+		{
+			name:      "valid undelivered status",
+			status:    "undelivered",
+			wantError: false,
+			expected:  MessageStatusUndelivered,
+		},
+		{
+			name:      "valid quarantined status",
+			status:    "quarantined",
+			wantError: false,
+			expected:  MessageStatusQuarantined,
+		},
+		{
+			name:      "valid paused status",
+			status:    "paused",
+			wantError: false,
+			expected:  MessageStatusPaused,
+		},
+		{
+			name:      "valid blocked status",
+			status:    "blocked",
+			wantError: false,
+			expected:  MessageStatusBlocked,
+		},
+		{
+			name:      "valid expired status",
+			status:    "expired",
+			wantError: false,
+			expected:  MessageStatusExpired,
+		},
 		{
 			name:      "invalid status",
 			status:    "unknown",
@@ -69,6 +111,7 @@ func TestMessageStatus_String(t *testing.T) {
 	assert.Equal(t, "processing", MessageStatusProcessing.String())
 	assert.Equal(t, "sent", MessageStatusSent.String())
 	assert.Equal(t, "failed", MessageStatusFailed.String())
+	assert.Equal(t, "cancelled", MessageStatusCancelled.String())
 }
 
 func TestMessageStatus_IsPending(t *testing.T) {
@@ -99,9 +142,39 @@ func TestMessageStatus_IsFailed(t *testing.T) {
 	assert.True(t, MessageStatusFailed.IsFailed())
 }
 
+func TestMessageStatus_IsTerminal(t *testing.T) {
+	assert.False(t, MessageStatusPending.IsTerminal())
+	assert.False(t, MessageStatusProcessing.IsTerminal())
+	assert.True(t, MessageStatusSent.IsTerminal())
+	assert.True(t, MessageStatusFailed.IsTerminal())
+	assert.True(t, MessageStatusCancelled.IsTerminal())
+	assert.True(t, MessageStatusDelivered.IsTerminal())
+	assert.True(t, MessageStatusUndelivered.IsTerminal())
+	assert.True(t, MessageStatusQuarantined.IsTerminal())
+	assert.False(t, MessageStatusPaused.IsTerminal())
+	assert.True(t, MessageStatusBlocked.IsTerminal())
+	assert.True(t, MessageStatusExpired.IsTerminal())
+}
+
+func TestMessageStatus_WasSent(t *testing.T) {
+	assert.False(t, MessageStatusPending.WasSent())
+	assert.False(t, MessageStatusFailed.WasSent())
+	assert.True(t, MessageStatusSent.WasSent())
+	assert.True(t, MessageStatusDelivered.WasSent())
+	assert.True(t, MessageStatusUndelivered.WasSent())
+}
+
+func TestMessageStatus_IsCancelled(t *testing.T) {
+	assert.False(t, MessageStatusPending.IsCancelled())
+	assert.False(t, MessageStatusSent.IsCancelled())
+	assert.True(t, MessageStatusCancelled.IsCancelled())
+}
+
 func TestMessageStatus_CanProcess(t *testing.T) {
 	assert.True(t, MessageStatusPending.CanProcess())
 	assert.False(t, MessageStatusProcessing.CanProcess())
 	assert.False(t, MessageStatusSent.CanProcess())
 	assert.False(t, MessageStatusFailed.CanProcess())
+	assert.False(t, MessageStatusCancelled.CanProcess())
+	assert.False(t, MessageStatusPaused.CanProcess())
 }
@@ -0,0 +1,34 @@
+package valueobject
+
+import (
+	"regexp"
+	"sort"
+)
+
+// templateVariable matches a {{name}} placeholder; spaces inside the braces
+// are allowed.
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// RenderTemplate replaces every {{name}} placeholder in content with its
+// variable. Placeholders without a variable are left as they are and their
+// names returned, sorted and without duplicates.
+func RenderTemplate(content string, variables map[string]string) (string, []string) {
+	missing := make(map[string]struct{})
+
+	rendered := templateVariable.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := templateVariable.FindStringSubmatch(placeholder)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		missing[name] = struct{}{}
+		return placeholder
+	})
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return rendered, names
+}
@@ -0,0 +1,69 @@
+package valueobject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		variables   map[string]string
+		want        string
+		wantMissing []string
+	}{
+		{
+			name:        "no placeholders",
+			content:     "Hello World",
+			want:        "Hello World",
+			wantMissing: []string{},
+		},
+		{
+			name:        "replaces variables",
+			content:     "Hi {{first_name}}, your code is {{ code }}",
+			variables:   map[string]string{"first_name": "Ayşe", "code": "1234"},
+			want:        "Hi Ayşe, your code is 1234",
+			wantMissing: []string{},
+		},
+		{
+			name:        "repeated placeholder",
+			content:     "{{code}} - {{code}}",
+			variables:   map[string]string{"code": "42"},
+			want:        "42 - 42",
+			wantMissing: []string{},
+		},
+		{
+			name:        "missing variables are kept and listed once",
+			content:     "Hi {{name}}, {{name}} your order {{order.id}} shipped",
+			variables:   map[string]string{"unused": "x"},
+			want:        "Hi {{name}}, {{name}} your order {{order.id}} shipped",
+			wantMissing: []string{"name", "order.id"},
+		},
+		{
+			name:        "empty value",
+			content:     "Hi {{name}}!",
+			variables:   map[string]string{"name": ""},
+			want:        "Hi !",
+			wantMissing: []string{},
+		},
+		{
+			name:        "not a placeholder",
+			content:     "{{ }} and {single}",
+			want:        "{{ }} and {single}",
+			wantMissing: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, missing := RenderTemplate(tt.content, tt.variables)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantMissing, missing)
+		})
+	}
+}
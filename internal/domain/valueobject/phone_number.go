@@ -2,31 +2,114 @@ package valueobject
 
 import (
 	"fmt"
-	"regexp"
-)
+	"strconv"
+	"strings"
 
-var phoneRegex = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+	"github.com/nyaruka/phonenumbers"
+)
 
+// PhoneNumber is a number valid for its country, stored in E.164 form.
 type PhoneNumber struct {
-	value string
+	value  string
+	region string
 }
 
+// NewPhoneNumber parses an international number. Spaces, dashes and
+// parentheses are allowed ("+90 (555) 123 45 67") and dropped on
+// normalization.
 func NewPhoneNumber(phone string) (*PhoneNumber, error) {
 	if phone == "" {
 		return nil, fmt.Errorf("phone number cannot be empty")
 	}
 
-	if !phoneRegex.MatchString(phone) {
+	if !strings.HasPrefix(strings.TrimSpace(phone), "+") {
 		return nil, fmt.Errorf("invalid phone number format: must start with + and contain country code")
 	}
 
-	return &PhoneNumber{value: phone}, nil
+	parsed, err := phonenumbers.Parse(phone, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid phone number format: %w", err)
+	}
+
+	if !phonenumbers.IsValidNumber(parsed) {
+		return nil, fmt.Errorf("invalid phone number: %s is not a valid number for its country", phone)
+	}
+
+	return &PhoneNumber{
+		value:  phonenumbers.Format(parsed, phonenumbers.E164),
+		region: phonenumbers.GetRegionCodeForNumber(parsed),
+	}, nil
 }
 
 func (p *PhoneNumber) String() string {
 	return p.value
 }
 
+// RegionCode is the ISO 3166-1 alpha-2 country the number belongs to, e.g.
+// "TR". Numbers shared by several countries resolve to the one their prefix
+// is assigned to.
+func (p *PhoneNumber) RegionCode() string {
+	return p.region
+}
+
+// Timezones are the IANA time zones the number may be in, e.g.
+// "Europe/Istanbul". Countries spanning several zones may return all of
+// them when the number does not narrow it down; none are returned when
+// nothing is known.
+func (p *PhoneNumber) Timezones() []string {
+	parsed, err := phonenumbers.Parse(p.value, "")
+	if err != nil {
+		return nil
+	}
+	zones, err := phonenumbers.GetTimezonesForNumber(parsed)
+	if err != nil {
+		return nil
+	}
+
+	var known []string
+	for _, zone := range zones {
+		if zone != phonenumbers.UNKNOWN_TIMEZONE {
+			known = append(known, zone)
+		}
+	}
+	return known
+}
+
+// Carrier is the English name of the carrier the number's range was assigned
+// to, or "" when it is not known. A ported number keeps the name of its
+// original carrier.
+func (p *PhoneNumber) Carrier() string {
+	parsed, err := phonenumbers.Parse(p.value, "")
+	if err != nil {
+		return ""
+	}
+	carrier, err := phonenumbers.GetCarrierForNumber(parsed, "en")
+	if err != nil {
+		return ""
+	}
+	return carrier
+}
+
+// SplitCountryCode splits a number or number prefix in E.164 form, such as
+// "+90555", into its country calling code ("90"), the region the code is
+// assigned to ("TR") and the digits after it ("555"). ok is false when the
+// leading digits are not a country calling code.
+func SplitCountryCode(prefix string) (countryCode, region, rest string, ok bool) {
+	digits := strings.TrimPrefix(prefix, "+")
+	// Country calling codes are one to three digits and none is the start
+	// of another
+	for n := 1; n <= 3 && n <= len(digits); n++ {
+		code, err := strconv.Atoi(digits[:n])
+		if err != nil {
+			return "", "", "", false
+		}
+		if region := phonenumbers.GetRegionCodeForCountryCode(code); region != phonenumbers.UNKNOWN_REGION {
+			return digits[:n], region, digits[n:], true
+		}
+	}
+	return "", "", "", false
+}
+
 func (p *PhoneNumber) Equals(other *PhoneNumber) bool {
 	if other == nil {
 		return false
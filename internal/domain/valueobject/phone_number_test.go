@@ -19,7 +19,7 @@ func TestNewPhoneNumber(t *testing.T) {
 		},
 		{
 			name:      "valid us phone",
-			phone:     "+15551234567",
+			phone:     "+12025550123",
 			wantError: false,
 		},
 		{
@@ -42,6 +42,11 @@ func TestNewPhoneNumber(t *testing.T) {
 			phone:     "+9",
 			wantError: true,
 		},
+		{
+			name:      "unassigned us area code",
+			phone:     "+15551234567",
+			wantError: true,
+		},
 		{
 			name:      "too long",
 			phone:     "+123456789012345678",
@@ -65,6 +70,25 @@ func TestNewPhoneNumber(t *testing.T) {
 	}
 }
 
+func TestNewPhoneNumber_NormalizesToE164(t *testing.T) {
+	phone, err := NewPhoneNumber("+90 (555) 123-45 67")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "+905551234567", phone.String())
+	assert.Equal(t, "TR", phone.RegionCode())
+
+	formatted, _ := NewPhoneNumber("+905551234567")
+	assert.True(t, phone.Equals(formatted))
+}
+
+func TestPhoneNumberRegionCode(t *testing.T) {
+	us, _ := NewPhoneNumber("+12025550123")
+	gb, _ := NewPhoneNumber("+442079460958")
+
+	assert.Equal(t, "US", us.RegionCode())
+	assert.Equal(t, "GB", gb.RegionCode())
+}
+
 func TestPhoneNumberEquals(t *testing.T) {
 	phone1, _ := NewPhoneNumber("+905551234567")
 	phone2, _ := NewPhoneNumber("+905551234567")
@@ -74,3 +98,46 @@ func TestPhoneNumberEquals(t *testing.T) {
 	assert.False(t, phone1.Equals(phone3))
 	assert.False(t, phone1.Equals(nil))
 }
+
+func TestPhoneNumber_Timezones(t *testing.T) {
+	phone, err := NewPhoneNumber("+905551234567")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Europe/Istanbul"}, phone.Timezones())
+}
+
+func TestPhoneNumber_Carrier(t *testing.T) {
+	turkcell, _ := NewPhoneNumber("+905321234567")
+	unknown, _ := NewPhoneNumber("+12025550123")
+
+	assert.Equal(t, "Turkcell", turkcell.Carrier())
+	assert.Empty(t, unknown.Carrier())
+}
+
+func TestSplitCountryCode(t *testing.T) {
+	tests := []struct {
+		prefix      string
+		countryCode string
+		region      string
+		rest        string
+		ok          bool
+	}{
+		{prefix: "+90555", countryCode: "90", region: "TR", rest: "555", ok: true},
+		{prefix: "+1415", countryCode: "1", region: "US", rest: "415", ok: true},
+		{prefix: "+3556", countryCode: "355", region: "AL", rest: "6", ok: true},
+		{prefix: "+44", countryCode: "44", region: "GB", rest: "", ok: true},
+		{prefix: "+99", ok: false},
+		{prefix: "+", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			countryCode, region, rest, ok := SplitCountryCode(tt.prefix)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.countryCode, countryCode)
+			assert.Equal(t, tt.region, region)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily window of local time, such as 22:00-08:00, during
+// which messages are held back. A window whose end is before its start runs
+// past midnight. The zero value is no window.
+type QuietHours struct {
+	start int
+	end   int
+	set   bool
+}
+
+// ParseQuietHours reads a window written as HH:MM-HH:MM.
+func ParseQuietHours(window string) (QuietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(window), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: must be HH:MM-HH:MM", window)
+	}
+
+	start, err := parseTimeOfDay(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", window, err)
+	}
+	end, err := parseTimeOfDay(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", window, err)
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: start and end must differ", window)
+	}
+
+	return QuietHours{start: start, end: end, set: true}, nil
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (q QuietHours) IsZero() bool {
+	return !q.set
+}
+
+func (q QuietHours) String() string {
+	if !q.set {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// EndAfter reports whether t falls in the window in t's location, and if so
+// when the window ends.
+func (q QuietHours) EndAfter(t time.Time) (time.Time, bool) {
+	if !q.set {
+		return time.Time{}, false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	var quiet, endsTomorrow bool
+	if q.start < q.end {
+		quiet = minute >= q.start && minute < q.end
+	} else {
+		quiet = minute >= q.start || minute < q.end
+		endsTomorrow = minute >= q.start
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	day := t
+	if endsTomorrow {
+		day = t.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), q.end/60, q.end%60, 0, 0, t.Location()), true
+}
@@ -0,0 +1,68 @@
+package valueobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    string
+		wantError bool
+	}{
+		{name: "overnight", window: "22:00-08:00"},
+		{name: "same day", window: "12:30-14:00"},
+		{name: "spaces", window: " 21:00 - 09:00 "},
+		{name: "no dash", window: "22:00", wantError: true},
+		{name: "bad time", window: "25:00-08:00", wantError: true},
+		{name: "empty window", window: "08:00-08:00", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, err := ParseQuietHours(tt.window)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, window.IsZero())
+			}
+		})
+	}
+}
+
+func TestQuietHours_EndAfter(t *testing.T) {
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	overnight, _ := ParseQuietHours("22:00-08:00")
+	midday, _ := ParseQuietHours("12:00-14:00")
+
+	tests := []struct {
+		name      string
+		window    QuietHours
+		at        time.Time
+		wantQuiet bool
+		wantEnd   time.Time
+	}{
+		{name: "before midnight", window: overnight, at: time.Date(2026, 3, 1, 23, 15, 0, 0, istanbul), wantQuiet: true, wantEnd: time.Date(2026, 3, 2, 8, 0, 0, 0, istanbul)},
+		{name: "after midnight", window: overnight, at: time.Date(2026, 3, 2, 6, 0, 0, 0, istanbul), wantQuiet: true, wantEnd: time.Date(2026, 3, 2, 8, 0, 0, 0, istanbul)},
+		{name: "at the end", window: overnight, at: time.Date(2026, 3, 2, 8, 0, 0, 0, istanbul)},
+		{name: "daytime", window: overnight, at: time.Date(2026, 3, 2, 15, 0, 0, 0, istanbul)},
+		{name: "same day window", window: midday, at: time.Date(2026, 3, 2, 13, 0, 0, 0, istanbul), wantQuiet: true, wantEnd: time.Date(2026, 3, 2, 14, 0, 0, 0, istanbul)},
+		{name: "no window", at: time.Date(2026, 3, 2, 23, 0, 0, 0, istanbul)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, quiet := tt.window.EndAfter(tt.at)
+
+			assert.Equal(t, tt.wantQuiet, quiet)
+			if tt.wantQuiet {
+				assert.True(t, tt.wantEnd.Equal(end), "got %s", end)
+			}
+		})
+	}
+}
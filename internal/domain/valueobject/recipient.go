@@ -0,0 +1,109 @@
+package valueobject
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// Length limits of the addresses of the non-SMS channels
+const (
+	maxEmailAddressLength = 254
+	maxDeviceTokenLength  = 4096
+)
+
+// Recipient is who a message goes to on its channel: a phone number for SMS,
+// an email address, or a push device token.
+type Recipient struct {
+	channel Channel
+	address string
+	phone   *PhoneNumber
+}
+
+// NewRecipient validates address for channel. Phone numbers are stored in
+// E.164 form and email domains in lower case; device tokens are kept as is.
+func NewRecipient(channel Channel, address string) (*Recipient, error) {
+	switch channel {
+	case ChannelSMS:
+		phone, err := NewPhoneNumber(address)
+		if err != nil {
+			return nil, err
+		}
+		return NewPhoneRecipient(phone), nil
+	case ChannelEmail:
+		email, err := normalizeEmailAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		return &Recipient{channel: channel, address: email}, nil
+	case ChannelPush:
+		if err := validateDeviceToken(address); err != nil {
+			return nil, err
+		}
+		return &Recipient{channel: channel, address: address}, nil
+	default:
+		return nil, fmt.Errorf("invalid channel: %s", channel)
+	}
+}
+
+// NewPhoneRecipient is the SMS recipient at phone.
+func NewPhoneRecipient(phone *PhoneNumber) *Recipient {
+	return &Recipient{channel: ChannelSMS, address: phone.String(), phone: phone}
+}
+
+func (r *Recipient) Channel() Channel {
+	return r.channel
+}
+
+func (r *Recipient) String() string {
+	return r.address
+}
+
+// PhoneNumber is nil unless the recipient is on the SMS channel.
+func (r *Recipient) PhoneNumber() *PhoneNumber {
+	return r.phone
+}
+
+func (r *Recipient) Equals(other *Recipient) bool {
+	if other == nil {
+		return false
+	}
+	return r.channel == other.channel && r.address == other.address
+}
+
+// normalizeEmailAddress accepts a bare address such as "ada@example.com";
+// display names ("Ada <ada@example.com>") are rejected.
+func normalizeEmailAddress(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("email address cannot be empty")
+	}
+	if len(address) > maxEmailAddressLength {
+		return "", fmt.Errorf("email address must be at most %d characters", maxEmailAddressLength)
+	}
+
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return "", fmt.Errorf("invalid email address: %s", address)
+	}
+
+	at := strings.LastIndex(parsed.Address, "@")
+	domain := strings.ToLower(parsed.Address[at+1:])
+	if !strings.Contains(domain, ".") {
+		return "", fmt.Errorf("invalid email address: %s has no top-level domain", address)
+	}
+	return parsed.Address[:at+1] + domain, nil
+}
+
+func validateDeviceToken(token string) error {
+	if token == "" {
+		return fmt.Errorf("device token cannot be empty")
+	}
+	if len(token) > maxDeviceTokenLength {
+		return fmt.Errorf("device token must be at most %d characters", maxDeviceTokenLength)
+	}
+	if strings.IndexFunc(token, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return fmt.Errorf("device token cannot contain whitespace or control characters")
+	}
+	return nil
+}
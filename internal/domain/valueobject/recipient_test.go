@@ -0,0 +1,84 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewChannel(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   string
+		wantError bool
+		expected  Channel
+	}{
+		{name: "sms", channel: "sms", expected: ChannelSMS},
+		{name: "email", channel: "email", expected: ChannelEmail},
+		{name: "push", channel: "push", expected: ChannelPush},
+		{name: "empty defaults to sms", channel: "", expected: ChannelSMS},
+		{name: "invalid", channel: "fax", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, err := NewChannel(tt.channel)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid channel")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, channel)
+			}
+		})
+	}
+}
+
+func TestNewRecipient(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   Channel
+		address   string
+		wantError bool
+		expected  string
+	}{
+		{name: "phone number", channel: ChannelSMS, address: "+90 555 123 45 67", expected: "+905551234567"},
+		{name: "invalid phone number", channel: ChannelSMS, address: "ada@example.com", wantError: true},
+		{name: "email address", channel: ChannelEmail, address: "Ada.Lovelace@Example.COM", expected: "Ada.Lovelace@example.com"},
+		{name: "email with display name", channel: ChannelEmail, address: "Ada <ada@example.com>", wantError: true},
+		{name: "email without domain", channel: ChannelEmail, address: "ada", wantError: true},
+		{name: "email without top-level domain", channel: ChannelEmail, address: "ada@localhost", wantError: true},
+		{name: "email too long", channel: ChannelEmail, address: strings.Repeat("a", 250) + "@example.com", wantError: true},
+		{name: "empty email", channel: ChannelEmail, address: "", wantError: true},
+		{name: "device token", channel: ChannelPush, address: "fcm:dGVzdC10b2tlbg", expected: "fcm:dGVzdC10b2tlbg"},
+		{name: "device token with spaces", channel: ChannelPush, address: "abc def", wantError: true},
+		{name: "empty device token", channel: ChannelPush, address: "", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipient, err := NewRecipient(tt.channel, tt.address)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, recipient)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.channel, recipient.Channel())
+				assert.Equal(t, tt.expected, recipient.String())
+			}
+		})
+	}
+}
+
+func TestRecipient_PhoneNumberOnlyForSMS(t *testing.T) {
+	sms, _ := NewRecipient(ChannelSMS, "+905551234567")
+	email, _ := NewRecipient(ChannelEmail, "ada@example.com")
+
+	assert.NotNil(t, sms.PhoneNumber())
+	assert.Equal(t, "TR", sms.PhoneNumber().RegionCode())
+	assert.Nil(t, email.PhoneNumber())
+	assert.False(t, sms.Equals(email))
+}
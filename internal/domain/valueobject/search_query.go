@@ -0,0 +1,66 @@
+package valueobject
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxSearchQueryLength bounds the text of a content search
+const MaxSearchQueryLength = 200
+
+// searchTokenPattern splits a query into optionally negated words and
+// "quoted phrases"
+var searchTokenPattern = regexp.MustCompile(`(-?)(?:"([^"]*)"|(\S+))`)
+
+// SearchQuery is a content search in web search syntax: every word or
+// "quoted phrase" must appear and a leading '-' excludes one.
+type SearchQuery struct {
+	raw      string
+	terms    []string
+	excluded []string
+}
+
+// NewSearchQuery needs at least one term that is not excluded.
+func NewSearchQuery(raw string) (*SearchQuery, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if len(raw) > MaxSearchQueryLength {
+		return nil, fmt.Errorf("search query exceeds %d characters", MaxSearchQueryLength)
+	}
+
+	q := &SearchQuery{raw: raw}
+	for _, match := range searchTokenPattern.FindAllStringSubmatch(raw, -1) {
+		term := strings.Join(strings.Fields(match[2]+match[3]), " ")
+		if term == "" || term == "-" {
+			continue
+		}
+		if match[1] == "-" {
+			q.excluded = append(q.excluded, term)
+		} else {
+			q.terms = append(q.terms, term)
+		}
+	}
+	if len(q.terms) == 0 {
+		return nil, fmt.Errorf("search query must contain a word that is not excluded")
+	}
+
+	return q, nil
+}
+
+// String is the query as given, for databases that parse it themselves.
+func (q *SearchQuery) String() string {
+	return q.raw
+}
+
+// Terms are the words and phrases that must appear.
+func (q *SearchQuery) Terms() []string {
+	return q.terms
+}
+
+// Excluded are the words and phrases that must not appear.
+func (q *SearchQuery) Excluded() []string {
+	return q.excluded
+}
@@ -0,0 +1,40 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSearchQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		wantTerms    []string
+		wantExcluded []string
+		wantError    bool
+	}{
+		{name: "words", raw: "  order shipped ", wantTerms: []string{"order", "shipped"}},
+		{name: "phrase", raw: `"your   code" 4821`, wantTerms: []string{"your code", "4821"}},
+		{name: "excluded", raw: `sale -"black friday" -spam`, wantTerms: []string{"sale"}, wantExcluded: []string{"black friday", "spam"}},
+		{name: "empty", raw: "   ", wantError: true},
+		{name: "only excluded", raw: "-spam", wantError: true},
+		{name: "too long", raw: strings.Repeat("a", MaxSearchQueryLength+1), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewSearchQuery(tt.raw)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tt.raw), q.String())
+			assert.Equal(t, tt.wantTerms, q.Terms())
+			assert.Equal(t, tt.wantExcluded, q.Excluded())
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package fixtures
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+)
+
+// StatusShare is the weight of one status in a Distribution.
+type StatusShare struct {
+	Status valueobject.MessageStatus
+	Weight int
+}
+
+// Distribution is the mix of statuses generated messages end up in. The
+// weights are relative, so sent=70,pending=20,failed=10 and sent=7,pending=2,
+// failed=1 are the same mix.
+type Distribution []StatusShare
+
+// DefaultDistribution is most of the traffic sent, some of it waiting and a
+// little failed.
+var DefaultDistribution = Distribution{
+	{Status: valueobject.MessageStatusSent, Weight: 70},
+	{Status: valueobject.MessageStatusPending, Weight: 20},
+	{Status: valueobject.MessageStatusFailed, Weight: 10},
+}
+
+// ParseDistribution reads a comma-separated list of status=weight pairs,
+// such as "sent=70,pending=20,failed=10". Messages cannot be generated as
+// processing, since they would wait for the reaper rather than a worker.
+func ParseDistribution(value string) (Distribution, error) {
+	var distribution Distribution
+	seen := make(map[valueobject.MessageStatus]bool)
+	for _, pair := range strings.Split(value, ",") {
+		name, weightValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid status share %q: want status=weight", pair)
+		}
+
+		status, err := valueobject.NewMessageStatus(strings.ToLower(strings.TrimSpace(name)))
+		if err != nil {
+			return nil, err
+		}
+		if status == valueobject.MessageStatusProcessing {
+			return nil, fmt.Errorf("messages cannot be generated as %s", status)
+		}
+		if seen[status] {
+			return nil, fmt.Errorf("status %s is listed twice", status)
+		}
+		seen[status] = true
+
+		weight, err := strconv.Atoi(strings.TrimSpace(weightValue))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s: want a non-negative integer", weightValue, status)
+		}
+		distribution = append(distribution, StatusShare{Status: status, Weight: weight})
+	}
+
+	if distribution.total() == 0 {
+		return nil, fmt.Errorf("status distribution %q has no weight", value)
+	}
+	return distribution, nil
+}
+
+func (d Distribution) String() string {
+	pairs := make([]string, len(d))
+	for i, share := range d {
+		pairs[i] = fmt.Sprintf("%s=%d", share.Status, share.Weight)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (d Distribution) total() int {
+	total := 0
+	for _, share := range d {
+		total += share.Weight
+	}
+	return total
+}
+
+// Counts splits n messages between the statuses in proportion to their
+// weights. Rounding goes to the largest remainders, so the counts always
+// add up to n and 70/20/10 of 100 is exactly 70, 20 and 10.
+func (d Distribution) Counts(n int) map[valueobject.MessageStatus]int {
+	total := d.total()
+	counts := make(map[valueobject.MessageStatus]int, len(d))
+	if total == 0 {
+		return counts
+	}
+
+	remainders := make([]int, len(d))
+	assigned := 0
+	for i, share := range d {
+		counts[share.Status] = n * share.Weight / total
+		remainders[i] = n * share.Weight % total
+		assigned += counts[share.Status]
+	}
+
+	order := make([]int, len(d))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < n; i++ {
+		counts[d[order[i%len(order)]].Status]++
+		assigned++
+	}
+	return counts
+}
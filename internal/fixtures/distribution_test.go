@@ -0,0 +1,84 @@
+package fixtures_test
+
+import (
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/fixtures"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDistribution(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{name: "percentages", value: "sent=70,pending=20,failed=10", want: "sent=70,pending=20,failed=10"},
+		{name: "spaces and case", value: " Sent = 3 , delivered=1 ", want: "sent=3,delivered=1"},
+		{name: "zero weight kept", value: "sent=1,failed=0", want: "sent=1,failed=0"},
+		{name: "missing weight", value: "sent", wantErr: "want status=weight"},
+		{name: "unknown status", value: "lost=5", wantErr: "invalid message status"},
+		{name: "processing", value: "processing=5", wantErr: "cannot be generated"},
+		{name: "listed twice", value: "sent=1,sent=2", wantErr: "listed twice"},
+		{name: "negative weight", value: "sent=-1", wantErr: "non-negative integer"},
+		{name: "no weight", value: "sent=0,failed=0", wantErr: "no weight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			distribution, err := fixtures.ParseDistribution(tt.value)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, distribution.String())
+		})
+	}
+}
+
+func TestDistribution_CountsAddUp(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		n     int
+		want  map[valueobject.MessageStatus]int
+	}{
+		{
+			name:  "exact shares",
+			value: "sent=70,pending=20,failed=10",
+			n:     100,
+			want:  map[valueobject.MessageStatus]int{"sent": 70, "pending": 20, "failed": 10},
+		},
+		{
+			name:  "largest remainder rounds up",
+			value: "sent=1,pending=1,failed=1",
+			n:     10,
+			want:  map[valueobject.MessageStatus]int{"sent": 4, "pending": 3, "failed": 3},
+		},
+		{
+			name:  "fewer messages than statuses",
+			value: "sent=60,pending=30,failed=10",
+			n:     1,
+			want:  map[valueobject.MessageStatus]int{"sent": 1, "pending": 0, "failed": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			distribution, _ := fixtures.ParseDistribution(tt.value)
+
+			// Act
+			counts := distribution.Counts(tt.n)
+
+			// Assert
+			assert.Equal(t, tt.want, counts)
+		})
+	}
+}
@@ -0,0 +1,321 @@
+// Package fixtures generates realistic message data: a mix of statuses with
+// the fields each status implies, created over a stretch of history, spread
+// across tenants and a pool of recipients that repeat the way real traffic
+// does. The same Options always generate the same data, so a seed that
+// exposed a problem can be run again. cmd/seed fills a database with it,
+// and integration tests can insert it with Insert.
+package fixtures
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// recentWindow is how far back messages that have not finished yet, pending
+// and paused ones, are created; older ones would have been sent already.
+const recentWindow = time.Hour
+
+// Options describes the messages Generate builds. Count, Now and Seed are
+// required to repeat a run; the others have defaults.
+type Options struct {
+	Count int
+	// Distribution defaults to DefaultDistribution
+	Distribution Distribution
+	// Tenants own the messages in turn; none leaves them unscoped
+	Tenants []uuid.UUID
+	// History is how far before Now the oldest message is created
+	History time.Duration
+	Now     time.Time
+	Seed    int64
+	// Recipients is the number of distinct phone numbers, default 50
+	Recipients int
+	// CharLimit defaults to 160, MaxAttempts to 3
+	CharLimit   int
+	MaxAttempts int
+}
+
+func (o *Options) withDefaults() Options {
+	opts := *o
+	if opts.Distribution == nil {
+		opts.Distribution = DefaultDistribution
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	opts.Now = opts.Now.UTC()
+	if opts.Recipients <= 0 {
+		opts.Recipients = 50
+	}
+	if opts.CharLimit <= 0 {
+		opts.CharLimit = 160
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	return opts
+}
+
+var (
+	// mobilePrefixes and the number of digits that follow them, weighted
+	// towards Turkey where most of the traffic goes
+	mobilePrefixes = []struct {
+		prefix string
+		digits int
+	}{
+		{"+90532", 7}, {"+90542", 7}, {"+90555", 7}, {"+90505", 7}, {"+90533", 7},
+		{"+49151", 8}, {"+49170", 8},
+		{"+447400", 6},
+	}
+
+	contentTemplates = []string{
+		"Your verification code is: %06d",
+		"Your order #%d has been confirmed.",
+		"Your order #%d has been shipped and is on its way.",
+		"Special offer just for you! Use code SAVE%d at checkout.",
+		"Flash sale starts in 1 hour! %d%% off selected items.",
+		"Reminder: your appointment is tomorrow at %d:00.",
+		"Your subscription has been renewed. Invoice #%d.",
+		"Weekly summary: %d new updates in your account.",
+		"Welcome to Insider! Your journey starts here. Ref %d",
+		"Security alert: new sign-in to your account. Code %d",
+	}
+
+	// Failures that use up every attempt, and those that end the first
+	// attempt they happen on
+	retriedFailures = []failure{
+		{apperrors.ErrorCodeServerError, "webhook returned 503"},
+		{apperrors.ErrorCodeTimeout, "webhook request timed out"},
+		{apperrors.ErrorCodeNetworkError, "connection reset by peer"},
+	}
+	permanentFailures = []failure{
+		{apperrors.ErrorCodeInvalidRecipient, "provider rejected the number as invalid"},
+		{apperrors.ErrorCodeRecipientOptedOut, "recipient opted out of messages"},
+		{apperrors.ErrorCodeProviderRejected, "provider rejected the message"},
+	}
+	undeliveredFailures = []failure{
+		{apperrors.ErrorCodeCarrierError, "carrier could not deliver the message"},
+		{apperrors.ErrorCodeInvalidRecipient, "handset unreachable"},
+	}
+)
+
+type failure struct {
+	code   apperrors.ErrorCode
+	reason string
+}
+
+// Generate builds opts.Count messages, oldest first, as if they had been
+// read back from the database.
+func Generate(opts Options) ([]*entity.Message, error) {
+	opts = opts.withDefaults()
+	if opts.Count < 0 {
+		return nil, fmt.Errorf("message count must not be negative, got %d", opts.Count)
+	}
+	if opts.History < 0 {
+		return nil, fmt.Errorf("history must not be negative, got %s", opts.History)
+	}
+
+	g := &generator{opts: opts, rng: rand.New(rand.NewSource(opts.Seed))}
+	recipients, err := g.recipients()
+	if err != nil {
+		return nil, err
+	}
+
+	// Lay the statuses out in a fixed order, then shuffle them with the seed
+	counts := opts.Distribution.Counts(opts.Count)
+	statuses := make([]valueobject.MessageStatus, 0, opts.Count)
+	for _, share := range opts.Distribution {
+		for i := 0; i < counts[share.Status]; i++ {
+			statuses = append(statuses, share.Status)
+		}
+	}
+	g.rng.Shuffle(len(statuses), func(i, j int) { statuses[i], statuses[j] = statuses[j], statuses[i] })
+
+	messages := make([]*entity.Message, len(statuses))
+	for i, status := range statuses {
+		var tenantID uuid.UUID
+		if len(opts.Tenants) > 0 {
+			tenantID = opts.Tenants[i%len(opts.Tenants)]
+		}
+		message, err := g.message(status, recipients[g.rng.Intn(len(recipients))], tenantID)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = message
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].CreatedAt().Before(messages[j].CreatedAt()) })
+	return messages, nil
+}
+
+type generator struct {
+	opts Options
+	rng  *rand.Rand
+}
+
+func (g *generator) recipients() ([]*valueobject.Recipient, error) {
+	recipients := make([]*valueobject.Recipient, g.opts.Recipients)
+	for i := range recipients {
+		mobile := mobilePrefixes[g.rng.Intn(len(mobilePrefixes))]
+		number := mobile.prefix
+		for d := 0; d < mobile.digits; d++ {
+			number += fmt.Sprint(g.rng.Intn(10))
+		}
+
+		phone, err := valueobject.NewPhoneNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		recipients[i] = valueobject.NewPhoneRecipient(phone)
+	}
+	return recipients, nil
+}
+
+func (g *generator) uuid() uuid.UUID {
+	// A reader over the seeded source keeps the IDs repeatable
+	id, _ := uuid.NewRandomFromReader(g.rng)
+	return id
+}
+
+// between returns a random duration in [lo, hi).
+func (g *generator) between(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(g.rng.Int63n(int64(hi-lo)))
+}
+
+// notAfterNow keeps the time a message was sent or delivered from landing
+// in the future when it was created just before Now.
+func (g *generator) notAfterNow(t time.Time) time.Time {
+	if t.After(g.opts.Now) {
+		return g.opts.Now
+	}
+	return t
+}
+
+func (g *generator) priority() valueobject.MessagePriority {
+	switch n := g.rng.Intn(10); {
+	case n == 0:
+		return valueobject.MessagePriorityHigh
+	case n == 1:
+		return valueobject.MessagePriorityLow
+	default:
+		return valueobject.MessagePriorityNormal
+	}
+}
+
+func (g *generator) message(status valueobject.MessageStatus, recipient *valueobject.Recipient, tenantID uuid.UUID) (*entity.Message, error) {
+	template := contentTemplates[g.rng.Intn(len(contentTemplates))]
+	content, err := valueobject.NewMessageContent(fmt.Sprintf(template, g.rng.Intn(1000000)), g.opts.CharLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	history := g.opts.History
+	if status == valueobject.MessageStatusPending || status == valueobject.MessageStatusPaused {
+		history = min(history, recentWindow)
+	}
+	createdAt := g.opts.Now.Add(-g.between(0, history)).Truncate(time.Microsecond)
+
+	var f fields
+	switch status {
+	case valueobject.MessageStatusSent, valueobject.MessageStatusDelivered, valueobject.MessageStatusUndelivered:
+		// One in five needed a retry
+		f.attempts = 1
+		if g.rng.Intn(5) == 0 && g.opts.MaxAttempts > 1 {
+			f.attempts = 2
+		}
+		sentAt := g.notAfterNow(createdAt.Add(g.between(time.Second, 30*time.Second) * time.Duration(f.attempts)))
+		f.sentAt = &sentAt
+		f.webhookMessageID = g.uuid().String()
+		switch status {
+		case valueobject.MessageStatusDelivered:
+			deliveredAt := g.notAfterNow(sentAt.Add(g.between(time.Second, time.Minute)))
+			f.deliveredAt = &deliveredAt
+		case valueobject.MessageStatusUndelivered:
+			f.fail(undeliveredFailures[g.rng.Intn(len(undeliveredFailures))])
+		}
+	case valueobject.MessageStatusFailed:
+		// Most failures are retried to the end; the rest are refused outright
+		if g.rng.Intn(3) == 0 {
+			f.attempts = 1
+			f.fail(permanentFailures[g.rng.Intn(len(permanentFailures))])
+		} else {
+			f.attempts = g.opts.MaxAttempts
+			f.fail(retriedFailures[g.rng.Intn(len(retriedFailures))])
+		}
+	case valueobject.MessageStatusPending:
+		// Some are scheduled for later in the day
+		if g.rng.Intn(5) == 0 {
+			scheduledAt := g.opts.Now.Add(g.between(time.Minute, 12*time.Hour)).Truncate(time.Microsecond)
+			f.scheduledAt = &scheduledAt
+		}
+	case valueobject.MessageStatusExpired:
+		expiresAt := createdAt.Add(g.between(5*time.Minute, 30*time.Minute))
+		f.expiresAt = &expiresAt
+		f.fail(failure{apperrors.ErrorCodeMessageExpired, "message expired before it was sent"})
+	case valueobject.MessageStatusBlocked:
+		f.fail(failure{apperrors.ErrorCodeRecipientBlocked, "recipient is on the blacklist"})
+	case valueobject.MessageStatusQuarantined:
+		f.fail(failure{apperrors.ErrorCodeContentRejected, "content filter rejected the message"})
+	}
+
+	return entity.ReconstructMessage(
+		g.uuid(),
+		recipient,
+		content,
+		content.Hash(),
+		status,
+		createdAt,
+		f.sentAt,
+		f.deliveredAt,
+		f.scheduledAt,
+		nil,
+		nil,
+		f.attempts,
+		g.opts.MaxAttempts,
+		f.lastError,
+		f.errorCode,
+		f.webhookMessageID,
+		"",
+		"",
+		"",
+		"",
+		g.priority(),
+		tenantID,
+		nil,
+		uuid.Nil,
+		uuid.Nil,
+		f.expiresAt,
+		nil,
+		"",
+		false,
+		false,
+		"",
+		1,
+	), nil
+}
+
+// fields are the columns a status implies.
+type fields struct {
+	attempts         int
+	sentAt           *time.Time
+	deliveredAt      *time.Time
+	scheduledAt      *time.Time
+	expiresAt        *time.Time
+	webhookMessageID string
+	lastError        string
+	errorCode        string
+}
+
+func (f *fields) fail(failure failure) {
+	f.errorCode = string(failure.code)
+	f.lastError = failure.reason
+}
@@ -0,0 +1,80 @@
+//go:build integration
+
+package fixtures_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/fixtures"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_Insert_StoresGeneratedMessages(t *testing.T) {
+	repositories := map[string]func(db *persistence.GormDB) repository.MessageRepository{
+		"gorm": func(db *persistence.GormDB) repository.MessageRepository {
+			return persistence.NewMessageRepositoryGorm(db.DB(), testsupport.CharLimit, false, 0.5)
+		},
+		"postgres": func(db *persistence.GormDB) repository.MessageRepository {
+			return persistence.NewMessageRepositoryPostgres(testsupport.SQLDB(t, db), testsupport.CharLimit, 0.5)
+		},
+	}
+
+	for name, newRepository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			db := testsupport.Postgres(t)
+			repo := newRepository(db)
+
+			tenants := fixtures.GenerateTenants(2, 1, time.Now())
+			tenantRepo := persistence.NewTenantRepositoryGorm(db.DB())
+			assert.NoError(t, fixtures.InsertTenants(ctx, tenantRepo, tenants))
+
+			distribution, _ := fixtures.ParseDistribution("sent=50,delivered=20,pending=20,failed=10")
+			messages, err := fixtures.Generate(fixtures.Options{
+				Count:        600,
+				Distribution: distribution,
+				Tenants:      fixtures.TenantIDs(tenants),
+				History:      7 * 24 * time.Hour,
+				Seed:         1,
+			})
+			assert.NoError(t, err)
+
+			// Act
+			err = fixtures.Insert(ctx, repo, messages)
+			reinsertTenantsErr := fixtures.InsertTenants(ctx, tenantRepo, tenants)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, reinsertTenantsErr)
+
+			stats, err := repo.GetStats(ctx)
+			if assert.NoError(t, err) {
+				assert.Equal(t, int64(600), stats.TotalMessages)
+				assert.Equal(t, int64(300), stats.SentMessages)
+				assert.Equal(t, int64(120), stats.DeliveredMessages)
+				assert.Equal(t, int64(120), stats.PendingMessages)
+				assert.Equal(t, int64(60), stats.FailedMessages)
+			}
+
+			for _, message := range messages {
+				if message.Status() != valueobject.MessageStatusFailed {
+					continue
+				}
+				stored, err := repo.FindByID(ctx, message.ID())
+				if assert.NoError(t, err) {
+					assert.Equal(t, message.ErrorCode(), stored.ErrorCode())
+					assert.Equal(t, message.Attempts(), stored.Attempts())
+					assert.Equal(t, message.TenantID(), stored.TenantID())
+				}
+				break
+			}
+		})
+	}
+}
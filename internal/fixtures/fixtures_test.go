@@ -0,0 +1,135 @@
+package fixtures_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/fixtures"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var fixedNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestGenerate_SameSeedSameMessages(t *testing.T) {
+	// Arrange
+	opts := fixtures.Options{Count: 50, History: 24 * time.Hour, Now: fixedNow, Seed: 42}
+
+	// Act
+	first, err1 := fixtures.Generate(opts)
+	second, err2 := fixtures.Generate(opts)
+	opts.Seed = 43
+	other, err3 := fixtures.Generate(opts)
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	for i := range first {
+		assert.Equal(t, first[i].ID(), second[i].ID())
+		assert.Equal(t, first[i].Recipient().String(), second[i].Recipient().String())
+		assert.Equal(t, first[i].Content().String(), second[i].Content().String())
+		assert.Equal(t, first[i].Status(), second[i].Status())
+		assert.Equal(t, first[i].CreatedAt(), second[i].CreatedAt())
+	}
+	assert.NotEqual(t, first[0].ID(), other[0].ID())
+}
+
+func TestGenerate_FollowsDistribution(t *testing.T) {
+	// Arrange
+	distribution, _ := fixtures.ParseDistribution("sent=70,pending=20,failed=10")
+
+	// Act
+	messages, err := fixtures.Generate(fixtures.Options{Count: 200, Distribution: distribution, History: time.Hour, Now: fixedNow})
+
+	// Assert
+	assert.NoError(t, err)
+	counts := make(map[valueobject.MessageStatus]int)
+	for _, message := range messages {
+		counts[message.Status()]++
+	}
+	assert.Equal(t, map[valueobject.MessageStatus]int{
+		valueobject.MessageStatusSent:    140,
+		valueobject.MessageStatusPending: 40,
+		valueobject.MessageStatusFailed:  20,
+	}, counts)
+}
+
+func TestGenerate_FillsFieldsOfEachStatus(t *testing.T) {
+	// Arrange
+	distribution, _ := fixtures.ParseDistribution("sent=1,delivered=1,undelivered=1,failed=1,pending=1,expired=1,blocked=1,quarantined=1,cancelled=1,paused=1")
+	tenants := []uuid.UUID{uuid.New(), uuid.New()}
+	history := 30 * 24 * time.Hour
+
+	// Act
+	messages, err := fixtures.Generate(fixtures.Options{
+		Count:        300,
+		Distribution: distribution,
+		Tenants:      tenants,
+		History:      history,
+		Now:          fixedNow,
+		Seed:         7,
+		MaxAttempts:  3,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	perTenant := make(map[uuid.UUID]int)
+	for i, message := range messages {
+		perTenant[message.TenantID()]++
+		assert.False(t, message.CreatedAt().Before(fixedNow.Add(-history)), "created before the history window")
+		assert.False(t, message.CreatedAt().After(fixedNow), "created in the future")
+		if i > 0 {
+			assert.False(t, message.CreatedAt().Before(messages[i-1].CreatedAt()), "not oldest first")
+		}
+
+		status := message.Status()
+		switch {
+		case status.WasSent():
+			assert.NotNil(t, message.SentAt())
+			assert.NotEmpty(t, message.WebhookMessageID())
+			assert.GreaterOrEqual(t, message.Attempts(), 1)
+		case status == valueobject.MessageStatusPending || status == valueobject.MessageStatusPaused:
+			assert.Zero(t, message.Attempts())
+			assert.True(t, message.CreatedAt().After(fixedNow.Add(-time.Hour)), "unfinished message created long ago")
+		}
+		switch status {
+		case valueobject.MessageStatusDelivered:
+			assert.NotNil(t, message.DeliveredAt())
+		case valueobject.MessageStatusUndelivered, valueobject.MessageStatusFailed, valueobject.MessageStatusBlocked, valueobject.MessageStatusQuarantined:
+			assert.NotEmpty(t, message.ErrorCode())
+			assert.NotEmpty(t, message.LastError())
+		case valueobject.MessageStatusExpired:
+			assert.Equal(t, "MESSAGE_EXPIRED", message.ErrorCode())
+			assert.NotNil(t, message.ExpiresAt())
+		}
+	}
+	assert.Equal(t, map[uuid.UUID]int{tenants[0]: 150, tenants[1]: 150}, perTenant)
+}
+
+func TestGenerate_RejectsNegativeCount(t *testing.T) {
+	// Act
+	messages, err := fixtures.Generate(fixtures.Options{Count: -1})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, messages)
+}
+
+func TestGenerateTenants_SameSeedSameTenants(t *testing.T) {
+	// Act
+	first := fixtures.GenerateTenants(3, 42, fixedNow)
+	second := fixtures.GenerateTenants(3, 42, fixedNow)
+
+	// Assert
+	if assert.Len(t, first, 3) {
+		for i := range first {
+			assert.Equal(t, first[i].ID, second[i].ID)
+			assert.Equal(t, first[i].APIKey, second[i].APIKey)
+			assert.Contains(t, first[i].APIKey, "tk_")
+			assert.NotEqual(t, first[i].APIKey, first[i].APIKeyHash)
+		}
+		assert.NotEqual(t, first[0].Name, first[1].Name)
+	}
+}
@@ -0,0 +1,102 @@
+package fixtures
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// insertBatchSize keeps each insert transaction short on large seeds.
+const insertBatchSize = 500
+
+// Insert stores messages with repo in batches. A repository inserts a new
+// message without the outcome of a send, so each message past pending is
+// written again to store its times, attempts and error.
+func Insert(ctx context.Context, repo repository.MessageRepository, messages []*entity.Message) error {
+	for start := 0; start < len(messages); start += insertBatchSize {
+		batch := messages[start:min(start+insertBatchSize, len(messages))]
+		if err := repo.CreateBatch(ctx, batch); err != nil {
+			return err
+		}
+		for _, message := range batch {
+			if message.Status() == valueobject.MessageStatusPending {
+				continue
+			}
+			if err := repo.Update(ctx, message); err != nil {
+				return fmt.Errorf("failed to store outcome of message %s: %w", message.ID(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Tenant is a generated tenant along with the API key that authenticates
+// as it, which only its hash is stored for.
+type Tenant struct {
+	*repository.Tenant
+	APIKey string
+}
+
+// GenerateTenants builds n active tenants, the same ones for the same seed.
+func GenerateTenants(n int, seed int64, now time.Time) []*Tenant {
+	rng := rand.New(rand.NewSource(seed))
+	now = now.UTC()
+
+	tenants := make([]*Tenant, n)
+	for i := range tenants {
+		id, _ := uuid.NewRandomFromReader(rng)
+		key := make([]byte, 32)
+		rng.Read(key)
+		apiKey := "tk_" + hex.EncodeToString(key)
+		hash := sha256.Sum256([]byte(apiKey))
+
+		tenants[i] = &Tenant{
+			Tenant: &repository.Tenant{
+				ID:         id,
+				Name:       fmt.Sprintf("fixtures-%d-%s", i+1, id.String()[:8]),
+				APIKeyHash: hex.EncodeToString(hash[:]),
+				Active:     true,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			},
+			APIKey: apiKey,
+		}
+	}
+	return tenants
+}
+
+// InsertTenants stores tenants with repo, skipping those stored already, so
+// seeding again with the same seed reuses them.
+func InsertTenants(ctx context.Context, repo repository.TenantRepository, tenants []*Tenant) error {
+	for _, tenant := range tenants {
+		_, err := repo.FindByID(ctx, tenant.ID)
+		if err == nil {
+			continue
+		}
+		if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != apperrors.ErrorCodeNotFound {
+			return err
+		}
+		if err := repo.Create(ctx, tenant.Tenant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TenantIDs lists the IDs of tenants, for Options.Tenants.
+func TenantIDs(tenants []*Tenant) []uuid.UUID {
+	ids := make([]uuid.UUID, len(tenants))
+	for i, tenant := range tenants {
+		ids[i] = tenant.ID
+	}
+	return ids
+}
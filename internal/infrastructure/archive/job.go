@@ -0,0 +1,288 @@
+// Package archive moves finished messages out of the database into
+// compressed files in object storage, recording each file in a manifest
+// table so archived ranges can still be found.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Result counts what one run archived.
+type Result struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Files      int
+	Archived   int64
+}
+
+// Status describes the configuration and the outcome of the last run.
+type Status struct {
+	MaxAge        time.Duration
+	Statuses      []string
+	Interval      time.Duration
+	LastRunAt     *time.Time
+	LastError     string
+	LastArchived  int64
+	TotalArchived int64
+}
+
+// Job archives messages older than maxAge every interval, a file of up to
+// batchSize messages at a time. Each file is uploaded and recorded in the
+// manifest before its messages are deleted, so a failure at any step keeps
+// them in the database; at worst a later run archives them a second time.
+// Runs never overlap: a manual run while one is in progress is refused.
+type Job struct {
+	repo      repository.MessageRepository
+	archives  repository.MessageArchiveRepository
+	store     Store
+	audit     service.AuditService
+	maxAge    time.Duration
+	statuses  []string
+	interval  time.Duration
+	batchSize int
+	prefix    string
+	now       func() time.Time
+
+	running sync.Mutex
+
+	mu            sync.RWMutex
+	lastRunAt     time.Time
+	lastError     string
+	lastArchived  int64
+	totalArchived int64
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewJob fails when cfg.Statuses names a status that is not final, since
+// those messages may still change. audit may be nil.
+func NewJob(repo repository.MessageRepository, archives repository.MessageArchiveRepository, store Store, audit service.AuditService, cfg *config.ArchiveConfig) (*Job, error) {
+	statuses := make([]string, 0, len(cfg.Statuses))
+	for _, name := range cfg.Statuses {
+		status, err := valueobject.NewMessageStatus(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if !status.IsTerminal() {
+			return nil, fmt.Errorf("archiving %s messages is not allowed; only finished messages can be archived", status)
+		}
+		statuses = append(statuses, status.String())
+	}
+
+	return &Job{
+		repo:      repo,
+		archives:  archives,
+		store:     store,
+		audit:     audit,
+		maxAge:    cfg.MaxAge,
+		statuses:  statuses,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		prefix:    cfg.Prefix,
+		now:       time.Now,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+func (j *Job) Start(ctx context.Context) {
+	j.wg.Add(1)
+	go j.run(ctx)
+
+	logger.Get().Info("archive job started",
+		zap.Duration("max_age", j.maxAge),
+		zap.Duration("interval", j.interval),
+		zap.Strings("statuses", j.statuses),
+	)
+}
+
+func (j *Job) Stop() {
+	close(j.stopChan)
+	j.wg.Wait()
+
+	logger.Get().Info("archive job stopped")
+}
+
+func (j *Job) Status() Status {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	status := Status{
+		MaxAge:        j.maxAge,
+		Statuses:      j.statuses,
+		Interval:      j.interval,
+		LastError:     j.lastError,
+		LastArchived:  j.lastArchived,
+		TotalArchived: j.totalArchived,
+	}
+	if !j.lastRunAt.IsZero() {
+		lastRunAt := j.lastRunAt
+		status.LastRunAt = &lastRunAt
+	}
+	return status
+}
+
+// List returns the archives recorded in the manifest that match filter.
+func (j *Job) List(ctx context.Context, filter repository.MessageArchiveFilter, limit, offset int) ([]*repository.MessageArchive, int64, error) {
+	return j.archives.List(ctx, filter, limit, offset)
+}
+
+func (j *Job) run(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.stopChan:
+			return
+		case <-ticker.C:
+			// Run logs its own failures
+			_, _ = j.Run(actor.WithActor(ctx, actor.System))
+		}
+	}
+}
+
+// Run archives files until no message older than the cutoff is left. On an
+// error it stops and returns what was archived so far.
+func (j *Job) Run(ctx context.Context) (*Result, error) {
+	if !j.running.TryLock() {
+		return nil, apperrors.New(apperrors.ErrorCodeConflict, "an archive run is already in progress")
+	}
+	defer j.running.Unlock()
+
+	result := &Result{StartedAt: j.now().UTC()}
+	cutoff := result.StartedAt.Add(-j.maxAge)
+
+	var runErr error
+	for {
+		if err := ctx.Err(); err != nil {
+			runErr = err
+			break
+		}
+
+		archived, err := j.archiveBatch(ctx, cutoff)
+		if err != nil {
+			runErr = err
+			break
+		}
+		if archived > 0 {
+			result.Files++
+			result.Archived += int64(archived)
+		}
+		if archived < j.batchSize {
+			break
+		}
+	}
+	result.FinishedAt = j.now().UTC()
+
+	j.mu.Lock()
+	j.lastRunAt = result.FinishedAt
+	j.lastError = ""
+	if runErr != nil {
+		j.lastError = runErr.Error()
+	}
+	j.lastArchived = result.Archived
+	j.totalArchived += result.Archived
+	j.mu.Unlock()
+
+	if runErr != nil {
+		logger.FromContext(ctx).Error("archive run failed", zap.Error(runErr))
+	}
+	if result.Archived > 0 {
+		logger.FromContext(ctx).Info("archive run moved messages",
+			zap.Int("files", result.Files),
+			zap.Int64("archived", result.Archived),
+		)
+		j.recordAudit(ctx, result)
+	}
+
+	return result, runErr
+}
+
+// archiveBatch writes the oldest archivable messages to one file, records
+// it and deletes them, returning how many it archived.
+func (j *Job) archiveBatch(ctx context.Context, cutoff time.Time) (int, error) {
+	messages, err := j.repo.FindArchivable(ctx, j.statuses, cutoff, j.batchSize)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	body, err := encode(messages)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode archive: %w", err)
+	}
+
+	archive := &repository.MessageArchive{
+		ID:             uuid.New(),
+		Format:         FormatJSONLGzip,
+		MessageCount:   len(messages),
+		SizeBytes:      int64(len(body)),
+		FirstCreatedAt: messages[0].CreatedAt().UTC(),
+		LastCreatedAt:  messages[len(messages)-1].CreatedAt().UTC(),
+		ArchivedAt:     j.now().UTC(),
+	}
+	archive.ObjectKey = j.objectKey(archive)
+
+	if err := j.store.Put(ctx, archive.ObjectKey, body, "application/gzip"); err != nil {
+		return 0, err
+	}
+	if err := j.archives.Add(ctx, archive); err != nil {
+		return 0, err
+	}
+	if _, err := j.repo.PurgeByIDs(ctx, messageIDs(messages)); err != nil {
+		return 0, err
+	}
+
+	metrics.MessagesArchived.Add(float64(len(messages)))
+	return len(messages), nil
+}
+
+// objectKey files an archive under the creation day of its oldest message,
+// e.g. messages/2024/01/31/<id>.jsonl.gz.
+func (j *Job) objectKey(archive *repository.MessageArchive) string {
+	return j.prefix + path.Join(
+		archive.FirstCreatedAt.Format("2006/01/02"),
+		archive.ID.String()+"."+archive.Format,
+	)
+}
+
+func (j *Job) recordAudit(ctx context.Context, result *Result) {
+	if j.audit == nil {
+		return
+	}
+
+	j.audit.Record(ctx, service.AuditRecord{
+		Action:       service.AuditActionArchiveRun,
+		ResourceType: service.AuditResourceArchive,
+		Details:      fmt.Sprintf("files=%d archived=%d", result.Files, result.Archived),
+	})
+}
+
+func messageIDs(messages []*entity.Message) []uuid.UUID {
+	ids := make([]uuid.UUID, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID()
+	}
+	return ids
+}
@@ -0,0 +1,203 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/testsupport"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMessageRepository hands out its messages oldest first and removes the
+// purged ones.
+type fakeMessageRepository struct {
+	repository.MessageRepository
+
+	messages []*entity.Message
+	cutoff   time.Time
+}
+
+func (r *fakeMessageRepository) FindArchivable(ctx context.Context, statuses []string, createdBefore time.Time, limit int) ([]*entity.Message, error) {
+	r.cutoff = createdBefore
+
+	var found []*entity.Message
+	for _, message := range r.messages {
+		if len(found) == limit {
+			break
+		}
+		for _, status := range statuses {
+			if message.Status().String() == status && message.CreatedAt().Before(createdBefore) {
+				found = append(found, message)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeMessageRepository) PurgeByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	purge := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		purge[id] = true
+	}
+
+	kept := r.messages[:0]
+	for _, message := range r.messages {
+		if !purge[message.ID()] {
+			kept = append(kept, message)
+		}
+	}
+	removed := int64(len(r.messages) - len(kept))
+	r.messages = kept
+	return removed, nil
+}
+
+type fakeArchiveRepository struct {
+	archives []*repository.MessageArchive
+}
+
+func (r *fakeArchiveRepository) Add(ctx context.Context, archive *repository.MessageArchive) error {
+	r.archives = append(r.archives, archive)
+	return nil
+}
+
+func (r *fakeArchiveRepository) List(ctx context.Context, filter repository.MessageArchiveFilter, limit, offset int) ([]*repository.MessageArchive, int64, error) {
+	return r.archives, int64(len(r.archives)), nil
+}
+
+type memoryStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[key] = body
+	return nil
+}
+
+func newTestJob(t *testing.T, repo *fakeMessageRepository, archives *fakeArchiveRepository, store *memoryStore) *Job {
+	t.Helper()
+
+	job, err := NewJob(repo, archives, store, nil, &config.ArchiveConfig{
+		MaxAge:    30 * 24 * time.Hour,
+		Statuses:  []string{"sent", "failed"},
+		Interval:  time.Hour,
+		BatchSize: 2,
+		Prefix:    "messages/",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return job
+}
+
+// decode reads the messages back from an archive file.
+func decode(t *testing.T, body []byte) []record {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var records []record
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var r record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	assert.NoError(t, scanner.Err())
+	return records
+}
+
+func TestNewJob_RejectsUnfinishedStatuses(t *testing.T) {
+	for _, statuses := range [][]string{{"pending"}, {"sent", "processing"}, {"unknown"}} {
+		_, err := NewJob(nil, nil, nil, nil, &config.ArchiveConfig{Statuses: statuses})
+		assert.Error(t, err, statuses)
+	}
+}
+
+func TestJob_RunArchivesOldMessagesInFiles(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-60 * 24 * time.Hour)
+	first := testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreatedAt(old).Build()
+	second := testsupport.NewMessage(t).Status(valueobject.MessageStatusFailed).CreatedAt(old.Add(time.Hour)).Build()
+	third := testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreatedAt(old.Add(48 * time.Hour)).Build()
+	recent := testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreatedAt(now.Add(-time.Hour)).Build()
+	pending := testsupport.NewMessage(t).CreatedAt(old).Build()
+
+	repo := &fakeMessageRepository{messages: []*entity.Message{first, pending, second, third, recent}}
+	archives := &fakeArchiveRepository{}
+	store := &memoryStore{objects: make(map[string][]byte)}
+	job := newTestJob(t, repo, archives, store)
+	job.now = func() time.Time { return now }
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.Equal(t, int64(3), result.Archived)
+	assert.Equal(t, now.Add(-30*24*time.Hour), repo.cutoff)
+	assert.Equal(t, []*entity.Message{pending, recent}, repo.messages)
+
+	if assert.Len(t, archives.archives, 2) {
+		manifest := archives.archives[0]
+		assert.Equal(t, "messages/2024/04/02/"+manifest.ID.String()+".jsonl.gz", manifest.ObjectKey)
+		assert.Equal(t, FormatJSONLGzip, manifest.Format)
+		assert.Equal(t, 2, manifest.MessageCount)
+		assert.Equal(t, first.CreatedAt(), manifest.FirstCreatedAt)
+		assert.Equal(t, second.CreatedAt(), manifest.LastCreatedAt)
+		assert.Equal(t, int64(len(store.objects[manifest.ObjectKey])), manifest.SizeBytes)
+
+		records := decode(t, store.objects[manifest.ObjectKey])
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, first.ID().String(), records[0].ID)
+			assert.Equal(t, "sent", records[0].Status)
+			assert.Equal(t, "+905551234567", records[0].PhoneNumber)
+			assert.Equal(t, "Test message", records[0].Content)
+			assert.Equal(t, "SERVER_ERROR", records[1].ErrorCode)
+		}
+		assert.Equal(t, 1, archives.archives[1].MessageCount)
+	}
+
+	status := job.Status()
+	assert.Equal(t, int64(3), status.TotalArchived)
+	assert.Empty(t, status.LastError)
+}
+
+func TestJob_RunKeepsMessagesWhenUploadFails(t *testing.T) {
+	// Arrange
+	now := time.Now().UTC()
+	message := testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreatedAt(now.Add(-60 * 24 * time.Hour)).Build()
+	repo := &fakeMessageRepository{messages: []*entity.Message{message}}
+	archives := &fakeArchiveRepository{}
+	store := &memoryStore{err: errors.New("bucket unavailable")}
+	job := newTestJob(t, repo, archives, store)
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Zero(t, result.Archived)
+	assert.Len(t, repo.messages, 1)
+	assert.Empty(t, archives.archives)
+	assert.Equal(t, "bucket unavailable", job.Status().LastError)
+}
@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// FormatJSONLGzip is the only archive format: one JSON record per line,
+// gzipped. Parquet would need a dependency this service does not carry.
+const FormatJSONLGzip = "jsonl.gz"
+
+// record is one archived message. Field names match the columns of the CSV
+// export, so both can be loaded with the same schema.
+type record struct {
+	ID                string            `json:"id"`
+	PhoneNumber       string            `json:"phone_number,omitempty"`
+	Content           string            `json:"content"`
+	ContentHash       string            `json:"content_hash"`
+	Status            string            `json:"status"`
+	Priority          string            `json:"priority"`
+	CreatedAt         time.Time         `json:"created_at"`
+	ScheduledAt       *time.Time        `json:"scheduled_at,omitempty"`
+	ExpiresAt         *time.Time        `json:"expires_at,omitempty"`
+	SentAt            *time.Time        `json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
+	Attempts          int               `json:"attempts"`
+	MaxAttempts       int               `json:"max_attempts"`
+	ErrorCode         string            `json:"error_code,omitempty"`
+	LastError         string            `json:"last_error,omitempty"`
+	WebhookMessageID  string            `json:"webhook_message_id,omitempty"`
+	WebhookResponse   string            `json:"webhook_response,omitempty"`
+	ProviderRequestID string            `json:"provider_request_id,omitempty"`
+	TraceID           string            `json:"trace_id,omitempty"`
+	ClientReference   string            `json:"client_reference,omitempty"`
+	TenantID          string            `json:"tenant_id,omitempty"`
+	CampaignID        string            `json:"campaign_id,omitempty"`
+	DestinationID     string            `json:"destination_id,omitempty"`
+	Channel           string            `json:"channel"`
+	Recipient         string            `json:"recipient"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+func toRecord(message *entity.Message) record {
+	phoneNumber := ""
+	if phone := message.PhoneNumber(); phone != nil {
+		phoneNumber = phone.String()
+	}
+
+	return record{
+		ID:                message.ID().String(),
+		PhoneNumber:       phoneNumber,
+		Content:           message.Content().String(),
+		ContentHash:       message.ContentHash(),
+		Status:            message.Status().String(),
+		Priority:          message.Priority().String(),
+		CreatedAt:         message.CreatedAt().UTC(),
+		ScheduledAt:       message.ScheduledAt(),
+		ExpiresAt:         message.ExpiresAt(),
+		SentAt:            message.SentAt(),
+		DeliveredAt:       message.DeliveredAt(),
+		Attempts:          message.Attempts(),
+		MaxAttempts:       message.MaxAttempts(),
+		ErrorCode:         message.ErrorCode(),
+		LastError:         message.LastError(),
+		WebhookMessageID:  message.WebhookMessageID(),
+		WebhookResponse:   message.WebhookResponse(),
+		ProviderRequestID: message.ProviderRequestID(),
+		TraceID:           message.TraceID(),
+		ClientReference:   message.IdempotencyKey(),
+		TenantID:          optionalID(message.TenantID()),
+		CampaignID:        optionalID(message.CampaignID()),
+		DestinationID:     optionalID(message.DestinationID()),
+		Channel:           message.Channel().String(),
+		Recipient:         message.Recipient().String(),
+		Metadata:          message.Metadata(),
+	}
+}
+
+// encode writes messages as gzipped JSON lines.
+func encode(messages []*entity.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, message := range messages {
+		if err := encoder.Encode(toRecord(message)); err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func optionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
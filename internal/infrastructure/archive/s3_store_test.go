@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestS3Store(t *testing.T, endpoint string, pathStyle bool) *S3Store {
+	t.Helper()
+
+	store, err := newS3Store(&config.ArchiveConfig{
+		Endpoint:  endpoint,
+		Bucket:    "archive-bucket",
+		PathStyle: pathStyle,
+	}, "eu-west-1", aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+	}), http.DefaultClient)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return store
+}
+
+func TestS3Store_PutSendsSignedRequest(t *testing.T) {
+	// Arrange
+	var method, path, authorization, contentHash string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		contentHash = r.Header.Get("X-Amz-Content-Sha256")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	store := newTestS3Store(t, server.URL, true)
+
+	// Act
+	err := store.Put(context.Background(), "messages/2024/01/31/a.jsonl.gz", []byte("archive"), "application/gzip")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/archive-bucket/messages/2024/01/31/a.jsonl.gz", path)
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), authorization)
+	assert.Contains(t, authorization, "/eu-west-1/s3/aws4_request")
+	sum := sha256.Sum256([]byte("archive"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), contentHash)
+	assert.Equal(t, "archive", string(body))
+}
+
+func TestS3Store_PutReportsErrorStatus(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+	}))
+	defer server.Close()
+	store := newTestS3Store(t, server.URL, true)
+
+	// Act
+	err := store.Put(context.Background(), "messages/a.jsonl.gz", []byte("archive"), "application/gzip")
+
+	// Assert
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "status 403")
+		assert.Contains(t, err.Error(), "AccessDenied")
+	}
+}
+
+func TestS3Store_ObjectURL(t *testing.T) {
+	virtualHost := newTestS3Store(t, "", false)
+	assert.Equal(t, "https://archive-bucket.s3.eu-west-1.amazonaws.com/messages/a%20b.jsonl.gz", virtualHost.objectURL("messages/a b.jsonl.gz"))
+
+	pathStyle := newTestS3Store(t, "https://storage.googleapis.com", true)
+	assert.Equal(t, "https://storage.googleapis.com/archive-bucket/messages/a.jsonl.gz", pathStyle.objectURL("messages/a.jsonl.gz"))
+}
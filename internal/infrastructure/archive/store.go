@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+)
+
+// Store writes archive files. Put replaces any object already under key.
+type Store interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// NewStore returns the store cfg.Store selects.
+func NewStore(ctx context.Context, cfg *config.ArchiveConfig) (Store, error) {
+	switch cfg.Store {
+	case config.ArchiveStoreFile:
+		return &fileStore{dir: cfg.Dir}, nil
+	case config.ArchiveStoreS3:
+		return NewS3Store(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown archive store %q", cfg.Store)
+	}
+}
+
+// fileStore writes archives below a local directory, for development and
+// single-host deployments that back the directory up themselves.
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Written under a temporary name first, so a crash never leaves a
+	// truncated file under the final one
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore_PutWritesUnderDir(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	store := &fileStore{dir: dir}
+
+	// Act
+	err := store.Put(context.Background(), "messages/2024/01/31/a.jsonl.gz", []byte("archive"), "application/gzip")
+	again := store.Put(context.Background(), "messages/2024/01/31/a.jsonl.gz", []byte("replaced"), "application/gzip")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, again)
+	body, readErr := os.ReadFile(filepath.Join(dir, "messages", "2024", "01", "31", "a.jsonl.gz"))
+	assert.NoError(t, readErr)
+	assert.Equal(t, "replaced", string(body))
+	entries, _ := os.ReadDir(filepath.Join(dir, "messages", "2024", "01", "31"))
+	assert.Len(t, entries, 1)
+}
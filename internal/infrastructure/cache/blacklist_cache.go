@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// BlacklistCache holds, per phone number, the tenants that blacklisted it
+// (uuid.Nil for a global entry). An empty list is cached as well, so numbers
+// that are not listed cost no database query either.
+type BlacklistCache interface {
+	// Get reports found false on a miss.
+	Get(ctx context.Context, phoneNumber string) (tenants []uuid.UUID, found bool, err error)
+	Set(ctx context.Context, phoneNumber string, tenants []uuid.UUID) error
+	Invalidate(ctx context.Context, phoneNumber string) error
+}
+
+type blacklistCache struct {
+	redis *RedisCache
+	ttl   time.Duration
+}
+
+// NewBlacklistCache keeps lookups for ttl. Writes invalidate the number they
+// touch, so ttl only bounds how long a failed invalidation goes unnoticed.
+func NewBlacklistCache(redis *RedisCache, ttl time.Duration) BlacklistCache {
+	return &blacklistCache{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+func (c *blacklistCache) Get(ctx context.Context, phoneNumber string) ([]uuid.UUID, bool, error) {
+	data, err := c.redis.Get(ctx, c.key(phoneNumber))
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read blacklist cache: %w", err)
+	}
+
+	var tenants []uuid.UUID
+	if err := json.Unmarshal([]byte(data), &tenants); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal blacklist cache: %w", err)
+	}
+	return tenants, true, nil
+}
+
+func (c *blacklistCache) Set(ctx context.Context, phoneNumber string, tenants []uuid.UUID) error {
+	if tenants == nil {
+		tenants = []uuid.UUID{}
+	}
+	data, err := json.Marshal(tenants)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blacklist cache: %w", err)
+	}
+
+	if err := c.redis.SetWithTTL(ctx, c.key(phoneNumber), data, c.ttl); err != nil {
+		return fmt.Errorf("failed to write blacklist cache: %w", err)
+	}
+	return nil
+}
+
+func (c *blacklistCache) Invalidate(ctx context.Context, phoneNumber string) error {
+	if err := c.redis.Delete(ctx, c.key(phoneNumber)); err != nil {
+		return fmt.Errorf("failed to invalidate blacklist cache: %w", err)
+	}
+	return nil
+}
+
+func (c *blacklistCache) key(phoneNumber string) string {
+	return fmt.Sprintf("blacklist:%s", phoneNumber)
+}
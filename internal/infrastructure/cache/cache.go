@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrCacheMiss is returned by Get for a key that is not cached. It is
+// redis.Nil, so a RedisCache miss matches it as well.
+var ErrCacheMiss = redis.Nil
+
+// Cache is the key-value store behind MessageCache. Values are stored as
+// strings; a []byte is stored as is and anything else in its default
+// format. Set uses the store's default TTL, as does SetMany, which stores
+// several keys in one round trip.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}) error
+	SetMany(ctx context.Context, values map[string]interface{}) error
+	SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string) (int64, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+// NoopCache stores nothing: every Get misses. It stands in for Redis when
+// the service runs without it.
+type NoopCache struct{}
+
+func (NoopCache) Get(context.Context, string) (string, error) {
+	return "", ErrCacheMiss
+}
+
+func (NoopCache) Set(context.Context, string, interface{}) error {
+	return nil
+}
+
+func (NoopCache) SetMany(context.Context, map[string]interface{}) error {
+	return nil
+}
+
+func (NoopCache) SetWithTTL(context.Context, string, interface{}, time.Duration) error {
+	return nil
+}
+
+func (NoopCache) Delete(context.Context, string) error {
+	return nil
+}
+
+func (NoopCache) Incr(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func (NoopCache) Exists(context.Context, string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"github.com/go-redis/redis/v8"
+)
+
+// dailyQuotaKeyTTL keeps a day's counter until well after the day ended,
+// whatever the clock skew between instances.
+const dailyQuotaKeyTTL = 48 * time.Hour
+
+// takeQuota counts one request, unless limit were counted already. Returns
+// the requests counted today, or -1 when the quota is used up.
+var takeQuota = redis.NewScript(`
+local used = redis.call('INCR', KEYS[1])
+if used == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+if used > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+	return -1
+end
+return used
+`)
+
+// DailyQuota counts the requests sent to a provider per UTC day in Redis, so
+// that every instance draws from the same quota.
+type DailyQuota struct {
+	redis *RedisCache
+	name  string
+	limit int
+	now   func() time.Time
+}
+
+// NewDailyQuota allows limit requests to provider name per UTC day.
+func NewDailyQuota(redis *RedisCache, name string, limit int) *DailyQuota {
+	return &DailyQuota{
+		redis: redis,
+		name:  name,
+		limit: limit,
+		now:   time.Now,
+	}
+}
+
+// Take counts one request against today's quota. Once the quota is used up
+// it reports false, counts nothing, and returns when the next day starts.
+func (q *DailyQuota) Take(ctx context.Context) (bool, time.Time, error) {
+	today := q.now().UTC().Truncate(24 * time.Hour)
+	resetAt := today.Add(24 * time.Hour)
+	key := fmt.Sprintf("provider:quota:%s:%s", q.name, today.Format("2006-01-02"))
+
+	start := time.Now()
+	used, err := takeQuota.Run(ctx, q.redis.client, []string{key},
+		q.limit, int(dailyQuotaKeyTTL.Seconds())).Int()
+	observe("daily_quota", start, err)
+	if err != nil {
+		return false, resetAt, fmt.Errorf("failed to take from the daily quota: %w", err)
+	}
+	if used < 0 {
+		metrics.ProviderDailyQuotaUsed.WithLabelValues(q.name).Set(float64(q.limit))
+		return false, resetAt, nil
+	}
+	metrics.ProviderDailyQuotaUsed.WithLabelValues(q.name).Set(float64(used))
+	return true, resetAt, nil
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// FallbackCache serves from primary and switches to fallback when primary
+// fails, so an outage costs one warning instead of one per call. Primary is
+// tried again once every retryInterval; the first call that succeeds
+// switches back.
+//
+// Keys written during an outage are not copied back, so cached reads can be
+// stale for up to their TTL after primary returns.
+type FallbackCache struct {
+	primary       Cache
+	fallback      Cache
+	retryInterval time.Duration
+
+	mu         sync.Mutex
+	inFallback bool
+	retryAt    time.Time
+}
+
+func NewFallbackCache(primary, fallback Cache, retryInterval time.Duration) *FallbackCache {
+	return &FallbackCache{
+		primary:       primary,
+		fallback:      fallback,
+		retryInterval: retryInterval,
+	}
+}
+
+// InFallback reports whether calls are served by the fallback cache.
+func (c *FallbackCache) InFallback() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.inFallback
+}
+
+func (c *FallbackCache) Get(ctx context.Context, key string) (string, error) {
+	return withFallback(ctx, c, func(store Cache) (string, error) {
+		return store.Get(ctx, key)
+	})
+}
+
+func (c *FallbackCache) Set(ctx context.Context, key string, value interface{}) error {
+	_, err := withFallback(ctx, c, func(store Cache) (struct{}, error) {
+		return struct{}{}, store.Set(ctx, key, value)
+	})
+	return err
+}
+
+func (c *FallbackCache) SetMany(ctx context.Context, values map[string]interface{}) error {
+	_, err := withFallback(ctx, c, func(store Cache) (struct{}, error) {
+		return struct{}{}, store.SetMany(ctx, values)
+	})
+	return err
+}
+
+func (c *FallbackCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	_, err := withFallback(ctx, c, func(store Cache) (struct{}, error) {
+		return struct{}{}, store.SetWithTTL(ctx, key, value, ttl)
+	})
+	return err
+}
+
+func (c *FallbackCache) Delete(ctx context.Context, key string) error {
+	_, err := withFallback(ctx, c, func(store Cache) (struct{}, error) {
+		return struct{}{}, store.Delete(ctx, key)
+	})
+	return err
+}
+
+func (c *FallbackCache) Incr(ctx context.Context, key string) (int64, error) {
+	return withFallback(ctx, c, func(store Cache) (int64, error) {
+		return store.Incr(ctx, key)
+	})
+}
+
+func (c *FallbackCache) Exists(ctx context.Context, key string) (bool, error) {
+	return withFallback(ctx, c, func(store Cache) (bool, error) {
+		return store.Exists(ctx, key)
+	})
+}
+
+// withFallback runs call against primary unless it is down, and against the
+// fallback when primary fails. A miss, or a call the caller gave up on, is
+// not a failure.
+func withFallback[T any](ctx context.Context, c *FallbackCache, call func(Cache) (T, error)) (T, error) {
+	if !c.tryPrimary() {
+		return call(c.fallback)
+	}
+
+	value, err := call(c.primary)
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		if ctx.Err() != nil {
+			return value, err
+		}
+		c.primaryFailed(ctx, err)
+		return call(c.fallback)
+	}
+
+	c.primaryServed(ctx)
+	return value, err
+}
+
+func (c *FallbackCache) tryPrimary() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.inFallback {
+		return true
+	}
+	if time.Now().Before(c.retryAt) {
+		return false
+	}
+	// One caller probes primary; the rest stay on the fallback until it
+	// answers or the next interval
+	c.retryAt = time.Now().Add(c.retryInterval)
+	return true
+}
+
+func (c *FallbackCache) primaryServed(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.inFallback {
+		return
+	}
+	c.inFallback = false
+	metrics.CacheFallback.Set(0)
+	logger.FromContext(ctx).Info("cache reachable again, leaving in-memory fallback")
+}
+
+func (c *FallbackCache) primaryFailed(ctx context.Context, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retryAt = time.Now().Add(c.retryInterval)
+	if c.inFallback {
+		return
+	}
+	c.inFallback = true
+	metrics.CacheFallback.Set(1)
+	logger.FromContext(ctx).Warn("cache unreachable, using in-memory fallback",
+		zap.Error(err),
+		zap.Duration("retry_interval", c.retryInterval),
+	)
+}
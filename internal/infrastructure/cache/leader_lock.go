@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// acquireLeaderLock takes the lock when it is free and extends it when the
+// caller already holds it. Returns 1 when the caller holds the lock.
+var acquireLeaderLock = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaderLock deletes the lock only if the caller still holds it, so a
+// holder whose lock already expired cannot drop its successor's.
+var releaseLeaderLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// LeaderLock is a single Redis key naming the instance allowed to dispatch.
+// It expires on its own when the holder stops renewing it.
+type LeaderLock struct {
+	redis *RedisCache
+	key   string
+}
+
+func NewLeaderLock(redis *RedisCache, key string) *LeaderLock {
+	return &LeaderLock{redis: redis, key: key}
+}
+
+// TryAcquire takes or renews the lock for holderID for ttl and reports
+// whether holderID holds it afterwards.
+func (l *LeaderLock) TryAcquire(ctx context.Context, holderID string, ttl time.Duration) (bool, error) {
+	start := time.Now()
+	held, err := acquireLeaderLock.Run(ctx, l.redis.client, []string{l.key}, holderID, ttl.Milliseconds()).Int()
+	observe("leader_lock_acquire", start, err)
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+func (l *LeaderLock) Release(ctx context.Context, holderID string) error {
+	start := time.Now()
+	err := releaseLeaderLock.Run(ctx, l.redis.client, []string{l.key}, holderID).Err()
+	observe("leader_lock_release", start, err)
+	return err
+}
+
+// Holder returns the current holder, or "" when the lock is free.
+func (l *LeaderLock) Holder(ctx context.Context) (string, error) {
+	holder, err := l.redis.Get(ctx, l.key)
+	if err == redis.Nil {
+		return "", nil
+	}
+	return holder, err
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewMemoryCache(2, 0)
+	assert.NoError(t, c.Set(ctx, "a", "1"))
+	assert.NoError(t, c.Set(ctx, "b", []byte("2")))
+	_, _ = c.Get(ctx, "a")
+
+	// Act
+	assert.NoError(t, c.Set(ctx, "c", 3))
+
+	// Assert
+	_, err := c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	value, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	value, err = c.Get(ctx, "c")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", value)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestMemoryCache_ExpiresAndIncrements(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewMemoryCache(10, time.Hour)
+	assert.NoError(t, c.SetWithTTL(ctx, "short", "x", time.Millisecond))
+
+	// Act
+	time.Sleep(5 * time.Millisecond)
+	exists, _ := c.Exists(ctx, "short")
+	first, firstErr := c.Incr(ctx, "generation")
+	second, _ := c.Incr(ctx, "generation")
+	_ = c.Set(ctx, "text", "abc")
+	_, incrErr := c.Incr(ctx, "text")
+
+	// Assert
+	assert.False(t, exists)
+	assert.NoError(t, firstErr)
+	assert.Equal(t, int64(1), first)
+	assert.Equal(t, int64(2), second)
+	assert.Error(t, incrErr)
+}
+
+// brokenCache fails Get while err is set, like an unreachable Redis.
+type brokenCache struct {
+	*MemoryCache
+	calls int
+	err   error
+}
+
+func (b *brokenCache) Get(ctx context.Context, key string) (string, error) {
+	b.calls++
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.MemoryCache.Get(ctx, key)
+}
+
+func TestFallbackCache_ServesFromFallbackUntilPrimaryAnswers(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	primary := &brokenCache{MemoryCache: NewMemoryCache(10, 0), err: errors.New("connection refused")}
+	fallback := NewMemoryCache(10, 0)
+	_ = fallback.Set(ctx, "key", "from memory")
+	c := NewFallbackCache(primary, fallback, 20*time.Millisecond)
+
+	// Act
+	first, firstErr := c.Get(ctx, "key")
+	second, _ := c.Get(ctx, "key")
+	callsDuringOutage := primary.calls
+	inFallback := c.InFallback()
+
+	primary.err = nil
+	_ = primary.MemoryCache.Set(ctx, "key", "from primary")
+	time.Sleep(30 * time.Millisecond)
+	recovered, _ := c.Get(ctx, "key")
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.Equal(t, "from memory", first)
+	assert.Equal(t, "from memory", second)
+	assert.Equal(t, 1, callsDuringOutage, "primary is not retried before the interval")
+	assert.True(t, inFallback)
+	assert.Equal(t, "from primary", recovered)
+	assert.False(t, c.InFallback())
+}
+
+func TestFallbackCache_MissDoesNotFallBack(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	c := NewFallbackCache(NewMemoryCache(10, 0), NewMemoryCache(10, 0), time.Minute)
+
+	// Act
+	_, err := c.Get(ctx, "missing")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	assert.False(t, c.InFallback())
+}
+
+func TestMessageCache_CacheSentMessagesInOneWrite(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := NewMemoryCache(10, 0)
+	messageCache := NewMessageCache(store, 0, 0)
+	sentAt := time.Now().UTC().Truncate(time.Second)
+
+	// Act
+	err := messageCache.CacheSentMessages(ctx, []*CachedMessage{
+		{MessageID: "first", WebhookMessageID: "webhook-1", SentAt: sentAt},
+		{MessageID: "second", WebhookMessageID: "webhook-2", SentAt: sentAt},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, store.Len())
+	second, err := messageCache.GetSentMessage(ctx, "second")
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook-2", second.WebhookMessageID)
+	assert.True(t, sentAt.Equal(second.SentAt))
+	assert.NoError(t, messageCache.CacheSentMessages(ctx, nil))
+}
@@ -13,23 +13,43 @@ import (
 type CachedMessage struct {
 	MessageID        string    `json:"message_id"`
 	WebhookMessageID string    `json:"webhook_message_id"`
+	ContentHash      string    `json:"content_hash"`
 	SentAt           time.Time `json:"sent_at"`
 	PhoneNumber      string    `json:"phone_number"`
 }
 
+// MessageCache also fronts the sent message list and the stats with a
+// cache-aside read cache. Reads are cached per scope (a tenant ID, or "all"
+// for the unscoped view); on a miss load runs and its result is cached.
+// InvalidateSentMessages drops every cached read of the given scopes.
+//
+// CacheSentMessages caches a batch of sent messages in one round trip.
 type MessageCache interface {
 	CacheSentMessage(ctx context.Context, msg *CachedMessage) error
+	CacheSentMessages(ctx context.Context, msgs []*CachedMessage) error
 	GetSentMessage(ctx context.Context, messageID string) (*CachedMessage, error)
 	IsCached(ctx context.Context, messageID string) (bool, error)
+
+	GetOrLoadSentPage(ctx context.Context, scope string, page, pageSize int, load func() ([]byte, error)) ([]byte, error)
+	GetOrLoadStats(ctx context.Context, scope string, load func() ([]byte, error)) ([]byte, error)
+	InvalidateSentMessages(ctx context.Context, scopes ...string) error
 }
 
 type messageCache struct {
-	redis *RedisCache
+	store    Cache
+	readTTL  time.Duration
+	statsTTL time.Duration
 }
 
-func NewMessageCache(redis *RedisCache) MessageCache {
+// NewMessageCache caches sent pages for readTTL and stats for statsTTL. The
+// stats back the total count of every sent page, so they are cached on their
+// own and shared by all pages. A TTL of 0 turns that part of the cache off,
+// so every read misses and nothing is stored.
+func NewMessageCache(store Cache, readTTL, statsTTL time.Duration) MessageCache {
 	return &messageCache{
-		redis: redis,
+		store:    store,
+		readTTL:  readTTL,
+		statsTTL: statsTTL,
 	}
 }
 
@@ -38,22 +58,22 @@ func (c *messageCache) CacheSentMessage(ctx context.Context, msg *CachedMessage)
 
 	data, err := json.Marshal(msg)
 	if err != nil {
-		logger.Get().Error("failed to marshal cached message",
+		logger.FromContext(ctx).Error("failed to marshal cached message",
 			zap.Error(err),
 			zap.String("message_id", msg.MessageID),
 		)
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	if err := c.redis.Set(ctx, key, data); err != nil {
-		logger.Get().Error("failed to cache sent message",
+	if err := c.store.Set(ctx, key, data); err != nil {
+		logger.FromContext(ctx).Error("failed to cache sent message",
 			zap.Error(err),
 			zap.String("message_id", msg.MessageID),
 		)
 		return fmt.Errorf("failed to cache message: %w", err)
 	}
 
-	logger.Get().Debug("cached sent message",
+	logger.FromContext(ctx).Debug("cached sent message",
 		zap.String("message_id", msg.MessageID),
 		zap.String("webhook_message_id", msg.WebhookMessageID),
 	)
@@ -61,10 +81,37 @@ func (c *messageCache) CacheSentMessage(ctx context.Context, msg *CachedMessage)
 	return nil
 }
 
+func (c *messageCache) CacheSentMessages(ctx context.Context, msgs []*CachedMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(msgs))
+	for _, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message %s: %w", msg.MessageID, err)
+		}
+		values[c.buildKey(msg.MessageID)] = data
+	}
+
+	if err := c.store.SetMany(ctx, values); err != nil {
+		logger.FromContext(ctx).Error("failed to cache sent messages",
+			zap.Error(err),
+			zap.Int("count", len(msgs)),
+		)
+		return fmt.Errorf("failed to cache messages: %w", err)
+	}
+
+	logger.FromContext(ctx).Debug("cached sent messages", zap.Int("count", len(msgs)))
+
+	return nil
+}
+
 func (c *messageCache) GetSentMessage(ctx context.Context, messageID string) (*CachedMessage, error) {
 	key := c.buildKey(messageID)
 
-	data, err := c.redis.Get(ctx, key)
+	data, err := c.store.Get(ctx, key)
 	if err != nil {
 		return nil, fmt.Errorf("message not found in cache: %w", err)
 	}
@@ -79,9 +126,73 @@ func (c *messageCache) GetSentMessage(ctx context.Context, messageID string) (*C
 
 func (c *messageCache) IsCached(ctx context.Context, messageID string) (bool, error) {
 	key := c.buildKey(messageID)
-	return c.redis.Exists(ctx, key)
+	return c.store.Exists(ctx, key)
 }
 
 func (c *messageCache) buildKey(messageID string) string {
 	return fmt.Sprintf("message:sent:%s", messageID)
 }
+
+func (c *messageCache) GetOrLoadSentPage(ctx context.Context, scope string, page, pageSize int, load func() ([]byte, error)) ([]byte, error) {
+	return c.getOrLoad(ctx, scope, fmt.Sprintf("sent:%d:%d", page, pageSize), c.readTTL, load)
+}
+
+func (c *messageCache) GetOrLoadStats(ctx context.Context, scope string, load func() ([]byte, error)) ([]byte, error) {
+	return c.getOrLoad(ctx, scope, "stats", c.statsTTL, load)
+}
+
+// InvalidateSentMessages bumps the generation of each scope. Read keys embed
+// the generation, so older entries are never read again and simply expire.
+func (c *messageCache) InvalidateSentMessages(ctx context.Context, scopes ...string) error {
+	if c.readTTL <= 0 && c.statsTTL <= 0 {
+		return nil
+	}
+
+	for _, scope := range scopes {
+		if _, err := c.store.Incr(ctx, c.generationKey(scope)); err != nil {
+			return fmt.Errorf("failed to invalidate cached reads: %w", err)
+		}
+	}
+	return nil
+}
+
+// getOrLoad stores the loaded value under the generation read before
+// loading, so a result that raced with an invalidation is filed under the
+// old generation and never served. Cache errors only cost the cache: the
+// value is loaded and returned regardless.
+func (c *messageCache) getOrLoad(ctx context.Context, scope, name string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	if ttl <= 0 {
+		return load()
+	}
+
+	generation, err := c.store.Get(ctx, c.generationKey(scope))
+	if err == ErrCacheMiss {
+		generation = "0"
+	} else if err != nil {
+		logger.FromContext(ctx).Warn("failed to read cache generation", zap.Error(err), zap.String("scope", scope))
+		return load()
+	}
+	key := fmt.Sprintf("messages:read:%s:%s:%s", scope, generation, name)
+
+	data, err := c.store.Get(ctx, key)
+	if err == nil {
+		return []byte(data), nil
+	}
+	if err != ErrCacheMiss {
+		logger.FromContext(ctx).Warn("failed to read cached value", zap.Error(err), zap.String("key", key))
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.store.SetWithTTL(ctx, key, value, ttl); err != nil {
+		logger.FromContext(ctx).Warn("failed to cache value", zap.Error(err), zap.String("key", key))
+	}
+	return value, nil
+}
+
+func (c *messageCache) generationKey(scope string) string {
+	return fmt.Sprintf("messages:read:%s:generation", scope)
+}
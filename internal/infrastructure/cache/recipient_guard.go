@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+type RecipientDecision int
+
+const (
+	RecipientAllowed RecipientDecision = iota
+	RecipientRateLimited
+	RecipientDuplicate
+	// RecipientCollapsed is a duplicate to be answered with the message it
+	// repeats, DuplicateOf, instead of an error
+	RecipientCollapsed
+)
+
+// RecipientReservation is the outcome of RecipientGuard.Reserve. An allowed
+// reservation holds a slot in the recipient's window until released.
+type RecipientReservation struct {
+	Decision    RecipientDecision
+	DuplicateOf uuid.UUID
+
+	rateKey    string
+	rateMember string
+	dedupKey   string
+}
+
+// RecipientGuard limits how many messages one phone number receives within
+// a sliding window and optionally rejects identical content sent to the same
+// number again within a dedup window.
+type RecipientGuard interface {
+	// Reserve admits messageID to phoneNumber; the ID is remembered so a
+	// later duplicate can be collapsed into it.
+	Reserve(ctx context.Context, phoneNumber, contentHash string, messageID uuid.UUID) (*RecipientReservation, error)
+	// Release gives back an allowed reservation whose message was not stored.
+	Release(ctx context.Context, reservation *RecipientReservation) error
+}
+
+// reserveRateSlot trims the window, then adds the member only if fewer than
+// the limit remain. Returns 1 when the slot was taken.
+var reserveRateSlot = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', tonumber(ARGV[1]) - tonumber(ARGV[2]))
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+type recipientGuard struct {
+	redis       *RedisCache
+	limit       int
+	window      time.Duration
+	dedupWindow time.Duration
+	collapse    bool
+}
+
+// NewRecipientGuard allows at most limit messages per number within window
+// (limit 0 disables the rate check) and rejects repeated content within
+// dedupWindow (0 disables deduplication). With collapse, repeated content
+// is reported as RecipientCollapsed rather than rejected.
+func NewRecipientGuard(redis *RedisCache, limit int, window, dedupWindow time.Duration, collapse bool) RecipientGuard {
+	return &recipientGuard{
+		redis:       redis,
+		limit:       limit,
+		window:      window,
+		dedupWindow: dedupWindow,
+		collapse:    collapse,
+	}
+}
+
+func (g *recipientGuard) Reserve(ctx context.Context, phoneNumber, contentHash string, messageID uuid.UUID) (*RecipientReservation, error) {
+	reservation := &RecipientReservation{Decision: RecipientAllowed}
+
+	if g.limit > 0 {
+		key := fmt.Sprintf("recipient:rate:%s", phoneNumber)
+		member := uuid.New().String()
+
+		start := time.Now()
+		taken, err := reserveRateSlot.Run(ctx, g.redis.client, []string{key},
+			start.UnixMilli(), g.window.Milliseconds(), g.limit, member).Int()
+		observe("recipient_rate", start, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check recipient rate: %w", err)
+		}
+		if taken == 0 {
+			reservation.Decision = RecipientRateLimited
+			return reservation, nil
+		}
+
+		reservation.rateKey = key
+		reservation.rateMember = member
+	}
+
+	if g.dedupWindow > 0 {
+		key := fmt.Sprintf("recipient:dedup:%s:%s", phoneNumber, contentHash)
+
+		start := time.Now()
+		claimed, err := g.redis.client.SetNX(ctx, key, messageID.String(), g.dedupWindow).Result()
+		observe("setnx", start, err)
+		if err != nil {
+			g.Release(ctx, reservation)
+			return nil, fmt.Errorf("failed to check duplicate content: %w", err)
+		}
+		if !claimed {
+			g.Release(ctx, reservation)
+			return g.duplicate(ctx, key), nil
+		}
+
+		reservation.dedupKey = key
+	}
+
+	return reservation, nil
+}
+
+// duplicate reports repeated content under key. It is only collapsed when
+// the message it repeats can still be read from the key.
+func (g *recipientGuard) duplicate(ctx context.Context, key string) *RecipientReservation {
+	if !g.collapse {
+		return &RecipientReservation{Decision: RecipientDuplicate}
+	}
+
+	start := time.Now()
+	value, err := g.redis.client.Get(ctx, key).Result()
+	observe("get", start, err)
+	original, parseErr := uuid.Parse(value)
+	if err != nil || parseErr != nil {
+		return &RecipientReservation{Decision: RecipientDuplicate}
+	}
+	return &RecipientReservation{Decision: RecipientCollapsed, DuplicateOf: original}
+}
+
+func (g *recipientGuard) Release(ctx context.Context, reservation *RecipientReservation) error {
+	if reservation.rateKey != "" {
+		start := time.Now()
+		err := g.redis.client.ZRem(ctx, reservation.rateKey, reservation.rateMember).Err()
+		observe("zrem", start, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	if reservation.dedupKey != "" {
+		if err := g.redis.Delete(ctx, reservation.dedupKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
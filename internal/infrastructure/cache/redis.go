@@ -7,6 +7,7 @@ import (
 
 	"github.com/eneskaya/insider-messaging/pkg/config"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
 )
@@ -54,21 +55,88 @@ func (r *RedisCache) HealthCheck(ctx context.Context) error {
 }
 
 func (r *RedisCache) Set(ctx context.Context, key string, value interface{}) error {
-	return r.client.Set(ctx, key, value, r.ttl).Err()
+	start := time.Now()
+	err := r.client.Set(ctx, key, value, r.ttl).Err()
+	observe("set", start, err)
+	return err
+}
+
+// SetMany sends one SET per key in a single pipeline. MSET would save the
+// commands but cannot give the keys a TTL.
+func (r *RedisCache) SetMany(ctx context.Context, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, r.ttl)
+		}
+		return nil
+	})
+	observe("set_many", start, err)
+	return err
+}
+
+// SetWithTTL stores value under key for ttl instead of the default cache TTL.
+func (r *RedisCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	start := time.Now()
+	err := r.client.Set(ctx, key, value, ttl).Err()
+	observe("set", start, err)
+	return err
 }
 
 func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
-	return r.client.Get(ctx, key).Result()
+	start := time.Now()
+	value, err := r.client.Get(ctx, key).Result()
+	observe("get", start, err)
+	return value, err
 }
 
 func (r *RedisCache) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+	start := time.Now()
+	err := r.client.Del(ctx, key).Err()
+	observe("del", start, err)
+	return err
+}
+
+func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	start := time.Now()
+	value, err := r.client.Incr(ctx, key).Result()
+	observe("incr", start, err)
+	return value, err
 }
 
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
 	result, err := r.client.Exists(ctx, key).Result()
+	observe("exists", start, err)
 	if err != nil {
 		return false, err
 	}
 	return result > 0, nil
 }
+
+// XAdd appends an entry to stream, trimming it to roughly maxLen entries
+// (0 = unbounded). It returns the ID Redis assigned.
+func (r *RedisCache) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	start := time.Now()
+	id, err := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+	observe("xadd", start, err)
+	return id, err
+}
+
+// observe labels a cache miss separately so it does not count as an error
+func observe(command string, start time.Time, err error) {
+	result := metrics.Outcome(err)
+	if err == redis.Nil {
+		result = "miss"
+	}
+	metrics.ObserveRedis(command, result, start)
+}
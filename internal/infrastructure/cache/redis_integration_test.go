@@ -0,0 +1,112 @@
+//go:build integration
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/internal/testsupport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_LeaderLock(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache := testsupport.Redis(t)
+	lock := cache.NewLeaderLock(redisCache, "scheduler:leader")
+
+	// Act
+	first, firstErr := lock.TryAcquire(ctx, "instance-a", time.Minute)
+	contended, _ := lock.TryAcquire(ctx, "instance-b", time.Minute)
+	renewed, _ := lock.TryAcquire(ctx, "instance-a", time.Minute)
+	assert.NoError(t, lock.Release(ctx, "instance-b"))
+	holderAfterForeignRelease, _ := lock.Holder(ctx)
+	assert.NoError(t, lock.Release(ctx, "instance-a"))
+	holderAfterRelease, _ := lock.Holder(ctx)
+	taken, _ := lock.TryAcquire(ctx, "instance-b", 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	holderAfterExpiry, _ := lock.Holder(ctx)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.True(t, first)
+	assert.False(t, contended)
+	assert.True(t, renewed)
+	assert.Equal(t, "instance-a", holderAfterForeignRelease, "only the holder can release the lock")
+	assert.Empty(t, holderAfterRelease)
+	assert.True(t, taken)
+	assert.Empty(t, holderAfterExpiry)
+}
+
+func TestIntegration_RedisCache_ExpiresAndIncrements(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache := testsupport.Redis(t)
+
+	// Act
+	assert.NoError(t, redisCache.SetWithTTL(ctx, "short", "value", 50*time.Millisecond))
+	value, err := redisCache.Get(ctx, "short")
+	time.Sleep(100 * time.Millisecond)
+	_, expiredErr := redisCache.Get(ctx, "short")
+	first, _ := redisCache.Incr(ctx, "counter")
+	second, _ := redisCache.Incr(ctx, "counter")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.ErrorIs(t, expiredErr, cache.ErrCacheMiss)
+	assert.Equal(t, int64(1), first)
+	assert.Equal(t, int64(2), second)
+}
+
+func TestIntegration_RedisCache_SetMany(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache := testsupport.Redis(t)
+	values := make(map[string]interface{}, 500)
+	for i := 0; i < 500; i++ {
+		values[fmt.Sprintf("message:sent:%d", i)] = []byte(fmt.Sprintf(`{"message_id":"%d"}`, i))
+	}
+
+	// Act
+	err := redisCache.SetMany(ctx, values)
+	last, getErr := redisCache.Get(ctx, "message:sent:499")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, getErr)
+	assert.Equal(t, `{"message_id":"499"}`, last)
+	assert.NoError(t, redisCache.SetMany(ctx, nil))
+}
+
+func TestIntegration_DailyQuota_SharedAcrossInstances(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	redisCache := testsupport.Redis(t)
+	name := fmt.Sprintf("provider-%d", time.Now().UnixNano())
+	first := cache.NewDailyQuota(redisCache, name, 3)
+	second := cache.NewDailyQuota(redisCache, name, 3)
+
+	// Act
+	var allowed int
+	for i := 0; i < 3; i++ {
+		for _, quota := range []*cache.DailyQuota{first, second} {
+			ok, _, err := quota.Take(ctx)
+			assert.NoError(t, err)
+			if ok {
+				allowed++
+			}
+		}
+	}
+	ok, resetAt, err := first.Take(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, allowed)
+	assert.False(t, ok)
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour).Add(24*time.Hour), resetAt)
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+type SendState int
+
+const (
+	// SendAcquired means the caller holds the guard and may send
+	SendAcquired SendState = iota
+	// SendInFlight means another sender holds the guard
+	SendInFlight
+	// SendCompleted means the message was already sent; the claim carries
+	// the recorded result
+	SendCompleted
+)
+
+// SendRecord is the provider's answer to a send, kept in the guard key so a
+// sender that finds the message already sent can store it without sending
+// again.
+type SendRecord struct {
+	WebhookMessageID  string    `json:"webhook_message_id"`
+	ProviderRequestID string    `json:"provider_request_id,omitempty"`
+	Response          string    `json:"response"`
+	SentAt            time.Time `json:"sent_at"`
+}
+
+// SendClaim is the outcome of SendGuard.Acquire.
+type SendClaim struct {
+	State  SendState
+	Record *SendRecord
+
+	key   string
+	token string
+}
+
+// SendGuard makes sure a message is handed to the provider at most once,
+// even when two workers end up holding the same message because a database
+// claim was lost or duplicated.
+type SendGuard interface {
+	Acquire(ctx context.Context, messageID uuid.UUID) (*SendClaim, error)
+	// Complete records the result of a send made under an acquired claim.
+	Complete(ctx context.Context, claim *SendClaim, record *SendRecord) error
+	// Release gives back an acquired claim whose send failed, so the
+	// message can be retried.
+	Release(ctx context.Context, claim *SendClaim) error
+}
+
+// inFlightPrefix marks the value of a guard key that is held by a sender;
+// any other value is a SendRecord
+const inFlightPrefix = "sending:"
+
+// releaseSendGuard deletes the key only while it still holds the caller's
+// token, so a sender whose hold expired cannot drop someone else's.
+var releaseSendGuard = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type sendGuard struct {
+	redis     *RedisCache
+	holdTTL   time.Duration
+	resultTTL time.Duration
+}
+
+// NewSendGuard holds a message for a sender for up to holdTTL, which bounds
+// how long a sender that crashed mid-send blocks the message, and remembers
+// a completed send for resultTTL.
+func NewSendGuard(redis *RedisCache, holdTTL, resultTTL time.Duration) SendGuard {
+	return &sendGuard{
+		redis:     redis,
+		holdTTL:   holdTTL,
+		resultTTL: resultTTL,
+	}
+}
+
+func (g *sendGuard) Acquire(ctx context.Context, messageID uuid.UUID) (*SendClaim, error) {
+	key := fmt.Sprintf("send:guard:%s", messageID)
+	token := inFlightPrefix + uuid.New().String()
+
+	start := time.Now()
+	acquired, err := g.redis.client.SetNX(ctx, key, token, g.holdTTL).Result()
+	observe("setnx", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire send guard: %w", err)
+	}
+	if acquired {
+		return &SendClaim{State: SendAcquired, key: key, token: token}, nil
+	}
+
+	value, err := g.redis.Get(ctx, key)
+	if err == redis.Nil {
+		// The holder released it in between; the next cycle gets another try
+		return &SendClaim{State: SendInFlight}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read send guard: %w", err)
+	}
+	if strings.HasPrefix(value, inFlightPrefix) {
+		return &SendClaim{State: SendInFlight}, nil
+	}
+
+	var record SendRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, fmt.Errorf("failed to decode send guard: %w", err)
+	}
+	return &SendClaim{State: SendCompleted, Record: &record}, nil
+}
+
+func (g *sendGuard) Complete(ctx context.Context, claim *SendClaim, record *SendRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return g.redis.SetWithTTL(ctx, claim.key, data, g.resultTTL)
+}
+
+func (g *sendGuard) Release(ctx context.Context, claim *SendClaim) error {
+	start := time.Now()
+	err := releaseSendGuard.Run(ctx, g.redis.client, []string{claim.key}, claim.token).Err()
+	observe("send_guard_release", start, err)
+	return err
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+import (
+	"sync"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Bus fans message events out to in-process subscribers. Delivery is best
+// effort: a subscriber that is not keeping up misses events rather than
+// blocking the publisher, so consumers must be able to fall back to the
+// repository.
+type Bus interface {
+	Publish(evt event.MessageEvent)
+	Subscribe(buffer int) (<-chan event.MessageEvent, func())
+}
+
+type inMemoryBus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]chan event.MessageEvent
+}
+
+func NewInMemoryBus() Bus {
+	return &inMemoryBus{
+		subscribers: make(map[int]chan event.MessageEvent),
+	}
+}
+
+func (b *inMemoryBus) Publish(evt event.MessageEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- evt:
+		default:
+			logger.Get().Debug("dropping event for slow subscriber",
+				zap.String("type", string(evt.Type)),
+				zap.String("message_id", evt.MessageID.String()),
+			)
+		}
+	}
+}
+
+func (b *inMemoryBus) Subscribe(buffer int) (<-chan event.MessageEvent, func()) {
+	ch := make(chan event.MessageEvent, buffer)
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
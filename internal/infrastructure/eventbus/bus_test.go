@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryBus_PublishSubscribe(t *testing.T) {
+	bus := NewInMemoryBus()
+	first, unsubscribeFirst := bus.Subscribe(1)
+	second, unsubscribeSecond := bus.Subscribe(1)
+	defer unsubscribeFirst()
+	defer unsubscribeSecond()
+
+	evt := event.NewMessageEvent(event.TypeMessageStatusChanged, uuid.New(), valueobject.MessageStatusSent)
+	bus.Publish(evt)
+
+	assert.Equal(t, evt, <-first)
+	assert.Equal(t, evt, <-second)
+}
+
+func TestInMemoryBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewInMemoryBus()
+	ch, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	id := uuid.New()
+	bus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, id, valueobject.MessageStatusProcessing))
+	bus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, id, valueobject.MessageStatusSent))
+
+	assert.Equal(t, valueobject.MessageStatusProcessing, (<-ch).Status)
+	assert.Len(t, ch, 0)
+}
+
+func TestInMemoryBus_Unsubscribe(t *testing.T) {
+	bus := NewInMemoryBus()
+	ch, unsubscribe := bus.Subscribe(1)
+
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(event.NewMessageEvent(event.TypeMessageCreated, uuid.New(), valueobject.MessageStatusPending))
+
+	_, open := <-ch
+	assert.False(t, open)
+}
@@ -0,0 +1,196 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DispatchLeaseName = "message-dispatch"
+
+	RolePrimary = "primary"
+	RoleStandby = "standby"
+)
+
+// Elector keeps the dispatch lease alive for this deployment. Leadership is
+// considered lost locally one renew interval before the lease expires in the
+// database, so a holder that cannot reach the database stops dispatching
+// before any other region is allowed to take over.
+type Elector struct {
+	repo          repository.LeaseRepository
+	holderID      string
+	region        string
+	role          string
+	ttl           time.Duration
+	renewInterval time.Duration
+	takeoverDelay time.Duration
+
+	mu         sync.RWMutex
+	leader     bool
+	validUntil time.Time
+	lease      *repository.Lease
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewElector(repo repository.LeaseRepository, cfg *config.FailoverConfig) *Elector {
+	hostname, _ := os.Hostname()
+
+	takeoverDelay := time.Duration(0)
+	if cfg.Role == RoleStandby {
+		takeoverDelay = cfg.StandbyTakeoverDelay
+	}
+
+	return &Elector{
+		repo:          repo,
+		holderID:      fmt.Sprintf("%s/%s/%d/%s", cfg.Region, hostname, os.Getpid(), uuid.New().String()[:8]),
+		region:        cfg.Region,
+		role:          cfg.Role,
+		ttl:           cfg.LeaseTTL,
+		renewInterval: cfg.RenewInterval,
+		takeoverDelay: takeoverDelay,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+func (e *Elector) Start(ctx context.Context) {
+	logger.Get().Info("starting dispatch lease elector",
+		zap.String("holder_id", e.holderID),
+		zap.String("region", e.region),
+		zap.String("role", e.role),
+		zap.Duration("lease_ttl", e.ttl),
+	)
+
+	e.tick(ctx)
+
+	e.wg.Add(1)
+	go e.run(ctx)
+}
+
+func (e *Elector) Stop() {
+	close(e.stopChan)
+	e.wg.Wait()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.mu.Unlock()
+
+	if wasLeader {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := e.repo.Release(ctx, DispatchLeaseName, e.holderID); err != nil {
+			logger.Get().Warn("failed to release dispatch lease", zap.Error(err))
+		}
+	}
+}
+
+// IsLeader reports whether this deployment may dispatch right now.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader && time.Now().Before(e.validUntil)
+}
+
+func (e *Elector) HolderID() string {
+	return e.holderID
+}
+
+func (e *Elector) Region() string {
+	return e.region
+}
+
+func (e *Elector) Role() string {
+	return e.role
+}
+
+// Lease returns the last lease state observed by this elector.
+func (e *Elector) Lease() *repository.Lease {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lease
+}
+
+// ForceFailover hands dispatch to the given region. The current holder
+// stops renewing on its next heartbeat and the target takes over once the
+// lease has expired, so there is no window where both regions dispatch.
+func (e *Elector) ForceFailover(ctx context.Context, region string) error {
+	if err := e.repo.SetPreferredRegion(ctx, DispatchLeaseName, region); err != nil {
+		return err
+	}
+
+	logger.Get().Warn("forced dispatch failover requested",
+		zap.String("target_region", region),
+		zap.String("requested_by", e.holderID),
+	)
+
+	e.tick(ctx)
+	return nil
+}
+
+func (e *Elector) run(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	attemptedAt := time.Now()
+
+	tickCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	defer cancel()
+
+	lease, acquired, err := e.repo.TryAcquire(tickCtx, DispatchLeaseName, e.holderID, e.region, e.ttl, e.takeoverDelay)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		// Keep the current validUntil: leadership lapses on its own if the
+		// database stays unreachable
+		logger.Get().Error("dispatch lease heartbeat failed", zap.Error(err))
+		return
+	}
+
+	wasLeader := e.leader
+	e.lease = lease
+	e.leader = acquired
+	if acquired {
+		e.validUntil = attemptedAt.Add(e.ttl - e.renewInterval)
+	}
+
+	switch {
+	case acquired && !wasLeader:
+		logger.Get().Info("acquired dispatch lease",
+			zap.String("region", e.region),
+			zap.Int64("epoch", lease.Epoch),
+		)
+	case !acquired && wasLeader:
+		logger.Get().Warn("lost dispatch lease",
+			zap.String("region", e.region),
+			zap.String("new_holder_region", lease.HolderRegion),
+		)
+	}
+}
@@ -0,0 +1,158 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLeaseRepository struct {
+	mock.Mock
+}
+
+func (m *MockLeaseRepository) TryAcquire(ctx context.Context, name, holderID, region string, ttl, takeoverDelay time.Duration) (*repository.Lease, bool, error) {
+	args := m.Called(ctx, name, holderID, region, ttl, takeoverDelay)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*repository.Lease), args.Bool(1), args.Error(2)
+}
+
+func (m *MockLeaseRepository) Release(ctx context.Context, name, holderID string) error {
+	args := m.Called(ctx, name, holderID)
+	return args.Error(0)
+}
+
+func (m *MockLeaseRepository) Get(ctx context.Context, name string) (*repository.Lease, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Lease), args.Error(1)
+}
+
+func (m *MockLeaseRepository) SetPreferredRegion(ctx context.Context, name, region string) error {
+	args := m.Called(ctx, name, region)
+	return args.Error(0)
+}
+
+func newTestElector(repo *MockLeaseRepository, role string) *Elector {
+	return NewElector(repo, &config.FailoverConfig{
+		Enabled:              true,
+		Region:               "eu-west",
+		Role:                 role,
+		LeaseTTL:             30 * time.Second,
+		RenewInterval:        10 * time.Second,
+		StandbyTakeoverDelay: 15 * time.Second,
+	})
+}
+
+func TestElector_AcquiresLease(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RolePrimary)
+
+	lease := &repository.Lease{Name: DispatchLeaseName, HolderID: elector.HolderID(), HolderRegion: "eu-west", Epoch: 1}
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", 30*time.Second, time.Duration(0)).
+		Return(lease, true, nil)
+
+	elector.tick(context.Background())
+
+	assert.True(t, elector.IsLeader())
+	assert.Equal(t, lease, elector.Lease())
+	repo.AssertExpectations(t)
+}
+
+func TestElector_StandbyUsesTakeoverDelay(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RoleStandby)
+
+	held := &repository.Lease{Name: DispatchLeaseName, HolderID: "us-east/host/1/abc", HolderRegion: "us-east", Epoch: 3}
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", 30*time.Second, 15*time.Second).
+		Return(held, false, nil)
+
+	elector.tick(context.Background())
+
+	assert.False(t, elector.IsLeader())
+	assert.Equal(t, "us-east", elector.Lease().HolderRegion)
+	repo.AssertExpectations(t)
+}
+
+func TestElector_LosesLeaseToAnotherRegion(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RolePrimary)
+
+	own := &repository.Lease{Name: DispatchLeaseName, HolderID: elector.HolderID(), HolderRegion: "eu-west", Epoch: 1}
+	other := &repository.Lease{Name: DispatchLeaseName, HolderID: "us-east/host/1/abc", HolderRegion: "us-east", Epoch: 2}
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(own, true, nil).Once()
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(other, false, nil).Once()
+
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	elector.tick(context.Background())
+	assert.False(t, elector.IsLeader())
+}
+
+func TestElector_LeadershipLapsesWhenRenewalFails(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RolePrimary)
+
+	own := &repository.Lease{Name: DispatchLeaseName, HolderID: elector.HolderID(), HolderRegion: "eu-west", Epoch: 1}
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(own, true, nil).Once()
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(nil, false, errors.New("connection refused")).Once()
+
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	// A failed heartbeat keeps the existing validity window
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	elector.mu.Lock()
+	elector.validUntil = time.Now().Add(-time.Second)
+	elector.mu.Unlock()
+
+	assert.False(t, elector.IsLeader())
+}
+
+func TestElector_StopReleasesHeldLease(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RolePrimary)
+
+	own := &repository.Lease{Name: DispatchLeaseName, HolderID: elector.HolderID(), HolderRegion: "eu-west", Epoch: 1}
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(own, true, nil)
+	repo.On("Release", mock.Anything, DispatchLeaseName, elector.HolderID()).Return(nil)
+
+	elector.Start(context.Background())
+	elector.Stop()
+
+	assert.False(t, elector.IsLeader())
+	repo.AssertCalled(t, "Release", mock.Anything, DispatchLeaseName, elector.HolderID())
+}
+
+func TestElector_ForceFailoverSetsPreferredRegion(t *testing.T) {
+	repo := new(MockLeaseRepository)
+	elector := newTestElector(repo, RolePrimary)
+
+	other := &repository.Lease{Name: DispatchLeaseName, HolderID: elector.HolderID(), HolderRegion: "eu-west", PreferredRegion: "us-east", Epoch: 1}
+	repo.On("SetPreferredRegion", mock.Anything, DispatchLeaseName, "us-east").Return(nil)
+	repo.On("TryAcquire", mock.Anything, DispatchLeaseName, elector.HolderID(), "eu-west", mock.Anything, mock.Anything).
+		Return(other, false, nil)
+
+	err := elector.ForceFailover(context.Background(), "us-east")
+
+	assert.NoError(t, err)
+	assert.False(t, elector.IsLeader())
+	repo.AssertExpectations(t)
+}
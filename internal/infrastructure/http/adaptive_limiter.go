@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"golang.org/x/time/rate"
+)
+
+// rateRecoverySteps is the number of increases it takes to climb from the
+// floor back to the ceiling
+const rateRecoverySteps = 10
+
+// adaptiveLimiter is the outbound rate limit of a provider. It starts at the
+// configured ceiling. Every 429 halves the rate, down to the floor, and holds
+// all sends until the Retry-After the provider gave has passed. After
+// recoverAfter consecutive successes faster than fastResponse the rate
+// climbs back one step towards the ceiling. The burst shrinks along with
+// the rate, so a lowered rate cannot be undone by a burst sized for the
+// ceiling.
+type adaptiveLimiter struct {
+	name         string
+	limiter      *rate.Limiter
+	burst        int
+	minRate      float64
+	recoverAfter int
+	fastResponse time.Duration
+	now          func() time.Time
+
+	mu           sync.Mutex
+	ceiling      float64
+	floor        float64
+	step         float64
+	current      float64
+	streak       int
+	blockedUntil time.Time
+}
+
+// newAdaptiveLimiter never lowers the rate when cfg.RateLimitMin is unset.
+// A cfg.RateLimitBurst of 0 allows one second's worth of requests at once.
+func newAdaptiveLimiter(name string, cfg *config.WebhookConfig) *adaptiveLimiter {
+	ceiling := float64(cfg.RateLimitPerSecond)
+
+	l := &adaptiveLimiter{
+		name:         name,
+		burst:        cfg.RateLimitBurst,
+		minRate:      cfg.RateLimitMin,
+		recoverAfter: cfg.RateRecoverySuccesses,
+		fastResponse: cfg.RateFastResponse,
+		now:          time.Now,
+		current:      ceiling,
+	}
+	l.setCeilingLocked(ceiling)
+	l.limiter = rate.NewLimiter(rate.Limit(ceiling), l.burstFor(ceiling))
+	metrics.ProviderRateLimit.WithLabelValues(name).Set(ceiling)
+	return l
+}
+
+// SetCeiling changes the configured rate while sending. A rate that 429s
+// lowered stays where it is, below the new ceiling, and climbs towards it as
+// usual.
+func (l *adaptiveLimiter) SetCeiling(perSecond int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	atCeiling := l.current >= l.ceiling
+	ceiling := float64(perSecond)
+	l.setCeilingLocked(ceiling)
+	limit := l.current
+	if atCeiling || l.current > ceiling {
+		limit = ceiling
+	}
+	l.setLimitLocked(limit)
+}
+
+func (l *adaptiveLimiter) setCeilingLocked(ceiling float64) {
+	floor := l.minRate
+	if floor <= 0 || floor > ceiling {
+		floor = ceiling
+	}
+	l.ceiling = ceiling
+	l.floor = floor
+	l.step = math.Max((ceiling-floor)/rateRecoverySteps, 0.1)
+}
+
+// Wait blocks until a request may be sent. It fails right away when the
+// provider asked us to hold off past ctx's deadline.
+func (l *adaptiveLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	blockedUntil := l.blockedUntil
+	l.mu.Unlock()
+
+	if delay := blockedUntil.Sub(l.now()); delay > 0 {
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(blockedUntil) {
+			return fmt.Errorf("%s asked to retry after %s, past the request deadline", l.name, delay.Round(time.Millisecond))
+		}
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return l.limiter.Wait(ctx)
+}
+
+// Throttled lowers the rate after a 429 and holds sends for retryAfter.
+func (l *adaptiveLimiter) Throttled(retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.streak = 0
+	if retryAfter > 0 {
+		if until := l.now().Add(retryAfter); until.After(l.blockedUntil) {
+			l.blockedUntil = until
+		}
+	}
+	l.setLimitLocked(math.Max(l.current/2, l.floor))
+}
+
+// Succeeded counts a successful request towards raising the rate again.
+// A slow response restarts the count, since the provider may be struggling.
+func (l *adaptiveLimiter) Succeeded(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current >= l.ceiling {
+		return
+	}
+	if latency > l.fastResponse {
+		l.streak = 0
+		return
+	}
+
+	l.streak++
+	if l.streak < l.recoverAfter {
+		return
+	}
+	l.streak = 0
+	l.setLimitLocked(math.Min(l.current+l.step, l.ceiling))
+}
+
+// Limit is the effective rate, in requests per second.
+func (l *adaptiveLimiter) Limit() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+// Burst is the number of requests that may currently go out at once.
+func (l *adaptiveLimiter) Burst() int {
+	return l.limiter.Burst()
+}
+
+func (l *adaptiveLimiter) setLimitLocked(limit float64) {
+	burst := l.burstFor(limit)
+	if limit == l.current && burst == l.limiter.Burst() {
+		return
+	}
+	l.current = limit
+	l.limiter.SetLimit(rate.Limit(limit))
+	l.limiter.SetBurst(burst)
+	metrics.ProviderRateLimit.WithLabelValues(l.name).Set(limit)
+}
+
+// burstFor scales the configured burst by how far limit is below the
+// ceiling. Without a configured burst it is one second's worth at limit.
+func (l *adaptiveLimiter) burstFor(limit float64) int {
+	if l.burst <= 0 || l.ceiling <= 0 {
+		return int(math.Max(math.Ceil(limit), 1))
+	}
+	return int(math.Max(math.Ceil(float64(l.burst)*limit/l.ceiling), 1))
+}
+
+// retryAfterError carries the delay a provider asked for with a 429.
+type retryAfterError struct {
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	if e.delay <= 0 {
+		return "no Retry-After given"
+	}
+	return "retry after " + e.delay.String()
+}
+
+// throttledError reports a 429 response from provider name.
+func throttledError(name string, header http.Header, now time.Time) error {
+	return apperrors.Wrap(apperrors.ErrorCodeThrottled, name+" throttled the request",
+		&retryAfterError{delay: parseRetryAfter(header.Get("Retry-After"), now)})
+}
+
+// retryAfterOf returns the delay carried by a throttled error, or 0.
+func retryAfterOf(err error) time.Duration {
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) {
+		return retryAfter.delay
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. A missing, malformed or past value yields 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
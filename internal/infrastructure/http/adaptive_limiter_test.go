@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdaptiveLimiter(now *time.Time) *adaptiveLimiter {
+	l := newAdaptiveLimiter("test", &config.WebhookConfig{
+		RateLimitPerSecond:    20,
+		RateLimitMin:          2,
+		RateRecoverySuccesses: 3,
+		RateFastResponse:      time.Second,
+	})
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestAdaptiveLimiter_ThrottledHalvesDownToFloor(t *testing.T) {
+	now := time.Now()
+	limiter := newTestAdaptiveLimiter(&now)
+
+	limiter.Throttled(0)
+	assert.Equal(t, 10.0, limiter.Limit())
+
+	for i := 0; i < 5; i++ {
+		limiter.Throttled(0)
+	}
+	assert.Equal(t, 2.0, limiter.Limit())
+}
+
+func TestAdaptiveLimiter_RecoversAfterFastSuccesses(t *testing.T) {
+	now := time.Now()
+	limiter := newTestAdaptiveLimiter(&now)
+	limiter.Throttled(0)
+
+	limiter.Succeeded(10 * time.Millisecond)
+	limiter.Succeeded(10 * time.Millisecond)
+	limiter.Succeeded(2 * time.Second)
+	limiter.Succeeded(10 * time.Millisecond)
+	limiter.Succeeded(10 * time.Millisecond)
+	assert.Equal(t, 10.0, limiter.Limit())
+
+	limiter.Succeeded(10 * time.Millisecond)
+	assert.InDelta(t, 11.8, limiter.Limit(), 0.001)
+
+	for i := 0; i < 30; i++ {
+		limiter.Succeeded(10 * time.Millisecond)
+	}
+	assert.Equal(t, 20.0, limiter.Limit())
+}
+
+func TestAdaptiveLimiter_SetCeiling(t *testing.T) {
+	now := time.Now()
+	limiter := newTestAdaptiveLimiter(&now)
+
+	limiter.SetCeiling(40)
+	assert.Equal(t, 40.0, limiter.Limit(), "an unthrottled limiter moves to the new ceiling")
+
+	limiter.Throttled(0)
+	limiter.SetCeiling(30)
+	assert.Equal(t, 20.0, limiter.Limit(), "a throttled rate below the new ceiling is kept")
+
+	limiter.SetCeiling(5)
+	assert.Equal(t, 5.0, limiter.Limit(), "a rate above the new ceiling is lowered to it")
+
+	for i := 0; i < 10; i++ {
+		limiter.Throttled(0)
+	}
+	assert.Equal(t, 2.0, limiter.Limit(), "the floor still holds")
+}
+
+func TestAdaptiveLimiter_BurstFollowsRate(t *testing.T) {
+	now := time.Now()
+	limiter := newTestAdaptiveLimiter(&now)
+	assert.Equal(t, 20, limiter.Burst(), "without a configured burst it is one second's worth")
+
+	limiter.Throttled(0)
+	assert.Equal(t, 10, limiter.Burst())
+
+	configured := newAdaptiveLimiter("test", &config.WebhookConfig{
+		RateLimitPerSecond:    20,
+		RateLimitBurst:        50,
+		RateLimitMin:          2,
+		RateRecoverySuccesses: 3,
+		RateFastResponse:      time.Second,
+	})
+	assert.Equal(t, 50, configured.Burst())
+
+	configured.Throttled(0)
+	assert.Equal(t, 25, configured.Burst(), "the burst shrinks with the rate")
+
+	configured.SetCeiling(40)
+	assert.Equal(t, 13, configured.Burst(), "the burst is scaled to the new ceiling")
+}
+
+func TestAdaptiveLimiter_WaitFailsWhenRetryAfterPassesDeadline(t *testing.T) {
+	now := time.Now()
+	limiter := newTestAdaptiveLimiter(&now)
+	limiter.Throttled(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
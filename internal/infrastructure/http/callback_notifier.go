@@ -0,0 +1,244 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"github.com/eneskaya/insider-messaging/pkg/netguard"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/signature"
+	"go.uber.org/zap"
+)
+
+// Results counted by metrics.StatusCallbacks
+const (
+	callbackDelivered = "delivered"
+	callbackFailed    = "failed"
+	callbackDropped   = "dropped"
+)
+
+// callbackDrainTimeout bounds the last attempt at the queued callbacks on
+// shutdown
+const callbackDrainTimeout = 5 * time.Second
+
+// pendingCallback is a callback waiting for its next attempt.
+type pendingCallback struct {
+	callback *provider.StatusCallback
+	body     []byte
+	attempt  int
+}
+
+// CallbackNotifier posts status callbacks from a bounded in-memory queue.
+// Each request is signed like the provider requests, with every signing
+// secret. A callback answered with a 2xx is done; a 4xx other than 408 and
+// 429 is not retried, and anything else is queued again after a backoff
+// that doubles with each attempt. On shutdown the queued callbacks get one
+// last attempt; those waiting for a retry are lost, as are those that
+// arrive while the queue is full.
+//
+// Callback URLs come from API callers, so the client only connects to public
+// addresses or the networks in CALLBACK_ALLOWED_NETWORKS. The address is
+// checked when dialing, which covers a host that resolves differently than
+// when the URL was accepted, and redirects must stay on https.
+type CallbackNotifier struct {
+	client      *http.Client
+	guard       *netguard.Guard
+	secrets     []string
+	maxAttempts int
+	backoff     time.Duration
+	workers     int
+
+	queue    chan *pendingCallback
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewCallbackNotifier(cfg *config.CallbackConfig) (*CallbackNotifier, error) {
+	guard, err := netguard.New(cfg.AllowedNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("callback allowed networks: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout, Control: guard.Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the callback host
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &CallbackNotifier{
+		client: &http.Client{
+			Timeout:       cfg.Timeout,
+			Transport:     transport,
+			CheckRedirect: httpsRedirectsOnly,
+		},
+		guard:       guard,
+		secrets:     cfg.SigningSecrets,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.RetryBackoff,
+		workers:     cfg.Workers,
+		queue:       make(chan *pendingCallback, cfg.QueueSize),
+		stopChan:    make(chan struct{}),
+	}, nil
+}
+
+// httpsRedirectsOnly follows up to 10 redirects, none of them to plain http.
+func httpsRedirectsOnly(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect to %s URL", req.URL.Scheme)
+	}
+	return nil
+}
+
+// CheckURL accepts https URLs whose host resolves to addresses the client
+// would connect to.
+func (n *CallbackNotifier) CheckURL(ctx context.Context, rawURL string) error {
+	return n.guard.CheckURL(ctx, rawURL)
+}
+
+func (n *CallbackNotifier) Start(ctx context.Context) {
+	for i := 0; i < n.workers; i++ {
+		n.wg.Add(1)
+		go n.run(ctx)
+	}
+
+	logger.Get().Info("status callback notifier started",
+		zap.Int("workers", n.workers),
+		zap.Int("max_attempts", n.maxAttempts),
+	)
+}
+
+// Stop waits for the callbacks being posted, then posts those still queued
+// once more within callbackDrainTimeout.
+func (n *CallbackNotifier) Stop() {
+	close(n.stopChan)
+	n.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), callbackDrainTimeout)
+	defer cancel()
+	for ctx.Err() == nil && len(n.queue) > 0 {
+		n.deliver(ctx, <-n.queue)
+	}
+
+	logger.Get().Info("status callback notifier stopped", zap.Int("dropped", len(n.queue)))
+}
+
+// Notify queues callback without waiting for it to be posted.
+func (n *CallbackNotifier) Notify(ctx context.Context, callback *provider.StatusCallback) {
+	// A struct of strings, numbers and times always marshals
+	body, _ := json.Marshal(callback)
+	n.enqueue(ctx, &pendingCallback{callback: callback, body: body})
+}
+
+func (n *CallbackNotifier) enqueue(ctx context.Context, pending *pendingCallback) {
+	select {
+	case n.queue <- pending:
+	default:
+		metrics.StatusCallbacks.WithLabelValues(callbackDropped).Inc()
+		logger.FromContext(ctx).Warn("status callback queue is full, dropping callback",
+			zap.String("message_id", pending.callback.MessageID.String()),
+			zap.String("status", pending.callback.Status),
+		)
+	}
+}
+
+func (n *CallbackNotifier) run(ctx context.Context) {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.stopChan:
+			return
+		case pending := <-n.queue:
+			n.deliver(ctx, pending)
+		}
+	}
+}
+
+func (n *CallbackNotifier) deliver(ctx context.Context, pending *pendingCallback) {
+	pending.attempt++
+	callback := pending.callback
+	log := logger.FromContext(ctx).With(
+		zap.String("message_id", callback.MessageID.String()),
+		zap.String("status", callback.Status),
+		zap.Int("attempt", pending.attempt),
+	)
+
+	retryable, err := n.post(ctx, pending)
+	if err == nil {
+		metrics.StatusCallbacks.WithLabelValues(callbackDelivered).Inc()
+		log.Debug("status callback delivered")
+		return
+	}
+
+	if !retryable || pending.attempt >= n.maxAttempts || n.stopped() {
+		metrics.StatusCallbacks.WithLabelValues(callbackFailed).Inc()
+		log.Warn("giving up on status callback", zap.Error(err))
+		return
+	}
+
+	delay := n.backoff << (pending.attempt - 1)
+	log.Info("status callback failed, retrying", zap.Error(err), zap.Duration("retry_in", delay))
+	time.AfterFunc(delay, func() {
+		if !n.stopped() {
+			n.enqueue(ctx, pending)
+		}
+	})
+}
+
+func (n *CallbackNotifier) stopped() bool {
+	select {
+	case <-n.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// post sends the callback once. A failure is retryable unless the receiver
+// rejected the request itself.
+func (n *CallbackNotifier) post(ctx context.Context, pending *pendingCallback) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pending.callback.URL, bytes.NewReader(pending.body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signature.Header, signature.SignAll(n.secrets, timestamp, pending.body))
+	req.Header.Set(signature.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if pending.callback.TraceID != "" {
+		req.Header.Set(requestid.Header, pending.callback.TraceID)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("callback URL answered %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("callback URL answered %d", resp.StatusCode)
+	}
+}
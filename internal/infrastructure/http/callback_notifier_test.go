@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/netguard"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/signature"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCallbackNotifier allows loopback so it can post to httptest servers.
+func newTestCallbackNotifier(t *testing.T, maxAttempts int) *CallbackNotifier {
+	t.Helper()
+	notifier, err := NewCallbackNotifier(&config.CallbackConfig{
+		SigningSecrets:  []string{"callback-secret"},
+		Timeout:         time.Second,
+		MaxAttempts:     maxAttempts,
+		RetryBackoff:    10 * time.Millisecond,
+		Workers:         1,
+		QueueSize:       10,
+		AllowedNetworks: []string{"127.0.0.0/8"},
+	})
+	require.NoError(t, err)
+	return notifier
+}
+
+func TestCallbackNotifier_PostsSignedCallback(t *testing.T) {
+	// Arrange
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	notifier := newTestCallbackNotifier(t, 3)
+	notifier.Start(context.Background())
+	defer notifier.Stop()
+
+	messageID := uuid.New()
+
+	// Act
+	notifier.Notify(context.Background(), &provider.StatusCallback{
+		URL:       server.URL,
+		TraceID:   "trace-123",
+		MessageID: messageID,
+		Status:    "sent",
+		Attempts:  1,
+	})
+
+	// Assert
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback was not posted")
+	}
+	body := <-bodies
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "trace-123", req.Header.Get(requestid.Header))
+	assert.NoError(t, signature.Verify("callback-secret", req.Header.Get(signature.Header), req.Header.Get(signature.TimestampHeader), body, time.Minute, time.Now()))
+
+	var payload map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(body, &payload)) {
+		assert.Equal(t, messageID.String(), payload["message_id"])
+		assert.Equal(t, "sent", payload["status"])
+		assert.NotContains(t, payload, "url")
+	}
+}
+
+func TestCallbackNotifier_Retries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		wantCalls int32
+	}{
+		{name: "server error is retried", status: http.StatusInternalServerError, wantCalls: 3},
+		{name: "rate limit is retried", status: http.StatusTooManyRequests, wantCalls: 3},
+		{name: "client error is not retried", status: http.StatusBadRequest, wantCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			notifier := newTestCallbackNotifier(t, 3)
+			notifier.Start(context.Background())
+			defer notifier.Stop()
+
+			// Act
+			notifier.Notify(context.Background(), &provider.StatusCallback{URL: server.URL, MessageID: uuid.New(), Status: "failed"})
+
+			// Assert
+			assert.Eventually(t, func() bool { return calls.Load() == tc.wantCalls }, 2*time.Second, 5*time.Millisecond)
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, tc.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestCallbackNotifier_RetryEndsOnSuccess(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	notifier := newTestCallbackNotifier(t, 5)
+	notifier.Start(context.Background())
+	defer notifier.Stop()
+
+	// Act
+	notifier.Notify(context.Background(), &provider.StatusCallback{URL: server.URL, MessageID: uuid.New(), Status: "sent"})
+
+	// Assert
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCallbackNotifier_RefusesInternalAddressWhenDialing(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	// Without an allowed network, as if the URL had resolved to a public
+	// address when it was accepted and to loopback now
+	notifier, err := NewCallbackNotifier(&config.CallbackConfig{
+		SigningSecrets: []string{"callback-secret"},
+		Timeout:        time.Second,
+		MaxAttempts:    1,
+		RetryBackoff:   10 * time.Millisecond,
+		Workers:        1,
+		QueueSize:      10,
+	})
+	require.NoError(t, err)
+
+	// Act
+	retryable, err := notifier.post(context.Background(), &pendingCallback{
+		callback: &provider.StatusCallback{URL: server.URL, MessageID: uuid.New(), Status: "sent"},
+		body:     []byte(`{}`),
+	})
+
+	// Assert
+	assert.True(t, retryable)
+	assert.ErrorIs(t, err, netguard.ErrForbidden)
+	assert.Zero(t, calls.Load())
+}
+
+func TestCallbackNotifier_CheckURL(t *testing.T) {
+	// Arrange
+	notifier := newTestCallbackNotifier(t, 1)
+
+	// Act & Assert
+	assert.ErrorIs(t, notifier.CheckURL(context.Background(), "https://169.254.169.254/latest/meta-data"), netguard.ErrForbidden)
+	assert.ErrorIs(t, notifier.CheckURL(context.Background(), "http://93.184.216.34/hook"), netguard.ErrScheme)
+	assert.NoError(t, notifier.CheckURL(context.Background(), "https://127.0.0.1:8443/hook"))
+}
+
+func TestNewCallbackNotifier_InvalidAllowedNetwork(t *testing.T) {
+	// Act
+	_, err := NewCallbackNotifier(&config.CallbackConfig{AllowedNetworks: []string{"not-a-network"}})
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+type CircuitBreakerStatus struct {
+	State               string
+	ConsecutiveFailures int
+	OpenedAt            *time.Time
+	RetryAt             *time.Time
+}
+
+// CircuitBreaker opens after threshold consecutive provider failures and
+// rejects sends for openDuration. After that it is half-open: a single probe
+// request goes through while the rest are still rejected, and the probe's
+// failure re-opens it while its success closes it. A probe that never
+// reports back frees its slot after another openDuration. A nil breaker
+// never opens.
+type CircuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	open                bool
+	openedAt            time.Time
+	probing             bool
+	probeStartedAt      time.Time
+	now                 func() time.Time
+}
+
+func NewCircuitBreaker(threshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:    threshold,
+		openDuration: openDuration,
+		now:          time.Now,
+	}
+}
+
+// Allow reports whether a request may be sent right now. While half-open
+// it admits the caller as the probe, so the caller must report the outcome
+// with RecordSuccess or RecordFailure.
+func (b *CircuitBreaker) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.acceptingLocked() {
+		return false
+	}
+	if b.stateLocked() == BreakerHalfOpen {
+		b.probing = true
+		b.probeStartedAt = b.now()
+	}
+	return true
+}
+
+// Accepting reports whether Allow would admit a request, without taking
+// the half-open probe. Callers that only decide whether to start sending
+// use it so the probe is left to the request itself.
+func (b *CircuitBreaker) Accepting() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.acceptingLocked()
+}
+
+func (b *CircuitBreaker) RecordSuccess() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.open = false
+	b.probing = false
+}
+
+// RecordFailure reports whether this failure opened the breaker.
+func (b *CircuitBreaker) RecordFailure() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFailures++
+
+	// A failed probe while half-open re-opens immediately
+	if b.stateLocked() == BreakerHalfOpen || (!b.open && b.consecutiveFailures >= b.threshold) {
+		b.open = true
+		b.openedAt = b.now()
+		b.probing = false
+		return true
+	}
+	return false
+}
+
+func (b *CircuitBreaker) Status() CircuitBreakerStatus {
+	if b == nil {
+		return CircuitBreakerStatus{State: BreakerClosed}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := CircuitBreakerStatus{
+		State:               b.stateLocked(),
+		ConsecutiveFailures: b.consecutiveFailures,
+	}
+	if b.open {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.openDuration)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+func (b *CircuitBreaker) acceptingLocked() bool {
+	switch b.stateLocked() {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		return !b.probing || !b.now().Before(b.probeStartedAt.Add(b.openDuration))
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) stateLocked() string {
+	if !b.open {
+		return BreakerClosed
+	}
+	if b.now().Before(b.openedAt.Add(b.openDuration)) {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker := NewCircuitBreaker(3, time.Minute)
+
+	assert.False(t, breaker.RecordFailure())
+	assert.False(t, breaker.RecordFailure())
+	assert.True(t, breaker.Allow())
+
+	assert.True(t, breaker.RecordFailure())
+	assert.False(t, breaker.Allow())
+	assert.Equal(t, BreakerOpen, breaker.Status().State)
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+
+	assert.True(t, breaker.Allow())
+	assert.Equal(t, 1, breaker.Status().ConsecutiveFailures)
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.Status().State)
+
+	// A failed probe re-opens for another full period
+	assert.True(t, breaker.RecordFailure())
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	breaker.RecordSuccess()
+	assert.Equal(t, BreakerClosed, breaker.Status().State)
+}
+
+func TestCircuitBreaker_HalfOpenAdmitsOneProbe(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	now = now.Add(time.Minute)
+
+	assert.True(t, breaker.Accepting())
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+	assert.False(t, breaker.Accepting())
+
+	// A probe that never reports back frees its slot
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+
+	breaker.RecordSuccess()
+	assert.True(t, breaker.Allow())
+	assert.True(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_HalfOpenAdmitsOneConcurrentCaller(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordFailure()
+	now = now.Add(time.Minute)
+
+	var admitted atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if breaker.Allow() {
+				admitted.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), admitted.Load())
+	assert.Equal(t, BreakerHalfOpen, breaker.Status().State)
+}
+
+func TestCircuitBreaker_NilIsAlwaysClosed(t *testing.T) {
+	var breaker *CircuitBreaker
+
+	assert.True(t, breaker.Allow())
+	assert.True(t, breaker.Accepting())
+	assert.False(t, breaker.RecordFailure())
+	assert.Equal(t, BreakerClosed, breaker.Status().State)
+}
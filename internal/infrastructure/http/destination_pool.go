@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DestinationPool keeps the webhook clients of the destinations stored in
+// the database. Every destination gets one dispatcher, so its rate limit and
+// circuit breaker hold across channels, and a client per channel on top of
+// it. A destination is read again once refreshInterval has passed since it
+// was loaded; its clients are rebuilt when it changed in between.
+type DestinationPool struct {
+	repo            repository.WebhookDestinationRepository
+	cfg             *config.WebhookConfig
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	rateLimit int
+	entries   map[uuid.UUID]*destinationEntry
+}
+
+type destinationEntry struct {
+	destination *repository.WebhookDestination
+	loadedAt    time.Time
+	dispatcher  *dispatcher
+	senders     map[valueobject.Channel]provider.SenderProvider
+}
+
+// NewDestinationPool takes timeouts, retries, signing and transport
+// settings from cfg; destinations only bring their URL, auth key and rate
+// limit.
+func NewDestinationPool(repo repository.WebhookDestinationRepository, cfg *config.WebhookConfig, refreshInterval time.Duration) *DestinationPool {
+	return &DestinationPool{
+		repo:            repo,
+		cfg:             cfg,
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+		rateLimit:       cfg.RateLimitPerSecond,
+		entries:         make(map[uuid.UUID]*destinationEntry),
+	}
+}
+
+func (p *DestinationPool) SenderFor(ctx context.Context, destinationID, tenantID uuid.UUID, channel valueobject.Channel) (provider.SenderProvider, error) {
+	entry, err := p.entry(ctx, destinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	destination := entry.destination
+	if !destination.Active || (destination.TenantID != uuid.Nil && destination.TenantID != tenantID) {
+		return nil, destinationUnavailable(destinationID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if sender, ok := entry.senders[channel]; ok {
+		return sender, nil
+	}
+
+	var channelName string
+	if channel != valueobject.ChannelSMS {
+		channelName = channel.String()
+	}
+	sender, err := newWebhookClientWith(entry.dispatcher.name, channelName, destination.URL, headerAuth(destination.AuthKey), p.cfg, entry.dispatcher)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to build webhook destination client", err)
+	}
+	entry.senders[channel] = sender
+	return sender, nil
+}
+
+// entry returns the cached destination, loading it when it is missing or
+// due for a refresh. A destination that cannot be reloaded keeps being
+// served from the cache.
+func (p *DestinationPool) entry(ctx context.Context, destinationID uuid.UUID) (*destinationEntry, error) {
+	now := p.now()
+
+	p.mu.Lock()
+	cached, ok := p.entries[destinationID]
+	fresh := ok && now.Sub(cached.loadedAt) < p.refreshInterval
+	p.mu.Unlock()
+	if fresh {
+		return cached, nil
+	}
+
+	destination, err := p.repo.FindByID(ctx, destinationID)
+	if err != nil {
+		if appErr, isAppErr := err.(*apperrors.AppError); isAppErr && appErr.Code == apperrors.ErrorCodeNotFound {
+			p.mu.Lock()
+			delete(p.entries, destinationID)
+			p.mu.Unlock()
+			return nil, destinationUnavailable(destinationID)
+		}
+		if ok {
+			logger.FromContext(ctx).Warn("failed to refresh webhook destination, using cached one",
+				zap.Error(err),
+				zap.String("destination_id", destinationID.String()),
+			)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if current, ok := p.entries[destinationID]; ok && current.destination.UpdatedAt.Equal(destination.UpdatedAt) {
+		current.loadedAt = now
+		return current, nil
+	}
+
+	cfg := *p.cfg
+	cfg.RateLimitPerSecond = p.rateLimit
+	if destination.RateLimitPerSecond > 0 {
+		// The global burst is sized for the global rate
+		cfg.RateLimitPerSecond = destination.RateLimitPerSecond
+		cfg.RateLimitBurst = 0
+	}
+	var breaker *CircuitBreaker
+	if cfg.BreakerThreshold > 0 {
+		breaker = NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerOpenDuration)
+	}
+
+	entry := &destinationEntry{
+		destination: destination,
+		loadedAt:    now,
+		dispatcher:  newDispatcher("destination-"+destination.Name, &cfg, breaker),
+		senders:     make(map[valueobject.Channel]provider.SenderProvider),
+	}
+	p.entries[destinationID] = entry
+	return entry, nil
+}
+
+// SetRateLimit changes the rate limit of the destinations that have none of
+// their own.
+func (p *DestinationPool) SetRateLimit(perSecond int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rateLimit = perSecond
+	for _, entry := range p.entries {
+		if entry.destination.RateLimitPerSecond == 0 {
+			entry.dispatcher.rateLimiter.SetCeiling(perSecond)
+		}
+	}
+}
+
+func destinationUnavailable(destinationID uuid.UUID) error {
+	return apperrors.New(apperrors.ErrorCodeDestinationUnavailable,
+		fmt.Sprintf("webhook destination %s is not available", destinationID))
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubDestinationRepository serves destinations from a map and counts reads.
+type stubDestinationRepository struct {
+	destinations map[uuid.UUID]repository.WebhookDestination
+	reads        int
+}
+
+func (r *stubDestinationRepository) Create(ctx context.Context, destination *repository.WebhookDestination) error {
+	r.destinations[destination.ID] = *destination
+	return nil
+}
+
+func (r *stubDestinationRepository) Update(ctx context.Context, destination *repository.WebhookDestination) error {
+	r.destinations[destination.ID] = *destination
+	return nil
+}
+
+func (r *stubDestinationRepository) FindByID(ctx context.Context, id uuid.UUID) (*repository.WebhookDestination, error) {
+	r.reads++
+	destination, ok := r.destinations[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("webhook destination not found")
+	}
+	return &destination, nil
+}
+
+func (r *stubDestinationRepository) List(ctx context.Context, limit, offset int) ([]*repository.WebhookDestination, int64, error) {
+	return nil, 0, nil
+}
+
+func newDestinationServer(t *testing.T, authKeys *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*authKeys = append(*authKeys, r.Header.Get("x-ins-auth-key"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDestinationPool_SendsWithDestinationSettings(t *testing.T) {
+	// Arrange
+	var authKeys []string
+	server := newDestinationServer(t, &authKeys)
+
+	destination := repository.WebhookDestination{
+		ID:                 uuid.New(),
+		Name:               "acme",
+		URL:                server.URL,
+		AuthKey:            "acme-key",
+		RateLimitPerSecond: 3,
+		Active:             true,
+	}
+	repo := &stubDestinationRepository{destinations: map[uuid.UUID]repository.WebhookDestination{destination.ID: destination}}
+	cfg := &config.WebhookConfig{URL: "http://global.invalid", AuthKey: "global-key", TimeoutSeconds: 10, RateLimitPerSecond: 10}
+	pool := NewDestinationPool(repo, cfg, time.Minute)
+
+	// Act
+	sender, err := pool.SenderFor(context.Background(), destination.ID, uuid.Nil, valueobject.ChannelSMS)
+	assert.NoError(t, err)
+	_, sendErr := sender.SendMessage(context.Background(), "+905551234567", "hello", nil)
+	again, againErr := pool.SenderFor(context.Background(), destination.ID, uuid.Nil, valueobject.ChannelSMS)
+
+	// Assert
+	assert.NoError(t, sendErr)
+	assert.Equal(t, []string{"acme-key"}, authKeys)
+	assert.Equal(t, "destination-acme", sender.Name())
+	assert.NoError(t, againErr)
+	assert.Same(t, sender, again)
+	assert.Equal(t, 1, repo.reads, "the destination is cached until the refresh interval passes")
+	assert.Equal(t, 3.0, sender.(*webhookClient).dispatcher.rateLimiter.ceiling)
+}
+
+func TestDestinationPool_Unavailable(t *testing.T) {
+	owner := uuid.New()
+	inactive := repository.WebhookDestination{ID: uuid.New(), Name: "inactive", URL: "http://inactive.invalid", Active: false}
+	owned := repository.WebhookDestination{ID: uuid.New(), Name: "owned", URL: "http://owned.invalid", TenantID: owner, Active: true}
+	repo := &stubDestinationRepository{destinations: map[uuid.UUID]repository.WebhookDestination{
+		inactive.ID: inactive,
+		owned.ID:    owned,
+	}}
+	cfg := &config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10}
+	pool := NewDestinationPool(repo, cfg, time.Minute)
+
+	tests := []struct {
+		name          string
+		destinationID uuid.UUID
+		tenantID      uuid.UUID
+	}{
+		{"unknown", uuid.New(), uuid.Nil},
+		{"inactive", inactive.ID, uuid.Nil},
+		{"other tenant", owned.ID, uuid.New()},
+		{"global caller", owned.ID, uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := pool.SenderFor(context.Background(), tt.destinationID, tt.tenantID, valueobject.ChannelSMS)
+
+			// Assert
+			appErr, ok := err.(*apperrors.AppError)
+			if assert.True(t, ok) {
+				assert.Equal(t, apperrors.ErrorCodeDestinationUnavailable, appErr.Code)
+			}
+		})
+	}
+
+	_, err := pool.SenderFor(context.Background(), owned.ID, owner, valueobject.ChannelSMS)
+	assert.NoError(t, err, "the owning tenant may use its destination")
+}
+
+func TestDestinationPool_RefreshPicksUpChanges(t *testing.T) {
+	// Arrange
+	var authKeys []string
+	server := newDestinationServer(t, &authKeys)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	destination := repository.WebhookDestination{
+		ID:        uuid.New(),
+		Name:      "acme",
+		URL:       server.URL,
+		AuthKey:   "old-key",
+		Active:    true,
+		UpdatedAt: created,
+	}
+	repo := &stubDestinationRepository{destinations: map[uuid.UUID]repository.WebhookDestination{destination.ID: destination}}
+	cfg := &config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10}
+	pool := NewDestinationPool(repo, cfg, time.Minute)
+	now := created
+	pool.now = func() time.Time { return now }
+
+	first, err := pool.SenderFor(context.Background(), destination.ID, uuid.Nil, valueobject.ChannelSMS)
+	assert.NoError(t, err)
+
+	// Act
+	now = now.Add(2 * time.Minute)
+	unchanged, unchangedErr := pool.SenderFor(context.Background(), destination.ID, uuid.Nil, valueobject.ChannelSMS)
+
+	destination.AuthKey = "new-key"
+	destination.UpdatedAt = created.Add(time.Hour)
+	repo.destinations[destination.ID] = destination
+	now = now.Add(2 * time.Minute)
+	rotated, rotatedErr := pool.SenderFor(context.Background(), destination.ID, uuid.Nil, valueobject.ChannelSMS)
+	_, sendErr := rotated.SendMessage(context.Background(), "+905551234567", "hello", nil)
+
+	// Assert
+	assert.NoError(t, unchangedErr)
+	assert.Same(t, first, unchanged, "an unchanged destination keeps its client")
+	assert.NoError(t, rotatedErr)
+	assert.NotSame(t, first, rotated)
+	assert.NoError(t, sendErr)
+	assert.Equal(t, []string{"new-key"}, authKeys)
+	assert.Equal(t, 3, repo.reads)
+}
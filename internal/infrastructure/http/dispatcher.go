@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// sendFunc performs a single delivery attempt against a provider.
+type sendFunc func(ctx context.Context, requestID string) (*provider.SendResult, error)
+
+// dispatcher applies the policy every sender provider shares: the adaptive
+// outbound rate limit, retries of transient failures with exponential backoff
+// and the circuit breaker. Providers only implement a single attempt. Every
+// request, retries included, is counted against the daily quota when one is
+// set.
+type dispatcher struct {
+	name         string
+	rateLimiter  *adaptiveLimiter
+	maxRetries   int
+	retryBackoff time.Duration
+	breaker      *CircuitBreaker
+	quota        DailyQuota
+}
+
+func newDispatcher(name string, cfg *config.WebhookConfig, breaker *CircuitBreaker) *dispatcher {
+	return &dispatcher{
+		name:         name,
+		rateLimiter:  newAdaptiveLimiter(name, cfg),
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		breaker:      breaker,
+	}
+}
+
+func (d *dispatcher) dispatch(ctx context.Context, requestID string, send sendFunc) (*provider.SendResult, error) {
+	var lastErr error
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := d.retryBackoff << (attempt - 1)
+			logger.FromContext(ctx).Warn("retrying provider request",
+				zap.String("provider", d.name),
+				zap.Int("retry", attempt),
+				zap.Duration("delay", delay),
+				zap.Error(lastErr),
+			)
+
+			select {
+			case <-ctx.Done():
+				return nil, lastErr
+			case <-time.After(delay):
+			}
+		}
+
+		if !d.breaker.Allow() {
+			return nil, apperrors.New(apperrors.ErrorCodeCircuitOpen,
+				fmt.Sprintf("%s circuit breaker is open", d.name))
+		}
+
+		if err := d.rateLimiter.Wait(ctx); err != nil {
+			logger.FromContext(ctx).Warn("rate limiter context cancelled", zap.Error(err))
+			// Keep the 429 as the outcome when its Retry-After cannot be waited out
+			if isThrottled(lastErr) {
+				return nil, lastErr
+			}
+			return nil, apperrors.Wrap(apperrors.ErrorCodeRateLimit, "rate limit wait cancelled", err)
+		}
+
+		if err := d.takeQuota(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := send(ctx, requestID)
+		latency := time.Since(start)
+		metrics.WebhookDuration.WithLabelValues(metrics.Outcome(err)).Observe(latency.Seconds())
+
+		if err == nil {
+			d.breaker.RecordSuccess()
+			d.rateLimiter.Succeeded(latency)
+			return resp, nil
+		}
+		lastErr = err
+
+		if isThrottled(err) {
+			// The provider answered, so it is reachable; it only wants us to
+			// slow down
+			d.breaker.RecordSuccess()
+			d.throttled(ctx, err)
+			continue
+		}
+
+		if !isRetryable(err) {
+			// The provider answered, so it is reachable even if it rejected us
+			if answered(err) {
+				d.breaker.RecordSuccess()
+			}
+			return nil, err
+		}
+
+		if d.breaker.RecordFailure() {
+			status := d.breaker.Status()
+			logger.FromContext(ctx).Error("provider circuit breaker opened",
+				zap.String("provider", d.name),
+				zap.Int("consecutive_failures", status.ConsecutiveFailures),
+				zap.Timep("retry_at", status.RetryAt),
+			)
+		}
+	}
+
+	return nil, lastErr
+}
+
+// takeQuota counts a request against the daily quota. A quota that cannot
+// be read refuses the request, since sending it uncounted could exceed it.
+func (d *dispatcher) takeQuota(ctx context.Context) error {
+	if d.quota == nil {
+		return nil
+	}
+
+	allowed, resetAt, err := d.quota.Take(ctx)
+	if err != nil {
+		return apperrors.Wrap(apperrors.ErrorCodeRateLimit, "daily quota could not be checked", err)
+	}
+	if !allowed {
+		logger.FromContext(ctx).Warn("provider daily quota is used up",
+			zap.String("provider", d.name),
+			zap.Time("reset_at", resetAt),
+		)
+		return apperrors.Wrap(apperrors.ErrorCodeQuotaExhausted, fmt.Sprintf("%s daily quota is used up", d.name),
+			&provider.QuotaExhaustedError{ResetAt: resetAt})
+	}
+	return nil
+}
+
+func (d *dispatcher) throttled(ctx context.Context, err error) {
+	retryAfter := retryAfterOf(err)
+	d.rateLimiter.Throttled(retryAfter)
+	metrics.ProviderThrottled.WithLabelValues(d.name).Inc()
+
+	logger.FromContext(ctx).Warn("provider throttled the request, lowering the send rate",
+		zap.String("provider", d.name),
+		zap.Duration("retry_after", retryAfter),
+		zap.Float64("rate_limit", d.rateLimiter.Limit()),
+	)
+}
+
+// isThrottled reports whether the provider answered 429.
+func isThrottled(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	return ok && appErr.Code == apperrors.ErrorCodeThrottled
+}
+
+// isRetryable reports whether another attempt could succeed: the provider
+// timed out, was unreachable or failed on its side. A 429 is retried
+// separately, after the limiter has slowed down.
+func isRetryable(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	if !ok {
+		return false
+	}
+	switch appErr.Code {
+	case apperrors.ErrorCodeTimeout, apperrors.ErrorCodeNetworkError, apperrors.ErrorCodeServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+// answered reports whether err is a response of the provider refusing the
+// request, as opposed to a failure to reach it.
+func answered(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	if !ok {
+		return false
+	}
+	switch appErr.Code {
+	case apperrors.ErrorCodeInvalidResponse, apperrors.ErrorCodeProviderRejected,
+		apperrors.ErrorCodeInvalidRecipient, apperrors.ErrorCodeRecipientOptedOut:
+		return true
+	default:
+		return false
+	}
+}
+
+// transportError classifies a request that never produced a response.
+func transportError(ctx context.Context, name string, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return apperrors.Wrap(apperrors.ErrorCodeTimeout, name+" request timeout", err)
+	}
+	return apperrors.Wrap(apperrors.ErrorCodeNetworkError, "network error during "+name+" request", err)
+}
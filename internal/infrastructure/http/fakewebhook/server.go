@@ -0,0 +1,261 @@
+// Package fakewebhook is a stand-in for the webhook provider that answers
+// with configurable latency and failure rates, for load and failure testing
+// without a real provider.
+package fakewebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+// Config sets how the server answers. The rates are fractions of the
+// requests between 0 and 1 and are drawn in the order hang, throttle, server
+// error, reject; what is left is accepted.
+type Config struct {
+	// Latency is added to every response, plus a random share of Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+	// HangRate of the requests get no answer until HangDuration has passed
+	// or the client gives up, to trigger client timeouts
+	HangRate     float64
+	HangDuration time.Duration
+	// ThrottleRate of the requests are answered 429 with RetryAfter
+	ThrottleRate float64
+	RetryAfter   time.Duration
+	// ErrorRate of the requests are answered 500, which the client retries
+	ErrorRate float64
+	// RejectRate of the requests are answered 400, which fails the message
+	// without a retry
+	RejectRate float64
+	// MaxPerSecond answers 429 to the requests above the rate, like a
+	// provider enforcing a quota; 0 allows any rate
+	MaxPerSecond int
+	// AuthKey, when set, must match the x-ins-auth-key header
+	AuthKey string
+	// Seed makes the drawn outcomes repeatable; 0 seeds from the clock
+	Seed int64
+}
+
+// Validate reports the first setting that is out of range.
+func (c *Config) Validate() error {
+	rates := []struct {
+		name  string
+		value float64
+	}{
+		{"hang rate", c.HangRate},
+		{"throttle rate", c.ThrottleRate},
+		{"error rate", c.ErrorRate},
+		{"reject rate", c.RejectRate},
+	}
+	total := 0.0
+	for _, r := range rates {
+		if r.value < 0 || r.value > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", r.name)
+		}
+		total += r.value
+	}
+	if total > 1 {
+		return fmt.Errorf("hang, throttle, error and reject rates must add up to at most 1")
+	}
+	if c.Latency < 0 || c.Jitter < 0 || c.HangDuration < 0 || c.RetryAfter < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if c.MaxPerSecond < 0 {
+		return fmt.Errorf("max per second must not be negative")
+	}
+	return nil
+}
+
+// Stats counts the requests by how they were answered.
+type Stats struct {
+	Requests     int64 `json:"requests"`
+	Accepted     int64 `json:"accepted"`
+	Hung         int64 `json:"hung"`
+	Throttled    int64 `json:"throttled"`
+	ServerErrors int64 `json:"server_errors"`
+	Rejected     int64 `json:"rejected"`
+	Unauthorized int64 `json:"unauthorized"`
+	BadRequests  int64 `json:"bad_requests"`
+}
+
+type outcome int
+
+const (
+	outcomeAccept outcome = iota
+	outcomeHang
+	outcomeThrottle
+	outcomeServerError
+	outcomeReject
+)
+
+// Server answers POST requests in the webhook's format. GET /stats returns
+// the Stats so far; any other GET answers 200 for health checks.
+type Server struct {
+	cfg     Config
+	limiter *rate.Limiter
+
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	requests, accepted, hung, throttled  atomic.Int64
+	serverErrors, rejected, unauthorized atomic.Int64
+	badRequests                          atomic.Int64
+}
+
+func NewServer(cfg Config) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &Server{
+		cfg:  cfg,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+	if cfg.MaxPerSecond > 0 {
+		s.limiter = rate.NewLimiter(rate.Limit(cfg.MaxPerSecond), cfg.MaxPerSecond)
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.handleSend(w, r)
+	case http.MethodGet, http.MethodHead:
+		if r.URL.Path == "/stats" {
+			writeJSON(w, http.StatusOK, s.Stats())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Stats returns the counts so far.
+func (s *Server) Stats() Stats {
+	return Stats{
+		Requests:     s.requests.Load(),
+		Accepted:     s.accepted.Load(),
+		Hung:         s.hung.Load(),
+		Throttled:    s.throttled.Load(),
+		ServerErrors: s.serverErrors.Load(),
+		Rejected:     s.rejected.Load(),
+		Unauthorized: s.unauthorized.Load(),
+		BadRequests:  s.badRequests.Load(),
+	}
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	if id := r.Header.Get(requestid.Header); id != "" {
+		w.Header().Set("X-Request-ID", id)
+	}
+
+	if s.cfg.AuthKey != "" && r.Header.Get("x-ins-auth-key") != s.cfg.AuthKey {
+		s.unauthorized.Add(1)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "invalid auth key"})
+		return
+	}
+
+	var req infrahttp.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.To == "" || req.Content == "" {
+		s.badRequests.Add(1)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "to and content are required"})
+		return
+	}
+
+	if s.limiter != nil && !s.limiter.Allow() {
+		s.throttle(w)
+		return
+	}
+
+	result, delay := s.draw()
+	if result == outcomeHang {
+		s.hung.Add(1)
+		delay = s.cfg.HangDuration
+	}
+
+	select {
+	case <-time.After(delay):
+	case <-r.Context().Done():
+		return
+	}
+
+	switch result {
+	case outcomeHang:
+		w.WriteHeader(http.StatusGatewayTimeout)
+	case outcomeThrottle:
+		s.throttle(w)
+	case outcomeServerError:
+		s.serverErrors.Add(1)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "simulated server error"})
+	case outcomeReject:
+		s.rejected.Add(1)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "simulated rejection"})
+	default:
+		s.accepted.Add(1)
+		writeJSON(w, http.StatusAccepted, infrahttp.WebhookResponse{
+			Message:   "Accepted",
+			MessageID: uuid.NewString(),
+		})
+	}
+}
+
+// draw picks the outcome of a request and the latency it is answered with.
+func (s *Server) draw() (outcome, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delay := s.cfg.Latency
+	if s.cfg.Jitter > 0 {
+		delay += time.Duration(s.rand.Int63n(int64(s.cfg.Jitter) + 1))
+	}
+
+	roll := s.rand.Float64()
+	for _, candidate := range []struct {
+		outcome outcome
+		rate    float64
+	}{
+		{outcomeHang, s.cfg.HangRate},
+		{outcomeThrottle, s.cfg.ThrottleRate},
+		{outcomeServerError, s.cfg.ErrorRate},
+		{outcomeReject, s.cfg.RejectRate},
+	} {
+		if roll < candidate.rate {
+			return candidate.outcome, delay
+		}
+		roll -= candidate.rate
+	}
+	return outcomeAccept, delay
+}
+
+func (s *Server) throttle(w http.ResponseWriter) {
+	s.throttled.Add(1)
+	if s.cfg.RetryAfter > 0 {
+		seconds := int((s.cfg.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	writeJSON(w, http.StatusTooManyRequests, map[string]string{"message": "simulated rate limit"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
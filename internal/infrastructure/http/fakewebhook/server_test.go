@@ -0,0 +1,173 @@
+package fakewebhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+	server, err := NewServer(cfg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return server
+}
+
+func post(server *Server, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	return recorder
+}
+
+const validBody = `{"to":"+905551234567","content":"hello"}`
+
+func TestServer_AcceptsWithWebhookClient(t *testing.T) {
+	// Arrange
+	fake := newTestServer(t, Config{AuthKey: "secret"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := infrahttp.NewWebhookClient(&config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "secret",
+		TimeoutSeconds:     5,
+		RateLimitPerSecond: 10,
+	}, nil)
+	assert.NoError(t, err)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "hello", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Accepted", result.Message)
+	assert.NotEmpty(t, result.MessageID)
+	assert.Equal(t, int64(1), fake.Stats().Accepted)
+}
+
+func TestServer_ErrorRateIsSeenAsServerError(t *testing.T) {
+	// Arrange
+	fake := newTestServer(t, Config{ErrorRate: 1})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := infrahttp.NewWebhookClient(&config.WebhookConfig{
+		URL:                server.URL,
+		TimeoutSeconds:     5,
+		RateLimitPerSecond: 10,
+	}, nil)
+	assert.NoError(t, err)
+
+	// Act
+	_, err = client.SendMessage(context.Background(), "+905551234567", "hello", nil)
+
+	// Assert
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeServerError, appErr.Code)
+	}
+	assert.Equal(t, int64(1), fake.Stats().ServerErrors)
+}
+
+func TestServer_Outcomes(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantStatus int
+	}{
+		{name: "throttle", cfg: Config{ThrottleRate: 1, RetryAfter: 1500 * time.Millisecond}, wantStatus: http.StatusTooManyRequests},
+		{name: "reject", cfg: Config{RejectRate: 1}, wantStatus: http.StatusBadRequest},
+		{name: "error", cfg: Config{ErrorRate: 1}, wantStatus: http.StatusInternalServerError},
+		{name: "hang", cfg: Config{HangRate: 1, HangDuration: time.Millisecond}, wantStatus: http.StatusGatewayTimeout},
+		{name: "accept", cfg: Config{}, wantStatus: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server := newTestServer(t, tt.cfg)
+
+			// Act
+			recorder := post(server, validBody, nil)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			if tt.wantStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestServer_MaxPerSecondThrottlesExcess(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, Config{MaxPerSecond: 2})
+
+	// Act
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		statuses = append(statuses, post(server, validBody, nil).Code)
+	}
+
+	// Assert
+	assert.Equal(t, []int{http.StatusAccepted, http.StatusAccepted, http.StatusTooManyRequests}, statuses)
+	assert.Equal(t, int64(1), server.Stats().Throttled)
+}
+
+func TestServer_RejectsWrongAuthKeyAndBadBody(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, Config{AuthKey: "secret"})
+
+	// Act
+	unauthorized := post(server, validBody, map[string]string{"x-ins-auth-key": "wrong"})
+	badRequest := post(server, `{"to":""}`, map[string]string{"x-ins-auth-key": "secret"})
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, unauthorized.Code)
+	assert.Equal(t, http.StatusBadRequest, badRequest.Code)
+	stats := server.Stats()
+	assert.Equal(t, int64(1), stats.Unauthorized)
+	assert.Equal(t, int64(1), stats.BadRequests)
+	assert.Equal(t, int64(2), stats.Requests)
+}
+
+func TestServer_StatsEndpoint(t *testing.T) {
+	// Arrange
+	server := newTestServer(t, Config{})
+	post(server, validBody, nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stats", nil))
+
+	// Assert
+	var stats Stats
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&stats))
+	assert.Equal(t, int64(1), stats.Accepted)
+}
+
+func TestNewServer_RejectsInvalidRates(t *testing.T) {
+	// Act
+	_, overOne := NewServer(Config{ErrorRate: 1.5})
+	_, sumOverOne := NewServer(Config{ErrorRate: 0.6, ThrottleRate: 0.6})
+
+	// Assert
+	assert.Error(t, overOne)
+	assert.Error(t, sumOverOne)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+)
+
+// probeCacheTTL is how long a probe result is reused, so frequent health
+// checks do not turn into a request to the provider each
+const probeCacheTTL = 10 * time.Second
+
+// ProviderProbe checks that the webhook provider is reachable with a HEAD
+// request. Any response below 500 counts as reachable: an endpoint that only
+// accepts POST still answers 405.
+type ProviderProbe struct {
+	client *http.Client
+	url    string
+	auth   webhookAuth
+	now    func() time.Time
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	lastErr   error
+}
+
+// NewProviderProbe probes cfg.HealthCheckURL, or cfg.URL when it is empty,
+// over the same proxy, TLS and auth settings as the webhook client.
+func NewProviderProbe(cfg *config.WebhookConfig) (*ProviderProbe, error) {
+	url := cfg.HealthCheckURL
+	if url == "" {
+		url = cfg.URL
+	}
+	client, err := newHTTPClient(cfg, cfg.HealthCheckTimeout)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := newWebhookAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderProbe{
+		client: client,
+		url:    url,
+		auth:   auth,
+		now:    time.Now,
+	}, nil
+}
+
+// Check returns nil when the provider answered the last probe, which is at
+// most probeCacheTTL old.
+func (p *ProviderProbe) Check(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.checkedAt.IsZero() && p.now().Sub(p.checkedAt) < probeCacheTTL {
+		return p.lastErr
+	}
+
+	p.lastErr = p.probe(ctx)
+	p.checkedAt = p.now()
+	return p.lastErr
+}
+
+func (p *ProviderProbe) probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.url, nil)
+	if err != nil {
+		return err
+	}
+	if err := p.auth.authorize(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("provider answered %d", resp.StatusCode)
+	}
+	return nil
+}
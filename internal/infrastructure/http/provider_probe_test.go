@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProviderProbe(t *testing.T, cfg *config.WebhookConfig) *ProviderProbe {
+	t.Helper()
+	probe, err := NewProviderProbe(cfg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return probe
+}
+
+func TestProviderProbe_Check(t *testing.T) {
+	// Arrange
+	statusCode := http.StatusMethodNotAllowed
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.Equal(t, "test-auth-key", r.Header.Get("x-ins-auth-key"))
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	probe := newTestProviderProbe(t, &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		HealthCheckTimeout: time.Second,
+	})
+	now := time.Now()
+	probe.now = func() time.Time { return now }
+
+	// Act & Assert - a method the endpoint does not accept still proves it is reachable
+	assert.NoError(t, probe.Check(context.Background()))
+
+	// Act & Assert - the result is reused until it expires
+	statusCode = http.StatusBadGateway
+	assert.NoError(t, probe.Check(context.Background()))
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(probeCacheTTL)
+	assert.Error(t, probe.Check(context.Background()))
+	assert.Equal(t, 2, requests)
+}
+
+func TestProviderProbe_Unreachable(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	probe := newTestProviderProbe(t, &config.WebhookConfig{
+		URL:                "http://unused.invalid",
+		HealthCheckURL:     server.URL,
+		HealthCheckTimeout: time.Second,
+	})
+
+	// Act
+	err := probe.Check(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+)
+
+// RateLimited is a provider whose outbound rate limit can be changed while
+// it is sending.
+type RateLimited interface {
+	SetRateLimit(perSecond int)
+}
+
+// DailyQuota caps the requests sent to a provider per day across every
+// instance. Take counts one request, or reports false and when the quota
+// resets once it is used up.
+type DailyQuota interface {
+	Take(ctx context.Context) (bool, time.Time, error)
+}
+
+// QuotaLimited is a provider whose sends can be counted against a daily
+// quota.
+type QuotaLimited interface {
+	LimitDaily(quota DailyQuota)
+}
+
+// NewSenderProvider builds the provider selected by senderCfg.Provider. All
+// providers share the retry, rate limit and breaker settings in webhookCfg.
+func NewSenderProvider(ctx context.Context, senderCfg *config.SenderConfig, webhookCfg *config.WebhookConfig, breaker *CircuitBreaker) (provider.SenderProvider, error) {
+	switch senderCfg.Provider {
+	case "", "webhook":
+		return NewWebhookClient(webhookCfg, breaker)
+	case "twilio":
+		return NewTwilioClient(&senderCfg.Twilio, webhookCfg, breaker)
+	case "sns":
+		return NewSNSClient(ctx, &senderCfg.SNS, webhookCfg, breaker)
+	case "fake":
+		return newWebhookClient("fake-webhook", "", senderCfg.FakeWebhookURL, webhookCfg, breaker)
+	default:
+		return nil, fmt.Errorf("unknown sender provider %q", senderCfg.Provider)
+	}
+}
+
+// NewChannelProviders builds the webhook clients of the email and push
+// channels enabled in senderCfg. Each gets its own circuit breaker, so an
+// outage of one channel does not hold back the others.
+func NewChannelProviders(senderCfg *config.SenderConfig, webhookCfg *config.WebhookConfig) (provider.ChannelProviders, error) {
+	urls := map[valueobject.Channel]string{
+		valueobject.ChannelEmail: senderCfg.EmailWebhookURL,
+		valueobject.ChannelPush:  senderCfg.PushWebhookURL,
+	}
+
+	providers := provider.ChannelProviders{}
+	for channel, url := range urls {
+		if url == "" {
+			continue
+		}
+		var breaker *CircuitBreaker
+		if webhookCfg.BreakerThreshold > 0 {
+			breaker = NewCircuitBreaker(webhookCfg.BreakerThreshold, webhookCfg.BreakerOpenDuration)
+		}
+		sender, err := NewChannelWebhookClient(channel, url, webhookCfg, breaker)
+		if err != nil {
+			return nil, fmt.Errorf("%s channel: %w", channel, err)
+		}
+		providers[channel] = sender
+	}
+	return providers, nil
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/smithy-go"
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+// snsPublisher is the part of the SNS API the client uses.
+type snsPublisher interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+type snsClient struct {
+	api        snsPublisher
+	timeout    time.Duration
+	senderID   string
+	smsType    string
+	dispatcher *dispatcher
+}
+
+// NewSNSClient publishes messages as direct SMS through AWS SNS. Credentials
+// come from the default AWS chain; the SDK's own retries are disabled so the
+// shared dispatcher policy from webhookCfg is the only one in effect.
+func NewSNSClient(ctx context.Context, cfg *config.SNSConfig, webhookCfg *config.WebhookConfig, breaker *CircuitBreaker) (provider.SenderProvider, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRetryMaxAttempts(1),
+	}
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+	// The SDK's own client is kept unless a proxy or TLS file is configured
+	if webhookCfg.CustomTransport() {
+		client, err := newHTTPClient(webhookCfg, 0)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, awsconfig.WithHTTPClient(client))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return newSNSClient(sns.NewFromConfig(awsCfg), cfg, webhookCfg, breaker), nil
+}
+
+func newSNSClient(api snsPublisher, cfg *config.SNSConfig, webhookCfg *config.WebhookConfig, breaker *CircuitBreaker) *snsClient {
+	return &snsClient{
+		api:        api,
+		timeout:    time.Duration(webhookCfg.TimeoutSeconds) * time.Second,
+		senderID:   cfg.SenderID,
+		smsType:    cfg.SMSType,
+		dispatcher: newDispatcher("sns", webhookCfg, breaker),
+	}
+}
+
+func (s *snsClient) Name() string {
+	return "sns"
+}
+
+func (s *snsClient) SetRateLimit(perSecond int) {
+	s.dispatcher.rateLimiter.SetCeiling(perSecond)
+}
+
+func (s *snsClient) LimitDaily(quota DailyQuota) {
+	s.dispatcher.quota = quota
+}
+
+func (s *snsClient) SendMessage(ctx context.Context, phoneNumber, content string, _ map[string]string) (*provider.SendResult, error) {
+	ctx, requestID := requestid.Ensure(ctx)
+
+	return s.dispatcher.dispatch(ctx, requestID, func(ctx context.Context, requestID string) (*provider.SendResult, error) {
+		return s.send(ctx, requestID, phoneNumber, content)
+	})
+}
+
+func (s *snsClient) send(ctx context.Context, requestID, phoneNumber, content string) (*provider.SendResult, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	attributes := map[string]snstypes.MessageAttributeValue{
+		"AWS.SNS.SMS.SMSType": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(s.smsType),
+		},
+	}
+	if s.senderID != "" {
+		attributes["AWS.SNS.SMS.SenderID"] = snstypes.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(s.senderID),
+		}
+	}
+
+	startTime := time.Now()
+	out, err := s.api.Publish(ctx, &sns.PublishInput{
+		PhoneNumber:       aws.String(phoneNumber),
+		Message:           aws.String(content),
+		MessageAttributes: attributes,
+	})
+	duration := time.Since(startTime)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("sns publish failed",
+			zap.Error(err),
+			zap.String("phone_number", phoneNumber),
+			zap.Duration("duration", duration),
+		)
+		return nil, mapSNSError(ctx, err)
+	}
+
+	providerRequestID, _ := awsmiddleware.GetRequestIDMetadata(out.ResultMetadata)
+
+	logger.FromContext(ctx).Info("sns publish completed",
+		zap.String("provider_request_id", providerRequestID),
+		zap.String("phone_number", phoneNumber),
+		zap.Duration("duration", duration),
+	)
+
+	messageID := aws.ToString(out.MessageId)
+	if messageID == "" {
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse, "sns response missing MessageId")
+	}
+
+	return &provider.SendResult{
+		MessageID:         messageID,
+		Message:           "Accepted",
+		RequestID:         requestID,
+		ProviderRequestID: providerRequestID,
+	}, nil
+}
+
+// mapSNSError keeps AWS server faults retryable and treats every other API
+// error as a rejection of the message, permanent when its code is mapped.
+func mapSNSError(ctx context.Context, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) {
+			provider.RecordResponseStatus(ctx, respErr.HTTPStatusCode())
+		}
+		provider.RecordRefusal(ctx, apiErr.ErrorCode(), []byte(apiErr.ErrorMessage()))
+
+		if apiErr.ErrorFault() == smithy.FaultServer {
+			return apperrors.Wrap(apperrors.ErrorCodeServerError,
+				fmt.Sprintf("sns server error: %s", apiErr.ErrorCode()), err)
+		}
+		code := apperrors.ErrorCodeInvalidResponse
+		if mapped, ok := apperrors.ProviderErrorCode("sns", apiErr.ErrorCode()); ok {
+			code = mapped
+		}
+		return apperrors.Wrap(code,
+			fmt.Sprintf("sns rejected message: %s: %s", apiErr.ErrorCode(), apiErr.ErrorMessage()), err)
+	}
+	return transportError(ctx, "sns", err)
+}
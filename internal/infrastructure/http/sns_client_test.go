@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/smithy-go"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSNSPublisher struct {
+	inputs []*sns.PublishInput
+	errs   []error
+}
+
+func (f *fakeSNSPublisher) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.inputs = append(f.inputs, params)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &sns.PublishOutput{MessageId: aws.String("sns-msg-123")}, nil
+}
+
+func TestSNSSendMessage_Success(t *testing.T) {
+	// Arrange
+	api := &fakeSNSPublisher{}
+	client := newSNSClient(api,
+		&config.SNSConfig{SenderID: "Insider", SMSType: "Transactional"},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10},
+		nil,
+	)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "sns-msg-123", result.MessageID)
+	assert.Len(t, api.inputs, 1)
+	assert.Equal(t, "+905551234567", aws.ToString(api.inputs[0].PhoneNumber))
+	assert.Equal(t, "Test message", aws.ToString(api.inputs[0].Message))
+	assert.Equal(t, "Insider", aws.ToString(api.inputs[0].MessageAttributes["AWS.SNS.SMS.SenderID"].StringValue))
+	assert.Equal(t, "Transactional", aws.ToString(api.inputs[0].MessageAttributes["AWS.SNS.SMS.SMSType"].StringValue))
+}
+
+func TestSNSSendMessage_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		code  apperrors.ErrorCode
+		calls int
+	}{
+		{"client fault", &smithy.GenericAPIError{Code: "InvalidParameter", Fault: smithy.FaultClient}, apperrors.ErrorCodeProviderRejected, 1},
+		{"unmapped client fault", &smithy.GenericAPIError{Code: "AuthorizationError", Fault: smithy.FaultClient}, apperrors.ErrorCodeInvalidResponse, 1},
+		{"server fault", &smithy.GenericAPIError{Code: "InternalError", Fault: smithy.FaultServer}, apperrors.ErrorCodeServerError, 2},
+		{"transport", errors.New("connection refused"), apperrors.ErrorCodeNetworkError, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			api := &fakeSNSPublisher{errs: []error{tt.err, tt.err}}
+			client := newSNSClient(api,
+				&config.SNSConfig{SMSType: "Transactional"},
+				&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10, MaxRetries: 1},
+				nil,
+			)
+
+			// Act
+			result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+			// Assert
+			assert.Nil(t, result)
+			appErr, ok := err.(*apperrors.AppError)
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, appErr.Code)
+			assert.Len(t, api.inputs, tt.calls)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeClientCertificate creates a self-signed client certificate and returns
+// the paths of its PEM files and the certificate itself.
+func writeClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "insider-messaging"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestNewHTTPClient_MutualTLS(t *testing.T) {
+	// Arrange
+	certFile, keyFile, clientCert := writeClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	serverPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, serverPEM, 0o600))
+
+	withCert := &config.WebhookConfig{TLSCAFile: caFile, TLSCertFile: certFile, TLSKeyFile: keyFile}
+	withoutCert := &config.WebhookConfig{TLSCAFile: caFile}
+
+	// Act
+	client, err := newHTTPClient(withCert, 5*time.Second)
+	assert.NoError(t, err)
+	resp, err := client.Get(server.URL)
+
+	anonymous, anonErr := newHTTPClient(withoutCert, 5*time.Second)
+	assert.NoError(t, anonErr)
+	_, rejected := anonymous.Get(server.URL)
+
+	// Assert
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Error(t, rejected)
+}
+
+func TestNewHTTPClient_UsesProxy(t *testing.T) {
+	// Arrange
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer proxy.Close()
+
+	client, err := newHTTPClient(&config.WebhookConfig{ProxyURL: proxy.URL}, 5*time.Second)
+	assert.NoError(t, err)
+
+	// Act
+	resp, err := client.Get("http://provider.invalid/send")
+
+	// Assert
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	}
+	assert.Equal(t, "http://provider.invalid/send", proxiedURL)
+}
+
+func TestNewHTTPClient_InvalidTLSFiles(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name string
+		cfg  *config.WebhookConfig
+	}{
+		{name: "missing CA file", cfg: &config.WebhookConfig{TLSCAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "CA file without certificates", cfg: &config.WebhookConfig{TLSCAFile: notPEM}},
+		{name: "missing client key", cfg: &config.WebhookConfig{TLSCertFile: notPEM, TLSKeyFile: filepath.Join(t.TempDir(), "missing.key")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newHTTPClient(tt.cfg, time.Second)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+// twilioRequestIDHeader carries Twilio's identifier for the API request.
+const twilioRequestIDHeader = "Twilio-Request-Id"
+
+type twilioMessageResponse struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+type twilioErrorResponse struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	MoreInfo string `json:"more_info"`
+}
+
+type twilioClient struct {
+	client              *http.Client
+	endpoint            string
+	accountSID          string
+	authToken           string
+	fromNumber          string
+	messagingServiceSID string
+	dispatcher          *dispatcher
+}
+
+// NewTwilioClient sends messages through the Twilio Messages API. Retries,
+// rate limiting, the breaker and the transport follow webhookCfg like the
+// webhook client.
+func NewTwilioClient(cfg *config.TwilioConfig, webhookCfg *config.WebhookConfig, breaker *CircuitBreaker) (provider.SenderProvider, error) {
+	client, err := newHTTPClient(webhookCfg, time.Duration(webhookCfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &twilioClient{
+		client: client,
+		endpoint: fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json",
+			strings.TrimRight(cfg.BaseURL, "/"), url.PathEscape(cfg.AccountSID)),
+		accountSID:          cfg.AccountSID,
+		authToken:           cfg.AuthToken,
+		fromNumber:          cfg.FromNumber,
+		messagingServiceSID: cfg.MessagingServiceSID,
+		dispatcher:          newDispatcher("twilio", webhookCfg, breaker),
+	}, nil
+}
+
+func (t *twilioClient) Name() string {
+	return "twilio"
+}
+
+func (t *twilioClient) SetRateLimit(perSecond int) {
+	t.dispatcher.rateLimiter.SetCeiling(perSecond)
+}
+
+func (t *twilioClient) LimitDaily(quota DailyQuota) {
+	t.dispatcher.quota = quota
+}
+
+func (t *twilioClient) SendMessage(ctx context.Context, phoneNumber, content string, _ map[string]string) (*provider.SendResult, error) {
+	ctx, requestID := requestid.Ensure(ctx)
+
+	return t.dispatcher.dispatch(ctx, requestID, func(ctx context.Context, requestID string) (*provider.SendResult, error) {
+		return t.send(ctx, requestID, phoneNumber, content)
+	})
+}
+
+func (t *twilioClient) send(ctx context.Context, requestID, phoneNumber, content string) (*provider.SendResult, error) {
+	form := url.Values{}
+	form.Set("To", phoneNumber)
+	form.Set("Body", content)
+	// A messaging service picks the sender itself and wins over a fixed number
+	if t.messagingServiceSID != "" {
+		form.Set("MessagingServiceSid", t.messagingServiceSID)
+	} else {
+		form.Set("From", t.fromNumber)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to create request", err)
+	}
+
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(requestid.Header, requestID)
+
+	startTime := time.Now()
+	resp, err := t.client.Do(req)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("twilio request failed",
+			zap.Error(err),
+			zap.String("phone_number", phoneNumber),
+			zap.Duration("duration", duration),
+		)
+		return nil, transportError(ctx, "twilio", err)
+	}
+	defer resp.Body.Close()
+	provider.RecordResponseStatus(ctx, resp.StatusCode)
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "failed to read response body", err)
+	}
+
+	providerRequestID := resp.Header.Get(twilioRequestIDHeader)
+
+	logger.FromContext(ctx).Info("twilio request completed",
+		zap.String("provider_request_id", providerRequestID),
+		zap.String("phone_number", phoneNumber),
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", duration),
+	)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var twilioErr twilioErrorResponse
+		_ = json.Unmarshal(responseBody, &twilioErr)
+
+		logger.FromContext(ctx).Error("twilio returned error status",
+			zap.Int("status_code", resp.StatusCode),
+			zap.Int("twilio_code", twilioErr.Code),
+			zap.String("response_body", string(responseBody)),
+		)
+		var twilioCode string
+		if twilioErr.Code != 0 {
+			twilioCode = strconv.Itoa(twilioErr.Code)
+		}
+		provider.RecordRefusal(ctx, twilioCode, responseBody)
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, throttledError("twilio", resp.Header, time.Now())
+		}
+		if resp.StatusCode >= 500 {
+			return nil, apperrors.New(apperrors.ErrorCodeServerError,
+				fmt.Sprintf("twilio server error: %d", resp.StatusCode))
+		}
+
+		if twilioErr.Code != 0 {
+			code := apperrors.ErrorCodeInvalidResponse
+			if mapped, ok := apperrors.ProviderErrorCode("twilio", twilioCode); ok {
+				code = mapped
+			}
+			return nil, apperrors.New(code,
+				fmt.Sprintf("twilio returned status %d: error %d: %s", resp.StatusCode, twilioErr.Code, twilioErr.Message))
+		}
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse,
+			fmt.Sprintf("twilio returned status %d: %s", resp.StatusCode, string(responseBody)))
+	}
+
+	var twilioResp twilioMessageResponse
+	if err := json.Unmarshal(responseBody, &twilioResp); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "invalid JSON response from twilio", err)
+	}
+
+	if twilioResp.SID == "" {
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse, "twilio response missing sid")
+	}
+
+	return &provider.SendResult{
+		MessageID:         twilioResp.SID,
+		Message:           twilioResp.Status,
+		RequestID:         requestID,
+		ProviderRequestID: providerRequestID,
+		Response:          responseBody,
+	}, nil
+}
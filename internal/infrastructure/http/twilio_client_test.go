@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTwilioClient(t *testing.T, cfg *config.TwilioConfig, webhookCfg *config.WebhookConfig, breaker *CircuitBreaker) provider.SenderProvider {
+	t.Helper()
+	client, err := NewTwilioClient(cfg, webhookCfg, breaker)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return client
+}
+
+func TestTwilioSendMessage_Success(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "secret", pass)
+
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "+905551234567", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		assert.Equal(t, "Test message", r.PostForm.Get("Body"))
+
+		w.Header().Set("Twilio-Request-Id", "RQ789")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	client := newTestTwilioClient(t,
+		&config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", FromNumber: "+15005550006", BaseURL: server.URL},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10},
+		nil,
+	)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "twilio", client.Name())
+	assert.Equal(t, "SM123", result.MessageID)
+	assert.Equal(t, "queued", result.Message)
+	assert.Equal(t, "RQ789", result.ProviderRequestID)
+}
+
+func TestTwilioSendMessage_UsesMessagingService(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "MG456", r.PostForm.Get("MessagingServiceSid"))
+		assert.Empty(t, r.PostForm.Get("From"))
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "accepted"}`))
+	}))
+	defer server.Close()
+
+	client := newTestTwilioClient(t,
+		&config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", FromNumber: "+15005550006", MessagingServiceSID: "MG456", BaseURL: server.URL},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10},
+		nil,
+	)
+
+	// Act
+	_, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestTwilioSendMessage_RejectedIsNotRetried(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 21211, "message": "The 'To' number is not a valid phone number.", "status": 400}`))
+	}))
+	defer server.Close()
+
+	client := newTestTwilioClient(t,
+		&config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", FromNumber: "+15005550006", BaseURL: server.URL},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10, MaxRetries: 2, RetryBackoff: time.Millisecond},
+		nil,
+	)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeInvalidRecipient, appErr.Code)
+	assert.False(t, appErr.Retryable())
+	assert.Contains(t, appErr.Message, "21211")
+	assert.Equal(t, 1, calls)
+}
+
+func TestTwilioSendMessage_RecordsRefusal(t *testing.T) {
+	// Arrange
+	body := `{"code": 30007, "message": "Message filtered by the carrier", "status": 400}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestTwilioClient(t,
+		&config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", FromNumber: "+15005550006", BaseURL: server.URL},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10, MaxRetries: 0, RetryBackoff: time.Millisecond},
+		nil,
+	)
+	ctx, status := provider.WithResponseStatus(context.Background())
+
+	// Act
+	_, err := client.SendMessage(ctx, "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.True(t, status.Refused)
+	assert.Equal(t, http.StatusBadRequest, status.Code)
+	assert.Equal(t, "30007", status.ErrorCode)
+	assert.Equal(t, body, status.Body)
+}
+
+func TestTwilioSendMessage_RetriesServerError(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	client := newTestTwilioClient(t,
+		&config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", FromNumber: "+15005550006", BaseURL: server.URL},
+		&config.WebhookConfig{TimeoutSeconds: 10, RateLimitPerSecond: 10, MaxRetries: 1, RetryBackoff: time.Millisecond},
+		nil,
+	)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "SM123", result.MessageID)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// webhookAuth puts the webhook credentials on a request.
+type webhookAuth interface {
+	authorize(ctx context.Context, req *http.Request) error
+}
+
+// newWebhookAuth returns the scheme selected by cfg.Auth. An empty scheme
+// sends the auth key header, as before schemes could be chosen.
+func newWebhookAuth(cfg *config.WebhookConfig) (webhookAuth, error) {
+	switch cfg.Auth.Scheme {
+	case "", "header":
+		return headerAuth(cfg.AuthKey), nil
+	case "basic":
+		return basicAuth{username: cfg.Auth.Username, password: cfg.Auth.Password}, nil
+	case "bearer":
+		return bearerAuth(cfg.Auth.Token), nil
+	case "oauth2":
+		return newOAuth2Auth(cfg)
+	default:
+		return nil, fmt.Errorf("unknown webhook auth scheme %q", cfg.Auth.Scheme)
+	}
+}
+
+// headerAuth sends the key as x-ins-auth-key.
+type headerAuth string
+
+func (a headerAuth) authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("x-ins-auth-key", string(a))
+	return nil
+}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+func (a basicAuth) authorize(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+type bearerAuth string
+
+func (a bearerAuth) authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(a))
+	return nil
+}
+
+// oauth2Auth sends a bearer token from the client-credentials grant. The
+// token is shared by all requests and fetched again expiryMargin before it
+// expires; a token without expires_in is kept until the webhook refuses it.
+type oauth2Auth struct {
+	client       *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	expiryMargin time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// tokenResponse is the part of an RFC 6749 token response that is used.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// newOAuth2Auth fetches tokens over the webhook transport, but never through
+// the wire log, which would otherwise hold the tokens.
+func newOAuth2Auth(cfg *config.WebhookConfig) (*oauth2Auth, error) {
+	tokenCfg := *cfg
+	tokenCfg.WireLog.Enabled = false
+	client, err := newHTTPClient(&tokenCfg, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	oauth2 := cfg.Auth.OAuth2
+	return &oauth2Auth{
+		client:       client,
+		tokenURL:     oauth2.TokenURL,
+		clientID:     oauth2.ClientID,
+		clientSecret: oauth2.ClientSecret,
+		scopes:       oauth2.Scopes,
+		expiryMargin: oauth2.ExpiryMargin,
+		now:          time.Now,
+	}, nil
+}
+
+func (a *oauth2Auth) authorize(ctx context.Context, req *http.Request) error {
+	token, err := a.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// invalidate drops the token a request was refused with, unless another
+// request already replaced it.
+func (a *oauth2Auth) invalidate(req *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if req.Header.Get("Authorization") == "Bearer "+a.token {
+		a.token = ""
+	}
+}
+
+// currentToken returns the cached token, fetching a new one while holding
+// the lock so concurrent requests wait for a single fetch.
+func (a *oauth2Auth) currentToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expiresAt.IsZero() || a.now().Before(a.expiresAt.Add(-a.expiryMargin))) {
+		return a.token, nil
+	}
+
+	token, err := a.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token.AccessToken
+	a.expiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		a.expiresAt = a.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	logger.FromContext(ctx).Debug("fetched webhook oauth2 token",
+		zap.Int64("expires_in", token.ExpiresIn),
+	)
+	return a.token, nil
+}
+
+func (a *oauth2Auth) fetchToken(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to create oauth2 token request", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, "oauth2 token", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "failed to read oauth2 token response", err)
+	}
+	if resp.StatusCode >= 500 {
+		return nil, apperrors.New(apperrors.ErrorCodeServerError,
+			fmt.Sprintf("oauth2 token endpoint error: %d", resp.StatusCode))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse,
+			fmt.Sprintf("oauth2 token endpoint returned status %d: %s", resp.StatusCode, string(body)))
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "failed to parse oauth2 token response", err)
+	}
+	if token.AccessToken == "" {
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse, "oauth2 token response has no access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse,
+			fmt.Sprintf("unsupported oauth2 token type %q", token.TokenType))
+	}
+	return &token, nil
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendMessage_AuthSchemes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		auth   config.WebhookAuthConfig
+		header string
+		want   string
+	}{
+		{name: "header", auth: config.WebhookAuthConfig{Scheme: "header"}, header: "x-ins-auth-key", want: "test-auth-key"},
+		{name: "basic", auth: config.WebhookAuthConfig{Scheme: "basic", Username: "user", Password: "pass"}, header: "Authorization", want: "Basic dXNlcjpwYXNz"},
+		{name: "bearer", auth: config.WebhookAuthConfig{Scheme: "bearer", Token: "static-token"}, header: "Authorization", want: "Bearer static-token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var got http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				json.NewEncoder(w).Encode(WebhookResponse{MessageID: "webhook-msg-123"})
+			}))
+			defer server.Close()
+
+			client := newTestWebhookClient(t, &config.WebhookConfig{
+				URL:                server.URL,
+				AuthKey:            "test-auth-key",
+				TimeoutSeconds:     10,
+				RateLimitPerSecond: 10,
+				Auth:               tc.auth,
+			}, nil)
+
+			// Act
+			_, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got.Get(tc.header))
+			if tc.header == "Authorization" {
+				assert.Empty(t, got.Get("x-ins-auth-key"), "the auth key is only sent with the header scheme")
+			}
+		})
+	}
+}
+
+// newTestTokenServer issues "token-1", "token-2", ... valid for expiresIn
+// seconds to the test client.
+func newTestTokenServer(t *testing.T, expiresIn int, issued *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client-id", clientID)
+		assert.Equal(t, "client-secret", secret)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "sms:send sms:read", r.PostForm.Get("scope"))
+
+		n := atomic.AddInt32(issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-" + strconv.Itoa(int(n)),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestOAuth2Config(webhookURL, tokenURL string) *config.WebhookConfig {
+	return &config.WebhookConfig{
+		URL:                webhookURL,
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 100,
+		Auth: config.WebhookAuthConfig{
+			Scheme: "oauth2",
+			OAuth2: config.OAuth2Config{
+				TokenURL:     tokenURL,
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				Scopes:       []string{"sms:send", "sms:read"},
+				ExpiryMargin: 30 * time.Second,
+			},
+		},
+	}
+}
+
+func TestOAuth2Auth_CachesTokenUntilShortlyBeforeExpiry(t *testing.T) {
+	// Arrange
+	var issued int32
+	tokenServer := newTestTokenServer(t, 3600, &issued)
+	auth, err := newOAuth2Auth(newTestOAuth2Config("http://unused.invalid", tokenServer.URL))
+	assert.NoError(t, err)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	token := func() string {
+		req := httptest.NewRequest(http.MethodPost, "http://unused.invalid", nil)
+		assert.NoError(t, auth.authorize(context.Background(), req))
+		return req.Header.Get("Authorization")
+	}
+
+	// Act
+	first := token()
+	now = now.Add(3569 * time.Second)
+	cached := token()
+	now = now.Add(time.Second)
+	refreshed := token()
+
+	// Assert
+	assert.Equal(t, "Bearer token-1", first)
+	assert.Equal(t, "Bearer token-1", cached)
+	assert.Equal(t, "Bearer token-2", refreshed, "the token is replaced within the expiry margin")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestSendMessage_OAuth2RefetchesRefusedToken(t *testing.T) {
+	// Arrange
+	var issued int32
+	tokenServer := newTestTokenServer(t, 3600, &issued)
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		// The provider revoked the first token before it expired
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(WebhookResponse{MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	client := newTestWebhookClient(t, newTestOAuth2Config(server.URL, tokenServer.URL), nil)
+
+	// Act
+	first, firstErr := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+	second, secondErr := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, "webhook-msg-123", first.MessageID)
+	assert.Equal(t, "webhook-msg-123", second.MessageID)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"}, authorizations)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestSendMessage_OAuth2TokenEndpointFailure(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		wantCode   apperrors.ErrorCode
+		wantCalls  int32
+	}{
+		{name: "bad credentials are not retried", statusCode: http.StatusUnauthorized, wantCode: apperrors.ErrorCodeInvalidResponse, wantCalls: 1},
+		{name: "server errors are retried", statusCode: http.StatusServiceUnavailable, wantCode: apperrors.ErrorCodeServerError, wantCalls: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var calls int32
+			tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer tokenServer.Close()
+			webhookCalled := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				webhookCalled = true
+			}))
+			defer server.Close()
+
+			cfg := newTestOAuth2Config(server.URL, tokenServer.URL)
+			cfg.MaxRetries = 2
+			cfg.RetryBackoff = time.Millisecond
+			client := newTestWebhookClient(t, cfg, nil)
+
+			// Act
+			result, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
+
+			// Assert
+			assert.Nil(t, result)
+			if appErr, ok := err.(*apperrors.AppError); assert.True(t, ok) {
+				assert.Equal(t, tc.wantCode, appErr.Code)
+			}
+			assert.Equal(t, tc.wantCalls, atomic.LoadInt32(&calls))
+			assert.False(t, webhookCalled)
+		})
+	}
+}
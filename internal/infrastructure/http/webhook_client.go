@@ -7,56 +7,122 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/pkg/config"
 	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/signature"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 )
 
+// WebhookRequest is posted for every message. Channel is only set on the
+// email and push webhooks; SMS requests carry none.
 type WebhookRequest struct {
-	To      string `json:"to"`
-	Content string `json:"content"`
+	Channel  string            `json:"channel,omitempty"`
+	To       string            `json:"to"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// Headers carrying the HMAC-SHA256 signatures of a request or response body
+// and the Unix time they were made at; see the signature package.
+const (
+	SignatureHeader          = "x-ins-signature"
+	SignatureTimestampHeader = "x-ins-signature-timestamp"
+)
+
 type WebhookResponse struct {
 	Message   string `json:"message"`
 	MessageID string `json:"messageId"`
 }
 
-type WebhookClient interface {
-	SendMessage(ctx context.Context, phoneNumber, content string) (*WebhookResponse, error)
+type webhookClient struct {
+	name                    string
+	channel                 string
+	client                  *http.Client
+	url                     string
+	auth                    webhookAuth
+	providerRequestIDHeader string
+	signingSecrets          []string
+	responseSecrets         []string
+	responseTolerance       time.Duration
+	dispatcher              *dispatcher
 }
 
-type webhookClient struct {
-	client      *http.Client
-	url         string
-	authKey     string
-	rateLimiter *rate.Limiter
+// NewWebhookClient retries timeouts, network errors and 5xx responses up to
+// cfg.MaxRetries times. A nil breaker disables short-circuiting. It fails
+// when the TLS files in cfg cannot be loaded.
+func NewWebhookClient(cfg *config.WebhookConfig, breaker *CircuitBreaker) (provider.SenderProvider, error) {
+	return newWebhookClient("webhook", "", cfg.URL, cfg, breaker)
 }
 
-func NewWebhookClient(cfg *config.WebhookConfig) WebhookClient {
-	return &webhookClient{
-		client: &http.Client{
-			Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second,
-		},
-		url:         cfg.URL,
-		authKey:     cfg.AuthKey,
-		rateLimiter: rate.NewLimiter(rate.Limit(cfg.RateLimitPerSecond), cfg.RateLimitPerSecond),
+// NewChannelWebhookClient posts the messages of channel to url, with the
+// channel named in every request.
+func NewChannelWebhookClient(channel valueobject.Channel, url string, cfg *config.WebhookConfig, breaker *CircuitBreaker) (provider.SenderProvider, error) {
+	return newWebhookClient(channel.String()+"-webhook", channel.String(), url, cfg, breaker)
+}
+
+func newWebhookClient(name, channel, url string, cfg *config.WebhookConfig, breaker *CircuitBreaker) (*webhookClient, error) {
+	auth, err := newWebhookAuth(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return newWebhookClientWith(name, channel, url, auth, cfg, newDispatcher(name, cfg, breaker))
 }
 
-func (w *webhookClient) SendMessage(ctx context.Context, phoneNumber, content string) (*WebhookResponse, error) {
-	if err := w.rateLimiter.Wait(ctx); err != nil {
-		logger.Get().Warn("rate limiter context cancelled", zap.Error(err))
-		return nil, apperrors.Wrap(apperrors.ErrorCodeRateLimit, "rate limit wait cancelled", err)
+// newWebhookClientWith sends through dispatcher, which clients posting to
+// the same webhook share so they are held to one rate limit.
+func newWebhookClientWith(name, channel, url string, auth webhookAuth, cfg *config.WebhookConfig, dispatcher *dispatcher) (*webhookClient, error) {
+	client, err := newHTTPClient(cfg, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, err
 	}
 
+	return &webhookClient{
+		name:                    name,
+		channel:                 channel,
+		client:                  client,
+		url:                     url,
+		auth:                    auth,
+		providerRequestIDHeader: cfg.ProviderRequestIDHeader,
+		signingSecrets:          cfg.SigningSecrets,
+		responseSecrets:         cfg.ResponseSecrets,
+		responseTolerance:       cfg.ResponseSignatureTolerance,
+		dispatcher:              dispatcher,
+	}, nil
+}
+
+func (w *webhookClient) Name() string {
+	return w.name
+}
+
+func (w *webhookClient) SetRateLimit(perSecond int) {
+	w.dispatcher.rateLimiter.SetCeiling(perSecond)
+}
+
+func (w *webhookClient) LimitDaily(quota DailyQuota) {
+	w.dispatcher.quota = quota
+}
+
+func (w *webhookClient) SendMessage(ctx context.Context, recipient, content string, metadata map[string]string) (*provider.SendResult, error) {
+	ctx, requestID := requestid.Ensure(ctx)
+
+	return w.dispatcher.dispatch(ctx, requestID, func(ctx context.Context, requestID string) (*provider.SendResult, error) {
+		return w.send(ctx, requestID, recipient, content, metadata)
+	})
+}
+
+func (w *webhookClient) send(ctx context.Context, requestID, recipient, content string, metadata map[string]string) (*provider.SendResult, error) {
 	reqBody := WebhookRequest{
-		To:      phoneNumber,
-		Content: content,
+		Channel:  w.channel,
+		To:       recipient,
+		Content:  content,
+		Metadata: metadata,
 	}
 
 	bodyBytes, err := json.Marshal(reqBody)
@@ -64,61 +130,74 @@ func (w *webhookClient) SendMessage(ctx context.Context, phoneNumber, content st
 		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to marshal request", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to create request", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-ins-auth-key", w.authKey)
-
 	startTime := time.Now()
-	resp, err := w.client.Do(req)
+	resp, err := w.post(ctx, requestID, bodyBytes)
 	duration := time.Since(startTime)
 
 	if err != nil {
-		logger.Get().Error("webhook request failed",
+		logger.FromContext(ctx).Error("webhook request failed",
 			zap.Error(err),
-			zap.String("phone_number", phoneNumber),
+			zap.String("recipient", recipient),
 			zap.Duration("duration", duration),
 		)
-
-		if ctx.Err() == context.DeadlineExceeded {
-			return nil, apperrors.Wrap(apperrors.ErrorCodeTimeout, "webhook request timeout", err)
+		if _, ok := err.(*apperrors.AppError); ok {
+			return nil, err
 		}
-		return nil, apperrors.Wrap(apperrors.ErrorCodeNetworkError, "network error during webhook request", err)
+		return nil, transportError(ctx, "webhook", err)
 	}
 	defer resp.Body.Close()
+	provider.RecordResponseStatus(ctx, resp.StatusCode)
 
 	responseBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "failed to read response body", err)
 	}
 
-	logger.Get().Info("webhook request completed",
-		zap.String("phone_number", phoneNumber),
+	providerRequestID := resp.Header.Get(w.providerRequestIDHeader)
+
+	logger.FromContext(ctx).Info("webhook request completed",
+		zap.String("provider_request_id", providerRequestID),
+		zap.String("recipient", recipient),
 		zap.Int("status_code", resp.StatusCode),
 		zap.Duration("duration", duration),
 	)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Get().Error("webhook returned error status",
+		logger.FromContext(ctx).Error("webhook returned error status",
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("response_body", string(responseBody)),
 		)
+		provider.RecordRefusal(ctx, "", responseBody)
 
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, throttledError("webhook", resp.Header, time.Now())
+		}
 		if resp.StatusCode >= 500 {
 			return nil, apperrors.New(apperrors.ErrorCodeServerError,
 				fmt.Sprintf("webhook server error: %d", resp.StatusCode))
 		}
+		// The webhook refused the message itself; other 4xx, such as a bad
+		// auth key, are configuration problems that can be fixed
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity {
+			return nil, apperrors.New(apperrors.ErrorCodeProviderRejected,
+				fmt.Sprintf("webhook rejected message with status %d: %s", resp.StatusCode, string(responseBody)))
+		}
 
 		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse,
 			fmt.Sprintf("webhook returned status %d: %s", resp.StatusCode, string(responseBody)))
 	}
 
+	if err := w.verifyResponse(resp.Header, responseBody); err != nil {
+		logger.FromContext(ctx).Error("webhook response signature rejected",
+			zap.Error(err),
+			zap.String("provider_request_id", providerRequestID),
+		)
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInvalidResponse, "webhook response signature rejected", err)
+	}
+
 	var webhookResp WebhookResponse
 	if err := json.Unmarshal(responseBody, &webhookResp); err != nil {
-		logger.Get().Error("failed to unmarshal webhook response",
+		logger.FromContext(ctx).Error("failed to unmarshal webhook response",
 			zap.Error(err),
 			zap.String("response_body", string(responseBody)),
 		)
@@ -129,5 +208,71 @@ func (w *webhookClient) SendMessage(ctx context.Context, phoneNumber, content st
 		return nil, apperrors.New(apperrors.ErrorCodeInvalidResponse, "webhook response missing messageId")
 	}
 
-	return &webhookResp, nil
+	return &provider.SendResult{
+		MessageID:         webhookResp.MessageID,
+		Message:           webhookResp.Message,
+		RequestID:         requestID,
+		ProviderRequestID: providerRequestID,
+		Response:          responseBody,
+	}, nil
+}
+
+// post sends the body once, or twice when an OAuth2 token was refused with
+// 401 before it expired: the token is dropped and the request repeated
+// with a new one.
+func (w *webhookClient) post(ctx context.Context, requestID string, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := w.newRequest(ctx, requestID, body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		oauth2, ok := w.auth.(*oauth2Auth)
+		if !ok || resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		oauth2.invalidate(req)
+		logger.FromContext(ctx).Warn("webhook refused the oauth2 token, fetching a new one")
+	}
+}
+
+func (w *webhookClient) newRequest(ctx context.Context, requestID string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrorCodeInternal, "failed to create request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.Header, requestID)
+	if err := w.auth.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	if len(w.signingSecrets) > 0 {
+		timestamp := time.Now().Unix()
+		req.Header.Set(SignatureHeader, signature.SignAll(w.signingSecrets, timestamp, body))
+		req.Header.Set(SignatureTimestampHeader, strconv.FormatInt(timestamp, 10))
+	}
+	return req, nil
+}
+
+// verifyResponse checks the response was signed with one of the response
+// secrets; without any configured every response is accepted.
+func (w *webhookClient) verifyResponse(header http.Header, body []byte) error {
+	if len(w.responseSecrets) == 0 {
+		return nil
+	}
+	return signature.VerifyAny(
+		w.responseSecrets,
+		header.Get(SignatureHeader),
+		header.Get(SignatureTimestampHeader),
+		body,
+		w.responseTolerance,
+		time.Now(),
+	)
 }
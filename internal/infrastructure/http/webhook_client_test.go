@@ -3,16 +3,32 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/eneskaya/insider-messaging/internal/domain/provider"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/pkg/config"
 	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/eneskaya/insider-messaging/pkg/signature"
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestWebhookClient(t *testing.T, cfg *config.WebhookConfig, breaker *CircuitBreaker) provider.SenderProvider {
+	t.Helper()
+	client, err := NewWebhookClient(cfg, breaker)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return client
+}
+
 func TestSendMessage_Success(t *testing.T) {
 	// Arrange - Create mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,10 +62,10 @@ func TestSendMessage_Success(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act
-	result, err := client.SendMessage(context.Background(), "+905551234567", "Test message")
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test message", nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -58,6 +74,98 @@ func TestSendMessage_Success(t *testing.T) {
 	assert.Equal(t, "webhook-msg-123", result.MessageID)
 }
 
+func TestSendMessage_ForwardsMetadata(t *testing.T) {
+	// Arrange
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	client := newTestWebhookClient(t, &config.WebhookConfig{URL: server.URL, TimeoutSeconds: 10, RateLimitPerSecond: 10}, nil)
+
+	// Act
+	_, err := client.SendMessage(context.Background(), "+905551234567", "Test", map[string]string{"campaign": "spring-sale"})
+	_, plainErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, plainErr)
+	if assert.Len(t, bodies, 2) {
+		assert.Equal(t, map[string]interface{}{"campaign": "spring-sale"}, bodies[0]["metadata"])
+		assert.NotContains(t, bodies[1], "metadata", "messages without metadata keep the original body")
+		assert.NotContains(t, bodies[1], "channel")
+	}
+}
+
+func TestSendMessage_ChannelWebhookNamesChannel(t *testing.T) {
+	// Arrange
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{URL: "http://sms.invalid", TimeoutSeconds: 10, RateLimitPerSecond: 10}
+	client, err := NewChannelWebhookClient(valueobject.ChannelEmail, server.URL, cfg, nil)
+	assert.NoError(t, err)
+
+	// Act
+	_, sendErr := client.SendMessage(context.Background(), "ada@example.com", "Your code is 123456", nil)
+
+	// Assert
+	assert.NoError(t, sendErr)
+	assert.Equal(t, "email-webhook", client.Name())
+	if assert.Len(t, bodies, 1) {
+		assert.Equal(t, "email", bodies[0]["channel"])
+		assert.Equal(t, "ada@example.com", bodies[0]["to"])
+	}
+}
+
+func TestSendMessage_PropagatesRequestID(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "trace-abc", r.Header.Get("X-Request-ID"))
+
+		w.Header().Set("X-Provider-Request-Id", "provider-req-789")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                     server.URL,
+		AuthKey:                 "test-auth-key",
+		TimeoutSeconds:          10,
+		RateLimitPerSecond:      10,
+		ProviderRequestIDHeader: "X-Provider-Request-Id",
+	}
+
+	client := newTestWebhookClient(t, cfg, nil)
+	ctx := requestid.WithRequestID(context.Background(), "trace-abc")
+
+	// Act
+	result, err := client.SendMessage(ctx, "+905551234567", "Test message", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "trace-abc", result.RequestID)
+	assert.Equal(t, "provider-req-789", result.ProviderRequestID)
+}
+
 func TestSendMessage_ServerError(t *testing.T) {
 	// Arrange
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -73,10 +181,10 @@ func TestSendMessage_ServerError(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act
-	result, err := client.SendMessage(context.Background(), "+905551234567", "Test")
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -101,17 +209,17 @@ func TestSendMessage_BadRequest(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act
-	result, err := client.SendMessage(context.Background(), "invalid-phone", "Test")
+	result, err := client.SendMessage(context.Background(), "invalid-phone", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	appErr, ok := err.(*apperrors.AppError)
 	assert.True(t, ok)
-	assert.Equal(t, apperrors.ErrorCodeInvalidResponse, appErr.Code)
+	assert.Equal(t, apperrors.ErrorCodeProviderRejected, appErr.Code)
 	assert.Contains(t, err.Error(), "400")
 }
 
@@ -131,10 +239,10 @@ func TestSendMessage_InvalidJSONResponse(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act
-	result, err := client.SendMessage(context.Background(), "+905551234567", "Test")
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -165,10 +273,10 @@ func TestSendMessage_MissingMessageID(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act
-	result, err := client.SendMessage(context.Background(), "+905551234567", "Test")
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -192,13 +300,13 @@ func TestSendMessage_Timeout(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
 
 	// Act
-	result, err := client.SendMessage(ctx, "+905551234567", "Test")
+	result, err := client.SendMessage(ctx, "+905551234567", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -229,12 +337,12 @@ func TestSendMessage_RateLimiting(t *testing.T) {
 		RateLimitPerSecond: 2, // 2 requests per second
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	// Act - Send 3 messages quickly
 	start := time.Now()
 	for i := 0; i < 3; i++ {
-		_, err := client.SendMessage(context.Background(), "+905551234567", "Test")
+		_, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
 		assert.NoError(t, err)
 	}
 	duration := time.Since(start)
@@ -259,13 +367,13 @@ func TestSendMessage_ContextCancelled(t *testing.T) {
 		RateLimitPerSecond: 10,
 	}
 
-	client := NewWebhookClient(cfg)
+	client := newTestWebhookClient(t, cfg, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
 	// Act
-	result, err := client.SendMessage(ctx, "+905551234567", "Test")
+	result, err := client.SendMessage(ctx, "+905551234567", "Test", nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -275,3 +383,339 @@ func TestSendMessage_ContextCancelled(t *testing.T) {
 	assert.Equal(t, apperrors.ErrorCodeRateLimit, appErr.Code)
 	assert.Contains(t, err.Error(), "rate limit wait cancelled")
 }
+
+func TestSendMessage_RetriesServerErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+		MaxRetries:         2,
+		RetryBackoff:       time.Millisecond,
+	}
+
+	client := newTestWebhookClient(t, cfg, nil)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook-msg-123", result.MessageID)
+	assert.Equal(t, 3, calls)
+}
+
+func TestSendMessage_DoesNotRetryClientErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+		MaxRetries:         2,
+		RetryBackoff:       time.Millisecond,
+	}
+
+	client := newTestWebhookClient(t, cfg, nil)
+
+	// Act
+	_, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSendMessage_OpenBreakerShortCircuits(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+	}
+
+	breaker := NewCircuitBreaker(2, time.Minute)
+	client := newTestWebhookClient(t, cfg, breaker)
+
+	// Act
+	client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeCircuitOpen, appErr.Code)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, BreakerOpen, breaker.Status().State)
+}
+
+func TestSendMessage_SignsRequestWithEveryKey(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		signatures := strings.Split(r.Header.Get(SignatureHeader), ",")
+		timestamp := r.Header.Get(SignatureTimestampHeader)
+		if assert.Len(t, signatures, 2) {
+			assert.NoError(t, signature.Verify("new-key", signatures[0], timestamp, body, time.Minute, time.Now()))
+			assert.NoError(t, signature.Verify("old-key", signatures[1], timestamp, body, time.Minute, time.Now()))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	client := newTestWebhookClient(t, &config.WebhookConfig{
+		URL:                server.URL,
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+		SigningSecrets:     []string{"new-key", "old-key"},
+	}, nil)
+
+	// Act
+	_, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestSendMessage_VerifiesResponseSignature(t *testing.T) {
+	// Arrange
+	responseKey := "old-key"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := json.Marshal(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+		if responseKey != "" {
+			now := time.Now().Unix()
+			w.Header().Set(SignatureHeader, signature.Sign(responseKey, now, body))
+			w.Header().Set(SignatureTimestampHeader, strconv.FormatInt(now, 10))
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := newTestWebhookClient(t, &config.WebhookConfig{
+		URL:                        server.URL,
+		TimeoutSeconds:             10,
+		RateLimitPerSecond:         10,
+		ResponseSecrets:            []string{"new-key", "old-key"},
+		ResponseSignatureTolerance: time.Minute,
+	}, nil)
+
+	// Act
+	signedWithOldKey, oldKeyErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	responseKey = "foreign-key"
+	_, foreignErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	responseKey = ""
+	_, unsignedErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, oldKeyErr)
+	assert.Equal(t, "webhook-msg-123", signedWithOldKey.MessageID)
+	for _, err := range []error{foreignErr, unsignedErr} {
+		appErr, ok := err.(*apperrors.AppError)
+		if assert.True(t, ok) {
+			assert.Equal(t, apperrors.ErrorCodeInvalidResponse, appErr.Code)
+		}
+	}
+}
+
+func TestSendMessage_ThrottledLowersRateAndRetries(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-123"})
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                   server.URL,
+		AuthKey:               "test-auth-key",
+		TimeoutSeconds:        10,
+		RateLimitPerSecond:    10,
+		RateLimitMin:          1,
+		RateRecoverySuccesses: 20,
+		RateFastResponse:      time.Second,
+		MaxRetries:            1,
+		RetryBackoff:          time.Millisecond,
+	}
+
+	breaker := NewCircuitBreaker(1, time.Minute)
+	client := newTestWebhookClient(t, cfg, breaker)
+
+	// Act
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook-msg-123", result.MessageID)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 5.0, client.(*webhookClient).dispatcher.rateLimiter.Limit())
+	assert.Equal(t, BreakerClosed, breaker.Status().State)
+}
+
+func TestSendMessage_ThrottledPastDeadline(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+		MaxRetries:         2,
+		RetryBackoff:       time.Millisecond,
+	}
+
+	client := newTestWebhookClient(t, cfg, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Act
+	result, err := client.SendMessage(ctx, "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeThrottled, appErr.Code)
+	assert.Equal(t, 1, calls)
+}
+
+// countingQuota allows limit requests, as a Redis quota would across
+// instances.
+type countingQuota struct {
+	limit   int
+	taken   int
+	resetAt time.Time
+	err     error
+}
+
+func (q *countingQuota) Take(ctx context.Context) (bool, time.Time, error) {
+	if q.err != nil {
+		return false, time.Time{}, q.err
+	}
+	if q.taken >= q.limit {
+		return false, q.resetAt, nil
+	}
+	q.taken++
+	return true, q.resetAt, nil
+}
+
+func TestSendMessage_DailyQuota(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Accepted", MessageID: "webhook-msg-" + strconv.Itoa(calls)})
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+		MaxRetries:         2,
+		RetryBackoff:       time.Millisecond,
+	}
+	client := newTestWebhookClient(t, cfg, nil)
+	resetAt := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	client.(QuotaLimited).LimitDaily(&countingQuota{limit: 2, resetAt: resetAt})
+
+	// Act
+	_, firstErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	_, secondErr := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+	result, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Nil(t, result)
+	assert.Equal(t, 2, calls, "a request past the quota is not sent or retried")
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeQuotaExhausted, appErr.Code)
+	}
+	var exhausted *provider.QuotaExhaustedError
+	if assert.ErrorAs(t, err, &exhausted) {
+		assert.Equal(t, resetAt, exhausted.ResetAt)
+	}
+}
+
+func TestSendMessage_DailyQuotaUnavailable(t *testing.T) {
+	// Arrange
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	cfg := &config.WebhookConfig{
+		URL:                server.URL,
+		AuthKey:            "test-auth-key",
+		TimeoutSeconds:     10,
+		RateLimitPerSecond: 10,
+	}
+	client := newTestWebhookClient(t, cfg, nil)
+	client.(QuotaLimited).LimitDaily(&countingQuota{err: io.ErrUnexpectedEOF})
+
+	// Act
+	_, err := client.SendMessage(context.Background(), "+905551234567", "Test", nil)
+
+	// Assert
+	assert.Zero(t, calls, "an uncounted request is not sent")
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeRateLimit, appErr.Code)
+		assert.True(t, appErr.Code.Retryable())
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// wireRedaction replaces the value of every redacted header
+const wireRedaction = "[redacted]"
+
+// wirePhonePattern matches E.164 numbers, also with the plus form-encoded
+var wirePhonePattern = regexp.MustCompile(`(\+|%2[Bb])(\d{3,11})(\d{4})`)
+
+var (
+	wireSinksMu sync.Mutex
+	wireSinks   = make(map[string]*zap.Logger)
+)
+
+// wireSink returns the logger appending to the file at path. Every client
+// writing to the same path shares one, so their lines do not interleave.
+func wireSink(path string) (*zap.Logger, error) {
+	wireSinksMu.Lock()
+	defer wireSinksMu.Unlock()
+
+	if sink, ok := wireSinks[path]; ok {
+		return sink, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WEBHOOK_WIRE_LOG_PATH: %w", err)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	sink := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.Lock(file), zapcore.InfoLevel))
+
+	wireSinks[path] = sink
+	return sink, nil
+}
+
+// wireLogTransport writes each request that passes through it and the
+// response to the wire log, redacted as configured. The response body is
+// read in full and handed on unchanged.
+type wireLogTransport struct {
+	next          http.RoundTripper
+	sink          *zap.Logger
+	redactPhones  bool
+	redactHeaders map[string]bool
+	maxBodyBytes  int
+}
+
+func newWireLogTransport(next http.RoundTripper, cfg *config.WireLogConfig) (*wireLogTransport, error) {
+	sink, err := wireSink(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	redactHeaders := make(map[string]bool, len(cfg.RedactHeaders))
+	for _, header := range cfg.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return &wireLogTransport{
+		next:          next,
+		sink:          sink,
+		redactPhones:  cfg.RedactPhones,
+		redactHeaders: redactHeaders,
+		maxBodyBytes:  cfg.MaxBodyBytes,
+	}, nil
+}
+
+func (t *wireLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	fields := []zap.Field{
+		zap.String("request_id", req.Header.Get(requestid.Header)),
+		zap.String("method", req.Method),
+		zap.String("url", t.redact(req.URL.String())),
+		zap.Any("request_headers", t.headers(req.Header)),
+		zap.String("request_body", t.body(requestBody)),
+		zap.Int64("duration_ms", duration.Milliseconds()),
+	}
+
+	if err != nil {
+		t.sink.Info("provider request failed", append(fields, zap.String("error", t.redact(err.Error())))...)
+		return resp, err
+	}
+
+	responseBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	fields = append(fields,
+		zap.Int("status", resp.StatusCode),
+		zap.Any("response_headers", t.headers(resp.Header)),
+		zap.String("response_body", t.body(responseBody)),
+	)
+	if readErr != nil {
+		fields = append(fields, zap.String("error", readErr.Error()))
+	}
+	t.sink.Info("provider request", fields...)
+
+	return resp, readErr
+}
+
+func (t *wireLogTransport) headers(header http.Header) map[string]string {
+	logged := make(map[string]string, len(header))
+	for name, values := range header {
+		if t.redactHeaders[http.CanonicalHeaderKey(name)] {
+			logged[name] = wireRedaction
+			continue
+		}
+		logged[name] = t.redact(strings.Join(values, ", "))
+	}
+	return logged
+}
+
+func (t *wireLogTransport) body(body []byte) string {
+	if t.maxBodyBytes > 0 && len(body) > t.maxBodyBytes {
+		return t.redact(string(body[:t.maxBodyBytes])) + fmt.Sprintf("...(%d bytes)", len(body))
+	}
+	return t.redact(string(body))
+}
+
+// redact keeps the last four digits of each phone number in s, enough to
+// match a line against a complaint without logging whole numbers.
+func (t *wireLogTransport) redact(s string) string {
+	if !t.redactPhones {
+		return s
+	}
+	return wirePhonePattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := wirePhonePattern.FindStringSubmatch(match)
+		return parts[1] + strings.Repeat("*", len(parts[2])) + parts[3]
+	})
+}
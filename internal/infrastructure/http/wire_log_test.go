@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClient_WireLog(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Provider-Id", "abc")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"to":"+905551234567","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "wire.log")
+	cfg := &config.WebhookConfig{WireLog: config.WireLogConfig{
+		Enabled:       true,
+		Path:          path,
+		RedactPhones:  true,
+		RedactHeaders: []string{"x-ins-auth-key"},
+	}}
+	client, err := newHTTPClient(cfg, 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"to":"+905551234567","content":"hi"}`))
+	req.Header.Set("x-ins-auth-key", "secret")
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `{"to":"+905551234567","status":"queued"}`, string(body), "the caller still reads the whole response")
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret")
+	assert.NotContains(t, string(raw), "905551234567")
+
+	var entry struct {
+		RequestHeaders  map[string]string `json:"request_headers"`
+		RequestBody     string            `json:"request_body"`
+		Status          int               `json:"status"`
+		ResponseHeaders map[string]string `json:"response_headers"`
+		ResponseBody    string            `json:"response_body"`
+	}
+	assert.NoError(t, json.Unmarshal(raw, &entry))
+	assert.Equal(t, "[redacted]", entry.RequestHeaders["X-Ins-Auth-Key"])
+	assert.Equal(t, `{"to":"+********4567","content":"hi"}`, entry.RequestBody)
+	assert.Equal(t, http.StatusAccepted, entry.Status)
+	assert.Equal(t, "abc", entry.ResponseHeaders["X-Provider-Id"])
+	assert.Equal(t, `{"to":"+********4567","status":"queued"}`, entry.ResponseBody)
+}
+
+func TestWireLogTransport_Redact(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "json", input: `"+905551234567"`, want: `"+********4567"`},
+		{name: "form encoded", input: "To=%2B14155550123&Body=hi", want: "To=%2B*******0123&Body=hi"},
+		{name: "no plus", input: "timestamp=1700000000", want: "timestamp=1700000000"},
+	}
+
+	transport := &wireLogTransport{redactPhones: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, transport.redact(tt.input))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Record results reported by metrics.KafkaRecordsConsumed
+const (
+	resultCreated  = "created"
+	resultRejected = "rejected"
+)
+
+// MessageCreateEvent is the JSON payload expected on the intake topic.
+// ClientReference defaults to the record's topic/partition/offset, so a
+// record redelivered after a crash does not create a second message.
+type MessageCreateEvent struct {
+	Channel         string            `json:"channel,omitempty"`
+	PhoneNumber     string            `json:"phone_number,omitempty"`
+	Recipient       string            `json:"recipient,omitempty"`
+	Content         string            `json:"content"`
+	ScheduledAt     *time.Time        `json:"scheduled_at,omitempty"`
+	ExpiresAt       *time.Time        `json:"expires_at,omitempty"`
+	Priority        string            `json:"priority,omitempty"`
+	ClientReference string            `json:"client_reference,omitempty"`
+	TenantID        string            `json:"tenant_id,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+}
+
+// recordReader is the part of kafka-go's Reader the consumer uses.
+type recordReader interface {
+	FetchMessage(ctx context.Context) (kafkago.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+// Consumer creates messages from a Kafka topic. Offsets are committed only
+// after a record was stored or rejected for good; transient failures are
+// retried in place so a partition never skips ahead of an unstored record.
+type Consumer struct {
+	reader         recordReader
+	messageService service.MessageService
+	topic          string
+	retryBackoff   time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewConsumer(cfg *config.KafkaConfig, messageService service.MessageService) *Consumer {
+	reader := kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers: cfg.Brokers,
+		Topic:   cfg.Topic,
+		GroupID: cfg.GroupID,
+	})
+
+	return newConsumer(reader, messageService, cfg.Topic, cfg.RetryBackoff)
+}
+
+func newConsumer(reader recordReader, messageService service.MessageService, topic string, retryBackoff time.Duration) *Consumer {
+	return &Consumer{
+		reader:         reader,
+		messageService: messageService,
+		topic:          topic,
+		retryBackoff:   retryBackoff,
+	}
+}
+
+func (c *Consumer) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.wg.Add(1)
+	go c.run(ctx)
+
+	logger.Get().Info("kafka consumer started", zap.String("topic", c.topic))
+}
+
+// Stop lets the record in progress finish its current attempt, then closes
+// the reader so the group rebalances without waiting for a session timeout.
+func (c *Consumer) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	c.wg.Wait()
+
+	if err := c.reader.Close(); err != nil {
+		logger.Get().Error("failed to close kafka reader", zap.Error(err))
+	}
+
+	logger.Get().Info("kafka consumer stopped")
+}
+
+func (c *Consumer) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	for {
+		record, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Get().Error("failed to fetch kafka record", zap.Error(err))
+			if !c.sleep(ctx) {
+				return
+			}
+			continue
+		}
+
+		if !c.handle(ctx, record) {
+			return
+		}
+
+		if err := c.reader.CommitMessages(ctx, record); err != nil {
+			// The record will be redelivered; its client reference makes
+			// that harmless
+			logger.Get().Error("failed to commit kafka offset",
+				zap.Error(err),
+				zap.Int("partition", record.Partition),
+				zap.Int64("offset", record.Offset),
+			)
+		}
+	}
+}
+
+// handle processes record until it is stored or rejected. It returns false
+// only when ctx was cancelled first, leaving the offset uncommitted.
+func (c *Consumer) handle(ctx context.Context, record kafkago.Message) bool {
+	for {
+		err := c.process(ctx, record)
+		if err == nil {
+			metrics.KafkaRecordsConsumed.WithLabelValues(resultCreated).Inc()
+			return true
+		}
+
+		if isPermanent(err) {
+			metrics.KafkaRecordsConsumed.WithLabelValues(resultRejected).Inc()
+			logger.Get().Warn("rejected kafka record",
+				zap.Error(err),
+				zap.Int("partition", record.Partition),
+				zap.Int64("offset", record.Offset),
+			)
+			return true
+		}
+
+		logger.Get().Error("failed to create message from kafka record, retrying",
+			zap.Error(err),
+			zap.Int("partition", record.Partition),
+			zap.Int64("offset", record.Offset),
+			zap.Duration("retry_in", c.retryBackoff),
+		)
+		if !c.sleep(ctx) {
+			return false
+		}
+	}
+}
+
+func (c *Consumer) process(ctx context.Context, record kafkago.Message) error {
+	var evt MessageCreateEvent
+	if err := json.Unmarshal(record.Value, &evt); err != nil {
+		return apperrors.NewValidationError(fmt.Sprintf("invalid message event: %v", err))
+	}
+
+	if evt.TenantID != "" {
+		tenantID, err := uuid.Parse(evt.TenantID)
+		if err != nil {
+			return apperrors.NewValidationError("invalid tenant_id")
+		}
+		ctx = tenant.WithTenantID(ctx, tenantID)
+	}
+
+	ctx = actor.WithActor(ctx, actor.Kafka)
+
+	clientReference := evt.ClientReference
+	if clientReference == "" {
+		clientReference = fmt.Sprintf("kafka:%s/%d/%d", record.Topic, record.Partition, record.Offset)
+	}
+
+	resp, err := c.messageService.CreateMessage(ctx, &dto.CreateMessageRequest{
+		Channel:         evt.Channel,
+		PhoneNumber:     evt.PhoneNumber,
+		Recipient:       evt.Recipient,
+		Content:         evt.Content,
+		ScheduledAt:     evt.ScheduledAt,
+		ExpiresAt:       evt.ExpiresAt,
+		Priority:        evt.Priority,
+		ClientReference: clientReference,
+		Metadata:        evt.Metadata,
+		DryRun:          evt.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Get().Debug("created message from kafka record",
+		zap.String("message_id", resp.ID),
+		zap.Int("partition", record.Partition),
+		zap.Int64("offset", record.Offset),
+	)
+	return nil
+}
+
+// isPermanent reports whether the record should be dropped instead of
+// retried. Recipient limits count as permanent so one busy number cannot
+// stall the partition for the length of its window.
+func isPermanent(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	if !ok {
+		return false
+	}
+	switch appErr.Code {
+	case apperrors.ErrorCodeValidation, apperrors.ErrorCodeConflict,
+		apperrors.ErrorCodeRecipientRateLimited, apperrors.ErrorCodeDuplicateContent,
+		apperrors.ErrorCodeDestinationBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits out the retry backoff, returning false if ctx ends first.
+func (c *Consumer) sleep(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(c.retryBackoff):
+		return true
+	}
+}
@@ -0,0 +1,184 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReader struct {
+	records chan kafkago.Message
+
+	mu        sync.Mutex
+	committed []int64
+	closed    bool
+}
+
+func newFakeReader(records ...kafkago.Message) *fakeReader {
+	r := &fakeReader{records: make(chan kafkago.Message, len(records))}
+	for _, record := range records {
+		r.records <- record
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	select {
+	case <-ctx.Done():
+		return kafkago.Message{}, ctx.Err()
+	case record := <-r.records:
+		return record, nil
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) committedOffsets() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// fakeMessageService only implements CreateMessage; errs are returned by
+// successive calls before it starts succeeding.
+type fakeMessageService struct {
+	service.MessageService
+
+	mu       sync.Mutex
+	errs     []error
+	requests []*dto.CreateMessageRequest
+	tenants  []uuid.UUID
+}
+
+func (s *fakeMessageService) CreateMessage(ctx context.Context, req *dto.CreateMessageRequest) (*dto.MessageResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	s.requests = append(s.requests, req)
+	s.tenants = append(s.tenants, tenantID)
+	return &dto.MessageResponse{ID: uuid.New().String()}, nil
+}
+
+func (s *fakeMessageService) created() []*dto.CreateMessageRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*dto.CreateMessageRequest(nil), s.requests...)
+}
+
+func record(offset int64, value string) kafkago.Message {
+	return kafkago.Message{Topic: "messages.create", Partition: 0, Offset: offset, Value: []byte(value)}
+}
+
+func TestConsumer_CreatesMessageAndCommits(t *testing.T) {
+	// Arrange
+	tenantID := uuid.New()
+	reader := newFakeReader(
+		record(7, `{"phone_number": "+905551234567", "content": "Hello"}`),
+		record(8, `{"phone_number": "+905551234567", "content": "Hi", "client_reference": "order-1", "tenant_id": "`+tenantID.String()+`"}`),
+	)
+	svc := &fakeMessageService{}
+	consumer := newConsumer(reader, svc, "messages.create", time.Millisecond)
+
+	// Act
+	consumer.Start(context.Background())
+	assert.Eventually(t, func() bool { return len(reader.committedOffsets()) == 2 }, time.Second, time.Millisecond)
+	consumer.Stop()
+
+	// Assert
+	created := svc.created()
+	assert.Len(t, created, 2)
+	assert.Equal(t, "kafka:messages.create/0/7", created[0].ClientReference)
+	assert.Equal(t, "order-1", created[1].ClientReference)
+	assert.Equal(t, uuid.Nil, svc.tenants[0])
+	assert.Equal(t, tenantID, svc.tenants[1])
+	assert.Equal(t, []int64{7, 8}, reader.committedOffsets())
+	assert.True(t, reader.closed)
+}
+
+func TestConsumer_SkipsPermanentlyInvalidRecords(t *testing.T) {
+	// Arrange
+	reader := newFakeReader(
+		record(1, `not json`),
+		record(2, `{"phone_number": "+905551234567", "content": "Hi", "tenant_id": "nope"}`),
+		record(3, `{"phone_number": "invalid", "content": "Hi"}`),
+	)
+	svc := &fakeMessageService{errs: []error{apperrors.NewValidationError("invalid phone number")}}
+	consumer := newConsumer(reader, svc, "messages.create", time.Millisecond)
+
+	// Act
+	consumer.Start(context.Background())
+	assert.Eventually(t, func() bool { return len(reader.committedOffsets()) == 3 }, time.Second, time.Millisecond)
+	consumer.Stop()
+
+	// Assert
+	assert.Empty(t, svc.created())
+	assert.Equal(t, []int64{1, 2, 3}, reader.committedOffsets())
+}
+
+func TestConsumer_RetriesTransientFailuresBeforeCommitting(t *testing.T) {
+	// Arrange
+	reader := newFakeReader(record(5, `{"phone_number": "+905551234567", "content": "Hello"}`))
+	svc := &fakeMessageService{errs: []error{
+		apperrors.NewDatabaseError(assert.AnError),
+		apperrors.NewDatabaseError(assert.AnError),
+	}}
+	consumer := newConsumer(reader, svc, "messages.create", time.Millisecond)
+
+	// Act
+	consumer.Start(context.Background())
+	assert.Eventually(t, func() bool { return len(reader.committedOffsets()) == 1 }, time.Second, time.Millisecond)
+	consumer.Stop()
+
+	// Assert
+	assert.Len(t, svc.created(), 1)
+}
+
+func TestConsumer_StopLeavesFailingRecordUncommitted(t *testing.T) {
+	// Arrange
+	reader := newFakeReader(record(5, `{"phone_number": "+905551234567", "content": "Hello"}`))
+	errs := make([]error, 1000)
+	for i := range errs {
+		errs[i] = apperrors.NewDatabaseError(assert.AnError)
+	}
+	svc := &fakeMessageService{errs: errs}
+	consumer := newConsumer(reader, svc, "messages.create", time.Millisecond)
+
+	// Act
+	consumer.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	consumer.Stop()
+
+	// Assert
+	assert.Empty(t, reader.committedOffsets())
+	assert.Empty(t, svc.created())
+}
@@ -0,0 +1,146 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Lock is a lease on a shared key that expires unless its holder renews it.
+type Lock interface {
+	TryAcquire(ctx context.Context, holderID string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, holderID string) error
+}
+
+// Elector keeps one instance of a deployment dispatching. Every instance
+// tries to take or renew the lock each renew interval; the holder dispatches
+// and the others wait. When the holder dies its lock expires and the next
+// instance to try takes over. Like the failover elector, leadership is
+// considered lost locally one renew interval before the lock expires, so a
+// holder that cannot reach Redis goes quiet before anyone else can take
+// over.
+type Elector struct {
+	lock          Lock
+	holderID      string
+	ttl           time.Duration
+	renewInterval time.Duration
+
+	mu         sync.RWMutex
+	leader     bool
+	validUntil time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewElector(lock Lock, cfg *config.SchedulerLockConfig) *Elector {
+	hostname, _ := os.Hostname()
+
+	return &Elector{
+		lock:          lock,
+		holderID:      fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		ttl:           cfg.TTL,
+		renewInterval: cfg.RenewInterval,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+func (e *Elector) Start(ctx context.Context) {
+	logger.Get().Info("starting scheduler leader election",
+		zap.String("holder_id", e.holderID),
+		zap.Duration("lock_ttl", e.ttl),
+	)
+
+	e.tick(ctx)
+
+	e.wg.Add(1)
+	go e.run(ctx)
+}
+
+// Stop releases the lock when this instance holds it, so another instance
+// takes over on its next renewal instead of waiting for the lock to expire.
+func (e *Elector) Stop() {
+	close(e.stopChan)
+	e.wg.Wait()
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.mu.Unlock()
+
+	if wasLeader {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := e.lock.Release(ctx, e.holderID); err != nil {
+			logger.Get().Warn("failed to release scheduler leader lock", zap.Error(err))
+		}
+	}
+}
+
+// IsLeader reports whether this instance may dispatch right now.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader && time.Now().Before(e.validUntil)
+}
+
+func (e *Elector) HolderID() string {
+	return e.holderID
+}
+
+func (e *Elector) run(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.tick(ctx)
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	attemptedAt := time.Now()
+
+	tickCtx, cancel := context.WithTimeout(ctx, e.renewInterval)
+	defer cancel()
+
+	acquired, err := e.lock.TryAcquire(tickCtx, e.holderID, e.ttl)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		// Keep the current validUntil: leadership lapses on its own if Redis
+		// stays unreachable
+		logger.Get().Error("scheduler leader lock renewal failed", zap.Error(err))
+		return
+	}
+
+	wasLeader := e.leader
+	e.leader = acquired
+	if acquired {
+		e.validUntil = attemptedAt.Add(e.ttl - e.renewInterval)
+	}
+
+	switch {
+	case acquired && !wasLeader:
+		logger.Get().Info("became scheduler leader", zap.String("holder_id", e.holderID))
+	case !acquired && wasLeader:
+		logger.Get().Warn("lost scheduler leadership", zap.String("holder_id", e.holderID))
+	}
+}
@@ -0,0 +1,121 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryLock behaves like the Redis lock with a clock the test controls.
+type memoryLock struct {
+	mu        sync.Mutex
+	holder    string
+	expiresAt time.Time
+	now       time.Time
+	err       error
+}
+
+func (l *memoryLock) TryAcquire(_ context.Context, holderID string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.holder == "" || l.holder == holderID || !l.now.Before(l.expiresAt) {
+		l.holder = holderID
+		l.expiresAt = l.now.Add(ttl)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (l *memoryLock) Release(_ context.Context, holderID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder == holderID {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (l *memoryLock) advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+func newTestElector(lock Lock) *Elector {
+	return NewElector(lock, &config.SchedulerLockConfig{
+		Enabled:       true,
+		Key:           "scheduler:leader",
+		TTL:           15 * time.Second,
+		RenewInterval: 5 * time.Second,
+	})
+}
+
+func TestElector_OnlyOneInstanceLeads(t *testing.T) {
+	lock := &memoryLock{now: time.Now()}
+	first := newTestElector(lock)
+	second := newTestElector(lock)
+
+	first.tick(context.Background())
+	second.tick(context.Background())
+
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+}
+
+func TestElector_TakesOverWhenLeaderDies(t *testing.T) {
+	lock := &memoryLock{now: time.Now()}
+	first := newTestElector(lock)
+	second := newTestElector(lock)
+	first.tick(context.Background())
+
+	// The leader stops renewing; its lock expires after the TTL
+	lock.advance(10 * time.Second)
+	second.tick(context.Background())
+	assert.False(t, second.IsLeader())
+
+	lock.advance(5 * time.Second)
+	second.tick(context.Background())
+	assert.True(t, second.IsLeader())
+}
+
+func TestElector_LeadershipLapsesWhenRenewalFails(t *testing.T) {
+	lock := &memoryLock{now: time.Now()}
+	elector := newTestElector(lock)
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	// A failed renewal keeps the existing validity window
+	lock.err = errors.New("connection refused")
+	elector.tick(context.Background())
+	assert.True(t, elector.IsLeader())
+
+	elector.mu.Lock()
+	elector.validUntil = time.Now().Add(-time.Second)
+	elector.mu.Unlock()
+
+	assert.False(t, elector.IsLeader())
+}
+
+func TestElector_StopHandsOverImmediately(t *testing.T) {
+	lock := &memoryLock{now: time.Now()}
+	first := newTestElector(lock)
+	second := newTestElector(lock)
+
+	first.Start(context.Background())
+	second.tick(context.Background())
+	assert.False(t, second.IsLeader())
+
+	first.Stop()
+	second.tick(context.Background())
+
+	assert.False(t, first.IsLeader())
+	assert.True(t, second.IsLeader())
+}
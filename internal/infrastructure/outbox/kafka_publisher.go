@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+type kafkaPublisher struct {
+	writer *kafkago.Writer
+}
+
+// NewKafkaPublisher keys records by message ID so all events of a message
+// land on the same partition in order.
+func NewKafkaPublisher(brokers []string, topic string) Publisher {
+	return &kafkaPublisher{
+		writer: &kafkago.Writer{
+			Addr:         kafkago.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafkago.Hash{},
+			RequiredAcks: kafkago.RequireAll,
+		},
+	}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, evt *repository.OutboxEvent) error {
+	return p.writer.WriteMessages(ctx, kafkago.Message{
+		Key:   []byte(evt.AggregateID.String()),
+		Value: evt.Payload,
+		Headers: []kafkago.Header{
+			{Key: "event_id", Value: []byte(evt.ID.String())},
+			{Key: "event_type", Value: []byte(evt.Type)},
+		},
+	})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
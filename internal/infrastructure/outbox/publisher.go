@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+)
+
+// Publisher delivers one outbox event to the broker. Publish must only
+// return nil once the broker has accepted the event.
+type Publisher interface {
+	Publish(ctx context.Context, evt *repository.OutboxEvent) error
+	Close() error
+}
+
+// NewPublisher builds the publisher for cfg.Broker.
+func NewPublisher(cfg *config.OutboxConfig, kafkaCfg *config.KafkaConfig, redisCache *cache.RedisCache) (Publisher, error) {
+	switch cfg.Broker {
+	case "kafka":
+		return NewKafkaPublisher(kafkaCfg.Brokers, cfg.KafkaTopic), nil
+	case "rabbitmq":
+		return NewRabbitMQPublisher(cfg.RabbitMQURL, cfg.RabbitMQExchange)
+	case "redis":
+		return NewRedisStreamPublisher(redisCache, cfg.RedisStream, cfg.RedisStreamMaxLen), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox broker %q", cfg.Broker)
+	}
+}
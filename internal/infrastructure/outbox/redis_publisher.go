@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+)
+
+type redisStreamPublisher struct {
+	cache  *cache.RedisCache
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamPublisher appends events to a Redis stream on the cache
+// connection. The stream is trimmed to roughly maxLen entries.
+func NewRedisStreamPublisher(redisCache *cache.RedisCache, stream string, maxLen int64) Publisher {
+	return &redisStreamPublisher{
+		cache:  redisCache,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (p *redisStreamPublisher) Publish(ctx context.Context, evt *repository.OutboxEvent) error {
+	_, err := p.cache.XAdd(ctx, p.stream, p.maxLen, map[string]interface{}{
+		"event_id":   evt.ID.String(),
+		"event_type": evt.Type,
+		"message_id": evt.AggregateID.String(),
+		"payload":    string(evt.Payload),
+	})
+	return err
+}
+
+// Close is a no-op; the cache owns the connection.
+func (p *redisStreamPublisher) Close() error {
+	return nil
+}
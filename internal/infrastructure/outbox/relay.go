@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// pruneInterval is how often published events past the retention are
+	// deleted
+	pruneInterval = 10 * time.Minute
+
+	// finalDrainTimeout bounds the last drain on shutdown
+	finalDrainTimeout = 5 * time.Second
+)
+
+// Relay drains the outbox table to the broker. Delivery is at least once: an
+// event the broker accepted is published again if marking it fails, so
+// consumers should deduplicate on event_id.
+type Relay struct {
+	repo      repository.OutboxRepository
+	publisher Publisher
+	batchSize int
+	interval  time.Duration
+	retention time.Duration
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, cfg *config.OutboxConfig) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		batchSize: cfg.BatchSize,
+		interval:  cfg.PollInterval,
+		retention: cfg.Retention,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+
+	logger.Get().Info("outbox relay started",
+		zap.Int("batch_size", r.batchSize),
+		zap.Duration("poll_interval", r.interval),
+	)
+}
+
+// Stop waits for the current drain, then publishes whatever the last
+// processing cycle left behind before closing the publisher.
+func (r *Relay) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), finalDrainTimeout)
+	defer cancel()
+	r.drain(ctx)
+
+	if err := r.publisher.Close(); err != nil {
+		logger.Get().Error("failed to close outbox publisher", zap.Error(err))
+	}
+
+	logger.Get().Info("outbox relay stopped")
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	lastPrune := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			r.drain(ctx)
+
+			if r.retention > 0 && time.Since(lastPrune) >= pruneInterval {
+				r.prune(ctx)
+				lastPrune = time.Now()
+			}
+		}
+	}
+}
+
+// drain relays full batches back to back until the outbox is empty or a
+// publish fails.
+func (r *Relay) drain(ctx context.Context) {
+	for {
+		published, err := r.repo.Relay(ctx, r.batchSize, r.publish)
+		if err != nil || published < r.batchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, evt *repository.OutboxEvent) error {
+	if err := r.publisher.Publish(ctx, evt); err != nil {
+		return err
+	}
+	metrics.OutboxEventsPublished.WithLabelValues(evt.Type).Inc()
+	return nil
+}
+
+func (r *Relay) prune(ctx context.Context) {
+	deleted, err := r.repo.DeletePublishedBefore(ctx, time.Now().UTC().Add(-r.retention))
+	if err != nil {
+		return
+	}
+	if deleted > 0 {
+		logger.Get().Info("pruned published outbox events", zap.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOutboxRepository mimics the database relay: oldest first, stopping at
+// the first publish failure.
+type fakeOutboxRepository struct {
+	mu      sync.Mutex
+	pending []*repository.OutboxEvent
+	calls   int
+}
+
+func (r *fakeOutboxRepository) Relay(ctx context.Context, limit int, publish func(ctx context.Context, evt *repository.OutboxEvent) error) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+
+	published := 0
+	for published < limit && published < len(r.pending) {
+		if err := publish(ctx, r.pending[published]); err != nil {
+			break
+		}
+		published++
+	}
+	r.pending = r.pending[published:]
+	return published, nil
+}
+
+func (r *fakeOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeOutboxRepository) remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	failUntil int
+	attempts  int
+	published []string
+	closed    bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, evt *repository.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+	if p.attempts <= p.failUntil {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, evt.Type)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func (p *fakePublisher) publishedTypes() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+func newEvents(types ...string) []*repository.OutboxEvent {
+	messageID := uuid.New()
+	events := make([]*repository.OutboxEvent, 0, len(types))
+	for _, eventType := range types {
+		events = append(events, &repository.OutboxEvent{ID: uuid.New(), AggregateID: messageID, Type: eventType})
+	}
+	return events
+}
+
+func TestRelay_DrainsFullBatchesInOrder(t *testing.T) {
+	// Arrange
+	repo := &fakeOutboxRepository{pending: newEvents("message.created", "message.failed", "message.sent")}
+	publisher := &fakePublisher{}
+	relay := NewRelay(repo, publisher, &config.OutboxConfig{BatchSize: 2, PollInterval: time.Millisecond})
+
+	// Act
+	relay.Start(context.Background())
+	assert.Eventually(t, func() bool { return repo.remaining() == 0 }, time.Second, time.Millisecond)
+	relay.Stop()
+
+	// Assert
+	assert.Equal(t, []string{"message.created", "message.failed", "message.sent"}, publisher.publishedTypes())
+	assert.True(t, publisher.closed)
+}
+
+func TestRelay_RetriesAfterPublishFailure(t *testing.T) {
+	// Arrange
+	repo := &fakeOutboxRepository{pending: newEvents("message.created", "message.sent")}
+	publisher := &fakePublisher{failUntil: 2}
+	relay := NewRelay(repo, publisher, &config.OutboxConfig{BatchSize: 10, PollInterval: time.Millisecond})
+
+	// Act
+	relay.Start(context.Background())
+	assert.Eventually(t, func() bool { return repo.remaining() == 0 }, time.Second, time.Millisecond)
+	relay.Stop()
+
+	// Assert
+	assert.Equal(t, []string{"message.created", "message.sent"}, publisher.publishedTypes())
+}
+
+func TestRelay_StopDrainsRemainingEvents(t *testing.T) {
+	// Arrange
+	repo := &fakeOutboxRepository{}
+	publisher := &fakePublisher{}
+	relay := NewRelay(repo, publisher, &config.OutboxConfig{BatchSize: 10, PollInterval: time.Hour})
+	relay.Start(context.Background())
+
+	repo.mu.Lock()
+	repo.pending = newEvents("message.sent")
+	repo.mu.Unlock()
+
+	// Act
+	relay.Stop()
+
+	// Assert
+	assert.Equal(t, []string{"message.sent"}, publisher.publishedTypes())
+}
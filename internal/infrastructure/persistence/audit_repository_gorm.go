@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type auditRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewAuditRepositoryGorm(db *gorm.DB) repository.AuditRepository {
+	return &auditRepositoryGorm{db: db}
+}
+
+func (r *auditRepositoryGorm) Add(ctx context.Context, entry *repository.AuditEntry) error {
+	if err := r.db.WithContext(ctx).Create(model.ToAuditLogModel(entry)).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to add audit log entry",
+			zap.Error(err),
+			zap.String("action", entry.Action),
+			zap.String("resource_id", entry.ResourceID),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *auditRepositoryGorm) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*repository.AuditEntry, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.AuditLogModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"))
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.After != nil {
+		query = query.Where("occurred_at >= ?", *filter.After)
+	}
+	if filter.Before != nil {
+		query = query.Where("occurred_at < ?", *filter.Before)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count audit log entries", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.AuditLogModel
+	result := query.
+		Order("occurred_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list audit log entries", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	entries := make([]*repository.AuditEntry, len(models))
+	for i := range models {
+		entries[i] = models[i].ToAuditEntry()
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,70 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepositoryGorm_ListFiltersNewestFirst(t *testing.T) {
+	// Arrange
+	repo := persistence.NewAuditRepositoryGorm(newTestDB(t))
+	ctx := context.Background()
+	messageID := uuid.New().String()
+	now := time.Now().UTC()
+
+	entries := []*repository.AuditEntry{
+		{Action: "message.created", ResourceType: "message", ResourceID: messageID, Actor: "api_token", ToStatus: "pending", OccurredAt: now.Add(-2 * time.Minute)},
+		{Action: "message.cancelled", ResourceType: "message", ResourceID: messageID, Actor: "api_token", RequestID: "req-1", FromStatus: "pending", ToStatus: "cancelled", OccurredAt: now.Add(-time.Minute)},
+		{Action: "scheduler.started", ResourceType: "scheduler", Actor: "admin_token", OccurredAt: now},
+	}
+	for _, entry := range entries {
+		entry.ID = uuid.New()
+		assert.NoError(t, repo.Add(ctx, entry))
+	}
+
+	// Act
+	byMessage, total, err := repo.List(ctx, repository.AuditFilter{ResourceType: "message", ResourceID: messageID}, 10, 0)
+	since, sinceTotal, sinceErr := repo.List(ctx, repository.AuditFilter{After: &entries[1].OccurredAt}, 10, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, byMessage, 2) {
+		assert.Equal(t, "message.cancelled", byMessage[0].Action)
+		assert.Equal(t, "req-1", byMessage[0].RequestID)
+		assert.Equal(t, "pending", byMessage[0].FromStatus)
+		assert.Equal(t, "message.created", byMessage[1].Action)
+		assert.Empty(t, byMessage[1].FromStatus)
+	}
+
+	assert.NoError(t, sinceErr)
+	assert.Equal(t, int64(2), sinceTotal)
+	assert.Len(t, since, 2)
+}
+
+func TestAuditRepositoryGorm_ListIsTenantScoped(t *testing.T) {
+	// Arrange
+	repo := persistence.NewAuditRepositoryGorm(newTestDB(t))
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	assert.NoError(t, repo.Add(ctx, &repository.AuditEntry{ID: uuid.New(), Action: "message.created", ResourceType: "message", TenantID: tenantID, Actor: "tenant:" + tenantID.String(), OccurredAt: time.Now().UTC()}))
+	assert.NoError(t, repo.Add(ctx, &repository.AuditEntry{ID: uuid.New(), Action: "scheduler.stopped", ResourceType: "scheduler", Actor: "admin_token", OccurredAt: time.Now().UTC()}))
+
+	// Act
+	scoped, total, err := repo.List(tenant.WithTenantID(ctx, tenantID), repository.AuditFilter{}, 10, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, scoped, 1) {
+		assert.Equal(t, tenantID, scoped[0].TenantID)
+	}
+}
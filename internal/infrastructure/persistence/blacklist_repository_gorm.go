@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type blacklistRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewBlacklistRepositoryGorm(db *gorm.DB) repository.BlacklistRepository {
+	return &blacklistRepositoryGorm{db: db}
+}
+
+func (r *blacklistRepositoryGorm) Create(ctx context.Context, entry *repository.BlacklistEntry) error {
+	result := r.db.WithContext(ctx).Create(model.ToBlacklistModel(entry))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create blacklist entry",
+			zap.Error(result.Error),
+			zap.String("entry_id", entry.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+// Delete removes the entry and returns it, so the caller knows which number
+// to drop from the cache.
+func (r *blacklistRepositoryGorm) Delete(ctx context.Context, id uuid.UUID) (*repository.BlacklistEntry, error) {
+	entry, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Delete(&model.BlacklistModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to delete blacklist entry",
+			zap.Error(result.Error),
+			zap.String("entry_id", id.String()),
+		)
+		return nil, mapGormError(result.Error)
+	}
+	if err := checkRowsAffected(result, 1); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *blacklistRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.BlacklistEntry, error) {
+	var entryModel model.BlacklistModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		First(&entryModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find blacklist entry by ID",
+				zap.Error(result.Error),
+				zap.String("entry_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return entryModel.ToBlacklistEntry(), nil
+}
+
+func (r *blacklistRepositoryGorm) List(ctx context.Context, phoneNumber string, limit, offset int) ([]*repository.BlacklistEntry, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.BlacklistModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"))
+	if phoneNumber != "" {
+		query = query.Where("phone_number = ?", phoneNumber)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count blacklist entries", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.BlacklistModel
+	result := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list blacklist entries", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	entries := make([]*repository.BlacklistEntry, len(models))
+	for i := range models {
+		entries[i] = models[i].ToBlacklistEntry()
+	}
+
+	return entries, total, nil
+}
+
+func (r *blacklistRepositoryGorm) FindTenantsBlocking(ctx context.Context, phoneNumber string) ([]uuid.UUID, error) {
+	var models []model.BlacklistModel
+
+	result := r.db.WithContext(ctx).
+		Select("tenant_id").
+		Where("phone_number = ?", phoneNumber).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to look up blacklist", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	tenants := make([]uuid.UUID, len(models))
+	for i := range models {
+		tenants[i] = models[i].ToBlacklistEntry().TenantID
+	}
+	return tenants, nil
+}
@@ -0,0 +1,95 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBlacklistEntry(phoneNumber string, tenantID uuid.UUID) *repository.BlacklistEntry {
+	return &repository.BlacklistEntry{
+		ID:          uuid.New(),
+		PhoneNumber: phoneNumber,
+		TenantID:    tenantID,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+func TestBlacklistRepositoryGorm_GlobalAndTenantEntries(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewBlacklistRepositoryGorm(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	tenantID := uuid.New()
+	assert.NoError(t, persistence.NewTenantRepositoryGorm(db).Create(ctx, &repository.Tenant{
+		ID:         tenantID,
+		Name:       "acme",
+		APIKeyHash: "hash",
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}))
+
+	global := newTestBlacklistEntry("+905551234567", uuid.Nil)
+	scoped := newTestBlacklistEntry("+905551234567", tenantID)
+	assert.NoError(t, repo.Create(ctx, global))
+	assert.NoError(t, repo.Create(ctx, scoped))
+
+	// Act
+	duplicateGlobalErr := repo.Create(ctx, newTestBlacklistEntry("+905551234567", uuid.Nil))
+	duplicateTenantErr := repo.Create(ctx, newTestBlacklistEntry("+905551234567", tenantID))
+	blocking, findErr := repo.FindTenantsBlocking(ctx, "+905551234567")
+	unlisted, unlistedErr := repo.FindTenantsBlocking(ctx, "+905551234568")
+	tenantEntries, tenantTotal, listErr := repo.List(tenant.WithTenantID(ctx, tenantID), "", 10, 0)
+
+	// Assert
+	for _, err := range []error{duplicateGlobalErr, duplicateTenantErr} {
+		appErr, ok := err.(*apperrors.AppError)
+		if assert.True(t, ok) {
+			assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+		}
+	}
+	assert.NoError(t, findErr)
+	assert.ElementsMatch(t, []uuid.UUID{uuid.Nil, tenantID}, blocking)
+	assert.NoError(t, unlistedErr)
+	assert.Empty(t, unlisted)
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(1), tenantTotal)
+	if assert.Len(t, tenantEntries, 1) {
+		assert.Equal(t, scoped.ID, tenantEntries[0].ID)
+	}
+}
+
+func TestBlacklistRepositoryGorm_DeleteIsTenantScoped(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewBlacklistRepositoryGorm(db)
+	ctx := context.Background()
+
+	entry := newTestBlacklistEntry("+905551234567", uuid.Nil)
+	assert.NoError(t, repo.Create(ctx, entry))
+
+	// Act
+	_, tenantErr := repo.Delete(tenant.WithTenantID(ctx, uuid.New()), entry.ID)
+	deleted, deleteErr := repo.Delete(ctx, entry.ID)
+	blocking, findErr := repo.FindTenantsBlocking(ctx, "+905551234567")
+
+	// Assert
+	appErr, ok := tenantErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+	}
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, "+905551234567", deleted.PhoneNumber)
+	assert.NoError(t, findErr)
+	assert.Empty(t, blocking)
+}
@@ -0,0 +1,109 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// activeCampaignCondition keeps messages of paused campaigns out of the
+// dispatch queue; they stay pending until the campaign is resumed.
+const activeCampaignCondition = "(campaign_id IS NULL OR campaign_id NOT IN (SELECT id FROM campaigns WHERE status = 'paused'))"
+
+type campaignRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepositoryGorm(db *gorm.DB) repository.CampaignRepository {
+	return &campaignRepositoryGorm{db: db}
+}
+
+func (r *campaignRepositoryGorm) Create(ctx context.Context, campaign *repository.Campaign) error {
+	result := r.db.WithContext(ctx).Create(model.ToCampaignModel(campaign))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create campaign",
+			zap.Error(result.Error),
+			zap.String("campaign_id", campaign.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *campaignRepositoryGorm) Update(ctx context.Context, campaign *repository.Campaign) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.CampaignModel{}).
+		Where("id = ?", campaign.ID).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select("name", "status", "updated_at").
+		Updates(model.ToCampaignModel(campaign))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to update campaign",
+			zap.Error(result.Error),
+			zap.String("campaign_id", campaign.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *campaignRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.Campaign, error) {
+	var campaignModel model.CampaignModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		First(&campaignModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find campaign by ID",
+				zap.Error(result.Error),
+				zap.String("campaign_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return campaignModel.ToCampaign(), nil
+}
+
+func (r *campaignRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.Campaign, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.CampaignModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count campaigns", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.CampaignModel
+	result := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list campaigns", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	campaigns := make([]*repository.Campaign, len(models))
+	for i := range models {
+		campaigns[i] = models[i].ToCampaign()
+	}
+
+	return campaigns, total, nil
+}
@@ -0,0 +1,68 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaignRepositoryGorm_PausedCampaignIsNotDispatched(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	campaigns := persistence.NewCampaignRepositoryGorm(db)
+	messages := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	campaign := &repository.Campaign{
+		ID:        uuid.New(),
+		Name:      "launch",
+		Status:    repository.CampaignStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	assert.NoError(t, campaigns.Create(ctx, campaign))
+
+	first := newTestMessage(t, "first")
+	first.AssignCampaign(campaign.ID)
+	second := newTestMessage(t, "second")
+	second.AssignCampaign(campaign.ID)
+	standalone := newTestMessage(t, "standalone")
+	for _, message := range []*entity.Message{first, second, standalone} {
+		assert.NoError(t, messages.Create(ctx, message))
+	}
+
+	campaign.Status = repository.CampaignStatusPaused
+	assert.NoError(t, campaigns.Update(ctx, campaign))
+
+	// Act
+	whilePaused, pausedErr := messages.ClaimPendingMessages(ctx, 10)
+	stats, statsErr := messages.GetCampaignStats(ctx, campaign.ID)
+	listed, total, listErr := messages.FindByFilter(ctx, repository.MessageFilter{CampaignID: campaign.ID}, 10, 0)
+
+	campaign.Status = repository.CampaignStatusActive
+	assert.NoError(t, campaigns.Update(ctx, campaign))
+	afterResume, resumeErr := messages.ClaimPendingMessages(ctx, 10)
+
+	// Assert
+	assert.NoError(t, pausedErr)
+	if assert.Len(t, whilePaused, 1) {
+		assert.Equal(t, standalone.ID(), whilePaused[0].ID())
+	}
+	assert.NoError(t, statsErr)
+	assert.Equal(t, int64(2), stats.TotalMessages)
+	assert.Equal(t, int64(2), stats.PendingMessages)
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(2), total)
+	for _, message := range listed {
+		assert.Equal(t, campaign.ID, message.CampaignID())
+	}
+	assert.NoError(t, resumeErr)
+	assert.Len(t, afterResume, 2)
+}
@@ -0,0 +1,372 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type contactRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewContactRepositoryGorm(db *gorm.DB) repository.ContactRepository {
+	return &contactRepositoryGorm{db: db}
+}
+
+func (r *contactRepositoryGorm) Create(ctx context.Context, contact *repository.Contact) error {
+	result := r.db.WithContext(ctx).Create(model.ToContactModel(contact))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create contact",
+			zap.Error(result.Error),
+			zap.String("contact_id", contact.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *contactRepositoryGorm) Update(ctx context.Context, contact *repository.Contact) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.ContactModel{}).
+		Where("id = ?", contact.ID).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select("name", "phone_number", "email", "device_token", "updated_at").
+		Updates(model.ToContactModel(contact))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to update contact",
+			zap.Error(result.Error),
+			zap.String("contact_id", contact.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *contactRepositoryGorm) Delete(ctx context.Context, id uuid.UUID) error {
+	// Memberships are removed here as well, as SQLite does not enforce the
+	// foreign keys that cascade the delete on Postgres.
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).
+			Scopes(tenantScope(ctx, "tenant_id")).
+			Delete(&model.ContactModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := checkRowsAffected(result, 1); err != nil {
+			return err
+		}
+		return tx.Where("contact_id = ?", id).Delete(&model.ContactGroupMemberModel{}).Error
+	})
+
+	if err != nil {
+		if _, ok := err.(*apperrors.AppError); ok {
+			return err
+		}
+		logger.FromContext(ctx).Error("failed to delete contact",
+			zap.Error(err),
+			zap.String("contact_id", id.String()),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *contactRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.Contact, error) {
+	var contactModel model.ContactModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		First(&contactModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find contact by ID",
+				zap.Error(result.Error),
+				zap.String("contact_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return contactModel.ToContact(), nil
+}
+
+func (r *contactRepositoryGorm) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*repository.Contact, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var models []model.ContactModel
+	result := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to find contacts by ID", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	return toContacts(models), nil
+}
+
+func (r *contactRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.Contact, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.ContactModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count contacts", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.ContactModel
+	result := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list contacts", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	return toContacts(models), total, nil
+}
+
+type contactGroupRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewContactGroupRepositoryGorm(db *gorm.DB) repository.ContactGroupRepository {
+	return &contactGroupRepositoryGorm{db: db}
+}
+
+// contactGroupRow is a group read together with its member count.
+type contactGroupRow struct {
+	model.ContactGroupModel
+	MemberCount int64
+}
+
+const contactGroupColumns = "contact_groups.*, (SELECT COUNT(*) FROM contact_group_members WHERE contact_group_members.group_id = contact_groups.id) AS member_count"
+
+func (r *contactGroupRepositoryGorm) Create(ctx context.Context, group *repository.ContactGroup) error {
+	result := r.db.WithContext(ctx).Create(model.ToContactGroupModel(group))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create contact group",
+			zap.Error(result.Error),
+			zap.String("group_id", group.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *contactGroupRepositoryGorm) Update(ctx context.Context, group *repository.ContactGroup) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.ContactGroupModel{}).
+		Where("id = ?", group.ID).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select("name", "updated_at").
+		Updates(model.ToContactGroupModel(group))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to update contact group",
+			zap.Error(result.Error),
+			zap.String("group_id", group.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *contactGroupRepositoryGorm) Delete(ctx context.Context, id uuid.UUID) error {
+	// Memberships are removed here as well, as SQLite does not enforce the
+	// foreign keys that cascade the delete on Postgres.
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).
+			Scopes(tenantScope(ctx, "tenant_id")).
+			Delete(&model.ContactGroupModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := checkRowsAffected(result, 1); err != nil {
+			return err
+		}
+		return tx.Where("group_id = ?", id).Delete(&model.ContactGroupMemberModel{}).Error
+	})
+
+	if err != nil {
+		if _, ok := err.(*apperrors.AppError); ok {
+			return err
+		}
+		logger.FromContext(ctx).Error("failed to delete contact group",
+			zap.Error(err),
+			zap.String("group_id", id.String()),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *contactGroupRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.ContactGroup, error) {
+	var row contactGroupRow
+
+	result := r.db.WithContext(ctx).
+		Model(&model.ContactGroupModel{}).
+		Select(contactGroupColumns).
+		Where("contact_groups.id = ?", id).
+		Scopes(tenantScope(ctx, "contact_groups.tenant_id")).
+		Take(&row)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find contact group by ID",
+				zap.Error(result.Error),
+				zap.String("group_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return row.toContactGroup(), nil
+}
+
+func (r *contactGroupRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.ContactGroup, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.ContactGroupModel{}).
+		Scopes(tenantScope(ctx, "contact_groups.tenant_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count contact groups", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var rows []contactGroupRow
+	result := query.
+		Select(contactGroupColumns).
+		Order("contact_groups.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&rows)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list contact groups", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	groups := make([]*repository.ContactGroup, len(rows))
+	for i := range rows {
+		groups[i] = rows[i].toContactGroup()
+	}
+
+	return groups, total, nil
+}
+
+func (r *contactGroupRepositoryGorm) AddMembers(ctx context.Context, groupID uuid.UUID, contactIDs []uuid.UUID) (int64, error) {
+	if len(contactIDs) == 0 {
+		return 0, nil
+	}
+
+	members := make([]model.ContactGroupMemberModel, len(contactIDs))
+	for i, contactID := range contactIDs {
+		members[i] = model.ContactGroupMemberModel{GroupID: groupID, ContactID: contactID}
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&members)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to add contact group members",
+			zap.Error(result.Error),
+			zap.String("group_id", groupID.String()),
+		)
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *contactGroupRepositoryGorm) RemoveMember(ctx context.Context, groupID, contactID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("group_id = ? AND contact_id = ?", groupID, contactID).
+		Delete(&model.ContactGroupMemberModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to remove contact group member",
+			zap.Error(result.Error),
+			zap.String("group_id", groupID.String()),
+			zap.String("contact_id", contactID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *contactGroupRepositoryGorm) ListMembers(ctx context.Context, groupID uuid.UUID, limit, offset int) ([]*repository.Contact, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.ContactModel{}).
+		Joins("JOIN contact_group_members ON contact_group_members.contact_id = contacts.id").
+		Where("contact_group_members.group_id = ?", groupID).
+		Scopes(tenantScope(ctx, "contacts.tenant_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count contact group members", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.ContactModel
+	result := query.
+		Select("contacts.*").
+		Order("contact_group_members.created_at, contacts.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list contact group members",
+			zap.Error(result.Error),
+			zap.String("group_id", groupID.String()),
+		)
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	return toContacts(models), total, nil
+}
+
+func (r *contactGroupRow) toContactGroup() *repository.ContactGroup {
+	group := r.ContactGroupModel.ToContactGroup()
+	group.MemberCount = r.MemberCount
+	return group
+}
+
+func toContacts(models []model.ContactModel) []*repository.Contact {
+	contacts := make([]*repository.Contact, len(models))
+	for i := range models {
+		contacts[i] = models[i].ToContact()
+	}
+	return contacts
+}
@@ -0,0 +1,140 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContact(phoneNumber string, createdAt time.Time) *repository.Contact {
+	return &repository.Contact{
+		ID:          uuid.New(),
+		Name:        "contact " + phoneNumber,
+		PhoneNumber: phoneNumber,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+}
+
+func newTestContactGroup(name string) *repository.ContactGroup {
+	now := time.Now().UTC()
+	return &repository.ContactGroup{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestContactRepositoryGorm_PhoneNumberIsUniquePerTenant(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewContactRepositoryGorm(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	tenantID := uuid.New()
+	assert.NoError(t, persistence.NewTenantRepositoryGorm(db).Create(ctx, &repository.Tenant{
+		ID:         tenantID,
+		Name:       "acme",
+		APIKeyHash: "hash",
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}))
+
+	assert.NoError(t, repo.Create(ctx, newTestContact("+905551234567", now)))
+	scoped := newTestContact("+905551234567", now)
+	scoped.TenantID = tenantID
+	assert.NoError(t, repo.Create(ctx, scoped))
+	emailOnly := &repository.Contact{ID: uuid.New(), Email: "jane@example.com", CreatedAt: now, UpdatedAt: now}
+	assert.NoError(t, repo.Create(ctx, emailOnly))
+
+	// Act
+	duplicateErr := repo.Create(ctx, newTestContact("+905551234567", now))
+	tenantContacts, tenantTotal, listErr := repo.List(tenant.WithTenantID(ctx, tenantID), 10, 0)
+	found, findErr := repo.FindByID(ctx, emailOnly.ID)
+
+	// Assert
+	appErr, ok := duplicateErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+	}
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(1), tenantTotal)
+	if assert.Len(t, tenantContacts, 1) {
+		assert.Equal(t, scoped.ID, tenantContacts[0].ID)
+	}
+	assert.NoError(t, findErr)
+	assert.Equal(t, "jane@example.com", found.Email)
+	assert.Empty(t, found.PhoneNumber)
+}
+
+func TestContactGroupRepositoryGorm_Members(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	contacts := persistence.NewContactRepositoryGorm(db)
+	groups := persistence.NewContactGroupRepositoryGorm(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	first := newTestContact("+905551234567", now)
+	second := newTestContact("+905551234568", now.Add(time.Second))
+	assert.NoError(t, contacts.Create(ctx, first))
+	assert.NoError(t, contacts.Create(ctx, second))
+
+	group := newTestContactGroup("newsletter")
+	assert.NoError(t, groups.Create(ctx, group))
+
+	// Act
+	added, addErr := groups.AddMembers(ctx, group.ID, []uuid.UUID{first.ID, second.ID})
+	readded, readdErr := groups.AddMembers(ctx, group.ID, []uuid.UUID{first.ID})
+	members, total, membersErr := groups.ListMembers(ctx, group.ID, 10, 0)
+	deleteErr := contacts.Delete(ctx, first.ID)
+	afterDelete, deleteFindErr := groups.FindByID(ctx, group.ID)
+
+	// Assert
+	assert.NoError(t, addErr)
+	assert.Equal(t, int64(2), added)
+	assert.NoError(t, readdErr)
+	assert.Equal(t, int64(0), readded)
+	assert.NoError(t, membersErr)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, members, 2)
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, deleteFindErr)
+	assert.Equal(t, int64(1), afterDelete.MemberCount)
+}
+
+func TestContactGroupRepositoryGorm_DeleteIsTenantScoped(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	groups := persistence.NewContactGroupRepositoryGorm(db)
+	ctx := context.Background()
+
+	group := newTestContactGroup("newsletter")
+	assert.NoError(t, groups.Create(ctx, group))
+
+	// Act
+	tenantErr := groups.Delete(tenant.WithTenantID(ctx, uuid.New()), group.ID)
+	globalErr := groups.Delete(ctx, group.ID)
+	_, findErr := groups.FindByID(ctx, group.ID)
+
+	// Assert
+	appErr, ok := tenantErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+	}
+	assert.NoError(t, globalErr)
+	appErr, ok = findErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deadLetterRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewDeadLetterRepositoryGorm(db *gorm.DB) repository.DeadLetterRepository {
+	return &deadLetterRepositoryGorm{db: db}
+}
+
+func (r *deadLetterRepositoryGorm) Add(ctx context.Context, entry *repository.DeadLetter) error {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "message_id"}},
+			UpdateAll: true,
+		}).
+		Create(model.ToDeadLetterModel(entry))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to add dead letter",
+			zap.Error(result.Error),
+			zap.String("message_id", entry.MessageID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *deadLetterRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.DeadLetter, int64, error) {
+	// Bulk requeues leave their entries behind; the join hides them until
+	// the message either dead-letters again or is removed. Entries of
+	// soft-deleted messages are hidden along with the message.
+	query := r.db.WithContext(ctx).
+		Model(&model.DeadLetterModel{}).
+		Joins("JOIN messages ON messages.id = dead_letter_messages.message_id").
+		Where("messages.status = ? AND messages.deleted_at IS NULL", valueobject.MessageStatusFailed.String()).
+		Scopes(tenantScope(ctx, "messages.tenant_id"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count dead letters", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.DeadLetterModel
+	result := query.
+		Select("dead_letter_messages.*").
+		Order("dead_letter_messages.dead_lettered_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list dead letters", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	entries := make([]*repository.DeadLetter, len(models))
+	for i := range models {
+		entries[i] = models[i].ToDeadLetter()
+	}
+
+	return entries, total, nil
+}
+
+func (r *deadLetterRepositoryGorm) Remove(ctx context.Context, messageID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Delete(&model.DeadLetterModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to remove dead letter",
+			zap.Error(result.Error),
+			zap.String("message_id", messageID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
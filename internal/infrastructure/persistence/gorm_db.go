@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// SchemaVersion is the migration the queries of this build are written
+// against. Bump it with every new migration.
+const SchemaVersion uint = 37
+
+// GormDB is the connection the GORM repositories run on: PostgreSQL, or
+// SQLite for local development and tests.
+type GormDB struct {
+	db           *gorm.DB
+	maxIdleConns int
+}
+
+// NewGormDB connects to the database selected by DB_DRIVER, retrying for up
+// to DB_CONNECT_TIMEOUT while it is unreachable.
+func NewGormDB(cfg *config.DatabaseConfig) (*GormDB, error) {
+	open := NewPostgresGormDB
+	if cfg.Driver == config.DBDriverSQLite {
+		open = NewSQLiteGormDB
+	}
+
+	var db *GormDB
+	err := connectWithRetry(cfg.ConnectTimeout, "connect", func() error {
+		var err error
+		db, err = open(cfg)
+		return err
+	})
+	return db, err
+}
+
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger: gormlogger.New(
+			log.New(os.Stdout, "\r\n", log.LstdFlags),
+			gormlogger.Config{
+				SlowThreshold:             200 * time.Millisecond,
+				LogLevel:                  gormlogger.Warn,
+				IgnoreRecordNotFoundError: true,
+				Colorful:                  false,
+			},
+		),
+		PrepareStmt:            true,
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+	}
+}
+
+// openGormDB opens dialector, applies the pool settings and checks the
+// connection.
+func openGormDB(dialector gorm.Dialector, cfg *config.DatabaseConfig) (*GormDB, error) {
+	db, err := gorm.Open(dialector, newGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := registerQueryMetrics(db); err != nil {
+		return nil, fmt.Errorf("failed to register query metrics: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &GormDB{db: db, maxIdleConns: cfg.MaxIdleConns}, nil
+}
+
+func (p *GormDB) DB() *gorm.DB {
+	return p.db
+}
+
+func (p *GormDB) Close() error {
+	if p.db != nil {
+		sqlDB, err := p.db.DB()
+		if err != nil {
+			return err
+		}
+		logger.Get().Info("closing database connection")
+		return sqlDB.Close()
+	}
+	return nil
+}
+
+func (p *GormDB) HealthCheck(ctx context.Context) error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// ResetIdleConns closes the idle connections of the pool, so none opened
+// before a failover keeps pointing at the old primary. New ones are opened
+// as they are needed.
+func (p *GormDB) ResetIdleConns() error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxIdleConns(0)
+	sqlDB.SetMaxIdleConns(p.maxIdleConns)
+	return nil
+}
+
+// CheckSchema fails unless the migrations are applied up to at least
+// SchemaVersion and none was left half-applied. A newer schema passes, so the
+// previous release keeps serving while a rollout migrates ahead of it.
+func (p *GormDB) CheckSchema(ctx context.Context) error {
+	var state struct {
+		Version int64
+		Dirty   bool
+	}
+	result := p.db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state)
+	if result.Error != nil {
+		return fmt.Errorf("failed to read migration version: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no migrations applied, expected version %d", SchemaVersion)
+	}
+	if state.Dirty {
+		return fmt.Errorf("migration %d failed halfway and needs to be fixed", state.Version)
+	}
+	if state.Version < int64(SchemaVersion) {
+		return fmt.Errorf("database is at migration %d, expected at least %d", state.Version, SchemaVersion)
+	}
+	return nil
+}
+
+// isSQLite reports whether db runs on SQLite, for the few queries that need
+// PostgreSQL-only clauses dropped.
+func isSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == config.DBDriverSQLite
+}
@@ -0,0 +1,68 @@
+package persistence_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersion_MatchesNewestMigration(t *testing.T) {
+	for _, dir := range []string{"../../../migrations", "../../../migrations/sqlite"} {
+		entries, err := os.ReadDir(dir)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		var newest uint64
+		for _, entry := range entries {
+			prefix, _, ok := strings.Cut(entry.Name(), "_")
+			if entry.IsDir() || !ok {
+				continue
+			}
+			if version, err := strconv.ParseUint(prefix, 10, 64); err == nil && version > newest {
+				newest = version
+			}
+		}
+
+		assert.Equal(t, uint64(persistence.SchemaVersion), newest, "SchemaVersion must be bumped with the migrations in %s", dir)
+	}
+}
+
+func TestGormDB_CheckSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  string
+		wantErr bool
+	}{
+		{name: "migrated", wantErr: false},
+		{name: "newer schema", update: "UPDATE schema_migrations SET version = version + 1", wantErr: false},
+		{name: "older schema", update: "UPDATE schema_migrations SET version = version - 1", wantErr: true},
+		{name: "dirty migration", update: "UPDATE schema_migrations SET dirty = 1", wantErr: true},
+		{name: "no migrations", update: "DELETE FROM schema_migrations", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := newTestGormDB(t)
+			if tt.update != "" {
+				assert.NoError(t, db.DB().Exec(tt.update).Error)
+			}
+
+			// Act
+			err := db.CheckSchema(context.Background())
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -25,6 +25,9 @@ func mapGormError(err error) error {
 	case errors.Is(err, gorm.ErrDuplicatedKey):
 		return apperrors.New(apperrors.ErrorCodeAlreadyExists, "duplicate record")
 
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperrors.NewValidationError("referenced record does not exist")
+
 	case errors.Is(err, gorm.ErrInvalidData):
 		return apperrors.NewValidationError("invalid data")
 
@@ -0,0 +1,59 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "metrics:start"
+
+// registerQueryMetrics times every GORM operation into the db_query_duration
+// histogram, labelled by operation type.
+func registerQueryMetrics(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, hook := range hooks {
+		operation := hook.operation
+		if err := hook.before("metrics:before_"+operation, startQueryTimer); err != nil {
+			return err
+		}
+		if err := hook.after("metrics:after_"+operation, func(tx *gorm.DB) {
+			observeQuery(tx, operation)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func startQueryTimer(tx *gorm.DB) {
+	tx.InstanceSet(metricsStartKey, time.Now())
+}
+
+func observeQuery(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(metricsStartKey)
+	if !ok {
+		return
+	}
+	start, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	metrics.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
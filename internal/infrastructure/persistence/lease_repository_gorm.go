@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type leaseRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewLeaseRepositoryGorm(db *gorm.DB) repository.LeaseRepository {
+	return &leaseRepositoryGorm{db: db}
+}
+
+func (r *leaseRepositoryGorm) TryAcquire(
+	ctx context.Context,
+	name, holderID, region string,
+	ttl, takeoverDelay time.Duration,
+) (*repository.Lease, bool, error) {
+	// All time comparisons use the database clock so that regions with
+	// skewed clocks still agree on when a lease has expired
+	query := `
+		INSERT INTO dispatch_leases AS l (
+			name, holder_id, holder_region, epoch, acquired_at, renewed_at, expires_at
+		) VALUES (?, ?, ?, 1, NOW(), NOW(), NOW() + make_interval(secs => ?))
+		ON CONFLICT (name) DO UPDATE SET
+			holder_id = EXCLUDED.holder_id,
+			holder_region = EXCLUDED.holder_region,
+			preferred_region = NULL,
+			epoch = CASE WHEN l.holder_id = EXCLUDED.holder_id THEN l.epoch ELSE l.epoch + 1 END,
+			acquired_at = CASE WHEN l.holder_id = EXCLUDED.holder_id THEN l.acquired_at ELSE NOW() END,
+			renewed_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE (l.holder_id = EXCLUDED.holder_id OR l.expires_at < NOW() - make_interval(secs => ?))
+			AND (l.preferred_region IS NULL OR l.preferred_region = EXCLUDED.holder_region)
+		RETURNING *
+	`
+
+	var leases []model.DispatchLeaseModel
+	result := r.db.WithContext(ctx).
+		Raw(query, name, holderID, region, ttl.Seconds(), takeoverDelay.Seconds()).
+		Scan(&leases)
+
+	if result.Error != nil {
+		logger.Get().Error("failed to acquire dispatch lease",
+			zap.Error(result.Error),
+			zap.String("lease", name),
+		)
+		return nil, false, mapGormError(result.Error)
+	}
+
+	if len(leases) == 1 {
+		return leases[0].ToLease(), true, nil
+	}
+
+	current, err := r.Get(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return current, false, nil
+}
+
+func (r *leaseRepositoryGorm) Release(ctx context.Context, name, holderID string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.DispatchLeaseModel{}).
+		Where("name = ? AND holder_id = ?", name, holderID).
+		Update("expires_at", gorm.Expr("NOW()"))
+
+	if result.Error != nil {
+		logger.Get().Error("failed to release dispatch lease",
+			zap.Error(result.Error),
+			zap.String("lease", name),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *leaseRepositoryGorm) Get(ctx context.Context, name string) (*repository.Lease, error) {
+	var lease model.DispatchLeaseModel
+
+	result := r.db.WithContext(ctx).
+		Where("name = ?", name).
+		First(&lease)
+
+	if result.Error != nil {
+		return nil, mapGormError(result.Error)
+	}
+
+	return lease.ToLease(), nil
+}
+
+func (r *leaseRepositoryGorm) SetPreferredRegion(ctx context.Context, name, region string) error {
+	var preferred *string
+	if region != "" {
+		preferred = &region
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&model.DispatchLeaseModel{}).
+		Where("name = ?", name).
+		Update("preferred_region", preferred)
+
+	if result.Error != nil {
+		logger.Get().Error("failed to set preferred region",
+			zap.Error(result.Error),
+			zap.String("lease", name),
+		)
+		return mapGormError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("lease not found")
+	}
+
+	return nil
+}
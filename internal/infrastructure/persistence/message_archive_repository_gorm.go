@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type messageArchiveRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewMessageArchiveRepositoryGorm(db *gorm.DB) repository.MessageArchiveRepository {
+	return &messageArchiveRepositoryGorm{db: db}
+}
+
+func (r *messageArchiveRepositoryGorm) Add(ctx context.Context, archive *repository.MessageArchive) error {
+	if err := r.db.WithContext(ctx).Create(model.ToMessageArchiveModel(archive)).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to add message archive",
+			zap.Error(err),
+			zap.String("object_key", archive.ObjectKey),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *messageArchiveRepositoryGorm) List(ctx context.Context, filter repository.MessageArchiveFilter, limit, offset int) ([]*repository.MessageArchive, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.MessageArchiveModel{})
+	// An archive overlaps the range unless all its messages fall outside it
+	if filter.CreatedAfter != nil {
+		query = query.Where("last_created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("first_created_at < ?", *filter.CreatedBefore)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count message archives", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.MessageArchiveModel
+	result := query.
+		Order("first_created_at ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list message archives", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	archives := make([]*repository.MessageArchive, len(models))
+	for i := range models {
+		archives[i] = models[i].ToMessageArchive()
+	}
+
+	return archives, total, nil
+}
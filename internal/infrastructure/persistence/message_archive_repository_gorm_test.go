@@ -0,0 +1,68 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestArchive(first, last time.Time) *repository.MessageArchive {
+	return &repository.MessageArchive{
+		ID:             uuid.New(),
+		ObjectKey:      "messages/" + first.Format("2006/01/02") + "/archive.jsonl.gz",
+		Format:         "jsonl.gz",
+		MessageCount:   100,
+		SizeBytes:      2048,
+		FirstCreatedAt: first,
+		LastCreatedAt:  last,
+		ArchivedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestMessageArchiveRepositoryGorm_ListByCreatedRange(t *testing.T) {
+	// Arrange
+	repo := persistence.NewMessageArchiveRepositoryGorm(newTestDB(t))
+	ctx := context.Background()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	january := newTestArchive(day, day.Add(20*time.Hour))
+	spanning := newTestArchive(day.Add(20*time.Hour), day.Add(30*time.Hour))
+	later := newTestArchive(day.Add(48*time.Hour), day.Add(50*time.Hour))
+	for _, archive := range []*repository.MessageArchive{later, january, spanning} {
+		assert.NoError(t, repo.Add(ctx, archive))
+	}
+
+	from := day.Add(24 * time.Hour)
+	to := day.Add(48 * time.Hour)
+
+	// Act
+	all, total, allErr := repo.List(ctx, repository.MessageArchiveFilter{}, 10, 0)
+	inRange, rangeTotal, rangeErr := repo.List(ctx, repository.MessageArchiveFilter{CreatedAfter: &from, CreatedBefore: &to}, 10, 0)
+	page, _, pageErr := repo.List(ctx, repository.MessageArchiveFilter{}, 1, 1)
+
+	// Assert
+	assert.NoError(t, allErr)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, all, 3) {
+		assert.Equal(t, january.ID, all[0].ID)
+		assert.Equal(t, later.ID, all[2].ID)
+		assert.Equal(t, january.ObjectKey, all[0].ObjectKey)
+		assert.Equal(t, 100, all[0].MessageCount)
+		assert.Equal(t, int64(2048), all[0].SizeBytes)
+		assert.True(t, january.LastCreatedAt.Equal(all[0].LastCreatedAt))
+	}
+	assert.NoError(t, rangeErr)
+	assert.Equal(t, int64(1), rangeTotal)
+	if assert.Len(t, inRange, 1) {
+		assert.Equal(t, spanning.ID, inRange[0].ID)
+	}
+	assert.NoError(t, pageErr)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, spanning.ID, page[0].ID)
+	}
+}
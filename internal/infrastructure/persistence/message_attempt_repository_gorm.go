@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type messageAttemptRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewMessageAttemptRepositoryGorm(db *gorm.DB) repository.MessageAttemptRepository {
+	return &messageAttemptRepositoryGorm{db: db}
+}
+
+func (r *messageAttemptRepositoryGorm) Add(ctx context.Context, attempt *repository.MessageAttempt) error {
+	if err := r.db.WithContext(ctx).Create(model.ToMessageAttemptModel(attempt)).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to add message attempt",
+			zap.Error(err),
+			zap.String("message_id", attempt.MessageID.String()),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *messageAttemptRepositoryGorm) ListByMessage(ctx context.Context, messageID uuid.UUID) ([]*repository.MessageAttempt, error) {
+	var models []model.MessageAttemptModel
+	result := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("attempt, started_at").
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list message attempts",
+			zap.Error(result.Error),
+			zap.String("message_id", messageID.String()),
+		)
+		return nil, mapGormError(result.Error)
+	}
+
+	attempts := make([]*repository.MessageAttempt, len(models))
+	for i := range models {
+		attempts[i] = models[i].ToMessageAttempt()
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,69 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageAttemptRepositoryGorm_AddAndList(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	messages := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	repo := persistence.NewMessageAttemptRepositoryGorm(db)
+	ctx := context.Background()
+
+	message := newTestMessage(t, "Attempted message")
+	assert.NoError(t, messages.Create(ctx, message))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	failed := &repository.MessageAttempt{
+		ID:         uuid.New(),
+		MessageID:  message.ID(),
+		Attempt:    1,
+		StartedAt:  now.Add(-time.Minute),
+		Duration:   1500 * time.Millisecond,
+		Status:     "failed",
+		StatusCode: 503,
+		ErrorCode:  "WEBHOOK_UNAVAILABLE",
+		Error:      "service unavailable",
+	}
+	sent := &repository.MessageAttempt{
+		ID:                uuid.New(),
+		MessageID:         message.ID(),
+		Attempt:           2,
+		StartedAt:         now,
+		Duration:          120 * time.Millisecond,
+		Status:            "sent",
+		StatusCode:        202,
+		WebhookResponse:   `{"messageId":"abc"}`,
+		ProviderRequestID: "req-1",
+	}
+	for _, attempt := range []*repository.MessageAttempt{sent, failed} {
+		assert.NoError(t, repo.Add(ctx, attempt))
+	}
+
+	// Act
+	attempts, err := repo.ListByMessage(ctx, message.ID())
+	other, otherErr := repo.ListByMessage(ctx, uuid.New())
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, attempts, 2) {
+		assert.Equal(t, failed.ID, attempts[0].ID)
+		assert.Equal(t, 503, attempts[0].StatusCode)
+		assert.Equal(t, 1500*time.Millisecond, attempts[0].Duration)
+		assert.Equal(t, "service unavailable", attempts[0].Error)
+		assert.Empty(t, attempts[0].WebhookResponse)
+		assert.Equal(t, sent.ID, attempts[1].ID)
+		assert.JSONEq(t, sent.WebhookResponse, attempts[1].WebhookResponse)
+		assert.Equal(t, "req-1", attempts[1].ProviderRequestID)
+	}
+	assert.NoError(t, otherErr)
+	assert.Empty(t, other)
+}
@@ -2,61 +2,187 @@ package persistence
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
 	"github.com/eneskaya/insider-messaging/internal/domain/repository"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// sentStatuses make up the sent listing; a delivery receipt moves a message
+// on from sent without taking it out of the listing.
+var sentStatuses = []string{
+	valueobject.MessageStatusSent.String(),
+	valueobject.MessageStatusDelivered.String(),
+	valueobject.MessageStatusUndelivered.String(),
+}
+
 type messageRepositoryGorm struct {
-	db        *gorm.DB
-	charLimit int
+	db                 *gorm.DB
+	charLimit          int
+	outbox             bool
+	lowerPriorityShare float64
 }
 
-func NewMessageRepositoryGorm(db *gorm.DB, charLimit int) repository.MessageRepository {
+// NewMessageRepositoryGorm stores the message's pending lifecycle events in
+// outbox_events within the same transaction as each write when outbox is set.
+// lowerPriorityShare is the part of each pending batch kept for normal and
+// low priority messages (see ClaimPendingMessages).
+func NewMessageRepositoryGorm(db *gorm.DB, charLimit int, outbox bool, lowerPriorityShare float64) repository.MessageRepository {
 	return &messageRepositoryGorm{
-		db:        db,
-		charLimit: charLimit,
+		db:                 db,
+		charLimit:          charLimit,
+		outbox:             outbox,
+		lowerPriorityShare: lowerPriorityShare,
 	}
 }
 
+// createBatchSize is the number of rows per INSERT statement in CreateBatch,
+// well below the bind parameter limits of Postgres and SQLite.
+const createBatchSize = 100
+
+// write runs fn and, with the outbox enabled, inserts the message's pending
+// events in the same transaction. The events are cleared once committed.
+func (r *messageRepositoryGorm) write(ctx context.Context, message *entity.Message, fn func(db *gorm.DB) error) error {
+	if !r.outbox || len(message.PendingEvents()) == 0 {
+		if err := fn(r.db.WithContext(ctx)); err != nil {
+			return err
+		}
+		message.ClearPendingEvents()
+		return nil
+	}
+
+	outboxModels, err := model.ToOutboxModels(message)
+	if err != nil {
+		return apperrors.NewInternalError(err)
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.Create(&outboxModels).Error; err != nil {
+			logger.FromContext(ctx).Error("failed to write outbox events",
+				zap.Error(err),
+				zap.String("message_id", message.ID().String()),
+			)
+			return mapGormError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	message.ClearPendingEvents()
+	return nil
+}
+
 func (r *messageRepositoryGorm) Create(ctx context.Context, message *entity.Message) error {
 	messageModel := model.ToModel(message)
 
-	result := r.db.WithContext(ctx).Create(messageModel)
-	if result.Error != nil {
-		logger.Get().Error("failed to create message",
-			zap.Error(result.Error),
-			zap.String("message_id", message.ID().String()),
-		)
-		return mapGormError(result.Error)
+	return r.write(ctx, message, func(db *gorm.DB) error {
+		if err := db.Create(messageModel).Error; err != nil {
+			logger.FromContext(ctx).Error("failed to create message",
+				zap.Error(err),
+				zap.String("message_id", message.ID().String()),
+			)
+			return mapGormError(err)
+		}
+		return nil
+	})
+}
+
+func (r *messageRepositoryGorm) CreateBatch(ctx context.Context, messages []*entity.Message) error {
+	if len(messages) == 0 {
+		return nil
 	}
 
+	messageModels := make([]*model.MessageModel, len(messages))
+	var outboxModels []model.OutboxEventModel
+	for i, message := range messages {
+		messageModels[i] = model.ToModel(message)
+		if r.outbox {
+			events, err := model.ToOutboxModels(message)
+			if err != nil {
+				return apperrors.NewInternalError(err)
+			}
+			outboxModels = append(outboxModels, events...)
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(messageModels, createBatchSize).Error; err != nil {
+			logger.FromContext(ctx).Error("failed to create message batch",
+				zap.Error(err),
+				zap.Int("count", len(messages)),
+			)
+			return mapGormError(err)
+		}
+		if len(outboxModels) > 0 {
+			if err := tx.CreateInBatches(outboxModels, createBatchSize).Error; err != nil {
+				logger.FromContext(ctx).Error("failed to write outbox events", zap.Error(err))
+				return mapGormError(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		message.ClearPendingEvents()
+	}
 	return nil
 }
 
 func (r *messageRepositoryGorm) Update(ctx context.Context, message *entity.Message) error {
 	messageModel := model.ToModel(message)
 
-	result := r.db.WithContext(ctx).
-		Model(&model.MessageModel{}).
-		Where("id = ?", messageModel.ID).
-		Updates(messageModel)
+	err := r.write(ctx, message, func(db *gorm.DB) error {
+		// Select the mutable columns explicitly so that zero values (a reset
+		// attempt counter, a cleared error) are written too. Passing the
+		// model lets the optimistic lock plugin match on the version that
+		// was read and bump it.
+		result := db.
+			Model(messageModel).
+			Where("id = ?", messageModel.ID).
+			Scopes(tenantScope(ctx, "tenant_id")).
+			Select(
+				"status", "sent_at", "delivered_at", "next_retry_at", "processing_started_at", "attempts", "last_error", "error_code",
+				"webhook_message_id", "webhook_response", "trace_id", "provider_request_id", "simulated", "version",
+			).
+			Updates(messageModel)
 
-	if result.Error != nil {
-		logger.Get().Error("failed to update message",
-			zap.Error(result.Error),
-			zap.String("message_id", message.ID().String()),
-		)
-		return mapGormError(result.Error)
-	}
+		if result.Error != nil {
+			logger.FromContext(ctx).Error("failed to update message",
+				zap.Error(result.Error),
+				zap.String("message_id", message.ID().String()),
+			)
+			return mapGormError(result.Error)
+		}
 
-	if err := checkRowsAffected(result, 1); err != nil {
+		if result.RowsAffected == 0 {
+			return r.explainMissedUpdate(ctx, db, messageModel.ID)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -64,15 +190,36 @@ func (r *messageRepositoryGorm) Update(ctx context.Context, message *entity.Mess
 	return nil
 }
 
+// explainMissedUpdate tells apart the two reasons a versioned update matches
+// no row: the message does not exist (for this tenant), or another writer
+// updated it since it was read.
+func (r *messageRepositoryGorm) explainMissedUpdate(ctx context.Context, db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	result := db.
+		Model(&model.MessageModel{}).
+		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Count(&count)
+	if result.Error != nil {
+		return mapGormError(result.Error)
+	}
+
+	if count == 0 {
+		return apperrors.NewNotFoundError("message not found")
+	}
+	return apperrors.NewVersionConflictError("message was modified concurrently")
+}
+
 func (r *messageRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
 	var messageModel model.MessageModel
 
 	result := r.db.WithContext(ctx).
 		Where("id = ?", id).
+		Scopes(tenantScope(ctx, "tenant_id")).
 		First(&messageModel)
 
 	if result.Error != nil {
-		logger.Get().Error("failed to find message by ID",
+		logger.FromContext(ctx).Error("failed to find message by ID",
 			zap.Error(result.Error),
 			zap.String("message_id", id.String()),
 		)
@@ -82,23 +229,219 @@ func (r *messageRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*en
 	return model.ToEntity(&messageModel, r.charLimit)
 }
 
-func (r *messageRepositoryGorm) FindPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+func (r *messageRepositoryGorm) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Message, error) {
+	var messageModel model.MessageModel
+
+	query := r.db.WithContext(ctx).Where("idempotency_key = ?", key)
+
+	// Keys are unique per tenant, with global-token messages as one more
+	// tenant of their own
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		query = query.Where("tenant_id = ?", tenantID)
+	} else {
+		query = query.Where("tenant_id IS NULL")
+	}
+
+	result := query.First(&messageModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find message by idempotency key", zap.Error(result.Error))
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return model.ToEntity(&messageModel, r.charLimit)
+}
+
+// FindByWebhookMessageID looks a message up by the ID its provider assigned,
+// e.g. to apply a delivery receipt.
+func (r *messageRepositoryGorm) FindByWebhookMessageID(ctx context.Context, webhookMessageID string) (*entity.Message, error) {
+	var messageModel model.MessageModel
+
+	result := r.db.WithContext(ctx).
+		Where("webhook_message_id = ?", webhookMessageID).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		First(&messageModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find message by webhook message ID",
+				zap.Error(result.Error),
+				zap.String("webhook_message_id", webhookMessageID),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return model.ToEntity(&messageModel, r.charLimit)
+}
+
+// ClaimPendingMessages claims due messages in priority then creation order,
+// keeping the lower priority share of the batch for normal and low priority
+// messages (see fillByPriority).
+//
+// The rows are locked, skipping those another instance is claiming, and
+// marked processing in one short transaction.
+func (r *messageRepositoryGorm) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	var claimed []*entity.Message
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked := &messageRepositoryGorm{db: tx, charLimit: r.charLimit, lowerPriorityShare: r.lowerPriorityShare}
+		pending, err := locked.findPendingMessages(ctx, limit)
+		if err != nil || len(pending) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(pending))
+		for i, message := range pending {
+			ids[i] = message.ID()
+		}
+
+		var models []model.MessageModel
+		result := tx.Raw(`
+			UPDATE messages
+			SET status = ?, attempts = attempts + 1, processing_started_at = ?, version = version + 1
+			WHERE id IN ? AND status = ?
+			RETURNING *
+		`, valueobject.MessageStatusProcessing.String(), time.Now().UTC(), ids, valueobject.MessageStatusPending.String()).
+			Scan(&models)
+
+		if result.Error != nil {
+			logger.FromContext(ctx).Error("failed to claim pending messages", zap.Error(result.Error))
+			return mapGormError(result.Error)
+		}
+
+		claimed, err = model.ToEntities(models, r.charLimit)
+		if err != nil {
+			return err
+		}
+		sortByIDs(claimed, ids)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// sortByIDs puts messages in the order of ids, which RETURNING does not keep.
+func sortByIDs(messages []*entity.Message, ids []uuid.UUID) {
+	position := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return position[messages[i].ID()] < position[messages[j].ID()]
+	})
+}
+
+// findPendingMessages selects the messages ClaimPendingMessages claims. A
+// claim limited to some priorities takes them in order without the lower
+// priority share, which only balances a batch of every priority.
+func (r *messageRepositoryGorm) findPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	if priorities := repository.ClaimPriorities(ctx); len(priorities) > 0 {
+		return r.findPending(ctx, "AND priority IN ?", []interface{}{priorityRanks(priorities)}, limit)
+	}
+
+	return fillByPriority(limit, r.lowerPriorityShare, (*entity.Message).ID,
+		func(lane priorityLane, limit int, exclude []uuid.UUID) ([]*entity.Message, error) {
+			condition, args := gormLaneCondition(lane, exclude)
+			return r.findPending(ctx, condition, args, limit)
+		})
+}
+
+// gormLaneCondition is the extra condition of a pending query in lane.
+func gormLaneCondition(lane priorityLane, exclude []uuid.UUID) (string, []interface{}) {
+	high := valueobject.MessagePriorityHigh.Rank()
+
+	var condition string
+	var args []interface{}
+	switch lane {
+	case laneHigh:
+		condition, args = "AND priority = ?", []interface{}{high}
+	case laneLower:
+		condition, args = "AND priority < ?", []interface{}{high}
+	}
+	if len(exclude) > 0 {
+		condition += " AND id NOT IN ?"
+		args = append(args, exclude)
+	}
+	return condition, args
+}
+
+// priorityRanks converts priorities to their stored form.
+func priorityRanks(priorities []valueobject.MessagePriority) []int {
+	ranks := make([]int, len(priorities))
+	for i, priority := range priorities {
+		ranks[i] = priority.Rank()
+	}
+	return ranks
+}
+
+// findPending locks up to limit due messages matching the extra condition.
+func (r *messageRepositoryGorm) findPending(ctx context.Context, condition string, conditionArgs []interface{}, limit int) ([]*entity.Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
 	var models []model.MessageModel
+	now := time.Now().UTC()
 
 	query := `
 		SELECT * FROM messages
 		WHERE status = ?
-		ORDER BY created_at ASC
+			AND deleted_at IS NULL
+			AND (scheduled_at IS NULL OR scheduled_at <= ?)
+			AND (next_retry_at IS NULL OR next_retry_at <= ?)
+			AND ` + activeTenantCondition + `
+			AND ` + activeCampaignCondition + `
+			%s
+			%s
+		ORDER BY priority DESC, created_at ASC
 		LIMIT ?
-		FOR UPDATE SKIP LOCKED
 	`
+	// SQLite has a single writer, so there are no row locks to skip; the
+	// version check on the claiming update settles races there
+	if !isSQLite(r.db) {
+		query += "FOR UPDATE SKIP LOCKED"
+	}
+
+	args := []interface{}{valueobject.MessageStatusPending.String(), now, now}
+	tenantFilter := ""
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		tenantFilter = "AND tenant_id = ?"
+		args = append(args, tenantID)
+	}
+	args = append(args, conditionArgs...)
 
 	result := r.db.WithContext(ctx).
-		Raw(query, valueobject.MessageStatusPending.String(), limit).
+		Raw(fmt.Sprintf(query, tenantFilter, condition), append(args, limit)...).
 		Scan(&models)
 
 	if result.Error != nil {
-		logger.Get().Error("failed to find pending messages", zap.Error(result.Error))
+		logger.FromContext(ctx).Error("failed to find pending messages", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	return model.ToEntities(models, r.charLimit)
+}
+
+// FindStaleProcessing returns messages claimed for sending before
+// startedBefore that are still processing, oldest claim first.
+func (r *messageRepositoryGorm) FindStaleProcessing(ctx context.Context, startedBefore time.Time, limit int) ([]*entity.Message, error) {
+	var models []model.MessageModel
+
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND processing_started_at < ?", valueobject.MessageStatusProcessing.String(), startedBefore).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Order("processing_started_at ASC").
+		Limit(limit).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to find stale processing messages", zap.Error(result.Error))
 		return nil, mapGormError(result.Error)
 	}
 
@@ -109,97 +452,611 @@ func (r *messageRepositoryGorm) FindSentMessages(ctx context.Context, limit, off
 	var models []model.MessageModel
 
 	result := r.db.WithContext(ctx).
-		Where("status = ?", valueobject.MessageStatusSent.String()).
+		Where("status IN ?", sentStatuses).
+		Scopes(tenantScope(ctx, "tenant_id")).
 		Order("sent_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&models)
 
 	if result.Error != nil {
-		logger.Get().Error("failed to find sent messages", zap.Error(result.Error))
+		logger.FromContext(ctx).Error("failed to find sent messages", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	return model.ToEntities(models, r.charLimit)
+}
+
+// jsonScope keeps messages whose JSON column, metadata or webhook_response,
+// contains every pair. Postgres answers it with JSONB containment; SQLite has
+// no JSONB, so each key is extracted. Keys are validated to be plain
+// identifiers, so they can be put into the JSON path as they are.
+func (r *messageRepositoryGorm) jsonScope(column string, fields map[string]string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !isSQLite(r.db) {
+			// A map of strings always marshals
+			data, _ := json.Marshal(fields)
+			return db.Where(column+" @> ?::jsonb", string(data))
+		}
+		for key, value := range fields {
+			db = db.Where("json_extract("+column+", ?) = ?", `$."`+key+`"`, value)
+		}
+		return db
+	}
+}
+
+// filterQuery selects the messages matching filter.
+func (r *messageRepositoryGorm) filterQuery(ctx context.Context, filter repository.MessageFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"))
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.PhoneNumber != "" {
+		query = query.Where("phone_number = ?", filter.PhoneNumber)
+	}
+	if filter.ErrorCode != "" {
+		query = query.Where("error_code = ?", filter.ErrorCode)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if len(filter.Metadata) > 0 {
+		query = query.Scopes(r.jsonScope("metadata", filter.Metadata))
+	}
+	if len(filter.ProviderResponse) > 0 {
+		query = query.Scopes(r.jsonScope("webhook_response", filter.ProviderResponse))
+	}
+	if filter.CampaignID != uuid.Nil {
+		query = query.Where("campaign_id = ?", filter.CampaignID)
+	}
+	if filter.SplitGroupID != uuid.Nil {
+		query = query.Where("split_group_id = ?", filter.SplitGroupID)
+	}
+
+	return query
+}
+
+func (r *messageRepositoryGorm) FindByFilter(ctx context.Context, filter repository.MessageFilter, limit, offset int) ([]*entity.Message, int64, error) {
+	query := r.filterQuery(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count filtered messages", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.MessageModel
+	result := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to find filtered messages", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	messages, err := model.ToEntities(models, r.charLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *messageRepositoryGorm) FindByFilterAfter(ctx context.Context, filter repository.MessageFilter, after *repository.MessageCursor, limit int) ([]*entity.Message, error) {
+	query := r.filterQuery(ctx, filter)
+	if after != nil {
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	var models []model.MessageModel
+	result := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to page through filtered messages", zap.Error(result.Error))
 		return nil, mapGormError(result.Error)
 	}
 
 	return model.ToEntities(models, r.charLimit)
 }
 
+// contentSearchText is the text search configuration of the content index.
+// simple neither stems nor drops stop words, so it suits any language.
+const contentSearchText = "to_tsvector('simple', content)"
+
+func (r *messageRepositoryGorm) SearchContent(ctx context.Context, q *valueobject.SearchQuery, limit, offset int) ([]*entity.Message, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id"), r.contentSearchScope(q))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count content search matches", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	// Postgres ranks by relevance; SQLite has no ranking, so newest first
+	order := clause.Expr{SQL: "created_at DESC"}
+	if !isSQLite(r.db) {
+		order = clause.Expr{
+			SQL:  "ts_rank(" + contentSearchText + ", websearch_to_tsquery('simple', ?)) DESC, created_at DESC",
+			Vars: []interface{}{q.String()},
+		}
+	}
+
+	var models []model.MessageModel
+	result := query.
+		Clauses(clause.OrderBy{Expression: order}).
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to search message content", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	messages, err := model.ToEntities(models, r.charLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// contentSearchScope keeps messages matching q. Postgres uses the full-text
+// index; SQLite matches every term as a case-insensitive substring.
+func (r *messageRepositoryGorm) contentSearchScope(q *valueobject.SearchQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !isSQLite(r.db) {
+			return db.Where(contentSearchText+" @@ websearch_to_tsquery('simple', ?)", q.String())
+		}
+		for _, term := range q.Terms() {
+			db = db.Where(`content LIKE ? ESCAPE '\'`, likePattern(term))
+		}
+		for _, term := range q.Excluded() {
+			db = db.Where(`content NOT LIKE ? ESCAPE '\'`, likePattern(term))
+		}
+		return db
+	}
+}
+
+// likePattern matches term anywhere, taking its wildcards literally
+func likePattern(term string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term) + "%"
+}
+
 func (r *messageRepositoryGorm) GetStats(ctx context.Context) (*repository.MessageStats, error) {
-	var stats repository.MessageStats
+	return r.stats(ctx, r.db.WithContext(ctx).Model(&model.MessageModel{}))
+}
+
+func (r *messageRepositoryGorm) GetCampaignStats(ctx context.Context, campaignID uuid.UUID) (*repository.MessageStats, error) {
+	return r.stats(ctx, r.db.WithContext(ctx).Model(&model.MessageModel{}).Where("campaign_id = ?", campaignID))
+}
+
+// stats counts the messages query selects by status.
+func (r *messageRepositoryGorm) stats(ctx context.Context, query *gorm.DB) (*repository.MessageStats, error) {
+	var result statusCounts
+
+	err := query.
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select(statusCountColumns).
+		Scan(&result).Error
+
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message stats", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+
+	stats := result.toStats()
+	return &stats, nil
+}
+
+func (r *messageRepositoryGorm) GetStatsByCaller(ctx context.Context) ([]repository.CallerStats, error) {
+	var results []struct {
+		Caller string
+		Counts statusCounts `gorm:"embedded"`
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select("COALESCE(created_by, '') as caller," + statusCountColumns).
+		Group("COALESCE(created_by, '')").
+		Order("total DESC, caller").
+		Scan(&results).Error
+
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message stats by caller", zap.Error(err))
+		return nil, mapGormError(err)
+	}
 
-	type statsResult struct {
-		Total   int64
-		Pending int64
-		Sent    int64
-		Failed  int64
+	stats := make([]repository.CallerStats, len(results))
+	for i, result := range results {
+		stats[i] = repository.CallerStats{Caller: result.Caller, MessageStats: result.Counts.toStats()}
 	}
+	return stats, nil
+}
 
-	var result statsResult
+func (r *messageRepositoryGorm) GetSendPerformance(ctx context.Context, since time.Time) (*repository.SendPerformance, error) {
+	// SQLite has no percentile aggregate; the percentile is read by offset
+	// once the number of sent messages is known
+	seconds, p95 := postgresSecondsToSend, postgresP95SecondsToSend
+	if isSQLite(r.db) {
+		seconds, p95 = "(julianday(sent_at) - julianday(created_at)) * 86400", "0"
+	}
 
+	var row sendPerformanceRow
 	err := r.db.WithContext(ctx).
 		Model(&model.MessageModel{}).
-		Select(`
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select(sendPerformanceColumns(seconds, p95)).
+		Scan(&row).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get send performance", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+
+	// Counted apart so each outcome is looked up by its own time's index
+	// instead of in the scan above
+	recent := func(count *int64, condition string, args ...interface{}) error {
+		return r.db.WithContext(ctx).
+			Model(&model.MessageModel{}).
+			Scopes(tenantScope(ctx, "tenant_id")).
+			Where(condition, args...).
+			Count(count).Error
+	}
+	if err := recent(&row.RecentSent, sentSince("?"), since); err != nil {
+		logger.FromContext(ctx).Error("failed to count recent outcomes", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+	if err := recent(&row.RecentFailed, failedSince("?"), since, since); err != nil {
+		logger.FromContext(ctx).Error("failed to count recent outcomes", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+
+	if isSQLite(r.db) && row.Sent > 0 {
+		var ranked []struct{ Seconds float64 }
+		err := r.db.WithContext(ctx).
+			Model(&model.MessageModel{}).
+			Scopes(tenantScope(ctx, "tenant_id")).
+			Select(seconds + " as seconds").
+			Where(sentCondition).
+			Order("seconds").
+			Offset(int(math.Ceil(0.95*float64(row.Sent))) - 1).
+			Limit(1).
+			Scan(&ranked).Error
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to get time to send percentile", zap.Error(err))
+			return nil, mapGormError(err)
+		}
+		if len(ranked) > 0 {
+			row.P95Seconds = ranked[0].Seconds
+		}
+	}
+
+	return row.toPerformance(), nil
+}
+
+// statusCountColumns counts messages by status into statusCounts
+const statusCountColumns = `
 			COUNT(*) as total,
 			COUNT(*) FILTER (WHERE status = 'pending') as pending,
 			COUNT(*) FILTER (WHERE status = 'sent') as sent,
-			COUNT(*) FILTER (WHERE status = 'failed') as failed
-		`).
-		Scan(&result).Error
+			COUNT(*) FILTER (WHERE status = 'failed') as failed,
+			COUNT(*) FILTER (WHERE status = 'cancelled') as cancelled,
+			COUNT(*) FILTER (WHERE status = 'delivered') as delivered,
+			COUNT(*) FILTER (WHERE status = 'undelivered') as undelivered,
+			COUNT(*) FILTER (WHERE status = 'quarantined') as quarantined,
+			COUNT(*) FILTER (WHERE status = 'paused') as paused,
+			COUNT(*) FILTER (WHERE status = 'blocked') as blocked,
+			COUNT(*) FILTER (WHERE status = 'expired') as expired
+		`
+
+type statusCounts struct {
+	Total       int64
+	Pending     int64
+	Sent        int64
+	Failed      int64
+	Cancelled   int64
+	Delivered   int64
+	Undelivered int64
+	Quarantined int64
+	Paused      int64
+	Blocked     int64
+	Expired     int64
+}
+
+func (c statusCounts) toStats() repository.MessageStats {
+	return repository.MessageStats{
+		TotalMessages:       c.Total,
+		PendingMessages:     c.Pending,
+		SentMessages:        c.Sent,
+		FailedMessages:      c.Failed,
+		CancelledMessages:   c.Cancelled,
+		DeliveredMessages:   c.Delivered,
+		UndeliveredMessages: c.Undelivered,
+		QuarantinedMessages: c.Quarantined,
+		PausedMessages:      c.Paused,
+		BlockedMessages:     c.Blocked,
+		ExpiredMessages:     c.Expired,
+	}
+}
+
+func (r *messageRepositoryGorm) GetStatsTimeSeries(ctx context.Context, bucket repository.StatsBucket, from, to time.Time) (*repository.MessageTimeSeries, error) {
+	created, err := r.countPerBucket(ctx, bucket, "created_at", "created_at >= ? AND created_at < ?", from, to)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := r.countPerBucket(ctx, bucket, "sent_at", sentCondition+" AND sent_at >= ? AND sent_at < ?", from, to)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := r.countPerBucket(ctx, bucket, failedAt, "status = 'failed' AND "+failedInRange("?", "?"), from, to, from, to)
+	if err != nil {
+		return nil, err
+	}
 
+	var errorCodes []repository.ErrorCodeCount
+	err = r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select("error_code, COUNT(*) as count").
+		Where(failedInRange("?", "?"), from, to, from, to).
+		Where("error_code IS NOT NULL AND error_code <> ''").
+		Group("error_code").
+		Order("count DESC, error_code").
+		Scan(&errorCodes).Error
 	if err != nil {
-		logger.Get().Error("failed to get message stats", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to get error code breakdown", zap.Error(err))
 		return nil, mapGormError(err)
 	}
 
-	stats.TotalMessages = result.Total
-	stats.PendingMessages = result.Pending
-	stats.SentMessages = result.Sent
-	stats.FailedMessages = result.Failed
+	buckets, err := toStatsBuckets(created, sent, failed)
+	if err != nil {
+		return nil, err
+	}
 
-	return &stats, nil
+	return &repository.MessageTimeSeries{Buckets: buckets, ErrorCodes: errorCodes}, nil
+}
+
+// countPerBucket counts the messages matching where per bucket of column.
+func (r *messageRepositoryGorm) countPerBucket(ctx context.Context, bucket repository.StatsBucket, column, where string, args ...interface{}) ([]bucketCountRow, error) {
+	var rows []bucketCountRow
+	bucketStart := r.bucketStart(bucket, column)
+	err := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select(bucketStart+" as start, COUNT(*) as count").
+		Where(where, args...).
+		Group(bucketStart).
+		Scan(&rows).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message time series", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+	return rows, nil
 }
 
-func (r *messageRepositoryGorm) BeginTx(ctx context.Context) (repository.Transaction, error) {
-	tx := r.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return nil, mapGormError(tx.Error)
+func (r *messageRepositoryGorm) FindDuplicateContent(ctx context.Context, from, to time.Time, minCount, limit int) ([]repository.DuplicateContent, error) {
+	var rows []duplicateContentRow
+	err := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select(fmt.Sprintf(`channel,
+			COALESCE(recipient, phone_number) as recipient,
+			content_hash,
+			MIN(content) as content,
+			COUNT(*) as count,
+			%s as first_created_at,
+			%s as last_created_at`, r.createdAtText("MIN"), r.createdAtText("MAX"))).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("channel, COALESCE(recipient, phone_number), content_hash").
+		Having("COUNT(*) >= ?", minCount).
+		Order("count DESC, last_created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find duplicate content", zap.Error(err))
+		return nil, mapGormError(err)
 	}
 
-	return &gormTransaction{
-		tx:  tx,
-		ctx: ctx,
-		db:  r.db,
-	}, nil
+	return toDuplicateContent(rows)
 }
 
-type gormTransaction struct {
-	tx  *gorm.DB
-	ctx context.Context
-	db  *gorm.DB
+func (r *messageRepositoryGorm) GetDeliveryByPrefix(ctx context.Context, from, to time.Time, prefixLength int) ([]repository.PrefixDelivery, error) {
+	var rows []repository.PrefixDelivery
+	err := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Scopes(tenantScope(ctx, "tenant_id")).
+		Select(`SUBSTR(phone_number, 1, ?) as prefix,
+			MIN(phone_number) as sample,
+			COUNT(*) as total,
+			COUNT(CASE WHEN status IN (?, ?) THEN 1 END) as sent,
+			COUNT(CASE WHEN status = ? THEN 1 END) as delivered,
+			COUNT(CASE WHEN status IN (?, ?) THEN 1 END) as failed`,
+			prefixLength,
+			valueobject.MessageStatusSent.String(), valueobject.MessageStatusDelivered.String(),
+			valueobject.MessageStatusDelivered.String(),
+			valueobject.MessageStatusFailed.String(), valueobject.MessageStatusUndelivered.String(),
+		).
+		Where("channel = ? AND phone_number <> ''", valueobject.ChannelSMS.String()).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("prefix").
+		Order("prefix").
+		Scan(&rows).Error
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count delivery by prefix", zap.Error(err))
+		return nil, mapGormError(err)
+	}
+
+	return rows, nil
 }
 
-func (t *gormTransaction) Commit() error {
-	err := t.tx.Commit().Error
-	if err != nil {
-		return mapGormError(err)
+// createdAtText applies aggregate to created_at, rendered as text with
+// createdAtLayout since SQLite returns aggregated times untyped.
+func (r *messageRepositoryGorm) createdAtText(aggregate string) string {
+	if isSQLite(r.db) {
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%f', %s(created_at))", aggregate)
 	}
-	return nil
+	return fmt.Sprintf("to_char(%s(created_at), 'YYYY-MM-DD HH24:MI:SS.MS')", aggregate)
 }
 
-func (t *gormTransaction) Rollback() error {
-	err := t.tx.Rollback().Error
-	if err != nil {
-		return mapGormError(err)
+// bucketStart truncates column to the bucket, rendered as text so both
+// dialects return the same format.
+func (r *messageRepositoryGorm) bucketStart(bucket repository.StatsBucket, column string) string {
+	if isSQLite(r.db) {
+		if bucket == repository.StatsBucketDay {
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d 00:00:00', %s)", column)
+		}
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00:00', %s)", column)
 	}
-	return nil
+	return postgresBucketStart(bucket, column)
+}
+
+func (r *messageRepositoryGorm) RequeueFailed(ctx context.Context, filter repository.FailedMessageFilter, resetAttempts bool) (int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.MessageModel{}).
+		Where("status = ?", valueobject.MessageStatusFailed.String()).
+		Scopes(tenantScope(ctx, "tenant_id"))
+
+	if filter.ErrorCode != "" {
+		query = query.Where("error_code = ?", filter.ErrorCode)
+	}
+	if filter.PhoneNumber != "" {
+		query = query.Where("phone_number = ?", filter.PhoneNumber)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	// The optimistic lock plugin bumps version for map updates as well
+	updates := map[string]interface{}{
+		"status":        valueobject.MessageStatusPending.String(),
+		"last_error":    "",
+		"error_code":    "",
+		"next_retry_at": nil,
+	}
+	if resetAttempts {
+		updates["attempts"] = 0
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to requeue failed messages", zap.Error(result.Error))
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *messageRepositoryGorm) SoftDeleteOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	// With DeletedAt on the model, Delete sets deleted_at on rows that do
+	// not have it yet
+	result := r.db.WithContext(ctx).
+		Where("id IN (?)", r.olderThan(r.db.WithContext(ctx), status, createdBefore, limit)).
+		Delete(&model.MessageModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to soft-delete messages",
+			zap.Error(result.Error),
+			zap.String("status", status),
+		)
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *messageRepositoryGorm) PurgeOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id IN (?)", r.olderThan(r.db.WithContext(ctx).Unscoped(), status, createdBefore, limit)).
+		Delete(&model.MessageModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to purge messages",
+			zap.Error(result.Error),
+			zap.String("status", status),
+		)
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *messageRepositoryGorm) FindArchivable(ctx context.Context, statuses []string, createdBefore time.Time, limit int) ([]*entity.Message, error) {
+	var models []model.MessageModel
+
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("status IN ? AND created_at < ?", statuses, createdBefore).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to find archivable messages", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	return model.ToEntities(models, r.charLimit)
+}
+
+func (r *messageRepositoryGorm) PurgeByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id IN ?", ids).
+		Delete(&model.MessageModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to purge messages by ID",
+			zap.Error(result.Error),
+			zap.Int("count", len(ids)),
+		)
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
 }
 
-func (t *gormTransaction) GetContext() context.Context {
-	return t.ctx
+// olderThan selects the IDs of up to limit messages in status created before
+// createdBefore. The LIMIT in a subquery keeps each delete, and the locks it
+// takes, small.
+func (r *messageRepositoryGorm) olderThan(db *gorm.DB, status string, createdBefore time.Time, limit int) *gorm.DB {
+	return db.
+		Model(&model.MessageModel{}).
+		Select("id").
+		Where("status = ? AND created_at < ?", status, createdBefore).
+		Limit(limit)
 }
 
 func (r *messageRepositoryGorm) WithTx(tx *gorm.DB) repository.MessageRepository {
 	return &messageRepositoryGorm{
-		db:        tx,
-		charLimit: r.charLimit,
+		db:                 tx,
+		charLimit:          r.charLimit,
+		outbox:             r.outbox,
+		lowerPriorityShare: r.lowerPriorityShare,
 	}
 }
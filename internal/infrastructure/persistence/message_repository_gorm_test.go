@@ -0,0 +1,1002 @@
+package persistence_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/testsupport"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const charLimit = 160
+
+// newTestDB opens a fresh SQLite file with migrations/sqlite applied.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return newTestGormDB(t).DB()
+}
+
+// newTestGormDB opens a SQLite database in a temporary directory with all
+// migrations applied.
+func newTestGormDB(t *testing.T) *persistence.GormDB {
+	t.Helper()
+
+	db, err := persistence.NewSQLiteGormDB(&config.DatabaseConfig{
+		Driver:          config.DBDriverSQLite,
+		SQLitePath:      filepath.Join(t.TempDir(), "messaging.db"),
+		MaxOpenConns:    4,
+		MaxIdleConns:    4,
+		ConnMaxLifetime: time.Minute,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sqlDB, err := db.DB().DB()
+	assert.NoError(t, err)
+	driver, err := sqlite.WithInstance(sqlDB, &sqlite.Config{})
+	assert.NoError(t, err)
+	m, err := migrate.NewWithDatabaseInstance("file://../../../migrations/sqlite", "sqlite", driver)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, m.Up()) {
+		t.FailNow()
+	}
+
+	return db
+}
+
+func newTestRepository(t *testing.T, outbox bool) repository.MessageRepository {
+	return persistence.NewMessageRepositoryGorm(newTestDB(t), charLimit, outbox, 0.5)
+}
+
+func newTestMessage(t *testing.T, content string) *entity.Message {
+	t.Helper()
+
+	phone, err := valueobject.NewPhoneNumber("+905551234567")
+	assert.NoError(t, err)
+	messageContent, err := valueobject.NewMessageContent(content, charLimit)
+	assert.NoError(t, err)
+	message, err := entity.NewMessage(phone, messageContent, 3)
+	assert.NoError(t, err)
+	return message
+}
+
+func TestMessageRepositoryGorm_CreateAndFindByID(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, true)
+	ctx := context.Background()
+	message := newTestMessage(t, "Hello from SQLite")
+	message.AssignPriority(valueobject.MessagePriorityHigh)
+
+	// Act
+	err := repo.Create(ctx, message)
+	found, findErr := repo.FindByID(ctx, message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, findErr)
+	assert.Equal(t, message.ID(), found.ID())
+	assert.Equal(t, "Hello from SQLite", found.Content().String())
+	assert.Equal(t, message.ContentHash(), found.ContentHash())
+	assert.Equal(t, valueobject.MessagePriorityHigh, found.Priority())
+	assert.WithinDuration(t, message.CreatedAt(), found.CreatedAt(), time.Millisecond)
+}
+
+func TestMessageRepositoryGorm_FindByIDNotFound(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+
+	// Act
+	_, err := repo.FindByID(context.Background(), uuid.New())
+
+	// Assert
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+}
+
+func TestMessageRepositoryGorm_DuplicateIdempotencyKey(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	first := newTestMessage(t, "first")
+	first.AssignIdempotencyKey("order-42")
+	second := newTestMessage(t, "second")
+	second.AssignIdempotencyKey("order-42")
+	assert.NoError(t, repo.Create(ctx, first))
+
+	// Act
+	err := repo.Create(ctx, second)
+
+	// Assert
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+}
+
+func TestMessageRepositoryGorm_CreateBatchIsAllOrNothing(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, true)
+	ctx := context.Background()
+	first := newTestMessage(t, "first")
+	second := newTestMessage(t, "second")
+	clash := newTestMessage(t, "clash")
+	clash.AssignIdempotencyKey("order-42")
+	clashAgain := newTestMessage(t, "clash again")
+	clashAgain.AssignIdempotencyKey("order-42")
+
+	// Act
+	err := repo.CreateBatch(ctx, []*entity.Message{first, second})
+	clashErr := repo.CreateBatch(ctx, []*entity.Message{clash, clashAgain})
+	stats, statsErr := repo.GetStats(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, first.PendingEvents())
+	appErr, ok := clashErr.(*apperrors.AppError)
+	if assert.True(t, ok) {
+		assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+	}
+	assert.NoError(t, statsErr)
+	assert.Equal(t, int64(2), stats.TotalMessages)
+}
+
+func TestMessageRepositoryGorm_UpdateVersionConflict(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	message := newTestMessage(t, "contended")
+	assert.NoError(t, repo.Create(ctx, message))
+
+	first, _ := repo.FindByID(ctx, message.ID())
+	stale, _ := repo.FindByID(ctx, message.ID())
+	assert.NoError(t, first.MarkAsCancelled())
+	assert.NoError(t, repo.Update(ctx, first))
+
+	// Act
+	stale.MarkAsProcessing()
+	err := repo.Update(ctx, stale)
+
+	// Assert
+	assert.True(t, apperrors.IsVersionConflict(err))
+	found, _ := repo.FindByID(ctx, message.ID())
+	assert.Equal(t, valueobject.MessageStatusCancelled, found.Status())
+}
+
+func TestMessageRepositoryGorm_ClaimPendingMessagesByPriority(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, true)
+	ctx := context.Background()
+	low := newTestMessage(t, "low")
+	low.AssignPriority(valueobject.MessagePriorityLow)
+	high := newTestMessage(t, "high")
+	high.AssignPriority(valueobject.MessagePriorityHigh)
+	later := newTestMessage(t, "later")
+	later.ScheduleAt(time.Now().Add(time.Hour))
+	for _, message := range []*entity.Message{low, high, later} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	// Act
+	pending, err := repo.ClaimPendingMessages(ctx, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, high.ID(), pending[0].ID())
+		assert.Equal(t, low.ID(), pending[1].ID())
+	}
+}
+
+func TestMessageRepositoryGorm_ClaimPendingMessagesLowerPriorityShare(t *testing.T) {
+	testLowerPriorityShare(t, func(t *testing.T) repository.MessageRepository {
+		return newTestRepository(t, false)
+	})
+}
+
+// testLowerPriorityShare checks a repository built with a lower priority
+// share of 0.5 against the same cases, so every repository splits a batch
+// the same way.
+func testLowerPriorityShare(t *testing.T, newRepo func(t *testing.T) repository.MessageRepository) {
+	tests := []struct {
+		name       string
+		high       int
+		normal     int
+		wantHigh   int
+		wantNormal int
+	}{
+		{name: "share kept for normal", high: 6, normal: 6, wantHigh: 2, wantNormal: 2},
+		{name: "unused normal room goes to high", high: 6, normal: 1, wantHigh: 3, wantNormal: 1},
+		{name: "unused high room goes to normal", high: 1, normal: 6, wantHigh: 1, wantNormal: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := newRepo(t)
+			ctx := context.Background()
+			for i := 0; i < tt.high+tt.normal; i++ {
+				message := newTestMessage(t, fmt.Sprintf("message %d", i))
+				if i < tt.high {
+					message.AssignPriority(valueobject.MessagePriorityHigh)
+				}
+				assert.NoError(t, repo.Create(ctx, message))
+			}
+
+			// Act
+			claimed, err := repo.ClaimPendingMessages(ctx, 4)
+
+			// Assert
+			assert.NoError(t, err)
+			counts := make(map[valueobject.MessagePriority]int)
+			for _, message := range claimed {
+				counts[message.Priority()]++
+			}
+			assert.Equal(t, tt.wantHigh, counts[valueobject.MessagePriorityHigh])
+			assert.Equal(t, tt.wantNormal, counts[valueobject.MessagePriorityNormal])
+		})
+	}
+}
+
+func TestMessageRepositoryGorm_ClaimPendingMessagesWithClaimPriorities(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	low := newTestMessage(t, "low")
+	low.AssignPriority(valueobject.MessagePriorityLow)
+	high := newTestMessage(t, "high")
+	high.AssignPriority(valueobject.MessagePriorityHigh)
+	normal := newTestMessage(t, "normal")
+	for _, message := range []*entity.Message{low, high, normal} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	// Act
+	laneCtx := repository.WithClaimPriorities(ctx, []valueobject.MessagePriority{valueobject.MessagePriorityHigh})
+	lane, laneErr := repo.ClaimPendingMessages(laneCtx, 10)
+	mainCtx := repository.WithClaimPriorities(ctx, []valueobject.MessagePriority{valueobject.MessagePriorityNormal, valueobject.MessagePriorityLow})
+	main, mainErr := repo.ClaimPendingMessages(mainCtx, 10)
+
+	// Assert
+	assert.NoError(t, laneErr)
+	assert.NoError(t, mainErr)
+	if assert.Len(t, lane, 1) {
+		assert.Equal(t, high.ID(), lane[0].ID())
+	}
+	assert.Len(t, main, 2)
+}
+
+func TestMessageRepositoryGorm_ClaimPendingMessagesMarksProcessing(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	message := newTestMessage(t, "claimed")
+	assert.NoError(t, repo.Create(ctx, message))
+
+	// Act
+	first, firstErr := repo.ClaimPendingMessages(ctx, 10)
+	second, secondErr := repo.ClaimPendingMessages(ctx, 10)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Empty(t, second)
+	if assert.Len(t, first, 1) {
+		claimed := first[0]
+		assert.True(t, claimed.Status().IsProcessing())
+		assert.Equal(t, 1, claimed.Attempts())
+		assert.NotNil(t, claimed.ProcessingStartedAt())
+
+		// The claimed copy carries the new version, so the outcome can be stored
+		claimed.MarkAsSent("webhook-123", "{}")
+		assert.NoError(t, repo.Update(ctx, claimed))
+	}
+	found, _ := repo.FindByID(ctx, message.ID())
+	assert.Equal(t, valueobject.MessageStatusSent, found.Status())
+}
+
+func TestMessageRepositoryGorm_ClaimPendingMessagesSkipsPaused(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	paused := newTestMessage(t, "paused")
+	resumed := newTestMessage(t, "resumed")
+	for _, message := range []*entity.Message{paused, resumed} {
+		assert.NoError(t, repo.Create(ctx, message))
+		assert.NoError(t, message.Pause())
+		assert.NoError(t, repo.Update(ctx, message))
+	}
+	assert.NoError(t, resumed.Resume())
+	assert.NoError(t, repo.Update(ctx, resumed))
+
+	// Act
+	stats, statsErr := repo.GetStats(ctx)
+	pending, err := repo.ClaimPendingMessages(ctx, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, resumed.ID(), pending[0].ID())
+	}
+	assert.NoError(t, statsErr)
+	assert.Equal(t, int64(1), stats.PausedMessages)
+	assert.Equal(t, int64(1), stats.PendingMessages)
+}
+
+func TestMessageRepositoryGorm_GetStats(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	pending := newTestMessage(t, "pending")
+	cancelled := newTestMessage(t, "cancelled")
+	assert.NoError(t, repo.Create(ctx, pending))
+	assert.NoError(t, repo.Create(ctx, cancelled))
+	assert.NoError(t, cancelled.MarkAsCancelled())
+	assert.NoError(t, repo.Update(ctx, cancelled))
+
+	// Act
+	stats, err := repo.GetStats(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.TotalMessages)
+	assert.Equal(t, int64(1), stats.PendingMessages)
+	assert.Equal(t, int64(1), stats.CancelledMessages)
+}
+
+func TestMessageRepositoryGorm_GetSendPerformance(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for i, toSend := range []time.Duration{10 * time.Second, 20 * time.Second, 90 * time.Second} {
+		message := newTestMessage(t, fmt.Sprintf("sent %d", i))
+		assert.NoError(t, repo.Create(ctx, message))
+		message.MarkAsProcessing()
+		message.MarkAsSent(fmt.Sprintf("webhook-%d", i), "{}")
+		assert.NoError(t, repo.Update(ctx, message))
+		createdAt := now.Add(-30 * time.Minute)
+		assert.NoError(t, db.Exec("UPDATE messages SET created_at = ?, sent_at = ?, attempts = ? WHERE id = ?",
+			createdAt, createdAt.Add(toSend), i%2+1, message.ID()).Error)
+	}
+	failed := newTestMessage(t, "failed")
+	earlier := newTestMessage(t, "earlier")
+	assert.NoError(t, repo.Create(ctx, failed))
+	assert.NoError(t, repo.Create(ctx, earlier))
+	for _, message := range []*entity.Message{failed, earlier} {
+		message.MarkAsProcessing()
+		message.FailPermanently("boom", "INVALID_RECIPIENT")
+		assert.NoError(t, repo.Update(ctx, message))
+	}
+	assert.NoError(t, db.Exec("UPDATE messages SET created_at = ?, processing_started_at = ? WHERE id = ?",
+		now.Add(-2*time.Hour), now.Add(-2*time.Hour), earlier.ID()).Error)
+	assert.NoError(t, repo.Create(ctx, newTestMessage(t, "pending")))
+
+	// Act
+	performance, err := repo.GetSendPerformance(ctx, now.Add(-time.Hour))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &repository.SendPerformance{
+		SentMessages:  3,
+		AvgAttempts:   4.0 / 3,
+		AvgTimeToSend: 40 * time.Second,
+		P95TimeToSend: 90 * time.Second,
+		RecentSent:    3,
+		RecentFailed:  1,
+	}, performance)
+}
+
+func TestMessageRepositoryGorm_GetSendPerformanceCountsRecentOutcomes(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	sent := newTestMessage(t, "sent")
+	failed := newTestMessage(t, "failed")
+	for _, message := range []*entity.Message{sent, failed} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	sent.MarkAsProcessing()
+	sent.MarkAsSent("webhook-1", "{}")
+	failed.MarkAsProcessing()
+	failed.FailPermanently("boom", "INVALID_RECIPIENT")
+	for _, message := range []*entity.Message{sent, failed} {
+		assert.NoError(t, repo.Update(ctx, message))
+	}
+	// Created long before the window, finished within it
+	assert.NoError(t, db.Exec("UPDATE messages SET created_at = ?", now.Add(-3*time.Hour)).Error)
+
+	// Act
+	performance, err := repo.GetSendPerformance(ctx, now.Add(-time.Hour))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), performance.RecentSent)
+	assert.Equal(t, int64(1), performance.RecentFailed)
+}
+
+func TestMessageRepositoryGorm_GetStatsByCaller(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+	tenantID := testsupport.CreateTenant(ctx, t, persistence.NewTenantRepositoryGorm(db)).ID
+	for _, caller := range []string{"api_token", "api_token", "jwt:billing", ""} {
+		message := newTestMessage(t, "hello "+caller)
+		message.RecordCreator(caller)
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	failed := newTestMessage(t, "failed")
+	failed.RecordCreator("jwt:billing")
+	assert.NoError(t, repo.Create(ctx, failed))
+	failed.MarkAsProcessing()
+	failed.FailPermanently("boom", "INVALID_RECIPIENT")
+	assert.NoError(t, repo.Update(ctx, failed))
+	owned := newTestMessage(t, "owned")
+	owned.AssignTenant(tenantID)
+	owned.RecordCreator(actor.Tenant(tenantID))
+	assert.NoError(t, repo.Create(ctx, owned))
+
+	// Act
+	stats, err := repo.GetStatsByCaller(ctx)
+	tenantStats, tenantErr := repo.GetStatsByCaller(tenant.WithTenantID(ctx, tenantID))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.CallerStats{
+		{Caller: "api_token", MessageStats: repository.MessageStats{TotalMessages: 2, PendingMessages: 2}},
+		{Caller: "jwt:billing", MessageStats: repository.MessageStats{TotalMessages: 2, PendingMessages: 1, FailedMessages: 1}},
+		{Caller: "", MessageStats: repository.MessageStats{TotalMessages: 1, PendingMessages: 1}},
+		{Caller: actor.Tenant(tenantID), MessageStats: repository.MessageStats{TotalMessages: 1, PendingMessages: 1}},
+	}, stats)
+	assert.NoError(t, tenantErr)
+	assert.Equal(t, []repository.CallerStats{
+		{Caller: actor.Tenant(tenantID), MessageStats: repository.MessageStats{TotalMessages: 1, PendingMessages: 1}},
+	}, tenantStats)
+}
+
+func TestMessageRepositoryGorm_GetStatsTimeSeries(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+	hour := time.Now().UTC().Truncate(time.Hour)
+
+	sent := newTestMessage(t, "sent")
+	failed := newTestMessage(t, "failed")
+	earlier := newTestMessage(t, "earlier")
+	for _, message := range []*entity.Message{sent, failed, earlier} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	sent.MarkAsProcessing()
+	sent.MarkAsSent("webhook-1", "{}")
+	assert.NoError(t, repo.Update(ctx, sent))
+	for failed.Status().IsPending() {
+		failed.MarkAsProcessing()
+		failed.MarkAsFailed("provider timed out", "TIMEOUT")
+	}
+	assert.NoError(t, repo.Update(ctx, failed))
+	earlier.MarkAsFailed("provider timed out", "TIMEOUT")
+	assert.NoError(t, repo.Update(ctx, earlier))
+	assert.NoError(t, db.Exec("UPDATE messages SET created_at = ? WHERE id = ?", hour.Add(-90*time.Minute), earlier.ID()).Error)
+
+	// Act
+	series, err := repo.GetStatsTimeSeries(ctx, repository.StatsBucketHour, hour.Add(-2*time.Hour), hour.Add(time.Hour))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, series.Buckets, 2) {
+		assert.Equal(t, repository.MessageStatsBucket{Start: hour.Add(-2 * time.Hour), Created: 1}, series.Buckets[0])
+		assert.Equal(t, repository.MessageStatsBucket{Start: hour, Created: 2, Sent: 1, Failed: 1}, series.Buckets[1])
+	}
+	assert.Equal(t, []repository.ErrorCodeCount{{ErrorCode: "TIMEOUT", Count: 2}}, series.ErrorCodes)
+}
+
+func TestMessageRepositoryGorm_GetStatsTimeSeriesBucketsOutcomesWhenTheyHappen(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	repo := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+	hour := time.Now().UTC().Truncate(time.Hour)
+	previous := hour.Add(-time.Hour)
+
+	sent := newTestMessage(t, "sent")
+	failed := newTestMessage(t, "failed")
+	for _, message := range []*entity.Message{sent, failed} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	sent.MarkAsProcessing()
+	sent.MarkAsSent("webhook-1", "{}")
+	assert.NoError(t, repo.Update(ctx, sent))
+	failed.MarkAsProcessing()
+	failed.FailPermanently("invalid number", "INVALID_RECIPIENT")
+	assert.NoError(t, repo.Update(ctx, failed))
+	// Both were created in the previous hour and finished in this one
+	assert.NoError(t, db.Exec("UPDATE messages SET created_at = ?", previous.Add(30*time.Minute)).Error)
+
+	// Act
+	series, err := repo.GetStatsTimeSeries(ctx, repository.StatsBucketHour, previous, hour.Add(time.Hour))
+	previousOnly, previousErr := repo.GetStatsTimeSeries(ctx, repository.StatsBucketHour, previous, hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.MessageStatsBucket{
+		{Start: previous, Created: 2},
+		{Start: hour, Sent: 1, Failed: 1},
+	}, series.Buckets)
+	assert.Equal(t, []repository.ErrorCodeCount{{ErrorCode: "INVALID_RECIPIENT", Count: 1}}, series.ErrorCodes)
+	assert.NoError(t, previousErr)
+	assert.Equal(t, []repository.MessageStatsBucket{{Start: previous, Created: 2}}, previousOnly.Buckets)
+	assert.Empty(t, previousOnly.ErrorCodes)
+}
+
+func TestMessageRepositoryGorm_FindDuplicateContent(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	first := newTestMessage(t, "Your order has shipped")
+	second := newTestMessage(t, "Your order has shipped")
+	third := newTestMessage(t, "Your order has shipped")
+	unique := newTestMessage(t, "Your order was delivered")
+	for _, message := range []*entity.Message{first, second, third, unique} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	// Act
+	duplicates, err := repo.FindDuplicateContent(ctx, now.Add(-time.Hour), now.Add(time.Hour), 2, 10)
+	otherTenant, otherErr := repo.FindDuplicateContent(tenant.WithTenantID(ctx, uuid.New()), now.Add(-time.Hour), now.Add(time.Hour), 2, 10)
+	tooFew, tooFewErr := repo.FindDuplicateContent(ctx, now.Add(-time.Hour), now.Add(time.Hour), 4, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, duplicates, 1) {
+		d := duplicates[0]
+		assert.Equal(t, "sms", d.Channel)
+		assert.Equal(t, "+905551234567", d.Recipient)
+		assert.Equal(t, first.Content().Hash(), d.ContentHash)
+		assert.Equal(t, "Your order has shipped", d.Content)
+		assert.Equal(t, int64(3), d.Count)
+		assert.WithinDuration(t, now, d.FirstCreatedAt, time.Minute)
+		assert.False(t, d.LastCreatedAt.Before(d.FirstCreatedAt))
+	}
+	assert.NoError(t, otherErr)
+	assert.Empty(t, otherTenant)
+	assert.NoError(t, tooFewErr)
+	assert.Empty(t, tooFew)
+}
+
+func TestMessageRepositoryGorm_GetDeliveryByPrefix(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	newMessageTo := func(number string) *entity.Message {
+		phone, err := valueobject.NewPhoneNumber(number)
+		assert.NoError(t, err)
+		content, _ := valueobject.NewMessageContent("Your code is 123456", charLimit)
+		message, err := entity.NewMessage(phone, content, 3)
+		assert.NoError(t, err)
+		return message
+	}
+	sent := newMessageTo("+905551234567")
+	failed := newMessageTo("+905559876543")
+	pending := newMessageTo("+905551112233")
+	other := newMessageTo("+905321234567")
+	for _, message := range []*entity.Message{sent, failed, pending, other} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	sent.MarkAsProcessing()
+	sent.MarkAsSent("webhook-1", "{}")
+	failed.MarkAsProcessing()
+	failed.FailPermanently("invalid number", "INVALID_RECIPIENT")
+	for _, message := range []*entity.Message{sent, failed} {
+		assert.NoError(t, repo.Update(ctx, message))
+	}
+
+	// Act
+	deliveries, err := repo.GetDeliveryByPrefix(ctx, now.Add(-time.Hour), now.Add(time.Hour), 6)
+	otherTenant, otherErr := repo.GetDeliveryByPrefix(tenant.WithTenantID(ctx, uuid.New()), now.Add(-time.Hour), now.Add(time.Hour), 6)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []repository.PrefixDelivery{
+		{Prefix: "+90532", Sample: "+905321234567", Total: 1},
+		{Prefix: "+90555", Sample: "+905551112233", Total: 3, Sent: 1, Failed: 1},
+	}, deliveries)
+	assert.NoError(t, otherErr)
+	assert.Empty(t, otherTenant)
+}
+
+func TestMessageRepositoryGorm_ExpiredMessage(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	message := newTestMessage(t, "Your code is 123456")
+	expiresAt := time.Now().Add(time.Minute)
+	message.ExpireAt(expiresAt)
+	assert.NoError(t, repo.Create(ctx, message))
+	assert.NoError(t, message.Expire("message expired before it was sent", "MESSAGE_EXPIRED"))
+	assert.NoError(t, repo.Update(ctx, message))
+
+	// Act
+	found, findErr := repo.FindByID(ctx, message.ID())
+	stats, statsErr := repo.GetStats(ctx)
+
+	// Assert
+	assert.NoError(t, findErr)
+	assert.NoError(t, statsErr)
+	assert.True(t, found.Status().IsExpired())
+	if assert.NotNil(t, found.ExpiresAt()) {
+		assert.WithinDuration(t, expiresAt, *found.ExpiresAt(), time.Millisecond)
+	}
+	assert.Equal(t, int64(1), stats.ExpiredMessages)
+	assert.Zero(t, stats.PendingMessages)
+}
+
+func TestMessageRepositoryGorm_DryRunMessage(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	message := newTestMessage(t, "Dry run")
+	message.MarkDryRun()
+	assert.NoError(t, repo.Create(ctx, message))
+	message.MarkAsProcessing()
+	message.MarkAsSimulated("simulated-"+message.ID().String(), `{"simulated":true}`)
+	assert.NoError(t, repo.Update(ctx, message))
+
+	// Act
+	found, err := repo.FindByID(ctx, message.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, found.DryRun())
+	assert.True(t, found.Simulated())
+	assert.True(t, found.Status().IsSent())
+}
+
+func TestMessageRepositoryGorm_EmailMessage(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	recipient, _ := valueobject.NewRecipient(valueobject.ChannelEmail, "ada@example.com")
+	content, _ := valueobject.NewMessageContent("Your invoice is ready", 100000)
+	email, _ := entity.NewMessageTo(uuid.New(), recipient, content, 3)
+	sms := newTestMessage(t, "sms")
+	for _, message := range []*entity.Message{email, sms} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	// Act
+	found, findErr := repo.FindByID(ctx, email.ID())
+	byChannel, total, filterErr := repo.FindByFilter(ctx, repository.MessageFilter{Channel: "email"}, 10, 0)
+
+	// Assert
+	assert.NoError(t, findErr)
+	assert.Equal(t, valueobject.ChannelEmail, found.Channel())
+	assert.Equal(t, "ada@example.com", found.Recipient().String())
+	assert.Nil(t, found.PhoneNumber())
+	assert.NoError(t, filterErr)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, byChannel, 1) {
+		assert.Equal(t, email.ID(), byChannel[0].ID())
+	}
+}
+
+func TestMessageRepositoryGorm_FindByFilterMetadata(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	spring := newTestMessage(t, "spring")
+	spring.AssignMetadata(valueobject.MessageMetadata{"campaign": "spring-sale", "segment": "vip"})
+	autumn := newTestMessage(t, "autumn")
+	autumn.AssignMetadata(valueobject.MessageMetadata{"campaign": "autumn-sale"})
+	plain := newTestMessage(t, "plain")
+	for _, message := range []*entity.Message{spring, autumn, plain} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	// Act
+	byCampaign, total, err := repo.FindByFilter(ctx, repository.MessageFilter{
+		Metadata: map[string]string{"campaign": "spring-sale", "segment": "vip"},
+	}, 10, 0)
+	none, noneTotal, noneErr := repo.FindByFilter(ctx, repository.MessageFilter{
+		Metadata: map[string]string{"campaign": "spring-sale", "segment": "new"},
+	}, 10, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, byCampaign, 1) {
+		assert.Equal(t, spring.ID(), byCampaign[0].ID())
+		assert.Equal(t, valueobject.MessageMetadata{"campaign": "spring-sale", "segment": "vip"}, byCampaign[0].Metadata())
+	}
+	assert.NoError(t, noneErr)
+	assert.Zero(t, noneTotal)
+	assert.Empty(t, none)
+}
+
+func TestMessageRepositoryGorm_FindByFilterSplitGroup(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	groupID := uuid.New()
+	first := newTestMessage(t, "(1/2) first half")
+	first.AssignPart(&valueobject.MessagePart{GroupID: groupID, Number: 1, Count: 2})
+	second := newTestMessage(t, "(2/2) second half")
+	second.AssignPart(&valueobject.MessagePart{GroupID: groupID, Number: 2, Count: 2})
+	whole := newTestMessage(t, "whole")
+	assert.NoError(t, repo.CreateBatch(ctx, []*entity.Message{first, second, whole}))
+
+	// Act
+	parts, total, err := repo.FindByFilter(ctx, repository.MessageFilter{SplitGroupID: groupID}, 10, 0)
+	stored, findErr := repo.FindByID(ctx, whole.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	found := map[uuid.UUID]*valueobject.MessagePart{}
+	for _, message := range parts {
+		found[message.ID()] = message.Part()
+	}
+	assert.Equal(t, map[uuid.UUID]*valueobject.MessagePart{
+		first.ID():  {GroupID: groupID, Number: 1, Count: 2},
+		second.ID(): {GroupID: groupID, Number: 2, Count: 2},
+	}, found)
+	assert.NoError(t, findErr)
+	assert.Nil(t, stored.Part())
+}
+
+func TestMessageRepositoryGorm_FindByFilterProviderResponse(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	twilio := newTestMessage(t, "twilio")
+	legacy := newTestMessage(t, "legacy")
+	for _, message := range []*entity.Message{twilio, legacy} {
+		assert.NoError(t, repo.Create(ctx, message))
+		message.MarkAsProcessing()
+	}
+	twilio.MarkAsSent("SM123", `{"sid":"SM123","status":"queued"}`)
+	legacy.MarkAsSent("webhook-1", `{"message": "say "hi"", "messageId": "webhook-1"}`)
+	assert.NoError(t, repo.Update(ctx, twilio))
+	assert.NoError(t, repo.Update(ctx, legacy))
+
+	// Act
+	bySID, total, err := repo.FindByFilter(ctx, repository.MessageFilter{
+		ProviderResponse: map[string]string{"sid": "SM123"},
+	}, 10, 0)
+	stored, findErr := repo.FindByID(ctx, legacy.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, bySID, 1) {
+		assert.Equal(t, twilio.ID(), bySID[0].ID())
+		assert.JSONEq(t, `{"sid":"SM123","status":"queued"}`, bySID[0].WebhookResponse())
+	}
+	assert.NoError(t, findErr)
+	assert.JSONEq(t, `{"raw":"{\"message\": \"say \"hi\"\", \"messageId\": \"webhook-1\"}"}`, stored.WebhookResponse())
+}
+
+func TestMessageRepositoryGorm_FindByFilterAfterPagesInOrder(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	var pending []uuid.UUID
+	for i := 0; i < 5; i++ {
+		message := newTestMessage(t, fmt.Sprintf("message %d", i))
+		assert.NoError(t, repo.Create(ctx, message))
+		pending = append(pending, message.ID())
+	}
+	cancelled := newTestMessage(t, "cancelled")
+	assert.NoError(t, cancelled.MarkAsCancelled())
+	assert.NoError(t, repo.Create(ctx, cancelled))
+
+	// Act
+	var exported []uuid.UUID
+	var after *repository.MessageCursor
+	pages := 0
+	for {
+		page, err := repo.FindByFilterAfter(ctx, repository.MessageFilter{Status: "pending"}, after, 2)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		pages++
+		for _, message := range page {
+			exported = append(exported, message.ID())
+		}
+		after = repository.CursorOf(page[len(page)-1])
+	}
+
+	// Assert
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, pending, exported)
+}
+
+func TestMessageRepositoryGorm_FindByWebhookMessageIDIsTenantScoped(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	sent := newTestMessage(t, "sent")
+	sent.MarkAsSent("webhook-1", "{}")
+	assert.NoError(t, repo.Create(ctx, sent))
+
+	// Act
+	found, findErr := repo.FindByWebhookMessageID(ctx, "webhook-1")
+	_, otherTenantErr := repo.FindByWebhookMessageID(tenant.WithTenantID(ctx, uuid.New()), "webhook-1")
+	_, unknownErr := repo.FindByWebhookMessageID(ctx, "webhook-2")
+
+	// Assert
+	assert.NoError(t, findErr)
+	assert.Equal(t, sent.ID(), found.ID())
+	for _, err := range []error{otherTenantErr, unknownErr} {
+		appErr, ok := err.(*apperrors.AppError)
+		if assert.True(t, ok) {
+			assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+		}
+	}
+}
+
+func TestMessageRepositoryGorm_SoftDeleteAndPurge(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	sent := newTestMessage(t, "sent")
+	sent.MarkAsSent("webhook-1", "{}")
+	cancelledFirst := newTestMessage(t, "cancelled first")
+	assert.NoError(t, cancelledFirst.MarkAsCancelled())
+	cancelledSecond := newTestMessage(t, "cancelled second")
+	assert.NoError(t, cancelledSecond.MarkAsCancelled())
+	pending := newTestMessage(t, "pending")
+	for _, message := range []*entity.Message{sent, cancelledFirst, cancelledSecond, pending} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	cutoff := time.Now().Add(time.Hour)
+
+	// Act
+	softDeleted, softErr := repo.SoftDeleteOlderThan(ctx, "sent", cutoff, 10)
+	_, findErr := repo.FindByID(ctx, sent.ID())
+	_, byWebhookErr := repo.FindByWebhookMessageID(ctx, "webhook-1")
+	stats, statsErr := repo.GetStats(ctx)
+	listed, total, listErr := repo.FindByFilter(ctx, repository.MessageFilter{}, 10, 0)
+	again, againErr := repo.SoftDeleteOlderThan(ctx, "sent", cutoff, 10)
+
+	purgedSent, purgeSentErr := repo.PurgeOlderThan(ctx, "sent", cutoff, 10)
+	purgedCancelled, purgeCancelledErr := repo.PurgeOlderThan(ctx, "cancelled", cutoff, 1)
+	remaining, _, remainingErr := repo.FindByFilter(ctx, repository.MessageFilter{}, 10, 0)
+
+	// Assert
+	assert.NoError(t, softErr)
+	assert.Equal(t, int64(1), softDeleted)
+	for _, err := range []error{findErr, byWebhookErr} {
+		appErr, ok := err.(*apperrors.AppError)
+		if assert.True(t, ok) {
+			assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+		}
+	}
+	assert.NoError(t, statsErr)
+	assert.Equal(t, int64(3), stats.TotalMessages)
+	assert.Zero(t, stats.SentMessages)
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, listed, 3)
+	assert.NoError(t, againErr)
+	assert.Zero(t, again)
+
+	assert.NoError(t, purgeSentErr)
+	assert.Equal(t, int64(1), purgedSent)
+	assert.NoError(t, purgeCancelledErr)
+	assert.Equal(t, int64(1), purgedCancelled)
+	assert.NoError(t, remainingErr)
+	assert.Len(t, remaining, 2)
+}
+
+func TestMessageRepositoryGorm_FindArchivableAndPurgeByIDs(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	sent := newTestMessage(t, "sent")
+	sent.MarkAsSent("webhook-1", "{}")
+	cancelled := newTestMessage(t, "cancelled")
+	assert.NoError(t, cancelled.MarkAsCancelled())
+	deleted := newTestMessage(t, "deleted")
+	deleted.MarkAsSent("webhook-2", "{}")
+	pending := newTestMessage(t, "pending")
+	for _, message := range []*entity.Message{sent, cancelled, deleted, pending} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+	cutoff := time.Now().Add(time.Hour)
+	// Soft-deleted messages are archived all the same
+	_, err := repo.SoftDeleteOlderThan(ctx, "sent", cutoff, 10)
+	assert.NoError(t, err)
+
+	// Act
+	archivable, findErr := repo.FindArchivable(ctx, []string{"sent", "cancelled"}, cutoff, 10)
+	firstOnly, firstErr := repo.FindArchivable(ctx, []string{"sent", "cancelled"}, cutoff, 1)
+	none, noneErr := repo.FindArchivable(ctx, []string{"sent"}, time.Now().Add(-time.Hour), 10)
+	purged, purgeErr := repo.PurgeByIDs(ctx, []uuid.UUID{sent.ID(), deleted.ID()})
+	remaining, _, remainingErr := repo.FindByFilter(ctx, repository.MessageFilter{}, 10, 0)
+
+	// Assert
+	assert.NoError(t, findErr)
+	ids := make([]uuid.UUID, len(archivable))
+	for i, message := range archivable {
+		ids[i] = message.ID()
+	}
+	assert.Equal(t, []uuid.UUID{sent.ID(), cancelled.ID(), deleted.ID()}, ids)
+	assert.NoError(t, firstErr)
+	if assert.Len(t, firstOnly, 1) {
+		assert.Equal(t, sent.ID(), firstOnly[0].ID())
+	}
+	assert.NoError(t, noneErr)
+	assert.Empty(t, none)
+	assert.NoError(t, purgeErr)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, remainingErr)
+	assert.Len(t, remaining, 2)
+}
+
+func TestMessageRepositoryGorm_SearchContent(t *testing.T) {
+	// Arrange
+	repo := newTestRepository(t, false)
+	ctx := context.Background()
+	shipped := newTestMessage(t, "Your order has shipped")
+	refunded := newTestMessage(t, "Your order was refunded")
+	wildcard := newTestMessage(t, "Save 50% today")
+	for _, message := range []*entity.Message{shipped, refunded, wildcard} {
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	search := func(raw string) ([]*entity.Message, int64, error) {
+		q, err := valueobject.NewSearchQuery(raw)
+		assert.NoError(t, err)
+		return repo.SearchContent(ctx, q, 10, 0)
+	}
+
+	// Act
+	orders, ordersTotal, ordersErr := search("ORDER")
+	notRefunded, _, notRefundedErr := search("order -refunded")
+	percent, percentTotal, percentErr := search("0%")
+
+	// Assert
+	assert.NoError(t, ordersErr)
+	assert.Equal(t, int64(2), ordersTotal)
+	assert.Len(t, orders, 2)
+	assert.NoError(t, notRefundedErr)
+	if assert.Len(t, notRefunded, 1) {
+		assert.Equal(t, shipped.ID(), notRefunded[0].ID())
+	}
+	assert.NoError(t, percentErr)
+	assert.Equal(t, int64(1), percentTotal)
+	if assert.Len(t, percent, 1) {
+		assert.Equal(t, wildcard.ID(), percent[0].ID())
+	}
+}
@@ -0,0 +1,311 @@
+//go:build integration
+
+package persistence_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/testsupport"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// postgresRepositories builds each message repository on a PostgreSQL
+// database.
+var postgresRepositories = map[string]func(t *testing.T, db *persistence.GormDB) repository.MessageRepository{
+	"gorm": func(_ *testing.T, db *persistence.GormDB) repository.MessageRepository {
+		return persistence.NewMessageRepositoryGorm(db.DB(), testsupport.CharLimit, false, 0.5)
+	},
+	"postgres": func(t *testing.T, db *persistence.GormDB) repository.MessageRepository {
+		return persistence.NewMessageRepositoryPostgres(testsupport.SQLDB(t, db), testsupport.CharLimit, 0.5)
+	},
+}
+
+// forEachPostgresRepository runs test against both message repositories,
+// each on a database of its own.
+func forEachPostgresRepository(t *testing.T, test func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository)) {
+	for name, newRepository := range postgresRepositories {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			db := testsupport.Postgres(t)
+			test(t, db, newRepository(t, db))
+		})
+	}
+}
+
+func TestIntegration_ClaimPendingMessages_ConcurrentClaimsSkipLockedRows(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, _ *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		created := testsupport.NewMessage(t).CreateMany(ctx, repo, 40)
+
+		// Act - workers claim until nothing is left, as scheduler instances do
+		var mu sync.Mutex
+		claims := make(map[uuid.UUID]int)
+		var wg sync.WaitGroup
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					claimed, err := repo.ClaimPendingMessages(ctx, 5)
+					if !assert.NoError(t, err) || len(claimed) == 0 {
+						return
+					}
+					mu.Lock()
+					for _, message := range claimed {
+						assert.Equal(t, valueobject.MessageStatusProcessing, message.Status())
+						assert.Equal(t, 1, message.Attempts())
+						claims[message.ID()]++
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assert.Len(t, claims, len(created))
+		for _, message := range created {
+			assert.Equal(t, 1, claims[message.ID()], "message %s claimed more than once", message.ID())
+		}
+	})
+}
+
+// The SQLite repository runs the same cases in
+// TestMessageRepositoryGorm_ClaimPendingMessagesLowerPriorityShare.
+func TestIntegration_ClaimPendingMessages_LowerPriorityShare(t *testing.T) {
+	for name, newRepository := range postgresRepositories {
+		t.Run(name, func(t *testing.T) {
+			testLowerPriorityShare(t, func(t *testing.T) repository.MessageRepository {
+				return newRepository(t, testsupport.Postgres(t))
+			})
+		})
+	}
+}
+
+func TestIntegration_ClaimPendingMessages_LeavesFutureAndOtherStatuses(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, _ *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		due := testsupport.NewMessage(t).Create(ctx, repo)
+		testsupport.NewMessage(t).ScheduledAt(time.Now().Add(24*time.Hour)).Create(ctx, repo)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).Create(ctx, repo)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusPaused).Create(ctx, repo)
+
+		// Act
+		claimed, err := repo.ClaimPendingMessages(ctx, 10)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, claimed, 1) {
+			assert.Equal(t, due.ID(), claimed[0].ID())
+		}
+	})
+}
+
+func TestIntegration_Update_OptimisticLocking(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, _ *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		created := testsupport.NewMessage(t).Create(ctx, repo)
+		first, err := repo.FindByID(ctx, created.ID())
+		assert.NoError(t, err)
+		second, err := repo.FindByID(ctx, created.ID())
+		assert.NoError(t, err)
+
+		// Act
+		first.MarkAsProcessing()
+		firstErr := repo.Update(ctx, first)
+		assert.NoError(t, second.MarkAsCancelled())
+		secondErr := repo.Update(ctx, second)
+		missing := testsupport.NewMessage(t).Build()
+		missingErr := repo.Update(ctx, missing)
+
+		// Assert
+		assert.NoError(t, firstErr)
+		assert.Equal(t, created.Version()+1, first.Version())
+		assertErrorCode(t, apperrors.ErrorCodeVersionConflict, secondErr)
+		assertErrorCode(t, apperrors.ErrorCodeNotFound, missingErr)
+
+		stored, err := repo.FindByID(ctx, created.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.MessageStatusProcessing, stored.Status())
+		assert.Equal(t, first.Version(), stored.Version())
+	})
+}
+
+func TestIntegration_GetStats(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		owner := testsupport.CreateTenant(ctx, t, persistence.NewTenantRepositoryGorm(db.DB()))
+
+		testsupport.NewMessage(t).CreateMany(ctx, repo, 3)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreateMany(ctx, repo, 2)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusDelivered).Create(ctx, repo)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusFailed).Tenant(owner.ID).CreateMany(ctx, repo, 2)
+		testsupport.NewMessage(t).Status(valueobject.MessageStatusCancelled).Tenant(owner.ID).Create(ctx, repo)
+
+		// Act
+		stats, err := repo.GetStats(ctx)
+		tenantStats, tenantErr := repo.GetStats(tenant.WithTenantID(ctx, owner.ID))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, repository.MessageStats{
+			TotalMessages:     9,
+			PendingMessages:   3,
+			SentMessages:      2,
+			DeliveredMessages: 1,
+			FailedMessages:    2,
+			CancelledMessages: 1,
+		}, *stats)
+
+		assert.NoError(t, tenantErr)
+		assert.Equal(t, repository.MessageStats{
+			TotalMessages:     3,
+			FailedMessages:    2,
+			CancelledMessages: 1,
+		}, *tenantStats)
+	})
+}
+
+func TestIntegration_GetSendPerformance(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		now := time.Now().UTC()
+
+		// Insert leaves the outcome of a send to Update
+		for _, message := range []*entity.Message{
+			testsupport.NewMessage(t).Status(valueobject.MessageStatusSent).CreatedAt(now.Add(-30*time.Minute)).Attempts(1, 3).Create(ctx, repo),
+			testsupport.NewMessage(t).Status(valueobject.MessageStatusDelivered).CreatedAt(now.Add(-20*time.Minute)).Attempts(3, 3).Create(ctx, repo),
+			testsupport.NewMessage(t).Status(valueobject.MessageStatusFailed).CreatedAt(now.Add(-10*time.Minute)).Attempts(3, 3).Create(ctx, repo),
+			testsupport.NewMessage(t).Status(valueobject.MessageStatusFailed).CreatedAt(now.Add(-2*time.Hour)).Attempts(3, 3).Create(ctx, repo),
+		} {
+			assert.NoError(t, repo.Update(ctx, message))
+		}
+		testsupport.NewMessage(t).Create(ctx, repo)
+
+		// Act
+		performance, err := repo.GetSendPerformance(ctx, now.Add(-time.Hour))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &repository.SendPerformance{
+			SentMessages:  2,
+			AvgAttempts:   2,
+			AvgTimeToSend: time.Second,
+			P95TimeToSend: time.Second,
+			RecentSent:    2,
+			RecentFailed:  1,
+		}, performance)
+	})
+}
+
+func TestIntegration_GetSendPerformance_CountsRecentOutcomes(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		now := time.Now().UTC()
+
+		sent := testsupport.NewMessage(t).CreatedAt(now.Add(-3*time.Hour)).Create(ctx, repo)
+		failed := testsupport.NewMessage(t).CreatedAt(now.Add(-3*time.Hour)).Create(ctx, repo)
+		sent.MarkAsProcessing()
+		sent.MarkAsSent("webhook-1", "{}")
+		failed.MarkAsProcessing()
+		failed.FailPermanently("boom", "INVALID_RECIPIENT")
+		for _, message := range []*entity.Message{sent, failed} {
+			assert.NoError(t, repo.Update(ctx, message))
+		}
+
+		// Act
+		performance, err := repo.GetSendPerformance(ctx, now.Add(-time.Hour))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), performance.RecentSent)
+		assert.Equal(t, int64(1), performance.RecentFailed)
+	})
+}
+
+func TestIntegration_GetStatsTimeSeries_BucketsOutcomesWhenTheyHappen(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		hour := time.Now().UTC().Truncate(time.Hour)
+		previous := hour.Add(-time.Hour)
+
+		sent := testsupport.NewMessage(t).CreatedAt(previous.Add(30*time.Minute)).Create(ctx, repo)
+		failed := testsupport.NewMessage(t).CreatedAt(previous.Add(30*time.Minute)).Create(ctx, repo)
+		sent.MarkAsProcessing()
+		sent.MarkAsSent("webhook-1", "{}")
+		failed.MarkAsProcessing()
+		failed.FailPermanently("boom", "INVALID_RECIPIENT")
+		for _, message := range []*entity.Message{sent, failed} {
+			assert.NoError(t, repo.Update(ctx, message))
+		}
+
+		// Act
+		series, err := repo.GetStatsTimeSeries(ctx, repository.StatsBucketHour, previous, hour.Add(time.Hour))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []repository.MessageStatsBucket{
+			{Start: previous, Created: 2},
+			{Start: hour, Sent: 1, Failed: 1},
+		}, series.Buckets)
+		assert.Equal(t, []repository.ErrorCodeCount{{ErrorCode: "INVALID_RECIPIENT", Count: 1}}, series.ErrorCodes)
+	})
+}
+
+func TestIntegration_GetStatsByCaller(t *testing.T) {
+	forEachPostgresRepository(t, func(t *testing.T, db *persistence.GormDB, repo repository.MessageRepository) {
+		// Arrange
+		ctx := context.Background()
+		owner := testsupport.CreateTenant(ctx, t, persistence.NewTenantRepositoryGorm(db.DB()))
+
+		testsupport.NewMessage(t).CreatedBy(actor.APIToken).CreateMany(ctx, repo, 3)
+		testsupport.NewMessage(t).CreatedBy(actor.Token("billing")).Status(valueobject.MessageStatusFailed).Create(ctx, repo)
+		testsupport.NewMessage(t).Create(ctx, repo)
+		testsupport.NewMessage(t).CreatedBy(actor.Tenant(owner.ID)).Tenant(owner.ID).CreateMany(ctx, repo, 2)
+
+		// Act
+		stats, err := repo.GetStatsByCaller(ctx)
+		tenantStats, tenantErr := repo.GetStatsByCaller(tenant.WithTenantID(ctx, owner.ID))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []repository.CallerStats{
+			{Caller: actor.APIToken, MessageStats: repository.MessageStats{TotalMessages: 3, PendingMessages: 3}},
+			{Caller: actor.Tenant(owner.ID), MessageStats: repository.MessageStats{TotalMessages: 2, PendingMessages: 2}},
+			{Caller: "", MessageStats: repository.MessageStats{TotalMessages: 1, PendingMessages: 1}},
+			{Caller: actor.Token("billing"), MessageStats: repository.MessageStats{TotalMessages: 1, FailedMessages: 1}},
+		}, stats)
+
+		assert.NoError(t, tenantErr)
+		assert.Equal(t, []repository.CallerStats{
+			{Caller: actor.Tenant(owner.ID), MessageStats: repository.MessageStats{TotalMessages: 2, PendingMessages: 2}},
+		}, tenantStats)
+	})
+}
+
+func assertErrorCode(t *testing.T, code apperrors.ErrorCode, err error) {
+	t.Helper()
+
+	appErr, ok := err.(*apperrors.AppError)
+	if assert.True(t, ok, "expected an AppError, got %v", err) {
+		assert.Equal(t, code, appErr.Code)
+	}
+}
@@ -3,53 +3,129 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
 	"github.com/eneskaya/insider-messaging/internal/domain/repository"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
 	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+// uniqueViolation is the Postgres SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
 type messageRepositoryPostgres struct {
-	db        *sql.DB
-	charLimit int
+	db                 *sql.DB
+	charLimit          int
+	lowerPriorityShare float64
 }
 
-func NewMessageRepositoryPostgres(db *sql.DB, charLimit int) repository.MessageRepository {
+// NewMessageRepositoryPostgres keeps lowerPriorityShare of each pending
+// batch for normal and low priority messages, as NewMessageRepositoryGorm
+// does.
+func NewMessageRepositoryPostgres(db *sql.DB, charLimit int, lowerPriorityShare float64) repository.MessageRepository {
 	return &messageRepositoryPostgres{
-		db:        db,
-		charLimit: charLimit,
+		db:                 db,
+		charLimit:          charLimit,
+		lowerPriorityShare: lowerPriorityShare,
 	}
 }
 
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 func (r *messageRepositoryPostgres) Create(ctx context.Context, message *entity.Message) error {
+	return r.insert(ctx, r.db, message)
+}
+
+func (r *messageRepositoryPostgres) CreateBatch(ctx context.Context, messages []*entity.Message) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return apperrors.NewDatabaseError(err)
+	}
+
+	for _, message := range messages {
+		if err := r.insert(ctx, tx, message); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.NewDatabaseError(err)
+	}
+	return nil
+}
+
+func (r *messageRepositoryPostgres) insert(ctx context.Context, db execer, message *entity.Message) error {
+	metadataJSON, err := marshalMetadata(message.Metadata())
+	if err != nil {
+		return apperrors.NewInternalError(err)
+	}
+
 	query := `
 		INSERT INTO messages (
-			id, phone_number, content, status, created_at,
-			attempts, max_attempts, version
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			id, channel, phone_number, recipient, content, content_hash, status, created_at,
+			scheduled_at, expires_at, attempts, max_attempts, idempotency_key, priority, tenant_id, trace_id, metadata, campaign_id, destination_id,
+			split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)
 	`
 
-	_, err := r.db.ExecContext(
+	var splitGroupID uuid.UUID
+	var partNumber, partCount int
+	if part := message.Part(); part != nil {
+		splitGroupID, partNumber, partCount = part.GroupID, part.Number, part.Count
+	}
+
+	_, err = db.ExecContext(
 		ctx,
 		query,
 		message.ID(),
-		message.PhoneNumber().String(),
+		message.Channel().String(),
+		model.PhoneNumberColumn(message.Recipient()),
+		model.RecipientColumn(message.Recipient()),
 		message.Content().String(),
+		message.ContentHash(),
 		message.Status().String(),
 		message.CreatedAt(),
+		message.ScheduledAt(),
+		message.ExpiresAt(),
 		message.Attempts(),
 		message.MaxAttempts(),
+		nullString(message.IdempotencyKey()),
+		message.Priority().Rank(),
+		nullUUID(message.TenantID()),
+		nullString(message.TraceID()),
+		metadataJSON,
+		nullUUID(message.CampaignID()),
+		nullUUID(message.DestinationID()),
+		nullUUID(splitGroupID),
+		partNumber,
+		partCount,
+		nullString(message.CreatedBy()),
+		message.DryRun(),
+		message.Simulated(),
+		nullString(message.CallbackURL()),
 		message.Version(),
 	)
 
 	if err != nil {
-		logger.Get().Error("failed to create message",
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return apperrors.New(apperrors.ErrorCodeAlreadyExists, "duplicate record")
+		}
+		logger.FromContext(ctx).Error("failed to create message",
 			zap.Error(err),
 			zap.String("message_id", message.ID().String()),
 		)
@@ -64,32 +140,45 @@ func (r *messageRepositoryPostgres) Update(ctx context.Context, message *entity.
 		UPDATE messages SET
 			status = $1,
 			sent_at = $2,
-			attempts = $3,
-			last_error = $4,
-			error_code = $5,
-			webhook_message_id = $6,
-			webhook_response = $7,
-			version = $8
-		WHERE id = $9 AND version = $10
+			delivered_at = $3,
+			next_retry_at = $4,
+			processing_started_at = $5,
+			attempts = $6,
+			last_error = $7,
+			error_code = $8,
+			webhook_message_id = $9,
+			webhook_response = $10,
+			trace_id = $11,
+			provider_request_id = $12,
+			simulated = $13,
+			version = $14
+		WHERE id = $15 AND version = $16 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
+	args := []interface{}{
 		message.Status().String(),
 		message.SentAt(),
+		message.DeliveredAt(),
+		message.NextRetryAt(),
+		message.ProcessingStartedAt(),
 		message.Attempts(),
 		message.LastError(),
 		message.ErrorCode(),
 		message.WebhookMessageID(),
-		message.WebhookResponse(),
-		message.Version()+1,
+		model.WebhookResponseColumn(message.WebhookResponse()),
+		message.TraceID(),
+		message.ProviderRequestID(),
+		message.Simulated(),
+		message.Version() + 1,
 		message.ID(),
 		message.Version(),
-	)
+	}
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", args)
+
+	result, err := r.db.ExecContext(ctx, query+tenantFilter, args...)
 
 	if err != nil {
-		logger.Get().Error("failed to update message",
+		logger.FromContext(ctx).Error("failed to update message",
 			zap.Error(err),
 			zap.String("message_id", message.ID().String()),
 		)
@@ -102,50 +191,92 @@ func (r *messageRepositoryPostgres) Update(ctx context.Context, message *entity.
 	}
 
 	if rowsAffected == 0 {
-		return apperrors.New(apperrors.ErrorCodeNotFound, "message not found or version mismatch (optimistic lock)")
+		return r.explainMissedUpdate(ctx, message.ID())
 	}
 
 	message.IncrementVersion()
 	return nil
 }
 
+// explainMissedUpdate tells apart the two reasons a versioned update matches
+// no row: the message does not exist (for this tenant), or another writer
+// updated it since it was read.
+func (r *messageRepositoryPostgres) explainMissedUpdate(ctx context.Context, id uuid.UUID) error {
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{id})
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM messages WHERE id = $1 AND deleted_at IS NULL"+tenantFilter+")", args...).Scan(&exists)
+	if err != nil {
+		return apperrors.NewDatabaseError(err)
+	}
+
+	if !exists {
+		return apperrors.NewNotFoundError("message not found")
+	}
+	return apperrors.NewVersionConflictError("message was modified concurrently")
+}
+
 func (r *messageRepositoryPostgres) FindByID(ctx context.Context, id uuid.UUID) (*entity.Message, error) {
 	query := `
 		SELECT
-			id, phone_number, content, status, created_at, sent_at,
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
 			attempts, max_attempts, last_error, error_code,
-			webhook_message_id, webhook_response, version
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
 		FROM messages
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{id})
 
 	var (
-		msgID            uuid.UUID
-		phoneNumber      string
-		content          string
-		status           string
-		createdAt        time.Time
-		sentAt           sql.NullTime
-		attempts         int
-		maxAttempts      int
-		lastError        sql.NullString
-		errorCode        sql.NullString
-		webhookMessageID sql.NullString
-		webhookResponse  sql.NullString
-		version          int
+		msgID               uuid.UUID
+		phoneNumber         string
+		content             string
+		contentHash         string
+		status              string
+		createdAt           time.Time
+		sentAt              sql.NullTime
+		deliveredAt         sql.NullTime
+		scheduledAt         sql.NullTime
+		nextRetryAt         sql.NullTime
+		processingStartedAt sql.NullTime
+		attempts            int
+		maxAttempts         int
+		lastError           sql.NullString
+		errorCode           sql.NullString
+		webhookMessageID    sql.NullString
+		webhookResponse     sql.NullString
+		traceID             sql.NullString
+		providerRequestID   sql.NullString
+		idempotencyKey      sql.NullString
+		priority            int
+		tenantID            uuid.NullUUID
+		metadata            sql.NullString
+		campaignID          uuid.NullUUID
+		destinationID       uuid.NullUUID
+		expiresAt           sql.NullTime
+		channel             string
+		recipient           sql.NullString
+		splitGroupID        uuid.NullUUID
+		partNumber          int
+		partCount           int
+		createdBy           sql.NullString
+		dryRun              bool
+		simulated           bool
+		callbackURL         sql.NullString
+		version             int
 	)
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&msgID, &phoneNumber, &content, &status, &createdAt, &sentAt,
+	err := r.db.QueryRowContext(ctx, query+tenantFilter, args...).Scan(
+		&msgID, &phoneNumber, &content, &contentHash, &status, &createdAt, &sentAt, &deliveredAt, &scheduledAt, &nextRetryAt, &processingStartedAt,
 		&attempts, &maxAttempts, &lastError, &errorCode,
-		&webhookMessageID, &webhookResponse, &version,
+		&webhookMessageID, &webhookResponse, &traceID, &providerRequestID, &idempotencyKey, &priority, &tenantID, &metadata, &campaignID, &destinationID, &expiresAt, &channel, &recipient, &splitGroupID, &partNumber, &partCount, &createdBy, &dryRun, &simulated, &callbackURL, &version,
 	)
 
 	if err == sql.ErrNoRows {
 		return nil, apperrors.NewNotFoundError("message not found")
 	}
 	if err != nil {
-		logger.Get().Error("failed to find message by ID",
+		logger.FromContext(ctx).Error("failed to find message by ID",
 			zap.Error(err),
 			zap.String("message_id", id.String()),
 		)
@@ -153,28 +284,240 @@ func (r *messageRepositoryPostgres) FindByID(ctx context.Context, id uuid.UUID)
 	}
 
 	return r.scanMessage(
-		msgID, phoneNumber, content, status, createdAt, sentAt,
+		msgID, phoneNumber, content, contentHash, status, createdAt, sentAt, deliveredAt, scheduledAt, nextRetryAt, processingStartedAt,
 		attempts, maxAttempts, lastError, errorCode,
-		webhookMessageID, webhookResponse, version,
+		webhookMessageID, webhookResponse, traceID, providerRequestID, idempotencyKey, priority, tenantID, metadata, campaignID, destinationID, expiresAt, channel, recipient, splitGroupID, partNumber, partCount, createdBy, dryRun, simulated, callbackURL, version,
 	)
 }
 
-func (r *messageRepositoryPostgres) FindPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+func (r *messageRepositoryPostgres) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Message, error) {
+	query := `
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		WHERE idempotency_key = $1 AND deleted_at IS NULL
+	`
+	args := []interface{}{key}
+
+	// Keys are unique per tenant, with global-token messages as one more
+	// tenant of their own
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		args = append(args, tenantID)
+		query += " AND tenant_id = $2"
+	} else {
+		query += " AND tenant_id IS NULL"
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" LIMIT 1", args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find message by idempotency key", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, apperrors.NewNotFoundError("message not found")
+	}
+
+	return messages[0], nil
+}
+
+func (r *messageRepositoryPostgres) FindByWebhookMessageID(ctx context.Context, webhookMessageID string) (*entity.Message, error) {
 	query := `
 		SELECT
-			id, phone_number, content, status, created_at, sent_at,
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
 			attempts, max_attempts, last_error, error_code,
-			webhook_message_id, webhook_response, version
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
 		FROM messages
+		WHERE webhook_message_id = $1 AND deleted_at IS NULL
+	`
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{webhookMessageID})
+
+	rows, err := r.db.QueryContext(ctx, query+tenantFilter+" LIMIT 1", args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find message by webhook message ID", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, apperrors.NewNotFoundError("message not found")
+	}
+
+	return messages[0], nil
+}
+
+// ClaimPendingMessages locks the batch, skipping rows another instance is
+// claiming, and marks it processing in the same transaction. The batch is
+// chosen as in the GORM repository, with the lower priority share.
+func (r *messageRepositoryPostgres) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer tx.Rollback()
+
+	var ids []uuid.UUID
+	if priorities := repository.ClaimPriorities(ctx); len(priorities) > 0 {
+		// The share only balances a batch of every priority
+		ids, err = r.findPendingIDs(ctx, tx, priorityFilter(priorities), limit)
+	} else {
+		ids, err = fillByPriority(limit, r.lowerPriorityShare, func(id uuid.UUID) uuid.UUID { return id },
+			func(lane priorityLane, limit int, exclude []uuid.UUID) ([]uuid.UUID, error) {
+				return r.findPendingIDs(ctx, tx, laneFilter(lane, exclude), limit)
+			})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		UPDATE messages
+		SET status = $1, attempts = attempts + 1, processing_started_at = $2, version = version + 1
+		WHERE id = ANY($3::uuid[]) AND status = $4
+		RETURNING
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+	`
+
+	rows, err := tx.QueryContext(ctx, query,
+		valueobject.MessageStatusProcessing.String(), time.Now().UTC(), pq.Array(uuidStrings(ids)), valueobject.MessageStatusPending.String())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to claim pending messages", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	messages, err := r.scanMessages(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	sortByIDs(messages, ids)
+	return messages, nil
+}
+
+// findPendingIDs locks up to limit due messages matching filter.
+func (r *messageRepositoryPostgres) findPendingIDs(ctx context.Context, tx *sql.Tx, filter pendingFilter, limit int) ([]uuid.UUID, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id FROM messages
 		WHERE status = $1
-		ORDER BY created_at ASC
-		LIMIT $2
+			AND deleted_at IS NULL
+			AND (scheduled_at IS NULL OR scheduled_at <= $2)
+			AND (next_retry_at IS NULL OR next_retry_at <= $2)
+			AND ` + activeTenantCondition + `
+			AND ` + activeCampaignCondition + `%s%s
+		ORDER BY priority DESC, created_at ASC
+		LIMIT $%d
 		FOR UPDATE SKIP LOCKED
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, valueobject.MessageStatusPending.String(), limit)
+	args := []interface{}{valueobject.MessageStatusPending.String(), time.Now().UTC()}
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", args)
+	condition, args := filter(args)
+	args = append(args, limit)
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(query, tenantFilter, condition, len(args)), args...)
 	if err != nil {
-		logger.Get().Error("failed to find pending messages", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to find pending messages", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	return ids, nil
+}
+
+// pendingFilter adds a condition to a pending query, numbering its
+// placeholders after args.
+type pendingFilter func(args []interface{}) (string, []interface{})
+
+// laneFilter keeps a pending query to lane, leaving out exclude.
+func laneFilter(lane priorityLane, exclude []uuid.UUID) pendingFilter {
+	return func(args []interface{}) (string, []interface{}) {
+		var condition string
+		switch lane {
+		case laneHigh:
+			args = append(args, valueobject.MessagePriorityHigh.Rank())
+			condition = fmt.Sprintf(" AND priority = $%d", len(args))
+		case laneLower:
+			args = append(args, valueobject.MessagePriorityHigh.Rank())
+			condition = fmt.Sprintf(" AND priority < $%d", len(args))
+		}
+		if len(exclude) > 0 {
+			args = append(args, pq.Array(uuidStrings(exclude)))
+			condition += fmt.Sprintf(" AND NOT (id = ANY($%d::uuid[]))", len(args))
+		}
+		return condition, args
+	}
+}
+
+// priorityFilter keeps a pending query to priorities.
+func priorityFilter(priorities []valueobject.MessagePriority) pendingFilter {
+	return func(args []interface{}) (string, []interface{}) {
+		args = append(args, pq.Array(priorityRanks(priorities)))
+		return fmt.Sprintf(" AND priority = ANY($%d)", len(args)), args
+	}
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+func (r *messageRepositoryPostgres) FindStaleProcessing(ctx context.Context, startedBefore time.Time, limit int) ([]*entity.Message, error) {
+	query := `
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		WHERE status = $1 AND processing_started_at < $2 AND deleted_at IS NULL%s
+		ORDER BY processing_started_at ASC
+		LIMIT $%d
+	`
+
+	args := []interface{}{valueobject.MessageStatusProcessing.String(), startedBefore}
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", args)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, tenantFilter, len(args)), args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find stale processing messages", zap.Error(err))
 		return nil, apperrors.NewDatabaseError(err)
 	}
 	defer rows.Close()
@@ -185,18 +528,21 @@ func (r *messageRepositoryPostgres) FindPendingMessages(ctx context.Context, lim
 func (r *messageRepositoryPostgres) FindSentMessages(ctx context.Context, limit, offset int) ([]*entity.Message, error) {
 	query := `
 		SELECT
-			id, phone_number, content, status, created_at, sent_at,
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
 			attempts, max_attempts, last_error, error_code,
-			webhook_message_id, webhook_response, version
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
 		FROM messages
-		WHERE status = $1
+		WHERE status = ANY($1) AND deleted_at IS NULL%s
 		ORDER BY sent_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $%d OFFSET $%d
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, valueobject.MessageStatusSent.String(), limit, offset)
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{pq.Array(sentStatuses)})
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, tenantFilter, len(args)-1, len(args)), args...)
 	if err != nil {
-		logger.Get().Error("failed to find sent messages", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to find sent messages", zap.Error(err))
 		return nil, apperrors.NewDatabaseError(err)
 	}
 	defer rows.Close()
@@ -204,41 +550,615 @@ func (r *messageRepositoryPostgres) FindSentMessages(ctx context.Context, limit,
 	return r.scanMessages(rows)
 }
 
+// filterCondition builds the WHERE clause selecting the messages matching
+// filter, with its arguments.
+func filterCondition(ctx context.Context, filter repository.MessageFilter) (string, []interface{}, error) {
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{})
+	where := "WHERE deleted_at IS NULL" + tenantFilter
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Channel != "" {
+		args = append(args, filter.Channel)
+		where += fmt.Sprintf(" AND channel = $%d", len(args))
+	}
+	if filter.PhoneNumber != "" {
+		args = append(args, filter.PhoneNumber)
+		where += fmt.Sprintf(" AND phone_number = $%d", len(args))
+	}
+	if filter.ErrorCode != "" {
+		args = append(args, filter.ErrorCode)
+		where += fmt.Sprintf(" AND error_code = $%d", len(args))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if len(filter.Metadata) > 0 {
+		metadataJSON, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return "", nil, apperrors.NewInternalError(err)
+		}
+		args = append(args, string(metadataJSON))
+		where += fmt.Sprintf(" AND metadata @> $%d::jsonb", len(args))
+	}
+	if len(filter.ProviderResponse) > 0 {
+		responseJSON, err := json.Marshal(filter.ProviderResponse)
+		if err != nil {
+			return "", nil, apperrors.NewInternalError(err)
+		}
+		args = append(args, string(responseJSON))
+		where += fmt.Sprintf(" AND webhook_response @> $%d::jsonb", len(args))
+	}
+	if filter.CampaignID != uuid.Nil {
+		args = append(args, filter.CampaignID)
+		where += fmt.Sprintf(" AND campaign_id = $%d", len(args))
+	}
+	if filter.SplitGroupID != uuid.Nil {
+		args = append(args, filter.SplitGroupID)
+		where += fmt.Sprintf(" AND split_group_id = $%d", len(args))
+	}
+
+	return where, args, nil
+}
+
+func (r *messageRepositoryPostgres) FindByFilter(ctx context.Context, filter repository.MessageFilter, limit, offset int) ([]*entity.Message, int64, error) {
+	where, args, err := filterCondition(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM messages "+where, args...).Scan(&total); err != nil {
+		logger.FromContext(ctx).Error("failed to count filtered messages", zap.Error(err))
+		return nil, 0, apperrors.NewDatabaseError(err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find filtered messages", zap.Error(err))
+		return nil, 0, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *messageRepositoryPostgres) FindByFilterAfter(ctx context.Context, filter repository.MessageFilter, after *repository.MessageCursor, limit int) ([]*entity.Message, error) {
+	where, args, err := filterCondition(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		where += fmt.Sprintf(" AND (created_at, id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		%s
+		ORDER BY created_at ASC, id ASC
+		LIMIT $%d
+	`, where, len(args)+1)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to page through filtered messages", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+func (r *messageRepositoryPostgres) SearchContent(ctx context.Context, q *valueobject.SearchQuery, limit, offset int) ([]*entity.Message, int64, error) {
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", []interface{}{q.String()})
+	where := "WHERE deleted_at IS NULL AND " + contentSearchText + " @@ websearch_to_tsquery('simple', $1)" + tenantFilter
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM messages "+where, args...).Scan(&total); err != nil {
+		logger.FromContext(ctx).Error("failed to count content search matches", zap.Error(err))
+		return nil, 0, apperrors.NewDatabaseError(err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		%s
+		ORDER BY ts_rank(%s, websearch_to_tsquery('simple', $1)) DESC, created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, contentSearchText, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to search message content", zap.Error(err))
+		return nil, 0, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
 func (r *messageRepositoryPostgres) GetStats(ctx context.Context) (*repository.MessageStats, error) {
+	return r.stats(ctx, "", nil)
+}
+
+func (r *messageRepositoryPostgres) GetCampaignStats(ctx context.Context, campaignID uuid.UUID) (*repository.MessageStats, error) {
+	return r.stats(ctx, " AND campaign_id = $1", []interface{}{campaignID})
+}
+
+// stats counts the messages matching condition by status; condition uses
+// placeholders up to len(args).
+func (r *messageRepositoryPostgres) stats(ctx context.Context, condition string, args []interface{}) (*repository.MessageStats, error) {
 	query := `
-		SELECT
-			COUNT(*) as total,
-			COUNT(*) FILTER (WHERE status = 'pending') as pending,
-			COUNT(*) FILTER (WHERE status = 'sent') as sent,
-			COUNT(*) FILTER (WHERE status = 'failed') as failed
+		SELECT` + statusCountColumns + `
 		FROM messages
+		WHERE deleted_at IS NULL
 	`
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", args)
 
 	var stats repository.MessageStats
-	err := r.db.QueryRowContext(ctx, query).Scan(
+	err := r.db.QueryRowContext(ctx, query+condition+tenantFilter, args...).Scan(statsDest(&stats)...)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message stats", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	return &stats, nil
+}
+
+func (r *messageRepositoryPostgres) GetStatsByCaller(ctx context.Context) ([]repository.CallerStats, error) {
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", nil)
+	query := `
+		SELECT COALESCE(created_by, '') as caller,` + statusCountColumns + `
+		FROM messages
+		WHERE deleted_at IS NULL` + tenantFilter + `
+		GROUP BY 1
+		ORDER BY total DESC, caller
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message stats by caller", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var stats []repository.CallerStats
+	for rows.Next() {
+		var caller repository.CallerStats
+		if err := rows.Scan(append([]interface{}{&caller.Caller}, statsDest(&caller.MessageStats)...)...); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		stats = append(stats, caller)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	return stats, nil
+}
+
+func (r *messageRepositoryPostgres) GetSendPerformance(ctx context.Context, since time.Time) (*repository.SendPerformance, error) {
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", nil)
+	query := `
+		SELECT` + sendPerformanceColumns(postgresSecondsToSend, postgresP95SecondsToSend) + `
+		FROM messages
+		WHERE deleted_at IS NULL` + tenantFilter
+
+	var row sendPerformanceRow
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&row.Sent, &row.AvgAttempts, &row.AvgSeconds, &row.P95Seconds,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get send performance", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	// Counted apart so each outcome is looked up by its own time's index
+	// instead of in the scan above
+	tenantFilter, args = tenantCondition(ctx, "tenant_id", []interface{}{since})
+	recentQuery := `
+		SELECT
+			(SELECT COUNT(*) FROM messages WHERE deleted_at IS NULL AND ` + sentSince("$1") + tenantFilter + `),
+			(SELECT COUNT(*) FROM messages WHERE deleted_at IS NULL AND ` + failedSince("$1") + tenantFilter + `)
+	`
+	if err := r.db.QueryRowContext(ctx, recentQuery, args...).Scan(&row.RecentSent, &row.RecentFailed); err != nil {
+		logger.FromContext(ctx).Error("failed to count recent outcomes", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	return row.toPerformance(), nil
+}
+
+// statsDest lists the fields of stats in the order of statusCountColumns.
+func statsDest(stats *repository.MessageStats) []interface{} {
+	return []interface{}{
 		&stats.TotalMessages,
 		&stats.PendingMessages,
 		&stats.SentMessages,
 		&stats.FailedMessages,
-	)
+		&stats.CancelledMessages,
+		&stats.DeliveredMessages,
+		&stats.UndeliveredMessages,
+		&stats.QuarantinedMessages,
+		&stats.PausedMessages,
+		&stats.BlockedMessages,
+		&stats.ExpiredMessages,
+	}
+}
 
+func (r *messageRepositoryPostgres) GetStatsTimeSeries(ctx context.Context, bucket repository.StatsBucket, from, to time.Time) (*repository.MessageTimeSeries, error) {
+	condition, args := tenantCondition(ctx, "tenant_id", []interface{}{from, to})
+
+	created, err := r.countPerBucket(ctx, bucket, "created_at", "created_at >= $1 AND created_at < $2", condition, args)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := r.countPerBucket(ctx, bucket, "sent_at", sentCondition+" AND sent_at >= $1 AND sent_at < $2", condition, args)
 	if err != nil {
-		logger.Get().Error("failed to get message stats", zap.Error(err))
+		return nil, err
+	}
+	failed, err := r.countPerBucket(ctx, bucket, failedAt, "status = 'failed' AND "+failedInRange("$1", "$2"), condition, args)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := toStatsBuckets(created, sent, failed)
+	if err != nil {
+		return nil, err
+	}
+
+	errorCodes, err := r.errorCodeCounts(ctx, condition, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.MessageTimeSeries{Buckets: buckets, ErrorCodes: errorCodes}, nil
+}
+
+// countPerBucket counts the messages matching where per bucket of column;
+// where binds the range to $1 and $2.
+func (r *messageRepositoryPostgres) countPerBucket(ctx context.Context, bucket repository.StatsBucket, column, where, condition string, args []interface{}) ([]bucketCountRow, error) {
+	bucketStart := postgresBucketStart(bucket, column)
+	query := `
+		SELECT ` + bucketStart + ` as start, COUNT(*) as count
+		FROM messages
+		WHERE deleted_at IS NULL AND ` + where + condition + `
+		GROUP BY start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get message time series", zap.Error(err))
 		return nil, apperrors.NewDatabaseError(err)
 	}
+	defer rows.Close()
 
-	return &stats, nil
+	var counts []bucketCountRow
+	for rows.Next() {
+		var row bucketCountRow
+		if err := rows.Scan(&row.Start, &row.Count); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		counts = append(counts, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	return counts, nil
 }
 
-func (r *messageRepositoryPostgres) BeginTx(ctx context.Context) (repository.Transaction, error) {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelReadCommitted,
+// errorCodeCounts counts the error codes of the messages that last failed
+// in the range bound to $1 and $2, most frequent first.
+func (r *messageRepositoryPostgres) errorCodeCounts(ctx context.Context, condition string, args []interface{}) ([]repository.ErrorCodeCount, error) {
+	query := `
+		SELECT error_code, COUNT(*) as count
+		FROM messages
+		WHERE deleted_at IS NULL AND ` + failedInRange("$1", "$2") + `
+			AND error_code IS NOT NULL AND error_code <> ''` + condition + `
+		GROUP BY error_code
+		ORDER BY count DESC, error_code
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get error code breakdown", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var counts []repository.ErrorCodeCount
+	for rows.Next() {
+		var count repository.ErrorCodeCount
+		if err := rows.Scan(&count.ErrorCode, &count.Count); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	return counts, nil
+}
+
+func (r *messageRepositoryPostgres) FindDuplicateContent(ctx context.Context, from, to time.Time, minCount, limit int) ([]repository.DuplicateContent, error) {
+	condition, args := tenantCondition(ctx, "tenant_id", []interface{}{from, to})
+	args = append(args, minCount, limit)
+
+	query := fmt.Sprintf(`
+		SELECT
+			channel,
+			COALESCE(recipient, phone_number) as recipient,
+			content_hash,
+			MIN(content) as content,
+			COUNT(*) as count,
+			MIN(created_at) as first_created_at,
+			MAX(created_at) as last_created_at
+		FROM messages
+		WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2%s
+		GROUP BY channel, COALESCE(recipient, phone_number), content_hash
+		HAVING COUNT(*) >= $%d
+		ORDER BY count DESC, last_created_at DESC
+		LIMIT $%d
+	`, condition, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find duplicate content", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var duplicates []repository.DuplicateContent
+	for rows.Next() {
+		var d repository.DuplicateContent
+		if err := rows.Scan(&d.Channel, &d.Recipient, &d.ContentHash, &d.Content, &d.Count, &d.FirstCreatedAt, &d.LastCreatedAt); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		duplicates = append(duplicates, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.NewDatabaseError(err)
+	}
+
+	return duplicates, nil
+}
+
+func (r *messageRepositoryPostgres) GetDeliveryByPrefix(ctx context.Context, from, to time.Time, prefixLength int) ([]repository.PrefixDelivery, error) {
+	condition, args := tenantCondition(ctx, "tenant_id", []interface{}{
+		from,
+		to,
+		prefixLength,
+		valueobject.ChannelSMS.String(),
+		valueobject.MessageStatusSent.String(),
+		valueobject.MessageStatusDelivered.String(),
+		valueobject.MessageStatusFailed.String(),
+		valueobject.MessageStatusUndelivered.String(),
 	})
+
+	query := fmt.Sprintf(`
+		SELECT
+			SUBSTR(phone_number, 1, $3) as prefix,
+			MIN(phone_number) as sample,
+			COUNT(*) as total,
+			COUNT(CASE WHEN status IN ($5, $6) THEN 1 END) as sent,
+			COUNT(CASE WHEN status = $6 THEN 1 END) as delivered,
+			COUNT(CASE WHEN status IN ($7, $8) THEN 1 END) as failed
+		FROM messages
+		WHERE deleted_at IS NULL AND channel = $4 AND phone_number <> ''
+			AND created_at >= $1 AND created_at < $2%s
+		GROUP BY prefix
+		ORDER BY prefix
+	`, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
+		logger.FromContext(ctx).Error("failed to count delivery by prefix", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	var deliveries []repository.PrefixDelivery
+	for rows.Next() {
+		var d repository.PrefixDelivery
+		if err := rows.Scan(&d.Prefix, &d.Sample, &d.Total, &d.Sent, &d.Delivered, &d.Failed); err != nil {
+			return nil, apperrors.NewDatabaseError(err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, apperrors.NewDatabaseError(err)
 	}
 
-	return &postgresTransaction{tx: tx, ctx: ctx}, nil
+	return deliveries, nil
+}
+
+func (r *messageRepositoryPostgres) RequeueFailed(ctx context.Context, filter repository.FailedMessageFilter, resetAttempts bool) (int64, error) {
+	query := `
+		UPDATE messages SET
+			status = $1,
+			last_error = '',
+			error_code = '',
+			next_retry_at = NULL,
+			attempts = CASE WHEN $2 THEN 0 ELSE attempts END,
+			version = version + 1
+		WHERE status = $3 AND deleted_at IS NULL
+	`
+	args := []interface{}{
+		valueobject.MessageStatusPending.String(),
+		resetAttempts,
+		valueobject.MessageStatusFailed.String(),
+	}
+
+	tenantFilter, args := tenantCondition(ctx, "tenant_id", args)
+	query += tenantFilter
+
+	if filter.ErrorCode != "" {
+		args = append(args, filter.ErrorCode)
+		query += fmt.Sprintf(" AND error_code = $%d", len(args))
+	}
+	if filter.PhoneNumber != "" {
+		args = append(args, filter.PhoneNumber)
+		query += fmt.Sprintf(" AND phone_number = $%d", len(args))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to requeue failed messages", zap.Error(err))
+		return 0, apperrors.NewDatabaseError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewDatabaseError(err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (r *messageRepositoryPostgres) SoftDeleteOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	query := `
+		UPDATE messages SET deleted_at = $1
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE status = $2 AND created_at < $3 AND deleted_at IS NULL
+			LIMIT $4
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), status, createdBefore, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to soft-delete messages",
+			zap.Error(err),
+			zap.String("status", status),
+		)
+		return 0, apperrors.NewDatabaseError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewDatabaseError(err)
+	}
+	return rowsAffected, nil
+}
+
+func (r *messageRepositoryPostgres) PurgeOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM messages
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE status = $1 AND created_at < $2
+			LIMIT $3
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, status, createdBefore, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to purge messages",
+			zap.Error(err),
+			zap.String("status", status),
+		)
+		return 0, apperrors.NewDatabaseError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewDatabaseError(err)
+	}
+	return rowsAffected, nil
+}
+
+func (r *messageRepositoryPostgres) FindArchivable(ctx context.Context, statuses []string, createdBefore time.Time, limit int) ([]*entity.Message, error) {
+	query := `
+		SELECT
+			id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at, next_retry_at, processing_started_at,
+			attempts, max_attempts, last_error, error_code,
+			webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority, tenant_id, metadata, campaign_id, destination_id, expires_at, channel, recipient, split_group_id, part_number, part_count, created_by, dry_run, simulated, callback_url, version
+		FROM messages
+		WHERE status = ANY($1) AND created_at < $2
+		ORDER BY created_at ASC, id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(statuses), createdBefore, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to find archivable messages", zap.Error(err))
+		return nil, apperrors.NewDatabaseError(err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+func (r *messageRepositoryPostgres) PurgeByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM messages WHERE id = ANY($1::uuid[])`, pq.Array(values))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to purge messages by ID",
+			zap.Error(err),
+			zap.Int("count", len(ids)),
+		)
+		return 0, apperrors.NewDatabaseError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.NewDatabaseError(err)
+	}
+	return rowsAffected, nil
 }
 
 func (r *messageRepositoryPostgres) scanMessages(rows *sql.Rows) ([]*entity.Message, error) {
@@ -246,34 +1166,57 @@ func (r *messageRepositoryPostgres) scanMessages(rows *sql.Rows) ([]*entity.Mess
 
 	for rows.Next() {
 		var (
-			msgID            uuid.UUID
-			phoneNumber      string
-			content          string
-			status           string
-			createdAt        time.Time
-			sentAt           sql.NullTime
-			attempts         int
-			maxAttempts      int
-			lastError        sql.NullString
-			errorCode        sql.NullString
-			webhookMessageID sql.NullString
-			webhookResponse  sql.NullString
-			version          int
+			msgID               uuid.UUID
+			phoneNumber         string
+			content             string
+			contentHash         string
+			status              string
+			createdAt           time.Time
+			sentAt              sql.NullTime
+			deliveredAt         sql.NullTime
+			scheduledAt         sql.NullTime
+			nextRetryAt         sql.NullTime
+			processingStartedAt sql.NullTime
+			attempts            int
+			maxAttempts         int
+			lastError           sql.NullString
+			errorCode           sql.NullString
+			webhookMessageID    sql.NullString
+			webhookResponse     sql.NullString
+			traceID             sql.NullString
+			providerRequestID   sql.NullString
+			idempotencyKey      sql.NullString
+			priority            int
+			tenantID            uuid.NullUUID
+			metadata            sql.NullString
+			campaignID          uuid.NullUUID
+			destinationID       uuid.NullUUID
+			expiresAt           sql.NullTime
+			channel             string
+			recipient           sql.NullString
+			splitGroupID        uuid.NullUUID
+			partNumber          int
+			partCount           int
+			createdBy           sql.NullString
+			dryRun              bool
+			simulated           bool
+			callbackURL         sql.NullString
+			version             int
 		)
 
 		err := rows.Scan(
-			&msgID, &phoneNumber, &content, &status, &createdAt, &sentAt,
+			&msgID, &phoneNumber, &content, &contentHash, &status, &createdAt, &sentAt, &deliveredAt, &scheduledAt, &nextRetryAt, &processingStartedAt,
 			&attempts, &maxAttempts, &lastError, &errorCode,
-			&webhookMessageID, &webhookResponse, &version,
+			&webhookMessageID, &webhookResponse, &traceID, &providerRequestID, &idempotencyKey, &priority, &tenantID, &metadata, &campaignID, &destinationID, &expiresAt, &channel, &recipient, &splitGroupID, &partNumber, &partCount, &createdBy, &dryRun, &simulated, &callbackURL, &version,
 		)
 		if err != nil {
 			return nil, apperrors.NewDatabaseError(err)
 		}
 
 		message, err := r.scanMessage(
-			msgID, phoneNumber, content, status, createdAt, sentAt,
+			msgID, phoneNumber, content, contentHash, status, createdAt, sentAt, deliveredAt, scheduledAt, nextRetryAt, processingStartedAt,
 			attempts, maxAttempts, lastError, errorCode,
-			webhookMessageID, webhookResponse, version,
+			webhookMessageID, webhookResponse, traceID, providerRequestID, idempotencyKey, priority, tenantID, metadata, campaignID, destinationID, expiresAt, channel, recipient, splitGroupID, partNumber, partCount, createdBy, dryRun, simulated, callbackURL, version,
 		)
 		if err != nil {
 			return nil, err
@@ -293,23 +1236,46 @@ func (r *messageRepositoryPostgres) scanMessage(
 	msgID uuid.UUID,
 	phoneNumber string,
 	content string,
+	contentHash string,
 	status string,
 	createdAt time.Time,
 	sentAt sql.NullTime,
+	deliveredAt sql.NullTime,
+	scheduledAt sql.NullTime,
+	nextRetryAt sql.NullTime,
+	processingStartedAt sql.NullTime,
 	attempts int,
 	maxAttempts int,
 	lastError sql.NullString,
 	errorCode sql.NullString,
 	webhookMessageID sql.NullString,
 	webhookResponse sql.NullString,
+	traceID sql.NullString,
+	providerRequestID sql.NullString,
+	idempotencyKey sql.NullString,
+	priority int,
+	tenantID uuid.NullUUID,
+	metadataJSON sql.NullString,
+	campaignID uuid.NullUUID,
+	destinationID uuid.NullUUID,
+	expiresAt sql.NullTime,
+	channel string,
+	recipient sql.NullString,
+	splitGroupID uuid.NullUUID,
+	partNumber int,
+	partCount int,
+	createdBy sql.NullString,
+	dryRun bool,
+	simulated bool,
+	callbackURL sql.NullString,
 	version int,
 ) (*entity.Message, error) {
-	phone, err := valueobject.NewPhoneNumber(phoneNumber)
+	messageRecipient, err := model.ToRecipient(channel, phoneNumber, recipient.String)
 	if err != nil {
-		return nil, fmt.Errorf("invalid phone number in database: %w", err)
+		return nil, fmt.Errorf("invalid recipient in database: %w", err)
 	}
 
-	messageContent, err := valueobject.NewMessageContent(content, r.charLimit)
+	messageContent, err := valueobject.NewMessageContent(content, messageRecipient.Channel().ContentLimit(r.charLimit))
 	if err != nil {
 		return nil, fmt.Errorf("invalid message content in database: %w", err)
 	}
@@ -319,41 +1285,96 @@ func (r *messageRepositoryPostgres) scanMessage(
 		return nil, fmt.Errorf("invalid message status in database: %w", err)
 	}
 
+	var metadata valueobject.MessageMetadata
+	if metadataJSON.Valid {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err != nil {
+			return nil, fmt.Errorf("invalid message metadata in database: %w", err)
+		}
+	}
+
 	var sentAtPtr *time.Time
 	if sentAt.Valid {
 		sentAtPtr = &sentAt.Time
 	}
 
+	var deliveredAtPtr *time.Time
+	if deliveredAt.Valid {
+		deliveredAtPtr = &deliveredAt.Time
+	}
+
+	var scheduledAtPtr *time.Time
+	if scheduledAt.Valid {
+		scheduledAtPtr = &scheduledAt.Time
+	}
+
+	var nextRetryAtPtr *time.Time
+	if nextRetryAt.Valid {
+		nextRetryAtPtr = &nextRetryAt.Time
+	}
+
+	var processingStartedAtPtr *time.Time
+	if processingStartedAt.Valid {
+		processingStartedAtPtr = &processingStartedAt.Time
+	}
+
+	var expiresAtPtr *time.Time
+	if expiresAt.Valid {
+		expiresAtPtr = &expiresAt.Time
+	}
+
 	return entity.ReconstructMessage(
 		msgID,
-		phone,
+		messageRecipient,
 		messageContent,
+		contentHash,
 		messageStatus,
 		createdAt,
 		sentAtPtr,
+		deliveredAtPtr,
+		scheduledAtPtr,
+		nextRetryAtPtr,
+		processingStartedAtPtr,
 		attempts,
 		maxAttempts,
 		lastError.String,
 		errorCode.String,
 		webhookMessageID.String,
 		webhookResponse.String,
+		traceID.String,
+		providerRequestID.String,
+		idempotencyKey.String,
+		valueobject.MessagePriorityFromRank(priority),
+		tenantID.UUID,
+		metadata,
+		campaignID.UUID,
+		destinationID.UUID,
+		expiresAtPtr,
+		model.ToMessagePart(splitGroupID.UUID, partNumber, partCount),
+		createdBy.String,
+		dryRun,
+		simulated,
+		callbackURL.String,
 		version,
 	), nil
 }
 
-type postgresTransaction struct {
-	tx  *sql.Tx
-	ctx context.Context
-}
-
-func (t *postgresTransaction) Commit() error {
-	return t.tx.Commit()
+// marshalMetadata returns the JSON stored for metadata, NULL when there is
+// none.
+func marshalMetadata(metadata valueobject.MessageMetadata) (sql.NullString, error) {
+	if len(metadata) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
 }
 
-func (t *postgresTransaction) Rollback() error {
-	return t.tx.Rollback()
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
 
-func (t *postgresTransaction) GetContext() context.Context {
-	return t.ctx
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
 }
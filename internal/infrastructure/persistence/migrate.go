@@ -0,0 +1,108 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/eneskaya/insider-messaging/migrations"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+)
+
+// NewMigrate opens a connection of its own to the database selected by
+// DB_DRIVER, which Close on the result closes again. Migrations come from
+// the directory at path, or the ones built into the binary when path is
+// empty; SQLite gets its own set, since Postgres-only syntax does not run
+// there.
+func NewMigrate(cfg *config.DatabaseConfig, path string) (*migrate.Migrate, error) {
+	driverName, dsn := "postgres", cfg.DSN()
+	var embedded fs.FS = migrations.Postgres
+	embeddedDir := "."
+	if cfg.Driver == config.DBDriverSQLite {
+		driverName, dsn = "sqlite", cfg.SQLiteDSN()
+		embedded, embeddedDir = migrations.SQLite, "sqlite"
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	// The drivers close db along with the migrate instance
+	var driver database.Driver
+	if driverName == "sqlite" {
+		driver, err = sqlite.WithInstance(db, &sqlite.Config{})
+	} else {
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	if path != "" {
+		m, err := migrate.NewWithDatabaseInstance("file://"+path, driverName, driver)
+		if err != nil {
+			driver.Close()
+			return nil, fmt.Errorf("failed to read migrations from %s: %w", path, err)
+		}
+		return m, nil
+	}
+
+	src, err := iofs.New(embedded, embeddedDir)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// MigrateUp applies the embedded migrations that are still pending. On
+// PostgreSQL the migrate driver holds an advisory lock while it runs, so
+// replicas starting together apply them one at a time and the later ones
+// find nothing left to do. An unreachable database is retried for up to
+// DB_CONNECT_TIMEOUT.
+func MigrateUp(cfg *config.DatabaseConfig) error {
+	var m *migrate.Migrate
+	err := connectWithRetry(cfg.ConnectTimeout, "migrate", func() error {
+		var err error
+		m, err = NewMigrate(cfg, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+	logger.Get().Info("database migrations applied",
+		zap.Uint("version", version),
+		zap.Bool("dirty", dirty),
+	)
+	return nil
+}
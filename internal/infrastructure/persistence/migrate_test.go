@@ -0,0 +1,38 @@
+package persistence_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateUp_AppliesEmbeddedMigrations(t *testing.T) {
+	// Arrange
+	cfg := &config.DatabaseConfig{
+		Driver:          config.DBDriverSQLite,
+		SQLitePath:      filepath.Join(t.TempDir(), "messaging.db"),
+		MaxOpenConns:    4,
+		MaxIdleConns:    4,
+		ConnMaxLifetime: time.Minute,
+	}
+
+	// Act
+	err := persistence.MigrateUp(cfg)
+	again := persistence.MigrateUp(cfg)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, again, "nothing left to apply is not an error")
+
+	db, err := persistence.NewSQLiteGormDB(cfg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+	assert.NoError(t, db.CheckSchema(context.Background()))
+}
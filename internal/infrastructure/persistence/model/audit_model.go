@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type AuditLogModel struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Action       string     `gorm:"type:varchar(50);not null;index:idx_audit_log_action"`
+	ResourceType string     `gorm:"column:resource_type;type:varchar(50);not null;index:idx_audit_log_resource,priority:1"`
+	ResourceID   *string    `gorm:"column:resource_id;type:varchar(255);index:idx_audit_log_resource,priority:2"`
+	TenantID     *uuid.UUID `gorm:"column:tenant_id;type:uuid;index:idx_audit_log_tenant_occurred_at,where:tenant_id IS NOT NULL"`
+	Actor        string     `gorm:"type:varchar(255);not null;index:idx_audit_log_actor"`
+	RequestID    *string    `gorm:"column:request_id;type:varchar(255);index:idx_audit_log_request_id,where:request_id IS NOT NULL"`
+	FromStatus   *string    `gorm:"column:from_status;type:varchar(20)"`
+	ToStatus     *string    `gorm:"column:to_status;type:varchar(20)"`
+	Details      string     `gorm:"type:text"`
+	OccurredAt   time.Time  `gorm:"column:occurred_at;not null;index:idx_audit_log_occurred_at"`
+}
+
+func (AuditLogModel) TableName() string {
+	return "audit_log"
+}
+
+func ToAuditLogModel(entry *repository.AuditEntry) *AuditLogModel {
+	return &AuditLogModel{
+		ID:           entry.ID,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   stringPtr(entry.ResourceID),
+		TenantID:     uuidPtr(entry.TenantID),
+		Actor:        entry.Actor,
+		RequestID:    stringPtr(entry.RequestID),
+		FromStatus:   stringPtr(entry.FromStatus),
+		ToStatus:     stringPtr(entry.ToStatus),
+		Details:      entry.Details,
+		OccurredAt:   entry.OccurredAt,
+	}
+}
+
+func (m *AuditLogModel) ToAuditEntry() *repository.AuditEntry {
+	return &repository.AuditEntry{
+		ID:           m.ID,
+		Action:       m.Action,
+		ResourceType: m.ResourceType,
+		ResourceID:   stringValue(m.ResourceID),
+		TenantID:     uuidValue(m.TenantID),
+		Actor:        m.Actor,
+		RequestID:    stringValue(m.RequestID),
+		FromStatus:   stringValue(m.FromStatus),
+		ToStatus:     stringValue(m.ToStatus),
+		Details:      m.Details,
+		OccurredAt:   m.OccurredAt,
+	}
+}
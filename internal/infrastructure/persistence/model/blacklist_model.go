@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type BlacklistModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PhoneNumber string     `gorm:"type:varchar(20);not null;index:idx_blacklist_phone_number"`
+	TenantID    *uuid.UUID `gorm:"column:tenant_id;type:uuid"`
+	Reason      string     `gorm:"type:text"`
+	CreatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (BlacklistModel) TableName() string {
+	return "blacklist"
+}
+
+func ToBlacklistModel(entry *repository.BlacklistEntry) *BlacklistModel {
+	return &BlacklistModel{
+		ID:          entry.ID,
+		PhoneNumber: entry.PhoneNumber,
+		TenantID:    uuidPtr(entry.TenantID),
+		Reason:      entry.Reason,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
+
+func (m *BlacklistModel) ToBlacklistEntry() *repository.BlacklistEntry {
+	return &repository.BlacklistEntry{
+		ID:          m.ID,
+		PhoneNumber: m.PhoneNumber,
+		TenantID:    uuidValue(m.TenantID),
+		Reason:      m.Reason,
+		CreatedAt:   m.CreatedAt,
+	}
+}
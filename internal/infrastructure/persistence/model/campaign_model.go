@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type CampaignModel struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name      string     `gorm:"type:varchar(255);not null"`
+	Status    string     `gorm:"type:varchar(20);not null;default:active"`
+	TenantID  *uuid.UUID `gorm:"column:tenant_id;type:uuid;index:idx_campaigns_tenant_created_at,priority:1"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_campaigns_tenant_created_at,priority:2"`
+	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (CampaignModel) TableName() string {
+	return "campaigns"
+}
+
+func ToCampaignModel(campaign *repository.Campaign) *CampaignModel {
+	return &CampaignModel{
+		ID:        campaign.ID,
+		Name:      campaign.Name,
+		Status:    campaign.Status,
+		TenantID:  uuidPtr(campaign.TenantID),
+		CreatedAt: campaign.CreatedAt,
+		UpdatedAt: campaign.UpdatedAt,
+	}
+}
+
+func (m *CampaignModel) ToCampaign() *repository.Campaign {
+	return &repository.Campaign{
+		ID:        m.ID,
+		Name:      m.Name,
+		Status:    m.Status,
+		TenantID:  uuidValue(m.TenantID),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type ContactModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name        string     `gorm:"type:varchar(255);not null;default:''"`
+	PhoneNumber *string    `gorm:"column:phone_number;type:varchar(20)"`
+	Email       *string    `gorm:"type:varchar(254)"`
+	DeviceToken *string    `gorm:"column:device_token;type:text"`
+	TenantID    *uuid.UUID `gorm:"column:tenant_id;type:uuid;index:idx_contacts_tenant_created_at,priority:1"`
+	CreatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_contacts_tenant_created_at,priority:2"`
+	UpdatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ContactModel) TableName() string {
+	return "contacts"
+}
+
+func ToContactModel(contact *repository.Contact) *ContactModel {
+	return &ContactModel{
+		ID:          contact.ID,
+		Name:        contact.Name,
+		PhoneNumber: stringPtr(contact.PhoneNumber),
+		Email:       stringPtr(contact.Email),
+		DeviceToken: stringPtr(contact.DeviceToken),
+		TenantID:    uuidPtr(contact.TenantID),
+		CreatedAt:   contact.CreatedAt,
+		UpdatedAt:   contact.UpdatedAt,
+	}
+}
+
+func (m *ContactModel) ToContact() *repository.Contact {
+	return &repository.Contact{
+		ID:          m.ID,
+		Name:        m.Name,
+		PhoneNumber: stringValue(m.PhoneNumber),
+		Email:       stringValue(m.Email),
+		DeviceToken: stringValue(m.DeviceToken),
+		TenantID:    uuidValue(m.TenantID),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+type ContactGroupModel struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name      string     `gorm:"type:varchar(255);not null"`
+	TenantID  *uuid.UUID `gorm:"column:tenant_id;type:uuid"`
+	CreatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ContactGroupModel) TableName() string {
+	return "contact_groups"
+}
+
+func ToContactGroupModel(group *repository.ContactGroup) *ContactGroupModel {
+	return &ContactGroupModel{
+		ID:        group.ID,
+		Name:      group.Name,
+		TenantID:  uuidPtr(group.TenantID),
+		CreatedAt: group.CreatedAt,
+		UpdatedAt: group.UpdatedAt,
+	}
+}
+
+func (m *ContactGroupModel) ToContactGroup() *repository.ContactGroup {
+	return &repository.ContactGroup{
+		ID:        m.ID,
+		Name:      m.Name,
+		TenantID:  uuidValue(m.TenantID),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+type ContactGroupMemberModel struct {
+	GroupID   uuid.UUID `gorm:"column:group_id;type:uuid;primaryKey"`
+	ContactID uuid.UUID `gorm:"column:contact_id;type:uuid;primaryKey;index:idx_contact_group_members_contact_id"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ContactGroupMemberModel) TableName() string {
+	return "contact_group_members"
+}
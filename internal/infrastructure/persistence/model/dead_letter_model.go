@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type DeadLetterModel struct {
+	MessageID         uuid.UUID `gorm:"column:message_id;type:uuid;primaryKey"`
+	PhoneNumber       string    `gorm:"column:phone_number;type:varchar(20);not null"`
+	Content           string    `gorm:"type:text;not null"`
+	Attempts          int       `gorm:"not null"`
+	LastError         string    `gorm:"type:text"`
+	ErrorCode         string    `gorm:"type:varchar(50);index:idx_dead_letter_messages_error_code"`
+	TraceID           string    `gorm:"column:trace_id;type:varchar(64)"`
+	ProviderRequestID string    `gorm:"column:provider_request_id;type:varchar(255)"`
+	MessageCreatedAt  time.Time `gorm:"column:message_created_at;not null"`
+	DeadLetteredAt    time.Time `gorm:"column:dead_lettered_at;not null;default:CURRENT_TIMESTAMP;index:idx_dead_letter_messages_dead_lettered_at"`
+}
+
+func (DeadLetterModel) TableName() string {
+	return "dead_letter_messages"
+}
+
+func ToDeadLetterModel(entry *repository.DeadLetter) *DeadLetterModel {
+	return &DeadLetterModel{
+		MessageID:         entry.MessageID,
+		PhoneNumber:       entry.PhoneNumber,
+		Content:           entry.Content,
+		Attempts:          entry.Attempts,
+		LastError:         entry.LastError,
+		ErrorCode:         entry.ErrorCode,
+		TraceID:           entry.TraceID,
+		ProviderRequestID: entry.ProviderRequestID,
+		MessageCreatedAt:  entry.MessageCreatedAt,
+		DeadLetteredAt:    entry.DeadLetteredAt,
+	}
+}
+
+func (m *DeadLetterModel) ToDeadLetter() *repository.DeadLetter {
+	return &repository.DeadLetter{
+		MessageID:         m.MessageID,
+		PhoneNumber:       m.PhoneNumber,
+		Content:           m.Content,
+		Attempts:          m.Attempts,
+		LastError:         m.LastError,
+		ErrorCode:         m.ErrorCode,
+		TraceID:           m.TraceID,
+		ProviderRequestID: m.ProviderRequestID,
+		MessageCreatedAt:  m.MessageCreatedAt,
+		DeadLetteredAt:    m.DeadLetteredAt,
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+)
+
+type DispatchLeaseModel struct {
+	Name            string    `gorm:"type:varchar(100);primaryKey"`
+	HolderID        string    `gorm:"column:holder_id;type:varchar(255);not null"`
+	HolderRegion    string    `gorm:"column:holder_region;type:varchar(100);not null"`
+	PreferredRegion *string   `gorm:"column:preferred_region;type:varchar(100)"`
+	Epoch           int64     `gorm:"not null;default:1"`
+	AcquiredAt      time.Time `gorm:"type:timestamptz;not null"`
+	RenewedAt       time.Time `gorm:"type:timestamptz;not null"`
+	ExpiresAt       time.Time `gorm:"type:timestamptz;not null"`
+}
+
+func (DispatchLeaseModel) TableName() string {
+	return "dispatch_leases"
+}
+
+func (m *DispatchLeaseModel) ToLease() *repository.Lease {
+	lease := &repository.Lease{
+		Name:         m.Name,
+		HolderID:     m.HolderID,
+		HolderRegion: m.HolderRegion,
+		Epoch:        m.Epoch,
+		AcquiredAt:   m.AcquiredAt,
+		RenewedAt:    m.RenewedAt,
+		ExpiresAt:    m.ExpiresAt,
+	}
+	if m.PreferredRegion != nil {
+		lease.PreferredRegion = *m.PreferredRegion
+	}
+	return lease
+}
@@ -1,20 +1,22 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/eneskaya/insider-messaging/internal/domain/entity"
 	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
 	"gorm.io/plugin/optimisticlock"
 )
 
 func ToEntity(model *MessageModel, charLimit int) (*entity.Message, error) {
-	phoneNumber, err := valueobject.NewPhoneNumber(model.PhoneNumber)
+	recipient, err := ToRecipient(model.Channel, model.PhoneNumber, stringValue(model.Recipient))
 	if err != nil {
-		return nil, fmt.Errorf("invalid phone number in database: %w", err)
+		return nil, fmt.Errorf("invalid recipient in database: %w", err)
 	}
 
-	content, err := valueobject.NewMessageContent(model.Content, charLimit)
+	content, err := valueobject.NewMessageContent(model.Content, recipient.Channel().ContentLimit(charLimit))
 	if err != nil {
 		return nil, fmt.Errorf("invalid message content in database: %w", err)
 	}
@@ -24,23 +26,58 @@ func ToEntity(model *MessageModel, charLimit int) (*entity.Message, error) {
 		return nil, fmt.Errorf("invalid message status in database: %w", err)
 	}
 
+	var metadata valueobject.MessageMetadata
+	if model.Metadata != nil {
+		if err := json.Unmarshal([]byte(*model.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("invalid message metadata in database: %w", err)
+		}
+	}
+
 	return entity.ReconstructMessage(
 		model.ID,
-		phoneNumber,
+		recipient,
 		content,
+		model.ContentHash,
 		status,
 		model.CreatedAt,
 		model.SentAt,
+		model.DeliveredAt,
+		model.ScheduledAt,
+		model.NextRetryAt,
+		model.ProcessingStartedAt,
 		model.Attempts,
 		model.MaxAttempts,
 		model.LastError,
 		model.ErrorCode,
 		model.WebhookMessageID,
-		model.WebhookResponse,
+		stringValue(model.WebhookResponse),
+		model.TraceID,
+		model.ProviderRequestID,
+		stringValue(model.IdempotencyKey),
+		valueobject.MessagePriorityFromRank(int(model.Priority)),
+		uuidValue(model.TenantID),
+		metadata,
+		uuidValue(model.CampaignID),
+		uuidValue(model.DestinationID),
+		model.ExpiresAt,
+		ToMessagePart(uuidValue(model.SplitGroupID), int(model.PartNumber), int(model.PartCount)),
+		model.CreatedBy,
+		model.DryRun,
+		model.Simulated,
+		stringValue(model.CallbackURL),
 		int(model.Version.Int64),
 	), nil
 }
 
+// ToMessagePart is nil for a message that is not part of a split text,
+// stored with no group ID.
+func ToMessagePart(groupID uuid.UUID, number, count int) *valueobject.MessagePart {
+	if groupID == uuid.Nil {
+		return nil
+	}
+	return &valueobject.MessagePart{GroupID: groupID, Number: number, Count: count}
+}
+
 func ToEntities(models []MessageModel, charLimit int) ([]*entity.Message, error) {
 	entities := make([]*entity.Message, 0, len(models))
 
@@ -56,30 +93,148 @@ func ToEntities(models []MessageModel, charLimit int) ([]*entity.Message, error)
 }
 
 func ToModel(entity *entity.Message) *MessageModel {
-	return &MessageModel{
-		ID:               entity.ID(),
-		PhoneNumber:      entity.PhoneNumber().String(),
-		Content:          entity.Content().String(),
-		Status:           entity.Status().String(),
-		CreatedAt:        entity.CreatedAt(),
-		SentAt:           entity.SentAt(),
-		Attempts:         entity.Attempts(),
-		MaxAttempts:      entity.MaxAttempts(),
-		LastError:        entity.LastError(),
-		ErrorCode:        entity.ErrorCode(),
-		WebhookMessageID: entity.WebhookMessageID(),
-		WebhookResponse:  entity.WebhookResponse(),
-		Version:          optimisticlock.Version{Int64: int64(entity.Version())},
+	model := &MessageModel{
+		ID:                  entity.ID(),
+		Channel:             entity.Channel().String(),
+		PhoneNumber:         PhoneNumberColumn(entity.Recipient()),
+		Recipient:           RecipientColumn(entity.Recipient()),
+		Content:             entity.Content().String(),
+		ContentHash:         entity.ContentHash(),
+		Status:              entity.Status().String(),
+		CreatedAt:           entity.CreatedAt(),
+		SentAt:              entity.SentAt(),
+		DeliveredAt:         entity.DeliveredAt(),
+		ScheduledAt:         entity.ScheduledAt(),
+		ExpiresAt:           entity.ExpiresAt(),
+		NextRetryAt:         entity.NextRetryAt(),
+		ProcessingStartedAt: entity.ProcessingStartedAt(),
+		Attempts:            entity.Attempts(),
+		MaxAttempts:         entity.MaxAttempts(),
+		LastError:           entity.LastError(),
+		ErrorCode:           entity.ErrorCode(),
+		WebhookMessageID:    entity.WebhookMessageID(),
+		WebhookResponse:     WebhookResponseColumn(entity.WebhookResponse()),
+		TraceID:             entity.TraceID(),
+		ProviderRequestID:   entity.ProviderRequestID(),
+		IdempotencyKey:      stringPtr(entity.IdempotencyKey()),
+		Priority:            int16(entity.Priority().Rank()),
+		TenantID:            uuidPtr(entity.TenantID()),
+		Metadata:            metadataPtr(entity.Metadata()),
+		CampaignID:          uuidPtr(entity.CampaignID()),
+		DestinationID:       uuidPtr(entity.DestinationID()),
+		CreatedBy:           entity.CreatedBy(),
+		DryRun:              entity.DryRun(),
+		Simulated:           entity.Simulated(),
+		CallbackURL:         stringPtr(entity.CallbackURL()),
+		Version:             optimisticlock.Version{Int64: int64(entity.Version())},
+	}
+	if part := entity.Part(); part != nil {
+		model.SplitGroupID = uuidPtr(part.GroupID)
+		model.PartNumber = int16(part.Number)
+		model.PartCount = int16(part.Count)
 	}
+	return model
 }
 
 func UpdateModelFromEntity(model *MessageModel, entity *entity.Message) {
 	model.Status = entity.Status().String()
 	model.SentAt = entity.SentAt()
+	model.DeliveredAt = entity.DeliveredAt()
+	model.NextRetryAt = entity.NextRetryAt()
+	model.ProcessingStartedAt = entity.ProcessingStartedAt()
 	model.Attempts = entity.Attempts()
 	model.LastError = entity.LastError()
 	model.ErrorCode = entity.ErrorCode()
 	model.WebhookMessageID = entity.WebhookMessageID()
-	model.WebhookResponse = entity.WebhookResponse()
+	model.WebhookResponse = WebhookResponseColumn(entity.WebhookResponse())
+	model.TraceID = entity.TraceID()
+	model.ProviderRequestID = entity.ProviderRequestID()
+	model.Simulated = entity.Simulated()
 	model.Version = optimisticlock.Version{Int64: int64(entity.Version())}
 }
+
+// ToRecipient reads an SMS recipient from the phone_number column and any
+// other from the recipient column.
+func ToRecipient(channel, phoneNumber, address string) (*valueobject.Recipient, error) {
+	c, err := valueobject.NewChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	if c == valueobject.ChannelSMS {
+		return valueobject.NewRecipient(c, phoneNumber)
+	}
+	return valueobject.NewRecipient(c, address)
+}
+
+// PhoneNumberColumn is empty for messages that are not SMS.
+func PhoneNumberColumn(recipient *valueobject.Recipient) string {
+	if recipient.Channel() != valueobject.ChannelSMS {
+		return ""
+	}
+	return recipient.String()
+}
+
+// WebhookResponseColumn is the provider response as stored in the JSONB
+// column: NULL when there is none, and a response that is not JSON, such as
+// one recorded by an older version, wrapped as {"raw": response}.
+func WebhookResponseColumn(response string) *string {
+	if response == "" {
+		return nil
+	}
+	if json.Valid([]byte(response)) {
+		return &response
+	}
+	// A map of strings always marshals
+	data, _ := json.Marshal(map[string]string{"raw": response})
+	wrapped := string(data)
+	return &wrapped
+}
+
+// RecipientColumn is NULL for SMS, whose number is in phone_number.
+func RecipientColumn(recipient *valueobject.Recipient) *string {
+	if recipient.Channel() == valueobject.ChannelSMS {
+		return nil
+	}
+	return stringPtr(recipient.String())
+}
+
+// stringPtr maps an empty string to NULL so optional unique columns do not
+// collide on the empty string.
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// metadataPtr stores messages without metadata as NULL.
+func metadataPtr(metadata valueobject.MessageMetadata) *string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	// A map of strings always marshals
+	data, _ := json.Marshal(metadata)
+	s := string(data)
+	return &s
+}
+
+func uuidPtr(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+func uuidValue(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
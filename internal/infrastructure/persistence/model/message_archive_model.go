@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type MessageArchiveModel struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ObjectKey      string    `gorm:"column:object_key;type:varchar(1024);not null"`
+	Format         string    `gorm:"type:varchar(20);not null"`
+	MessageCount   int       `gorm:"column:message_count;not null"`
+	SizeBytes      int64     `gorm:"column:size_bytes;not null"`
+	FirstCreatedAt time.Time `gorm:"column:first_created_at;not null"`
+	LastCreatedAt  time.Time `gorm:"column:last_created_at;not null"`
+	ArchivedAt     time.Time `gorm:"column:archived_at;not null"`
+}
+
+func (MessageArchiveModel) TableName() string {
+	return "message_archives"
+}
+
+func ToMessageArchiveModel(archive *repository.MessageArchive) *MessageArchiveModel {
+	return &MessageArchiveModel{
+		ID:             archive.ID,
+		ObjectKey:      archive.ObjectKey,
+		Format:         archive.Format,
+		MessageCount:   archive.MessageCount,
+		SizeBytes:      archive.SizeBytes,
+		FirstCreatedAt: archive.FirstCreatedAt,
+		LastCreatedAt:  archive.LastCreatedAt,
+		ArchivedAt:     archive.ArchivedAt,
+	}
+}
+
+func (m *MessageArchiveModel) ToMessageArchive() *repository.MessageArchive {
+	return &repository.MessageArchive{
+		ID:             m.ID,
+		ObjectKey:      m.ObjectKey,
+		Format:         m.Format,
+		MessageCount:   m.MessageCount,
+		SizeBytes:      m.SizeBytes,
+		FirstCreatedAt: m.FirstCreatedAt,
+		LastCreatedAt:  m.LastCreatedAt,
+		ArchivedAt:     m.ArchivedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type MessageAttemptModel struct {
+	ID                uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MessageID         uuid.UUID `gorm:"column:message_id;type:uuid;not null;index:idx_message_attempts_message_id,priority:1"`
+	Attempt           int       `gorm:"not null;index:idx_message_attempts_message_id,priority:2"`
+	StartedAt         time.Time `gorm:"column:started_at;not null"`
+	DurationMs        int64     `gorm:"column:duration_ms;not null;default:0"`
+	Status            string    `gorm:"type:varchar(20);not null"`
+	StatusCode        *int      `gorm:"column:status_code"`
+	ErrorCode         *string   `gorm:"column:error_code;type:varchar(50)"`
+	Error             *string   `gorm:"column:error;type:text"`
+	WebhookResponse   *string   `gorm:"column:webhook_response;type:jsonb"`
+	ProviderRequestID *string   `gorm:"column:provider_request_id;type:varchar(255)"`
+}
+
+func (MessageAttemptModel) TableName() string {
+	return "message_attempts"
+}
+
+func ToMessageAttemptModel(attempt *repository.MessageAttempt) *MessageAttemptModel {
+	var statusCode *int
+	if attempt.StatusCode != 0 {
+		statusCode = &attempt.StatusCode
+	}
+	return &MessageAttemptModel{
+		ID:                attempt.ID,
+		MessageID:         attempt.MessageID,
+		Attempt:           attempt.Attempt,
+		StartedAt:         attempt.StartedAt,
+		DurationMs:        attempt.Duration.Milliseconds(),
+		Status:            attempt.Status,
+		StatusCode:        statusCode,
+		ErrorCode:         stringPtr(attempt.ErrorCode),
+		Error:             stringPtr(attempt.Error),
+		WebhookResponse:   WebhookResponseColumn(attempt.WebhookResponse),
+		ProviderRequestID: stringPtr(attempt.ProviderRequestID),
+	}
+}
+
+func (m *MessageAttemptModel) ToMessageAttempt() *repository.MessageAttempt {
+	attempt := &repository.MessageAttempt{
+		ID:                m.ID,
+		MessageID:         m.MessageID,
+		Attempt:           m.Attempt,
+		StartedAt:         m.StartedAt,
+		Duration:          time.Duration(m.DurationMs) * time.Millisecond,
+		Status:            m.Status,
+		ErrorCode:         stringValue(m.ErrorCode),
+		Error:             stringValue(m.Error),
+		WebhookResponse:   stringValue(m.WebhookResponse),
+		ProviderRequestID: stringValue(m.ProviderRequestID),
+	}
+	if m.StatusCode != nil {
+		attempt.StatusCode = *m.StatusCode
+	}
+	return attempt
+}
@@ -4,23 +4,50 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"gorm.io/plugin/optimisticlock"
 )
 
 type MessageModel struct {
-	ID               uuid.UUID                 `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	PhoneNumber      string                    `gorm:"column:phone_number;type:varchar(20);not null;index:idx_messages_phone"`
-	Content          string                    `gorm:"type:text;not null"`
-	Status           string                    `gorm:"type:varchar(20);not null;default:'pending';index:idx_messages_status;index:idx_messages_status_created_at,priority:1"`
-	CreatedAt        time.Time                 `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_messages_created_at;index:idx_messages_status_created_at,priority:2;index:idx_messages_pending_fifo,where:status = 'pending'"`
-	SentAt           *time.Time                `gorm:"index:idx_messages_sent_at,where:sent_at IS NOT NULL"`
-	Attempts         int                       `gorm:"not null;default:0"`
-	MaxAttempts      int                       `gorm:"not null;default:3"`
-	LastError        string                    `gorm:"type:text"`
-	ErrorCode        string                    `gorm:"type:varchar(50)"`
-	WebhookMessageID string                    `gorm:"column:webhook_message_id;type:varchar(255)"`
-	WebhookResponse  string                    `gorm:"type:text"`
-	Version          optimisticlock.Version    `gorm:"column:version;not null;default:0"`
+	ID                  uuid.UUID              `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Channel             string                 `gorm:"column:channel;type:varchar(10);not null;default:'sms'"`
+	PhoneNumber         string                 `gorm:"column:phone_number;type:varchar(20);not null;index:idx_messages_phone;index:idx_messages_phone_content_hash,priority:1"`
+	Recipient           *string                `gorm:"column:recipient;type:text"`
+	Content             string                 `gorm:"type:text;not null"`
+	ContentHash         string                 `gorm:"column:content_hash;type:char(64);not null;index:idx_messages_phone_content_hash,priority:2"`
+	Status              string                 `gorm:"type:varchar(20);not null;default:'pending';index:idx_messages_status;index:idx_messages_status_created_at,priority:1"`
+	CreatedAt           time.Time              `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_messages_created_at;index:idx_messages_status_created_at,priority:2;index:idx_messages_pending_fifo,where:status = 'pending';index:idx_messages_pending_priority,priority:2"`
+	SentAt              *time.Time             `gorm:"index:idx_messages_sent_at,where:sent_at IS NOT NULL"`
+	DeliveredAt         *time.Time             `gorm:"column:delivered_at"`
+	ScheduledAt         *time.Time             `gorm:"column:scheduled_at;index:idx_messages_scheduled_at,where:status = 'pending' AND scheduled_at IS NOT NULL"`
+	ExpiresAt           *time.Time             `gorm:"column:expires_at"`
+	NextRetryAt         *time.Time             `gorm:"column:next_retry_at;index:idx_messages_next_retry_at,where:status = 'pending' AND next_retry_at IS NOT NULL"`
+	ProcessingStartedAt *time.Time             `gorm:"column:processing_started_at;index:idx_messages_processing_started_at,where:status = 'processing';index:idx_messages_failed_at,where:status = 'failed'"`
+	Attempts            int                    `gorm:"not null;default:0"`
+	MaxAttempts         int                    `gorm:"not null;default:3"`
+	LastError           string                 `gorm:"type:text"`
+	ErrorCode           string                 `gorm:"type:varchar(50)"`
+	WebhookMessageID    string                 `gorm:"column:webhook_message_id;type:varchar(255);index:idx_messages_webhook_message_id,where:webhook_message_id IS NOT NULL AND webhook_message_id <> ''"`
+	WebhookResponse     *string                `gorm:"column:webhook_response;type:jsonb"`
+	TraceID             string                 `gorm:"column:trace_id;type:varchar(64)"`
+	ProviderRequestID   string                 `gorm:"column:provider_request_id;type:varchar(255)"`
+	IdempotencyKey      *string                `gorm:"column:idempotency_key;type:varchar(255)"`
+	Priority            int16                  `gorm:"column:priority;type:smallint;not null;default:1;index:idx_messages_pending_priority,sort:desc,priority:1,where:status = 'pending'"`
+	TenantID            *uuid.UUID             `gorm:"column:tenant_id;type:uuid;index:idx_messages_tenant_created_at,where:tenant_id IS NOT NULL"`
+	Metadata            *string                `gorm:"column:metadata;type:jsonb"`
+	CampaignID          *uuid.UUID             `gorm:"column:campaign_id;type:uuid;index:idx_messages_campaign_id,where:campaign_id IS NOT NULL"`
+	DestinationID       *uuid.UUID             `gorm:"column:destination_id;type:uuid;index:idx_messages_destination_id,where:destination_id IS NOT NULL"`
+	SplitGroupID        *uuid.UUID             `gorm:"column:split_group_id;type:uuid;index:idx_messages_split_group_id,where:split_group_id IS NOT NULL"`
+	PartNumber          int16                  `gorm:"column:part_number;type:smallint;not null;default:0"`
+	PartCount           int16                  `gorm:"column:part_count;type:smallint;not null;default:0"`
+	CreatedBy           string                 `gorm:"column:created_by;type:varchar(255)"`
+	DryRun              bool                   `gorm:"column:dry_run;not null;default:false"`
+	Simulated           bool                   `gorm:"column:simulated;not null;default:false"`
+	CallbackURL         *string                `gorm:"column:callback_url;type:text"`
+	Version             optimisticlock.Version `gorm:"column:version;not null;default:0"`
+	// DeletedAt makes GORM hide soft-deleted rows from every query built on
+	// the model; raw SQL has to exclude them itself
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
 }
 
 func (MessageModel) TableName() string {
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type OutboxEventModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Seq         int64      `gorm:"column:seq;autoIncrement;<-:false;index:idx_outbox_events_unpublished,where:published_at IS NULL"`
+	AggregateID uuid.UUID  `gorm:"column:aggregate_id;type:uuid;not null"`
+	TenantID    *uuid.UUID `gorm:"column:tenant_id;type:uuid"`
+	EventType   string     `gorm:"column:event_type;type:varchar(50);not null"`
+	Payload     string     `gorm:"type:jsonb;not null"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"type:text"`
+	CreatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	PublishedAt *time.Time `gorm:"index:idx_outbox_events_published_at,where:published_at IS NOT NULL"`
+}
+
+func (OutboxEventModel) TableName() string {
+	return "outbox_events"
+}
+
+// OutboxPayload is the JSON body published for every message lifecycle
+// event. It captures the message as it was stored with the event.
+type OutboxPayload struct {
+	EventID          string     `json:"event_id"`
+	Type             string     `json:"type"`
+	MessageID        string     `json:"message_id"`
+	TenantID         string     `json:"tenant_id,omitempty"`
+	Status           string     `json:"status"`
+	Channel          string     `json:"channel"`
+	PhoneNumber      string     `json:"phone_number,omitempty"`
+	Recipient        string     `json:"recipient"`
+	Attempts         int        `json:"attempts"`
+	ErrorCode        string     `json:"error_code,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	WebhookMessageID string     `json:"webhook_message_id,omitempty"`
+	NextRetryAt      *time.Time `json:"next_retry_at,omitempty"`
+	OccurredAt       time.Time  `json:"occurred_at"`
+}
+
+// ToOutboxModels builds one outbox row per event the message has pending.
+func ToOutboxModels(message *entity.Message) ([]OutboxEventModel, error) {
+	events := message.PendingEvents()
+	models := make([]OutboxEventModel, 0, len(events))
+	now := time.Now().UTC()
+
+	for _, eventType := range events {
+		id := uuid.New()
+		payload := OutboxPayload{
+			EventID:          id.String(),
+			Type:             string(eventType),
+			MessageID:        message.ID().String(),
+			Status:           message.Status().String(),
+			Channel:          message.Channel().String(),
+			PhoneNumber:      PhoneNumberColumn(message.Recipient()),
+			Recipient:        message.Recipient().String(),
+			Attempts:         message.Attempts(),
+			ErrorCode:        message.ErrorCode(),
+			LastError:        message.LastError(),
+			WebhookMessageID: message.WebhookMessageID(),
+			NextRetryAt:      message.NextRetryAt(),
+			OccurredAt:       now,
+		}
+		if message.TenantID() != uuid.Nil {
+			payload.TenantID = message.TenantID().String()
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		models = append(models, OutboxEventModel{
+			ID:          id,
+			AggregateID: message.ID(),
+			TenantID:    uuidPtr(message.TenantID()),
+			EventType:   string(eventType),
+			Payload:     string(body),
+			CreatedAt:   now,
+		})
+	}
+
+	return models, nil
+}
+
+func (m *OutboxEventModel) ToOutboxEvent() *repository.OutboxEvent {
+	evt := &repository.OutboxEvent{
+		ID:          m.ID,
+		AggregateID: m.AggregateID,
+		Type:        m.EventType,
+		Payload:     []byte(m.Payload),
+		Attempts:    m.Attempts,
+		LastError:   m.LastError,
+		CreatedAt:   m.CreatedAt,
+		PublishedAt: m.PublishedAt,
+	}
+	if m.TenantID != nil {
+		evt.TenantID = *m.TenantID
+	}
+	return evt
+}
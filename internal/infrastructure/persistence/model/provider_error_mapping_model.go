@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type ProviderErrorMappingModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Provider     string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_error_mappings_match"`
+	StatusCode   int       `gorm:"column:status_code;not null;default:0;uniqueIndex:idx_provider_error_mappings_match"`
+	ProviderCode string    `gorm:"column:provider_code;type:varchar(255);not null;default:'';uniqueIndex:idx_provider_error_mappings_match"`
+	BodyContains string    `gorm:"column:body_contains;type:text;not null;default:'';uniqueIndex:idx_provider_error_mappings_match"`
+	ErrorCode    string    `gorm:"column:error_code;type:varchar(50);not null"`
+	Description  string    `gorm:"type:text;not null;default:''"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (ProviderErrorMappingModel) TableName() string {
+	return "provider_error_mappings"
+}
+
+func ToProviderErrorMappingModel(mapping *repository.ProviderErrorMapping) *ProviderErrorMappingModel {
+	return &ProviderErrorMappingModel{
+		ID:           mapping.ID,
+		Provider:     mapping.Provider,
+		StatusCode:   mapping.StatusCode,
+		ProviderCode: mapping.ProviderCode,
+		BodyContains: mapping.BodyContains,
+		ErrorCode:    mapping.ErrorCode,
+		Description:  mapping.Description,
+		CreatedAt:    mapping.CreatedAt,
+	}
+}
+
+func (m *ProviderErrorMappingModel) ToProviderErrorMapping() *repository.ProviderErrorMapping {
+	return &repository.ProviderErrorMapping{
+		ID:           m.ID,
+		Provider:     m.Provider,
+		StatusCode:   m.StatusCode,
+		ProviderCode: m.ProviderCode,
+		BodyContains: m.BodyContains,
+		ErrorCode:    m.ErrorCode,
+		Description:  m.Description,
+		CreatedAt:    m.CreatedAt,
+	}
+}
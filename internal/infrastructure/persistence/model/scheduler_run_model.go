@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type SchedulerRunModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Instance   string    `gorm:"type:varchar(255);not null"`
+	StartedAt  time.Time `gorm:"column:started_at;not null;index:idx_scheduler_runs_started_at"`
+	DurationMs int64     `gorm:"column:duration_ms;not null;default:0"`
+	Processed  int       `gorm:"not null;default:0"`
+	Successful int       `gorm:"not null;default:0"`
+	Failed     int       `gorm:"not null;default:0"`
+	Errors     int       `gorm:"not null;default:0"`
+	LastError  *string   `gorm:"column:last_error;type:text"`
+	SkipReason *string   `gorm:"column:skip_reason;type:varchar(50)"`
+}
+
+func (SchedulerRunModel) TableName() string {
+	return "scheduler_runs"
+}
+
+func ToSchedulerRunModel(run *repository.SchedulerRun) *SchedulerRunModel {
+	return &SchedulerRunModel{
+		ID:         run.ID,
+		Instance:   run.Instance,
+		StartedAt:  run.StartedAt,
+		DurationMs: run.Duration.Milliseconds(),
+		Processed:  run.Processed,
+		Successful: run.Successful,
+		Failed:     run.Failed,
+		Errors:     run.Errors,
+		LastError:  stringPtr(run.LastError),
+		SkipReason: stringPtr(run.SkipReason),
+	}
+}
+
+func (m *SchedulerRunModel) ToSchedulerRun() *repository.SchedulerRun {
+	return &repository.SchedulerRun{
+		ID:         m.ID,
+		Instance:   m.Instance,
+		StartedAt:  m.StartedAt,
+		Duration:   time.Duration(m.DurationMs) * time.Millisecond,
+		Processed:  m.Processed,
+		Successful: m.Successful,
+		Failed:     m.Failed,
+		Errors:     m.Errors,
+		LastError:  stringValue(m.LastError),
+		SkipReason: stringValue(m.SkipReason),
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type TenantModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name       string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_tenants_name"`
+	APIKeyHash string    `gorm:"column:api_key_hash;type:char(64);not null;uniqueIndex:idx_tenants_api_key_hash"`
+	Active     bool      `gorm:"not null;default:true"`
+	QuietHours string    `gorm:"type:varchar(11);not null;default:''"`
+	CreatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (TenantModel) TableName() string {
+	return "tenants"
+}
+
+func ToTenantModel(tenant *repository.Tenant) *TenantModel {
+	return &TenantModel{
+		ID:         tenant.ID,
+		Name:       tenant.Name,
+		APIKeyHash: tenant.APIKeyHash,
+		Active:     tenant.Active,
+		QuietHours: tenant.QuietHours,
+		CreatedAt:  tenant.CreatedAt,
+		UpdatedAt:  tenant.UpdatedAt,
+	}
+}
+
+func (m *TenantModel) ToTenant() *repository.Tenant {
+	return &repository.Tenant{
+		ID:         m.ID,
+		Name:       m.Name,
+		APIKeyHash: m.APIKeyHash,
+		Active:     m.Active,
+		QuietHours: m.QuietHours,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type WebhookDestinationModel struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name               string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_webhook_destinations_name"`
+	URL                string     `gorm:"column:url;type:text;not null"`
+	AuthKey            string     `gorm:"column:auth_key;type:text;not null;default:''"`
+	RateLimitPerSecond int        `gorm:"column:rate_limit_per_second;not null;default:0"`
+	TenantID           *uuid.UUID `gorm:"column:tenant_id;type:uuid;index:idx_webhook_destinations_tenant_id,where:tenant_id IS NOT NULL"`
+	Active             bool       `gorm:"not null;default:true"`
+	CreatedAt          time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+func (WebhookDestinationModel) TableName() string {
+	return "webhook_destinations"
+}
+
+func ToWebhookDestinationModel(destination *repository.WebhookDestination) *WebhookDestinationModel {
+	return &WebhookDestinationModel{
+		ID:                 destination.ID,
+		Name:               destination.Name,
+		URL:                destination.URL,
+		AuthKey:            destination.AuthKey,
+		RateLimitPerSecond: destination.RateLimitPerSecond,
+		TenantID:           uuidPtr(destination.TenantID),
+		Active:             destination.Active,
+		CreatedAt:          destination.CreatedAt,
+		UpdatedAt:          destination.UpdatedAt,
+	}
+}
+
+func (m *WebhookDestinationModel) ToWebhookDestination() *repository.WebhookDestination {
+	return &repository.WebhookDestination{
+		ID:                 m.ID,
+		Name:               m.Name,
+		URL:                m.URL,
+		AuthKey:            m.AuthKey,
+		RateLimitPerSecond: m.RateLimitPerSecond,
+		TenantID:           uuidValue(m.TenantID),
+		Active:             m.Active,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+	}
+}
@@ -0,0 +1,144 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Connection is what the monitor checks; GormDB implements it.
+type Connection interface {
+	HealthCheck(ctx context.Context) error
+	ResetIdleConns() error
+}
+
+// Monitor pings the database every interval, so a restart or failover is
+// noticed even while nothing else queries it. While the database is
+// unreachable the monitor closes its dispatch gate, so scheduler cycles are
+// skipped instead of failing message by message, and it opens it again as
+// soon as a ping succeeds. The idle connections are reset on recovery, since
+// after a failover they may still point at the old primary.
+type Monitor struct {
+	conn     Connection
+	interval time.Duration
+
+	mu        sync.RWMutex
+	up        bool
+	downSince time.Time
+	lastError string
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// MonitorStatus is whether the database was reachable on the last ping and,
+// during an outage, since when it is not.
+type MonitorStatus struct {
+	Up        bool
+	DownSince *time.Time
+	LastError string
+}
+
+// NewMonitor starts out assuming the database is reachable, since the
+// service only starts once it has connected.
+func NewMonitor(conn Connection, interval time.Duration) *Monitor {
+	metrics.DatabaseUp.Set(1)
+	return &Monitor{
+		conn:     conn,
+		interval: interval,
+		up:       true,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (m *Monitor) Start(ctx context.Context) {
+	m.wg.Add(1)
+	go m.run(ctx)
+
+	logger.Get().Info("database connection monitor started", zap.Duration("interval", m.interval))
+}
+
+func (m *Monitor) Stop() {
+	close(m.stopChan)
+	m.wg.Wait()
+}
+
+// IsLeader lets the monitor serve as a scheduler dispatch gate: it reports
+// whether the database was reachable on the last ping.
+func (m *Monitor) IsLeader() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.up
+}
+
+func (m *Monitor) Status() MonitorStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := MonitorStatus{Up: m.up, LastError: m.lastError}
+	if !m.up {
+		downSince := m.downSince
+		status.DownSince = &downSince
+	}
+	return status
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	err := m.conn.HealthCheck(checkCtx)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down, not an outage
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		m.lastError = err.Error()
+		if m.up {
+			m.up = false
+			m.downSince = time.Now()
+			metrics.DatabaseUp.Set(0)
+			logger.Get().Error("database unreachable, pausing dispatch until it is back", zap.Error(err))
+		}
+		return
+	}
+
+	if !m.up {
+		if err := m.conn.ResetIdleConns(); err != nil {
+			logger.Get().Warn("failed to reset idle database connections", zap.Error(err))
+		}
+		logger.Get().Info("database reachable again, resuming dispatch",
+			zap.Duration("outage", time.Since(m.downSince)),
+		)
+		m.up = true
+		m.downSince = time.Time{}
+		m.lastError = ""
+		metrics.DatabaseUp.Set(1)
+	}
+}
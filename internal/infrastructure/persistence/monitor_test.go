@@ -0,0 +1,81 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyConnection fails its health checks while down is set.
+type flakyConnection struct {
+	mu     sync.Mutex
+	down   bool
+	resets int
+}
+
+func (c *flakyConnection) HealthCheck(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (c *flakyConnection) ResetIdleConns() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resets++
+	return nil
+}
+
+func (c *flakyConnection) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func TestMonitor_ClosesGateDuringOutage(t *testing.T) {
+	// Arrange
+	conn := &flakyConnection{}
+	monitor := persistence.NewMonitor(conn, 10*time.Millisecond)
+	monitor.Start(context.Background())
+	defer monitor.Stop()
+	assert.True(t, monitor.IsLeader())
+
+	// Act
+	conn.setDown(true)
+
+	// Assert
+	assert.Eventually(t, func() bool { return !monitor.IsLeader() }, time.Second, 5*time.Millisecond)
+	status := monitor.Status()
+	assert.False(t, status.Up)
+	assert.NotNil(t, status.DownSince)
+	assert.Equal(t, "connection refused", status.LastError)
+}
+
+func TestMonitor_ReopensGateAndResetsConnectionsOnRecovery(t *testing.T) {
+	// Arrange
+	conn := &flakyConnection{down: true}
+	monitor := persistence.NewMonitor(conn, 10*time.Millisecond)
+	monitor.Start(context.Background())
+	defer monitor.Stop()
+	assert.Eventually(t, func() bool { return !monitor.IsLeader() }, time.Second, 5*time.Millisecond)
+
+	// Act
+	conn.setDown(false)
+
+	// Assert
+	assert.Eventually(t, monitor.IsLeader, time.Second, 5*time.Millisecond)
+	status := monitor.Status()
+	assert.Nil(t, status.DownSince)
+	assert.Empty(t, status.LastError)
+	conn.mu.Lock()
+	assert.Equal(t, 1, conn.resets)
+	conn.mu.Unlock()
+}
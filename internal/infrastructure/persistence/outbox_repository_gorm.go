@@ -0,0 +1,114 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// outboxRelayLockKey serializes relays across instances so events are
+// published in seq order.
+const outboxRelayLockKey = "outbox_relay"
+
+type outboxRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepositoryGorm(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepositoryGorm{db: db}
+}
+
+func (r *outboxRepositoryGorm) Relay(
+	ctx context.Context,
+	limit int,
+	publish func(ctx context.Context, evt *repository.OutboxEvent) error,
+) (int, error) {
+	published := 0
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Another instance is relaying; it will get to these events. SQLite
+		// is for a single local instance, so there is nobody to coordinate
+		// with there.
+		if !isSQLite(tx) {
+			var locked bool
+			if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", outboxRelayLockKey).Scan(&locked).Error; err != nil {
+				return mapGormError(err)
+			}
+			if !locked {
+				return nil
+			}
+		}
+
+		var models []model.OutboxEventModel
+		result := tx.
+			Where("published_at IS NULL").
+			Order("seq ASC").
+			Limit(limit).
+			Find(&models)
+		if result.Error != nil {
+			return mapGormError(result.Error)
+		}
+
+		ids := make([]uuid.UUID, 0, len(models))
+		for i := range models {
+			if err := publish(ctx, models[i].ToOutboxEvent()); err != nil {
+				logger.Get().Warn("failed to publish outbox event",
+					zap.Error(err),
+					zap.String("event_id", models[i].ID.String()),
+					zap.String("event_type", models[i].EventType),
+					zap.Int("attempts", models[i].Attempts+1),
+				)
+
+				updateErr := tx.Model(&model.OutboxEventModel{}).
+					Where("id = ?", models[i].ID).
+					Updates(map[string]interface{}{
+						"attempts":   gorm.Expr("attempts + 1"),
+						"last_error": err.Error(),
+					}).Error
+				if updateErr != nil {
+					return mapGormError(updateErr)
+				}
+				break
+			}
+			ids = append(ids, models[i].ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.OutboxEventModel{}).
+			Where("id IN ?", ids).
+			Update("published_at", time.Now().UTC()).Error; err != nil {
+			return mapGormError(err)
+		}
+		published = len(ids)
+		return nil
+	})
+
+	if err != nil {
+		logger.Get().Error("failed to relay outbox events", zap.Error(err))
+		return 0, err
+	}
+
+	return published, nil
+}
+
+func (r *outboxRepositoryGorm) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", cutoff).
+		Delete(&model.OutboxEventModel{})
+
+	if result.Error != nil {
+		logger.Get().Error("failed to prune published outbox events", zap.Error(result.Error))
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,76 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepositoryGorm_RelaysInInsertOrder(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	messages := persistence.NewMessageRepositoryGorm(db, charLimit, true, 0.5)
+	outbox := persistence.NewOutboxRepositoryGorm(db)
+	ctx := context.Background()
+
+	first := newTestMessage(t, "first")
+	second := newTestMessage(t, "second")
+	assert.NoError(t, messages.Create(ctx, first))
+	assert.NoError(t, messages.Create(ctx, second))
+
+	var relayed []uuid.UUID
+	publish := func(ctx context.Context, evt *repository.OutboxEvent) error {
+		relayed = append(relayed, evt.AggregateID)
+		return nil
+	}
+
+	// Act
+	published, err := outbox.Relay(ctx, 10, publish)
+	again, againErr := outbox.Relay(ctx, 10, publish)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, againErr)
+	assert.Equal(t, 2, published)
+	assert.Zero(t, again)
+	assert.Equal(t, []uuid.UUID{first.ID(), second.ID()}, relayed)
+}
+
+func TestOutboxRepositoryGorm_StopsAtFirstFailure(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	messages := persistence.NewMessageRepositoryGorm(db, charLimit, true, 0.5)
+	outbox := persistence.NewOutboxRepositoryGorm(db)
+	ctx := context.Background()
+
+	first := newTestMessage(t, "first")
+	second := newTestMessage(t, "second")
+	assert.NoError(t, messages.Create(ctx, first))
+	assert.NoError(t, messages.Create(ctx, second))
+
+	// Act
+	published, err := outbox.Relay(ctx, 10, func(ctx context.Context, evt *repository.OutboxEvent) error {
+		return errors.New("broker unavailable")
+	})
+
+	var retried *repository.OutboxEvent
+	_, retryErr := outbox.Relay(ctx, 1, func(ctx context.Context, evt *repository.OutboxEvent) error {
+		retried = evt
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, retryErr)
+	assert.Zero(t, published)
+	if assert.NotNil(t, retried) {
+		assert.Equal(t, first.ID(), retried.AggregateID)
+		assert.Equal(t, 1, retried.Attempts)
+		assert.Equal(t, "broker unavailable", retried.LastError)
+	}
+}
@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// priorityLane narrows the pending messages a query looks at by priority.
+type priorityLane int
+
+const (
+	laneAll priorityLane = iota
+	laneHigh
+	laneLower
+)
+
+// findInLane locks up to limit due messages of the lane in priority then
+// creation order, leaving out the exclude IDs.
+type findInLane[T any] func(lane priorityLane, limit int, exclude []uuid.UUID) ([]T, error)
+
+// fillByPriority picks a pending batch of up to limit messages for both
+// message repositories. High priority goes first, except that lowerShare of
+// the batch (rounded down) is kept for normal and low priority messages
+// while they are waiting, so a steady stream of urgent messages cannot
+// starve them. Kept slots lower priorities cannot fill go to high priority
+// and the other way round, so a full batch is sent whenever enough messages
+// are due. The batch is in priority then creation order.
+func fillByPriority[T any](limit int, lowerShare float64, id func(T) uuid.UUID, find findInLane[T]) ([]T, error) {
+	kept := int(math.Floor(float64(limit) * lowerShare))
+	if kept <= 0 {
+		return find(laneAll, limit, nil)
+	}
+
+	lower, err := find(laneLower, kept, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, err := find(laneHigh, limit-len(lower), nil)
+	if err != nil {
+		return nil, err
+	}
+	batch = append(batch, lower...)
+
+	if len(batch) < limit && len(lower) == kept {
+		exclude := make([]uuid.UUID, len(lower))
+		for i, message := range lower {
+			exclude[i] = id(message)
+		}
+
+		more, err := find(laneLower, limit-len(batch), exclude)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, more...)
+	}
+
+	return batch, nil
+}
@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type pendingTestMessage struct {
+	id   uuid.UUID
+	high bool
+}
+
+// findInMemory serves fillByPriority from pending, which is in priority
+// then creation order like the pending queries.
+func findInMemory(pending []pendingTestMessage) findInLane[pendingTestMessage] {
+	return func(lane priorityLane, limit int, exclude []uuid.UUID) ([]pendingTestMessage, error) {
+		excluded := make(map[uuid.UUID]bool, len(exclude))
+		for _, id := range exclude {
+			excluded[id] = true
+		}
+
+		var found []pendingTestMessage
+		for _, message := range pending {
+			if len(found) == limit {
+				break
+			}
+			if excluded[message.id] || (lane == laneHigh && !message.high) || (lane == laneLower && message.high) {
+				continue
+			}
+			found = append(found, message)
+		}
+		return found, nil
+	}
+}
+
+func TestFillByPriority(t *testing.T) {
+	tests := []struct {
+		name       string
+		high       int
+		lower      int
+		limit      int
+		lowerShare float64
+		wantHigh   int
+		wantLower  int
+	}{
+		{name: "strict priority", high: 6, lower: 6, limit: 4, lowerShare: 0, wantHigh: 4, wantLower: 0},
+		{name: "lower priorities keep their share", high: 6, lower: 6, limit: 4, lowerShare: 0.5, wantHigh: 2, wantLower: 2},
+		{name: "share rounds down", high: 6, lower: 6, limit: 5, lowerShare: 0.5, wantHigh: 3, wantLower: 2},
+		{name: "high priority fills unused lower slots", high: 6, lower: 1, limit: 4, lowerShare: 0.5, wantHigh: 3, wantLower: 1},
+		{name: "lower priorities fill unused high slots", high: 1, lower: 6, limit: 4, lowerShare: 0.5, wantHigh: 1, wantLower: 3},
+		{name: "fewer due than the limit", high: 1, lower: 1, limit: 4, lowerShare: 0.5, wantHigh: 1, wantLower: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var pending []pendingTestMessage
+			for i := 0; i < tt.high; i++ {
+				pending = append(pending, pendingTestMessage{id: uuid.New(), high: true})
+			}
+			for i := 0; i < tt.lower; i++ {
+				pending = append(pending, pendingTestMessage{id: uuid.New()})
+			}
+
+			// Act
+			batch, err := fillByPriority(tt.limit, tt.lowerShare,
+				func(m pendingTestMessage) uuid.UUID { return m.id }, findInMemory(pending))
+
+			// Assert
+			assert.NoError(t, err)
+			var high, lower int
+			seen := make(map[uuid.UUID]bool)
+			for i, message := range batch {
+				assert.False(t, seen[message.id], "message picked twice")
+				seen[message.id] = true
+				if message.high {
+					assert.Equal(t, i, high, "high priority comes first")
+					high++
+				} else {
+					lower++
+				}
+			}
+			assert.Equal(t, tt.wantHigh, high)
+			assert.Equal(t, tt.wantLower, lower)
+		})
+	}
+}
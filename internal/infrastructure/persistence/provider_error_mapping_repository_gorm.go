@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type providerErrorMappingRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewProviderErrorMappingRepositoryGorm(db *gorm.DB) repository.ProviderErrorMappingRepository {
+	return &providerErrorMappingRepositoryGorm{db: db}
+}
+
+func (r *providerErrorMappingRepositoryGorm) Create(ctx context.Context, mapping *repository.ProviderErrorMapping) error {
+	result := r.db.WithContext(ctx).Create(model.ToProviderErrorMappingModel(mapping))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create provider error mapping",
+			zap.Error(result.Error),
+			zap.String("mapping_id", mapping.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *providerErrorMappingRepositoryGorm) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&model.ProviderErrorMappingModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to delete provider error mapping",
+			zap.Error(result.Error),
+			zap.String("mapping_id", id.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *providerErrorMappingRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.ProviderErrorMapping, error) {
+	var mappingModel model.ProviderErrorMappingModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&mappingModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find provider error mapping by ID",
+				zap.Error(result.Error),
+				zap.String("mapping_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return mappingModel.ToProviderErrorMapping(), nil
+}
+
+func (r *providerErrorMappingRepositoryGorm) List(ctx context.Context) ([]*repository.ProviderErrorMapping, error) {
+	var models []model.ProviderErrorMappingModel
+	result := r.db.WithContext(ctx).
+		Order("created_at ASC").
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list provider error mappings", zap.Error(result.Error))
+		return nil, mapGormError(result.Error)
+	}
+
+	mappings := make([]*repository.ProviderErrorMapping, len(models))
+	for i := range models {
+		mappings[i] = models[i].ToProviderErrorMapping()
+	}
+
+	return mappings, nil
+}
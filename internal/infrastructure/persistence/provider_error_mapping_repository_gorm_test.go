@@ -0,0 +1,64 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderErrorMappingRepositoryGorm_CreateListDelete(t *testing.T) {
+	// Arrange
+	repo := persistence.NewProviderErrorMappingRepositoryGorm(newTestDB(t))
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	byStatus := &repository.ProviderErrorMapping{
+		ID:          uuid.New(),
+		Provider:    "webhook",
+		StatusCode:  402,
+		ErrorCode:   "INSUFFICIENT_BALANCE",
+		Description: "prepaid account ran out",
+		CreatedAt:   now,
+	}
+	byCode := &repository.ProviderErrorMapping{
+		ID:           uuid.New(),
+		Provider:     "twilio",
+		ProviderCode: "30007",
+		ErrorCode:    "MESSAGE_BLOCKED",
+		CreatedAt:    now.Add(time.Second),
+	}
+	assert.NoError(t, repo.Create(ctx, byStatus))
+	assert.NoError(t, repo.Create(ctx, byCode))
+
+	// Act
+	duplicate := *byStatus
+	duplicate.ID = uuid.New()
+	duplicateErr := repo.Create(ctx, &duplicate)
+	found, findErr := repo.FindByID(ctx, byStatus.ID)
+	deleteErr := repo.Delete(ctx, byCode.ID)
+	listed, listErr := repo.List(ctx)
+	missingErr := repo.Delete(ctx, byCode.ID)
+
+	// Assert
+	var appErr *apperrors.AppError
+	if assert.ErrorAs(t, duplicateErr, &appErr) {
+		assert.Equal(t, apperrors.ErrorCodeAlreadyExists, appErr.Code)
+	}
+	assert.NoError(t, findErr)
+	assert.Equal(t, 402, found.StatusCode)
+	assert.Equal(t, "prepaid account ran out", found.Description)
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, listErr)
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, byStatus.ID, listed[0].ID)
+	}
+	if assert.ErrorAs(t, missingErr, &appErr) {
+		assert.Equal(t, apperrors.ErrorCodeNotFound, appErr.Code)
+	}
+}
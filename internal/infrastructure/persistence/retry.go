@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	connectRetryBase = 500 * time.Millisecond
+	connectRetryMax  = 10 * time.Second
+)
+
+// connectWithRetry calls connect until it succeeds or timeout has passed,
+// doubling the wait between attempts up to connectRetryMax, so a database
+// that is still starting or failing over does not stop the service from
+// starting. It returns the last error once timeout has passed.
+func connectWithRetry(timeout time.Duration, what string, connect func() error) error {
+	deadline := time.Now().Add(timeout)
+	wait := connectRetryBase
+
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		logger.Get().Warn("database not reachable, retrying",
+			zap.String("operation", what),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", wait),
+			zap.Error(err),
+		)
+		time.Sleep(wait)
+		wait = min(wait*2, connectRetryMax)
+	}
+}
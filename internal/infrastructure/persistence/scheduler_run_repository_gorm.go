@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type schedulerRunRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewSchedulerRunRepositoryGorm(db *gorm.DB) repository.SchedulerRunRepository {
+	return &schedulerRunRepositoryGorm{db: db}
+}
+
+func (r *schedulerRunRepositoryGorm) Add(ctx context.Context, run *repository.SchedulerRun) error {
+	if err := r.db.WithContext(ctx).Create(model.ToSchedulerRunModel(run)).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to add scheduler run",
+			zap.Error(err),
+			zap.String("run_id", run.ID.String()),
+		)
+		return mapGormError(err)
+	}
+
+	return nil
+}
+
+func (r *schedulerRunRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.SchedulerRun, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.SchedulerRunModel{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count scheduler runs", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.SchedulerRunModel
+	result := query.
+		Order("started_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list scheduler runs", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	runs := make([]*repository.SchedulerRun, len(models))
+	for i := range models {
+		runs[i] = models[i].ToSchedulerRun()
+	}
+
+	return runs, total, nil
+}
+
+func (r *schedulerRunRepositoryGorm) DeleteStartedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("started_at < ?", cutoff).
+		Delete(&model.SchedulerRunModel{})
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to delete old scheduler runs", zap.Error(result.Error))
+		return 0, mapGormError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,54 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunRepositoryGorm_ListAndPrune(t *testing.T) {
+	// Arrange
+	repo := persistence.NewSchedulerRunRepositoryGorm(newTestDB(t))
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := &repository.SchedulerRun{ID: uuid.New(), Instance: "api-1", StartedAt: now.Add(-48 * time.Hour), Processed: 1, Successful: 1}
+	skipped := &repository.SchedulerRun{ID: uuid.New(), Instance: "api-1", StartedAt: now.Add(-time.Minute), SkipReason: "circuit_open"}
+	latest := &repository.SchedulerRun{
+		ID:         uuid.New(),
+		Instance:   "api-2",
+		StartedAt:  now,
+		Duration:   250 * time.Millisecond,
+		Processed:  4,
+		Successful: 3,
+		Failed:     1,
+		Errors:     1,
+		LastError:  "claim failed",
+	}
+	for _, run := range []*repository.SchedulerRun{old, skipped, latest} {
+		assert.NoError(t, repo.Add(ctx, run))
+	}
+
+	// Act
+	page, total, listErr := repo.List(ctx, 2, 0)
+	deleted, deleteErr := repo.DeleteStartedBefore(ctx, now.Add(-24*time.Hour))
+	_, remaining, _ := repo.List(ctx, 10, 0)
+
+	// Assert
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, latest.ID, page[0].ID)
+		assert.Equal(t, 250*time.Millisecond, page[0].Duration)
+		assert.Equal(t, "claim failed", page[0].LastError)
+		assert.Equal(t, "circuit_open", page[1].SkipReason)
+	}
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, int64(2), remaining)
+}
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteDialector runs GORM's SQLite dialect on the pure Go modernc driver,
+// so no C toolchain is needed, and translates that driver's constraint
+// errors, which the stock translator only knows in their cgo form.
+type sqliteDialector struct {
+	*sqlite.Dialector
+}
+
+func (d sqliteDialector) Translate(err error) error {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return gorm.ErrDuplicatedKey
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return gorm.ErrForeignKeyViolated
+	default:
+		return err
+	}
+}
+
+// NewSQLiteGormDB opens the database file at DB_SQLITE_PATH, creating it if
+// needed. The schema comes from migrations/sqlite.
+func NewSQLiteGormDB(cfg *config.DatabaseConfig) (*GormDB, error) {
+	dialector := sqliteDialector{&sqlite.Dialector{
+		DriverName: "sqlite",
+		DSN:        cfg.SQLiteDSN(),
+	}}
+
+	db, err := openGormDB(dialector, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Get().Info("connected to SQLite database with GORM",
+		zap.String("path", cfg.SQLitePath),
+	)
+
+	return db, nil
+}
@@ -0,0 +1,192 @@
+package persistence
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+)
+
+// bucketStartLayout is the text format both dialects render bucket starts in
+const bucketStartLayout = "2006-01-02 15:04:05"
+
+// bucketCountRow is one row of a time series query; Start is rendered with
+// bucketStartLayout.
+type bucketCountRow struct {
+	Start string
+	Count int64
+}
+
+// failedAt is when a failed message failed: the start of its last attempt,
+// which every failure goes through, or its creation for a row that never
+// recorded one.
+const failedAt = "COALESCE(processing_started_at, created_at)"
+
+// failedInRange keeps the messages whose failedAt is in [from, to), given
+// the placeholders of both bounds. It compares the columns rather than
+// failedAt so idx_messages_failed_at and idx_messages_created_at can serve
+// it.
+func failedInRange(from, to string) string {
+	return fmt.Sprintf(`((processing_started_at >= %[1]s AND processing_started_at < %[2]s)
+			OR (processing_started_at IS NULL AND created_at >= %[1]s AND created_at < %[2]s))`, from, to)
+}
+
+// createdAtLayout is the text format of aggregated creation times
+const createdAtLayout = "2006-01-02 15:04:05.000"
+
+// duplicateContentRow is one row of a duplicate content query; the times
+// are rendered with createdAtLayout.
+type duplicateContentRow struct {
+	Channel        string
+	Recipient      string
+	ContentHash    string
+	Content        string
+	Count          int64
+	FirstCreatedAt string
+	LastCreatedAt  string
+}
+
+func toDuplicateContent(rows []duplicateContentRow) ([]repository.DuplicateContent, error) {
+	duplicates := make([]repository.DuplicateContent, 0, len(rows))
+	for _, row := range rows {
+		first, err := time.ParseInLocation(createdAtLayout, row.FirstCreatedAt, time.UTC)
+		if err != nil {
+			return nil, apperrors.NewDatabaseError(fmt.Errorf("invalid creation time %q: %w", row.FirstCreatedAt, err))
+		}
+		last, err := time.ParseInLocation(createdAtLayout, row.LastCreatedAt, time.UTC)
+		if err != nil {
+			return nil, apperrors.NewDatabaseError(fmt.Errorf("invalid creation time %q: %w", row.LastCreatedAt, err))
+		}
+		duplicates = append(duplicates, repository.DuplicateContent{
+			Channel:        row.Channel,
+			Recipient:      row.Recipient,
+			ContentHash:    row.ContentHash,
+			Content:        row.Content,
+			Count:          row.Count,
+			FirstCreatedAt: first,
+			LastCreatedAt:  last,
+		})
+	}
+	return duplicates, nil
+}
+
+// postgresBucketStart truncates column to the bucket with date_trunc.
+func postgresBucketStart(bucket repository.StatsBucket, column string) string {
+	return fmt.Sprintf("to_char(date_trunc('%s', %s), 'YYYY-MM-DD HH24:MI:SS')", bucket, column)
+}
+
+// toStatsBuckets merges the counts of the created, sent and failed series,
+// each bucketed on its own time, oldest bucket first.
+func toStatsBuckets(created, sent, failed []bucketCountRow) ([]repository.MessageStatsBucket, error) {
+	byStart := make(map[string]*repository.MessageStatsBucket)
+	bucketFor := func(start string) (*repository.MessageStatsBucket, error) {
+		if b, ok := byStart[start]; ok {
+			return b, nil
+		}
+		t, err := time.ParseInLocation(bucketStartLayout, start, time.UTC)
+		if err != nil {
+			return nil, apperrors.NewDatabaseError(fmt.Errorf("invalid bucket start %q: %w", start, err))
+		}
+		b := &repository.MessageStatsBucket{Start: t}
+		byStart[start] = b
+		return b, nil
+	}
+
+	for _, row := range created {
+		b, err := bucketFor(row.Start)
+		if err != nil {
+			return nil, err
+		}
+		b.Created += row.Count
+	}
+	for _, row := range sent {
+		b, err := bucketFor(row.Start)
+		if err != nil {
+			return nil, err
+		}
+		b.Sent += row.Count
+	}
+	for _, row := range failed {
+		b, err := bucketFor(row.Start)
+		if err != nil {
+			return nil, err
+		}
+		b.Failed += row.Count
+	}
+
+	buckets := make([]repository.MessageStatsBucket, 0, len(byStart))
+	for _, b := range byStart {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+// sentCondition keeps the messages that were sent, whatever happened to
+// them after.
+const sentCondition = "status IN ('sent', 'delivered', 'undelivered')"
+
+// sendPerformanceColumns aggregates the sent messages of a
+// sendPerformanceRow in one pass over the messages. seconds is the time to
+// send in seconds and p95 the expression of its 95th percentile.
+func sendPerformanceColumns(seconds, p95 string) string {
+	return fmt.Sprintf(`
+			COUNT(*) FILTER (WHERE %[1]s) as sent,
+			COALESCE(AVG(attempts) FILTER (WHERE %[1]s), 0) as avg_attempts,
+			COALESCE(AVG(%[2]s) FILTER (WHERE %[1]s), 0) as avg_seconds,
+			%[3]s as p95_seconds
+		`, sentCondition, seconds, p95)
+}
+
+// sentSince keeps the messages sent at or after the placeholder since. It
+// compares sent_at itself so idx_messages_sent_at serves it.
+func sentSince(since string) string {
+	return fmt.Sprintf("%s AND sent_at >= %s", sentCondition, since)
+}
+
+// failedSince keeps the messages whose failedAt is at or after the
+// placeholder since, comparing the columns so idx_messages_failed_at and
+// idx_messages_status_created_at serve it.
+func failedSince(since string) string {
+	return fmt.Sprintf(`status = 'failed' AND (processing_started_at >= %[1]s
+			OR (processing_started_at IS NULL AND created_at >= %[1]s))`, since)
+}
+
+// postgresSecondsToSend is the time from created_at to sent_at in seconds
+const postgresSecondsToSend = "EXTRACT(EPOCH FROM sent_at - created_at)::float8"
+
+// postgresP95SecondsToSend picks the 95th percentile time to send of the
+// sent messages by nearest rank, like the SQLite query does.
+var postgresP95SecondsToSend = fmt.Sprintf(
+	"COALESCE(PERCENTILE_DISC(0.95) WITHIN GROUP (ORDER BY %s) FILTER (WHERE %s), 0)",
+	postgresSecondsToSend, sentCondition)
+
+// sendPerformanceRow is one row of a send performance query, with the times
+// to send in seconds.
+type sendPerformanceRow struct {
+	Sent         int64
+	AvgAttempts  float64
+	AvgSeconds   float64
+	P95Seconds   float64
+	RecentSent   int64
+	RecentFailed int64
+}
+
+func (row sendPerformanceRow) toPerformance() *repository.SendPerformance {
+	return &repository.SendPerformance{
+		SentMessages:  row.Sent,
+		AvgAttempts:   row.AvgAttempts,
+		AvgTimeToSend: secondsToDuration(row.AvgSeconds),
+		P95TimeToSend: secondsToDuration(row.P95Seconds),
+		RecentSent:    row.RecentSent,
+		RecentFailed:  row.RecentFailed,
+	}
+}
+
+// secondsToDuration rounds to milliseconds, below what either dialect
+// measures time differences in reliably.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+}
@@ -0,0 +1,118 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type tenantRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewTenantRepositoryGorm(db *gorm.DB) repository.TenantRepository {
+	return &tenantRepositoryGorm{db: db}
+}
+
+func (r *tenantRepositoryGorm) Create(ctx context.Context, tenant *repository.Tenant) error {
+	result := r.db.WithContext(ctx).Create(model.ToTenantModel(tenant))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create tenant",
+			zap.Error(result.Error),
+			zap.String("tenant_id", tenant.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *tenantRepositoryGorm) Update(ctx context.Context, tenant *repository.Tenant) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.TenantModel{}).
+		Where("id = ?", tenant.ID).
+		Select("name", "api_key_hash", "active", "quiet_hours", "updated_at").
+		Updates(model.ToTenantModel(tenant))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to update tenant",
+			zap.Error(result.Error),
+			zap.String("tenant_id", tenant.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *tenantRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.Tenant, error) {
+	var tenantModel model.TenantModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&tenantModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find tenant by ID",
+				zap.Error(result.Error),
+				zap.String("tenant_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return tenantModel.ToTenant(), nil
+}
+
+func (r *tenantRepositoryGorm) FindByAPIKeyHash(ctx context.Context, hash string) (*repository.Tenant, error) {
+	var tenantModel model.TenantModel
+
+	result := r.db.WithContext(ctx).
+		Where("api_key_hash = ?", hash).
+		First(&tenantModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find tenant by API key", zap.Error(result.Error))
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return tenantModel.ToTenant(), nil
+}
+
+func (r *tenantRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.Tenant, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.TenantModel{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count tenants", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.TenantModel
+	result := query.
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list tenants", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	tenants := make([]*repository.Tenant, len(models))
+	for i := range models {
+		tenants[i] = models[i].ToTenant()
+	}
+
+	return tenants, total, nil
+}
@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+// tenantScope restricts a query to the tenant carried by ctx. Requests made
+// with the global token and the scheduler carry no tenant and are not
+// restricted.
+func tenantScope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if id, ok := tenant.FromContext(ctx); ok {
+			return db.Where(column+" = ?", id)
+		}
+		return db
+	}
+}
+
+// tenantCondition is the database/sql counterpart of tenantScope. It returns
+// the extra predicate (empty when unscoped) and the extended argument list.
+func tenantCondition(ctx context.Context, column string, args []interface{}) (string, []interface{}) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", args
+	}
+	args = append(args, id)
+	return fmt.Sprintf(" AND %s = $%d", column, len(args)), args
+}
+
+// activeTenantCondition keeps messages of deactivated tenants out of the
+// dispatch queue.
+const activeTenantCondition = "(tenant_id IS NULL OR tenant_id IN (SELECT id FROM tenants WHERE active))"
@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence/model"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type webhookDestinationRepositoryGorm struct {
+	db *gorm.DB
+}
+
+func NewWebhookDestinationRepositoryGorm(db *gorm.DB) repository.WebhookDestinationRepository {
+	return &webhookDestinationRepositoryGorm{db: db}
+}
+
+func (r *webhookDestinationRepositoryGorm) Create(ctx context.Context, destination *repository.WebhookDestination) error {
+	result := r.db.WithContext(ctx).Create(model.ToWebhookDestinationModel(destination))
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to create webhook destination",
+			zap.Error(result.Error),
+			zap.String("destination_id", destination.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *webhookDestinationRepositoryGorm) Update(ctx context.Context, destination *repository.WebhookDestination) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.WebhookDestinationModel{}).
+		Where("id = ?", destination.ID).
+		Select("name", "url", "auth_key", "rate_limit_per_second", "active", "updated_at").
+		Updates(model.ToWebhookDestinationModel(destination))
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to update webhook destination",
+			zap.Error(result.Error),
+			zap.String("destination_id", destination.ID.String()),
+		)
+		return mapGormError(result.Error)
+	}
+
+	return checkRowsAffected(result, 1)
+}
+
+func (r *webhookDestinationRepositoryGorm) FindByID(ctx context.Context, id uuid.UUID) (*repository.WebhookDestination, error) {
+	var destinationModel model.WebhookDestinationModel
+
+	result := r.db.WithContext(ctx).
+		Where("id = ?", id).
+		First(&destinationModel)
+
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Error("failed to find webhook destination by ID",
+				zap.Error(result.Error),
+				zap.String("destination_id", id.String()),
+			)
+		}
+		return nil, mapGormError(result.Error)
+	}
+
+	return destinationModel.ToWebhookDestination(), nil
+}
+
+func (r *webhookDestinationRepositoryGorm) List(ctx context.Context, limit, offset int) ([]*repository.WebhookDestination, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.WebhookDestinationModel{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.FromContext(ctx).Error("failed to count webhook destinations", zap.Error(err))
+		return nil, 0, mapGormError(err)
+	}
+
+	var models []model.WebhookDestinationModel
+	result := query.
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&models)
+
+	if result.Error != nil {
+		logger.FromContext(ctx).Error("failed to list webhook destinations", zap.Error(result.Error))
+		return nil, 0, mapGormError(result.Error)
+	}
+
+	destinations := make([]*repository.WebhookDestination, len(models))
+	for i := range models {
+		destinations[i] = models[i].ToWebhookDestination()
+	}
+
+	return destinations, total, nil
+}
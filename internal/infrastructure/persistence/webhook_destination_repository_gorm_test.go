@@ -0,0 +1,63 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDestinationRepositoryGorm_RoutesMessages(t *testing.T) {
+	// Arrange
+	db := newTestDB(t)
+	destinations := persistence.NewWebhookDestinationRepositoryGorm(db)
+	messages := persistence.NewMessageRepositoryGorm(db, charLimit, false, 0.5)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	destination := &repository.WebhookDestination{
+		ID:                 uuid.New(),
+		Name:               "acme",
+		URL:                "https://hooks.acme.test/sms",
+		AuthKey:            "acme-key",
+		RateLimitPerSecond: 5,
+		Active:             true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	assert.NoError(t, destinations.Create(ctx, destination))
+
+	message := newTestMessage(t, "routed")
+	message.AssignDestination(destination.ID)
+	assert.NoError(t, messages.Create(ctx, message))
+
+	destination.RateLimitPerSecond = 20
+	destination.Active = false
+	destination.UpdatedAt = now.Add(time.Minute)
+	assert.NoError(t, destinations.Update(ctx, destination))
+
+	// Act
+	found, findErr := destinations.FindByID(ctx, destination.ID)
+	listed, total, listErr := destinations.List(ctx, 10, 0)
+	stored, storedErr := messages.FindByID(ctx, message.ID())
+	duplicate := *destination
+	duplicate.ID = uuid.New()
+	duplicateErr := destinations.Create(ctx, &duplicate)
+
+	// Assert
+	assert.NoError(t, findErr)
+	assert.Equal(t, "acme-key", found.AuthKey)
+	assert.Equal(t, 20, found.RateLimitPerSecond)
+	assert.False(t, found.Active)
+	assert.Equal(t, uuid.Nil, found.TenantID)
+	assert.NoError(t, listErr)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, listed, 1)
+	assert.NoError(t, storedErr)
+	assert.Equal(t, destination.ID, stored.DestinationID())
+	assert.Error(t, duplicateErr, "destination names are unique")
+}
@@ -0,0 +1,294 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Retention modes selectable with RETENTION_MODE
+const (
+	ModeSoft = "soft"
+	ModeHard = "hard"
+)
+
+// Policy removes messages in Status once they are older than MaxAge.
+type Policy struct {
+	Status string
+	MaxAge time.Duration
+}
+
+// ParsePolicies parses status:days pairs. Only messages that are done can be
+// removed, so pending and processing are rejected.
+func ParsePolicies(values []string) ([]Policy, error) {
+	policies := make([]Policy, 0, len(values))
+	seen := make(map[string]bool, len(values))
+
+	for _, value := range values {
+		name, daysStr, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid retention policy %q, expected status:days", value)
+		}
+
+		status, err := valueobject.NewMessageStatus(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if !status.IsTerminal() {
+			return nil, fmt.Errorf("retention policy for %s messages is not allowed; only finished messages can be removed", status)
+		}
+		if seen[status.String()] {
+			return nil, fmt.Errorf("duplicate retention policy for %s messages", status)
+		}
+		seen[status.String()] = true
+
+		days, err := strconv.Atoi(strings.TrimSpace(daysStr))
+		if err != nil || days < 1 {
+			return nil, fmt.Errorf("invalid retention policy %q, days must be a positive integer", value)
+		}
+
+		policies = append(policies, Policy{Status: status.String(), MaxAge: time.Duration(days) * 24 * time.Hour})
+	}
+
+	return policies, nil
+}
+
+// Result counts the messages one run removed, by status.
+type Result struct {
+	Mode       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Removed    map[string]int64
+}
+
+// Total is the number of messages removed across all statuses.
+func (r *Result) Total() int64 {
+	var total int64
+	for _, removed := range r.Removed {
+		total += removed
+	}
+	return total
+}
+
+// Status describes the configured policies and the outcome of the last run.
+type Status struct {
+	Mode         string
+	Policies     []Policy
+	Interval     time.Duration
+	LastRunAt    *time.Time
+	LastError    string
+	LastRemoved  map[string]int64
+	TotalRemoved int64
+}
+
+// Job removes messages past their status's retention every interval. Runs
+// never overlap: a manual run while one is in progress is refused.
+type Job struct {
+	repo      repository.MessageRepository
+	audit     service.AuditService
+	mode      string
+	policies  []Policy
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+
+	running sync.Mutex
+
+	mu           sync.RWMutex
+	lastRunAt    time.Time
+	lastError    string
+	lastRemoved  map[string]int64
+	totalRemoved int64
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewJob fails when cfg.Policies cannot be parsed. audit may be nil.
+func NewJob(repo repository.MessageRepository, audit service.AuditService, cfg *config.RetentionConfig) (*Job, error) {
+	policies, err := ParsePolicies(cfg.Policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		repo:      repo,
+		audit:     audit,
+		mode:      cfg.Mode,
+		policies:  policies,
+		interval:  cfg.Interval,
+		batchSize: cfg.BatchSize,
+		now:       time.Now,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+func (j *Job) Start(ctx context.Context) {
+	j.wg.Add(1)
+	go j.run(ctx)
+
+	logger.Get().Info("retention job started",
+		zap.String("mode", j.mode),
+		zap.Duration("interval", j.interval),
+		zap.Int("policies", len(j.policies)),
+	)
+}
+
+func (j *Job) Stop() {
+	close(j.stopChan)
+	j.wg.Wait()
+
+	logger.Get().Info("retention job stopped")
+}
+
+func (j *Job) Status() Status {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	status := Status{
+		Mode:         j.mode,
+		Policies:     j.policies,
+		Interval:     j.interval,
+		LastError:    j.lastError,
+		LastRemoved:  j.lastRemoved,
+		TotalRemoved: j.totalRemoved,
+	}
+	if !j.lastRunAt.IsZero() {
+		lastRunAt := j.lastRunAt
+		status.LastRunAt = &lastRunAt
+	}
+	return status
+}
+
+func (j *Job) run(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-j.stopChan:
+			return
+		case <-ticker.C:
+			// Run logs its own failures
+			_, _ = j.Run(actor.WithActor(ctx, actor.System))
+		}
+	}
+}
+
+// Run applies every policy once, in batches until no message is left to
+// remove. A failing policy does not stop the others; the first error is
+// returned along with what was removed.
+func (j *Job) Run(ctx context.Context) (*Result, error) {
+	if !j.running.TryLock() {
+		return nil, apperrors.New(apperrors.ErrorCodeConflict, "a retention run is already in progress")
+	}
+	defer j.running.Unlock()
+
+	result := &Result{
+		Mode:      j.mode,
+		StartedAt: j.now().UTC(),
+		Removed:   make(map[string]int64, len(j.policies)),
+	}
+
+	var runErr error
+	for _, policy := range j.policies {
+		removed, err := j.apply(ctx, policy, result.StartedAt.Add(-policy.MaxAge))
+		result.Removed[policy.Status] = removed
+		if err != nil && runErr == nil {
+			runErr = err
+		}
+	}
+	result.FinishedAt = j.now().UTC()
+
+	j.mu.Lock()
+	j.lastRunAt = result.FinishedAt
+	j.lastError = ""
+	if runErr != nil {
+		j.lastError = runErr.Error()
+	}
+	j.lastRemoved = result.Removed
+	j.totalRemoved += result.Total()
+	j.mu.Unlock()
+
+	if runErr != nil {
+		logger.FromContext(ctx).Error("retention run failed", zap.Error(runErr))
+	}
+	if result.Total() > 0 {
+		logger.FromContext(ctx).Info("retention run removed messages",
+			zap.String("mode", j.mode),
+			zap.Int64("removed", result.Total()),
+		)
+		j.recordAudit(ctx, result)
+	}
+
+	return result, runErr
+}
+
+// apply removes the messages in policy.Status created before cutoff.
+func (j *Job) apply(ctx context.Context, policy Policy, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		var removed int64
+		var err error
+		if j.mode == ModeHard {
+			removed, err = j.repo.PurgeOlderThan(ctx, policy.Status, cutoff, j.batchSize)
+		} else {
+			removed, err = j.repo.SoftDeleteOlderThan(ctx, policy.Status, cutoff, j.batchSize)
+		}
+		if err != nil {
+			return total, err
+		}
+
+		total += removed
+		metrics.MessagesRemoved.WithLabelValues(policy.Status, j.mode).Add(float64(removed))
+
+		if removed < int64(j.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (j *Job) recordAudit(ctx context.Context, result *Result) {
+	if j.audit == nil {
+		return
+	}
+
+	statuses := make([]string, 0, len(result.Removed))
+	for status := range result.Removed {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	details := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		details = append(details, fmt.Sprintf("%s=%d", status, result.Removed[status]))
+	}
+
+	j.audit.Record(ctx, service.AuditRecord{
+		Action:       service.AuditActionRetentionRun,
+		ResourceType: service.AuditResourceRetention,
+		Details:      j.mode + " " + strings.Join(details, " "),
+	})
+}
@@ -0,0 +1,150 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMessageRepository holds the number of removable messages per status and
+// hands them out a batch at a time.
+type fakeMessageRepository struct {
+	repository.MessageRepository
+
+	removable   map[string]int64
+	failStatus  string
+	softDeletes int
+	purges      int
+	cutoffs     map[string]time.Time
+}
+
+func (r *fakeMessageRepository) SoftDeleteOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	r.softDeletes++
+	return r.remove(status, createdBefore, limit)
+}
+
+func (r *fakeMessageRepository) PurgeOlderThan(ctx context.Context, status string, createdBefore time.Time, limit int) (int64, error) {
+	r.purges++
+	return r.remove(status, createdBefore, limit)
+}
+
+func (r *fakeMessageRepository) remove(status string, createdBefore time.Time, limit int) (int64, error) {
+	if status == r.failStatus {
+		return 0, errors.New("database unavailable")
+	}
+	r.cutoffs[status] = createdBefore
+
+	removed := r.removable[status]
+	if removed > int64(limit) {
+		removed = int64(limit)
+	}
+	r.removable[status] -= removed
+	return removed, nil
+}
+
+func newTestJob(t *testing.T, repo *fakeMessageRepository, mode string, policies ...string) *Job {
+	t.Helper()
+
+	job, err := NewJob(repo, nil, &config.RetentionConfig{
+		Mode:      mode,
+		Policies:  policies,
+		Interval:  time.Hour,
+		BatchSize: 10,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return job
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies([]string{"sent:30", " failed : 90 "})
+	assert.NoError(t, err)
+	assert.Equal(t, []Policy{
+		{Status: "sent", MaxAge: 30 * 24 * time.Hour},
+		{Status: "failed", MaxAge: 90 * 24 * time.Hour},
+	}, policies)
+
+	for _, invalid := range [][]string{
+		{"sent"},
+		{"sent:0"},
+		{"sent:soon"},
+		{"unknown:30"},
+		{"pending:30"},
+		{"processing:30"},
+		{"sent:30", "sent:60"},
+	} {
+		_, err := ParsePolicies(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestJob_RunSoftDeletesInBatches(t *testing.T) {
+	// Arrange
+	repo := &fakeMessageRepository{
+		removable: map[string]int64{"sent": 25, "failed": 3},
+		cutoffs:   make(map[string]time.Time),
+	}
+	job := newTestJob(t, repo, ModeSoft, "sent:30", "failed:90")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	job.now = func() time.Time { return now }
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"sent": 25, "failed": 3}, result.Removed)
+	assert.Equal(t, int64(28), result.Total())
+	// Three batches for sent (10, 10, 5) and one for failed
+	assert.Equal(t, 4, repo.softDeletes)
+	assert.Zero(t, repo.purges)
+	assert.Equal(t, now.Add(-30*24*time.Hour), repo.cutoffs["sent"])
+	assert.Equal(t, now.Add(-90*24*time.Hour), repo.cutoffs["failed"])
+
+	status := job.Status()
+	assert.Equal(t, int64(28), status.TotalRemoved)
+	assert.Empty(t, status.LastError)
+	assert.NotNil(t, status.LastRunAt)
+}
+
+func TestJob_RunHardModePurges(t *testing.T) {
+	// Arrange
+	repo := &fakeMessageRepository{
+		removable: map[string]int64{"cancelled": 4},
+		cutoffs:   make(map[string]time.Time),
+	}
+	job := newTestJob(t, repo, ModeHard, "cancelled:7")
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Removed["cancelled"])
+	assert.Equal(t, 1, repo.purges)
+	assert.Zero(t, repo.softDeletes)
+}
+
+func TestJob_RunContinuesPastFailingPolicy(t *testing.T) {
+	// Arrange
+	repo := &fakeMessageRepository{
+		removable:  map[string]int64{"sent": 2, "failed": 5},
+		failStatus: "sent",
+		cutoffs:    make(map[string]time.Time),
+	}
+	job := newTestJob(t, repo, ModeSoft, "sent:30", "failed:90")
+
+	// Act
+	result, err := job.Run(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, int64(5), result.Removed["failed"])
+	assert.Equal(t, "database unavailable", job.Status().LastError)
+}
@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Dispatch states recorded in the audit log when the auto-pause acts
+const (
+	dispatchActive = "dispatching"
+	dispatchPaused = "paused"
+)
+
+// HealthCheck is a dependency the auto-pause watches, such as the database
+// or the provider. Check returns nil while it is healthy.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthCheckStatus struct {
+	Name                string
+	ConsecutiveFailures int
+	LastError           string
+}
+
+type AutoPauseStatus struct {
+	Paused      bool
+	Reason      string
+	PausedAt    *time.Time
+	LastCheckAt *time.Time
+	Checks      []HealthCheckStatus
+}
+
+// AutoPause runs its health checks every interval and pauses dispatch once
+// one of them has failed threshold times in a row, so cycles stop claiming
+// messages and spending attempts on sends that cannot succeed. Dispatch
+// resumes on the first round in which every check passes. Each pause and
+// resume is logged and recorded in the audit log.
+type AutoPause struct {
+	checks    []HealthCheck
+	interval  time.Duration
+	threshold int
+	audit     service.AuditService
+
+	mu          sync.RWMutex
+	failures    []int
+	lastErrors  []string
+	paused      bool
+	reason      string
+	pausedAt    time.Time
+	lastCheckAt time.Time
+	now         func() time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewAutoPause records pauses with audit, which may be nil.
+func NewAutoPause(checks []HealthCheck, interval time.Duration, threshold int, audit service.AuditService) *AutoPause {
+	return &AutoPause{
+		checks:     checks,
+		interval:   interval,
+		threshold:  threshold,
+		audit:      audit,
+		failures:   make([]int, len(checks)),
+		lastErrors: make([]string, len(checks)),
+		now:        time.Now,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+func (a *AutoPause) Start(ctx context.Context) {
+	a.wg.Add(1)
+	go a.run(ctx)
+
+	names := make([]string, len(a.checks))
+	for i, check := range a.checks {
+		names[i] = check.Name
+	}
+	logger.Get().Info("dispatch auto-pause started",
+		zap.Strings("checks", names),
+		zap.Duration("interval", a.interval),
+		zap.Int("threshold", a.threshold),
+	)
+}
+
+func (a *AutoPause) Stop() {
+	close(a.stopChan)
+	a.wg.Wait()
+	logger.Get().Info("dispatch auto-pause stopped")
+}
+
+// PauseWhenUnhealthy skips cycles while a's health checks keep failing.
+// Call it before Start.
+func (s *Scheduler) PauseWhenUnhealthy(a *AutoPause) {
+	s.autoPause = a
+}
+
+// AutoPauseStatus returns nil when no auto-pause is configured.
+func (s *Scheduler) AutoPauseStatus() *AutoPauseStatus {
+	if s.autoPause == nil {
+		return nil
+	}
+	status := s.autoPause.Status()
+	return &status
+}
+
+// Allow reports whether dispatch may proceed.
+func (a *AutoPause) Allow() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !a.paused
+}
+
+func (a *AutoPause) Status() AutoPauseStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	status := AutoPauseStatus{Paused: a.paused, Reason: a.reason}
+	if a.paused {
+		pausedAt := a.pausedAt
+		status.PausedAt = &pausedAt
+	}
+	if !a.lastCheckAt.IsZero() {
+		lastCheckAt := a.lastCheckAt
+		status.LastCheckAt = &lastCheckAt
+	}
+	for i, check := range a.checks {
+		status.Checks = append(status.Checks, HealthCheckStatus{
+			Name:                check.Name,
+			ConsecutiveFailures: a.failures[i],
+			LastError:           a.lastErrors[i],
+		})
+	}
+	return status
+}
+
+func (a *AutoPause) run(ctx context.Context) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.stopChan:
+			return
+		case <-ticker.C:
+			a.Check(ctx)
+		}
+	}
+}
+
+// Check runs every health check once, pausing or resuming dispatch as the
+// results require.
+func (a *AutoPause) Check(ctx context.Context) {
+	errs := make([]error, len(a.checks))
+	for i, check := range a.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, a.interval)
+		errs[i] = check.Check(checkCtx)
+		cancel()
+	}
+	if ctx.Err() != nil {
+		// Shutting down, not an outage
+		return
+	}
+
+	a.mu.Lock()
+	healthy := true
+	failing := ""
+	for i, check := range a.checks {
+		if errs[i] == nil {
+			a.failures[i] = 0
+			a.lastErrors[i] = ""
+			continue
+		}
+		healthy = false
+		a.failures[i]++
+		a.lastErrors[i] = errs[i].Error()
+		if failing == "" && a.failures[i] >= a.threshold {
+			failing = fmt.Sprintf("%s health check failed %d times in a row: %v", check.Name, a.failures[i], errs[i])
+		}
+	}
+	a.lastCheckAt = a.now()
+
+	wasPaused, pausedAt := a.paused, a.pausedAt
+	switch {
+	case !a.paused && failing != "":
+		a.paused = true
+		a.reason = failing
+		a.pausedAt = a.now()
+	case a.paused && failing != "":
+		a.reason = failing
+	case a.paused && healthy:
+		a.paused = false
+		a.reason = ""
+		a.pausedAt = time.Time{}
+	}
+	paused := a.paused
+	a.mu.Unlock()
+
+	switch {
+	case !wasPaused && paused:
+		metrics.DispatchAutoPaused.Set(1)
+		logger.Get().Error("dependency unhealthy, pausing dispatch until it recovers", zap.String("reason", failing))
+		a.record(ctx, service.AuditActionDispatchAutoPaused, dispatchActive, dispatchPaused, failing)
+	case wasPaused && !paused:
+		metrics.DispatchAutoPaused.Set(0)
+		logger.Get().Info("dependencies healthy again, resuming dispatch",
+			zap.Duration("paused_for", a.now().Sub(pausedAt)),
+		)
+		a.record(ctx, service.AuditActionDispatchAutoResumed, dispatchPaused, dispatchActive, "")
+	}
+}
+
+func (a *AutoPause) record(ctx context.Context, action, from, to, details string) {
+	if a.audit == nil {
+		return
+	}
+	a.audit.Record(actor.WithActor(ctx, actor.System), service.AuditRecord{
+		Action:       action,
+		ResourceType: service.AuditResourceScheduler,
+		FromStatus:   from,
+		ToStatus:     to,
+		Details:      details,
+	})
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/stretchr/testify/assert"
+)
+
+// switchableCheck fails while err is set.
+type switchableCheck struct {
+	err error
+}
+
+func (c *switchableCheck) Check(ctx context.Context) error {
+	return c.err
+}
+
+// recordedAudit keeps the records it is given.
+type recordedAudit struct {
+	service.AuditService
+	records []service.AuditRecord
+	actors  []string
+}
+
+func (a *recordedAudit) Record(ctx context.Context, record service.AuditRecord) {
+	a.records = append(a.records, record)
+	a.actors = append(a.actors, actor.FromContext(ctx))
+}
+
+func newTestAutoPause(audit service.AuditService) (*AutoPause, *switchableCheck, *switchableCheck) {
+	database, provider := &switchableCheck{}, &switchableCheck{}
+	autoPause := NewAutoPause([]HealthCheck{
+		{Name: "database", Check: database.Check},
+		{Name: "provider", Check: provider.Check},
+	}, time.Second, 3, audit)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	autoPause.now = func() time.Time { return now }
+	return autoPause, database, provider
+}
+
+func TestAutoPause_PausesAfterConsecutiveFailures(t *testing.T) {
+	// Arrange
+	audit := &recordedAudit{}
+	autoPause, _, provider := newTestAutoPause(audit)
+	provider.err = errors.New("connection refused")
+
+	// Act
+	autoPause.Check(context.Background())
+	autoPause.Check(context.Background())
+	allowedBefore := autoPause.Allow()
+	autoPause.Check(context.Background())
+
+	// Assert
+	assert.True(t, allowedBefore)
+	assert.False(t, autoPause.Allow())
+
+	status := autoPause.Status()
+	assert.True(t, status.Paused)
+	assert.Equal(t, "provider health check failed 3 times in a row: connection refused", status.Reason)
+	assert.NotNil(t, status.PausedAt)
+	assert.Equal(t, []HealthCheckStatus{
+		{Name: "database"},
+		{Name: "provider", ConsecutiveFailures: 3, LastError: "connection refused"},
+	}, status.Checks)
+
+	if assert.Len(t, audit.records, 1) {
+		assert.Equal(t, service.AuditActionDispatchAutoPaused, audit.records[0].Action)
+		assert.Equal(t, service.AuditResourceScheduler, audit.records[0].ResourceType)
+		assert.Equal(t, status.Reason, audit.records[0].Details)
+		assert.Equal(t, actor.System, audit.actors[0])
+	}
+}
+
+func TestAutoPause_SuccessResetsFailureCount(t *testing.T) {
+	// Arrange
+	autoPause, database, _ := newTestAutoPause(nil)
+
+	// Act
+	database.err = errors.New("timeout")
+	autoPause.Check(context.Background())
+	autoPause.Check(context.Background())
+	database.err = nil
+	autoPause.Check(context.Background())
+	database.err = errors.New("timeout")
+	autoPause.Check(context.Background())
+	autoPause.Check(context.Background())
+
+	// Assert
+	assert.True(t, autoPause.Allow())
+	assert.Equal(t, 2, autoPause.Status().Checks[0].ConsecutiveFailures)
+}
+
+func TestAutoPause_ResumesWhenEveryCheckPasses(t *testing.T) {
+	// Arrange
+	audit := &recordedAudit{}
+	autoPause, database, provider := newTestAutoPause(audit)
+	database.err = errors.New("timeout")
+	provider.err = errors.New("503")
+	for i := 0; i < 3; i++ {
+		autoPause.Check(context.Background())
+	}
+
+	// Act
+	database.err = nil
+	autoPause.Check(context.Background())
+	stillPaused := !autoPause.Allow()
+	provider.err = nil
+	autoPause.Check(context.Background())
+
+	// Assert
+	assert.True(t, stillPaused)
+	assert.True(t, autoPause.Allow())
+
+	status := autoPause.Status()
+	assert.False(t, status.Paused)
+	assert.Empty(t, status.Reason)
+	assert.Nil(t, status.PausedAt)
+
+	if assert.Len(t, audit.records, 2) {
+		assert.Equal(t, service.AuditActionDispatchAutoPaused, audit.records[0].Action)
+		assert.Equal(t, service.AuditActionDispatchAutoResumed, audit.records[1].Action)
+	}
+}
+
+func TestAutoPause_IgnoresChecksCutShortByShutdown(t *testing.T) {
+	// Arrange
+	autoPause, database, _ := newTestAutoPause(nil)
+	database.err = context.Canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		autoPause.Check(ctx)
+	}
+
+	// Assert
+	assert.True(t, autoPause.Allow())
+	assert.Zero(t, autoPause.Status().Checks[0].ConsecutiveFailures)
+}
+
+func TestScheduler_SkipsCyclesWhileAutoPaused(t *testing.T) {
+	// Arrange
+	autoPause, database, _ := newTestAutoPause(nil)
+	database.err = errors.New("timeout")
+	for i := 0; i < 3; i++ {
+		autoPause.Check(context.Background())
+	}
+	svc := &processService{}
+	runs := &recordedRuns{}
+	s := NewScheduler(svc, 1, 10, 1, nil, nil, nil, nil, nil, runs)
+	s.PauseWhenUnhealthy(autoPause)
+
+	// Act
+	s.processMessages(context.Background())
+
+	// Assert
+	assert.Zero(t, svc.calls)
+	if assert.Len(t, runs.runs, 1) {
+		assert.Equal(t, SkipReasonUnhealthy, runs.runs[0].SkipReason)
+	}
+	assert.True(t, s.AutoPauseStatus().Paused)
+
+	database.err = nil
+	autoPause.Check(context.Background())
+	s.processMessages(context.Background())
+	assert.Equal(t, int64(1), svc.calls)
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+)
+
+// Dispatch modes. In interval mode messages wait for the next cycle; in eager
+// mode creating a message starts one right away.
+const (
+	DispatchModeInterval = "interval"
+	DispatchModeEager    = "eager"
+)
+
+// eagerQueueSize buffers creation events between cycles. Events that do not
+// fit are dropped by the bus, which loses nothing: the cycle they would have
+// started is already queued and claims every pending message.
+const eagerQueueSize = 256
+
+// SendOnCreate switches the scheduler to eager mode: every pending message
+// published as created on bus starts a cycle, and the cycles repeat until a
+// batch comes back short. The regular schedule keeps running for retries,
+// scheduled messages and creations on other instances. Call it before Start.
+func (s *Scheduler) SendOnCreate(bus eventbus.Bus) {
+	s.createdEvents = bus
+}
+
+// Mode returns DispatchModeEager or DispatchModeInterval.
+func (s *Scheduler) Mode() string {
+	if s.createdEvents != nil {
+		return DispatchModeEager
+	}
+	return DispatchModeInterval
+}
+
+// sendCreated drains the pending messages after a creation event. Events
+// already queued are folded into the same cycles.
+func (s *Scheduler) sendCreated(ctx context.Context, created <-chan event.MessageEvent) {
+	for {
+		for drained := false; !drained; {
+			select {
+			case <-created:
+			default:
+				drained = true
+			}
+		}
+
+		claimed := s.processMessages(ctx)
+
+		s.mu.RLock()
+		batchSize := s.batchSize
+		s.mu.RUnlock()
+		if claimed < batchSize {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// backlogService claims from a backlog of pending messages and sends them all.
+type backlogService struct {
+	service.MessageService
+
+	mu      sync.Mutex
+	backlog int
+	claims  int
+}
+
+func (s *backlogService) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims++
+	n := min(limit, s.backlog)
+	s.backlog -= n
+	messages := make([]*entity.Message, n)
+	for i := range messages {
+		messages[i] = &entity.Message{}
+	}
+	return messages, nil
+}
+
+func (s *backlogService) ProcessClaimedMessage(ctx context.Context, message *entity.Message) error {
+	return nil
+}
+
+func (s *backlogService) BatchSentCache(ctx context.Context) (context.Context, func(context.Context)) {
+	return ctx, func(context.Context) {}
+}
+
+func (s *backlogService) add(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backlog += n
+}
+
+func (s *backlogService) claimCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claims
+}
+
+func TestScheduler_EagerModeSendsOnCreate(t *testing.T) {
+	// Arrange
+	svc := &backlogService{}
+	bus := eventbus.NewInMemoryBus()
+	s := NewScheduler(svc, 2, 3600, 2, nil, nil, nil, nil, nil, nil)
+	s.SendOnCreate(bus)
+
+	assert.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+	// The first cycle runs on start; the next would be an hour away
+	assert.Eventually(t, func() bool { return svc.claimCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	svc.add(5)
+	bus.Publish(event.NewMessageEvent(event.TypeMessageCreated, uuid.New(), valueobject.MessageStatusPending))
+
+	// Assert
+	// Two full batches, then a short one ends the drain
+	assert.Eventually(t, func() bool { return svc.claimCount() == 4 }, time.Second, 10*time.Millisecond)
+	_, processed, _, _ := s.GetStats()
+	assert.Equal(t, int64(5), processed)
+	assert.Equal(t, DispatchModeEager, s.Mode())
+}
+
+func TestScheduler_EagerModeIgnoresOtherEvents(t *testing.T) {
+	// Arrange
+	svc := &backlogService{}
+	bus := eventbus.NewInMemoryBus()
+	s := NewScheduler(svc, 2, 3600, 1, nil, nil, nil, nil, nil, nil)
+	s.SendOnCreate(bus)
+
+	assert.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+	assert.Eventually(t, func() bool { return svc.claimCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	bus.Publish(event.NewMessageEvent(event.TypeMessageStatusChanged, uuid.New(), valueobject.MessageStatusPending))
+	bus.Publish(event.NewMessageEvent(event.TypeMessageCreated, uuid.New(), valueobject.MessageStatusBlocked))
+	time.Sleep(100 * time.Millisecond)
+
+	// Assert
+	assert.Equal(t, 1, svc.claimCount())
+}
+
+func TestScheduler_IntervalModeByDefault(t *testing.T) {
+	s := NewScheduler(&processService{}, 2, 10, 1, nil, nil, nil, nil, nil, nil)
+
+	assert.Equal(t, DispatchModeInterval, s.Mode())
+}
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Lane sends the messages of some priorities apart from the main cycle, on
+// an interval, batch size, worker pool and rate limit of its own, so urgent
+// traffic such as one-time passwords never waits behind a bulk backlog.
+type Lane struct {
+	Name        string
+	Priorities  []valueobject.MessagePriority
+	Interval    time.Duration
+	BatchSize   int
+	WorkerCount int
+	// RateLimitPerSecond caps the lane's sends on top of the provider's
+	// rate limit; 0 leaves only the provider's
+	RateLimitPerSecond int
+}
+
+// LaneStatus is a lane's settings and what it has sent since the start.
+type LaneStatus struct {
+	Lane
+	LastRunAt       time.Time
+	TotalProcessed  int64
+	TotalSuccessful int64
+	TotalFailed     int64
+}
+
+type lane struct {
+	Lane
+	limiter *rate.Limiter
+
+	mu              sync.Mutex
+	lastRunAt       time.Time
+	totalProcessed  int64
+	totalSuccessful int64
+	totalFailed     int64
+}
+
+// AddLane moves the messages of lane.Priorities out of the main cycle into a
+// lane that cycles every lane.Interval. A lane cycle that claims a full
+// batch runs again right away, so a burst is drained at full speed. Lane
+// cycles follow the dispatch gate, retry budget and circuit breaker like
+// the main one, but are not recorded in the run history. Call it before
+// Start.
+func (s *Scheduler) AddLane(l Lane) {
+	s.lanes = append(s.lanes, &lane{Lane: l, limiter: newLimiter(l.RateLimitPerSecond)})
+
+	inLane := make(map[valueobject.MessagePriority]bool)
+	for _, added := range s.lanes {
+		for _, priority := range added.Priorities {
+			inLane[priority] = true
+		}
+	}
+	s.mainPriorities = nil
+	for _, priority := range valueobject.MessagePriorities() {
+		if !inLane[priority] {
+			s.mainPriorities = append(s.mainPriorities, priority)
+		}
+	}
+}
+
+// LimitRate caps the sends of the main cycle at perSecond on top of the
+// provider's rate limit; 0 removes the cap. Call it before Start.
+func (s *Scheduler) LimitRate(perSecond int) {
+	s.limiter = newLimiter(perSecond)
+}
+
+// MainPriorities returns the priorities the main cycle sends, or nil when
+// there are no lanes and it sends them all.
+func (s *Scheduler) MainPriorities() []valueobject.MessagePriority {
+	return s.mainPriorities
+}
+
+// Lanes returns the status of each lane in the order they were added.
+func (s *Scheduler) Lanes() []LaneStatus {
+	statuses := make([]LaneStatus, len(s.lanes))
+	for i, l := range s.lanes {
+		l.mu.Lock()
+		statuses[i] = LaneStatus{
+			Lane:            l.Lane,
+			LastRunAt:       l.lastRunAt,
+			TotalProcessed:  l.totalProcessed,
+			TotalSuccessful: l.totalSuccessful,
+			TotalFailed:     l.totalFailed,
+		}
+		l.mu.Unlock()
+	}
+	return statuses
+}
+
+func newLimiter(perSecond int) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), perSecond)
+}
+
+func (s *Scheduler) runLane(ctx context.Context, l *lane) {
+	defer s.wg.Done()
+
+	logger.Get().Info("starting scheduler lane",
+		zap.String("lane", l.Name),
+		zap.Stringers("priorities", l.Priorities),
+		zap.Duration("interval", l.Interval),
+		zap.Int("batch_size", l.BatchSize),
+		zap.Int("worker_count", l.WorkerCount),
+	)
+
+	ticker := time.NewTicker(l.Interval)
+	defer ticker.Stop()
+
+	for {
+		for s.processLane(ctx, l) >= l.BatchSize {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopChan:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processLane runs one lane cycle and returns the number of messages it
+// claimed.
+func (s *Scheduler) processLane(ctx context.Context, l *lane) int {
+	start := time.Now()
+	result, _ := s.runCycle(ctx, cycle{
+		lane:        l.Name,
+		batchSize:   l.BatchSize,
+		workerCount: l.WorkerCount,
+		priorities:  l.Priorities,
+		limiter:     l.limiter,
+	})
+	if result == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	l.lastRunAt = start
+	l.totalProcessed += result.processed
+	l.totalSuccessful += result.successful
+	l.totalFailed += result.failed
+	l.mu.Unlock()
+
+	return result.claimed
+}
@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ReaperStatus struct {
+	Threshold     time.Duration
+	Interval      time.Duration
+	LastRunAt     *time.Time
+	LastError     string
+	TotalRequeued int64
+	TotalFailed   int64
+}
+
+// Reaper periodically releases messages that have been processing for longer
+// than threshold, which only happens when a worker died or lost its database
+// connection between claiming a message and recording the outcome. threshold
+// must comfortably exceed the longest send, or live sends get reaped too.
+type Reaper struct {
+	messageService service.MessageService
+	threshold      time.Duration
+	interval       time.Duration
+	batchSize      int
+
+	mu            sync.RWMutex
+	lastRunAt     time.Time
+	lastError     string
+	totalRequeued int64
+	totalFailed   int64
+	now           func() time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewReaper(messageService service.MessageService, threshold, interval time.Duration, batchSize int) *Reaper {
+	return &Reaper{
+		messageService: messageService,
+		threshold:      threshold,
+		interval:       interval,
+		batchSize:      batchSize,
+		now:            time.Now,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+func (r *Reaper) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+
+	logger.Get().Info("stale message reaper started",
+		zap.Duration("threshold", r.threshold),
+		zap.Duration("interval", r.interval),
+		zap.Int("batch_size", r.batchSize),
+	)
+}
+
+func (r *Reaper) Stop() {
+	close(r.stopChan)
+	r.wg.Wait()
+
+	logger.Get().Info("stale message reaper stopped")
+}
+
+func (r *Reaper) Status() ReaperStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := ReaperStatus{
+		Threshold:     r.threshold,
+		Interval:      r.interval,
+		LastError:     r.lastError,
+		TotalRequeued: r.totalRequeued,
+		TotalFailed:   r.totalFailed,
+	}
+	if !r.lastRunAt.IsZero() {
+		lastRunAt := r.lastRunAt
+		status.LastRunAt = &lastRunAt
+	}
+	return status
+}
+
+func (r *Reaper) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			r.Reap(ctx)
+		}
+	}
+}
+
+// Reap releases stale claims in batches until none are left or a batch
+// fails.
+func (r *Reaper) Reap(ctx context.Context) {
+	startedBefore := r.now().UTC().Add(-r.threshold)
+
+	var requeued, failed int
+	var reapErr error
+	for {
+		result, err := r.messageService.ReapStaleMessages(ctx, startedBefore, r.batchSize)
+		if err != nil {
+			reapErr = err
+			break
+		}
+		requeued += result.Requeued
+		failed += result.Failed
+
+		// Stop on a short batch, or when nothing in a full one could be
+		// released, so a stuck row cannot keep the loop spinning
+		if result.Found < r.batchSize || result.Requeued+result.Failed == 0 {
+			break
+		}
+	}
+
+	r.mu.Lock()
+	r.lastRunAt = r.now()
+	r.lastError = ""
+	if reapErr != nil {
+		r.lastError = reapErr.Error()
+	}
+	r.totalRequeued += int64(requeued)
+	r.totalFailed += int64(failed)
+	r.mu.Unlock()
+
+	if reapErr != nil {
+		logger.Get().Error("stale message reaper failed", zap.Error(reapErr))
+	}
+	if requeued+failed > 0 {
+		logger.Get().Warn("released stale processing messages",
+			zap.Int("requeued", requeued),
+			zap.Int("failed", failed),
+		)
+	}
+}
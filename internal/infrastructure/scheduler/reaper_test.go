@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// reapService returns the queued results in order, then empty batches.
+type reapService struct {
+	service.MessageService
+	results       []*service.ReapResult
+	err           error
+	calls         int
+	startedBefore time.Time
+}
+
+func (s *reapService) ReapStaleMessages(ctx context.Context, startedBefore time.Time, limit int) (*service.ReapResult, error) {
+	s.calls++
+	s.startedBefore = startedBefore
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.results) == 0 {
+		return &service.ReapResult{}, nil
+	}
+	result := s.results[0]
+	s.results = s.results[1:]
+	return result, nil
+}
+
+func newTestReaper(svc service.MessageService) (*Reaper, time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reaper := NewReaper(svc, 10*time.Minute, time.Minute, 2)
+	reaper.now = func() time.Time { return now }
+	return reaper, now
+}
+
+func TestReaper_DrainsFullBatches(t *testing.T) {
+	svc := &reapService{results: []*service.ReapResult{
+		{Found: 2, Requeued: 2},
+		{Found: 1, Failed: 1},
+	}}
+	reaper, now := newTestReaper(svc)
+
+	reaper.Reap(context.Background())
+
+	assert.Equal(t, 2, svc.calls)
+	assert.Equal(t, now.Add(-10*time.Minute), svc.startedBefore)
+
+	status := reaper.Status()
+	assert.Equal(t, int64(2), status.TotalRequeued)
+	assert.Equal(t, int64(1), status.TotalFailed)
+	assert.Equal(t, now, *status.LastRunAt)
+	assert.Empty(t, status.LastError)
+}
+
+func TestReaper_StopsWhenNothingCanBeReleased(t *testing.T) {
+	svc := &reapService{results: []*service.ReapResult{
+		{Found: 2},
+		{Found: 2},
+	}}
+	reaper, _ := newTestReaper(svc)
+
+	reaper.Reap(context.Background())
+
+	assert.Equal(t, 1, svc.calls)
+}
+
+func TestReaper_RecordsError(t *testing.T) {
+	svc := &reapService{err: errors.New("connection refused")}
+	reaper, _ := newTestReaper(svc)
+
+	assert.Nil(t, reaper.Status().LastRunAt)
+
+	reaper.Reap(context.Background())
+
+	status := reaper.Status()
+	assert.NotNil(t, status.LastRunAt)
+	assert.Equal(t, "connection refused", status.LastError)
+}
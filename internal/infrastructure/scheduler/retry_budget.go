@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+type budgetSample struct {
+	at        time.Time
+	attempted int
+	failed    int
+}
+
+type RetryBudgetStatus struct {
+	Paused       bool
+	TrippedAt    *time.Time
+	PausedUntil  *time.Time
+	Attempted    int
+	Failed       int
+	FailureRatio float64
+}
+
+// RetryBudget trips when the share of failed sends over a sliding window
+// exceeds maxFailureRatio. Once tripped it stays paused until pauseDuration
+// elapses, or until Resume is called when pauseDuration is zero.
+type RetryBudget struct {
+	window          time.Duration
+	maxFailureRatio float64
+	minAttempts     int
+	pauseDuration   time.Duration
+
+	mu          sync.Mutex
+	samples     []budgetSample
+	tripped     bool
+	trippedAt   time.Time
+	pausedUntil time.Time
+	now         func() time.Time
+}
+
+func NewRetryBudget(window time.Duration, maxFailureRatio float64, minAttempts int, pauseDuration time.Duration) *RetryBudget {
+	return &RetryBudget{
+		window:          window,
+		maxFailureRatio: maxFailureRatio,
+		minAttempts:     minAttempts,
+		pauseDuration:   pauseDuration,
+		now:             time.Now,
+	}
+}
+
+// Record adds the outcome of a dispatch cycle and reports whether this call
+// tripped the budget.
+func (b *RetryBudget) Record(attempted, failed int) bool {
+	if attempted == 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.samples = append(b.samples, budgetSample{at: now, attempted: attempted, failed: failed})
+	b.prune(now)
+
+	if b.tripped {
+		return false
+	}
+
+	totalAttempted, totalFailed := b.totals()
+	if totalAttempted < b.minAttempts {
+		return false
+	}
+
+	if float64(totalFailed)/float64(totalAttempted) <= b.maxFailureRatio {
+		return false
+	}
+
+	b.tripped = true
+	b.trippedAt = now
+	if b.pauseDuration > 0 {
+		b.pausedUntil = now.Add(b.pauseDuration)
+	}
+
+	return true
+}
+
+// Allow reports whether dispatch may proceed, clearing an expired pause.
+func (b *RetryBudget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.tripped {
+		return true
+	}
+
+	if !b.pausedUntil.IsZero() && !b.now().Before(b.pausedUntil) {
+		b.reset()
+		return true
+	}
+
+	return false
+}
+
+func (b *RetryBudget) Resume() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reset()
+}
+
+func (b *RetryBudget) Status() RetryBudgetStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.prune(b.now())
+	attempted, failed := b.totals()
+
+	status := RetryBudgetStatus{
+		Paused:    b.tripped,
+		Attempted: attempted,
+		Failed:    failed,
+	}
+	if attempted > 0 {
+		status.FailureRatio = float64(failed) / float64(attempted)
+	}
+	if b.tripped {
+		trippedAt := b.trippedAt
+		status.TrippedAt = &trippedAt
+		if !b.pausedUntil.IsZero() {
+			pausedUntil := b.pausedUntil
+			status.PausedUntil = &pausedUntil
+		}
+	}
+
+	return status
+}
+
+// reset clears the pause and the window so the failures that caused the trip
+// do not immediately trip it again. Callers must hold mu.
+func (b *RetryBudget) reset() {
+	b.tripped = false
+	b.trippedAt = time.Time{}
+	b.pausedUntil = time.Time{}
+	b.samples = nil
+}
+
+func (b *RetryBudget) prune(now time.Time) {
+	cutoff := now.Add(-b.window)
+	i := 0
+	for i < len(b.samples) && b.samples[i].at.Before(cutoff) {
+		i++
+	}
+	b.samples = b.samples[i:]
+}
+
+func (b *RetryBudget) totals() (attempted, failed int) {
+	for _, sample := range b.samples {
+		attempted += sample.attempted
+		failed += sample.failed
+	}
+	return attempted, failed
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBudget(pause time.Duration) (*RetryBudget, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	budget := NewRetryBudget(time.Minute, 0.5, 10, pause)
+	budget.now = func() time.Time { return now }
+	return budget, &now
+}
+
+func TestRetryBudget_TripsAboveRatio(t *testing.T) {
+	budget, _ := newTestBudget(time.Minute)
+
+	assert.False(t, budget.Record(5, 5), "below minimum attempts")
+	assert.True(t, budget.Allow())
+
+	assert.True(t, budget.Record(5, 1))
+	assert.False(t, budget.Allow())
+
+	status := budget.Status()
+	assert.True(t, status.Paused)
+	assert.Equal(t, 10, status.Attempted)
+	assert.Equal(t, 6, status.Failed)
+	assert.NotNil(t, status.PausedUntil)
+}
+
+func TestRetryBudget_StaysUnderRatio(t *testing.T) {
+	budget, _ := newTestBudget(time.Minute)
+
+	assert.False(t, budget.Record(10, 5))
+	assert.True(t, budget.Allow())
+}
+
+func TestRetryBudget_PauseExpires(t *testing.T) {
+	budget, now := newTestBudget(time.Minute)
+	budget.Record(10, 10)
+	assert.False(t, budget.Allow())
+
+	*now = now.Add(time.Minute)
+
+	assert.True(t, budget.Allow())
+	assert.False(t, budget.Status().Paused)
+	assert.Equal(t, 0, budget.Status().Attempted)
+}
+
+func TestRetryBudget_ManualResumeRequired(t *testing.T) {
+	budget, now := newTestBudget(0)
+	budget.Record(10, 10)
+
+	*now = now.Add(time.Hour)
+	assert.False(t, budget.Allow())
+	assert.Nil(t, budget.Status().PausedUntil)
+
+	budget.Resume()
+	assert.True(t, budget.Allow())
+}
+
+func TestRetryBudget_SlidingWindow(t *testing.T) {
+	budget, now := newTestBudget(time.Minute)
+	budget.Record(8, 8)
+
+	*now = now.Add(2 * time.Minute)
+
+	assert.False(t, budget.Record(10, 2), "old failures fall out of the window")
+	assert.Equal(t, 10, budget.Status().Attempted)
+}
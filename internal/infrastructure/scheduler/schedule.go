@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when the next processing cycle runs.
+type Schedule interface {
+	Next(t time.Time) time.Time
+	String() string
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+func (s intervalSchedule) String() string {
+	return "@every " + s.interval.String()
+}
+
+// cronSearchLimit bounds how far ahead Next looks for a matching minute, so
+// an expression that can never match (e.g. "0 0 31 2 *") does not loop
+// forever.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var weekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+type cronSchedule struct {
+	spec     string
+	location *time.Location
+
+	minutes  [60]bool
+	hours    [24]bool
+	days     [32]bool
+	months   [13]bool
+	weekdays [7]bool
+
+	// Standard cron semantics: when both day fields are restricted a day
+	// matching either one qualifies
+	daysAny     bool
+	weekdaysAny bool
+}
+
+// ParseSchedule parses a standard five-field cron expression (minute, hour,
+// day of month, month, day of week), such as "*/2 9-18 * * MON-FRI", or one
+// of the @hourly/@daily/@weekly/@monthly/@yearly descriptors. It is evaluated
+// in the named time zone, UTC when empty.
+func ParseSchedule(spec, timezone string) (Schedule, error) {
+	location := time.UTC
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+		location = loc
+	}
+
+	expr := strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &cronSchedule{
+		spec:        spec,
+		location:    location,
+		daysAny:     fields[2] == "*",
+		weekdaysAny: fields[4] == "*",
+	}
+
+	weekdays := make([]bool, 8)
+	parsers := []struct {
+		name     string
+		field    string
+		min, max int
+		names    map[string]int
+		set      []bool
+	}{
+		{"minute", fields[0], 0, 59, nil, s.minutes[:]},
+		{"hour", fields[1], 0, 23, nil, s.hours[:]},
+		{"day of month", fields[2], 1, 31, nil, s.days[:]},
+		{"month", fields[3], 1, 12, monthNames, s.months[:]},
+		{"day of week", fields[4], 0, 7, weekdayNames, weekdays},
+	}
+	for _, p := range parsers {
+		if err := parseCronField(p.field, p.min, p.max, p.names, p.set); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s: %w", spec, p.name, err)
+		}
+	}
+
+	// 7 is an alias for Sunday
+	copy(s.weekdays[:], weekdays[:7])
+	s.weekdays[0] = s.weekdays[0] || weekdays[7]
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", spec)
+	}
+
+	return s, nil
+}
+
+// parseCronField marks every value the field selects. A field is a comma
+// separated list of "*", a value or a range, each optionally followed by
+// "/step".
+func parseCronField(field string, min, max int, names map[string]int, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], names); err != nil {
+				return err
+			}
+			if hi, err = parseCronValue(bounds[1], names); err != nil {
+				return err
+			}
+		default:
+			value, err := parseCronValue(rangePart, names)
+			if err != nil {
+				return err
+			}
+			lo, hi = value, value
+			// "5/15" means from 5 to the end in steps of 15
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
+
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToUpper(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// Next returns the first matching minute after t, or the zero time when none
+// exists within cronSearchLimit. Fields that do not match skip ahead a whole
+// month, day or hour at a time.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.location).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case !s.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		case !s.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dayMatch := s.days[t.Day()]
+	weekdayMatch := s.weekdays[t.Weekday()]
+	if s.daysAny || s.weekdaysAny {
+		return dayMatch && weekdayMatch
+	}
+	return dayMatch || weekdayMatch
+}
+
+func (s *cronSchedule) String() string {
+	return s.spec + " (" + s.location.String() + ")"
+}
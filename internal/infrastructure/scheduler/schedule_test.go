@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule_BusinessHours(t *testing.T) {
+	schedule, err := ParseSchedule("*/30 9-17 * * MON-FRI", "Europe/Istanbul")
+	assert.NoError(t, err)
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+
+	// Friday 17:45 local: the next run is Monday at 09:00
+	next := schedule.Next(time.Date(2024, 1, 5, 17, 45, 0, 0, istanbul))
+	assert.Equal(t, time.Date(2024, 1, 8, 9, 0, 0, 0, istanbul), next)
+
+	// Within the window runs follow the minute field
+	next = schedule.Next(time.Date(2024, 1, 8, 10, 5, 0, 0, istanbul))
+	assert.Equal(t, time.Date(2024, 1, 8, 10, 30, 0, 0, istanbul), next)
+
+	assert.Equal(t, "*/30 9-17 * * MON-FRI (Europe/Istanbul)", schedule.String())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	_, err := ParseSchedule("0 9-18 * *", "")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("0 9-18 * * MON-FRI", "Mars/Olympus")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("0 9-25 * * *", "")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("0 0 31 2 *", "")
+	assert.Error(t, err, "February 31st never comes")
+}
+
+func TestParseSchedule_DayFields(t *testing.T) {
+	// Restricting both day fields matches either one
+	schedule, err := ParseSchedule("0 12 1 * SUN", "")
+	assert.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC), next)
+
+	// 7 is Sunday too, and descriptors expand to their expression
+	schedule, err = ParseSchedule("0 0 * * 7", "")
+	assert.NoError(t, err)
+	weekly, err := ParseSchedule("@weekly", "")
+	assert.NoError(t, err)
+
+	from := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), schedule.Next(from))
+	assert.Equal(t, schedule.Next(from), weekly.Next(from))
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	schedule := intervalSchedule{interval: 10 * time.Second}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(10*time.Second), schedule.Next(now))
+	assert.Equal(t, "@every 10s", schedule.String())
+}
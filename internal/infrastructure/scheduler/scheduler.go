@@ -7,23 +7,107 @@ import (
 	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/event"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/eventbus"
+	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
 	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
+// DispatchGate decides whether this instance is allowed to send messages,
+// e.g. because it holds the cross-region dispatch lease.
+type DispatchGate interface {
+	IsLeader() bool
+}
+
+// allGates lets an instance dispatch only while every gate allows it.
+type allGates []DispatchGate
+
+func (g allGates) IsLeader() bool {
+	for _, gate := range g {
+		if !gate.IsLeader() {
+			return false
+		}
+	}
+	return true
+}
+
+// AllGates combines the given gates, skipping nil ones. It returns nil when
+// none is left, so the scheduler dispatches unconditionally.
+func AllGates(gates ...DispatchGate) DispatchGate {
+	combined := make(allGates, 0, len(gates))
+	for _, gate := range gates {
+		if gate != nil {
+			combined = append(combined, gate)
+		}
+	}
+	switch len(combined) {
+	case 0:
+		return nil
+	case 1:
+		return combined[0]
+	default:
+		return combined
+	}
+}
+
+// Reasons a cycle was recorded without sending anything
+const (
+	SkipReasonRetryBudget = "retry_budget"
+	SkipReasonCircuitOpen = "circuit_open"
+	SkipReasonUnhealthy   = "unhealthy"
+
+	// skipReasonStandby is not recorded; another instance is dispatching
+	skipReasonStandby = "standby"
+)
+
+// TriggerResult is what a cycle run on demand did.
+type TriggerResult struct {
+	Claimed    int
+	Processed  int64
+	Successful int64
+	Failed     int64
+	Duration   time.Duration
+}
+
 type Scheduler struct {
 	messageService service.MessageService
 	batchSize      int
-	interval       time.Duration
+	schedule       Schedule
 	workerCount    int
+	retryBudget    *RetryBudget
+	dispatchGate   DispatchGate
+	breaker        *infrahttp.CircuitBreaker
+	reaper         *Reaper
+	autoPause      *AutoPause
+	runs           service.SchedulerRunService
+	// createdEvents is the dispatch queue of eager mode; nil in interval mode
+	createdEvents eventbus.Bus
+	// lanes send some priorities apart from the main cycle, which then only
+	// claims mainPriorities; limiter caps the main cycle's sends
+	lanes          []*lane
+	mainPriorities []valueobject.MessagePriority
+	limiter        *rate.Limiter
+
+	// cycleMu keeps a triggered cycle from overlapping a scheduled one
+	cycleMu sync.Mutex
 
 	mu           sync.RWMutex
 	isRunning    bool
 	stopChan     chan struct{}
 	stoppedChan  chan struct{}
+	reconfigured chan struct{}
 	wg           sync.WaitGroup
 
 	lastRunAt       time.Time
+	nextRunAt       time.Time
+	degraded        bool
 	totalProcessed  int64
 	totalSuccessful int64
 	totalFailed     int64
@@ -34,14 +118,31 @@ func NewScheduler(
 	batchSize int,
 	intervalSeconds int,
 	workerCount int,
+	retryBudget *RetryBudget,
+	dispatchGate DispatchGate,
+	breaker *infrahttp.CircuitBreaker,
+	reaper *Reaper,
+	schedule Schedule,
+	runs service.SchedulerRunService,
 ) *Scheduler {
+	// Without a cron schedule a cycle runs right away and then every interval
+	if schedule == nil {
+		schedule = intervalSchedule{interval: time.Duration(intervalSeconds) * time.Second}
+	}
+
 	return &Scheduler{
 		messageService: messageService,
 		batchSize:      batchSize,
-		interval:       time.Duration(intervalSeconds) * time.Second,
+		schedule:       schedule,
 		workerCount:    workerCount,
+		retryBudget:    retryBudget,
+		dispatchGate:   dispatchGate,
+		breaker:        breaker,
+		reaper:         reaper,
+		runs:           runs,
 		stopChan:       make(chan struct{}),
 		stoppedChan:    make(chan struct{}),
+		reconfigured:   make(chan struct{}, 1),
 	}
 }
 
@@ -55,17 +156,24 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	s.isRunning = true
 	s.stopChan = make(chan struct{})
 	s.stoppedChan = make(chan struct{})
+	batchSize, schedule, workerCount := s.batchSize, s.schedule, s.workerCount
 	s.mu.Unlock()
 
 	logger.Get().Info("starting message scheduler",
-		zap.Int("batch_size", s.batchSize),
-		zap.Duration("interval", s.interval),
-		zap.Int("worker_count", s.workerCount),
+		zap.String("mode", s.Mode()),
+		zap.Int("batch_size", batchSize),
+		zap.Stringer("schedule", schedule),
+		zap.Int("worker_count", workerCount),
 	)
 
 	s.wg.Add(1)
 	go s.run(ctx)
 
+	for _, l := range s.lanes {
+		s.wg.Add(1)
+		go s.runLane(ctx, l)
+	}
+
 	return nil
 }
 
@@ -86,6 +194,7 @@ func (s *Scheduler) Stop() error {
 
 	s.mu.Lock()
 	s.isRunning = false
+	s.nextRunAt = time.Time{}
 	s.mu.Unlock()
 
 	close(s.stoppedChan)
@@ -106,99 +215,467 @@ func (s *Scheduler) GetStats() (lastRunAt time.Time, processed, successful, fail
 	return s.lastRunAt, atomic.LoadInt64(&s.totalProcessed), atomic.LoadInt64(&s.totalSuccessful), atomic.LoadInt64(&s.totalFailed)
 }
 
+// Schedule describes when cycles run, e.g. "@every 10s" or a cron
+// expression with its time zone.
+func (s *Scheduler) Schedule() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.schedule.String()
+}
+
+// Config returns the current batch size, worker count and, unless a cron
+// schedule is in use, the interval between cycles.
+func (s *Scheduler) Config() (batchSize, workerCount int, interval time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if schedule, ok := s.schedule.(intervalSchedule); ok {
+		interval = schedule.interval
+	}
+	return s.batchSize, s.workerCount, interval
+}
+
+// Reconfigure changes the batch size, worker count and interval without a
+// restart. Nil values are left as they are. A cycle already in flight
+// finishes with the old batch size and worker pool; a new interval restarts
+// the wait for the next cycle from now. The interval cannot be changed while
+// a cron schedule is in use.
+func (s *Scheduler) Reconfigure(batchSize, workerCount *int, interval *time.Duration) error {
+	if batchSize != nil && *batchSize < 1 {
+		return apperrors.NewValidationError("batch size must be at least 1")
+	}
+	if workerCount != nil && *workerCount < 1 {
+		return apperrors.NewValidationError("worker count must be at least 1")
+	}
+	if interval != nil && *interval < time.Second {
+		return apperrors.NewValidationError("interval must be at least 1 second")
+	}
+
+	s.mu.Lock()
+	if interval != nil {
+		if _, ok := s.schedule.(intervalSchedule); !ok {
+			s.mu.Unlock()
+			return apperrors.New(apperrors.ErrorCodeConflict, "interval cannot be changed while a cron schedule is in use")
+		}
+		s.schedule = intervalSchedule{interval: *interval}
+	}
+	if batchSize != nil {
+		s.batchSize = *batchSize
+	}
+	if workerCount != nil {
+		s.workerCount = *workerCount
+	}
+	batch, workers, schedule := s.batchSize, s.workerCount, s.schedule
+	s.mu.Unlock()
+
+	if interval != nil {
+		// Wake the run loop so it re-arms its timer; one pending signal is
+		// enough
+		select {
+		case s.reconfigured <- struct{}{}:
+		default:
+		}
+	}
+
+	logger.Get().Info("scheduler reconfigured",
+		zap.Int("batch_size", batch),
+		zap.Stringer("schedule", schedule),
+		zap.Int("worker_count", workers),
+	)
+	return nil
+}
+
+// NextRunAt returns when the next cycle is due, or nil while stopped.
+func (s *Scheduler) NextRunAt() *time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.nextRunAt.IsZero() {
+		return nil
+	}
+	nextRunAt := s.nextRunAt
+	return &nextRunAt
+}
+
+// RetryBudgetStatus returns nil when no retry budget is configured.
+func (s *Scheduler) RetryBudgetStatus() *RetryBudgetStatus {
+	if s.retryBudget == nil {
+		return nil
+	}
+	status := s.retryBudget.Status()
+	return &status
+}
+
+// CircuitBreakerStatus returns nil when the webhook breaker is disabled.
+func (s *Scheduler) CircuitBreakerStatus() *infrahttp.CircuitBreakerStatus {
+	if s.breaker == nil {
+		return nil
+	}
+	status := s.breaker.Status()
+	return &status
+}
+
+// ReaperStatus returns nil when the stale message reaper is disabled.
+func (s *Scheduler) ReaperStatus() *ReaperStatus {
+	if s.reaper == nil {
+		return nil
+	}
+	status := s.reaper.Status()
+	return &status
+}
+
+// IsDispatching reports whether this instance currently holds every dispatch
+// lease and lock it is configured with.
+func (s *Scheduler) IsDispatching() bool {
+	return s.dispatchGate == nil || s.dispatchGate.IsLeader()
+}
+
+// IsDegraded reports whether the last cycle was skipped or cut short because
+// the webhook circuit breaker was open.
+func (s *Scheduler) IsDegraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.degraded
+}
+
+// ResumeDispatch lifts a safety pause raised by the retry budget.
+func (s *Scheduler) ResumeDispatch() {
+	if s.retryBudget == nil {
+		return
+	}
+	s.retryBudget.Resume()
+	logger.Get().Info("dispatch resumed by operator")
+}
+
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	// Subscribed before the first cycle, so a message created while it runs
+	// wakes the next one
+	var created <-chan event.MessageEvent
+	if s.createdEvents != nil {
+		events, unsubscribe := s.createdEvents.Subscribe(eagerQueueSize)
+		defer unsubscribe()
+		created = events
+	}
 
-	s.processMessages(ctx)
+	s.mu.RLock()
+	_, runNow := s.schedule.(intervalSchedule)
+	s.mu.RUnlock()
+	if runNow {
+		s.processMessages(ctx)
+	}
 
 	for {
-		select {
-		case <-ctx.Done():
-			logger.Get().Info("scheduler context cancelled")
-			return
-		case <-s.stopChan:
-			logger.Get().Info("scheduler stop signal received")
-			return
-		case <-ticker.C:
-			s.processMessages(ctx)
+		s.mu.Lock()
+		next := s.schedule.Next(time.Now())
+		s.nextRunAt = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+
+		// Eager cycles leave the timer running, so the regular cycle still
+		// picks up retries and scheduled messages on time
+	waiting:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				logger.Get().Info("scheduler context cancelled")
+				return
+			case <-s.stopChan:
+				timer.Stop()
+				logger.Get().Info("scheduler stop signal received")
+				return
+			case <-s.reconfigured:
+				timer.Stop()
+				break waiting
+			case <-timer.C:
+				s.processMessages(ctx)
+				break waiting
+			case evt := <-created:
+				if evt.Type == event.TypeMessageCreated && evt.Status.IsPending() {
+					s.sendCreated(ctx, created)
+				}
+			}
 		}
 	}
 }
 
-func (s *Scheduler) processMessages(ctx context.Context) {
+// Trigger runs one cycle of the main lane now, whether or not the scheduler
+// is started, and waits for it. It fails with a conflict naming the reason
+// when the dispatch lease, retry budget, auto-pause or circuit breaker holds
+// the cycle back. A scheduled cycle in flight is waited for first.
+func (s *Scheduler) Trigger(ctx context.Context) (*TriggerResult, error) {
+	start := time.Now()
+	result, skipReason := s.runMain(ctx)
+	if result == nil {
+		return nil, apperrors.New(apperrors.ErrorCodeConflict, "cycle skipped: "+skipReason)
+	}
+
+	logger.Get().Info("message processing cycle triggered by operator",
+		zap.Int64("processed", result.processed),
+	)
+	return &TriggerResult{
+		Claimed:    result.claimed,
+		Processed:  result.processed,
+		Successful: result.successful,
+		Failed:     result.failed,
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// processMessages runs one cycle of the main lane and returns the number of
+// messages it claimed.
+func (s *Scheduler) processMessages(ctx context.Context) int {
+	result, _ := s.runMain(ctx)
+	if result == nil {
+		return 0
+	}
+	return result.claimed
+}
+
+func (s *Scheduler) runMain(ctx context.Context) (*cycleResult, string) {
+	s.cycleMu.Lock()
+	defer s.cycleMu.Unlock()
+
 	s.mu.Lock()
 	s.lastRunAt = time.Now()
+	// Sized once per cycle so a reconfiguration applies from the next one
+	batchSize, workerCount := s.batchSize, s.workerCount
 	s.mu.Unlock()
 
-	logger.Get().Info("starting message processing cycle")
+	return s.runCycle(ctx, cycle{
+		batchSize:   batchSize,
+		workerCount: workerCount,
+		priorities:  s.mainPriorities,
+		limiter:     s.limiter,
+	})
+}
 
-	processCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+// cycle is what a cycle claims and how it sends; lane is empty for the main
+// cycle, the only one recorded in the run history.
+type cycle struct {
+	lane        string
+	batchSize   int
+	workerCount int
+	priorities  []valueobject.MessagePriority
+	limiter     *rate.Limiter
+}
 
-	jobsChan := make(chan struct{}, s.batchSize)
-	resultsChan := make(chan bool, s.batchSize)
+type cycleResult struct {
+	claimed    int
+	processed  int64
+	successful int64
+	failed     int64
+}
 
-	var workerWg sync.WaitGroup
-	for i := 0; i < s.workerCount; i++ {
-		workerWg.Add(1)
-		go s.worker(processCtx, i, jobsChan, resultsChan, &workerWg)
+// runCycle claims a batch and sends it. It returns nil and the reason when
+// the cycle was skipped.
+func (s *Scheduler) runCycle(ctx context.Context, c cycle) (*cycleResult, string) {
+	cycleStart := time.Now()
+	log := logger.Get()
+	// Lanes cycle often, so their skips are only logged at debug level
+	logSkip := log.Warn
+	if c.lane != "" {
+		log = log.With(zap.String("lane", c.lane))
+		logSkip = log.Debug
 	}
 
-	go func() {
-		for i := 0; i < s.batchSize; i++ {
-			select {
-			case <-processCtx.Done():
-				return
-			case jobsChan <- struct{}{}:
-			}
+	if s.dispatchGate != nil && !s.dispatchGate.IsLeader() {
+		log.Debug("skipping message processing cycle, another instance or region is dispatching")
+		return nil, skipReasonStandby
+	}
+
+	if s.retryBudget != nil && !s.retryBudget.Allow() {
+		logSkip("skipping message processing cycle, dispatch paused by retry budget")
+		if c.lane == "" {
+			s.recordRun(ctx, &repository.SchedulerRun{StartedAt: cycleStart, SkipReason: SkipReasonRetryBudget})
 		}
-		close(jobsChan)
-	}()
+		return nil, SkipReasonRetryBudget
+	}
 
-	go func() {
-		workerWg.Wait()
-		close(resultsChan)
-	}()
+	if s.autoPause != nil && !s.autoPause.Allow() {
+		logSkip("skipping message processing cycle, dispatch paused until dependencies are healthy")
+		if c.lane == "" {
+			s.recordRun(ctx, &repository.SchedulerRun{StartedAt: cycleStart, SkipReason: SkipReasonUnhealthy})
+		}
+		return nil, SkipReasonUnhealthy
+	}
+
+	if !s.breaker.Accepting() {
+		s.setDegraded(true)
+		logSkip("skipping message processing cycle, webhook circuit breaker is open")
+		if c.lane == "" {
+			s.recordRun(ctx, &repository.SchedulerRun{StartedAt: cycleStart, SkipReason: SkipReasonCircuitOpen})
+		}
+		return nil, SkipReasonCircuitOpen
+	}
+
+	if c.lane == "" {
+		log.Info("starting message processing cycle")
+	}
+
+	processCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	if len(c.priorities) > 0 {
+		processCtx = repository.WithClaimPriorities(processCtx, c.priorities)
+	}
 
 	successful := int64(0)
 	failed := int64(0)
-	for result := range resultsChan {
-		if result {
-			successful++
-		} else {
+	deferred := int64(0)
+	errorCount := 0
+	lastError := ""
+
+	// One query claims the whole batch; the workers share it out over a
+	// channel instead of each claiming a message of its own
+	messages, err := s.messageService.ClaimPendingMessages(processCtx, c.batchSize)
+	if err != nil {
+		log.Error("failed to claim pending messages", zap.Error(err))
+		errorCount++
+		lastError = err.Error()
+	}
+
+	// An idle lane cycle has nothing to report
+	if c.lane != "" && len(messages) == 0 {
+		return &cycleResult{}, ""
+	}
+
+	defer func() {
+		metrics.SchedulerCycleDuration.Observe(time.Since(cycleStart).Seconds())
+	}()
+
+	jobsChan := make(chan *entity.Message, len(messages))
+	for _, message := range messages {
+		jobsChan <- message
+	}
+	close(jobsChan)
+
+	workerCount := c.workerCount
+	if workerCount > len(messages) {
+		workerCount = len(messages)
+	}
+	resultsChan := make(chan error, len(messages))
+
+	// Sent messages are cached together once the workers are done, in one
+	// round trip instead of one each
+	workerCtx, flushSent := processCtx, func(context.Context) {}
+	if len(messages) > 0 {
+		workerCtx, flushSent = s.messageService.BatchSentCache(processCtx)
+	}
+
+	var workerWg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		workerWg.Add(1)
+		go s.worker(workerCtx, c.limiter, jobsChan, resultsChan, &workerWg)
+	}
+	workerWg.Wait()
+	close(resultsChan)
+	flushSent(context.WithoutCancel(processCtx))
+
+	for err := range resultsChan {
+		// A message held for quiet hours or the daily quota was neither sent
+		// nor failed
+		if service.IsDeferred(err) {
+			deferred++
+			continue
+		}
+		if err != nil {
 			failed++
+			continue
 		}
+		successful++
+	}
+
+	// Workers stop early when the lease is lost, the breaker opens or the
+	// cycle times out; whatever they left is handed back for the next cycle
+	var unsent []*entity.Message
+	for message := range jobsChan {
+		unsent = append(unsent, message)
 	}
+	if len(unsent) > 0 {
+		s.messageService.ReleaseClaimedMessages(context.WithoutCancel(processCtx), unsent)
+	}
+
+	// The breaker may have opened mid-cycle, leaving part of the batch unsent
+	degraded := s.breaker.Status().State != infrahttp.BreakerClosed
+	s.setDegraded(degraded)
 
 	processed := successful + failed
 	atomic.AddInt64(&s.totalProcessed, processed)
 	atomic.AddInt64(&s.totalSuccessful, successful)
 	atomic.AddInt64(&s.totalFailed, failed)
 
-	logger.Get().Info("message processing cycle completed",
+	log.Info("message processing cycle completed",
 		zap.Int64("processed", processed),
 		zap.Int64("successful", successful),
 		zap.Int64("failed", failed),
+		zap.Int64("deferred", deferred),
+		zap.Bool("degraded", degraded),
 	)
+
+	if c.lane == "" {
+		s.recordRun(ctx, &repository.SchedulerRun{
+			StartedAt:  cycleStart,
+			Duration:   time.Since(cycleStart),
+			Processed:  int(processed),
+			Successful: int(successful),
+			Failed:     int(failed),
+			Errors:     errorCount,
+			LastError:  lastError,
+		})
+	}
+
+	if s.retryBudget != nil && s.retryBudget.Record(int(processed), int(failed)) {
+		status := s.retryBudget.Status()
+		log.Error("ALERT: retry budget exhausted, dispatch paused",
+			zap.Bool("alert", true),
+			zap.Int("attempted", status.Attempted),
+			zap.Int("failed", status.Failed),
+			zap.Float64("failure_ratio", status.FailureRatio),
+			zap.Timep("paused_until", status.PausedUntil),
+		)
+	}
+
+	return &cycleResult{
+		claimed:    len(messages),
+		processed:  processed,
+		successful: successful,
+		failed:     failed,
+	}, ""
+}
+
+// recordRun adds the cycle to the run history, when one is kept
+func (s *Scheduler) recordRun(ctx context.Context, run *repository.SchedulerRun) {
+	if s.runs == nil {
+		return
+	}
+	s.runs.Record(ctx, run)
+}
+
+func (s *Scheduler) setDegraded(degraded bool) {
+	s.mu.Lock()
+	s.degraded = degraded
+	s.mu.Unlock()
 }
 
-func (s *Scheduler) worker(ctx context.Context, id int, jobs <-chan struct{}, results chan<- bool, wg *sync.WaitGroup) {
+func (s *Scheduler) worker(ctx context.Context, limiter *rate.Limiter, jobs <-chan *entity.Message, results chan<- error, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	for {
-		select {
-		case <-ctx.Done():
+	// Stop taking messages as soon as the lease is lost or the breaker
+	// opens mid-cycle
+	for ctx.Err() == nil &&
+		(s.dispatchGate == nil || s.dispatchGate.IsLeader()) &&
+		s.breaker.Accepting() {
+		// Waits before taking a message, so one the cycle runs out of time
+		// for is still in jobs to be released
+		if limiter != nil && limiter.Wait(ctx) != nil {
 			return
-		case _, ok := <-jobs:
-			if !ok {
-				return
-			}
-
-			_, err := s.messageService.ProcessPendingMessages(ctx, 1)
-			results <- (err == nil)
 		}
+		message, ok := <-jobs
+		if !ok {
+			return
+		}
+
+		results <- s.messageService.ProcessClaimedMessage(ctx, message)
 	}
 }
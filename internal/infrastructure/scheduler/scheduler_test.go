@@ -0,0 +1,389 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// processService counts the batches the scheduler claims.
+type processService struct {
+	service.MessageService
+	calls int64
+}
+
+func (s *processService) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	atomic.AddInt64(&s.calls, 1)
+	return nil, nil
+}
+
+func TestScheduler_Reconfigure(t *testing.T) {
+	s := NewScheduler(&processService{}, 2, 10, 5, nil, nil, nil, nil, nil, nil)
+
+	batchSize, workerCount := 20, 8
+	interval := 30 * time.Second
+	err := s.Reconfigure(&batchSize, &workerCount, &interval)
+
+	assert.NoError(t, err)
+	gotBatch, gotWorkers, gotInterval := s.Config()
+	assert.Equal(t, 20, gotBatch)
+	assert.Equal(t, 8, gotWorkers)
+	assert.Equal(t, 30*time.Second, gotInterval)
+	assert.Equal(t, "@every 30s", s.Schedule())
+}
+
+func TestScheduler_ReconfigureKeepsOmittedValues(t *testing.T) {
+	s := NewScheduler(&processService{}, 2, 10, 5, nil, nil, nil, nil, nil, nil)
+
+	workerCount := 3
+	err := s.Reconfigure(nil, &workerCount, nil)
+
+	assert.NoError(t, err)
+	batchSize, gotWorkers, interval := s.Config()
+	assert.Equal(t, 2, batchSize)
+	assert.Equal(t, 3, gotWorkers)
+	assert.Equal(t, 10*time.Second, interval)
+}
+
+func TestScheduler_ReconfigureRejectsInvalidValues(t *testing.T) {
+	s := NewScheduler(&processService{}, 2, 10, 5, nil, nil, nil, nil, nil, nil)
+
+	zero := 0
+	short := 100 * time.Millisecond
+
+	assert.Error(t, s.Reconfigure(&zero, nil, nil))
+	assert.Error(t, s.Reconfigure(nil, &zero, nil))
+	assert.Error(t, s.Reconfigure(nil, nil, &short))
+
+	batchSize, workerCount, interval := s.Config()
+	assert.Equal(t, 2, batchSize)
+	assert.Equal(t, 5, workerCount)
+	assert.Equal(t, 10*time.Second, interval)
+}
+
+func TestScheduler_ReconfigureIntervalWithCronSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("*/5 * * * *", "UTC")
+	assert.NoError(t, err)
+	s := NewScheduler(&processService{}, 2, 10, 5, nil, nil, nil, nil, schedule, nil)
+
+	interval := time.Minute
+	err = s.Reconfigure(nil, nil, &interval)
+
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperrors.ErrorCodeConflict, appErr.Code)
+
+	_, _, gotInterval := s.Config()
+	assert.Zero(t, gotInterval)
+}
+
+func TestScheduler_ReconfigureIntervalRearmsTimer(t *testing.T) {
+	svc := &processService{}
+	s := NewScheduler(svc, 1, 3600, 1, nil, nil, nil, nil, nil, nil)
+
+	assert.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+
+	// The first cycle runs on start; the next would be an hour away
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&svc.calls) == 1 }, time.Second, 10*time.Millisecond)
+
+	interval := time.Second
+	assert.NoError(t, s.Reconfigure(nil, nil, &interval))
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&svc.calls) >= 2 }, 3*time.Second, 10*time.Millisecond)
+	assert.WithinDuration(t, time.Now(), *s.NextRunAt(), 2*time.Second)
+}
+
+// batchService claims batchSize messages and hands out the given results
+// in turn, one per message sent.
+type batchService struct {
+	service.MessageService
+	claimErr error
+
+	mu       sync.Mutex
+	claims   int
+	results  []error
+	released []*entity.Message
+}
+
+func (s *batchService) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims++
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	messages := make([]*entity.Message, limit)
+	for i := range messages {
+		messages[i] = &entity.Message{}
+	}
+	return messages, nil
+}
+
+func (s *batchService) ProcessClaimedMessage(ctx context.Context, message *entity.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.results[0]
+	s.results = s.results[1:]
+	return err
+}
+
+func (s *batchService) BatchSentCache(ctx context.Context) (context.Context, func(context.Context)) {
+	return ctx, func(context.Context) {}
+}
+
+func (s *batchService) ReleaseClaimedMessages(ctx context.Context, messages []*entity.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.released = append(s.released, messages...)
+}
+
+type recordedRuns struct {
+	runs []*repository.SchedulerRun
+}
+
+func (r *recordedRuns) Record(ctx context.Context, run *repository.SchedulerRun) {
+	r.runs = append(r.runs, run)
+}
+
+func (r *recordedRuns) ListRuns(ctx context.Context, page, pageSize int) (*dto.SchedulerRunListResponse, error) {
+	return nil, nil
+}
+
+func TestScheduler_RecordsCycle(t *testing.T) {
+	svc := &batchService{results: []error{nil, errors.New("webhook failed"), nil}}
+	runs := &recordedRuns{}
+	s := NewScheduler(svc, 3, 10, 1, nil, nil, nil, nil, nil, runs)
+
+	s.processMessages(context.Background())
+
+	if assert.Len(t, runs.runs, 1) {
+		run := runs.runs[0]
+		assert.Equal(t, 3, run.Processed)
+		assert.Equal(t, 2, run.Successful)
+		assert.Equal(t, 1, run.Failed)
+		assert.Zero(t, run.Errors)
+		assert.Empty(t, run.SkipReason)
+		assert.False(t, run.StartedAt.IsZero())
+	}
+
+	svc = &batchService{claimErr: errors.New("claim failed")}
+	s = NewScheduler(svc, 3, 10, 1, nil, nil, nil, nil, nil, runs)
+
+	s.processMessages(context.Background())
+
+	if assert.Len(t, runs.runs, 2) {
+		run := runs.runs[1]
+		assert.Zero(t, run.Processed)
+		assert.Equal(t, 1, run.Errors)
+		assert.Equal(t, "claim failed", run.LastError)
+	}
+}
+
+func TestScheduler_DoesNotCountDeferredAsFailed(t *testing.T) {
+	deferred := apperrors.New(apperrors.ErrorCodeQuietHours, "recipient is in quiet hours")
+	svc := &batchService{results: []error{deferred, nil, deferred}}
+	runs := &recordedRuns{}
+	s := NewScheduler(svc, 3, 10, 1, nil, nil, nil, nil, nil, runs)
+
+	s.processMessages(context.Background())
+
+	if assert.Len(t, runs.runs, 1) {
+		assert.Equal(t, 1, runs.runs[0].Processed)
+		assert.Equal(t, 1, runs.runs[0].Successful)
+		assert.Zero(t, runs.runs[0].Failed)
+	}
+	assert.Zero(t, s.totalFailed)
+}
+
+func TestScheduler_ClaimsBatchOnceForAllWorkers(t *testing.T) {
+	svc := &batchService{results: make([]error, 10)}
+	s := NewScheduler(svc, 10, 10, 4, nil, nil, nil, nil, nil, nil)
+
+	s.processMessages(context.Background())
+
+	assert.Equal(t, 1, svc.claims)
+	assert.Empty(t, svc.results)
+	assert.Empty(t, svc.released)
+	assert.Equal(t, int64(10), s.totalSuccessful)
+}
+
+// countdownGate loses the lease after allowing n checks.
+type countdownGate struct {
+	n int64
+}
+
+func (g *countdownGate) IsLeader() bool {
+	return atomic.AddInt64(&g.n, -1) >= 0
+}
+
+func TestScheduler_ReleasesUnsentMessages(t *testing.T) {
+	svc := &batchService{results: []error{nil, nil, nil}}
+	// The cycle's own check and the worker's first message
+	s := NewScheduler(svc, 3, 10, 1, nil, &countdownGate{n: 2}, nil, nil, nil, nil)
+
+	s.processMessages(context.Background())
+
+	assert.Len(t, svc.results, 2)
+	assert.Len(t, svc.released, 2)
+	assert.Equal(t, int64(1), s.totalProcessed)
+}
+
+func TestScheduler_RecordsSkippedCycle(t *testing.T) {
+	budget := NewRetryBudget(time.Minute, 0.5, 1, time.Minute)
+	budget.Record(1, 1)
+	runs := &recordedRuns{}
+	s := NewScheduler(&processService{}, 1, 10, 1, budget, fixedGate(true), nil, nil, nil, runs)
+
+	s.processMessages(context.Background())
+
+	if assert.Len(t, runs.runs, 1) {
+		assert.Equal(t, SkipReasonRetryBudget, runs.runs[0].SkipReason)
+		assert.Zero(t, runs.runs[0].Processed)
+	}
+
+	// Instances that are not dispatching leave the history to the one that is
+	s = NewScheduler(&processService{}, 1, 10, 1, nil, fixedGate(false), nil, nil, nil, runs)
+	s.processMessages(context.Background())
+	assert.Len(t, runs.runs, 1)
+}
+
+func TestScheduler_TriggerRunsCycleWhileStopped(t *testing.T) {
+	// Arrange
+	svc := &batchService{results: []error{nil, errors.New("webhook failed"), nil}}
+	runs := &recordedRuns{}
+	s := NewScheduler(svc, 3, 10, 2, nil, nil, nil, nil, nil, runs)
+
+	// Act
+	result, err := s.Trigger(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, s.IsRunning())
+	assert.Equal(t, 3, result.Claimed)
+	assert.Equal(t, int64(3), result.Processed)
+	assert.Equal(t, int64(2), result.Successful)
+	assert.Equal(t, int64(1), result.Failed)
+	assert.Len(t, runs.runs, 1)
+	assert.Equal(t, int64(3), s.totalProcessed)
+}
+
+func TestScheduler_TriggerReportsSkippedCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  func() *RetryBudget
+		gate    DispatchGate
+		message string
+	}{
+		{
+			name: "retry budget paused",
+			budget: func() *RetryBudget {
+				budget := NewRetryBudget(time.Minute, 0.5, 1, time.Minute)
+				budget.Record(1, 1)
+				return budget
+			},
+			message: "cycle skipped: " + SkipReasonRetryBudget,
+		},
+		{
+			name:    "another instance dispatching",
+			budget:  func() *RetryBudget { return nil },
+			gate:    fixedGate(false),
+			message: "cycle skipped: " + skipReasonStandby,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			svc := &processService{}
+			s := NewScheduler(svc, 1, 10, 1, tt.budget(), tt.gate, nil, nil, nil, nil)
+
+			// Act
+			result, err := s.Trigger(context.Background())
+
+			// Assert
+			assert.Nil(t, result)
+			appErr, ok := err.(*apperrors.AppError)
+			if assert.True(t, ok) {
+				assert.Equal(t, apperrors.ErrorCodeConflict, appErr.Code)
+				assert.Equal(t, tt.message, appErr.Message)
+			}
+			assert.Zero(t, svc.calls)
+		})
+	}
+}
+
+type fixedGate bool
+
+func (g fixedGate) IsLeader() bool {
+	return bool(g)
+}
+
+func TestAllGates(t *testing.T) {
+	assert.Nil(t, AllGates())
+	assert.Nil(t, AllGates(nil))
+	assert.Equal(t, fixedGate(true), AllGates(nil, fixedGate(true)))
+
+	assert.True(t, AllGates(fixedGate(true), fixedGate(true)).IsLeader())
+	assert.False(t, AllGates(fixedGate(true), fixedGate(false)).IsLeader())
+}
+
+// priorityService records the claim priorities each claim carries.
+type priorityService struct {
+	service.MessageService
+
+	mu     sync.Mutex
+	claims [][]valueobject.MessagePriority
+}
+
+func (s *priorityService) ClaimPendingMessages(ctx context.Context, limit int) ([]*entity.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims = append(s.claims, repository.ClaimPriorities(ctx))
+	return nil, nil
+}
+
+func (s *priorityService) claimed(priorities []valueobject.MessagePriority) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, claim := range s.claims {
+		if assert.ObjectsAreEqual(priorities, claim) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScheduler_AddLaneSplitsPriorities(t *testing.T) {
+	// Arrange
+	svc := &priorityService{}
+	s := NewScheduler(svc, 1, 3600, 1, nil, nil, nil, nil, nil, nil)
+	high := []valueobject.MessagePriority{valueobject.MessagePriorityHigh}
+	rest := []valueobject.MessagePriority{valueobject.MessagePriorityNormal, valueobject.MessagePriorityLow}
+
+	// Act
+	s.AddLane(Lane{Name: "priority", Priorities: high, Interval: time.Hour, BatchSize: 1, WorkerCount: 1})
+	assert.NoError(t, s.Start(context.Background()))
+	defer s.Stop()
+
+	// Assert
+	assert.Equal(t, rest, s.MainPriorities())
+	assert.Eventually(t, func() bool { return svc.claimed(high) && svc.claimed(rest) }, time.Second, 10*time.Millisecond)
+	if lanes := s.Lanes(); assert.Len(t, lanes, 1) {
+		assert.Equal(t, "priority", lanes[0].Name)
+	}
+}
@@ -0,0 +1,136 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/scheduler"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Service names reported through grpc.health.v1. The empty name is the
+// overall server status and is SERVING only when the database and Redis are
+// reachable; a stopped scheduler is reported on its own entry so that pausing
+// dispatch does not pull the API out of rotation. Running without Redis,
+// there is no redis entry and the overall status follows the database.
+const (
+	ServiceOverall   = ""
+	ServiceDatabase  = "database"
+	ServiceRedis     = "redis"
+	ServiceScheduler = "scheduler"
+)
+
+type Server struct {
+	cfg          *config.GRPCConfig
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	db           *persistence.GormDB
+	redis        *cache.RedisCache
+	scheduler    *scheduler.Scheduler
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewServer(
+	cfg *config.GRPCConfig,
+	db *persistence.GormDB,
+	redis *cache.RedisCache,
+	scheduler *scheduler.Scheduler,
+) *Server {
+	grpcServer := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	return &Server{
+		cfg:          cfg,
+		grpcServer:   grpcServer,
+		healthServer: healthServer,
+		db:           db,
+		redis:        redis,
+		scheduler:    scheduler,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", ":"+s.cfg.Port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on gRPC port: %w", err)
+	}
+
+	s.updateHealth()
+
+	s.wg.Add(1)
+	go s.watchHealth()
+
+	go func() {
+		logger.Get().Info("starting gRPC server", zap.String("port", s.cfg.Port))
+		if err := s.grpcServer.Serve(listener); err != nil {
+			logger.Get().Error("gRPC server stopped with error", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+
+	// Flip every service to NOT_SERVING so watchers drain before we go away
+	s.healthServer.Shutdown()
+	s.grpcServer.GracefulStop()
+
+	logger.Get().Info("gRPC server stopped")
+}
+
+func (s *Server) watchHealth() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.updateHealth()
+		}
+	}
+}
+
+func (s *Server) updateHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dbHealthy := s.db.HealthCheck(ctx) == nil
+	redisHealthy := true
+	if s.redis != nil {
+		redisHealthy = s.redis.HealthCheck(ctx) == nil
+		s.healthServer.SetServingStatus(ServiceRedis, servingStatus(redisHealthy))
+	}
+	schedulerRunning := s.scheduler.IsRunning()
+
+	s.healthServer.SetServingStatus(ServiceDatabase, servingStatus(dbHealthy))
+	s.healthServer.SetServingStatus(ServiceScheduler, servingStatus(schedulerRunning))
+	s.healthServer.SetServingStatus(ServiceOverall, servingStatus(dbHealthy && redisHealthy))
+}
+
+func servingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
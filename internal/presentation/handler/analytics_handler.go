@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetDeliveryAnalytics godoc
+// @Summary Report delivery per country and number prefix
+// @Description Count the SMS messages created within a range that were sent, delivered and failed, per country calling code and per number prefix (the country code followed by prefix_digits digits), with their sent and failed ratios. Both lists are ordered by failed messages, then by volume. Each prefix names the carrier its numbers were assigned to when known; ported numbers make this a hint. Without from the range covers the last 7 days. Tenant API keys only count their own messages.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "RFC 3339 timestamp, inclusive"
+// @Param to query string false "RFC 3339 timestamp, exclusive (default now)"
+// @Param prefix_digits query int false "Digits after the country code that make a prefix" default(3) minimum(1) maximum(6)
+// @Param min_messages query int false "Leave out prefixes with fewer messages" default(0) minimum(0)
+// @Param limit query int false "Most prefixes to list" default(50) minimum(1) maximum(500)
+// @Success 200 {object} dto.DeliveryAnalyticsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/analytics/delivery [get]
+func (h *AnalyticsHandler) GetDeliveryAnalytics(c *gin.Context) {
+	var req dto.DeliveryAnalyticsRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	result, err := h.analyticsService.GetDeliveryByPrefix(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
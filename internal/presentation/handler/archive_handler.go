@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/archive"
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveHandler struct {
+	job *archive.Job
+}
+
+func NewArchiveHandler(job *archive.Job) *ArchiveHandler {
+	return &ArchiveHandler{
+		job: job,
+	}
+}
+
+// ListArchives godoc
+// @Summary List message archives
+// @Description List the archive files messages were moved to, oldest messages first. With from and to, only files holding messages created in that range are listed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "RFC 3339 timestamp, inclusive"
+// @Param to query string false "RFC 3339 timestamp, exclusive"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ArchiveListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/archives [get]
+func (h *ArchiveHandler) ListArchives(c *gin.Context) {
+	var req dto.ListArchivesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "from must be before to",
+		})
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	filter := repository.MessageArchiveFilter{CreatedAfter: req.From, CreatedBefore: req.To}
+	archives, total, err := h.job.List(c.Request.Context(), filter, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	responses := make([]dto.ArchiveResponse, len(archives))
+	for i, archive := range archives {
+		responses[i] = dto.ArchiveResponse{
+			ID:             archive.ID.String(),
+			ObjectKey:      archive.ObjectKey,
+			Format:         archive.Format,
+			MessageCount:   archive.MessageCount,
+			SizeBytes:      archive.SizeBytes,
+			FirstCreatedAt: archive.FirstCreatedAt,
+			LastCreatedAt:  archive.LastCreatedAt,
+			ArchivedAt:     archive.ArchivedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.ArchiveListResponse{
+		Archives:   responses,
+		TotalCount: int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	})
+}
+
+// RunArchive godoc
+// @Summary Run the archive job now
+// @Description Move every finished message older than ARCHIVE_MAX_AGE to object storage and delete it from the database
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ArchiveRunResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/archives/run [post]
+func (h *ArchiveHandler) RunArchive(c *gin.Context) {
+	// A run the client stops waiting for still finishes, like a scheduled one
+	result, err := h.job.Run(context.WithoutCancel(c.Request.Context()))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ArchiveRunResponse{
+		StartedAt:  result.StartedAt,
+		FinishedAt: result.FinishedAt,
+		Files:      result.Files,
+		Archived:   result.Archived,
+	})
+}
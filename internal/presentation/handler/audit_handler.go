@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLog godoc
+// @Summary List audit log entries
+// @Description Retrieve recorded writes (message creates, status changes, cancels, retries and scheduler control), newest first, optionally filtered. Tenant API keys only see entries for their own messages.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param action query string false "Action, e.g. message.cancelled or scheduler.started"
+// @Param actor query string false "Actor: api_token, admin_token, tenant:<id>, anonymous, system, kafka or provider"
+// @Param resource_type query string false "Resource type" Enums(message, scheduler)
+// @Param resource_id query string false "Resource ID, e.g. a message ID"
+// @Param request_id query string false "X-Request-ID of the request that made the write"
+// @Param after query string false "RFC 3339 timestamp, inclusive"
+// @Param before query string false "RFC 3339 timestamp, exclusive"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.AuditLogListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	var req dto.ListAuditLogRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	result, err := h.auditService.ListAuditLog(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// FieldError is the reason one request field was rejected. Field is the
+// name the client sent it under, dotted for nested fields.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// bindJSON decodes and validates the JSON body into req, answering 400, or
+// 413 for a body over the size limit, when it cannot. It reports whether
+// the handler should go on.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondBindError(c, req, err)
+		return false
+	}
+	return true
+}
+
+// bindStrictJSON is bindJSON rejecting fields req does not have, so a
+// misspelled option fails instead of being ignored.
+func bindStrictJSON(c *gin.Context, req interface{}) bool {
+	if c.Request.Body == nil {
+		respondBindError(c, req, io.EOF)
+		return false
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(req)
+	if err == nil && decoder.More() {
+		err = errors.New("body must hold a single JSON object")
+	}
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	if err != nil {
+		respondBindError(c, req, err)
+		return false
+	}
+	return true
+}
+
+// bindQuery binds and validates the query string into req like bindJSON.
+func bindQuery(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		respondBindError(c, req, err)
+		return false
+	}
+	return true
+}
+
+// respondBindError turns a binding error into a validation error listing
+// each rejected field, in place of the decoder's or validator's own text.
+func respondBindError(c *gin.Context, req interface{}, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error: fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit),
+			Code:  string(apperrors.ErrorCodeBodyTooLarge),
+		})
+		return
+	}
+
+	resp := ErrorResponse{
+		Error: "request validation failed",
+		Code:  string(apperrors.ErrorCodeValidation),
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case len(validationErrors(err)) > 0:
+		for _, fieldErr := range validationErrors(err) {
+			resp.Fields = append(resp.Fields, FieldError{
+				Field:   fieldPath(reflect.TypeOf(req), fieldErr.StructNamespace()),
+				Message: validationMessage(fieldErr),
+			})
+		}
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		resp.Error = "request body must be a JSON object"
+	case errors.As(err, &typeErr):
+		resp.Fields = []FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be %s, not %s", typeName(typeErr.Type), typeErr.Value),
+		}}
+	case errors.As(err, &syntaxErr):
+		resp.Error = fmt.Sprintf("malformed JSON at byte %d", syntaxErr.Offset)
+	case errors.Is(err, io.EOF):
+		resp.Error = "request body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		resp.Error = "malformed JSON: unexpected end of body"
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		resp.Fields = []FieldError{{Field: field, Message: "is not a known field"}}
+	default:
+		resp.Error = err.Error()
+	}
+
+	c.JSON(http.StatusBadRequest, resp)
+}
+
+// fieldError is what the validator gin binds with reports for each field
+// that failed a check.
+type fieldError interface {
+	Tag() string
+	Param() string
+	Kind() reflect.Kind
+	StructNamespace() string
+}
+
+// validationErrors lists the failed checks err reports, if it comes from
+// the validator.
+func validationErrors(err error) []fieldError {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	fieldErrs := make([]fieldError, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		fieldErr, ok := v.Index(i).Interface().(fieldError)
+		if !ok {
+			return nil
+		}
+		fieldErrs = append(fieldErrs, fieldErr)
+	}
+	return fieldErrs
+}
+
+// fieldPath turns a validator namespace such as
+// "CreateMessageRequest.Metadata[key]" into the path the client sent the
+// field under, "metadata[key]", using the JSON or query names of t.
+func fieldPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, len(segments))
+	for i, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		path[i] = name + index
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			continue
+		}
+		path[i] = tagName(field) + index
+		t = field.Type
+	}
+	return strings.Join(path, ".")
+}
+
+// tagName is the name field is decoded from, JSON or query.
+func tagName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validationMessage(fieldErr fieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if isCollection(fieldErr.Kind()) {
+			return fmt.Sprintf("must have at least %s items", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		if isCollection(fieldErr.Kind()) {
+			return fmt.Sprintf("must have at most %s items", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	case "email":
+		return "must be an email address"
+	case "url":
+		return "must be a URL"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	default:
+		if fieldErr.Param() != "" {
+			return fmt.Sprintf("failed the %s=%s check", fieldErr.Tag(), fieldErr.Param())
+		}
+		return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+	}
+}
+
+func isCollection(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+// typeName is the JSON name of the type a field expected.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindingTestItem struct {
+	Name  string `json:"name" binding:"required"`
+	Count int    `json:"count" binding:"min=1"`
+}
+
+type bindingTestRequest struct {
+	Title string            `json:"title" binding:"required,max=5"`
+	Items []bindingTestItem `json:"items" binding:"dive"`
+}
+
+func serveBinding(bind func(*gin.Context, interface{}) bool, maxBytes int64, body string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(middleware.BodyLimit(maxBytes, nil))
+	router.POST("/items", func(c *gin.Context) {
+		var req bindingTestRequest
+		if !bind(c, &req) {
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.ContentLength = -1
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "valid", body: `{"title": "ok", "items": [{"name": "a", "count": 1}]}`, expectedCode: http.StatusNoContent},
+		{name: "unknown fields ignored", body: `{"title": "ok", "extra": true}`, expectedCode: http.StatusNoContent},
+		{
+			name:         "failed checks listed per field",
+			body:         `{"title": "too long", "items": [{"count": 0}]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "request validation failed", "code": "VALIDATION_ERROR", "fields": [
+				{"field": "title", "message": "must be at most 5 characters"},
+				{"field": "items[0].name", "message": "is required"},
+				{"field": "items[0].count", "message": "must be at least 1"}
+			]}`,
+		},
+		{
+			name:         "wrong type",
+			body:         `{"title": 5}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "request validation failed", "code": "VALIDATION_ERROR", "fields": [
+				{"field": "title", "message": "must be a string, not number"}
+			]}`,
+		},
+		{
+			name:         "malformed",
+			body:         `{"title": }`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "malformed JSON at byte 11", "code": "VALIDATION_ERROR"}`,
+		},
+		{
+			name:         "empty",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "request body is empty", "code": "VALIDATION_ERROR"}`,
+		},
+		{
+			name:         "over size limit",
+			body:         `{"title": "ok", "items": [{"name": "` + strings.Repeat("a", 100) + `", "count": 1}]}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: `{"error": "request body exceeds 64 bytes", "code": "BODY_TOO_LARGE"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := serveBinding(bindJSON, 64, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindStrictJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "valid", body: `{"title": "ok"}`, expectedCode: http.StatusNoContent},
+		{
+			name:         "unknown field",
+			body:         `{"title": "ok", "tilte": "ok"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "request validation failed", "code": "VALIDATION_ERROR", "fields": [
+				{"field": "tilte", "message": "is not a known field"}
+			]}`,
+		},
+		{
+			name:         "still validated",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "request validation failed", "code": "VALIDATION_ERROR", "fields": [
+				{"field": "title", "message": "is required"}
+			]}`,
+		},
+		{
+			name:         "trailing data",
+			body:         `{"title": "ok"} {"title": "ok"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error": "body must hold a single JSON object", "code": "VALIDATION_ERROR"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := serveBinding(bindStrictJSON, 0, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFieldPath_UsesClientNames(t *testing.T) {
+	// Act
+	path := fieldPath(reflect.TypeOf(&dto.CreateMessageRequest{}), "CreateMessageRequest.ClientReference")
+
+	// Assert
+	assert.Equal(t, "client_reference", path)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BlacklistHandler struct {
+	blacklistService service.BlacklistService
+}
+
+func NewBlacklistHandler(blacklistService service.BlacklistService) *BlacklistHandler {
+	return &BlacklistHandler{
+		blacklistService: blacklistService,
+	}
+}
+
+// AddBlacklistEntry godoc
+// @Summary Blacklist a phone number
+// @Description Stop sending to a phone number. An entry added with the global API token applies to every tenant; one added with a tenant's API key applies to that tenant only.
+// @Tags blacklist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entry body dto.CreateBlacklistEntryRequest true "Phone number to blacklist"
+// @Success 201 {object} dto.BlacklistEntryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/blacklist [post]
+func (h *BlacklistHandler) AddBlacklistEntry(c *gin.Context) {
+	var req dto.CreateBlacklistEntryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.blacklistService.AddEntry(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListBlacklistEntries godoc
+// @Summary List blacklist entries
+// @Description Retrieve a paginated list of blacklist entries, newest first
+// @Tags blacklist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param phone_number query string false "Only entries for this phone number"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.BlacklistListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/blacklist [get]
+func (h *BlacklistHandler) ListBlacklistEntries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.blacklistService.ListEntries(c.Request.Context(), c.Query("phone_number"), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBlacklistEntry godoc
+// @Summary Get a blacklist entry
+// @Tags blacklist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Blacklist entry ID"
+// @Success 200 {object} dto.BlacklistEntryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/blacklist/{id} [get]
+func (h *BlacklistHandler) GetBlacklistEntry(c *gin.Context) {
+	id, ok := parseBlacklistEntryID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.blacklistService.GetEntry(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveBlacklistEntry godoc
+// @Summary Remove a blacklist entry
+// @Description Allow sending to the phone number again. Messages already blocked stay blocked until they are retried.
+// @Tags blacklist
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Blacklist entry ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/blacklist/{id} [delete]
+func (h *BlacklistHandler) RemoveBlacklistEntry(c *gin.Context) {
+	id, ok := parseBlacklistEntryID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.blacklistService.RemoveEntry(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "blacklist entry removed",
+	})
+}
+
+func parseBlacklistEntryID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid blacklist entry ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CampaignHandler struct {
+	campaignService service.CampaignService
+}
+
+func NewCampaignHandler(campaignService service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+	}
+}
+
+// CreateCampaign godoc
+// @Summary Create a campaign
+// @Description Create a campaign and, optionally, its first batch of messages. Messages that fail validation are reported by index in errors; the others are still created.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param campaign body dto.CreateCampaignRequest true "Campaign details"
+// @Success 201 {object} dto.CampaignBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	var req dto.CreateCampaignRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.campaignService.CreateCampaign(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListCampaigns godoc
+// @Summary List campaigns
+// @Description Retrieve a paginated list of campaigns, newest first
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.CampaignListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns [get]
+func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.campaignService.ListCampaigns(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCampaign godoc
+// @Summary Get a campaign
+// @Description Retrieve a campaign with the number of its messages in each status
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id} [get]
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.campaignService.GetCampaign(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddCampaignMessages godoc
+// @Summary Add messages to a campaign
+// @Description Create a batch of messages under the campaign. Messages that fail validation are reported by index in errors; the others are still created.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Param messages body dto.AddCampaignMessagesRequest true "Messages to create"
+// @Success 201 {object} dto.CampaignBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/messages [post]
+func (h *CampaignHandler) AddCampaignMessages(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddCampaignMessagesRequest
+	if !bindStrictJSON(c, &req) {
+		return
+	}
+
+	result, err := h.campaignService.AddMessages(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// PauseCampaign godoc
+// @Summary Pause a campaign
+// @Description Stop dispatching the campaign's pending messages until it is resumed. Messages already being sent are not recalled.
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/pause [post]
+func (h *CampaignHandler) PauseCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.campaignService.PauseCampaign(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ResumeCampaign godoc
+// @Summary Resume a campaign
+// @Description Dispatch the campaign's pending messages again from the next scheduler run
+// @Tags campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} dto.CampaignResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/campaigns/{id}/resume [post]
+func (h *CampaignHandler) ResumeCampaign(c *gin.Context) {
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.campaignService.ResumeCampaign(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseCampaignID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid campaign ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 
 	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
@@ -8,15 +9,22 @@ import (
 )
 
 type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  string `json:"code,omitempty"`
+	Error  string       `json:"error"`
+	Code   string       `json:"code,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
 }
 
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
+// handleError answers 504 for any error once the request deadline has
+// passed, since the error then most likely comes from the cancellation.
 func handleError(c *gin.Context, err error) {
+	if c.Request.Context().Err() == context.DeadlineExceeded {
+		err = apperrors.New(apperrors.ErrorCodeDeadlineExceeded, "request deadline exceeded")
+	}
+
 	if appErr, ok := err.(*apperrors.AppError); ok {
 		statusCode := getHTTPStatusCode(appErr.Code)
 		c.JSON(statusCode, ErrorResponse{
@@ -32,17 +40,30 @@ func handleError(c *gin.Context, err error) {
 	})
 }
 
+func isNotFound(err error) bool {
+	appErr, ok := err.(*apperrors.AppError)
+	return ok && appErr.Code == apperrors.ErrorCodeNotFound
+}
+
 func getHTTPStatusCode(code apperrors.ErrorCode) int {
 	switch code {
 	case apperrors.ErrorCodeValidation:
 		return http.StatusBadRequest
 	case apperrors.ErrorCodeNotFound:
 		return http.StatusNotFound
-	case apperrors.ErrorCodeAlreadyExists:
+	case apperrors.ErrorCodeAlreadyExists, apperrors.ErrorCodeConflict, apperrors.ErrorCodeVersionConflict,
+		apperrors.ErrorCodeDuplicateContent:
 		return http.StatusConflict
+	case apperrors.ErrorCodeDestinationBlocked, apperrors.ErrorCodeContentRejected, apperrors.ErrorCodeChannelUnavailable,
+		apperrors.ErrorCodeDestinationUnavailable:
+		return http.StatusUnprocessableEntity
 	case apperrors.ErrorCodeTimeout:
 		return http.StatusRequestTimeout
-	case apperrors.ErrorCodeRateLimit:
+	case apperrors.ErrorCodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case apperrors.ErrorCodeBodyTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperrors.ErrorCodeRateLimit, apperrors.ErrorCodeRecipientRateLimited:
 		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
@@ -0,0 +1,477 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ContactHandler struct {
+	contactService service.ContactService
+}
+
+func NewContactHandler(contactService service.ContactService) *ContactHandler {
+	return &ContactHandler{
+		contactService: contactService,
+	}
+}
+
+// CreateContact godoc
+// @Summary Create a contact
+// @Description Add a recipient to the directory with at least one of phone_number, email and device_token. A phone number belongs to one contact only.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param contact body dto.CreateContactRequest true "Contact details"
+// @Success 201 {object} dto.ContactResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/contacts [post]
+func (h *ContactHandler) CreateContact(c *gin.Context) {
+	var req dto.CreateContactRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.CreateContact(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListContacts godoc
+// @Summary List contacts
+// @Description Retrieve a paginated list of contacts, newest first
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ContactListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/contacts [get]
+func (h *ContactHandler) ListContacts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.contactService.ListContacts(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetContact godoc
+// @Summary Get a contact
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contact ID"
+// @Success 200 {object} dto.ContactResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/contacts/{id} [get]
+func (h *ContactHandler) GetContact(c *gin.Context) {
+	id, ok := parseContactID(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.contactService.GetContact(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateContact godoc
+// @Summary Change a contact
+// @Description Change the fields that are set; an empty address clears it, as long as the contact keeps one
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contact ID"
+// @Param contact body dto.UpdateContactRequest true "Fields to change"
+// @Success 200 {object} dto.ContactResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/contacts/{id} [patch]
+func (h *ContactHandler) UpdateContact(c *gin.Context) {
+	id, ok := parseContactID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateContactRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.UpdateContact(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteContact godoc
+// @Summary Delete a contact
+// @Description Remove the contact and its group memberships. Messages already created for it are kept.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Contact ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/contacts/{id} [delete]
+func (h *ContactHandler) DeleteContact(c *gin.Context) {
+	id, ok := parseContactID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.DeleteContact(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "contact deleted",
+	})
+}
+
+// CreateGroup godoc
+// @Summary Create a contact group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param group body dto.CreateGroupRequest true "Group details"
+// @Success 201 {object} dto.GroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups [post]
+func (h *ContactHandler) CreateGroup(c *gin.Context) {
+	var req dto.CreateGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.CreateGroup(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListGroups godoc
+// @Summary List contact groups
+// @Description Retrieve a paginated list of groups with their member counts, newest first
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.GroupListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups [get]
+func (h *ContactHandler) ListGroups(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.contactService.ListGroups(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetGroup godoc
+// @Summary Get a contact group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} dto.GroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id} [get]
+func (h *ContactHandler) GetGroup(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.contactService.GetGroup(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateGroup godoc
+// @Summary Rename a contact group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param group body dto.UpdateGroupRequest true "New name"
+// @Success 200 {object} dto.GroupResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id} [patch]
+func (h *ContactHandler) UpdateGroup(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateGroupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.UpdateGroup(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteGroup godoc
+// @Summary Delete a contact group
+// @Description Remove the group and its memberships; its contacts are kept
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id} [delete]
+func (h *ContactHandler) DeleteGroup(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.DeleteGroup(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "group deleted",
+	})
+}
+
+// AddGroupMembers godoc
+// @Summary Add contacts to a group
+// @Description Add up to 1000 contacts at once. Contacts already in the group are skipped and not counted in added.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param members body dto.AddGroupMembersRequest true "Contacts to add"
+// @Success 200 {object} dto.AddGroupMembersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id}/members [post]
+func (h *ContactHandler) AddGroupMembers(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AddGroupMembersRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.AddMembers(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListGroupMembers godoc
+// @Summary List the contacts of a group
+// @Description Retrieve a paginated list of the group's contacts in the order they were added
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.GroupMemberListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id}/members [get]
+func (h *ContactHandler) ListGroupMembers(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.contactService.ListMembers(c.Request.Context(), id, page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveGroupMember godoc
+// @Summary Remove a contact from a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param contactId path string true "Contact ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/groups/{id}/members/{contactId} [delete]
+func (h *ContactHandler) RemoveGroupMember(c *gin.Context) {
+	id, ok := parseGroupID(c, "id")
+	if !ok {
+		return
+	}
+	contactID, ok := parseContactID(c, "contactId")
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.RemoveMember(c.Request.Context(), id, contactID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "contact removed from group",
+	})
+}
+
+// SendToGroup godoc
+// @Summary Send a message to a group
+// @Description Create one message per member of the group (at most 1000), each to the member's address on the channel. Members the message cannot be created for, such as those without an address on the channel, are reported in errors; the others are still created.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param groupId path string true "Group ID"
+// @Param message body dto.SendToGroupRequest true "Message details"
+// @Success 201 {object} dto.GroupSendResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/to-group/{groupId} [post]
+func (h *ContactHandler) SendToGroup(c *gin.Context) {
+	id, ok := parseGroupID(c, "groupId")
+	if !ok {
+		return
+	}
+
+	var req dto.SendToGroupRequest
+	if !bindStrictJSON(c, &req) {
+		return
+	}
+
+	result, err := h.contactService.SendToGroup(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func parseContactID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid contact ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func parseGroupID(c *gin.Context, param string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid group ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
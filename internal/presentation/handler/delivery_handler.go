@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/signature"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// maxReceiptBodySize bounds how much of a callback body is read before the
+// signature is checked.
+const maxReceiptBodySize = 64 << 10
+
+type DeliveryHandler struct {
+	messageService service.MessageService
+	secret         string
+	tolerance      time.Duration
+}
+
+// NewDeliveryHandler accepts receipts signed with secret whose signature
+// timestamp is within tolerance of the current time.
+func NewDeliveryHandler(messageService service.MessageService, secret string, tolerance time.Duration) *DeliveryHandler {
+	return &DeliveryHandler{
+		messageService: messageService,
+		secret:         secret,
+		tolerance:      tolerance,
+	}
+}
+
+// HandleDeliveryStatus godoc
+// @Summary Receive a provider delivery receipt
+// @Description Called by the sender provider once a sent message is delivered, undelivered or rejected. The message is looked up by webhook_message_id. Requests are authenticated with an HMAC-SHA256 signature of "<timestamp>.<body>" instead of a bearer token. Repeating a receipt is safe.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param receipt body dto.DeliveryReceiptRequest true "Delivery receipt"
+// @Param X-Signature header string true "Hex HMAC-SHA256 of the timestamp, a dot and the raw body"
+// @Param X-Signature-Timestamp header string true "Unix time the receipt was signed at"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/webhooks/delivery-status [post]
+func (h *DeliveryHandler) HandleDeliveryStatus(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxReceiptBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "failed to read request body",
+		})
+		return
+	}
+
+	err = signature.Verify(
+		h.secret,
+		c.GetHeader(signature.Header),
+		c.GetHeader(signature.TimestampHeader),
+		body,
+		h.tolerance,
+		time.Now(),
+	)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn("rejected delivery receipt", zap.Error(err), zap.String("client_ip", c.ClientIP()))
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	var req dto.DeliveryReceiptRequest
+	if err := binding.JSON.BindBody(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	ctx := actor.WithActor(c.Request.Context(), actor.Provider)
+	result, err := h.messageService.ApplyDeliveryReceipt(ctx, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/failover"
+	"github.com/gin-gonic/gin"
+)
+
+type FailoverHandler struct {
+	elector *failover.Elector
+}
+
+func NewFailoverHandler(elector *failover.Elector) *FailoverHandler {
+	return &FailoverHandler{
+		elector: elector,
+	}
+}
+
+// GetFailoverStatus godoc
+// @Summary Get dispatch failover status
+// @Description Show which region currently holds the dispatch lease and the role of this instance
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.FailoverStatusResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/admin/failover [get]
+func (h *FailoverHandler) GetFailoverStatus(c *gin.Context) {
+	resp := dto.FailoverStatusResponse{
+		Region:   h.elector.Region(),
+		Role:     h.elector.Role(),
+		HolderID: h.elector.HolderID(),
+		IsLeader: h.elector.IsLeader(),
+	}
+
+	if lease := h.elector.Lease(); lease != nil {
+		expiresAt := lease.ExpiresAt
+		resp.LeaseHolderID = lease.HolderID
+		resp.LeaseRegion = lease.HolderRegion
+		resp.PreferredRegion = lease.PreferredRegion
+		resp.Epoch = lease.Epoch
+		resp.ExpiresAt = &expiresAt
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ForceFailover godoc
+// @Summary Force dispatch failover
+// @Description Hand the dispatch lease to the given region. The current holder stops dispatching on its next heartbeat and the target takes over once the lease expires
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.FailoverRequest true "Target region"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/failover [post]
+func (h *FailoverHandler) ForceFailover(c *gin.Context) {
+	var req dto.FailoverRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.elector.ForceFailover(c.Request.Context(), req.Region); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "failover to region " + req.Region + " requested",
+	})
+}
@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+)
+
+// graphQLMaxDepth bounds how deeply a query may nest selections; the schema
+// is shallow, so anything deeper is a mistake or an attempt to load the API.
+const graphQLMaxDepth = 8
+
+// graphQLCodeForbidden is the code of the error answered where the REST
+// endpoint would answer 403.
+const graphQLCodeForbidden = "FORBIDDEN"
+
+// graphQLSchema is read-only: writes keep going through the REST endpoints,
+// which read-only JWTs cannot reach.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# Messages matching every given filter, newest first. pageSize is at most 100.
+	messages(filter: MessageFilter, page: Int = 1, pageSize: Int = 20): MessageList!
+	# The message with the ID, null when there is none
+	message(id: ID!): Message
+	stats: MessageStats!
+	# Not available to tenant API keys
+	scheduler: SchedulerStatus!
+}
+
+input MessageFilter {
+	status: String
+	channel: String
+	phoneNumber: String
+	errorCode: String
+	createdAfter: Time
+	createdBefore: Time
+	campaignId: ID
+	splitGroupId: ID
+	metadata: [MetadataEntryInput!]
+	providerResponse: [MetadataEntryInput!]
+}
+
+input MetadataEntryInput {
+	key: String!
+	value: String!
+}
+
+type MessageList {
+	messages: [Message!]!
+	totalCount: Int!
+	page: Int!
+	pageSize: Int!
+}
+
+type Message {
+	id: ID!
+	channel: String!
+	phoneNumber: String
+	recipient: String!
+	content: String!
+	encoding: String!
+	segments: Int!
+	status: String!
+	priority: String!
+	attempts: Int!
+	maxAttempts: Int!
+	lastError: String
+	errorCode: String
+	webhookMessageId: String
+	clientReference: String
+	tenantId: ID
+	campaignId: ID
+	destinationId: ID
+	createdBy: String
+	metadata: [MetadataEntry!]!
+	createdAt: Time!
+	scheduledAt: Time
+	sentAt: Time
+	deliveredAt: Time
+	expiresAt: Time
+	nextRetryAt: Time
+}
+
+type MetadataEntry {
+	key: String!
+	value: String!
+}
+
+type MessageStats {
+	totalMessages: Int!
+	pendingMessages: Int!
+	sentMessages: Int!
+	failedMessages: Int!
+	cancelledMessages: Int!
+	deliveredMessages: Int!
+	undeliveredMessages: Int!
+	quarantinedMessages: Int!
+	pausedMessages: Int!
+	blockedMessages: Int!
+	expiredMessages: Int!
+	# Only given with the stats of all messages
+	performance: SendPerformance
+}
+
+type SendPerformance {
+	avgAttemptsPerSent: Float!
+	avgTimeToSendMs: Int!
+	p95TimeToSendMs: Int!
+	failureRateLastHour: Float!
+}
+
+type SchedulerStatus {
+	isRunning: Boolean!
+	mode: String!
+	lastRunAt: Time
+	nextRunAt: Time
+	schedule: String!
+	batchSize: Int!
+	workerCount: Int!
+	intervalSeconds: Int!
+	priorities: [String!]!
+	lanes: [SchedulerLane!]!
+	totalProcessed: Int!
+	totalSuccessful: Int!
+	totalFailed: Int!
+	degraded: Boolean!
+	dispatching: Boolean!
+}
+
+type SchedulerLane {
+	name: String!
+	priorities: [String!]!
+	intervalSeconds: Float!
+	batchSize: Int!
+	workerCount: Int!
+	lastRunAt: Time
+	totalProcessed: Int!
+	totalSuccessful: Int!
+	totalFailed: Int!
+}
+`
+
+// GraphQLHandler serves the message queries of the REST API through one
+// GraphQL endpoint, so that a dashboard fetches only the fields it shows.
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+func NewGraphQLHandler(messageService service.MessageService, schedulerHandler *SchedulerHandler) *GraphQLHandler {
+	resolver := &graphQLResolver{
+		messageService:   messageService,
+		schedulerHandler: schedulerHandler,
+	}
+
+	return &GraphQLHandler{
+		schema: graphql.MustParseSchema(graphQLSchema, resolver, graphql.MaxDepth(graphQLMaxDepth)),
+	}
+}
+
+// GraphQLRequest is a GraphQL query posted as JSON.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query godoc
+// @Summary Query messages with GraphQL
+// @Description Run a GraphQL query over messages, message stats and the scheduler status, selecting only the fields needed. The schema is read-only; tenant API keys only see their own messages and cannot query the scheduler. Errors of individual fields are listed under errors with the same codes as the REST API, next to whatever data could be resolved.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body GraphQLRequest true "GraphQL query"
+// @Success 200 {object} object
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req GraphQLRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	c.JSON(http.StatusOK, h.schema.Exec(c.Request.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+type graphQLResolver struct {
+	messageService   service.MessageService
+	schedulerHandler *SchedulerHandler
+}
+
+type metadataEntryInput struct {
+	Key   string
+	Value string
+}
+
+type messageFilterInput struct {
+	Status           *string
+	Channel          *string
+	PhoneNumber      *string
+	ErrorCode        *string
+	CreatedAfter     *graphql.Time
+	CreatedBefore    *graphql.Time
+	CampaignID       *graphql.ID
+	SplitGroupID     *graphql.ID
+	Metadata         *[]metadataEntryInput
+	ProviderResponse *[]metadataEntryInput
+}
+
+func (r *graphQLResolver) Messages(ctx context.Context, args struct {
+	Filter   *messageFilterInput
+	Page     int32
+	PageSize int32
+}) (*messageListResolver, error) {
+	req := dto.ListMessagesRequest{
+		Page:     int(args.Page),
+		PageSize: int(args.PageSize),
+	}
+	if f := args.Filter; f != nil {
+		req.Status = stringValue(f.Status)
+		req.Channel = stringValue(f.Channel)
+		req.PhoneNumber = stringValue(f.PhoneNumber)
+		req.ErrorCode = stringValue(f.ErrorCode)
+		req.CreatedAfter = timeValue(f.CreatedAfter)
+		req.CreatedBefore = timeValue(f.CreatedBefore)
+		req.CampaignID = idValue(f.CampaignID)
+		req.SplitGroupID = idValue(f.SplitGroupID)
+		req.Metadata = entryMap(f.Metadata)
+		req.ProviderResponse = entryMap(f.ProviderResponse)
+	}
+
+	result, err := r.messageService.ListMessages(ctx, &req)
+	if err != nil {
+		return nil, graphQLError(ctx, err)
+	}
+	return &messageListResolver{list: result}, nil
+}
+
+func (r *graphQLResolver) Message(ctx context.Context, args struct{ ID graphql.ID }) (*messageResolver, error) {
+	id, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, graphQLError(ctx, apperrors.NewValidationError("invalid message ID format"))
+	}
+
+	message, err := r.messageService.GetMessage(ctx, id)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, graphQLError(ctx, err)
+	}
+	return &messageResolver{message: message}, nil
+}
+
+func (r *graphQLResolver) Stats(ctx context.Context) (*messageStatsResolver, error) {
+	stats, err := r.messageService.GetStats(ctx)
+	if err != nil {
+		return nil, graphQLError(ctx, err)
+	}
+	return &messageStatsResolver{stats: stats}, nil
+}
+
+func (r *graphQLResolver) Scheduler(ctx context.Context) (*schedulerStatusResolver, error) {
+	// The scheduler is shared by every tenant, as on /api/v1/scheduler
+	if _, ok := tenant.FromContext(ctx); ok {
+		return nil, &graphQLResolverError{
+			message: "tenant API keys cannot access the scheduler",
+			code:    graphQLCodeForbidden,
+		}
+	}
+
+	status := r.schedulerHandler.status()
+	return &schedulerStatusResolver{status: &status}, nil
+}
+
+// graphQLResolverError carries the error code of the REST API under the
+// code extension of a GraphQL error.
+type graphQLResolverError struct {
+	message string
+	code    string
+}
+
+func (e *graphQLResolverError) Error() string {
+	return e.message
+}
+
+func (e *graphQLResolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// graphQLError hides unexpected errors behind a generic message as
+// handleError does, logging them instead.
+func graphQLError(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		err = apperrors.New(apperrors.ErrorCodeDeadlineExceeded, "request deadline exceeded")
+	}
+
+	if appErr, ok := err.(*apperrors.AppError); ok {
+		return &graphQLResolverError{message: appErr.Message, code: string(appErr.Code)}
+	}
+
+	logger.FromContext(ctx).Error("graphql query failed", zap.Error(err))
+	return &graphQLResolverError{message: "internal server error", code: string(apperrors.ErrorCodeInternal)}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func idValue(id *graphql.ID) string {
+	if id == nil {
+		return ""
+	}
+	return string(*id)
+}
+
+func timeValue(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
+
+func entryMap(entries *[]metadataEntryInput) map[string]string {
+	if entries == nil {
+		return nil
+	}
+	m := make(map[string]string, len(*entries))
+	for _, entry := range *entries {
+		m[entry.Key] = entry.Value
+	}
+	return m
+}
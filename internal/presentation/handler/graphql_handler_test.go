@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGraphQLMessageService only implements the queries the GraphQL schema
+// uses.
+type fakeGraphQLMessageService struct {
+	service.MessageService
+
+	listRequest *dto.ListMessagesRequest
+	list        *dto.MessageListResponse
+	message     *dto.MessageResponse
+	err         error
+}
+
+func (s *fakeGraphQLMessageService) ListMessages(ctx context.Context, req *dto.ListMessagesRequest) (*dto.MessageListResponse, error) {
+	s.listRequest = req
+	return s.list, s.err
+}
+
+func (s *fakeGraphQLMessageService) GetMessage(ctx context.Context, id uuid.UUID) (*dto.MessageResponse, error) {
+	return s.message, s.err
+}
+
+func (s *fakeGraphQLMessageService) GetStats(ctx context.Context) (*dto.MessageStatsResponse, error) {
+	return &dto.MessageStatsResponse{TotalMessages: 3, SentMessages: 2}, s.err
+}
+
+type graphQLTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions"`
+	} `json:"errors"`
+}
+
+func serveGraphQL(t *testing.T, messageService service.MessageService, tenantID *uuid.UUID, body string) (int, graphQLTestResponse) {
+	t.Helper()
+
+	router := gin.New()
+	if tenantID != nil {
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(tenant.WithTenantID(c.Request.Context(), *tenantID))
+		})
+	}
+	router.POST("/graphql", NewGraphQLHandler(messageService, nil).Query)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp graphQLTestResponse
+	if w.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestGraphQLHandler_MessagesSelectsFieldsAndFilters(t *testing.T) {
+	// Arrange
+	messageService := &fakeGraphQLMessageService{
+		list: &dto.MessageListResponse{
+			Messages: []dto.MessageResponse{{
+				ID:        "8b4c6f7e-0d4b-4a57-9a41-3f5d2c1b0a99",
+				Status:    "failed",
+				Content:   "Your code is 1234",
+				ErrorCode: "RATE_LIMIT",
+				Metadata:  map[string]string{"order": "42", "campaign": "spring"},
+				CreatedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+			}},
+			TotalCount: 1,
+			Page:       2,
+			PageSize:   10,
+		},
+	}
+	body := `{
+		"query": "query($after: Time) { messages(filter: {status: \"failed\", createdAfter: $after, metadata: [{key: \"order\", value: \"42\"}]}, page: 2, pageSize: 10) { totalCount messages { id status errorCode metadata { key value } } } }",
+		"variables": {"after": "2026-10-01T00:00:00Z"}
+	}`
+
+	// Act
+	code, resp := serveGraphQL(t, messageService, nil, body)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{
+		"totalCount": 1,
+		"messages": [{
+			"id": "8b4c6f7e-0d4b-4a57-9a41-3f5d2c1b0a99",
+			"status": "failed",
+			"errorCode": "RATE_LIMIT",
+			"metadata": [{"key": "campaign", "value": "spring"}, {"key": "order", "value": "42"}]
+		}]
+	}`, string(resp.Data["messages"]))
+
+	req := messageService.listRequest
+	assert.Equal(t, "failed", req.Status)
+	assert.Equal(t, map[string]string{"order": "42"}, req.Metadata)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *req.CreatedAfter)
+	assert.Nil(t, req.CreatedBefore)
+	assert.Equal(t, 2, req.Page)
+	assert.Equal(t, 10, req.PageSize)
+}
+
+func TestGraphQLHandler_Errors(t *testing.T) {
+	tenantID := uuid.New()
+
+	tests := []struct {
+		name     string
+		err      error
+		tenantID *uuid.UUID
+		query    string
+		field    string
+		wantData string
+		wantErr  string
+		wantCode string
+	}{
+		{
+			name:     "missing message is null",
+			err:      apperrors.NewNotFoundError("message not found"),
+			query:    `{ message(id: \"8b4c6f7e-0d4b-4a57-9a41-3f5d2c1b0a99\") { id } }`,
+			field:    "message",
+			wantData: "null",
+		},
+		{
+			name:     "invalid message ID",
+			query:    `{ message(id: \"42\") { id } }`,
+			field:    "message",
+			wantData: "null",
+			wantErr:  "invalid message ID format",
+			wantCode: string(apperrors.ErrorCodeValidation),
+		},
+		{
+			name:     "service error keeps its code",
+			err:      apperrors.NewValidationError("invalid status"),
+			query:    `{ messages(filter: {status: \"bogus\"}) { totalCount } }`,
+			wantErr:  "invalid status",
+			wantCode: string(apperrors.ErrorCodeValidation),
+		},
+		{
+			name:     "unexpected error is hidden",
+			err:      errors.New("pq: connection refused"),
+			query:    `{ messages { totalCount } }`,
+			wantErr:  "internal server error",
+			wantCode: string(apperrors.ErrorCodeInternal),
+		},
+		{
+			name:     "tenant cannot query the scheduler",
+			tenantID: &tenantID,
+			query:    `{ stats { totalMessages } scheduler { isRunning } }`,
+			wantErr:  "tenant API keys cannot access the scheduler",
+			wantCode: graphQLCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			messageService := &fakeGraphQLMessageService{err: tt.err}
+
+			// Act
+			code, resp := serveGraphQL(t, messageService, tt.tenantID, `{"query": "`+tt.query+`"}`)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, code)
+			if tt.field != "" {
+				assert.Equal(t, tt.wantData, string(resp.Data[tt.field]))
+			}
+			if tt.wantErr == "" {
+				assert.Empty(t, resp.Errors)
+				return
+			}
+			if assert.Len(t, resp.Errors, 1) {
+				assert.Equal(t, tt.wantErr, resp.Errors[0].Message)
+				assert.Equal(t, tt.wantCode, resp.Errors[0].Extensions["code"])
+			}
+		})
+	}
+}
+
+func TestGraphQLHandler_RejectsMissingQuery(t *testing.T) {
+	// Act
+	code, _ := serveGraphQL(t, &fakeGraphQLMessageService{}, nil, `{"variables": {}}`)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// The resolvers below expose the REST responses field by field. GraphQL Int
+// is 32 bits wide, so counts are narrowed to int32.
+
+type messageListResolver struct {
+	list *dto.MessageListResponse
+}
+
+func (r *messageListResolver) Messages() []*messageResolver {
+	messages := make([]*messageResolver, len(r.list.Messages))
+	for i := range r.list.Messages {
+		messages[i] = &messageResolver{message: &r.list.Messages[i]}
+	}
+	return messages
+}
+
+func (r *messageListResolver) TotalCount() int32 { return int32(r.list.TotalCount) }
+func (r *messageListResolver) Page() int32       { return int32(r.list.Page) }
+func (r *messageListResolver) PageSize() int32   { return int32(r.list.PageSize) }
+
+type messageResolver struct {
+	message *dto.MessageResponse
+}
+
+func (r *messageResolver) ID() graphql.ID             { return graphql.ID(r.message.ID) }
+func (r *messageResolver) Channel() string            { return r.message.Channel }
+func (r *messageResolver) PhoneNumber() *string       { return optionalString(r.message.PhoneNumber) }
+func (r *messageResolver) Recipient() string          { return r.message.Recipient }
+func (r *messageResolver) Content() string            { return r.message.Content }
+func (r *messageResolver) Encoding() string           { return r.message.Encoding }
+func (r *messageResolver) Segments() int32            { return int32(r.message.Segments) }
+func (r *messageResolver) Status() string             { return r.message.Status }
+func (r *messageResolver) Priority() string           { return r.message.Priority }
+func (r *messageResolver) Attempts() int32            { return int32(r.message.Attempts) }
+func (r *messageResolver) MaxAttempts() int32         { return int32(r.message.MaxAttempts) }
+func (r *messageResolver) LastError() *string         { return optionalString(r.message.LastError) }
+func (r *messageResolver) ErrorCode() *string         { return optionalString(r.message.ErrorCode) }
+func (r *messageResolver) ClientReference() *string   { return optionalString(r.message.ClientReference) }
+func (r *messageResolver) CreatedBy() *string         { return optionalString(r.message.CreatedBy) }
+func (r *messageResolver) TenantID() *graphql.ID      { return optionalGraphQLID(r.message.TenantID) }
+func (r *messageResolver) CampaignID() *graphql.ID    { return optionalGraphQLID(r.message.CampaignID) }
+func (r *messageResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.message.CreatedAt} }
+func (r *messageResolver) ScheduledAt() *graphql.Time { return optionalTime(r.message.ScheduledAt) }
+func (r *messageResolver) SentAt() *graphql.Time      { return optionalTime(r.message.SentAt) }
+func (r *messageResolver) DeliveredAt() *graphql.Time { return optionalTime(r.message.DeliveredAt) }
+func (r *messageResolver) ExpiresAt() *graphql.Time   { return optionalTime(r.message.ExpiresAt) }
+func (r *messageResolver) NextRetryAt() *graphql.Time { return optionalTime(r.message.NextRetryAt) }
+
+func (r *messageResolver) WebhookMessageID() *string {
+	return optionalString(r.message.WebhookMessageID)
+}
+
+func (r *messageResolver) DestinationID() *graphql.ID {
+	return optionalGraphQLID(r.message.DestinationID)
+}
+
+// Metadata lists the entries by key, since maps have no order
+func (r *messageResolver) Metadata() []*metadataEntryResolver {
+	entries := make([]*metadataEntryResolver, 0, len(r.message.Metadata))
+	for key, value := range r.message.Metadata {
+		entries = append(entries, &metadataEntryResolver{key: key, value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	return entries
+}
+
+type metadataEntryResolver struct {
+	key   string
+	value string
+}
+
+func (r *metadataEntryResolver) Key() string   { return r.key }
+func (r *metadataEntryResolver) Value() string { return r.value }
+
+type messageStatsResolver struct {
+	stats *dto.MessageStatsResponse
+}
+
+func (r *messageStatsResolver) TotalMessages() int32       { return int32(r.stats.TotalMessages) }
+func (r *messageStatsResolver) PendingMessages() int32     { return int32(r.stats.PendingMessages) }
+func (r *messageStatsResolver) SentMessages() int32        { return int32(r.stats.SentMessages) }
+func (r *messageStatsResolver) FailedMessages() int32      { return int32(r.stats.FailedMessages) }
+func (r *messageStatsResolver) CancelledMessages() int32   { return int32(r.stats.CancelledMessages) }
+func (r *messageStatsResolver) DeliveredMessages() int32   { return int32(r.stats.DeliveredMessages) }
+func (r *messageStatsResolver) UndeliveredMessages() int32 { return int32(r.stats.UndeliveredMessages) }
+func (r *messageStatsResolver) QuarantinedMessages() int32 { return int32(r.stats.QuarantinedMessages) }
+func (r *messageStatsResolver) PausedMessages() int32      { return int32(r.stats.PausedMessages) }
+func (r *messageStatsResolver) BlockedMessages() int32     { return int32(r.stats.BlockedMessages) }
+func (r *messageStatsResolver) ExpiredMessages() int32     { return int32(r.stats.ExpiredMessages) }
+
+func (r *messageStatsResolver) Performance() *sendPerformanceResolver {
+	if r.stats.Performance == nil {
+		return nil
+	}
+	return &sendPerformanceResolver{performance: r.stats.Performance}
+}
+
+type sendPerformanceResolver struct {
+	performance *dto.SendPerformanceResponse
+}
+
+func (r *sendPerformanceResolver) AvgAttemptsPerSent() float64 {
+	return r.performance.AvgAttemptsPerSent
+}
+func (r *sendPerformanceResolver) AvgTimeToSendMs() int32 {
+	return int32(r.performance.AvgTimeToSendMs)
+}
+func (r *sendPerformanceResolver) P95TimeToSendMs() int32 {
+	return int32(r.performance.P95TimeToSendMs)
+}
+func (r *sendPerformanceResolver) FailureRateLastHour() float64 {
+	return r.performance.FailureRateLastHour
+}
+
+type schedulerStatusResolver struct {
+	status *dto.SchedulerStatusResponse
+}
+
+func (r *schedulerStatusResolver) IsRunning() bool          { return r.status.IsRunning }
+func (r *schedulerStatusResolver) Mode() string             { return r.status.Mode }
+func (r *schedulerStatusResolver) NextRunAt() *graphql.Time { return optionalTime(r.status.NextRunAt) }
+func (r *schedulerStatusResolver) Schedule() string         { return r.status.Schedule }
+func (r *schedulerStatusResolver) BatchSize() int32         { return int32(r.status.BatchSize) }
+func (r *schedulerStatusResolver) WorkerCount() int32       { return int32(r.status.WorkerCount) }
+func (r *schedulerStatusResolver) IntervalSeconds() int32   { return int32(r.status.IntervalSeconds) }
+func (r *schedulerStatusResolver) Priorities() []string     { return nonNilStrings(r.status.Priorities) }
+func (r *schedulerStatusResolver) TotalProcessed() int32    { return int32(r.status.TotalProcessed) }
+func (r *schedulerStatusResolver) TotalSuccessful() int32   { return int32(r.status.TotalSuccessful) }
+func (r *schedulerStatusResolver) TotalFailed() int32       { return int32(r.status.TotalFailed) }
+func (r *schedulerStatusResolver) Degraded() bool           { return r.status.Degraded }
+func (r *schedulerStatusResolver) Dispatching() bool        { return r.status.Dispatching }
+
+// LastRunAt is null before the first cycle
+func (r *schedulerStatusResolver) LastRunAt() *graphql.Time {
+	if r.status.LastRunAt.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: r.status.LastRunAt}
+}
+
+func (r *schedulerStatusResolver) Lanes() []*schedulerLaneResolver {
+	lanes := make([]*schedulerLaneResolver, len(r.status.Lanes))
+	for i := range r.status.Lanes {
+		lanes[i] = &schedulerLaneResolver{lane: &r.status.Lanes[i]}
+	}
+	return lanes
+}
+
+type schedulerLaneResolver struct {
+	lane *dto.SchedulerLaneResponse
+}
+
+func (r *schedulerLaneResolver) Name() string             { return r.lane.Name }
+func (r *schedulerLaneResolver) Priorities() []string     { return nonNilStrings(r.lane.Priorities) }
+func (r *schedulerLaneResolver) IntervalSeconds() float64 { return r.lane.IntervalSeconds }
+func (r *schedulerLaneResolver) BatchSize() int32         { return int32(r.lane.BatchSize) }
+func (r *schedulerLaneResolver) WorkerCount() int32       { return int32(r.lane.WorkerCount) }
+func (r *schedulerLaneResolver) LastRunAt() *graphql.Time { return optionalTime(r.lane.LastRunAt) }
+func (r *schedulerLaneResolver) TotalProcessed() int32    { return int32(r.lane.TotalProcessed) }
+func (r *schedulerLaneResolver) TotalSuccessful() int32   { return int32(r.lane.TotalSuccessful) }
+func (r *schedulerLaneResolver) TotalFailed() int32       { return int32(r.lane.TotalFailed) }
+
+// optionalString answers null for the fields the REST API omits when empty
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalGraphQLID(id string) *graphql.ID {
+	if id == "" {
+		return nil
+	}
+	gqlID := graphql.ID(id)
+	return &gqlID
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
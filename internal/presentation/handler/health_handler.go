@@ -6,30 +6,53 @@ import (
 	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	infrahttp "github.com/eneskaya/insider-messaging/internal/infrastructure/http"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/scheduler"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type HealthHandler struct {
-	db    *persistence.PostgresGormDB
-	redis *cache.RedisCache
+	db        *persistence.GormDB
+	redis     *cache.RedisCache
+	fallback  *cache.FallbackCache
+	scheduler *scheduler.Scheduler
+	probe     *infrahttp.ProviderProbe
 }
 
-func NewHealthHandler(db *persistence.PostgresGormDB, redis *cache.RedisCache) *HealthHandler {
+// NewHealthHandler reports the provider only when probe is not nil, and the
+// message cache's backend only when fallback is not nil. A nil redis is
+// reported as disabled.
+func NewHealthHandler(db *persistence.GormDB, redis *cache.RedisCache, fallback *cache.FallbackCache, scheduler *scheduler.Scheduler, probe *infrahttp.ProviderProbe) *HealthHandler {
 	return &HealthHandler{
-		db:    db,
-		redis: redis,
+		db:        db,
+		redis:     redis,
+		fallback:  fallback,
+		scheduler: scheduler,
+		probe:     probe,
 	}
 }
 
 type HealthResponse struct {
-	Status   string            `json:"status"`
-	Services map[string]string `json:"services"`
+	// Status is healthy, degraded when only the provider is unreachable, or
+	// unhealthy when the database or Redis is. Redis is disabled when the
+	// service runs without it.
+	Status    string            `json:"status"`
+	Services  map[string]string `json:"services"`
+	Scheduler SchedulerHealth   `json:"scheduler"`
+}
+
+type SchedulerHealth struct {
+	Running bool `json:"running"`
+	// LastCycleAt is omitted until the first cycle has run
+	LastCycleAt *time.Time `json:"last_cycle_at,omitempty"`
 }
 
 // HealthCheck godoc
 // @Summary Health check endpoint
-// @Description Check the health status of the application and its dependencies
+// @Description Check the health status of the application and its dependencies. With CACHE_MEMORY_FALLBACK enabled, cache reports whether the message cache is served from redis or from memory. With WEBHOOK_HEALTH_CHECK enabled the provider is probed too; an unreachable provider reports degraded but keeps the status code at 200, since messages are still accepted and retried.
 // @Tags health
 // @Accept json
 // @Produce json
@@ -50,38 +73,113 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 		services["database"] = "healthy"
 	}
 
-	if err := h.redis.HealthCheck(ctx); err != nil {
+	if h.redis == nil {
+		services["redis"] = "disabled"
+	} else if err := h.redis.HealthCheck(ctx); err != nil {
 		services["redis"] = "unhealthy"
 		allHealthy = false
 	} else {
 		services["redis"] = "healthy"
 	}
 
+	// The message cache keeps working from memory while Redis is down
+	if h.fallback != nil {
+		if h.fallback.InFallback() {
+			services["cache"] = "memory"
+		} else {
+			services["cache"] = "redis"
+		}
+	}
+
+	providerHealthy := true
+	if h.probe != nil {
+		if err := h.probe.Check(ctx); err != nil {
+			logger.FromContext(ctx).Warn("provider health check failed", zap.Error(err))
+			services["provider"] = "degraded"
+			providerHealthy = false
+		} else {
+			services["provider"] = "healthy"
+		}
+	}
+
 	status := "healthy"
 	statusCode := http.StatusOK
 	if !allHealthy {
 		status = "unhealthy"
 		statusCode = http.StatusServiceUnavailable
+	} else if !providerHealthy {
+		status = "degraded"
+	}
+
+	schedulerHealth := SchedulerHealth{Running: h.scheduler.IsRunning()}
+	if lastRunAt, _, _, _ := h.scheduler.GetStats(); !lastRunAt.IsZero() {
+		schedulerHealth.LastCycleAt = &lastRunAt
 	}
 
 	c.JSON(statusCode, HealthResponse{
-		Status:   status,
-		Services: services,
+		Status:    status,
+		Services:  services,
+		Scheduler: schedulerHealth,
 	})
 }
 
+// ReadinessResponse reports each check /ready runs as ok or failed, and
+// redis as disabled when the service runs without it.
+type ReadinessResponse struct {
+	// Status is ready, or not_ready when any check failed
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
 // ReadinessCheck godoc
 // @Summary Readiness check endpoint
-// @Description Check if the application is ready to accept traffic
+// @Description Check that the database and Redis (unless the service runs without it) answer and that the database migrations are at least at the version this build expects. Returns 503 until they do, so no traffic is routed to an instance that cannot serve it.
 // @Tags health
 // @Accept json
 // @Produce json
-// @Success 200 {object} SuccessResponse
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
 // @Router /ready [get]
 func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, SuccessResponse{
-		Message: "ready",
-	})
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	checks := []readinessCheck{
+		{"database", h.db.HealthCheck},
+		{"migrations", h.db.CheckSchema},
+	}
+	if h.redis != nil {
+		checks = append(checks, readinessCheck{"redis", h.redis.HealthCheck})
+	}
+
+	results := make(map[string]string, len(checks)+1)
+	if h.redis == nil {
+		results["redis"] = "disabled"
+	}
+	ready := true
+	for _, check := range checks {
+		if err := check.check(ctx); err != nil {
+			logger.FromContext(ctx).Warn("readiness check failed",
+				zap.String("check", check.name),
+				zap.Error(err),
+			)
+			results[check.name] = "failed"
+			ready = false
+			continue
+		}
+		results[check.name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, ReadinessResponse{Status: "not_ready", Checks: results})
+		return
+	}
+	c.JSON(http.StatusOK, ReadinessResponse{Status: "ready", Checks: results})
 }
 
 // LivenessCheck godoc
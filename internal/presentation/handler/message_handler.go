@@ -1,25 +1,104 @@
 package handler
 
 import (
+	"compress/gzip"
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/application/dto"
 	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type MessageHandler struct {
 	messageService service.MessageService
+	intake         *service.MessageIntake
 }
 
-func NewMessageHandler(messageService service.MessageService) *MessageHandler {
+func NewMessageHandler(messageService service.MessageService, intake *service.MessageIntake) *MessageHandler {
 	return &MessageHandler{
 		messageService: messageService,
+		intake:         intake,
 	}
 }
 
+// maxImportBodyBytes bounds an import upload; the file is streamed, so this
+// caps the work per request rather than memory.
+const maxImportBodyBytes = 100 << 20
+
+// ImportMessages godoc
+// @Summary Import messages from a file
+// @Description Create a message from every row of a CSV or JSONL file, validating each row on its own. CSV needs a header with phone_number and content; priority, scheduled_at, client_reference and metadata.<key> columns are optional. JSONL has one create message request per line. Rejected rows are listed by line number.
+// @Tags messages
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or JSONL file"
+// @Param format query string false "File format; defaults to the file extension" Enums(csv, jsonl)
+// @Success 200 {object} dto.MessageImportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/import [post]
+func (h *MessageHandler) ImportMessages(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "expected a multipart/form-data upload",
+		})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: fmt.Sprintf("invalid multipart upload: %v", err),
+			})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		format := c.Query("format")
+		if format == "" {
+			format = filepath.Ext(part.FileName())
+		}
+		importFormat, err := service.ParseImportFormat(format)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		result, err := h.messageService.ImportMessages(c.Request.Context(), part, importFormat)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error: "missing file field",
+	})
+}
+
 // GetSentMessages godoc
 // @Summary Get list of sent messages
 // @Description Retrieve a paginated list of successfully sent messages
@@ -47,6 +126,193 @@ func (h *MessageHandler) GetSentMessages(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// ListMessages godoc
+// @Summary List messages
+// @Description Retrieve a paginated list of messages of any status, newest first, optionally filtered
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Message status" Enums(pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined, paused, blocked, expired)
+// @Param channel query string false "Delivery channel" Enums(sms, email, push)
+// @Param phone_number query string false "Recipient phone number"
+// @Param error_code query string false "Last error code"
+// @Param created_after query string false "RFC 3339 timestamp, inclusive"
+// @Param created_before query string false "RFC 3339 timestamp, exclusive"
+// @Param metadata[key] query string false "Metadata value; repeat with other keys to require several"
+// @Param provider_response[field] query string false "Top-level string field of the provider's response, such as provider_response[messageId] or provider_response[sid]; repeat with other fields to require several"
+// @Param split_group_id query string false "Split group ID, listing the parts of one split message"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.MessageListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages [get]
+func (h *MessageHandler) ListMessages(c *gin.Context) {
+	var req dto.ListMessagesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	req.Metadata = c.QueryMap("metadata")
+	req.ProviderResponse = c.QueryMap("provider_response")
+
+	result, err := h.messageService.ListMessages(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SearchMessages godoc
+// @Summary Search messages by content
+// @Description Full-text search over message content, best match first. q takes words, "quoted phrases" and -excluded words; every other word must appear. highlight is the HTML escaped content with matches wrapped in <mark></mark>.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.MessageSearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/search [get]
+func (h *MessageHandler) SearchMessages(c *gin.Context) {
+	var req dto.SearchMessagesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	result, err := h.messageService.SearchMessages(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportMessages godoc
+// @Summary Export messages as CSV
+// @Description Stream the messages matching the filters as CSV, oldest first, optionally gzip compressed. Messages are read from the database a page at a time, so exports of any size are supported. The metadata column holds a JSON object.
+// @Tags messages
+// @Produce text/csv
+// @Produce application/gzip
+// @Security BearerAuth
+// @Param status query string false "Message status" Enums(pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined, paused, blocked, expired)
+// @Param from query string false "RFC 3339 creation time, inclusive"
+// @Param to query string false "RFC 3339 creation time, exclusive"
+// @Param channel query string false "Delivery channel" Enums(sms, email, push)
+// @Param phone_number query string false "Recipient phone number"
+// @Param error_code query string false "Last error code"
+// @Param campaign_id query string false "Campaign ID"
+// @Param metadata[key] query string false "Metadata value; repeat with other keys to require several"
+// @Param gzip query bool false "Compress the export with gzip"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/export [get]
+func (h *MessageHandler) ExportMessages(c *gin.Context) {
+	var req dto.ExportMessagesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	req.Metadata = c.QueryMap("metadata")
+
+	out := &exportWriter{c: c, gzip: req.Gzip}
+	err := h.messageService.ExportMessages(c.Request.Context(), &req, out)
+	if err == nil {
+		err = out.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !out.started {
+		handleError(c, err)
+		return
+	}
+	// The status line is already sent; cutting the body short is all that
+	// is left, and the missing trailer (or gzip footer) shows it to clients
+	logger.FromContext(c.Request.Context()).Error("message export aborted",
+		zap.Error(err),
+	)
+	c.Abort()
+}
+
+// exportWriter sends the response headers on the first write, so an export
+// that fails before producing output can still answer with an error.
+type exportWriter struct {
+	c       *gin.Context
+	gzip    bool
+	started bool
+	body    io.Writer
+	gz      *gzip.Writer
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.start()
+	}
+	return e.body.Write(p)
+}
+
+func (e *exportWriter) start() {
+	e.started = true
+
+	filename := "messages.csv"
+	e.body = e.c.Writer
+	if e.gzip {
+		filename += ".gz"
+		e.gz = gzip.NewWriter(e.c.Writer)
+		e.body = e.gz
+		e.c.Header("Content-Type", "application/gzip")
+	} else {
+		e.c.Header("Content-Type", "text/csv; charset=utf-8")
+	}
+	e.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	e.c.Status(http.StatusOK)
+}
+
+// Close ends the gzip stream; it writes the headers if nothing was written.
+func (e *exportWriter) Close() error {
+	if !e.started {
+		e.start()
+	}
+	if e.gz != nil {
+		return e.gz.Close()
+	}
+	return nil
+}
+
+// GetMessageByWebhookID godoc
+// @Summary Get message by provider message ID
+// @Description Find the message the provider assigned this ID to when it was sent (webhook messageId, Twilio SID or SNS MessageId), e.g. to follow up on a delivery receipt or a provider support ticket
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Provider message ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/by-webhook-id/{id} [get]
+func (h *MessageHandler) GetMessageByWebhookID(c *gin.Context) {
+	result, err := h.messageService.GetMessageByWebhookID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // GetMessage godoc
 // @Summary Get message by ID
 // @Description Retrieve detailed information about a specific message
@@ -56,6 +322,7 @@ func (h *MessageHandler) GetSentMessages(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Message ID"
 // @Success 200 {object} dto.MessageResponse
+// @Success 202 {object} dto.AcceptedMessageResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -72,6 +339,321 @@ func (h *MessageHandler) GetMessage(c *gin.Context) {
 	}
 
 	result, err := h.messageService.GetMessage(c.Request.Context(), id)
+	if err != nil {
+		// Messages submitted with async=true may not be persisted yet
+		if status, ok := h.intake.Status(c.Request.Context(), id); ok && isNotFound(err) {
+			if status.State == service.IntakeStateRejected {
+				handleError(c, status.Err)
+				return
+			}
+			if status.State == service.IntakeStateDuplicate {
+				result, err = h.messageService.GetMessage(c.Request.Context(), status.MessageID)
+				if err != nil {
+					handleError(c, err)
+					return
+				}
+				c.JSON(http.StatusOK, result)
+				return
+			}
+			c.JSON(http.StatusAccepted, acceptedResponse(id, status.State))
+			return
+		}
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CancelMessage godoc
+// @Summary Cancel a pending message
+// @Description Withdraw a pending or paused message that the scheduler has not picked up yet. Messages that are processing, sent or failed cannot be cancelled.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/cancel [post]
+func (h *MessageHandler) CancelMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	result, err := h.messageService.CancelMessage(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PauseMessage godoc
+// @Summary Pause a pending message
+// @Description Hold a pending message back so the scheduler skips it until it is resumed. Messages the scheduler has already picked up cannot be paused.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/pause [post]
+func (h *MessageHandler) PauseMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	result, err := h.messageService.PauseMessage(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ResumeMessage godoc
+// @Summary Resume a paused message
+// @Description Put a paused message back in the pending queue. Its scheduled time and retry backoff still apply.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/resume [post]
+func (h *MessageHandler) ResumeMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	result, err := h.messageService.ResumeMessage(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RetryMessage godoc
+// @Summary Retry a failed or quarantined message
+// @Description Put a failed message back in the queue, or release a quarantined one. By default a failed message gets one more attempt; reset_attempts=true restores the full retry budget.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Param reset_attempts query bool false "Reset the attempt counter" default(false)
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/retry [post]
+func (h *MessageHandler) RetryMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	resetAttempts, _ := strconv.ParseBool(c.DefaultQuery("reset_attempts", "false"))
+
+	result, err := h.messageService.RetryMessage(c.Request.Context(), id, resetAttempts)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListDeadLetters godoc
+// @Summary List dead-lettered messages
+// @Description Retrieve a paginated list of messages that exhausted their attempts, most recent first
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.DeadLetterListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/dead-letter [get]
+func (h *MessageHandler) ListDeadLetters(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.messageService.ListDeadLetters(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RequeueDeadLetter godoc
+// @Summary Replay a dead-lettered message
+// @Description Put a dead-lettered message back in the queue with its attempt counter reset
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/dead-letter/{id}/requeue [post]
+func (h *MessageHandler) RequeueDeadLetter(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	result, err := h.messageService.RequeueDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RetryFailedMessages godoc
+// @Summary Retry failed messages in bulk
+// @Description Put every failed message matching the filter back in the queue. An empty filter matches all failed messages.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param filter body dto.RetryFailedRequest false "Filter and options"
+// @Success 200 {object} dto.RetryFailedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/retry-failed [post]
+func (h *MessageHandler) RetryFailedMessages(c *gin.Context) {
+	var req dto.RetryFailedRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	result, err := h.messageService.RetryFailedMessages(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// WaitForMessage godoc
+// @Summary Wait for a message to reach a terminal state
+// @Description Long-poll until the message is sent or permanently failed, or until the timeout elapses. The current message is returned either way; check its status.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Param timeout query string false "Maximum wait, e.g. 30s (max 60s)" default(30s)
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/wait [get]
+func (h *MessageHandler) WaitForMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	timeout, err := parseWaitTimeout(c.DefaultQuery("timeout", "30s"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.messageService.WaitForMessage(c.Request.Context(), id, timeout)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListMessageAttempts godoc
+// @Summary List a message's send attempts
+// @Description Every attempt to send the message, oldest first, with its duration, the provider's HTTP status and the error or provider response
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} dto.MessageAttemptListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/{id}/attempts [get]
+func (h *MessageHandler) ListMessageAttempts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid message ID format",
+		})
+		return
+	}
+
+	result, err := h.messageService.ListMessageAttempts(c.Request.Context(), id)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -82,16 +664,30 @@ func (h *MessageHandler) GetMessage(c *gin.Context) {
 
 // GetStats godoc
 // @Summary Get message statistics
-// @Description Retrieve statistics about messages (total, pending, sent, failed)
+// @Description Retrieve statistics about messages (total, pending, sent, failed), along with the average attempts per sent message, the average and p95 time from creation to sending and the failure rate over the last hour. With group_by=caller the counts are listed per caller that created the messages instead, as a dto.CallerStatsResponse.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param group_by query string false "Count per caller instead of in total" Enums(caller)
 // @Success 200 {object} dto.MessageStatsResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/messages/stats [get]
 func (h *MessageHandler) GetStats(c *gin.Context) {
+	switch c.Query("group_by") {
+	case "":
+	case "caller":
+		h.getStatsByCaller(c)
+		return
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "group_by must be caller",
+		})
+		return
+	}
+
 	stats, err := h.messageService.GetStats(c.Request.Context())
 	if err != nil {
 		handleError(c, err)
@@ -101,25 +697,123 @@ func (h *MessageHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *MessageHandler) getStatsByCaller(c *gin.Context) {
+	stats, err := h.messageService.GetStatsByCaller(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetStatsTimeSeries godoc
+// @Summary Get message counts over time
+// @Description Count the messages created, sent and failed in each UTC hour or day of a range, each on the time it happened (sent includes messages delivered or undelivered since; a message failed when its last attempt started), with every bucket listed, plus how often each error code occurs among the messages that last failed in the range. The range is widened to whole buckets and may span at most 1000; without from it covers the last 24 buckets.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket query string false "Bucket width" Enums(hour, day) default(hour)
+// @Param from query string false "RFC 3339 timestamp, inclusive"
+// @Param to query string false "RFC 3339 timestamp, exclusive (default now)"
+// @Success 200 {object} dto.StatsTimeSeriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/stats/timeseries [get]
+func (h *MessageHandler) GetStatsTimeSeries(c *gin.Context) {
+	var req dto.StatsTimeSeriesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	result, err := h.messageService.GetStatsTimeSeries(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDuplicateContent godoc
+// @Summary Report duplicate content
+// @Description List content that was created at least min_count times for the same recipient within a range, most repeated first. Without from the range covers the last 24 hours.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "RFC 3339 timestamp, inclusive"
+// @Param to query string false "RFC 3339 timestamp, exclusive (default now)"
+// @Param min_count query int false "Messages that make a duplicate" default(2) minimum(2)
+// @Param limit query int false "Most groups to list" default(50) minimum(1) maximum(500)
+// @Success 200 {object} dto.DuplicateContentResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/messages/duplicates [get]
+func (h *MessageHandler) GetDuplicateContent(c *gin.Context) {
+	var req dto.DuplicateContentRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	result, err := h.messageService.GetDuplicateContent(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // CreateMessage godoc
 // @Summary Create a new message
-// @Description Create a new message to be sent
+// @Description Create a new message to be sent. channel selects sms (default, to phone_number), email or push (to recipient, an email address or device token); a channel without a configured provider is rejected with 422. The phone number is normalized to E.164; a destination country excluded by the allow or block list is rejected with 422 or stored as quarantined, depending on configuration. Content rejected by the content filter is refused with 422. Repeating a request with the same Idempotency-Key header (or client_reference) returns the original message. With async=true the request is acknowledged immediately with 202 and validated/persisted in the background; poll the Location URL for the outcome.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param message body dto.CreateMessageRequest true "Message details"
+// @Param Idempotency-Key header string false "Returns the existing message instead of creating a duplicate; same as client_reference"
+// @Param async query bool false "Create asynchronously" default(false)
 // @Success 201 {object} dto.MessageResponse
+// @Success 202 {object} dto.AcceptedMessageResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/messages [post]
 func (h *MessageHandler) CreateMessage(c *gin.Context) {
 	var req dto.CreateMessageRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: err.Error(),
-		})
+	if !bindStrictJSON(c, &req) {
+		return
+	}
+
+	if key := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader)); key != "" {
+		if req.ClientReference != "" && req.ClientReference != key {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Idempotency-Key header and client_reference must match when both are set",
+			})
+			return
+		}
+		req.ClientReference = key
+	}
+	req.ClientReference = strings.TrimSpace(req.ClientReference)
+
+	if async, _ := strconv.ParseBool(c.DefaultQuery("async", "false")); async {
+		id, err := h.intake.Submit(c.Request.Context(), &req)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		resp := acceptedResponse(id, service.IntakeStateAccepted)
+		c.Header("Location", resp.StatusURL)
+		c.JSON(http.StatusAccepted, resp)
 		return
 	}
 
@@ -131,3 +825,33 @@ func (h *MessageHandler) CreateMessage(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, result)
 }
+
+func acceptedResponse(id uuid.UUID, state service.IntakeState) dto.AcceptedMessageResponse {
+	return dto.AcceptedMessageResponse{
+		ID:        id.String(),
+		Status:    string(state),
+		StatusURL: "/api/v1/messages/" + id.String(),
+	}
+}
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+const maxWaitTimeout = 60 * time.Second
+
+// parseWaitTimeout accepts Go durations ("30s") as well as bare seconds ("30").
+func parseWaitTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid timeout %q", value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	if timeout <= 0 || timeout > maxWaitTimeout {
+		return 0, fmt.Errorf("timeout must be between 1s and %s", maxWaitTimeout)
+	}
+
+	return timeout, nil
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProviderErrorMappingHandler struct {
+	mappingService service.ProviderErrorMappingService
+}
+
+func NewProviderErrorMappingHandler(mappingService service.ProviderErrorMappingService) *ProviderErrorMappingHandler {
+	return &ProviderErrorMappingHandler{
+		mappingService: mappingService,
+	}
+}
+
+// CreateMapping godoc
+// @Summary Map a provider refusal to an error code
+// @Description Store error_code for messages the provider refuses with a matching HTTP status, provider error code or response body. The most specific matching mapping wins; permanent codes fail the message without further attempts. Other instances pick up changes within PROVIDER_ERROR_MAPPING_REFRESH_INTERVAL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param mapping body dto.CreateProviderErrorMappingRequest true "Mapping details"
+// @Success 201 {object} dto.ProviderErrorMappingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/provider-error-mappings [post]
+func (h *ProviderErrorMappingHandler) CreateMapping(c *gin.Context) {
+	var req dto.CreateProviderErrorMappingRequest
+	if !bindStrictJSON(c, &req) {
+		return
+	}
+
+	result, err := h.mappingService.CreateMapping(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListMappings godoc
+// @Summary List provider error mappings
+// @Description Retrieve every provider error mapping, oldest first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ProviderErrorMappingListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/provider-error-mappings [get]
+func (h *ProviderErrorMappingHandler) ListMappings(c *gin.Context) {
+	result, err := h.mappingService.ListMappings(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMapping godoc
+// @Summary Get a provider error mapping
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} dto.ProviderErrorMappingResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/provider-error-mappings/{id} [get]
+func (h *ProviderErrorMappingHandler) GetMapping(c *gin.Context) {
+	id, ok := parseMappingID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.mappingService.GetMapping(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteMapping godoc
+// @Summary Delete a provider error mapping
+// @Description Refusals it matched get the provider's built-in classification again. Messages that already failed keep their error code.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Mapping ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/provider-error-mappings/{id} [delete]
+func (h *ProviderErrorMappingHandler) DeleteMapping(c *gin.Context) {
+	id, ok := parseMappingID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mappingService.DeleteMapping(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "provider error mapping deleted",
+	})
+}
+
+func parseMappingID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid mapping ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
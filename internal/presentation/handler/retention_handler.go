@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/retention"
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	job *retention.Job
+}
+
+func NewRetentionHandler(job *retention.Job) *RetentionHandler {
+	return &RetentionHandler{
+		job: job,
+	}
+}
+
+// GetRetentionStatus godoc
+// @Summary Get retention job status
+// @Description Show the retention policies, the mode and the outcome of the last run
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RetentionStatusResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/admin/retention [get]
+func (h *RetentionHandler) GetRetentionStatus(c *gin.Context) {
+	status := h.job.Status()
+
+	policies := make([]dto.RetentionPolicyResponse, len(status.Policies))
+	for i, policy := range status.Policies {
+		policies[i] = dto.RetentionPolicyResponse{
+			Status: policy.Status,
+			Days:   int(policy.MaxAge / (24 * time.Hour)),
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.RetentionStatusResponse{
+		Mode:            status.Mode,
+		Policies:        policies,
+		IntervalSeconds: status.Interval.Seconds(),
+		LastRunAt:       status.LastRunAt,
+		LastError:       status.LastError,
+		LastRemoved:     status.LastRemoved,
+		TotalRemoved:    status.TotalRemoved,
+	})
+}
+
+// RunRetention godoc
+// @Summary Run the retention job now
+// @Description Soft-delete or purge, depending on the configured mode, every message past its status's retention and report how many were removed
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.RetentionRunResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/retention/run [post]
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	// A run the client stops waiting for still finishes, like a scheduled one
+	result, err := h.job.Run(context.WithoutCancel(c.Request.Context()))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RetentionRunResponse{
+		Mode:       result.Mode,
+		StartedAt:  result.StartedAt,
+		FinishedAt: result.FinishedAt,
+		Removed:    result.Removed,
+		Total:      result.Total(),
+	})
+}
@@ -2,20 +2,36 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
 	"github.com/eneskaya/insider-messaging/internal/infrastructure/scheduler"
 	"github.com/gin-gonic/gin"
 )
 
+// Scheduler states recorded in the audit log
+const (
+	schedulerRunning = "running"
+	schedulerStopped = "stopped"
+)
+
 type SchedulerHandler struct {
 	scheduler *scheduler.Scheduler
+	audit     service.AuditService
+	runs      service.SchedulerRunService
 }
 
-func NewSchedulerHandler(scheduler *scheduler.Scheduler) *SchedulerHandler {
+func NewSchedulerHandler(scheduler *scheduler.Scheduler, audit service.AuditService, runs service.SchedulerRunService) *SchedulerHandler {
 	return &SchedulerHandler{
 		scheduler: scheduler,
+		audit:     audit,
+		runs:      runs,
 	}
 }
 
@@ -44,6 +60,7 @@ func (h *SchedulerHandler) StartScheduler(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionSchedulerStarted, schedulerStopped, schedulerRunning, "")
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Message: "scheduler started successfully",
@@ -73,15 +90,46 @@ func (h *SchedulerHandler) StopScheduler(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	h.recordAudit(c, service.AuditActionSchedulerStopped, schedulerRunning, schedulerStopped, "")
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Message: "scheduler stopped successfully",
 	})
 }
 
+// TriggerCycle godoc
+// @Summary Run a processing cycle now
+// @Description Run one cycle of the main lane immediately, even while the scheduler is stopped, and answer once it has finished with how many messages it claimed, sent and failed. A scheduled cycle in flight is waited for first. The cycle is refused with 409 when the retry budget, auto-pause or circuit breaker holds dispatch back, or another instance holds the dispatch lease.
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TriggerCycleResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/scheduler/trigger [post]
+func (h *SchedulerHandler) TriggerCycle(c *gin.Context) {
+	result, err := h.scheduler.Trigger(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	h.recordAudit(c, service.AuditActionSchedulerTriggered, "", "",
+		fmt.Sprintf("processed=%d successful=%d failed=%d", result.Processed, result.Successful, result.Failed))
+
+	c.JSON(http.StatusOK, dto.TriggerCycleResponse{
+		Claimed:    result.Claimed,
+		Processed:  result.Processed,
+		Successful: result.Successful,
+		Failed:     result.Failed,
+		DurationMs: result.Duration.Milliseconds(),
+	})
+}
+
 // GetSchedulerStatus godoc
 // @Summary Get scheduler status
-// @Description Get current status and statistics of the message scheduler
+// @Description Get current status and statistics of the message scheduler, including its schedule, batch size and worker count, the next run while started, retry budget, webhook circuit breaker, stale message reaper and health-gated auto-pause state
 // @Tags scheduler
 // @Accept json
 // @Produce json
@@ -90,13 +138,232 @@ func (h *SchedulerHandler) StopScheduler(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse
 // @Router /api/v1/scheduler/status [get]
 func (h *SchedulerHandler) GetSchedulerStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.status())
+}
+
+// ListSchedulerRuns godoc
+// @Summary List scheduler runs
+// @Description Retrieve a paginated history of scheduler cycles, newest first, with how many messages each processed, sent and failed. Cycles skipped because the retry budget paused dispatch or the circuit breaker was open are included with a skip_reason.
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.SchedulerRunListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/scheduler/runs [get]
+func (h *SchedulerHandler) ListSchedulerRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.runs.ListRuns(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateSchedulerConfig godoc
+// @Summary Tune the scheduler at runtime
+// @Description Change the batch size, worker count or interval between cycles without a restart. Omitted fields keep their value. A cycle already in flight finishes with the old settings; a new interval restarts the wait for the next cycle. The interval cannot be changed while MESSAGE_SCHEDULE is set. Changes are not persisted and revert to the environment on restart.
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param config body dto.UpdateSchedulerConfigRequest true "Settings to change"
+// @Success 200 {object} dto.SchedulerStatusResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/v1/scheduler/config [patch]
+func (h *SchedulerHandler) UpdateSchedulerConfig(c *gin.Context) {
+	var req dto.UpdateSchedulerConfigRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if req.BatchSize == nil && req.IntervalSeconds == nil && req.WorkerCount == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "at least one of batch_size, interval_seconds or worker_count is required",
+		})
+		return
+	}
+
+	var interval *time.Duration
+	if req.IntervalSeconds != nil {
+		d := time.Duration(*req.IntervalSeconds) * time.Second
+		interval = &d
+	}
+
+	if err := h.scheduler.Reconfigure(req.BatchSize, req.WorkerCount, interval); err != nil {
+		handleError(c, err)
+		return
+	}
+	h.recordAudit(c, service.AuditActionSchedulerReconfigured, "", "", describeConfigChange(&req))
+
+	c.JSON(http.StatusOK, h.status())
+}
+
+func (h *SchedulerHandler) status() dto.SchedulerStatusResponse {
 	lastRunAt, processed, successful, failed := h.scheduler.GetStats()
+	batchSize, workerCount, interval := h.scheduler.Config()
 
-	c.JSON(http.StatusOK, dto.SchedulerStatusResponse{
+	resp := dto.SchedulerStatusResponse{
 		IsRunning:       h.scheduler.IsRunning(),
+		Mode:            h.scheduler.Mode(),
 		LastRunAt:       lastRunAt,
+		NextRunAt:       h.scheduler.NextRunAt(),
+		Schedule:        h.scheduler.Schedule(),
+		BatchSize:       batchSize,
+		WorkerCount:     workerCount,
+		IntervalSeconds: int(interval / time.Second),
 		TotalProcessed:  processed,
 		TotalSuccessful: successful,
 		TotalFailed:     failed,
+		Degraded:        h.scheduler.IsDegraded(),
+		Dispatching:     h.scheduler.IsDispatching(),
+		Priorities:      priorityNames(h.scheduler.MainPriorities()),
+	}
+
+	for _, lane := range h.scheduler.Lanes() {
+		laneResp := dto.SchedulerLaneResponse{
+			Name:               lane.Name,
+			Priorities:         priorityNames(lane.Priorities),
+			IntervalSeconds:    lane.Interval.Seconds(),
+			BatchSize:          lane.BatchSize,
+			WorkerCount:        lane.WorkerCount,
+			RateLimitPerSecond: lane.RateLimitPerSecond,
+			TotalProcessed:     lane.TotalProcessed,
+			TotalSuccessful:    lane.TotalSuccessful,
+			TotalFailed:        lane.TotalFailed,
+		}
+		if !lane.LastRunAt.IsZero() {
+			lastRunAt := lane.LastRunAt
+			laneResp.LastRunAt = &lastRunAt
+		}
+		resp.Lanes = append(resp.Lanes, laneResp)
+	}
+
+	if budget := h.scheduler.RetryBudgetStatus(); budget != nil {
+		resp.RetryBudget = &dto.RetryBudgetResponse{
+			Paused:       budget.Paused,
+			TrippedAt:    budget.TrippedAt,
+			PausedUntil:  budget.PausedUntil,
+			Attempted:    budget.Attempted,
+			Failed:       budget.Failed,
+			FailureRatio: budget.FailureRatio,
+		}
+	}
+
+	if breaker := h.scheduler.CircuitBreakerStatus(); breaker != nil {
+		resp.CircuitBreaker = &dto.CircuitBreakerResponse{
+			State:               breaker.State,
+			ConsecutiveFailures: breaker.ConsecutiveFailures,
+			OpenedAt:            breaker.OpenedAt,
+			RetryAt:             breaker.RetryAt,
+		}
+	}
+
+	if reaper := h.scheduler.ReaperStatus(); reaper != nil {
+		resp.Reaper = &dto.ReaperResponse{
+			ThresholdSeconds: reaper.Threshold.Seconds(),
+			IntervalSeconds:  reaper.Interval.Seconds(),
+			LastRunAt:        reaper.LastRunAt,
+			LastError:        reaper.LastError,
+			TotalRequeued:    reaper.TotalRequeued,
+			TotalFailed:      reaper.TotalFailed,
+		}
+	}
+
+	if autoPause := h.scheduler.AutoPauseStatus(); autoPause != nil {
+		resp.AutoPause = &dto.AutoPauseResponse{
+			Paused:      autoPause.Paused,
+			Reason:      autoPause.Reason,
+			PausedAt:    autoPause.PausedAt,
+			LastCheckAt: autoPause.LastCheckAt,
+			Checks:      make([]dto.HealthCheckResponse, 0, len(autoPause.Checks)),
+		}
+		for _, check := range autoPause.Checks {
+			resp.AutoPause.Checks = append(resp.AutoPause.Checks, dto.HealthCheckResponse{
+				Name:                check.Name,
+				ConsecutiveFailures: check.ConsecutiveFailures,
+				LastError:           check.LastError,
+			})
+		}
+	}
+
+	return resp
+}
+
+// ResumeDispatch godoc
+// @Summary Resume dispatch after a safety pause
+// @Description Clear a pause raised by the retry budget and reset its failure window
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/scheduler/resume [post]
+func (h *SchedulerHandler) ResumeDispatch(c *gin.Context) {
+	budget := h.scheduler.RetryBudgetStatus()
+	if budget == nil || !budget.Paused {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "dispatch is not paused",
+		})
+		return
+	}
+
+	h.scheduler.ResumeDispatch()
+	h.recordAudit(c, service.AuditActionDispatchResumed, "", "", "")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "dispatch resumed successfully",
 	})
 }
+
+func (h *SchedulerHandler) recordAudit(c *gin.Context, action, from, to, details string) {
+	if h.audit == nil {
+		return
+	}
+	h.audit.Record(c.Request.Context(), service.AuditRecord{
+		Action:       action,
+		ResourceType: service.AuditResourceScheduler,
+		FromStatus:   from,
+		ToStatus:     to,
+		Details:      details,
+	})
+}
+
+// describeConfigChange lists the settings a reconfiguration changed, e.g.
+// "batch_size=20 worker_count=8".
+func describeConfigChange(req *dto.UpdateSchedulerConfigRequest) string {
+	var changes []string
+	if req.BatchSize != nil {
+		changes = append(changes, fmt.Sprintf("batch_size=%d", *req.BatchSize))
+	}
+	if req.IntervalSeconds != nil {
+		changes = append(changes, fmt.Sprintf("interval_seconds=%d", *req.IntervalSeconds))
+	}
+	if req.WorkerCount != nil {
+		changes = append(changes, fmt.Sprintf("worker_count=%d", *req.WorkerCount))
+	}
+	return strings.Join(changes, " ")
+}
+
+func priorityNames(priorities []valueobject.MessagePriority) []string {
+	if len(priorities) == 0 {
+		return nil
+	}
+	names := make([]string, len(priorities))
+	for i, priority := range priorities {
+		names[i] = priority.String()
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewTemplate godoc
+// @Summary Preview message content with variables
+// @Description Render content with {{name}} placeholders filled from variables and check it as SMS content: content moderation, the character limit, and the SMS segments of each part it would be split into. Reports the content as it would be sent and any violations that would reject it as a message. Nothing is stored or sent.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param template body dto.TemplatePreviewRequest true "Content and variables"
+// @Success 200 {object} dto.TemplatePreviewResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/v1/templates/preview [post]
+func (h *MessageHandler) PreviewTemplate(c *gin.Context) {
+	var req dto.TemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.messageService.PreviewTemplate(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
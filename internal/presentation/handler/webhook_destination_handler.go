@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/eneskaya/insider-messaging/internal/application/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookDestinationHandler struct {
+	destinationService service.WebhookDestinationService
+}
+
+func NewWebhookDestinationHandler(destinationService service.WebhookDestinationService) *WebhookDestinationHandler {
+	return &WebhookDestinationHandler{
+		destinationService: destinationService,
+	}
+}
+
+// CreateDestination godoc
+// @Summary Create a webhook destination
+// @Description Register a webhook that messages created with its destination_id are sent to, with its own auth key and rate limit
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param destination body dto.CreateWebhookDestinationRequest true "Destination details"
+// @Success 201 {object} dto.WebhookDestinationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/webhook-destinations [post]
+func (h *WebhookDestinationHandler) CreateDestination(c *gin.Context) {
+	var req dto.CreateWebhookDestinationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.destinationService.CreateDestination(c.Request.Context(), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListDestinations godoc
+// @Summary List webhook destinations
+// @Description Retrieve a paginated list of webhook destinations, oldest first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.WebhookDestinationListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/webhook-destinations [get]
+func (h *WebhookDestinationHandler) ListDestinations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	result, err := h.destinationService.ListDestinations(c.Request.Context(), page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetDestination godoc
+// @Summary Get a webhook destination
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Destination ID"
+// @Success 200 {object} dto.WebhookDestinationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/webhook-destinations/{id} [get]
+func (h *WebhookDestinationHandler) GetDestination(c *gin.Context) {
+	id, ok := parseDestinationID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.destinationService.GetDestination(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateDestination godoc
+// @Summary Change or deactivate a webhook destination
+// @Description Messages of a deactivated destination fail with DESTINATION_UNAVAILABLE. Senders pick up changes within WEBHOOK_DESTINATION_REFRESH_INTERVAL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Destination ID"
+// @Param destination body dto.UpdateWebhookDestinationRequest true "Fields to change"
+// @Success 200 {object} dto.WebhookDestinationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/admin/webhook-destinations/{id} [patch]
+func (h *WebhookDestinationHandler) UpdateDestination(c *gin.Context) {
+	id, ok := parseDestinationID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookDestinationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.destinationService.UpdateDestination(c.Request.Context(), id, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseDestinationID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid destination ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
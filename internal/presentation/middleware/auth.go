@@ -1,14 +1,35 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/jwt"
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-// AuthMiddleware validates Bearer token for protected endpoints
-func AuthMiddleware(apiToken string) gin.HandlerFunc {
+// TenantResolver maps a tenant API key to its tenant. ok is false for
+// unknown keys and deactivated tenants.
+type TenantResolver interface {
+	ResolveAPIKey(ctx context.Context, apiKey string) (tenantID uuid.UUID, ok bool, err error)
+}
+
+// roleKey holds the role of a request authenticated with a JWT
+const roleKey = "auth_role"
+
+// AuthMiddleware validates Bearer token for protected endpoints. Any of the
+// extra tokens (e.g. the admin token) is accepted as well. When verifier is
+// set, JWTs are accepted and their role recorded for RequireRole. Other tokens
+// are looked up as tenant API keys when tenants is set, and the request
+// context is scoped to the resolved tenant.
+func AuthMiddleware(apiToken string, tenants TenantResolver, verifier *jwt.Verifier, extraTokens ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip auth for health and docs endpoints
 		if strings.HasPrefix(c.Request.URL.Path, "/health") ||
@@ -19,37 +40,184 @@ func AuthMiddleware(apiToken string) gin.HandlerFunc {
 			return
 		}
 
-		// Get Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "missing authorization header",
+		token, ok := bearerToken(c)
+		if !ok {
+			return
+		}
+
+		// Validate token
+		if apiToken != "" && token == apiToken {
+			setActor(c, actor.APIToken)
+			c.Next()
+			return
+		}
+		if containsToken(extraTokens, token) {
+			setActor(c, actor.AdminToken)
+			c.Next()
+			return
+		}
+
+		if verifier != nil && jwt.LooksLikeToken(token) {
+			claims, err := verifier.Verify(token)
+			if err != nil {
+				message := "invalid token"
+				if errors.Is(err, jwt.ErrExpired) {
+					message = "token expired"
+				}
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": message,
+				})
+				c.Abort()
+				return
+			}
+
+			c.Set(roleKey, claims.Role)
+			setActor(c, actor.Token(claims.Subject))
+			c.Next()
+			return
+		}
+
+		if tenants != nil && token != "" {
+			tenantID, ok, err := tenants.ResolveAPIKey(c.Request.Context(), token)
+			if err != nil {
+				logger.FromContext(c.Request.Context()).Error("failed to resolve tenant API key", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "failed to verify token",
+				})
+				c.Abort()
+				return
+			}
+			if ok {
+				c.Request = c.Request.WithContext(tenant.WithTenantID(c.Request.Context(), tenantID))
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid token",
+		})
+		c.Abort()
+	}
+}
+
+// OperatorOnly rejects requests authenticated with a tenant API key, for
+// endpoints that act on the whole deployment (scheduler control, admin).
+func OperatorOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := tenant.FromContext(c.Request.Context()); ok {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "tenant API keys cannot access this endpoint",
 			})
 			c.Abort()
 			return
 		}
 
-		// Check Bearer prefix
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid authorization format, expected: Bearer <token>",
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose JWT role is below minimum. Requests
+// without a role, made with a static token or tenant API key, pass; the
+// static token checks apply to them.
+func RequireRole(minimum string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, ok := requestRole(c); ok && !jwt.RoleAtLeast(role, minimum) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": minimum + " role required",
 			})
 			c.Abort()
 			return
 		}
 
-		// Validate token
-		token := parts[1]
-		if token != apiToken {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "invalid token",
+		c.Next()
+	}
+}
+
+// ReadOnlyGuard lets read-only JWTs make GET and HEAD requests only.
+func ReadOnlyGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		RequireRole(jwt.RoleOperator)(c)
+	}
+}
+
+// AdminAuthMiddleware restricts a route group to callers presenting the
+// admin token, or a JWT with the admin role
+func AdminAuthMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requestRole(c); ok {
+			RequireRole(jwt.RoleAdmin)(c)
+			return
+		}
+
+		token, ok := bearerToken(c)
+		if !ok {
+			return
+		}
+
+		if token != adminToken {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "admin token required",
 			})
 			c.Abort()
 			return
 		}
 
-		// Token is valid, continue
+		setActor(c, actor.AdminToken)
 		c.Next()
 	}
 }
+
+func requestRole(c *gin.Context) (string, bool) {
+	role, ok := c.Get(roleKey)
+	if !ok {
+		return "", false
+	}
+	return role.(string), true
+}
+
+// setActor records on the request context which token authenticated the
+// request, for the audit log.
+func setActor(c *gin.Context, name string) {
+	c.Request = c.Request.WithContext(actor.WithActor(c.Request.Context(), name))
+}
+
+// bearerToken extracts the token from the Authorization header. On failure it
+// writes the 401 response and aborts the request.
+func bearerToken(c *gin.Context) (string, bool) {
+	// Get Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "missing authorization header",
+		})
+		c.Abort()
+		return "", false
+	}
+
+	// Check Bearer prefix
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid authorization format, expected: Bearer <token>",
+		})
+		c.Abort()
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+func containsToken(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t != "" && t == token {
+			return true
+		}
+	}
+	return false
+}
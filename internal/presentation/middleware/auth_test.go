@@ -1,11 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/eneskaya/insider-messaging/pkg/actor"
+	"github.com/eneskaya/insider-messaging/pkg/jwt"
+	"github.com/eneskaya/insider-messaging/pkg/tenant"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,7 +24,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	apiToken := "test-secret-token"
 
 	router := gin.New()
-	router.Use(AuthMiddleware(apiToken))
+	router.Use(AuthMiddleware(apiToken, nil, nil))
 	router.GET("/api/v1/messages", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
@@ -38,7 +44,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 func TestAuthMiddleware_MissingAuthorizationHeader(t *testing.T) {
 	// Arrange
 	apiToken := "test-secret-token"
-	middleware := AuthMiddleware(apiToken)
+	middleware := AuthMiddleware(apiToken, nil, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -55,7 +61,7 @@ func TestAuthMiddleware_MissingAuthorizationHeader(t *testing.T) {
 func TestAuthMiddleware_InvalidTokenFormat(t *testing.T) {
 	// Arrange
 	apiToken := "test-secret-token"
-	middleware := AuthMiddleware(apiToken)
+	middleware := AuthMiddleware(apiToken, nil, nil)
 
 	testCases := []struct {
 		name          string
@@ -99,7 +105,7 @@ func TestAuthMiddleware_InvalidTokenFormat(t *testing.T) {
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	// Arrange
 	apiToken := "test-secret-token"
-	middleware := AuthMiddleware(apiToken)
+	middleware := AuthMiddleware(apiToken, nil, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -131,7 +137,7 @@ func TestAuthMiddleware_SkipHealthEndpoints(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			router := gin.New()
-			router.Use(AuthMiddleware(apiToken))
+			router.Use(AuthMiddleware(apiToken, nil, nil))
 			router.GET(tc.path, func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"status": "ok"})
 			})
@@ -153,7 +159,7 @@ func TestAuthMiddleware_SkipHealthEndpoints(t *testing.T) {
 func TestAuthMiddleware_RequireAuthForProtectedEndpoints(t *testing.T) {
 	// Arrange
 	apiToken := "test-secret-token"
-	middleware := AuthMiddleware(apiToken)
+	middleware := AuthMiddleware(apiToken, nil, nil)
 
 	testCases := []string{
 		"/api/v1/messages",
@@ -176,3 +182,271 @@ func TestAuthMiddleware_RequireAuthForProtectedEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddleware_AcceptsExtraTokens(t *testing.T) {
+	// Arrange
+	router := gin.New()
+	router.Use(AuthMiddleware("test-secret-token", nil, nil, "admin-token"))
+	router.GET("/api/v1/messages", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_RecordsActor(t *testing.T) {
+	tests := []struct {
+		token string
+		actor string
+	}{
+		{"test-secret-token", actor.APIToken},
+		{"admin-token", actor.AdminToken},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		var got string
+		router := gin.New()
+		router.Use(AuthMiddleware("test-secret-token", nil, nil, "admin-token"))
+		router.GET("/api/v1/messages", func(c *gin.Context) {
+			got = actor.FromContext(c.Request.Context())
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tt.actor, got)
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	testCases := []struct {
+		name         string
+		authHeader   string
+		expectedCode int
+	}{
+		{name: "admin token", authHeader: "Bearer admin-token", expectedCode: http.StatusOK},
+		{name: "api token", authHeader: "Bearer test-secret-token", expectedCode: http.StatusForbidden},
+		{name: "missing header", authHeader: "", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(AdminAuthMiddleware("admin-token"))
+			router.PUT("/api/v1/admin/log-level", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/log-level", nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+type stubTenantResolver struct {
+	keys map[string]uuid.UUID
+}
+
+func (r stubTenantResolver) ResolveAPIKey(_ context.Context, apiKey string) (uuid.UUID, bool, error) {
+	id, ok := r.keys[apiKey]
+	return id, ok, nil
+}
+
+func TestAuthMiddleware_ResolvesTenantAPIKey(t *testing.T) {
+	// Arrange
+	tenantID := uuid.New()
+	resolver := stubTenantResolver{keys: map[string]uuid.UUID{"tk_tenant": tenantID}}
+
+	var scoped uuid.UUID
+	router := gin.New()
+	router.Use(AuthMiddleware("test-secret-token", resolver, nil))
+	router.GET("/api/v1/messages", func(c *gin.Context) {
+		scoped, _ = tenant.FromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+	req.Header.Set("Authorization", "Bearer tk_tenant")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, tenantID, scoped)
+}
+
+func TestAuthMiddleware_UnknownTenantAPIKey(t *testing.T) {
+	// Arrange
+	resolver := stubTenantResolver{keys: map[string]uuid.UUID{}}
+	middleware := AuthMiddleware("test-secret-token", resolver, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+	c.Request.Header.Set("Authorization", "Bearer tk_unknown")
+
+	// Act
+	middleware(c)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid token")
+}
+
+func TestOperatorOnly_RejectsTenantKeys(t *testing.T) {
+	// Arrange
+	resolver := stubTenantResolver{keys: map[string]uuid.UUID{"tk_tenant": uuid.New()}}
+
+	router := gin.New()
+	router.Use(AuthMiddleware("test-secret-token", resolver, nil))
+	router.POST("/api/v1/scheduler/stop", OperatorOnly(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	testCases := []struct {
+		name         string
+		token        string
+		expectedCode int
+	}{
+		{name: "global token", token: "test-secret-token", expectedCode: http.StatusOK},
+		{name: "tenant key", token: "tk_tenant", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler/stop", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func signTestJWT(t *testing.T, role string, expiresIn time.Duration) string {
+	token, err := jwt.Sign(testJWTSecret, jwt.Claims{
+		Subject:   "alice",
+		Role:      role,
+		ExpiresAt: time.Now().Add(expiresIn).Unix(),
+	})
+	assert.NoError(t, err)
+	return token
+}
+
+func TestAuthMiddleware_AcceptsJWT(t *testing.T) {
+	// Arrange
+	var got string
+	router := gin.New()
+	router.Use(AuthMiddleware("", nil, jwt.NewVerifier(testJWTSecret, "")))
+	router.GET("/api/v1/messages", func(c *gin.Context) {
+		got = actor.FromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name         string
+		token        string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "valid token", token: signTestJWT(t, jwt.RoleReadOnly, time.Hour), expectedCode: http.StatusOK},
+		{name: "expired token", token: signTestJWT(t, jwt.RoleAdmin, -time.Hour), expectedCode: http.StatusUnauthorized, expectedBody: "token expired"},
+		{name: "empty token", token: "", expectedCode: http.StatusUnauthorized, expectedBody: "invalid token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+		})
+	}
+	assert.Equal(t, actor.Token("alice"), got)
+}
+
+func TestRequireRole_EnforcesJWTRoles(t *testing.T) {
+	// Arrange
+	router := gin.New()
+	router.Use(AuthMiddleware("test-secret-token", nil, jwt.NewVerifier(testJWTSecret, "")))
+	api := router.Group("/api/v1", ReadOnlyGuard())
+	api.GET("/messages", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/messages", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	api.POST("/scheduler/stop", RequireRole(jwt.RoleAdmin), func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.PUT("/admin/log-level", AdminAuthMiddleware("admin-token"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	readOnly := signTestJWT(t, jwt.RoleReadOnly, time.Hour)
+	operator := signTestJWT(t, jwt.RoleOperator, time.Hour)
+	admin := signTestJWT(t, jwt.RoleAdmin, time.Hour)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{name: "read-only reads", method: http.MethodGet, path: "/api/v1/messages", token: readOnly, expectedCode: http.StatusOK},
+		{name: "read-only writes", method: http.MethodPost, path: "/api/v1/messages", token: readOnly, expectedCode: http.StatusForbidden},
+		{name: "operator writes", method: http.MethodPost, path: "/api/v1/messages", token: operator, expectedCode: http.StatusCreated},
+		{name: "operator stops scheduler", method: http.MethodPost, path: "/api/v1/scheduler/stop", token: operator, expectedCode: http.StatusForbidden},
+		{name: "admin stops scheduler", method: http.MethodPost, path: "/api/v1/scheduler/stop", token: admin, expectedCode: http.StatusOK},
+		{name: "static token stops scheduler", method: http.MethodPost, path: "/api/v1/scheduler/stop", token: "test-secret-token", expectedCode: http.StatusOK},
+		{name: "operator on admin endpoint", method: http.MethodPut, path: "/api/v1/admin/log-level", token: operator, expectedCode: http.StatusForbidden},
+		{name: "admin on admin endpoint", method: http.MethodPut, path: "/api/v1/admin/log-level", token: admin, expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit caps request bodies at maxBytes. A request declaring a larger
+// Content-Length is answered 413 before its handler runs; any other body is
+// cut off at the limit, so reading past it fails and the handler answers
+// 413 in turn. routes maps a route path, as registered, to its own limit;
+// 0 there, or as maxBytes, leaves a route without one.
+func BodyLimit(maxBytes int64, routes map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := routes[c.FullPath()]
+		if !ok {
+			limit = maxBytes
+		}
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "request body too large",
+				"code":  string(apperrors.ErrorCodeBodyTooLarge),
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// readBody answers 413 when the body cannot be read whole, as bindJSON does.
+func readBody(c *gin.Context) {
+	if _, err := io.ReadAll(c.Request.Body); err != nil {
+		c.Status(http.StatusRequestEntityTooLarge)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		chunked       bool
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "within limit", path: "/items", body: "0123456789", expectedCode: http.StatusOK},
+		{
+			name:          "declared length over limit",
+			path:          "/items",
+			body:          "0123456789x",
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedError: `{"error": "request body too large", "code": "BODY_TOO_LARGE"}`,
+		},
+		{name: "unknown length over limit", path: "/items", body: "0123456789x", chunked: true, expectedCode: http.StatusRequestEntityTooLarge},
+		{name: "route without limit", path: "/items/import", body: strings.Repeat("x", 100), expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(BodyLimit(10, map[string]int64{"/items/import": 0}))
+			router.POST("/items", readBody)
+			router.POST("/items/import", readBody)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedError != "" {
+				assert.JSONEq(t, tt.expectedError, w.Body.String())
+			}
+		})
+	}
+}
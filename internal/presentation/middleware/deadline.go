@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	apperrors "github.com/eneskaya/insider-messaging/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestDeadlines are the server-side time limits of API requests: Read
+// for GET and HEAD requests, Write for the others and Bulk for the routes
+// that work through many messages at once. 0 disables a limit.
+type RequestDeadlines struct {
+	Read  time.Duration
+	Write time.Duration
+	Bulk  time.Duration
+}
+
+// Deadline cancels the request context once the request's limit has
+// passed, so the services and repositories working on it give up too.
+// routes maps a route path, as registered, to its own limit and takes
+// precedence over the method; 0 there leaves the route without one. A
+// handler that returns without answering after the deadline gets 504.
+func Deadline(deadlines RequestDeadlines, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.FullPath()]
+		if !ok {
+			timeout = deadlines.Write
+			if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+				timeout = deadlines.Read
+			}
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"error": "request deadline exceeded",
+				"code":  string(apperrors.ErrorCodeDeadlineExceeded),
+			})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitForCancel blocks until the request context ends, like a query that
+// outlives the deadline, and answers nothing.
+func waitForCancel(c *gin.Context) {
+	select {
+	case <-c.Request.Context().Done():
+	case <-time.After(time.Second):
+		c.Status(http.StatusOK)
+	}
+}
+
+func TestDeadline_ExceededAnswers504(t *testing.T) {
+	// Arrange
+	router := gin.New()
+	router.Use(Deadline(RequestDeadlines{Read: 10 * time.Millisecond, Write: time.Minute}, nil))
+	router.GET("/slow", waitForCancel)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+
+	// Act
+	started := time.Now()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"error": "request deadline exceeded", "code": "DEADLINE_EXCEEDED"}`, w.Body.String())
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestDeadline_SelectsLimitByMethodAndRoute(t *testing.T) {
+	// Arrange
+	deadlines := map[string]time.Duration{}
+	record := func(c *gin.Context) {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			deadlines[c.FullPath()] = time.Until(deadline).Round(time.Minute)
+		}
+		c.Status(http.StatusOK)
+	}
+
+	router := gin.New()
+	router.Use(Deadline(RequestDeadlines{Read: 5 * time.Minute, Write: 10 * time.Minute, Bulk: 60 * time.Minute}, map[string]time.Duration{
+		"/items/import":   60 * time.Minute,
+		"/items/:id/wait": 0,
+	}))
+	router.GET("/items/:id", record)
+	router.POST("/items", record)
+	router.POST("/items/import", record)
+	router.GET("/items/:id/wait", record)
+
+	// Act
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items/1", nil),
+		httptest.NewRequest(http.MethodPost, "/items", nil),
+		httptest.NewRequest(http.MethodPost, "/items/import", nil),
+		httptest.NewRequest(http.MethodGet, "/items/1/wait", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Assert
+	assert.Equal(t, map[string]time.Duration{
+		"/items/:id":    5 * time.Minute,
+		"/items":        10 * time.Minute,
+		"/items/import": 60 * time.Minute,
+	}, deadlines)
+}
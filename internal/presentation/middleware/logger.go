@@ -35,15 +35,15 @@ func Logger() gin.HandlerFunc {
 
 		if len(c.Errors) > 0 {
 			for _, e := range c.Errors {
-				logger.Get().Error("request error", append(fields, zap.Error(e.Err))...)
+				logger.FromContext(c.Request.Context()).Error("request error", append(fields, zap.Error(e.Err))...)
 			}
 		} else {
 			if statusCode >= 500 {
-				logger.Get().Error("server error", fields...)
+				logger.FromContext(c.Request.Context()).Error("server error", fields...)
 			} else if statusCode >= 400 {
-				logger.Get().Warn("client error", fields...)
+				logger.FromContext(c.Request.Context()).Warn("client error", fields...)
 			} else {
-				logger.Get().Info("request completed", fields...)
+				logger.FromContext(c.Request.Context()).Info("request completed", fields...)
 			}
 		}
 	}
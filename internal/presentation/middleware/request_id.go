@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength matches the messages.trace_id column width.
+const maxRequestIDLength = 64
+
+// RequestID puts the caller's X-Request-ID, or a new one when it is missing
+// or too long, on the request context and echoes it in the response. Log
+// lines, audit entries and webhook calls made for the request carry it, so
+// they can be traced back to it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = requestid.New()
+		}
+
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithRequestID(header string) (string, *httptest.ResponseRecorder) {
+	var got string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/api/v1/messages", func(c *gin.Context) {
+		got = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/messages", nil)
+	if header != "" {
+		req.Header.Set(requestid.Header, header)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return got, w
+}
+
+func TestRequestID_KeepsCallerID(t *testing.T) {
+	got, w := serveWithRequestID("req-123")
+
+	assert.Equal(t, "req-123", got)
+	assert.Equal(t, "req-123", w.Header().Get(requestid.Header))
+}
+
+func TestRequestID_GeneratesMissingID(t *testing.T) {
+	got, w := serveWithRequestID("")
+
+	assert.NotEmpty(t, got)
+	assert.Equal(t, got, w.Header().Get(requestid.Header))
+}
+
+func TestRequestID_ReplacesOverlongID(t *testing.T) {
+	overlong := strings.Repeat("a", maxRequestIDLength+1)
+
+	got, _ := serveWithRequestID(overlong)
+
+	assert.NotEmpty(t, got)
+	assert.NotEqual(t, overlong, got)
+}
@@ -1,8 +1,12 @@
 package router
 
 import (
+	"time"
+
 	"github.com/eneskaya/insider-messaging/internal/presentation/handler"
 	"github.com/eneskaya/insider-messaging/internal/presentation/middleware"
+	"github.com/eneskaya/insider-messaging/pkg/jwt"
+	"github.com/eneskaya/insider-messaging/pkg/metrics"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -13,19 +17,60 @@ type Router struct {
 	messageHandler    *handler.MessageHandler
 	schedulerHandler  *handler.SchedulerHandler
 	healthHandler     *handler.HealthHandler
+	failoverHandler   *handler.FailoverHandler
+	logLevelHandler   *handler.LogLevelHandler
+	tenantHandler     *handler.TenantHandler
+	deliveryHandler   *handler.DeliveryHandler
+	auditHandler      *handler.AuditHandler
+	campaignHandler   *handler.CampaignHandler
+	retentionHandler  *handler.RetentionHandler
+	archiveHandler    *handler.ArchiveHandler
+	blacklistHandler  *handler.BlacklistHandler
+	webhookHandler    *handler.WebhookDestinationHandler
+	mappingHandler    *handler.ProviderErrorMappingHandler
+	contactHandler    *handler.ContactHandler
+	analyticsHandler  *handler.AnalyticsHandler
+	graphqlHandler    *handler.GraphQLHandler
+	tenantResolver    middleware.TenantResolver
 	apiToken          string
+	adminToken        string
+	jwtVerifier       *jwt.Verifier
+	metricsEnabled    bool
+	deadlines         middleware.RequestDeadlines
+	maxBodyBytes      int64
 }
 
 func NewRouter(
 	messageHandler *handler.MessageHandler,
 	schedulerHandler *handler.SchedulerHandler,
 	healthHandler *handler.HealthHandler,
+	failoverHandler *handler.FailoverHandler,
+	logLevelHandler *handler.LogLevelHandler,
+	tenantHandler *handler.TenantHandler,
+	deliveryHandler *handler.DeliveryHandler,
+	auditHandler *handler.AuditHandler,
+	campaignHandler *handler.CampaignHandler,
+	retentionHandler *handler.RetentionHandler,
+	archiveHandler *handler.ArchiveHandler,
+	blacklistHandler *handler.BlacklistHandler,
+	webhookHandler *handler.WebhookDestinationHandler,
+	mappingHandler *handler.ProviderErrorMappingHandler,
+	contactHandler *handler.ContactHandler,
+	analyticsHandler *handler.AnalyticsHandler,
+	graphqlHandler *handler.GraphQLHandler,
+	tenantResolver middleware.TenantResolver,
 	apiToken string,
+	adminToken string,
+	jwtVerifier *jwt.Verifier,
+	metricsEnabled bool,
+	deadlines middleware.RequestDeadlines,
+	maxBodyBytes int64,
 ) *Router {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 
 	engine.Use(middleware.Recovery())
+	engine.Use(middleware.RequestID())
 	engine.Use(middleware.Logger())
 	engine.Use(middleware.CORS())
 
@@ -34,7 +79,27 @@ func NewRouter(
 		messageHandler:    messageHandler,
 		schedulerHandler:  schedulerHandler,
 		healthHandler:     healthHandler,
+		failoverHandler:   failoverHandler,
+		logLevelHandler:   logLevelHandler,
+		tenantHandler:     tenantHandler,
+		deliveryHandler:   deliveryHandler,
+		auditHandler:      auditHandler,
+		campaignHandler:   campaignHandler,
+		retentionHandler:  retentionHandler,
+		archiveHandler:    archiveHandler,
+		blacklistHandler:  blacklistHandler,
+		webhookHandler:    webhookHandler,
+		mappingHandler:    mappingHandler,
+		contactHandler:    contactHandler,
+		analyticsHandler:  analyticsHandler,
+		graphqlHandler:    graphqlHandler,
+		tenantResolver:    tenantResolver,
 		apiToken:          apiToken,
+		adminToken:        adminToken,
+		jwtVerifier:       jwtVerifier,
+		metricsEnabled:    metricsEnabled,
+		deadlines:         deadlines,
+		maxBodyBytes:      maxBodyBytes,
 	}
 }
 
@@ -44,29 +109,194 @@ func (r *Router) Setup() *gin.Engine {
 	r.engine.GET("/ready", r.healthHandler.ReadinessCheck)
 	r.engine.GET("/live", r.healthHandler.LivenessCheck)
 	r.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if r.metricsEnabled {
+		r.engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// Delivery receipts come from the provider, which signs them instead of
+	// presenting a bearer token; the route only exists with a receipt secret
+	if r.deliveryHandler != nil {
+		r.engine.POST("/api/v1/webhooks/delivery-status", r.deliveryHandler.HandleDeliveryStatus)
+	}
 
 	// Protected endpoints (auth required)
 	// Auth middleware is applied globally, but skips health/swagger endpoints
-	if r.apiToken != "" {
-		r.engine.Use(middleware.AuthMiddleware(r.apiToken))
+	if r.apiToken != "" || r.jwtVerifier != nil {
+		r.engine.Use(middleware.AuthMiddleware(r.apiToken, r.tenantResolver, r.jwtVerifier, r.adminToken))
+	}
+
+	// GraphQL only queries, so read-only JWTs may post to it and it gets the
+	// read deadline; the route only exists when GRAPHQL_ENABLED is set
+	if r.graphqlHandler != nil {
+		graphqlDeadline := map[string]time.Duration{"/graphql": r.deadlines.Read}
+		r.engine.POST("/graphql", middleware.Deadline(r.deadlines, graphqlDeadline),
+			middleware.BodyLimit(r.maxBodyBytes, nil), r.graphqlHandler.Query)
+	}
+
+	// Routes handling many messages at once get the bulk deadline; waiting
+	// for a message is bounded by its own timeout
+	bulkRoutes := map[string]time.Duration{
+		"/api/v1/messages/export":            r.deadlines.Bulk,
+		"/api/v1/messages/import":            r.deadlines.Bulk,
+		"/api/v1/messages/retry-failed":      r.deadlines.Bulk,
+		"/api/v1/messages/to-group/:groupId": r.deadlines.Bulk,
+		"/api/v1/campaigns/:id/messages":     r.deadlines.Bulk,
+		"/api/v1/admin/retention/run":        r.deadlines.Bulk,
+		"/api/v1/admin/archives/run":         r.deadlines.Bulk,
+		"/api/v1/scheduler/trigger":          r.deadlines.Bulk,
+		"/api/v1/messages/:id/wait":          0,
+	}
+
+	// Imports are streamed and capped by their handler
+	bodyLimitRoutes := map[string]int64{
+		"/api/v1/messages/import": 0,
 	}
 
-	v1 := r.engine.Group("/api/v1")
+	// Read-only JWTs can only make GET requests
+	v1 := r.engine.Group("/api/v1", middleware.ReadOnlyGuard(), middleware.Deadline(r.deadlines, bulkRoutes),
+		middleware.BodyLimit(r.maxBodyBytes, bodyLimitRoutes))
 	{
-		scheduler := v1.Group("/scheduler")
+		// Tenant API keys only reach their own messages; the scheduler is
+		// shared by everyone
+		scheduler := v1.Group("/scheduler", middleware.OperatorOnly())
 		{
-			scheduler.POST("/start", r.schedulerHandler.StartScheduler)
-			scheduler.POST("/stop", r.schedulerHandler.StopScheduler)
+			// Starting and stopping dispatch needs the admin role when the
+			// caller has a JWT
+			scheduler.POST("/start", middleware.RequireRole(jwt.RoleAdmin), r.schedulerHandler.StartScheduler)
+			scheduler.POST("/stop", middleware.RequireRole(jwt.RoleAdmin), r.schedulerHandler.StopScheduler)
 			scheduler.GET("/status", r.schedulerHandler.GetSchedulerStatus)
+			scheduler.GET("/runs", r.schedulerHandler.ListSchedulerRuns)
+			scheduler.POST("/resume", middleware.RequireRole(jwt.RoleAdmin), r.schedulerHandler.ResumeDispatch)
+			scheduler.POST("/trigger", middleware.RequireRole(jwt.RoleAdmin), r.schedulerHandler.TriggerCycle)
+
+			// Retuning the scheduler is an admin action, like the /admin
+			// endpoints below
+			if r.adminToken != "" {
+				scheduler.PATCH("/config", middleware.AdminAuthMiddleware(r.adminToken), r.schedulerHandler.UpdateSchedulerConfig)
+			} else {
+				scheduler.PATCH("/config", middleware.RequireRole(jwt.RoleAdmin), r.schedulerHandler.UpdateSchedulerConfig)
+			}
 		}
 
 		messages := v1.Group("/messages")
 		{
+			messages.GET("", r.messageHandler.ListMessages)
 			messages.GET("/sent", r.messageHandler.GetSentMessages)
 			messages.GET("/stats", r.messageHandler.GetStats)
+			messages.GET("/stats/timeseries", r.messageHandler.GetStatsTimeSeries)
+			messages.GET("/duplicates", r.messageHandler.GetDuplicateContent)
+			messages.GET("/search", r.messageHandler.SearchMessages)
+			messages.GET("/dead-letter", r.messageHandler.ListDeadLetters)
+			messages.POST("/dead-letter/:id/requeue", r.messageHandler.RequeueDeadLetter)
+			messages.GET("/export", r.messageHandler.ExportMessages)
+			messages.GET("/by-webhook-id/:id", r.messageHandler.GetMessageByWebhookID)
 			messages.GET("/:id", r.messageHandler.GetMessage)
+			messages.GET("/:id/wait", r.messageHandler.WaitForMessage)
+			messages.GET("/:id/attempts", r.messageHandler.ListMessageAttempts)
+			messages.POST("/:id/cancel", r.messageHandler.CancelMessage)
+			messages.POST("/:id/pause", r.messageHandler.PauseMessage)
+			messages.POST("/:id/resume", r.messageHandler.ResumeMessage)
+			messages.POST("/:id/retry", r.messageHandler.RetryMessage)
+			messages.POST("/retry-failed", r.messageHandler.RetryFailedMessages)
+			messages.POST("/import", r.messageHandler.ImportMessages)
+			messages.POST("/to-group/:groupId", r.contactHandler.SendToGroup)
 			messages.POST("", r.messageHandler.CreateMessage)
 		}
+
+		campaigns := v1.Group("/campaigns")
+		{
+			campaigns.GET("", r.campaignHandler.ListCampaigns)
+			campaigns.POST("", r.campaignHandler.CreateCampaign)
+			campaigns.GET("/:id", r.campaignHandler.GetCampaign)
+			campaigns.POST("/:id/messages", r.campaignHandler.AddCampaignMessages)
+			campaigns.POST("/:id/pause", r.campaignHandler.PauseCampaign)
+			campaigns.POST("/:id/resume", r.campaignHandler.ResumeCampaign)
+		}
+
+		// Tenant API keys manage entries for their own tenant only
+		blacklist := v1.Group("/blacklist")
+		{
+			blacklist.GET("", r.blacklistHandler.ListBlacklistEntries)
+			blacklist.POST("", r.blacklistHandler.AddBlacklistEntry)
+			blacklist.GET("/:id", r.blacklistHandler.GetBlacklistEntry)
+			blacklist.DELETE("/:id", r.blacklistHandler.RemoveBlacklistEntry)
+		}
+
+		contacts := v1.Group("/contacts")
+		{
+			contacts.GET("", r.contactHandler.ListContacts)
+			contacts.POST("", r.contactHandler.CreateContact)
+			contacts.GET("/:id", r.contactHandler.GetContact)
+			contacts.PATCH("/:id", r.contactHandler.UpdateContact)
+			contacts.DELETE("/:id", r.contactHandler.DeleteContact)
+		}
+
+		groups := v1.Group("/groups")
+		{
+			groups.GET("", r.contactHandler.ListGroups)
+			groups.POST("", r.contactHandler.CreateGroup)
+			groups.GET("/:id", r.contactHandler.GetGroup)
+			groups.PATCH("/:id", r.contactHandler.UpdateGroup)
+			groups.DELETE("/:id", r.contactHandler.DeleteGroup)
+			groups.GET("/:id/members", r.contactHandler.ListGroupMembers)
+			groups.POST("/:id/members", r.contactHandler.AddGroupMembers)
+			groups.DELETE("/:id/members/:contactId", r.contactHandler.RemoveGroupMember)
+		}
+
+		// Tenant API keys only count their own messages
+		analytics := v1.Group("/analytics")
+		{
+			analytics.GET("/delivery", r.analyticsHandler.GetDeliveryAnalytics)
+		}
+
+		v1.POST("/templates/preview", r.messageHandler.PreviewTemplate)
+
+		// Tenant API keys only see entries for their own messages
+		v1.GET("/audit", r.auditHandler.ListAuditLog)
+
+		// Without an admin token the admin endpoints fall back to API_TOKEN
+		admin := v1.Group("/admin", middleware.OperatorOnly(), middleware.RequireRole(jwt.RoleAdmin))
+		if r.adminToken != "" {
+			admin.Use(middleware.AdminAuthMiddleware(r.adminToken))
+		}
+		{
+			admin.GET("/log-level", r.logLevelHandler.GetLogLevel)
+			admin.PUT("/log-level", r.logLevelHandler.SetLogLevel)
+
+			admin.GET("/tenants", r.tenantHandler.ListTenants)
+			admin.POST("/tenants", r.tenantHandler.CreateTenant)
+			admin.GET("/tenants/:id", r.tenantHandler.GetTenant)
+			admin.PATCH("/tenants/:id", r.tenantHandler.UpdateTenant)
+			admin.POST("/tenants/:id/rotate-key", r.tenantHandler.RotateTenantAPIKey)
+
+			admin.GET("/webhook-destinations", r.webhookHandler.ListDestinations)
+			admin.POST("/webhook-destinations", r.webhookHandler.CreateDestination)
+			admin.GET("/webhook-destinations/:id", r.webhookHandler.GetDestination)
+			admin.PATCH("/webhook-destinations/:id", r.webhookHandler.UpdateDestination)
+
+			admin.GET("/provider-error-mappings", r.mappingHandler.ListMappings)
+			admin.POST("/provider-error-mappings", r.mappingHandler.CreateMapping)
+			admin.GET("/provider-error-mappings/:id", r.mappingHandler.GetMapping)
+			admin.DELETE("/provider-error-mappings/:id", r.mappingHandler.DeleteMapping)
+
+			// Failover endpoints only exist when the dispatch lease is enabled
+			if r.failoverHandler != nil {
+				admin.GET("/failover", r.failoverHandler.GetFailoverStatus)
+				admin.POST("/failover", r.failoverHandler.ForceFailover)
+			}
+
+			// Retention endpoints only exist when the retention job is enabled
+			if r.retentionHandler != nil {
+				admin.GET("/retention", r.retentionHandler.GetRetentionStatus)
+				admin.POST("/retention/run", r.retentionHandler.RunRetention)
+			}
+
+			// Archive endpoints only exist when the archive job is enabled
+			if r.archiveHandler != nil {
+				admin.GET("/archives", r.archiveHandler.ListArchives)
+				admin.POST("/archives/run", r.archiveHandler.RunArchive)
+			}
+		}
 	}
 
 	return r.engine
@@ -0,0 +1,213 @@
+package testsupport
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/domain/entity"
+	"github.com/eneskaya/insider-messaging/internal/domain/repository"
+	"github.com/eneskaya/insider-messaging/internal/domain/valueobject"
+	"github.com/google/uuid"
+)
+
+// CharLimit is the content limit fixtures are built and read back with.
+const CharLimit = 160
+
+// MessageBuilder builds a message in any state, as if it had been read
+// back from the database. Every field has a usable default: a pending SMS
+// to +905551234567 created now, with three attempts.
+type MessageBuilder struct {
+	t           testing.TB
+	phone       string
+	content     string
+	status      valueobject.MessageStatus
+	priority    valueobject.MessagePriority
+	createdAt   time.Time
+	scheduledAt *time.Time
+	attempts    int
+	maxAttempts int
+	errorCode   string
+	tenantID    uuid.UUID
+	createdBy   string
+}
+
+func NewMessage(t testing.TB) *MessageBuilder {
+	return &MessageBuilder{
+		t:           t,
+		phone:       "+905551234567",
+		content:     "Test message",
+		status:      valueobject.MessageStatusPending,
+		priority:    valueobject.MessagePriorityNormal,
+		createdAt:   time.Now().UTC(),
+		maxAttempts: 3,
+	}
+}
+
+func (b *MessageBuilder) To(phone string) *MessageBuilder {
+	b.phone = phone
+	return b
+}
+
+func (b *MessageBuilder) Content(content string) *MessageBuilder {
+	b.content = content
+	return b
+}
+
+// Status sets the status; sent and delivered messages get a sent time and
+// failed ones an error code.
+func (b *MessageBuilder) Status(status valueobject.MessageStatus) *MessageBuilder {
+	b.status = status
+	return b
+}
+
+func (b *MessageBuilder) Priority(priority valueobject.MessagePriority) *MessageBuilder {
+	b.priority = priority
+	return b
+}
+
+func (b *MessageBuilder) CreatedAt(createdAt time.Time) *MessageBuilder {
+	b.createdAt = createdAt.UTC()
+	return b
+}
+
+func (b *MessageBuilder) ScheduledAt(scheduledAt time.Time) *MessageBuilder {
+	at := scheduledAt.UTC()
+	b.scheduledAt = &at
+	return b
+}
+
+func (b *MessageBuilder) Attempts(attempts, maxAttempts int) *MessageBuilder {
+	b.attempts = attempts
+	b.maxAttempts = maxAttempts
+	return b
+}
+
+func (b *MessageBuilder) ErrorCode(code string) *MessageBuilder {
+	b.errorCode = code
+	return b
+}
+
+func (b *MessageBuilder) Tenant(tenantID uuid.UUID) *MessageBuilder {
+	b.tenantID = tenantID
+	return b
+}
+
+// CreatedBy sets the caller recorded as having created the message.
+func (b *MessageBuilder) CreatedBy(caller string) *MessageBuilder {
+	b.createdBy = caller
+	return b
+}
+
+func (b *MessageBuilder) Build() *entity.Message {
+	b.t.Helper()
+
+	phone, err := valueobject.NewPhoneNumber(b.phone)
+	if err != nil {
+		b.t.Fatalf("invalid fixture phone number %q: %v", b.phone, err)
+	}
+	content, err := valueobject.NewMessageContent(b.content, CharLimit)
+	if err != nil {
+		b.t.Fatalf("invalid fixture content: %v", err)
+	}
+
+	var sentAt, deliveredAt *time.Time
+	var webhookMessageID string
+	switch b.status {
+	case valueobject.MessageStatusSent, valueobject.MessageStatusDelivered, valueobject.MessageStatusUndelivered:
+		at := b.createdAt.Add(time.Second)
+		sentAt = &at
+		webhookMessageID = "webhook-" + uuid.NewString()
+		if b.status == valueobject.MessageStatusDelivered {
+			deliveredAt = &at
+		}
+	}
+	errorCode := b.errorCode
+	if errorCode == "" && b.status == valueobject.MessageStatusFailed {
+		errorCode = "SERVER_ERROR"
+	}
+
+	return entity.ReconstructMessage(
+		uuid.New(),
+		valueobject.NewPhoneRecipient(phone),
+		content,
+		content.Hash(),
+		b.status,
+		b.createdAt,
+		sentAt,
+		deliveredAt,
+		b.scheduledAt,
+		nil,
+		nil,
+		b.attempts,
+		b.maxAttempts,
+		"",
+		errorCode,
+		webhookMessageID,
+		"",
+		"",
+		"",
+		"",
+		b.priority,
+		b.tenantID,
+		nil,
+		uuid.Nil,
+		uuid.Nil,
+		nil,
+		nil,
+		b.createdBy,
+		false,
+		false,
+		"",
+		1,
+	)
+}
+
+// Create builds the message and stores it with repo.
+func (b *MessageBuilder) Create(ctx context.Context, repo repository.MessageRepository) *entity.Message {
+	b.t.Helper()
+
+	message := b.Build()
+	if err := repo.Create(ctx, message); err != nil {
+		b.t.Fatalf("failed to create fixture message: %v", err)
+	}
+	return message
+}
+
+// CreateMany stores n messages built alike, each with a distinct number.
+func (b *MessageBuilder) CreateMany(ctx context.Context, repo repository.MessageRepository, n int) []*entity.Message {
+	b.t.Helper()
+
+	messages := make([]*entity.Message, n)
+	for i := range messages {
+		phone := b.phone
+		b.phone = fmt.Sprintf("%s%04d", phone[:len(phone)-4], i)
+		messages[i] = b.Create(ctx, repo)
+		b.phone = phone
+	}
+	return messages
+}
+
+// CreateTenant stores an active tenant with a unique name and API key hash.
+func CreateTenant(ctx context.Context, t testing.TB, repo repository.TenantRepository) *repository.Tenant {
+	t.Helper()
+
+	now := time.Now().UTC()
+	name := "tenant-" + randomSuffix(t)
+	hash := sha256.Sum256([]byte(name))
+	tenant := &repository.Tenant{
+		ID:         uuid.New(),
+		Name:       name,
+		APIKeyHash: hex.EncodeToString(hash[:]),
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := repo.Create(ctx, tenant); err != nil {
+		t.Fatalf("failed to create fixture tenant: %v", err)
+	}
+	return tenant
+}
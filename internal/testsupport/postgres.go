@@ -0,0 +1,114 @@
+// Package testsupport runs integration tests against real PostgreSQL and
+// Redis servers, such as the ones docker-compose starts, and builds the
+// fixtures they work on. The servers are read from TEST_DB_* and
+// TEST_REDIS_* variables, which default to the docker-compose ports. Tests
+// using it are built with the integration tag:
+//
+//	docker-compose up -d postgres redis
+//	go test -tags integration ./...
+package testsupport
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/persistence"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// PostgresConfig is the server under TEST_DB_HOST, TEST_DB_PORT,
+// TEST_DB_USER, TEST_DB_PASSWORD and TEST_DB_NAME, the database test
+// databases are created from.
+func PostgresConfig() config.DatabaseConfig {
+	return config.DatabaseConfig{
+		Driver:          config.DBDriverPostgres,
+		Host:            getEnv("TEST_DB_HOST", "localhost"),
+		Port:            getEnv("TEST_DB_PORT", "5433"),
+		User:            getEnv("TEST_DB_USER", "messaging_user"),
+		Password:        getEnv("TEST_DB_PASSWORD", "secure_password_123"),
+		Name:            getEnv("TEST_DB_NAME", "messaging_db"),
+		SSLMode:         getEnv("TEST_DB_SSLMODE", "disable"),
+		MaxOpenConns:    10,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: time.Minute,
+	}
+}
+
+// Postgres creates an empty database for the test, applies every migration
+// and drops it again when the test ends, so tests can run in parallel
+// without seeing each other's rows.
+func Postgres(t testing.TB) *persistence.GormDB {
+	t.Helper()
+
+	admin := PostgresConfig()
+	adminDB, err := sql.Open("postgres", admin.DSN())
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", admin.Name, err)
+	}
+	t.Cleanup(func() { adminDB.Close() })
+
+	cfg := admin
+	cfg.Name = "test_" + randomSuffix(t)
+	if _, err := adminDB.Exec("CREATE DATABASE " + cfg.Name); err != nil {
+		t.Fatalf("failed to create test database on %s:%s: %v", admin.Host, admin.Port, err)
+	}
+	t.Cleanup(func() {
+		if _, err := adminDB.Exec("DROP DATABASE IF EXISTS " + cfg.Name + " WITH (FORCE)"); err != nil {
+			t.Errorf("failed to drop test database %s: %v", cfg.Name, err)
+		}
+	})
+
+	m, err := persistence.NewMigrate(&cfg, "")
+	if err != nil {
+		t.Fatalf("failed to prepare migrations: %v", err)
+	}
+	err = m.Up()
+	m.Close()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	db, err := persistence.NewPostgresGormDB(&cfg)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	// Registered last, so it runs before the database is dropped
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// SQLDB returns the connection pool under db, for the repositories built
+// on database/sql.
+func SQLDB(t testing.TB, db *persistence.GormDB) *sql.DB {
+	t.Helper()
+
+	sqlDB, err := db.DB().DB()
+	if err != nil {
+		t.Fatalf("failed to get connection pool: %v", err)
+	}
+	return sqlDB
+}
+
+func randomSuffix(t testing.TB) string {
+	t.Helper()
+
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate database name: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,60 @@
+package testsupport
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/infrastructure/cache"
+	"github.com/eneskaya/insider-messaging/pkg/config"
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisConfig is the server under TEST_REDIS_HOST, TEST_REDIS_PORT,
+// TEST_REDIS_PASSWORD and TEST_REDIS_DB. The database defaults to 15 so a
+// development instance sharing the server keeps its keys.
+func RedisConfig(t testing.TB) config.RedisConfig {
+	t.Helper()
+
+	db, err := strconv.Atoi(getEnv("TEST_REDIS_DB", "15"))
+	if err != nil {
+		t.Fatalf("TEST_REDIS_DB must be a number: %v", err)
+	}
+	return config.RedisConfig{
+		Enabled:  true,
+		Host:     getEnv("TEST_REDIS_HOST", "localhost"),
+		Port:     getEnv("TEST_REDIS_PORT", "6380"),
+		Password: getEnv("TEST_REDIS_PASSWORD", ""),
+		DB:       db,
+		CacheTTL: time.Minute,
+	}
+}
+
+// Redis empties the test database and connects to it. Tests sharing the
+// database must not run in parallel.
+func Redis(t testing.TB) *cache.RedisCache {
+	t.Helper()
+
+	cfg := RedisConfig(t)
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address(),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("failed to empty Redis database %d on %s: %v", cfg.DB, cfg.Address(), err)
+	}
+
+	redisCache, err := cache.NewRedisCache(&cfg)
+	if err != nil {
+		t.Fatalf("failed to connect to Redis: %v", err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+
+	return redisCache
+}
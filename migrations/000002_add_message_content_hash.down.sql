@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_phone_content_hash;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,9 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+UPDATE messages SET content_hash = encode(sha256(convert_to(content, 'UTF8')), 'hex') WHERE content_hash IS NULL;
+
+ALTER TABLE messages ALTER COLUMN content_hash SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_phone_content_hash ON messages(phone_number, content_hash);
+
+COMMENT ON COLUMN messages.content_hash IS 'Hex-encoded SHA-256 of content, used for integrity checks and deduplication';
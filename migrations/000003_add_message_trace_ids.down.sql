@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_messages_trace_id;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS provider_request_id;
+ALTER TABLE messages DROP COLUMN IF EXISTS trace_id;
@@ -0,0 +1,7 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS trace_id VARCHAR(64);
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS provider_request_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_messages_trace_id ON messages(trace_id) WHERE trace_id IS NOT NULL;
+
+COMMENT ON COLUMN messages.trace_id IS 'Request/trace ID forwarded to the provider on the last send attempt';
+COMMENT ON COLUMN messages.provider_request_id IS 'Request ID reported by the provider in its response headers';
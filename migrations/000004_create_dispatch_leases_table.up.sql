@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS dispatch_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder_id VARCHAR(255) NOT NULL,
+    holder_region VARCHAR(100) NOT NULL,
+    preferred_region VARCHAR(100),
+    epoch BIGINT NOT NULL DEFAULT 1,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    renewed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+COMMENT ON TABLE dispatch_leases IS 'Heartbeat leases deciding which deployment/region may dispatch messages';
+COMMENT ON COLUMN dispatch_leases.epoch IS 'Incremented on every change of holder, usable as a fencing token';
+COMMENT ON COLUMN dispatch_leases.preferred_region IS 'Set by an operator to force failover; only this region may take or renew the lease';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_scheduled_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS scheduled_at;
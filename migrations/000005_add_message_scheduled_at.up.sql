@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_scheduled_at ON messages(scheduled_at) WHERE status = 'pending' AND scheduled_at IS NOT NULL;
+
+COMMENT ON COLUMN messages.scheduled_at IS 'Earliest time the scheduler may send the message; NULL sends as soon as possible';
@@ -0,0 +1,6 @@
+UPDATE messages SET status = 'failed', last_error = 'cancelled' WHERE status = 'cancelled';
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed';
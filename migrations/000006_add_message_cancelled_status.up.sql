@@ -0,0 +1,4 @@
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled';
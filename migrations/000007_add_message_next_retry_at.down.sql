@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_next_retry_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS next_retry_at;
@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_next_retry_at ON messages(next_retry_at) WHERE status = 'pending' AND next_retry_at IS NOT NULL;
+
+COMMENT ON COLUMN messages.next_retry_at IS 'Earliest time a failed attempt may be retried; NULL retries on the next cycle';
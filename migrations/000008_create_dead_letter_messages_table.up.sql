@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS dead_letter_messages (
+    message_id UUID PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    error_code VARCHAR(50),
+    trace_id VARCHAR(64),
+    provider_request_id VARCHAR(255),
+    message_created_at TIMESTAMP NOT NULL,
+    dead_lettered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_dead_lettered_at ON dead_letter_messages(dead_lettered_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_error_code ON dead_letter_messages(error_code);
+
+COMMENT ON TABLE dead_letter_messages IS 'Snapshot of messages that exhausted max_attempts, kept for inspection and replay';
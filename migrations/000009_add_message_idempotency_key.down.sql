@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_idempotency_key;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_idempotency_key ON messages(idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+COMMENT ON COLUMN messages.idempotency_key IS 'Client supplied Idempotency-Key / client_reference; repeated creates return the original message';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_messages_tenant_idempotency_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_idempotency_key ON messages(idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_messages_tenant_created_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
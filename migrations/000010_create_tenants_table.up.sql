@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_name ON tenants(name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_api_key_hash ON tenants(api_key_hash);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_created_at ON messages(tenant_id, created_at) WHERE tenant_id IS NOT NULL;
+
+-- Idempotency keys only need to be unique within a tenant; messages created
+-- with the global API token share the nil tenant
+DROP INDEX IF EXISTS idx_messages_idempotency_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_tenant_idempotency_key
+    ON messages(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'::uuid), idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+
+COMMENT ON TABLE tenants IS 'API clients whose messages are isolated from each other; only the SHA-256 of the API key is stored';
+COMMENT ON COLUMN messages.tenant_id IS 'Owning tenant; NULL for messages created with the global API token';
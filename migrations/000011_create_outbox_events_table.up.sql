@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    seq BIGSERIAL NOT NULL,
+    aggregate_id UUID NOT NULL,
+    tenant_id UUID,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE outbox_events IS 'Message lifecycle events written with the state change and drained to the broker by the outbox relay';
+COMMENT ON COLUMN outbox_events.seq IS 'Insertion order; events are published in seq order';
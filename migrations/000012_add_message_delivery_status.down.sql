@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_messages_webhook_message_id;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS delivered_at;
+
+UPDATE messages SET status = 'sent' WHERE status IN ('delivered', 'undelivered');
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled';
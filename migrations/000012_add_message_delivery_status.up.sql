@@ -0,0 +1,9 @@
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered'));
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_webhook_message_id ON messages(webhook_message_id) WHERE webhook_message_id IS NOT NULL AND webhook_message_id <> '';
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered';
+COMMENT ON COLUMN messages.delivered_at IS 'Delivery time reported by the provider''s delivery receipt';
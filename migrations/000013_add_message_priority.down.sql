@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_messages_pending_priority;
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_priority;
+ALTER TABLE messages DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,8 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 1;
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_priority;
+ALTER TABLE messages ADD CONSTRAINT chk_priority CHECK (priority BETWEEN 0 AND 2);
+
+CREATE INDEX IF NOT EXISTS idx_messages_pending_priority ON messages(priority DESC, created_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN messages.priority IS 'Dispatch priority: 2 high, 1 normal, 0 low; pending messages are claimed by priority, then age';
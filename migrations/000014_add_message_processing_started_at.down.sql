@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_processing_started_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS processing_started_at;
@@ -0,0 +1,8 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
+
+-- Claims made before this column existed count from the migration onwards
+UPDATE messages SET processing_started_at = NOW() WHERE status = 'processing' AND processing_started_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_processing_started_at ON messages(processing_started_at) WHERE status = 'processing';
+
+COMMENT ON COLUMN messages.processing_started_at IS 'When the message was last claimed for sending; used to reap claims left behind by crashed workers';
@@ -0,0 +1,7 @@
+-- Quarantined messages were never going to be sent without a release
+UPDATE messages SET status = 'cancelled' WHERE status = 'quarantined';
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered';
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255),
+    tenant_id UUID,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    from_status VARCHAR(20),
+    to_status VARCHAR(20),
+    details TEXT,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request_id ON audit_log(request_id) WHERE request_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_occurred_at ON audit_log(tenant_id, occurred_at) WHERE tenant_id IS NOT NULL;
+
+COMMENT ON TABLE audit_log IS 'Append-only record of message and scheduler writes: who made them, on which request, and the status change';
+COMMENT ON COLUMN audit_log.actor IS 'api_token, admin_token, tenant:<id>, anonymous, system, kafka or provider';
+COMMENT ON COLUMN audit_log.tenant_id IS 'Tenant owning the resource; tenant API keys only see their own entries';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_metadata;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS metadata;
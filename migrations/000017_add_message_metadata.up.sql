@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_messages_metadata ON messages USING GIN (metadata jsonb_path_ops) WHERE metadata IS NOT NULL;
+
+COMMENT ON COLUMN messages.metadata IS 'Client key/value data set at creation and forwarded to the webhook';
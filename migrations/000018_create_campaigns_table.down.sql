@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_campaign_id;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS campaign_id;
+
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    tenant_id UUID REFERENCES tenants(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_tenant_created_at ON campaigns(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_campaigns_paused ON campaigns(id) WHERE status = 'paused';
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS campaign_id UUID REFERENCES campaigns(id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_campaign_id ON messages(campaign_id) WHERE campaign_id IS NOT NULL;
+
+COMMENT ON TABLE campaigns IS 'Named batches of messages that are tracked and paused together';
+COMMENT ON COLUMN messages.campaign_id IS 'Campaign the message was created under; NULL for standalone messages';
@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+COMMENT ON COLUMN messages.deleted_at IS 'When the retention job soft-deleted the message; deleted messages are hidden from every read';
@@ -0,0 +1,7 @@
+-- Paused messages were held back by an operator; cancel them rather than send
+UPDATE messages SET status = 'cancelled' WHERE status = 'paused';
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined';
@@ -0,0 +1,9 @@
+-- Blocked messages were never going to be sent to an opted-out recipient
+UPDATE messages SET status = 'cancelled' WHERE status = 'blocked';
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined', 'paused'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined, paused';
+
+DROP TABLE IF EXISTS blacklist;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS blacklist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    phone_number VARCHAR(20) NOT NULL,
+    tenant_id UUID REFERENCES tenants(id),
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A number is listed at most once per tenant; entries created with the
+-- global API token share the nil tenant and apply to every tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blacklist_tenant_phone_number
+    ON blacklist(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), phone_number);
+CREATE INDEX IF NOT EXISTS idx_blacklist_phone_number ON blacklist(phone_number);
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined', 'paused', 'blocked'));
+
+COMMENT ON TABLE blacklist IS 'Phone numbers that opted out; messages to them are stored as blocked and never sent';
+COMMENT ON COLUMN blacklist.tenant_id IS 'Tenant the opt-out applies to; NULL applies to every tenant';
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined, paused, blocked';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS scheduler_runs (
+    id UUID PRIMARY KEY,
+    instance VARCHAR(255) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    successful INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    errors INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    skip_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduler_runs_started_at ON scheduler_runs(started_at);
+
+COMMENT ON TABLE scheduler_runs IS 'One row per scheduler cycle, pruned after SCHEDULER_RUN_RETENTION';
+COMMENT ON COLUMN scheduler_runs.errors IS 'Batches that could not be processed at all, e.g. because claiming them failed';
+COMMENT ON COLUMN scheduler_runs.skip_reason IS 'retry_budget or circuit_open when the cycle sent nothing';
@@ -0,0 +1,3 @@
+-- Backs GET /api/v1/messages/search. The expression must match the one the
+-- repositories query with, or the index is not used.
+CREATE INDEX IF NOT EXISTS idx_messages_content_fts ON messages USING GIN (to_tsvector('simple', content));
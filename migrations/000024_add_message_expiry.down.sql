@@ -0,0 +1,9 @@
+-- Expired messages were never sent and must not be sent now
+UPDATE messages SET status = 'cancelled' WHERE status = 'expired';
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_status;
+ALTER TABLE messages ADD CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined', 'paused', 'blocked'));
+
+COMMENT ON COLUMN messages.status IS 'Message status: pending, processing, sent, failed, cancelled, delivered, undelivered, quarantined, paused, blocked';
+
+ALTER TABLE messages DROP COLUMN IF EXISTS expires_at;
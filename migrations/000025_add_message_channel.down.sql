@@ -0,0 +1,8 @@
+-- Email and push messages have no phone number to fall back to
+DELETE FROM messages WHERE channel <> 'sms';
+
+DROP INDEX IF EXISTS idx_messages_recipient;
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_channel;
+ALTER TABLE messages DROP COLUMN IF EXISTS recipient;
+ALTER TABLE messages DROP COLUMN IF EXISTS channel;
@@ -0,0 +1,10 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel VARCHAR(10) NOT NULL DEFAULT 'sms';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS recipient TEXT;
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS chk_channel;
+ALTER TABLE messages ADD CONSTRAINT chk_channel CHECK (channel IN ('sms', 'email', 'push'));
+
+CREATE INDEX IF NOT EXISTS idx_messages_recipient ON messages(recipient) WHERE recipient IS NOT NULL;
+
+COMMENT ON COLUMN messages.channel IS 'Delivery channel: sms, email or push';
+COMMENT ON COLUMN messages.recipient IS 'Email address or device token of email and push messages; SMS keep their number in phone_number';
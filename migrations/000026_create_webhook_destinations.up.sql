@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS webhook_destinations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    auth_key TEXT NOT NULL DEFAULT '',
+    rate_limit_per_second INT NOT NULL DEFAULT 0,
+    tenant_id UUID REFERENCES tenants(id),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rate_limit_per_second CHECK (rate_limit_per_second >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_destinations_name ON webhook_destinations(name);
+CREATE INDEX IF NOT EXISTS idx_webhook_destinations_tenant_id ON webhook_destinations(tenant_id) WHERE tenant_id IS NOT NULL;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS destination_id UUID REFERENCES webhook_destinations(id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_destination_id ON messages(destination_id) WHERE destination_id IS NOT NULL;
+
+COMMENT ON TABLE webhook_destinations IS 'Webhooks messages can be routed to instead of the configured provider';
+COMMENT ON COLUMN webhook_destinations.rate_limit_per_second IS 'Outbound requests per second; 0 uses WEBHOOK_RATE_LIMIT_PER_SECOND';
+COMMENT ON COLUMN webhook_destinations.tenant_id IS 'Tenant allowed to use the destination; NULL for destinations open to every caller';
+COMMENT ON COLUMN messages.destination_id IS 'Webhook destination the message is sent to; NULL for the configured provider';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_webhook_response;
+
+ALTER TABLE messages ALTER COLUMN webhook_response TYPE TEXT USING webhook_response::text;
@@ -0,0 +1,17 @@
+-- Responses were stored as hand-built strings that are not valid JSON when
+-- the provider's reply contained quotes; those are kept as {"raw": ...}
+CREATE FUNCTION pg_temp.webhook_response_jsonb(response TEXT) RETURNS JSONB AS $$
+BEGIN
+    RETURN response::jsonb;
+EXCEPTION WHEN others THEN
+    RETURN jsonb_build_object('raw', response);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE messages
+    ALTER COLUMN webhook_response TYPE JSONB
+    USING pg_temp.webhook_response_jsonb(NULLIF(webhook_response, ''));
+
+CREATE INDEX IF NOT EXISTS idx_messages_webhook_response ON messages USING GIN (webhook_response jsonb_path_ops) WHERE webhook_response IS NOT NULL;
+
+COMMENT ON COLUMN messages.webhook_response IS 'Response body of the provider that accepted the message';
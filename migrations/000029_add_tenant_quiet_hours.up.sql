@@ -0,0 +1,3 @@
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS quiet_hours VARCHAR(11) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN tenants.quiet_hours IS 'Quiet hours replacing QUIET_HOURS for the tenant''s messages: HH:MM-HH:MM, off, or empty to use QUIET_HOURS';
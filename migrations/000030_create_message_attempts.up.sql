@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id UUID PRIMARY KEY,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    status_code INT,
+    error_code VARCHAR(50),
+    error TEXT,
+    webhook_response JSONB,
+    provider_request_id VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_attempts_message_id ON message_attempts(message_id, attempt);
+
+COMMENT ON TABLE message_attempts IS 'One row per send attempt of a message, removed along with the message';
+COMMENT ON COLUMN message_attempts.status_code IS 'HTTP status of the provider''s last response, NULL when it did not answer';
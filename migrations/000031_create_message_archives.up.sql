@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS message_archives (
+    id UUID PRIMARY KEY,
+    object_key VARCHAR(1024) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    message_count INT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    first_created_at TIMESTAMP NOT NULL,
+    last_created_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_archives_created_range ON message_archives(first_created_at, last_created_at);
+
+COMMENT ON TABLE message_archives IS 'One row per archive file of messages moved out of the messages table';
+COMMENT ON COLUMN message_archives.first_created_at IS 'Creation time of the oldest message in the file';
+COMMENT ON COLUMN message_archives.last_created_at IS 'Creation time of the newest message in the file';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_split_group_id;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS part_count;
+ALTER TABLE messages DROP COLUMN IF EXISTS part_number;
+ALTER TABLE messages DROP COLUMN IF EXISTS split_group_id;
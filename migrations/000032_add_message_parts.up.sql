@@ -0,0 +1,8 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS split_group_id UUID;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS part_number SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS part_count SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_messages_split_group_id ON messages(split_group_id) WHERE split_group_id IS NOT NULL;
+
+COMMENT ON COLUMN messages.split_group_id IS 'Shared by the parts of a long text split into several messages; NULL for whole messages';
+COMMENT ON COLUMN messages.part_number IS 'Position of the part, from 1 to part_count; 0 for whole messages';
@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS created_by VARCHAR(255);
+
+COMMENT ON COLUMN messages.created_by IS 'Caller that created the message: api_token, admin_token, jwt:<subject>, tenant:<id>, kafka or anonymous';
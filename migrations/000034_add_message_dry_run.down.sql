@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN IF EXISTS simulated;
+ALTER TABLE messages DROP COLUMN IF EXISTS dry_run;
@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS simulated BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN messages.dry_run IS 'Created with dry_run: every send is simulated instead of reaching the provider';
+COMMENT ON COLUMN messages.simulated IS 'The send was simulated, by dry_run or the global dry-run mode';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS provider_error_mappings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(255) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    provider_code VARCHAR(255) NOT NULL DEFAULT '',
+    body_contains TEXT NOT NULL DEFAULT '',
+    error_code VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_provider_error_mappings_status_code CHECK (status_code = 0 OR status_code BETWEEN 100 AND 599)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_error_mappings_match ON provider_error_mappings(provider, status_code, provider_code, body_contains);
+
+COMMENT ON TABLE provider_error_mappings IS 'Error codes stored for provider refusals, editable at runtime';
+COMMENT ON COLUMN provider_error_mappings.provider IS 'Sender name: webhook, twilio, sns, email-webhook, push-webhook or destination-<name>';
+COMMENT ON COLUMN provider_error_mappings.status_code IS 'HTTP status the refusal must have; 0 for any';
+COMMENT ON COLUMN provider_error_mappings.provider_code IS 'Provider error code the refusal must carry; empty for any';
+COMMENT ON COLUMN provider_error_mappings.body_contains IS 'Text the response body must contain, case-insensitively; empty for any';
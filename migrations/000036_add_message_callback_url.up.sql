@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS callback_url TEXT;
+
+COMMENT ON COLUMN messages.callback_url IS 'Where the final status is posted once the message is sent or fails';
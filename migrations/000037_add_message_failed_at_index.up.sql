@@ -0,0 +1,3 @@
+-- A failed message failed when its last attempt started, so the time series
+-- and failure rate look failures up by processing_started_at
+CREATE INDEX IF NOT EXISTS idx_messages_failed_at ON messages(processing_started_at) WHERE status = 'failed';
@@ -0,0 +1,15 @@
+// Package migrations embeds the SQL migrations so the binaries can apply
+// them without the files next to them.
+package migrations
+
+import "embed"
+
+// Postgres holds the PostgreSQL migrations, SQLite their SQLite counterparts
+// under sqlite/.
+var (
+	//go:embed *.sql
+	Postgres embed.FS
+
+	//go:embed sqlite/*.sql
+	SQLite embed.FS
+)
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_outbox_events_seq;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS dead_letter_messages;
+DROP TABLE IF EXISTS dispatch_leases;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS tenants;
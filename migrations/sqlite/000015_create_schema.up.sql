@@ -0,0 +1,115 @@
+-- SQLite counterpart of migrations 000001 to 000015, for local development
+-- and tests. UUIDs are stored as text and COMMENT ON is not supported, so
+-- the column documentation lives in the Postgres migrations.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_name ON tenants(name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_api_key_hash ON tenants(api_key_hash);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id TEXT PRIMARY KEY,
+    phone_number VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    scheduled_at TIMESTAMP,
+    next_retry_at TIMESTAMP,
+    processing_started_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT,
+    error_code VARCHAR(50),
+    webhook_message_id VARCHAR(255),
+    webhook_response TEXT,
+    trace_id VARCHAR(64),
+    provider_request_id VARCHAR(255),
+    idempotency_key VARCHAR(255),
+    priority SMALLINT NOT NULL DEFAULT 1,
+    tenant_id TEXT REFERENCES tenants(id),
+    version BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined')),
+    CONSTRAINT chk_priority CHECK (priority BETWEEN 0 AND 2)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_status ON messages(status);
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at) WHERE sent_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_status_created_at ON messages(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_pending_fifo ON messages(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_messages_phone_content_hash ON messages(phone_number, content_hash);
+CREATE INDEX IF NOT EXISTS idx_messages_trace_id ON messages(trace_id) WHERE trace_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_scheduled_at ON messages(scheduled_at) WHERE status = 'pending' AND scheduled_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_next_retry_at ON messages(next_retry_at) WHERE status = 'pending' AND next_retry_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_created_at ON messages(tenant_id, created_at) WHERE tenant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_webhook_message_id ON messages(webhook_message_id) WHERE webhook_message_id IS NOT NULL AND webhook_message_id <> '';
+CREATE INDEX IF NOT EXISTS idx_messages_pending_priority ON messages(priority DESC, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_messages_processing_started_at ON messages(processing_started_at) WHERE status = 'processing';
+
+-- Idempotency keys only need to be unique within a tenant; messages created
+-- with the global API token share the nil tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_tenant_idempotency_key
+    ON messages(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS dispatch_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder_id VARCHAR(255) NOT NULL,
+    holder_region VARCHAR(100) NOT NULL,
+    preferred_region VARCHAR(100),
+    epoch BIGINT NOT NULL DEFAULT 1,
+    acquired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    renewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dead_letter_messages (
+    message_id TEXT PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    error_code VARCHAR(50),
+    trace_id VARCHAR(64),
+    provider_request_id VARCHAR(255),
+    message_created_at TIMESTAMP NOT NULL,
+    dead_lettered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_dead_lettered_at ON dead_letter_messages(dead_lettered_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_messages_error_code ON dead_letter_messages(error_code);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id TEXT PRIMARY KEY,
+    seq INTEGER,
+    aggregate_id TEXT NOT NULL,
+    tenant_id TEXT,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at) WHERE published_at IS NOT NULL;
+
+-- SQLite has no sequences; the rowid grows with every insert, which is the
+-- order BIGSERIAL gives seq in Postgres
+CREATE TRIGGER IF NOT EXISTS trg_outbox_events_seq
+AFTER INSERT ON outbox_events
+WHEN NEW.seq IS NULL
+BEGIN
+    UPDATE outbox_events SET seq = NEW.rowid WHERE rowid = NEW.rowid;
+END;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    tenant_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_tenant_created_at ON campaigns(tenant_id, created_at);
+
+ALTER TABLE messages ADD COLUMN campaign_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_messages_campaign_id ON messages(campaign_id) WHERE campaign_id IS NOT NULL;
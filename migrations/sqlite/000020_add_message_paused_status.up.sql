@@ -0,0 +1,67 @@
+-- SQLite cannot change a CHECK constraint in place, so the messages table is
+-- rebuilt. Dropping the old table cascades to dead_letter_messages, whose
+-- rows are copied aside and restored afterwards.
+
+CREATE TABLE dead_letter_messages_backup AS SELECT * FROM dead_letter_messages;
+
+CREATE TABLE messages_new (
+    id TEXT PRIMARY KEY,
+    phone_number VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    scheduled_at TIMESTAMP,
+    next_retry_at TIMESTAMP,
+    processing_started_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT,
+    error_code VARCHAR(50),
+    webhook_message_id VARCHAR(255),
+    webhook_response TEXT,
+    trace_id VARCHAR(64),
+    provider_request_id VARCHAR(255),
+    idempotency_key VARCHAR(255),
+    priority SMALLINT NOT NULL DEFAULT 1,
+    tenant_id TEXT REFERENCES tenants(id),
+    version BIGINT NOT NULL DEFAULT 0,
+    metadata TEXT,
+    campaign_id TEXT,
+    deleted_at TIMESTAMP,
+    CONSTRAINT chk_status CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'cancelled', 'delivered', 'undelivered', 'quarantined', 'paused')),
+    CONSTRAINT chk_priority CHECK (priority BETWEEN 0 AND 2)
+);
+
+INSERT INTO messages_new SELECT
+    id, phone_number, content, content_hash, status, created_at, sent_at, delivered_at, scheduled_at,
+    next_retry_at, processing_started_at, attempts, max_attempts, last_error, error_code,
+    webhook_message_id, webhook_response, trace_id, provider_request_id, idempotency_key, priority,
+    tenant_id, version, metadata, campaign_id, deleted_at
+FROM messages;
+
+DROP TABLE messages;
+ALTER TABLE messages_new RENAME TO messages;
+
+CREATE INDEX IF NOT EXISTS idx_messages_status ON messages(status);
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at) WHERE sent_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_status_created_at ON messages(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_pending_fifo ON messages(created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_messages_phone_content_hash ON messages(phone_number, content_hash);
+CREATE INDEX IF NOT EXISTS idx_messages_trace_id ON messages(trace_id) WHERE trace_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_scheduled_at ON messages(scheduled_at) WHERE status = 'pending' AND scheduled_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_next_retry_at ON messages(next_retry_at) WHERE status = 'pending' AND next_retry_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_tenant_created_at ON messages(tenant_id, created_at) WHERE tenant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_webhook_message_id ON messages(webhook_message_id) WHERE webhook_message_id IS NOT NULL AND webhook_message_id <> '';
+CREATE INDEX IF NOT EXISTS idx_messages_pending_priority ON messages(priority DESC, created_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_messages_processing_started_at ON messages(processing_started_at) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_messages_campaign_id ON messages(campaign_id) WHERE campaign_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_tenant_idempotency_key
+    ON messages(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+
+INSERT INTO dead_letter_messages SELECT * FROM dead_letter_messages_backup;
+DROP TABLE dead_letter_messages_backup;
@@ -0,0 +1,2 @@
+-- SQLite has no full-text index to match PostgreSQL's; content search scans
+-- messages with LIKE instead. Kept so the versions stay aligned.
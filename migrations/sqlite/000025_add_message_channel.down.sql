@@ -0,0 +1,7 @@
+-- Email and push messages have no phone number to fall back to
+DELETE FROM messages WHERE channel <> 'sms';
+
+DROP INDEX IF EXISTS idx_messages_recipient;
+
+ALTER TABLE messages DROP COLUMN recipient;
+ALTER TABLE messages DROP COLUMN channel;
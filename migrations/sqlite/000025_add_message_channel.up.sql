@@ -0,0 +1,4 @@
+ALTER TABLE messages ADD COLUMN channel VARCHAR(10) NOT NULL DEFAULT 'sms' CHECK (channel IN ('sms', 'email', 'push'));
+ALTER TABLE messages ADD COLUMN recipient TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_messages_recipient ON messages(recipient) WHERE recipient IS NOT NULL;
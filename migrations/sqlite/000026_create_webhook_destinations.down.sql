@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_destination_id;
+
+ALTER TABLE messages DROP COLUMN destination_id;
+
+DROP TABLE IF EXISTS webhook_destinations;
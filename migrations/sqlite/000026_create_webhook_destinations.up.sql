@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS webhook_destinations (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    auth_key TEXT NOT NULL DEFAULT '',
+    rate_limit_per_second INTEGER NOT NULL DEFAULT 0 CHECK (rate_limit_per_second >= 0),
+    tenant_id TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_destinations_name ON webhook_destinations(name);
+CREATE INDEX IF NOT EXISTS idx_webhook_destinations_tenant_id ON webhook_destinations(tenant_id) WHERE tenant_id IS NOT NULL;
+
+ALTER TABLE messages ADD COLUMN destination_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_messages_destination_id ON messages(destination_id) WHERE destination_id IS NOT NULL;
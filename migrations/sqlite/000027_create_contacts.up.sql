@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS contacts (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    phone_number VARCHAR(20),
+    email VARCHAR(254),
+    device_token TEXT,
+    tenant_id TEXT REFERENCES tenants(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_contact_address CHECK (phone_number IS NOT NULL OR email IS NOT NULL OR device_token IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_tenant_phone_number
+    ON contacts(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), phone_number)
+    WHERE phone_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contacts_tenant_created_at ON contacts(tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS contact_groups (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    tenant_id TEXT REFERENCES tenants(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contact_groups_tenant_name
+    ON contact_groups(COALESCE(tenant_id, '00000000-0000-0000-0000-000000000000'), name);
+
+CREATE TABLE IF NOT EXISTS contact_group_members (
+    group_id TEXT NOT NULL REFERENCES contact_groups(id) ON DELETE CASCADE,
+    contact_id TEXT NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_group_members_contact_id ON contact_group_members(contact_id);
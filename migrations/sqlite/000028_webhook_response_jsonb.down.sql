@@ -0,0 +1,2 @@
+-- The wrapped responses stay valid JSON, which TEXT holds as well; kept so
+-- the versions stay aligned.
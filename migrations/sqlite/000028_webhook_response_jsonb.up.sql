@@ -0,0 +1,5 @@
+-- SQLite keeps JSON as TEXT; responses that are not valid JSON are wrapped
+-- as {"raw": ...} so the json functions can read every row
+UPDATE messages SET webhook_response = NULL WHERE webhook_response = '';
+UPDATE messages SET webhook_response = json_object('raw', webhook_response)
+WHERE webhook_response IS NOT NULL AND NOT json_valid(webhook_response);
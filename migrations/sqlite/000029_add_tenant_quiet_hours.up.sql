@@ -0,0 +1 @@
+ALTER TABLE tenants ADD COLUMN quiet_hours VARCHAR(11) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS message_attempts (
+    id TEXT PRIMARY KEY,
+    message_id TEXT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    status_code INTEGER,
+    error_code VARCHAR(50),
+    error TEXT,
+    webhook_response TEXT,
+    provider_request_id VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_attempts_message_id ON message_attempts(message_id, attempt);
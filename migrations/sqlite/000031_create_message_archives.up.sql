@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS message_archives (
+    id TEXT PRIMARY KEY,
+    object_key VARCHAR(1024) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    message_count INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    first_created_at TIMESTAMP NOT NULL,
+    last_created_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_archives_created_range ON message_archives(first_created_at, last_created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_split_group_id;
+
+ALTER TABLE messages DROP COLUMN part_count;
+ALTER TABLE messages DROP COLUMN part_number;
+ALTER TABLE messages DROP COLUMN split_group_id;
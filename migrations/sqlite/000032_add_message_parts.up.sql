@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN split_group_id TEXT;
+ALTER TABLE messages ADD COLUMN part_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN part_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_messages_split_group_id ON messages(split_group_id) WHERE split_group_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE messages ADD COLUMN created_by TEXT;
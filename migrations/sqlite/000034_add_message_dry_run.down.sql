@@ -0,0 +1,2 @@
+ALTER TABLE messages DROP COLUMN simulated;
+ALTER TABLE messages DROP COLUMN dry_run;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE messages ADD COLUMN simulated BOOLEAN NOT NULL DEFAULT FALSE;
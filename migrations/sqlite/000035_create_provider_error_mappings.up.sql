@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS provider_error_mappings (
+    id TEXT PRIMARY KEY,
+    provider VARCHAR(255) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0 CHECK (status_code = 0 OR status_code BETWEEN 100 AND 599),
+    provider_code VARCHAR(255) NOT NULL DEFAULT '',
+    body_contains TEXT NOT NULL DEFAULT '',
+    error_code VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_provider_error_mappings_match ON provider_error_mappings(provider, status_code, provider_code, body_contains);
@@ -0,0 +1 @@
+ALTER TABLE messages ADD COLUMN callback_url TEXT;
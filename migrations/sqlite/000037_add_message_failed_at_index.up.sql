@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_messages_failed_at ON messages(processing_started_at) WHERE status = 'failed';
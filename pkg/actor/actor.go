@@ -0,0 +1,43 @@
+package actor
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Actors recorded for writes that were not made with a tenant API key
+const (
+	APIToken   = "api_token"
+	AdminToken = "admin_token"
+	// Anonymous is an API call made while authentication is disabled
+	Anonymous = "anonymous"
+	// System is the scheduler, the stale claim reaper and the retention job
+	System   = "system"
+	Kafka    = "kafka"
+	Provider = "provider"
+)
+
+type contextKey struct{}
+
+// WithActor records who is making the calls on ctx, for the audit log.
+func WithActor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextKey{}).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// Tenant is the actor name of a tenant API key.
+func Tenant(id uuid.UUID) string {
+	return "tenant:" + id.String()
+}
+
+// Token is the actor name of a JWT, after its subject.
+func Token(subject string) string {
+	return "jwt:" + subject
+}
@@ -0,0 +1,221 @@
+// Package client is a Go client for the messaging REST API, for services
+// that create messages or control the scheduler without writing the HTTP
+// calls themselves. Its types mirror the API's JSON as documented in the
+// Swagger spec under /swagger.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+)
+
+// Config configures a Client. Token is sent as a bearer token: the
+// API_TOKEN, a tenant API key or a JWT. Only BaseURL is required.
+type Config struct {
+	BaseURL string
+	Token   string
+	// Timeout bounds each attempt; long polls get their wait on top
+	Timeout time.Duration
+	// MaxRetries is how often a request is repeated after a network error,
+	// 429 or 502-504; RetryBackoff is the first wait, doubled per retry
+	// unless the response names a Retry-After
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// HTTPClient replaces http.DefaultClient, e.g. for a custom transport
+	HTTPClient *http.Client
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL      *url.URL
+	token        string
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	httpClient   *http.Client
+}
+
+// New returns a client for the API at cfg.BaseURL. A zero Timeout is 30s,
+// a zero RetryBackoff 200ms and a negative MaxRetries disables retries.
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil || baseURL.Host == "" || (baseURL.Scheme != "http" && baseURL.Scheme != "https") {
+		return nil, fmt.Errorf("base URL must be an absolute http or https URL, got %q", cfg.BaseURL)
+	}
+
+	c := &Client{
+		baseURL:      baseURL,
+		token:        cfg.Token,
+		timeout:      cfg.Timeout,
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		httpClient:   cfg.HTTPClient,
+	}
+	if c.timeout <= 0 {
+		c.timeout = 30 * time.Second
+	}
+	if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.retryBackoff <= 0 {
+		c.retryBackoff = 200 * time.Millisecond
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	return c, nil
+}
+
+// APIError is a response outside 2xx. Code is the API's error code, such
+// as NOT_FOUND or VALIDATION_ERROR, when it sent one.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Fields lists the request fields a 400 rejected, when it names any
+	Fields []FieldError
+}
+
+// FieldError is one request field the API rejected and why.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("messaging API returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("messaging API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// request is one API call; wait extends the attempt timeout for long polls.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   interface{}
+	wait   time.Duration
+}
+
+// do sends req, retrying as configured, and decodes a 2xx body into out
+// when out is not nil.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	target := *c.baseURL
+	target.Path += req.path
+	target.RawQuery = req.query.Encode()
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.attempt(ctx, req, target.String(), body, out)
+		if err == nil || attempt >= c.maxRetries || retryAfter < 0 {
+			return err
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends req once. It returns a negative retryAfter when the error
+// is final, 0 to retry after the backoff and a positive one to retry after
+// that long.
+func (c *Client) attempt(ctx context.Context, req request, target string, body []byte, out interface{}) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout+req.wait)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to build request: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		httpReq.Header.Set(requestid.Header, id)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil && ctx.Err() != context.DeadlineExceeded {
+			return -1, err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out == nil {
+			return 0, nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return -1, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return 0, nil
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+	var errorBody struct {
+		Error  string       `json:"error"`
+		Code   string       `json:"code"`
+		Fields []FieldError `json:"fields"`
+	}
+	if json.Unmarshal(respBody, &errorBody) == nil && errorBody.Error != "" {
+		apiErr.Message, apiErr.Code, apiErr.Fields = errorBody.Error, errorBody.Code, errorBody.Fields
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return retryAfter(resp.Header.Get("Retry-After")), apiErr
+	default:
+		return -1, apiErr
+	}
+}
+
+// retryAfter reads a Retry-After header in seconds, or returns 0 without one.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
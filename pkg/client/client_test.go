@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/internal/application/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(Config{BaseURL: server.URL, Token: "token", MaxRetries: 2, RetryBackoff: time.Millisecond})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return c
+}
+
+func TestClient_CreateMessage(t *testing.T) {
+	// Arrange
+	var gotAuth, gotKey string
+	var gotBody CreateMessageRequest
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("Idempotency-Key")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"42","status":"pending","phone_number":"+905551234567"}`))
+	})
+
+	// Act
+	message, err := c.CreateMessage(context.Background(), CreateMessageRequest{PhoneNumber: "+905551234567", Content: "hi"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "42", message.ID)
+	assert.Equal(t, "pending", message.Status)
+	assert.Equal(t, "Bearer token", gotAuth)
+	assert.NotEmpty(t, gotKey)
+	assert.Equal(t, gotKey, gotBody.ClientReference)
+}
+
+func TestClient_RetriesUnavailable(t *testing.T) {
+	// Arrange
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"total_messages":7}`))
+	})
+
+	// Act
+	stats, err := c.GetStats(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), stats.TotalMessages)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	// Arrange
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"message not found","code":"NOT_FOUND"}`))
+	})
+
+	// Act
+	_, err := c.GetMessage(context.Background(), "missing")
+
+	// Assert
+	apiErr, ok := err.(*APIError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+		assert.Equal(t, "message not found", apiErr.Message)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestClient_ReportsRejectedFields(t *testing.T) {
+	// Arrange
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"request validation failed","code":"VALIDATION_ERROR","fields":[{"field":"content","message":"is required"}]}`))
+	})
+
+	// Act
+	_, err := c.CreateMessage(context.Background(), CreateMessageRequest{PhoneNumber: "+905551234567"})
+
+	// Assert
+	apiErr, ok := err.(*APIError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+		assert.Equal(t, []FieldError{{Field: "content", Message: "is required"}}, apiErr.Fields)
+	}
+}
+
+func TestNew_RejectsRelativeBaseURL(t *testing.T) {
+	_, err := New(Config{BaseURL: "localhost:8080"})
+
+	assert.Error(t, err)
+}
+
+// TestTypes_MatchAPI keeps the client's types in step with the API's: every
+// field of a client type must exist in the API type under the same JSON
+// name, and the full copies must not miss any.
+func TestTypes_MatchAPI(t *testing.T) {
+	tests := []struct {
+		name   string
+		client interface{}
+		api    interface{}
+		subset bool
+	}{
+		{name: "create request", client: CreateMessageRequest{}, api: dto.CreateMessageRequest{}},
+		{name: "message", client: Message{}, api: dto.MessageResponse{}},
+		{name: "message list", client: MessageList{}, api: dto.MessageListResponse{}},
+		{name: "stats", client: MessageStats{}, api: dto.MessageStatsResponse{}},
+		{name: "scheduler status", client: SchedulerStatus{}, api: dto.SchedulerStatusResponse{}, subset: true},
+		{name: "scheduler lane", client: SchedulerLane{}, api: dto.SchedulerLaneResponse{}},
+		{name: "scheduler config", client: SchedulerConfig{}, api: dto.UpdateSchedulerConfigRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientFields := jsonFields(reflect.TypeOf(tt.client))
+			apiFields := jsonFields(reflect.TypeOf(tt.api))
+			for name := range clientFields {
+				assert.Contains(t, apiFields, name)
+			}
+			if !tt.subset {
+				for name := range apiFields {
+					assert.Contains(t, clientFields, name)
+				}
+			}
+		})
+	}
+}
+
+func jsonFields(typ reflect.Type) map[string]bool {
+	fields := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateMessageRequest is the body of POST /api/v1/messages. PhoneNumber
+// is required for SMS, Recipient for email and push.
+type CreateMessageRequest struct {
+	Channel         string            `json:"channel,omitempty"`
+	PhoneNumber     string            `json:"phone_number,omitempty"`
+	Recipient       string            `json:"recipient,omitempty"`
+	Content         string            `json:"content"`
+	ScheduledAt     *time.Time        `json:"scheduled_at,omitempty"`
+	ExpiresAt       *time.Time        `json:"expires_at,omitempty"`
+	Priority        string            `json:"priority,omitempty"`
+	ClientReference string            `json:"client_reference,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	DestinationID   string            `json:"destination_id,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+	CallbackURL     string            `json:"callback_url,omitempty"`
+}
+
+type Message struct {
+	ID                string            `json:"id"`
+	Channel           string            `json:"channel"`
+	PhoneNumber       string            `json:"phone_number,omitempty"`
+	Recipient         string            `json:"recipient"`
+	Content           string            `json:"content"`
+	ContentHash       string            `json:"content_hash"`
+	Encoding          string            `json:"encoding"`
+	Segments          int               `json:"segments"`
+	Status            string            `json:"status"`
+	CreatedAt         time.Time         `json:"created_at"`
+	SentAt            *time.Time        `json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
+	ScheduledAt       *time.Time        `json:"scheduled_at,omitempty"`
+	ExpiresAt         *time.Time        `json:"expires_at,omitempty"`
+	NextRetryAt       *time.Time        `json:"next_retry_at,omitempty"`
+	Attempts          int               `json:"attempts"`
+	MaxAttempts       int               `json:"max_attempts"`
+	LastError         string            `json:"last_error,omitempty"`
+	ErrorCode         string            `json:"error_code,omitempty"`
+	WebhookMessageID  string            `json:"webhook_message_id,omitempty"`
+	ProviderResponse  json.RawMessage   `json:"provider_response,omitempty"`
+	TraceID           string            `json:"trace_id,omitempty"`
+	ProviderRequestID string            `json:"provider_request_id,omitempty"`
+	ClientReference   string            `json:"client_reference,omitempty"`
+	Priority          string            `json:"priority"`
+	TenantID          string            `json:"tenant_id,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	CampaignID        string            `json:"campaign_id,omitempty"`
+	DestinationID     string            `json:"destination_id,omitempty"`
+	CreatedBy         string            `json:"created_by,omitempty"`
+	DryRun            bool              `json:"dry_run,omitempty"`
+	Simulated         bool              `json:"simulated,omitempty"`
+	CallbackURL       string            `json:"callback_url,omitempty"`
+	SplitGroupID      string            `json:"split_group_id,omitempty"`
+	PartNumber        int               `json:"part_number,omitempty"`
+	PartCount         int               `json:"part_count,omitempty"`
+}
+
+type MessageList struct {
+	Messages   []Message `json:"messages"`
+	TotalCount int       `json:"total_count"`
+	Page       int       `json:"page"`
+	PageSize   int       `json:"page_size"`
+}
+
+type MessageStats struct {
+	TotalMessages       int64 `json:"total_messages"`
+	PendingMessages     int64 `json:"pending_messages"`
+	SentMessages        int64 `json:"sent_messages"`
+	FailedMessages      int64 `json:"failed_messages"`
+	CancelledMessages   int64 `json:"cancelled_messages"`
+	DeliveredMessages   int64 `json:"delivered_messages"`
+	UndeliveredMessages int64 `json:"undelivered_messages"`
+	QuarantinedMessages int64 `json:"quarantined_messages"`
+	PausedMessages      int64 `json:"paused_messages"`
+	BlockedMessages     int64 `json:"blocked_messages"`
+	ExpiredMessages     int64 `json:"expired_messages"`
+	// Performance is only given by GetStats
+	Performance *SendPerformance `json:"performance,omitempty"`
+}
+
+// SendPerformance sums up how sending went. The failure rate covers the
+// messages created in the last hour that were sent or failed.
+type SendPerformance struct {
+	AvgAttemptsPerSent  float64 `json:"avg_attempts_per_sent"`
+	AvgTimeToSendMs     int64   `json:"avg_time_to_send_ms"`
+	P95TimeToSendMs     int64   `json:"p95_time_to_send_ms"`
+	FailureRateLastHour float64 `json:"failure_rate_last_hour"`
+}
+
+// CallerStats counts the messages one caller created, such as
+// tenant:<id> for a tenant API key.
+type CallerStats struct {
+	Caller string `json:"caller"`
+	MessageStats
+}
+
+// CreateMessage creates a message. The request's ClientReference is sent
+// as the Idempotency-Key, and one is generated when it is empty, so a
+// retried create returns the first message instead of sending a duplicate.
+func (c *Client) CreateMessage(ctx context.Context, req CreateMessageRequest) (*Message, error) {
+	if req.ClientReference == "" {
+		req.ClientReference = uuid.NewString()
+	}
+
+	var message Message
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/messages",
+		header: http.Header{"Idempotency-Key": []string{req.ClientReference}},
+		body:   req,
+	}, &message)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (c *Client) GetMessage(ctx context.Context, id string) (*Message, error) {
+	var message Message
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/messages/" + url.PathEscape(id)}, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// WaitForMessage returns the message once it is sent or has failed for
+// good, or as it is when timeout (at most 60s) has passed; check Status.
+func (c *Client) WaitForMessage(ctx context.Context, id string, timeout time.Duration) (*Message, error) {
+	var message Message
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/messages/" + url.PathEscape(id) + "/wait",
+		query:  url.Values{"timeout": []string{timeout.String()}},
+		wait:   timeout,
+	}, &message)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetSentMessages returns a page of sent messages, most recent first.
+func (c *Client) GetSentMessages(ctx context.Context, page, pageSize int) (*MessageList, error) {
+	var list MessageList
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/messages/sent",
+		query:  url.Values{"page": []string{strconv.Itoa(page)}, "page_size": []string{strconv.Itoa(pageSize)}},
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *Client) GetStats(ctx context.Context) (*MessageStats, error) {
+	var stats MessageStats
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/messages/stats"}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetStatsByCaller counts messages per caller that created them, busiest
+// caller first.
+func (c *Client) GetStatsByCaller(ctx context.Context) ([]CallerStats, error) {
+	var stats struct {
+		Callers []CallerStats `json:"callers"`
+	}
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/v1/messages/stats",
+		query:  url.Values{"group_by": []string{"caller"}},
+	}, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats.Callers, nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SchedulerStatus is the scheduler's state and settings. The retry budget,
+// circuit breaker and reaper details of the API are left out.
+type SchedulerStatus struct {
+	IsRunning       bool            `json:"is_running"`
+	Mode            string          `json:"mode"`
+	LastRunAt       time.Time       `json:"last_run_at,omitempty"`
+	NextRunAt       *time.Time      `json:"next_run_at,omitempty"`
+	Schedule        string          `json:"schedule"`
+	BatchSize       int             `json:"batch_size"`
+	WorkerCount     int             `json:"worker_count"`
+	IntervalSeconds int             `json:"interval_seconds,omitempty"`
+	Priorities      []string        `json:"priorities,omitempty"`
+	Lanes           []SchedulerLane `json:"lanes,omitempty"`
+	TotalProcessed  int64           `json:"total_processed"`
+	TotalSuccessful int64           `json:"total_successful"`
+	TotalFailed     int64           `json:"total_failed"`
+	Degraded        bool            `json:"degraded"`
+	Dispatching     bool            `json:"dispatching"`
+}
+
+type SchedulerLane struct {
+	Name               string     `json:"name"`
+	Priorities         []string   `json:"priorities"`
+	IntervalSeconds    float64    `json:"interval_seconds"`
+	BatchSize          int        `json:"batch_size"`
+	WorkerCount        int        `json:"worker_count"`
+	RateLimitPerSecond int        `json:"rate_limit_per_second,omitempty"`
+	LastRunAt          *time.Time `json:"last_run_at,omitempty"`
+	TotalProcessed     int64      `json:"total_processed"`
+	TotalSuccessful    int64      `json:"total_successful"`
+	TotalFailed        int64      `json:"total_failed"`
+}
+
+// SchedulerConfig changes only the fields that are set.
+type SchedulerConfig struct {
+	BatchSize       *int `json:"batch_size,omitempty"`
+	IntervalSeconds *int `json:"interval_seconds,omitempty"`
+	WorkerCount     *int `json:"worker_count,omitempty"`
+}
+
+func (c *Client) SchedulerStatus(ctx context.Context) (*SchedulerStatus, error) {
+	var status SchedulerStatus
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/scheduler/status"}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StartScheduler fails with a 400 APIError when it is already running.
+func (c *Client) StartScheduler(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/api/v1/scheduler/start"}, nil)
+}
+
+// StopScheduler fails with a 400 APIError when it is not running.
+func (c *Client) StopScheduler(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/api/v1/scheduler/stop"}, nil)
+}
+
+// ResumeDispatch clears a pause raised by the retry budget; it fails with a
+// 400 APIError when dispatch is not paused.
+func (c *Client) ResumeDispatch(ctx context.Context) error {
+	return c.do(ctx, request{method: http.MethodPost, path: "/api/v1/scheduler/resume"}, nil)
+}
+
+// UpdateSchedulerConfig changes the scheduler of the instance that serves
+// the request and returns its new status.
+func (c *Client) UpdateSchedulerConfig(ctx context.Context, cfg SchedulerConfig) (*SchedulerStatus, error) {
+	var status SchedulerStatus
+	if err := c.do(ctx, request{method: http.MethodPatch, path: "/api/v1/scheduler/config", body: cfg}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals make a Watcher reload at once. SIGHUP is taken by the
+// binary upgrade.
+var reloadSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package config
+
+import "os"
+
+// reloadSignals is empty as Windows has no SIGUSR1; the file is still
+// watched.
+var reloadSignals []os.Signal
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// source looks settings up by their environment variable name, falling back
+// to the values of the config file.
+type source struct {
+	path   string
+	values map[string]string
+}
+
+// newSource reads the YAML file at path, a mapping of environment variable
+// names to values such as
+//
+//	LOG_LEVEL: debug
+//	MESSAGE_BATCH_SIZE: 10
+//	PHONE_BLOCKED_COUNTRIES: [CU, IR]
+//
+// Lists are joined with commas, like the variables expect them. An empty
+// path yields a source that only reads the environment.
+func newSource(path string) (source, error) {
+	src := source{path: path, values: map[string]string{}}
+	if path == "" {
+		return src, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return src, fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return src, fmt.Errorf("failed to parse CONFIG_FILE %s: %w", path, err)
+	}
+
+	for key, node := range nodes {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.Tag != "!!null" {
+				src.values[key] = node.Value
+			}
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(node.Content))
+			for _, item := range node.Content {
+				if item.Kind != yaml.ScalarNode {
+					return src, fmt.Errorf("CONFIG_FILE %s: %s must be a list of plain values", path, key)
+				}
+				items = append(items, item.Value)
+			}
+			src.values[key] = strings.Join(items, ",")
+		default:
+			return src, fmt.Errorf("CONFIG_FILE %s: %s must be a value or a list", path, key)
+		}
+	}
+	return src, nil
+}
+
+// lookup returns the environment variable key, or the file's value when the
+// variable is not set.
+func (s source) lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return s.values[key]
+}
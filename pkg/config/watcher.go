@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/eneskaya/insider-messaging/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Watcher loads the configuration again when CONFIG_FILE changes or the
+// process receives SIGUSR1, and hands it to its subscribers. A configuration
+// that fails validation is dropped and the previous one stays in effect.
+//
+// Only the log level, batch size, worker count, interval and provider rate
+// limit are applied while running; changes to other settings are logged and
+// wait for a restart.
+type Watcher struct {
+	load     func() (*Config, error)
+	path     string
+	interval time.Duration
+
+	mu          sync.Mutex
+	current     *Config
+	modTime     time.Time
+	subscribers []func(previous, current *Config)
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewWatcher(cfg *Config) *Watcher {
+	w := &Watcher{
+		load:     Load,
+		path:     cfg.App.ConfigFile,
+		interval: cfg.App.ConfigWatchInterval,
+		current:  cfg,
+		stopChan: make(chan struct{}),
+	}
+	w.modTime, _ = w.stat()
+	return w
+}
+
+// Subscribe registers fn to be called after every successful reload with the
+// configuration before and after it, so fn can apply only what changed.
+func (w *Watcher) Subscribe(fn func(previous, current *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Current is the configuration of the last successful load.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Reload loads and validates the configuration and notifies the
+// subscribers. Reloads run one at a time, so subscribers see them in order.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Taken before loading, so a broken file is not retried on every tick
+	if modTime, err := w.stat(); err == nil {
+		w.modTime = modTime
+	}
+
+	cfg, err := w.load()
+	if err != nil {
+		return err
+	}
+
+	previous := w.current
+	w.current = cfg
+
+	if !reflect.DeepEqual(previous.restartOnly(), cfg.restartOnly()) {
+		logger.Get().Warn("configuration changes other than log level, batch size, worker count, interval and rate limit take effect after a restart")
+	}
+	for _, fn := range w.subscribers {
+		fn(previous, cfg)
+	}
+
+	logger.Get().Info("configuration reloaded", zap.String("file", w.path))
+	return nil
+}
+
+func (w *Watcher) Start(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	if len(reloadSignals) > 0 {
+		signal.Notify(signals, reloadSignals...)
+	}
+
+	w.wg.Add(1)
+	go w.run(ctx, signals)
+
+	logger.Get().Info("configuration watcher started",
+		zap.String("file", w.path),
+		zap.Duration("interval", w.interval),
+	)
+}
+
+func (w *Watcher) Stop() {
+	close(w.stopChan)
+	w.wg.Wait()
+
+	logger.Get().Info("configuration watcher stopped")
+}
+
+func (w *Watcher) run(ctx context.Context, signals chan os.Signal) {
+	defer w.wg.Done()
+	defer signal.Stop(signals)
+
+	var tick <-chan time.Time
+	if w.path != "" && w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopChan:
+			return
+		case <-signals:
+			w.reload("signal")
+		case <-tick:
+			if w.changed() {
+				w.reload("file changed")
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload(reason string) {
+	if err := w.Reload(); err != nil {
+		logger.Get().Error("configuration reload failed, keeping the current configuration",
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+}
+
+// changed reports whether the file was modified since it was last loaded. A
+// file being replaced may be missing for a moment, which is not a change.
+func (w *Watcher) changed() bool {
+	modTime, err := w.stat()
+	if err != nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !modTime.Equal(w.modTime)
+}
+
+func (w *Watcher) stat() (time.Time, error) {
+	if w.path == "" {
+		return time.Time{}, os.ErrNotExist
+	}
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// restartOnly is c without the settings a Watcher applies while running.
+func (c *Config) restartOnly() Config {
+	fixed := *c
+	fixed.App.LogLevel = ""
+	fixed.Message.BatchSize = 0
+	fixed.Message.WorkerCount = 0
+	fixed.Message.IntervalSeconds = 0
+	fixed.Webhook.RateLimitPerSecond = 0
+	return fixed
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, `
+LOG_LEVEL: debug
+MESSAGE_BATCH_SIZE: 7
+RETRY_BACKOFF_BASE: 45s
+PHONE_BLOCKED_COUNTRIES: [CU, IR]
+WEBHOOK_RATE_LIMIT_PER_SECOND: 25
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("WEBHOOK_RATE_LIMIT_PER_SECOND", "40")
+
+	// Act
+	cfg, err := Load()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg.App.LogLevel)
+	assert.Equal(t, 7, cfg.Message.BatchSize)
+	assert.Equal(t, 45*time.Second, cfg.Message.RetryBackoff.Base)
+	assert.Equal(t, []string{"CU", "IR"}, cfg.Message.Destinations.Blocked)
+	assert.Equal(t, 40, cfg.Webhook.RateLimitPerSecond, "the environment overrides the file")
+	assert.Equal(t, path, cfg.App.ConfigFile)
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "malformed YAML", content: "LOG_LEVEL: [debug"},
+		{name: "nested section", content: "MESSAGE:\n  BATCH_SIZE: 5\n"},
+		{name: "invalid value", content: "MESSAGE_BATCH_SIZE: 0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfigFile(t, path, tt.content)
+			t.Setenv("CONFIG_FILE", path)
+
+			// Act
+			_, err := Load()
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "MESSAGE_BATCH_SIZE: 2\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+
+	watcher := NewWatcher(cfg)
+	var calls int
+	var previous, current *Config
+	watcher.Subscribe(func(p, c *Config) {
+		calls++
+		previous, current = p, c
+	})
+
+	// Act
+	writeConfigFile(t, path, "MESSAGE_BATCH_SIZE: 5\n")
+	err = watcher.Reload()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, previous.Message.BatchSize)
+	assert.Equal(t, 5, current.Message.BatchSize)
+	assert.Same(t, current, watcher.Current())
+}
+
+func TestWatcher_ReloadKeepsConfigWhenInvalid(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "MESSAGE_BATCH_SIZE: 2\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+
+	watcher := NewWatcher(cfg)
+	var calls int
+	watcher.Subscribe(func(_, _ *Config) { calls++ })
+
+	// Act
+	writeConfigFile(t, path, "MESSAGE_BATCH_SIZE: 0\n")
+	err = watcher.Reload()
+
+	// Assert
+	assert.Error(t, err)
+	assert.Zero(t, calls)
+	assert.Same(t, cfg, watcher.Current())
+}
+
+func TestWatcher_ChangedFollowsModTime(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, "MESSAGE_BATCH_SIZE: 2\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	watcher := NewWatcher(cfg)
+
+	// Act & Assert
+	assert.False(t, watcher.changed())
+
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, later, later))
+	assert.True(t, watcher.changed())
+
+	assert.NoError(t, watcher.Reload())
+	assert.False(t, watcher.changed())
+}
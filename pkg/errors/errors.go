@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type ErrorCode string
 
@@ -8,15 +11,90 @@ const (
 	ErrorCodeValidation      ErrorCode = "VALIDATION_ERROR"
 	ErrorCodeNotFound        ErrorCode = "NOT_FOUND"
 	ErrorCodeAlreadyExists   ErrorCode = "ALREADY_EXISTS"
+	ErrorCodeConflict        ErrorCode = "CONFLICT"
+	ErrorCodeVersionConflict ErrorCode = "VERSION_CONFLICT"
 	ErrorCodeDatabase        ErrorCode = "DATABASE_ERROR"
 	ErrorCodeInternal        ErrorCode = "INTERNAL_ERROR"
 	ErrorCodeTimeout         ErrorCode = "TIMEOUT"
 	ErrorCodeNetworkError    ErrorCode = "NETWORK_ERROR"
 	ErrorCodeInvalidResponse ErrorCode = "INVALID_RESPONSE"
 	ErrorCodeRateLimit       ErrorCode = "RATE_LIMIT"
+	ErrorCodeThrottled       ErrorCode = "PROVIDER_THROTTLED"
 	ErrorCodeServerError     ErrorCode = "SERVER_ERROR"
+	ErrorCodeIntegrity       ErrorCode = "INTEGRITY_ERROR"
+	ErrorCodeCircuitOpen     ErrorCode = "CIRCUIT_OPEN"
+	ErrorCodeStaleClaim      ErrorCode = "STALE_CLAIM"
+
+	// The API request ran past its server-side deadline
+	ErrorCodeDeadlineExceeded ErrorCode = "DEADLINE_EXCEEDED"
+
+	// The API request body is over the size limit
+	ErrorCodeBodyTooLarge ErrorCode = "BODY_TOO_LARGE"
+
+	// Rejections by the per-recipient guard
+	ErrorCodeRecipientRateLimited ErrorCode = "RECIPIENT_RATE_LIMITED"
+	ErrorCodeDuplicateContent     ErrorCode = "DUPLICATE_CONTENT"
+
+	// Destination country excluded by the allow or block list
+	ErrorCodeDestinationBlocked ErrorCode = "DESTINATION_BLOCKED"
+
+	// Recipient on the opt-out blacklist
+	ErrorCodeRecipientBlocked ErrorCode = "RECIPIENT_BLOCKED"
+
+	// Content refused by the content filter
+	ErrorCodeContentRejected ErrorCode = "CONTENT_REJECTED"
+
+	// Expiry passed before the message could be sent
+	ErrorCodeMessageExpired ErrorCode = "MESSAGE_EXPIRED"
+
+	// No provider is configured for the message's channel
+	ErrorCodeChannelUnavailable ErrorCode = "CHANNEL_UNAVAILABLE"
+
+	// Another worker holds the message's send guard
+	ErrorCodeSendInProgress ErrorCode = "SEND_IN_PROGRESS"
+
+	// Held back until the recipient's quiet hours end
+	ErrorCodeQuietHours ErrorCode = "QUIET_HOURS"
+
+	// Held back until the provider's daily quota resets
+	ErrorCodeQuotaExhausted ErrorCode = "DAILY_QUOTA_EXHAUSTED"
+
+	// Webhook destination unknown, inactive or owned by another tenant
+	ErrorCodeDestinationUnavailable ErrorCode = "DESTINATION_UNAVAILABLE"
+
+	// Provider rejections that sending again cannot fix; see ProviderErrorCode
+	ErrorCodeInvalidRecipient  ErrorCode = "INVALID_RECIPIENT"
+	ErrorCodeRecipientOptedOut ErrorCode = "RECIPIENT_OPTED_OUT"
+	ErrorCodeProviderRejected  ErrorCode = "PROVIDER_REJECTED"
+	ErrorCodeMessageBlocked    ErrorCode = "MESSAGE_BLOCKED"
+
+	// Provider failures that may clear up by the next attempt
+	ErrorCodeInsufficientBalance ErrorCode = "INSUFFICIENT_BALANCE"
+	ErrorCodeCarrierError        ErrorCode = "CARRIER_ERROR"
 )
 
+// permanentCodes are the failures another attempt would repeat: the message
+// itself, its recipient or its content was refused. Every other code is
+// assumed to be transient.
+var permanentCodes = map[ErrorCode]bool{
+	ErrorCodeValidation:         true,
+	ErrorCodeIntegrity:          true,
+	ErrorCodeDestinationBlocked: true,
+	ErrorCodeRecipientBlocked:   true,
+	ErrorCodeContentRejected:    true,
+	ErrorCodeMessageExpired:     true,
+	ErrorCodeInvalidRecipient:   true,
+	ErrorCodeRecipientOptedOut:  true,
+	ErrorCodeProviderRejected:   true,
+	ErrorCodeMessageBlocked:     true,
+}
+
+// Retryable reports whether an operation that failed with c could succeed
+// when it is tried again later.
+func (c ErrorCode) Retryable() bool {
+	return !permanentCodes[c]
+}
+
 type AppError struct {
 	Code    ErrorCode
 	Message string
@@ -34,6 +112,12 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Retryable reports whether trying again later could succeed, e.g. after a
+// timeout, but not after the provider refused the recipient.
+func (e *AppError) Retryable() bool {
+	return e.Code.Retryable()
+}
+
 func New(code ErrorCode, message string) *AppError {
 	return &AppError{
 		Code:    code,
@@ -64,3 +148,24 @@ func NewDatabaseError(err error) *AppError {
 func NewInternalError(err error) *AppError {
 	return Wrap(ErrorCodeInternal, "internal server error", err)
 }
+
+// NewVersionConflictError reports an optimistic lock failure: the record was
+// changed by someone else after it was read.
+func NewVersionConflictError(message string) *AppError {
+	return New(ErrorCodeVersionConflict, message)
+}
+
+func IsVersionConflict(err error) bool {
+	appErr, ok := err.(*AppError)
+	return ok && appErr.Code == ErrorCodeVersionConflict
+}
+
+// IsRetryable reports whether err is worth retrying. Errors that are not an
+// AppError carry no classification and are retried.
+func IsRetryable(err error) bool {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Retryable()
+	}
+	return true
+}
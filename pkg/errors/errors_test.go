@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", New(ErrorCodeTimeout, "request timeout"), true},
+		{"server error", New(ErrorCodeServerError, "502"), true},
+		{"invalid response", New(ErrorCodeInvalidResponse, "missing messageId"), true},
+		{"invalid recipient", New(ErrorCodeInvalidRecipient, "invalid number"), false},
+		{"opted out", New(ErrorCodeRecipientOptedOut, "unsubscribed"), false},
+		{"blocked", New(ErrorCodeMessageBlocked, "carrier filtered"), false},
+		{"insufficient balance", New(ErrorCodeInsufficientBalance, "top up"), true},
+		{"carrier error", New(ErrorCodeCarrierError, "handset unreachable"), true},
+		{"wrapped rejection", fmt.Errorf("sms send failed: %w", New(ErrorCodeProviderRejected, "400")), false},
+		{"unclassified", errors.New("boom"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestProviderErrorCode(t *testing.T) {
+	code, ok := ProviderErrorCode("twilio", "21211")
+	assert.True(t, ok)
+	assert.Equal(t, ErrorCodeInvalidRecipient, code)
+
+	_, ok = ProviderErrorCode("twilio", "30001")
+	assert.False(t, ok, "unmapped codes keep the generic classification")
+
+	_, ok = ProviderErrorCode("webhook", "21211")
+	assert.False(t, ok)
+}
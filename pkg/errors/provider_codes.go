@@ -0,0 +1,52 @@
+package errors
+
+// providerCodes maps the error codes providers answer a refused message
+// with to the code the message fails with. Codes that are not listed keep
+// the generic classification of the response.
+var providerCodes = map[string]map[string]ErrorCode{
+	"twilio": {
+		"21211": ErrorCodeInvalidRecipient,  // Invalid 'To' phone number
+		"21214": ErrorCodeInvalidRecipient,  // 'To' phone number cannot be reached
+		"21217": ErrorCodeInvalidRecipient,  // Phone number does not appear to be valid
+		"21407": ErrorCodeInvalidRecipient,  // Phone number type does not support SMS
+		"21614": ErrorCodeInvalidRecipient,  // 'To' number is not a valid mobile number
+		"21610": ErrorCodeRecipientOptedOut, // Recipient replied STOP
+		"21408": ErrorCodeProviderRejected,  // Sending to the region is not enabled
+		"21602": ErrorCodeProviderRejected,  // Message body is required
+		"21617": ErrorCodeProviderRejected,  // Message body exceeds 1600 characters
+	},
+	"sns": {
+		"InvalidParameter":      ErrorCodeProviderRejected,
+		"InvalidParameterValue": ErrorCodeProviderRejected,
+	},
+}
+
+// ProviderFailureCodes are the codes a provider's refusal can be mapped to
+// by the provider error mappings managed at runtime.
+var ProviderFailureCodes = []ErrorCode{
+	ErrorCodeInvalidRecipient,
+	ErrorCodeRecipientOptedOut,
+	ErrorCodeMessageBlocked,
+	ErrorCodeProviderRejected,
+	ErrorCodeInsufficientBalance,
+	ErrorCodeCarrierError,
+	ErrorCodeServerError,
+	ErrorCodeInvalidResponse,
+}
+
+// IsProviderFailureCode reports whether code is one of ProviderFailureCodes.
+func IsProviderFailureCode(code ErrorCode) bool {
+	for _, failureCode := range ProviderFailureCodes {
+		if code == failureCode {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderErrorCode returns the code a provider's own error code maps to.
+// ok is false for codes that are not mapped.
+func ProviderErrorCode(provider, code string) (ErrorCode, bool) {
+	mapped, ok := providerCodes[provider][code]
+	return mapped, ok
+}
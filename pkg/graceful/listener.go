@@ -0,0 +1,83 @@
+package graceful
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ListenerFDEnv is set on a child process started by Upgrade and holds the
+// descriptor number of the inherited listening socket.
+const ListenerFDEnv = "INSIDER_LISTENER_FD"
+
+// Listen returns the listener handed over by a parent process when one exists,
+// otherwise it binds addr, optionally with SO_REUSEPORT so that an old and a
+// new binary can accept on the same port side by side.
+func Listen(addr string, reusePort bool) (net.Listener, error) {
+	if fdStr := os.Getenv(ListenerFDEnv); fdStr != "" {
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", ListenerFDEnv, fdStr, err)
+		}
+
+		file := os.NewFile(uintptr(fd), "inherited-listener")
+		defer file.Close()
+
+		listener, err := net.FileListener(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use inherited listener: %w", err)
+		}
+		return listener, nil
+	}
+
+	lc := net.ListenConfig{}
+	if reusePort {
+		lc.Control = reusePortControl
+	}
+
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// Inherited reports whether this process was started by Upgrade.
+func Inherited() bool {
+	return os.Getenv(ListenerFDEnv) != ""
+}
+
+// Upgrade re-executes the current binary with the same arguments and passes
+// the listening socket to it. The caller keeps serving until it shuts down
+// gracefully; the kernel queues new connections on the shared socket so
+// nothing is refused in between.
+func Upgrade(listener net.Listener) (*os.Process, error) {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return nil, fmt.Errorf("listener handoff requires a TCP listener, got %T", listener)
+	}
+
+	file, err := tcpListener.File()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listener file: %w", err)
+	}
+	defer file.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve executable: %w", err)
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// ExtraFiles[0] becomes descriptor 3 in the child
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Env = append(os.Environ(), ListenerFDEnv+"=3")
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start new process: %w", err)
+	}
+
+	return cmd.Process, nil
+}
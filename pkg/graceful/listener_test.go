@@ -0,0 +1,44 @@
+//go:build linux || darwin || freebsd
+
+package graceful
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListen_ReusePort(t *testing.T) {
+	first, err := Listen("127.0.0.1:0", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer first.Close()
+
+	second, err := Listen(first.Addr().String(), true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer second.Close()
+
+	assert.Equal(t, first.Addr().String(), second.Addr().String())
+}
+
+func TestListen_WithoutReusePortConflicts(t *testing.T) {
+	first, err := Listen("127.0.0.1:0", false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer first.Close()
+
+	_, err = Listen(first.Addr().String(), false)
+	assert.Error(t, err)
+}
+
+func TestListen_InvalidInheritedFD(t *testing.T) {
+	t.Setenv(ListenerFDEnv, "not-a-number")
+
+	_, err := Listen("127.0.0.1:0", false)
+	assert.Error(t, err)
+	assert.True(t, Inherited())
+}
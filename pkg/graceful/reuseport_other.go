@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd
+
+package graceful
+
+import "syscall"
+
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return nil
+}
@@ -0,0 +1,169 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Roles a token can carry, from most to least privileged
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleReadOnly = "read-only"
+)
+
+// Leeway is the clock skew tolerated when checking exp and nbf.
+const Leeway = 30 * time.Second
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrInvalid   = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+	ErrClaims    = errors.New("invalid token claims")
+)
+
+// Claims are the registered claims this API reads plus the caller's role.
+// Times are Unix seconds.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	Issuer    string `json:"iss,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+var encoding = base64.RawURLEncoding
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	return rank(role) > 0
+}
+
+// RoleAtLeast reports whether role grants everything minimum does.
+func RoleAtLeast(role, minimum string) bool {
+	return rank(role) > 0 && rank(role) >= rank(minimum)
+}
+
+func rank(role string) int {
+	switch role {
+	case RoleAdmin:
+		return 3
+	case RoleOperator:
+		return 2
+	case RoleReadOnly:
+		return 1
+	}
+	return 0
+}
+
+// Sign returns claims as an HS256 signed token.
+func Sign(secret string, claims Claims) (string, error) {
+	head, err := json.Marshal(header{Algorithm: "HS256", Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := encoding.EncodeToString(head) + "." + encoding.EncodeToString(payload)
+	return unsigned + "." + encoding.EncodeToString(sum(secret, unsigned)), nil
+}
+
+// Verifier checks HS256 tokens signed with a shared secret. Other algorithms,
+// including "none", are rejected.
+type Verifier struct {
+	secret string
+	issuer string
+	now    func() time.Time
+}
+
+// NewVerifier also requires the iss claim to equal issuer when it is set.
+func NewVerifier(secret, issuer string) *Verifier {
+	return &Verifier{
+		secret: secret,
+		issuer: issuer,
+		now:    time.Now,
+	}
+}
+
+// Verify checks the signature, expiry and role of token and returns its
+// claims. Tokens without an exp claim are rejected.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var head header
+	if err := decode(parts[0], &head); err != nil {
+		return nil, err
+	}
+	if head.Algorithm != "HS256" {
+		return nil, ErrInvalid
+	}
+
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !hmac.Equal(sum(v.secret, parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalid
+	}
+
+	var claims Claims
+	if err := decode(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(Leeway)) {
+		return nil, ErrExpired
+	}
+	if claims.NotBefore != 0 && now.Add(Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrClaims
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrClaims
+	}
+	if claims.Subject == "" || !ValidRole(claims.Role) {
+		return nil, ErrClaims
+	}
+
+	return &claims, nil
+}
+
+// LooksLikeToken tells a JWT apart from an opaque API key without verifying
+// it.
+func LooksLikeToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func decode(segment string, v interface{}) error {
+	raw, err := encoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
+
+func sum(secret, unsigned string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
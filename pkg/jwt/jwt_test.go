@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func validClaims(now time.Time) Claims {
+	return Claims{
+		Subject:   "alice",
+		Role:      RoleOperator,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerify_AcceptsSignedToken(t *testing.T) {
+	now := time.Now()
+	token, err := Sign(testSecret, validClaims(now))
+	assert.NoError(t, err)
+
+	claims, err := NewVerifier(testSecret, "").Verify(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.Equal(t, RoleOperator, claims.Role)
+}
+
+func TestVerify_RejectsTamperedOrForeignToken(t *testing.T) {
+	now := time.Now()
+	token, _ := Sign(testSecret, validClaims(now))
+
+	admin := validClaims(now)
+	admin.Role = RoleAdmin
+	forged, _ := Sign("another-secret", admin)
+	parts := strings.Split(token, ".")
+	adminPayload := strings.Split(forged, ".")[1]
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	verifier := NewVerifier(testSecret, "")
+	_, err := verifier.Verify(forged)
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = verifier.Verify(parts[0] + "." + adminPayload + "." + parts[2])
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = verifier.Verify(unsigned)
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = verifier.Verify("not-a-token")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestVerify_RejectsExpiredToken(t *testing.T) {
+	now := time.Now()
+	expired := validClaims(now)
+	expired.ExpiresAt = now.Add(-time.Minute).Unix()
+	withinLeeway := validClaims(now)
+	withinLeeway.ExpiresAt = now.Add(-Leeway / 2).Unix()
+	noExpiry := validClaims(now)
+	noExpiry.ExpiresAt = 0
+
+	verifier := NewVerifier(testSecret, "")
+	for _, tc := range []struct {
+		claims Claims
+		err    error
+	}{
+		{expired, ErrExpired},
+		{withinLeeway, nil},
+		{noExpiry, ErrExpired},
+	} {
+		token, _ := Sign(testSecret, tc.claims)
+		_, err := verifier.Verify(token)
+		if tc.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, tc.err)
+		}
+	}
+}
+
+func TestVerify_ChecksClaims(t *testing.T) {
+	now := time.Now()
+	unknownRole := validClaims(now)
+	unknownRole.Role = "superuser"
+	notYetValid := validClaims(now)
+	notYetValid.NotBefore = now.Add(time.Hour).Unix()
+	issued := validClaims(now)
+	issued.Issuer = "insider"
+
+	verifier := NewVerifier(testSecret, "insider")
+	for _, claims := range []Claims{unknownRole, notYetValid, validClaims(now)} {
+		token, _ := Sign(testSecret, claims)
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrClaims)
+	}
+
+	token, _ := Sign(testSecret, issued)
+	_, err := verifier.Verify(token)
+	assert.NoError(t, err)
+}
+
+func TestRoleAtLeast(t *testing.T) {
+	assert.True(t, RoleAtLeast(RoleAdmin, RoleOperator))
+	assert.True(t, RoleAtLeast(RoleOperator, RoleOperator))
+	assert.False(t, RoleAtLeast(RoleReadOnly, RoleOperator))
+	assert.False(t, RoleAtLeast("", RoleReadOnly))
+}
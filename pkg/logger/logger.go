@@ -1,29 +1,29 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var log *zap.Logger
+var (
+	log   *zap.Logger
+	level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+)
 
-func Init(level string) error {
-	var zapLevel zapcore.Level
-	switch level {
-	case "debug":
-		zapLevel = zapcore.DebugLevel
-	case "info":
-		zapLevel = zapcore.InfoLevel
-	case "warn":
-		zapLevel = zapcore.WarnLevel
-	case "error":
-		zapLevel = zapcore.ErrorLevel
-	default:
+func Init(lvl string) error {
+	zapLevel, err := parseLevel(lvl)
+	if err != nil {
 		zapLevel = zapcore.InfoLevel
 	}
+	level.SetLevel(zapLevel)
 
 	config := zap.Config{
-		Level:            zap.NewAtomicLevelAt(zapLevel),
+		Level:            level,
 		Development:      false,
 		Encoding:         "json",
 		EncoderConfig:    zap.NewProductionEncoderConfig(),
@@ -34,7 +34,6 @@ func Init(level string) error {
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	var err error
 	log, err = config.Build()
 	if err != nil {
 		return err
@@ -50,8 +49,47 @@ func Get() *zap.Logger {
 	return log
 }
 
+// FromContext returns the logger tagged with the request ID carried by ctx,
+// so every line written while serving a request can be correlated with it.
+func FromContext(ctx context.Context) *zap.Logger {
+	if id := requestid.FromContext(ctx); id != "" {
+		return Get().With(zap.String("request_id", id))
+	}
+	return Get()
+}
+
+// SetLevel changes the level of the logger built by Init without rebuilding
+// it, so it is safe to call while other goroutines are logging.
+func SetLevel(lvl string) error {
+	zapLevel, err := parseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(zapLevel)
+	return nil
+}
+
+func Level() string {
+	return level.Level().String()
+}
+
 func Sync() {
 	if log != nil {
 		_ = log.Sync()
 	}
 }
+
+func parseLevel(lvl string) (zapcore.Level, error) {
+	switch lvl {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", lvl)
+	}
+}
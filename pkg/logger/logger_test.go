@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eneskaya/insider-messaging/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetLevel(t *testing.T) {
+	assert.NoError(t, Init("info"))
+	defer func() { _ = SetLevel("info") }()
+
+	assert.False(t, Get().Core().Enabled(zapcore.DebugLevel), "debug should be disabled at info")
+
+	assert.NoError(t, SetLevel("debug"))
+	assert.Equal(t, "debug", Level())
+	assert.True(t, Get().Core().Enabled(zapcore.DebugLevel), "existing logger should pick up the new level")
+
+	assert.NoError(t, SetLevel("error"))
+	assert.Equal(t, "error", Level())
+	assert.False(t, Get().Core().Enabled(zapcore.InfoLevel))
+}
+
+func TestSetLevel_RejectsUnknownLevel(t *testing.T) {
+	assert.NoError(t, Init("warn"))
+	defer func() { _ = SetLevel("info") }()
+
+	err := SetLevel("verbose")
+
+	assert.Error(t, err)
+	assert.Equal(t, "warn", Level())
+}
+
+func TestFromContext_AddsRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	previous := log
+	log = zap.New(core)
+	defer func() { log = previous }()
+
+	FromContext(requestid.WithRequestID(context.Background(), "req-123")).Info("with id")
+	FromContext(context.Background()).Info("without id")
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "req-123", entries[0].ContextMap()["request_id"])
+	assert.NotContains(t, entries[1].ContextMap(), "request_id")
+}
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "insider_messaging"
+
+var (
+	MessagesCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_created_total",
+		Help:      "Messages accepted by the API.",
+	})
+
+	MessagesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Messages delivered to the webhook.",
+	})
+
+	MessagesSimulated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_simulated_total",
+		Help:      "Dry-run sends recorded as sent without calling the provider; also counted as sent.",
+	})
+
+	MessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_failed_total",
+		Help:      "Failed delivery attempts, by error code.",
+	}, []string{"error_code"})
+
+	MessagesDeadLettered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_dead_lettered_total",
+		Help:      "Messages moved to the dead letter queue after exhausting their attempts.",
+	})
+
+	MessagesExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_expired_total",
+		Help:      "Messages that passed their expiry before they were sent.",
+	})
+
+	DuplicatesCollapsed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "duplicate_messages_collapsed_total",
+		Help:      "Creates answered with an earlier message of the same recipient and content.",
+	})
+
+	DuplicateSendsPrevented = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "duplicate_sends_prevented_total",
+		Help:      "Sends skipped by the send guard, by reason (in_flight or already_sent).",
+	}, []string{"reason"})
+
+	MessagesReaped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_reaped_total",
+		Help:      "Messages released from a stale processing claim, by resulting status.",
+	}, []string{"status"})
+
+	MessagesRemoved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_removed_total",
+		Help:      "Messages removed by the retention job, by status and mode (soft or hard).",
+	}, []string{"status", "mode"})
+
+	MessagesArchived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_archived_total",
+		Help:      "Messages moved to object storage and removed by the archive job.",
+	})
+
+	DeliveryReceipts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "delivery_receipts_total",
+		Help:      "Provider delivery receipts applied to messages, by resulting status.",
+	}, []string{"status"})
+
+	KafkaRecordsConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_records_consumed_total",
+		Help:      "Records read from the message intake topic, by result.",
+	}, []string{"result"})
+
+	StatusCallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "status_callbacks_total",
+		Help:      "Status callbacks posted to message callback URLs, by result (delivered, failed or dropped).",
+	}, []string{"result"})
+
+	OutboxEventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_events_published_total",
+		Help:      "Lifecycle events the outbox relay delivered to the broker, by event type.",
+	}, []string{"event_type"})
+
+	WebhookDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_request_duration_seconds",
+		Help:      "Latency of outbound webhook requests, by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	MessagesDeferred = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_deferred_total",
+		Help:      "Messages held back until the recipient's quiet hours end, by country.",
+	}, []string{"region"})
+
+	ProviderRateLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_rate_limit_per_second",
+		Help:      "Effective outbound request rate, lowered while the provider throttles us, by provider.",
+	}, []string{"provider"})
+
+	ProviderThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_throttled_total",
+		Help:      "429 responses from the provider, by provider.",
+	}, []string{"provider"})
+
+	ProviderDailyQuotaUsed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_daily_quota_used",
+		Help:      "Requests counted against today's provider quota across all instances, by provider.",
+	}, []string{"provider"})
+
+	SchedulerCycleDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_cycle_duration_seconds",
+		Help:      "Duration of a scheduler processing cycle.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency, by operation.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation"})
+
+	DatabaseUp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "database_up",
+		Help:      "1 while the database connection monitor reaches the database, 0 during an outage.",
+	})
+
+	DispatchAutoPaused = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "dispatch_auto_paused",
+		Help:      "1 while dispatch is paused because a health check keeps failing.",
+	})
+
+	RedisOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_operation_duration_seconds",
+		Help:      "Redis command latency, by command and result.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25},
+	}, []string{"command", "result"})
+
+	CacheFallback = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_fallback",
+		Help:      "1 while the message cache is served from memory because Redis is unreachable, 0 otherwise.",
+	})
+)
+
+// Outcome labels shared by the histograms above
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Outcome maps an error to the success/error label value.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// ObserveRedis records the latency of a Redis command started at start.
+func ObserveRedis(command, result string, start time.Time) {
+	RedisOperationDuration.WithLabelValues(command, result).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, Outcome(nil))
+	assert.Equal(t, OutcomeError, Outcome(errors.New("boom")))
+}
+
+func TestHandler_ExposesMessageCounters(t *testing.T) {
+	// Arrange
+	before := testutil.ToFloat64(MessagesCreated)
+	MessagesCreated.Inc()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+
+	// Act
+	Handler().ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(MessagesCreated))
+	assert.True(t, strings.Contains(rec.Body.String(), "insider_messaging_messages_created_total"))
+}
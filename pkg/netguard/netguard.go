@@ -0,0 +1,111 @@
+// Package netguard keeps requests to caller supplied URLs, such as status
+// callbacks, away from the service's own network.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrScheme    = errors.New("URL must be an absolute https URL")
+	ErrForbidden = errors.New("address is not public")
+)
+
+// Resolver looks up the addresses of a host name.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// Guard refuses loopback, private, link-local, unspecified and multicast
+// addresses, except those in the networks an operator allowed. URLs are
+// checked when they are accepted; Control checks the address actually
+// dialed, so a host that resolves differently later is still refused.
+type Guard struct {
+	allowed  []*net.IPNet
+	resolver Resolver
+}
+
+// New returns a Guard that lets through the CIDR networks in allowed.
+func New(allowed []string) (*Guard, error) {
+	g := &Guard{resolver: net.DefaultResolver}
+	for _, cidr := range allowed {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		g.allowed = append(g.allowed, network)
+	}
+	return g, nil
+}
+
+// WithResolver makes g resolve host names with r.
+func (g *Guard) WithResolver(r Resolver) *Guard {
+	g.resolver = r
+	return g
+}
+
+// CheckURL accepts an absolute https URL whose host resolves only to
+// addresses CheckIP accepts.
+func (g *Guard) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrScheme
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%s: %w", host, ErrForbidden)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return g.CheckIP(ip)
+	}
+
+	addrs, err := g.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("resolve %s: no addresses", host)
+	}
+	for _, addr := range addrs {
+		if err := g.CheckIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckIP accepts public unicast addresses and those in an allowed network.
+func (g *Guard) CheckIP(ip net.IP) error {
+	for _, network := range g.allowed {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%s: %w", ip, ErrForbidden)
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function that refuses to connect to an
+// address CheckIP does not accept.
+func (g *Guard) Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%s: %w", host, ErrForbidden)
+	}
+	return g.CheckIP(ip)
+}
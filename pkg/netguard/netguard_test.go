@@ -0,0 +1,153 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver resolves every host to the same addresses.
+type staticResolver []string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	addrs := make([]net.IPAddr, 0, len(r))
+	for _, ip := range r {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestGuard_CheckIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		wantErr bool
+	}{
+		{name: "public IPv4", ip: "93.184.216.34"},
+		{name: "public IPv6", ip: "2606:2800:220:1:248:1893:25c8:1946"},
+		{name: "IPv4 loopback", ip: "127.0.0.1", wantErr: true},
+		{name: "IPv4 loopback range", ip: "127.10.0.1", wantErr: true},
+		{name: "IPv6 loopback", ip: "::1", wantErr: true},
+		{name: "IPv4-mapped loopback", ip: "::ffff:127.0.0.1", wantErr: true},
+		{name: "private 10/8", ip: "10.1.2.3", wantErr: true},
+		{name: "private 172.16/12", ip: "172.31.255.1", wantErr: true},
+		{name: "private 192.168/16", ip: "192.168.0.10", wantErr: true},
+		{name: "IPv6 unique local", ip: "fd00::1", wantErr: true},
+		{name: "link-local", ip: "169.254.1.1", wantErr: true},
+		{name: "cloud metadata", ip: "169.254.169.254", wantErr: true},
+		{name: "IPv6 link-local", ip: "fe80::1", wantErr: true},
+		{name: "IPv4 unspecified", ip: "0.0.0.0", wantErr: true},
+		{name: "IPv6 unspecified", ip: "::", wantErr: true},
+		{name: "IPv4 multicast", ip: "224.0.0.1", wantErr: true},
+		{name: "IPv4 global multicast", ip: "239.1.2.3", wantErr: true},
+		{name: "IPv6 multicast", ip: "ff02::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			guard, err := New(nil)
+			require.NoError(t, err)
+
+			// Act
+			err = guard.CheckIP(net.ParseIP(tt.ip))
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrForbidden)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGuard_CheckIPAllowedNetwork(t *testing.T) {
+	// Arrange
+	guard, err := New([]string{"10.20.0.0/16"})
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.NoError(t, guard.CheckIP(net.ParseIP("10.20.3.4")))
+	assert.ErrorIs(t, guard.CheckIP(net.ParseIP("10.21.3.4")), ErrForbidden)
+}
+
+func TestNew_InvalidNetwork(t *testing.T) {
+	// Act
+	_, err := New([]string{"10.0.0.0"})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestGuard_CheckURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		resolved []string
+		wantErr  error
+	}{
+		{name: "public host", url: "https://client.example.com/hook", resolved: []string{"93.184.216.34"}},
+		{name: "public IP literal", url: "https://93.184.216.34/hook"},
+		{name: "http scheme", url: "http://client.example.com/hook", resolved: []string{"93.184.216.34"}, wantErr: ErrScheme},
+		{name: "relative URL", url: "/hook", wantErr: ErrScheme},
+		{name: "localhost", url: "https://localhost/hook", wantErr: ErrForbidden},
+		{name: "localhost subdomain", url: "https://api.localhost./hook", wantErr: ErrForbidden},
+		{name: "loopback literal", url: "https://127.0.0.1:8443/hook", wantErr: ErrForbidden},
+		{name: "IPv6 loopback literal", url: "https://[::1]/hook", wantErr: ErrForbidden},
+		{name: "metadata literal", url: "https://169.254.169.254/latest/meta-data", wantErr: ErrForbidden},
+		{name: "resolves to private", url: "https://internal.example.com/hook", resolved: []string{"10.0.0.5"}, wantErr: ErrForbidden},
+		{name: "one of several addresses private", url: "https://mixed.example.com/hook", resolved: []string{"93.184.216.34", "192.168.1.1"}, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			guard, err := New(nil)
+			require.NoError(t, err)
+			guard.WithResolver(staticResolver(tt.resolved))
+
+			// Act
+			err = guard.CheckURL(context.Background(), tt.url)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGuard_ControlRefusesInternalAddress(t *testing.T) {
+	// Arrange
+	guard, err := New(nil)
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.True(t, errors.Is(guard.Control("tcp4", "169.254.169.254:80", nil), ErrForbidden))
+	assert.True(t, errors.Is(guard.Control("tcp6", "[::1]:443", nil), ErrForbidden))
+	assert.NoError(t, guard.Control("tcp4", "93.184.216.34:443", nil))
+}
+
+func TestGuard_DialRefusedAtConnectTime(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	guard, err := New(nil)
+	require.NoError(t, err)
+	dialer := &net.Dialer{Control: guard.Control}
+
+	// Act
+	_, err = dialer.Dial("tcp", listener.Addr().String())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,38 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header carries the request/trace ID on inbound API calls and outbound
+// webhook calls.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// Ensure returns ctx unchanged when it already carries an ID, otherwise it
+// attaches a newly generated one.
+func Ensure(ctx context.Context) (context.Context, string) {
+	if id := FromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := New()
+	return WithRequestID(ctx, id), id
+}
+
+func New() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,88 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying the HMAC-SHA256 signature of a callback body and the Unix
+// time it was signed at.
+const (
+	Header          = "X-Signature"
+	TimestampHeader = "X-Signature-Timestamp"
+)
+
+var (
+	ErrMissing = errors.New("missing signature")
+	ErrInvalid = errors.New("invalid signature")
+	ErrExpired = errors.New("signature timestamp outside the allowed window")
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	return hex.EncodeToString(sum(secret, timestamp, body))
+}
+
+// Verify checks a signature produced by Sign; an optional "sha256=" prefix is
+// accepted. Timestamps further than tolerance from now are rejected so that a
+// captured request cannot be replayed later. A zero tolerance skips the check.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissing
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	if tolerance > 0 {
+		skew := now.Sub(time.Unix(ts, 0))
+		if skew > tolerance || skew < -tolerance {
+			return ErrExpired
+		}
+	}
+
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !hmac.Equal(sum(secret, ts, body), given) {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+// SignAll signs body with every secret and joins the signatures with commas,
+// so a receiver rotating keys can check whichever one it knows.
+func SignAll(secrets []string, timestamp int64, body []byte) string {
+	signatures := make([]string, len(secrets))
+	for i, secret := range secrets {
+		signatures[i] = "sha256=" + Sign(secret, timestamp, body)
+	}
+	return strings.Join(signatures, ",")
+}
+
+// VerifyAny is Verify for a key being rotated: the signature may have been
+// made with any of secrets.
+func VerifyAny(secrets []string, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	err := ErrInvalid
+	for _, secret := range secrets {
+		err = Verify(secret, signature, timestamp, body, tolerance, now)
+		if err != ErrInvalid {
+			return err
+		}
+	}
+	return err
+}
+
+func sum(secret string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
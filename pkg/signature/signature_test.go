@@ -0,0 +1,66 @@
+package signature
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify_AcceptsSignedBody(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"webhook_message_id":"wh-1","status":"delivered"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", now.Unix(), body)
+
+	assert.NoError(t, Verify("secret", sig, ts, body, time.Minute, now))
+	assert.NoError(t, Verify("secret", "sha256="+sig, ts, body, time.Minute, now))
+}
+
+func TestVerify_RejectsTamperedOrForeignSignature(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"status":"delivered"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", now.Unix(), body)
+
+	assert.ErrorIs(t, Verify("secret", sig, ts, []byte(`{"status":"undelivered"}`), time.Minute, now), ErrInvalid)
+	assert.ErrorIs(t, Verify("other", sig, ts, body, time.Minute, now), ErrInvalid)
+	assert.ErrorIs(t, Verify("secret", "not-hex", ts, body, time.Minute, now), ErrInvalid)
+	assert.ErrorIs(t, Verify("secret", sig, "", body, time.Minute, now), ErrMissing)
+}
+
+func TestVerify_RejectsStaleTimestamp(t *testing.T) {
+	signedAt := time.Now().Add(-10 * time.Minute)
+	body := []byte(`{}`)
+	ts := strconv.FormatInt(signedAt.Unix(), 10)
+	sig := Sign("secret", signedAt.Unix(), body)
+
+	assert.ErrorIs(t, Verify("secret", sig, ts, body, 5*time.Minute, time.Now()), ErrExpired)
+	assert.NoError(t, Verify("secret", sig, ts, body, 0, time.Now()))
+}
+
+func TestSignAll_VerifiesWithEitherKey(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"to":"+905551234567","content":"hi"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	header := SignAll([]string{"new", "old"}, now.Unix(), body)
+
+	signatures := strings.Split(header, ",")
+	assert.Len(t, signatures, 2)
+	assert.NoError(t, Verify("new", signatures[0], ts, body, time.Minute, now))
+	assert.NoError(t, Verify("old", signatures[1], ts, body, time.Minute, now))
+}
+
+func TestVerifyAny_AcceptsAnyConfiguredKey(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"messageId":"wh-1"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("old", now.Unix(), body)
+
+	assert.NoError(t, VerifyAny([]string{"new", "old"}, sig, ts, body, time.Minute, now))
+	assert.ErrorIs(t, VerifyAny([]string{"new"}, sig, ts, body, time.Minute, now), ErrInvalid)
+	assert.ErrorIs(t, VerifyAny([]string{"new", "old"}, sig, "", body, time.Minute, now), ErrMissing)
+	assert.ErrorIs(t, VerifyAny(nil, sig, ts, body, time.Minute, now), ErrInvalid)
+}